@@ -0,0 +1,296 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// -------------------------------------------------------------------------
+// Align
+// -------------------------------------------------------------------------
+
+// alignKind is the shape of a line that Align can line up with its
+// neighbors.
+type alignKind int
+
+const (
+	alignNone     alignKind = iota // Left as it is
+	alignAssign                    // x := value, x = value, x += value...
+	alignKeyValue                  // key: value, as in map and struct literals
+	alignField                     // Name Type `tag`, as in struct types
+)
+
+// alignLine is a line split into the cells lined up by Align.
+type alignLine struct {
+	kind    alignKind
+	indent  string
+	cells   []string
+	comment string // Trailing comment, "" if none
+}
+
+// onAlign lines up the selected lines, or the block of lines around the
+// cursor sharing its indentation: the operators of assignments, the
+// values of key: value entries, the types and tags of struct fields, and
+// trailing comments. Like gofmt, it pads with spaces after the
+// indentation, and a blank line or a line of another shape starts a new
+// alignment section.
+func (i *Ite) onAlign() {
+	var first, last int
+	if len(i.editText.TagRanges("sel")) >= 2 {
+		first, last = i.selectedLines()
+	} else {
+		first, last = i.indentBlock()
+		if first == 0 {
+			return
+		}
+	}
+	from, to := fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.end", last)
+	if i.blockProtected(from, to) {
+		return
+	}
+	old := i.editText.Get(from, to)[0]
+	text := strings.Join(alignLines(strings.Split(old, "\n")), "\n")
+	if text == old {
+		i.showStatusHint("Nothing to align")
+		return
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, text)
+	})
+	i.selectRange(from, fmt.Sprintf("%d.end", last))
+}
+
+// indentBlock returns the run of non-blank lines around the cursor with
+// the indentation of the cursor line, or 0, 0 on a blank line.
+func (i *Ite) indentBlock() (first, last int) {
+	line, _ := parseIndex(i.editText.Index("insert"))
+	end, _ := parseIndex(i.editText.Index("end-1c"))
+	text := lineText(i.editText, line)
+	if strings.TrimSpace(text) == "" {
+		return 0, 0
+	}
+	indent := leadingSpace(text)
+	same := func(n int) bool {
+		t := lineText(i.editText, n)
+		return strings.TrimSpace(t) != "" && leadingSpace(t) == indent
+	}
+	first, last = line, line
+	for first > 1 && same(first-1) {
+		first--
+	}
+	for last < end && same(last+1) {
+		last++
+	}
+	return first, last
+}
+
+// alignLines lines up the cells of consecutive lines of the same kind and
+// indentation. Comment lines are kept and don't end a section.
+func alignLines(lines []string) []string {
+	parsed := make([]alignLine, len(lines))
+	for n, line := range lines {
+		parsed[n] = splitAlignLine(line)
+	}
+	out := slices.Clone(lines)
+	for start := 0; start < len(lines); {
+		if parsed[start].kind == alignNone {
+			start++
+			continue
+		}
+		section := []int{start}
+		end := start + 1
+		for ; end < len(lines); end++ {
+			p := parsed[end]
+			if p.kind == alignNone && strings.HasPrefix(strings.TrimSpace(lines[end]), "//") {
+				continue // Comment lines don't break the alignment
+			}
+			if p.kind != parsed[start].kind || p.indent != parsed[start].indent {
+				break
+			}
+			section = append(section, end)
+		}
+		for n, text := range formatSection(parsed, section) {
+			out[section[n]] = text
+		}
+		start = end
+	}
+	return out
+}
+
+// formatSection pads the cells of the lines of a section to the widest
+// cell of their column, and the trailing comments to the widest code.
+func formatSection(parsed []alignLine, section []int) []string {
+	var widths []int
+	for _, n := range section {
+		for c, cell := range parsed[n].cells {
+			if c == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[c] = max(widths[c], utf8.RuneCountInString(cell))
+		}
+	}
+	codes := make([]string, len(section))
+	codeWidth := 0
+	for k, n := range section {
+		p := parsed[n]
+		var sb strings.Builder
+		for c, cell := range p.cells {
+			sb.WriteString(cell)
+			if c < len(p.cells)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[c]-utf8.RuneCountInString(cell)+1))
+			}
+		}
+		codes[k] = strings.TrimRight(sb.String(), " ")
+		codeWidth = max(codeWidth, utf8.RuneCountInString(codes[k]))
+	}
+	out := make([]string, len(section))
+	for k, n := range section {
+		p := parsed[n]
+		out[k] = p.indent + codes[k]
+		if p.comment != "" {
+			out[k] += strings.Repeat(" ", codeWidth-utf8.RuneCountInString(codes[k])+1) + p.comment
+		}
+	}
+	return out
+}
+
+// typeStart lists the tokens a struct field type can start with.
+var typeStart = map[token.Token]bool{
+	token.IDENT: true, token.MUL: true, token.LBRACK: true, token.MAP: true,
+	token.CHAN: true, token.FUNC: true, token.STRUCT: true, token.INTERFACE: true,
+	token.ARROW: true,
+}
+
+// alignToken is a token of a line with its byte offsets.
+type alignToken struct {
+	tok        token.Token
+	start, end int
+	depth      int // Brackets open before the token
+}
+
+// splitAlignLine finds the kind of line and its cells, with the Go scanner
+// so that operators inside strings, calls or literals don't count.
+func splitAlignLine(line string) alignLine {
+	indent := leadingSpace(line)
+	code := line[len(indent):]
+	res := alignLine{indent: indent}
+	if code == "" {
+		return res
+	}
+
+	src := []byte(code)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	broken := false
+	s.Init(file, src, func(token.Position, string) { broken = true }, scanner.ScanComments)
+	var toks []alignToken
+	commentAt := len(code)
+	depth := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF || (tok == token.SEMICOLON && lit == "\n") {
+			break
+		}
+		start := file.Offset(pos)
+		if tok == token.COMMENT {
+			commentAt = start
+			break
+		}
+		end := start + len(lit)
+		if lit == "" {
+			end = start + len(tok.String())
+		}
+		switch tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			toks = append(toks, alignToken{tok, start, end, depth})
+			depth++
+			continue
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+		}
+		toks = append(toks, alignToken{tok, start, end, depth})
+	}
+	if broken || len(toks) == 0 || depth != 0 {
+		return res
+	}
+	res.comment = strings.TrimSpace(code[commentAt:])
+	code = strings.TrimSpace(code[:commentAt])
+	if toks[0].tok.IsKeyword() {
+		return res // Statements such as return or case x:
+	}
+
+	for _, t := range toks {
+		if t.depth > 0 {
+			continue
+		}
+		switch t.tok {
+		case token.ASSIGN, token.DEFINE, token.ADD_ASSIGN, token.SUB_ASSIGN, token.MUL_ASSIGN,
+			token.QUO_ASSIGN, token.REM_ASSIGN, token.AND_ASSIGN, token.OR_ASSIGN, token.XOR_ASSIGN,
+			token.SHL_ASSIGN, token.SHR_ASSIGN, token.AND_NOT_ASSIGN:
+			res.kind = alignAssign
+			res.cells = []string{strings.TrimSpace(code[:t.start]), t.tok.String() + " " + strings.TrimSpace(code[t.end:])}
+			return res
+		case token.COLON:
+			res.kind = alignKeyValue
+			res.cells = []string{strings.TrimSpace(code[:t.end]), strings.TrimSpace(code[t.end:])}
+			return res
+		}
+	}
+
+	// Struct field: names and type, or an embedded type, and an optional tag
+	last := toks[len(toks)-1]
+	var tag string
+	typeEnd, typeToks := len(code), toks
+	if last.tok == token.STRING && len(toks) > 1 {
+		tag = code[last.start:last.end]
+		typeEnd, typeToks = last.start, toks[:len(toks)-1]
+	}
+	switch {
+	case isEmbeddedType(typeToks):
+		res.cells = []string{strings.TrimSpace(code[:typeEnd])}
+	default:
+		names := 0
+		for names < len(typeToks) && typeToks[names].tok == token.IDENT {
+			names++
+			if names == len(typeToks) || typeToks[names].tok != token.COMMA {
+				break
+			}
+			names++
+		}
+		if names == 0 || names == len(typeToks) || typeToks[names-1].tok != token.IDENT ||
+			!typeStart[typeToks[names].tok] {
+			return alignLine{indent: indent}
+		}
+		at := typeToks[names].start
+		res.cells = []string{strings.TrimSpace(code[:at]), strings.TrimSpace(code[at:typeEnd])}
+	}
+	res.kind = alignField
+	if tag != "" {
+		res.cells = append(res.cells, tag) // An embedded field's tag goes in the type column
+	}
+	return res
+}
+
+// isEmbeddedType reports whether toks are an embedded field type: T, *T,
+// pkg.T or *pkg.T.
+func isEmbeddedType(toks []alignToken) bool {
+	if len(toks) > 0 && toks[0].tok == token.MUL {
+		toks = toks[1:]
+	}
+	switch len(toks) {
+	case 1:
+		return toks[0].tok == token.IDENT
+	case 3:
+		return toks[0].tok == token.IDENT && toks[1].tok == token.PERIOD && toks[2].tok == token.IDENT
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlignLines(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			"assignments",
+			"a := 1\nlonger := 2",
+			"a      := 1\nlonger := 2",
+		},
+		{
+			"struct fields",
+			"\tName string // The name\n\tID int // The id",
+			"\tName string // The name\n\tID   int    // The id",
+		},
+		{
+			"comment lines kept",
+			"x = 1\n// note\nyyy = 2",
+			"x   = 1\n// note\nyyy = 2",
+		},
+		{
+			"indentation ends a section",
+			"a := 1\n\tbb := 2",
+			"a := 1\n\tbb := 2",
+		},
+		{
+			"other lines kept",
+			"if x {\n}",
+			"if x {\n}",
+		},
+	}
+	for _, tt := range tests {
+		got := strings.Join(alignLines(strings.Split(tt.in, "\n")), "\n")
+		if got != tt.want {
+			t.Errorf("%s: alignLines = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
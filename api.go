@@ -0,0 +1,230 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// External Tool API
+// -------------------------------------------------------------------------
+
+// When enabled in the Settings menu, the editor serves a small HTTP API on
+// a Unix socket in a directory only the user can enter, so terminals,
+// fuzzy finders and Git GUIs can follow and drive it. The path
+// of the socket is in the ITE_SOCKET environment variable of the programs
+// the editor starts:
+//
+//	curl --unix-socket "$ITE_SOCKET" http://ite/state
+//	curl --unix-socket "$ITE_SOCKET" -d '{"path": "/src/main.go", "line": 12}' http://ite/open
+//
+// GET /state returns the file, cursor, selection and open files of the
+// editor; POST /open opens a file at a line and, optionally, a column,
+// both counted from 1.
+const (
+	apiSocketEnv = "ITE_SOCKET"
+	apiTimeout   = 10 * time.Second // Longest wait for the editor to answer
+	apiMaxBody   = 64 << 10
+	apiFilePerms = 0600  // -rw-------
+	apiDirName   = "run" // Directory of the sockets in the config dir, without XDG_RUNTIME_DIR
+)
+
+// apiState is the answer to GET /state.
+type apiState struct {
+	File      string   `json:"file"` // "" for an untitled buffer
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	Modified  bool     `json:"modified"`
+	Selection string   `json:"selection,omitempty"`
+	Files     []string `json:"files"` // Files of the panes
+	Root      string   `json:"root,omitempty"`
+}
+
+// apiOpen is the body of POST /open.
+type apiOpen struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// apiSocketPath returns the path of the socket of this instance, in
+// $XDG_RUNTIME_DIR/ite, or else in the run directory of the config dir.
+func apiSocketPath() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir != "" {
+		dir = filepath.Join(dir, configDirName)
+	} else {
+		config, err := configDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(config, apiDirName)
+	}
+	return filepath.Join(dir, fmt.Sprintf("ite-%d.sock", os.Getpid())), nil
+}
+
+// startAPI starts serving the API, reporting failures in the status bar.
+func (i *Ite) startAPI() {
+	if i.api != nil {
+		return
+	}
+	path, err := apiSocketPath()
+	if err == nil {
+		// Other users can't reach a socket in a directory they can't enter,
+		// even before its own mode is set
+		err = os.MkdirAll(filepath.Dir(path), privateDirPerms)
+	}
+	if err == nil {
+		err = os.Chmod(filepath.Dir(path), privateDirPerms)
+	}
+	if err != nil {
+		i.showError("Error starting the API: " + err.Error())
+		return
+	}
+	os.Remove(path) // Left by a crashed editor that had our pid
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		i.showError("Error starting the API: " + err.Error())
+		return
+	}
+	os.Chmod(path, apiFilePerms)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /state", i.serveAPIState)
+	mux.HandleFunc("POST /open", i.serveAPIOpen)
+	i.api = &http.Server{Handler: mux, ReadHeaderTimeout: apiTimeout}
+	i.apiSocket = path
+	os.Setenv(apiSocketEnv, path)
+	go i.api.Serve(ln)
+}
+
+// stopAPI stops serving the API and removes its socket.
+func (i *Ite) stopAPI() {
+	if i.api == nil {
+		return
+	}
+	i.api.Close()
+	os.Remove(i.apiSocket)
+	os.Unsetenv(apiSocketEnv)
+	i.api, i.apiSocket = nil, ""
+}
+
+// onToggleAPI switches the API on or off and persists the choice.
+func (i *Ite) onToggleAPI() {
+	i.config.ExternalAPI = !i.config.ExternalAPI
+	i.apiVar.Set(checkValue(i.config.ExternalAPI))
+	i.saveConfig()
+	if !i.config.ExternalAPI {
+		i.stopAPI()
+		i.showStatusHint("External tool API stopped")
+		return
+	}
+	i.startAPI()
+	if i.api != nil {
+		i.showStatusHint("External tool API on " + i.apiSocket)
+	}
+}
+
+// onUI runs fn on the Tk thread and waits for it, reporting false when the
+// editor doesn't get to it within apiTimeout.
+func (i *Ite) onUI(fn func()) bool {
+	done := make(chan struct{})
+	i.Dispatch(func() {
+		defer close(done)
+		fn()
+	})
+	select {
+	case <-done:
+		return true
+	case <-time.After(apiTimeout):
+		return false
+	}
+}
+
+// serveAPIState answers GET /state.
+func (i *Ite) serveAPIState(w http.ResponseWriter, r *http.Request) {
+	var state apiState
+	ok := i.onUI(func() {
+		line, col := parseIndex(i.editText.Index("insert"))
+		state = apiState{File: i.currentFile, Line: line, Column: col + 1, Modified: i.editText.Modified()}
+		if from, to := i.editRange(""); from != to {
+			state.Selection = i.editText.Get(from, to)[0]
+		}
+		for _, p := range i.panes {
+			file := p.file
+			if p == i.active {
+				file = i.currentFile
+			}
+			if file != "" {
+				state.Files = append(state.Files, file)
+			}
+		}
+		if i.currentFile != "" {
+			state.Root = i.projectDir()
+		}
+	})
+	if !ok {
+		http.Error(w, "editor busy", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// serveAPIOpen answers POST /open.
+func (i *Ite) serveAPIOpen(w http.ResponseWriter, r *http.Request) {
+	var req apiOpen
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBody)).Decode(&req); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !filepath.IsAbs(req.Path) {
+		http.Error(w, "path must be absolute", http.StatusBadRequest)
+		return
+	}
+	if _, err := os.Stat(req.Path); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var err error
+	ok := i.onUI(func() { err = i.openAt(req.Path, max(req.Line, 1), max(req.Column, 1)) })
+	switch {
+	case !ok:
+		http.Error(w, "editor busy", http.StatusServiceUnavailable)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// errOpenCancelled reports that the user kept the unsaved buffer open.
+var errOpenCancelled = errors.New("cancelled by the user")
+
+// openAt opens path, unless it is the current file, moves the cursor to
+// line and the character column col, and raises the window.
+func (i *Ite) openAt(path string, line, col int) error {
+	if !samePath(path, i.currentFile) {
+		if !i.promptSaveIfModified() {
+			return errOpenCancelled
+		}
+		if err := i.openFile(path); err != nil {
+			return err
+		}
+	}
+	WmDeiconify(App)
+	tclEval("raise .")
+	i.jumpTo(line, col-1)
+	return nil
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Assembly Viewer
+// -------------------------------------------------------------------------
+
+// Show Assembly compiles the package of the current file with -S and lists
+// the instructions of the function at the cursor, and of its closures, in
+// a window. Each instruction shows the source line it comes from; clicking
+// it moves the editor there and highlights the instructions of that line.
+const (
+	tagAsmSymbol  = "asmsymbol"  // Assembly tag of the function headings
+	tagAsmCurrent = "asmcurrent" // Assembly tag of the instructions of the selected line
+	tagAsmForeign = "asmforeign" // Assembly tag of instructions inlined from other files
+)
+
+// asmInstrRe matches an instruction of the -S listing, capturing the file,
+// the line and the instruction, e.g.
+// "\t0x0005 00005 (/src/a.go:2)\tMOVL\t$1, AX".
+var asmInstrRe = regexp.MustCompile(`^\t0x[0-9a-f]+ \d+ \((.+):(\d+)\)\t(.*)$`)
+
+// asmLine is an instruction of the listing.
+type asmLine struct {
+	location
+	instr string
+}
+
+// asmFunc is the listing of a function.
+type asmFunc struct {
+	symbol string
+	lines  []asmLine
+}
+
+// asmPanel is the window showing the assembly of a function.
+type asmPanel struct {
+	window *ToplevelWidget
+	view   *TextWidget
+	file   string          // Source file of the function
+	lines  map[int]asmLine // Instructions by view line
+}
+
+// onShowAssembly compiles the package of the current file in the
+// background and shows the assembly of the function at the cursor.
+func (i *Ite) onShowAssembly() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Show Assembly needs a saved Go file.")
+		return
+	}
+	line, _ := parseIndex(i.editText.Index("insert"))
+	name := funcSymbolAt(i.currentFile, i.editText.Text(), line)
+	if name == "" {
+		i.showStatusHint("No function at the cursor")
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	file := i.currentFile
+	i.showStatusHint("Compiling " + name + "...")
+	go func() {
+		cmd := exec.Command("go", "build", "-gcflags=-S", "-o", os.DevNull, ".")
+		cmd.Dir = filepath.Dir(file)
+		output, err := cmd.CombinedOutput()
+		funcs := asmFuncs(string(output), name)
+		if err != nil {
+			err = errors.New(strings.TrimSpace(string(output)))
+		}
+		i.Dispatch(func() {
+			switch {
+			case err != nil:
+				i.showError("Show Assembly: " + err.Error())
+			case len(funcs) == 0:
+				i.showStatusHint("No assembly for " + name + ", it may have been inlined everywhere or is generic")
+			default:
+				i.showAssembly(name, file, funcs, line)
+			}
+		})
+	}()
+}
+
+// funcSymbolAt returns the name the compiler gives the function of src
+// holding line: "F" for a function, "T.M" or "(*T).M" for a method, or ""
+// if line is outside any function.
+func funcSymbolAt(path, src string, line int) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fset.Position(fn.Pos()).Line > line || line > fset.Position(fn.End()).Line {
+			continue
+		}
+		if fn.Recv == nil || len(fn.Recv.List) == 0 {
+			return fn.Name.Name
+		}
+		recv := fn.Recv.List[0].Type
+		star := false
+		if s, ok := recv.(*ast.StarExpr); ok {
+			recv, star = s.X, true
+		}
+		switch t := recv.(type) { // Type parameters are left out
+		case *ast.IndexExpr:
+			recv = t.X
+		case *ast.IndexListExpr:
+			recv = t.X
+		}
+		id, ok := recv.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		if star {
+			return "(*" + id.Name + ")." + fn.Name.Name
+		}
+		return id.Name + "." + fn.Name.Name
+	}
+	return ""
+}
+
+// asmFuncs extracts from the -S listing the functions whose symbol is the
+// package path followed by name, and their closures.
+func asmFuncs(listing, name string) []asmFunc {
+	var funcs []asmFunc
+	var cur *asmFunc
+	for _, line := range strings.Split(listing, "\n") {
+		if line == "" || line[0] == '\t' {
+			if cur == nil {
+				continue
+			}
+			if m := asmInstrRe.FindStringSubmatch(line); m != nil {
+				op, _, _ := strings.Cut(m[3], "\t")
+				if op == "FUNCDATA" || op == "PCDATA" {
+					continue
+				}
+				l := asmLine{location: location{path: m[1], col: 1}, instr: m[3]}
+				l.line, _ = strconv.Atoi(m[2])
+				cur.lines = append(cur.lines, l)
+			}
+			continue
+		}
+		cur = nil
+		symbol, kind, _ := strings.Cut(line, " ")
+		if strings.HasPrefix(kind, "STEXT") && isSymbolOf(symbol, name) {
+			funcs = append(funcs, asmFunc{symbol: symbol})
+			cur = &funcs[len(funcs)-1]
+		}
+	}
+	return funcs
+}
+
+// isSymbolOf reports whether symbol, qualified by the package path, is
+// the function name or one of its closures.
+func isSymbolOf(symbol, name string) bool {
+	// The package path ends at the first dot after its last slash
+	rest := symbol[strings.LastIndexByte(symbol, '/')+1:]
+	dot := strings.IndexByte(rest, '.')
+	if dot < 0 {
+		return false
+	}
+	rest = rest[dot+1:]
+	return rest == name || strings.HasPrefix(rest, name+".func") || strings.HasPrefix(rest, name+"[")
+}
+
+// showAssembly shows the listings of funcs, compiled from file, in the
+// assembly window, highlighting the instructions of line.
+func (i *Ite) showAssembly(name, file string, funcs []asmFunc, line int) {
+	p := i.asm
+	if p == nil {
+		p = &asmPanel{window: Toplevel()}
+		p.view = p.window.Text(textStyle(), Width(80), Height(30), Wrap("none"))
+		scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.view) }))
+		p.view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+		Grid(p.view, Row(0), Column(0), Sticky(NEWS))
+		Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+		GridRowConfigure(p.window, 0, Weight(1))
+		GridColumnConfigure(p.window, 0, Weight(1))
+		p.view.TagConfigure(tagAsmSymbol, Font(editorFontFamily, fontSize, "bold"))
+		p.view.TagConfigure(tagAsmCurrent, Background(theme.CurrentLine))
+		p.view.TagConfigure(tagAsmForeign, Foreground(theme.Muted))
+		Bind(p.view, "<Button-1>", Command(func() { i.onAssemblyClick() }))
+		closeWindow := func() {
+			Destroy(p.window)
+			i.asm = nil
+			Focus(i.editText)
+		}
+		Bind(p.window, "<Escape>", Command(closeWindow))
+		WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+		i.asm = p
+	} else {
+		WmDeiconify(p.window.Window)
+		tclEval("raise %s", p.window)
+	}
+	p.window.WmTitle("Assembly: " + name)
+	p.file = file
+	p.lines = make(map[int]asmLine)
+
+	p.view.Configure(State("normal"))
+	p.view.Delete("1.0", "end")
+	n := 1
+	for _, f := range funcs {
+		if n > 1 {
+			p.view.Insert("end", "\n")
+			n++
+		}
+		p.view.Insert("end", f.symbol+"\n", tagAsmSymbol)
+		n++
+		for _, l := range f.lines {
+			text := fmt.Sprintf("%5d  %s\n", l.line, l.instr)
+			if samePath(l.path, file) {
+				p.view.Insert("end", text)
+			} else {
+				text = fmt.Sprintf("%5d  %s    // %s\n", l.line, l.instr, filepath.Base(l.path))
+				p.view.Insert("end", text, tagAsmForeign)
+			}
+			p.lines[n] = l
+			n++
+		}
+	}
+	p.view.Configure(State("disabled"))
+	i.highlightAssembly(line)
+}
+
+// onAssemblyClick moves the editor to the source line of the instruction
+// clicked, if it is in the file the assembly was compiled from.
+func (i *Ite) onAssemblyClick() {
+	p := i.asm
+	n, _ := parseIndex(p.view.Index("current"))
+	l, ok := p.lines[n]
+	if !ok {
+		return
+	}
+	if !samePath(l.path, i.currentFile) {
+		i.showStatusHint(fmt.Sprintf("Inlined from %s:%d", l.path, l.line))
+		return
+	}
+	i.highlightAssembly(l.line)
+	i.jumpTo(l.line, 0)
+}
+
+// highlightAssembly marks the instructions of source line and scrolls the
+// first one into view.
+func (i *Ite) highlightAssembly(line int) {
+	p := i.asm
+	p.view.TagRemove(tagAsmCurrent, "1.0", "end")
+	first := 0
+	for n, l := range p.lines {
+		if l.line != line || !samePath(l.path, p.file) {
+			continue
+		}
+		p.view.TagAdd(tagAsmCurrent, fmt.Sprintf("%d.0", n), fmt.Sprintf("%d.0", n+1))
+		if first == 0 || n < first {
+			first = n
+		}
+	}
+	if first > 0 {
+		p.view.See(fmt.Sprintf("%d.0", first))
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Assert Selection
+// -------------------------------------------------------------------------
+
+const (
+	assertTestName = "TestIteAssertSelection" // Test wrapping the selection
+	assertTestFile = "ite_assert_test.go"     // Name the test file takes in the package
+)
+
+// versionElemRe matches the major version suffix of an import path.
+var versionElemRe = regexp.MustCompile(`^v\d+$`)
+
+// onAssertSelection checks the selected boolean expression by running it
+// as the only statement of a temporary test in the package of the current
+// file. The test reaches the go command through an overlay, so the
+// package directory is never written to. Compile errors point back into
+// the selection.
+func (i *Ite) onAssertSelection() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Assert Selection needs a saved Go file.")
+		return
+	}
+	sel := i.editText.TagRanges("sel")
+	if len(sel) < 2 {
+		i.showError("Select a boolean expression to assert.")
+		return
+	}
+	expr := i.editText.Get(sel[0], sel[len(sel)-1])[0]
+	x, err := parser.ParseExpr(expr)
+	if err != nil {
+		i.showError("Assert Selection: not an expression: " + err.Error())
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), i.currentFile, i.editText.Text(), parser.ImportsOnly)
+	if err != nil {
+		i.showError("Assert Selection: " + err.Error())
+		return
+	}
+	line, col := parseIndex(sel[0])
+	byteCol := len(string([]rune(lineText(i.editText, line))[:col])) + 1
+	src := assertTestSource(f, x, expr, i.currentFile, line, byteCol)
+
+	overlay, err := i.writeAssertOverlay(filepath.Join(filepath.Dir(i.currentFile), assertTestFile), src)
+	if err != nil {
+		i.showError("Assert Selection: " + err.Error())
+		return
+	}
+	i.runCommand(i.console(consoleTest),
+		[]string{"test", "-json", "-fullpath", "-count=1", "-overlay", overlay, "-run", "^" + assertTestName + "$", i.currentPackage()},
+		statusTesting, newTestDecoder())
+}
+
+// assertTestSource returns a test file for the package of f that fails
+// unless expr, parsed as x, holds. It imports the packages of f that expr
+// refers to. The line directive maps expr to its place in file.
+func assertTestSource(f *ast.File, x ast.Expr, expr, file string, line, col int) string {
+	used := make(map[string]bool)
+	ast.Inspect(x, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\nimport (\n\t\"testing\"\n", f.Name.Name)
+	for _, imp := range f.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		name := importName(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if used[name] && importPath != "testing" {
+			fmt.Fprintf(&b, "\t%s %s\n", name, imp.Path.Value)
+		}
+	}
+	fmt.Fprintf(&b, ")\n\nfunc %s(t *testing.T) {\n\tif !(\n", assertTestName)
+	fmt.Fprintf(&b, "//line %s:%d:%d\n%s) {\n", filepath.ToSlash(file), line, col, expr)
+	fmt.Fprintf(&b, "\t\tt.Fatalf(\"assertion failed: %%s\", %s)\n\t}\n}\n", strconv.Quote(expr))
+	return b.String()
+}
+
+// importName returns the default package name of an import path: its last
+// element, skipping a major version suffix.
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if versionElemRe.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	return name
+}
+
+// writeAssertOverlay writes src and an overlay file placing it at target
+// into a new temporary directory, replacing the one of the previous
+// assertion or mutation. It returns the path of the overlay file.
+func (i *Ite) writeAssertOverlay(target, src string) (string, error) {
+	i.removeAssertOverlay()
+	dir, err := os.MkdirTemp("", "ite-assert-")
+	if err != nil {
+		return "", err
+	}
+	i.assertDir = dir
+	testPath := filepath.Join(dir, filepath.Base(target))
+	if err := os.WriteFile(testPath, []byte(src), defaultFilePerms); err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(map[string]map[string]string{"Replace": {abs: testPath}})
+	if err != nil {
+		return "", err
+	}
+	overlay := filepath.Join(dir, "overlay.json")
+	return overlay, os.WriteFile(overlay, data, defaultFilePerms)
+}
+
+// removeAssertOverlay deletes the files of the last assertion or
+// mutation, if any.
+func (i *Ite) removeAssertOverlay() {
+	if i.assertDir != "" {
+		os.RemoveAll(i.assertDir)
+		i.assertDir = ""
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// -------------------------------------------------------------------------
+// Atomic Writes
+// -------------------------------------------------------------------------
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory, renamed over path once complete, so that a crash leaves
+// either the old content or the new one, never a truncated file. An
+// existing file keeps its permissions and, where the system allows, its
+// owner; a new one gets perm. A symbolic link is followed, the file it
+// points to being replaced. When the directory can't hold the temporary
+// file, the file is overwritten in place.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	info, statErr := os.Stat(path)
+	if statErr == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".ite-*")
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return os.WriteFile(path, data, perm)
+		}
+		return err
+	}
+	done := false
+	defer func() {
+		if !done {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if statErr == nil {
+		preserveOwner(tmp, info)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	done = true
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Auto-Closing Pairs
+// -------------------------------------------------------------------------
+
+// Typing an opening bracket or quote also inserts its closing character
+// after the cursor, or wraps the selection in the pair. The closing
+// characters inserted that way are tagged, so typing one over them steps
+// past it instead of adding another, and Backspace between an empty pair,
+// or Delete before it, deletes both. Deleting a line break joins the lines
+// the way the code reads: the indentation of the second line and the
+// blanks ending the first give way to a single space, or to none next to
+// a bracket, and a blank line goes away without touching the other.
+const (
+	pairsBindTag  = "ItePairs"
+	tagAutoClosed = "autoclosed" // Closing characters inserted by the editor
+)
+
+// openingKeys maps the keysyms of the opening characters to them.
+var openingKeys = map[string]string{
+	"parenleft":   "(",
+	"bracketleft": "[",
+	"braceleft":   "{",
+	"quotedbl":    `"`,
+	"grave":       "`",
+}
+
+// closingPairs maps the opening characters to their closing ones.
+var closingPairs = map[string]string{"(": ")", "[": "]", "{": "}", `"`: `"`, "`": "`"}
+
+// closesBefore are the characters before which an opening one is paired:
+// a pair isn't added in front of a word.
+const closesBefore = ")]};,:"
+
+// bindAutoPairs installs the bindings inserting and skipping pairs. The
+// closing brace is handled by insertCloseBrace.
+func (i *Ite) bindAutoPairs() {
+	addBindtag(i.editText.Window, pairsBindTag, "Text")
+	handle := func(fn func() bool) func(*Event) {
+		return func(e *Event) {
+			if !i.config.AutoClosePairs || i.composing || i.largeFile || e.State&(ModifierControl|ModifierAlt) != 0 {
+				return
+			}
+			if fn() {
+				i.editText.See("insert")
+				e.SetReturnCodeBreak()
+			}
+		}
+	}
+	for keysym, open := range openingKeys {
+		Bind(pairsBindTag, "<"+keysym+">", Command(handle(func() bool { return i.typePair(open) })))
+	}
+	Bind(pairsBindTag, "<parenright>", Command(handle(func() bool { return i.skipAutoClosed(")") })))
+	Bind(pairsBindTag, "<bracketright>", Command(handle(func() bool { return i.skipAutoClosed("]") })))
+	Bind(pairsBindTag, "<BackSpace>", Command(handle(func() bool {
+		return i.deletePair() || i.joinLine("insert -1c", true)
+	})))
+	Bind(pairsBindTag, "<Delete>", Command(handle(func() bool {
+		return i.deletePairAfter() || i.joinLine("insert", false)
+	})))
+}
+
+// onToggleAutoClosePairs switches auto-closing pairs on or off and
+// persists the choice.
+func (i *Ite) onToggleAutoClosePairs() {
+	i.config.AutoClosePairs = !i.config.AutoClosePairs
+	i.autoPairsVar.Set(checkValue(i.config.AutoClosePairs))
+	i.saveConfig()
+}
+
+// typePair types open, wrapping the selection in it and its closing
+// character, or adding the closing character after the cursor when it is
+// followed by a blank or a closing character. It reports whether it did,
+// false leaving the key to the editor.
+func (i *Ite) typePair(open string) bool {
+	closer := closingPairs[open]
+	if from, to := i.editRange(""); from != to {
+		if i.blockProtected(from, to) {
+			return true
+		}
+		i.editGroup(func() {
+			i.editText.Insert(to, closer)
+			i.editText.Insert(from, open)
+		})
+		return true
+	}
+	if open == closer && i.skipAutoClosed(closer) {
+		return true
+	}
+	prev := i.editText.Get("insert -1c", "insert")[0]
+	next := i.editText.Get("insert", "insert +1c")[0]
+	if next != "" && !strings.ContainsAny(next, " \t\n"+closesBefore) {
+		return false
+	}
+	if open == closer {
+		// Not after a word or escape, nor to end a string
+		r, _ := utf8.DecodeLastRuneInString(prev)
+		before := i.editText.Get("insert linestart", "insert")[0]
+		if prev == `\` || prev == open || i.isWordChar(r) || strings.Count(before, open)%2 == 1 {
+			return false
+		}
+	}
+	i.editText.Insert("insert", open)
+	i.editText.Insert("insert", closer, tagAutoClosed)
+	i.editText.MarkSet("insert", "insert -1c")
+	return true
+}
+
+// skipAutoClosed moves the cursor past closer when it is the closing
+// character the editor inserted after the cursor, reporting whether it
+// did.
+func (i *Ite) skipAutoClosed(closer string) bool {
+	if !i.config.AutoClosePairs || i.editText.Get("insert", "insert +1c")[0] != closer ||
+		!slices.Contains(i.editText.TagNames("insert"), tagAutoClosed) {
+		return false
+	}
+	i.editText.TagRemove(tagAutoClosed, "insert", "insert +1c")
+	i.editText.MarkSet("insert", "insert +1c")
+	return true
+}
+
+// deletePair deletes both characters of an empty pair around the cursor
+// whose closing character the editor inserted, reporting whether it did.
+func (i *Ite) deletePair() bool {
+	if from, to := i.editRange(""); from != to {
+		return false
+	}
+	prev := i.editText.Get("insert -1c", "insert")[0]
+	closer, ok := closingPairs[prev]
+	if !ok || i.editText.Get("insert", "insert +1c")[0] != closer ||
+		!slices.Contains(i.editText.TagNames("insert"), tagAutoClosed) {
+		return false
+	}
+	i.editText.Delete("insert -1c", "insert +1c")
+	return true
+}
+
+// deletePairAfter deletes both characters of an empty pair after the
+// cursor whose closing character the editor inserted, reporting whether
+// it did.
+func (i *Ite) deletePairAfter() bool {
+	if from, to := i.editRange(""); from != to {
+		return false
+	}
+	closer, ok := closingPairs[i.editText.Get("insert", "insert +1c")[0]]
+	if !ok || i.editText.Get("insert +1c", "insert +2c")[0] != closer ||
+		!slices.Contains(i.editText.TagNames("insert +1c"), tagAutoClosed) {
+		return false
+	}
+	i.editText.Delete("insert", "insert +2c")
+	return true
+}
+
+// joinLine deletes the line break at index nl, which Backspace deletes
+// when start is true and Delete otherwise, merging the blanks around it
+// as lineJoin says. It reports whether it did, false leaving the key to
+// the editor when nl isn't a line break at the cursor.
+func (i *Ite) joinLine(nl string, start bool) bool {
+	if from, to := i.editRange(""); from != to {
+		return false
+	}
+	insert := i.editText.Index("insert")
+	if start != (insert == i.editText.Index("insert linestart")) || start && insert == "1.0" ||
+		i.editText.Get(nl, nl+" +1c")[0] != "\n" || i.editText.Index(nl+" +1c") == i.editText.Index("end") {
+		return false
+	}
+	nl = i.editText.Index(nl)
+	before := i.editText.Get(nl+" linestart", nl)[0]
+	after := i.editText.Get(nl+" +1c", nl+" +1c lineend")[0]
+	cutBefore, cutAfter, sep := lineJoin(before, after)
+	from := i.editText.Index(fmt.Sprintf("%s -%dc", nl, cutBefore))
+	to := i.editText.Index(fmt.Sprintf("%s +%dc", nl, 1+cutAfter))
+	if i.isProtected(from, to) {
+		return false // The editor deletes the line break alone, if allowed
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, sep)
+		i.editText.MarkSet("insert", from)
+	})
+	return true
+}
+
+// lineJoin returns how the line before is merged with the line after it:
+// the blanks ending before and starting after that are dropped, and the
+// separator put between what is left. A blank line is dropped whole.
+func lineJoin(before, after string) (cutBefore, cutAfter int, sep string) {
+	trimmed := strings.TrimRight(before, " \t")
+	content := strings.TrimLeft(after, " \t")
+	switch {
+	case trimmed == "":
+		return len(before), 0, ""
+	case content == "":
+		return len(before) - len(trimmed), len(after), ""
+	}
+	cutBefore, cutAfter = len(before)-len(trimmed), len(after)-len(content)
+	if strings.ContainsAny(trimmed[len(trimmed)-1:], "([{.") || strings.ContainsAny(content[:1], ")]},.;") {
+		return cutBefore, cutAfter, ""
+	}
+	return cutBefore, cutAfter, " "
+}
@@ -0,0 +1,27 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestLineJoin(t *testing.T) {
+	tests := []struct {
+		before, after, want string
+	}{
+		{"\tx := a +", "\t\tb", "\tx := a + b"},
+		{"\tx := a +  ", "b", "\tx := a + b"},
+		{"\tfoo(", "\t\tbar)", "\tfoo(bar)"},
+		{"\tfoo(bar", "\t)", "\tfoo(bar)"},
+		{"\tx := y.", "\t\tMethod()", "\tx := y.Method()"},
+		{"\t", "\tif ok {", "\tif ok {"},
+		{"", "\treturn", "\treturn"},
+		{"\treturn  ", "\t\t", "\treturn"},
+	}
+	for _, tt := range tests {
+		cutBefore, cutAfter, sep := lineJoin(tt.before, tt.after)
+		if got := tt.before[:len(tt.before)-cutBefore] + sep + tt.after[cutAfter:]; got != tt.want {
+			t.Errorf("lineJoin(%q, %q) joins to %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
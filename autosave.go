@@ -0,0 +1,48 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Save on Focus Loss
+// -------------------------------------------------------------------------
+
+// bindFocusSave saves the modified buffers when the application loses the
+// keyboard focus, if enabled in the preferences. Focus moving between
+// ITE's own windows doesn't count: Tk reports no focus window only once
+// another application has it.
+func (i *Ite) bindFocusSave() {
+	Bind(App, "<FocusOut>", Command(func() {
+		if !i.config.SaveOnFocusLoss {
+			return
+		}
+		TclAfterIdle(func() {
+			if tclEval("focus") == "" {
+				i.saveModifiedBuffers()
+			}
+		})
+	}))
+}
+
+// saveModifiedBuffers writes every pane holding unsaved changes to a named
+// file. Untitled buffers and files changed by other programs are left
+// alone; errors go to the status bar rather than interrupting with a dialog.
+func (i *Ite) saveModifiedBuffers() {
+	for _, p := range i.panes {
+		i.withPane(p, i.autosaveBuffer)
+	}
+}
+
+// autosaveBuffer saves the active buffer if it is modified and named.
+func (i *Ite) autosaveBuffer() {
+	if i.currentFile == "" || !i.editText.Modified() || i.loading() || i.changedOnDisk() {
+		return
+	}
+	if err := i.writeBuffer(); err != nil {
+		i.showStatusHint("Autosave failed: " + err.Error())
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Autospacing
+// -------------------------------------------------------------------------
+
+// gofmt spaces Go code, not the data and query files next to it. With
+// Autospacing on, typing in those adds the space that goes before or after
+// an operator, so "name:value" comes out as "name: value". The rules come
+// from the extension of the file, or its name, the spacing tables of the
+// config file overriding the built-in entries of their operators:
+//
+//	[spacing.".sql"]
+//	"=" = "around"       # Also "before" or "after"
+//	"," = "after first"  # Only the first comma of a line
+//	"<" = "none"         # Left alone
+//
+// Operators in quotes or after a comment marker are left alone, and a
+// space goes after one only before a word, a number, a quote or an
+// opening bracket, and not after a longer operator, so "http://" and
+// "a<=b" keep their shape.
+const spacingBindTag = "IteSpacing"
+
+// spacingRules are the spacings of the operators of a type of file, such
+// as "around" or "after first", by operator, a single character.
+type spacingRules map[string]string
+
+// spacingRule is the spacing of an operator.
+type spacingRule struct {
+	op     rune
+	before bool // Put a space before the operator
+	after  bool // Put a space after the operator
+	first  bool // Only for the first operator of a line, such as the colon of a YAML key
+}
+
+// defaultSpacing are the built-in spacing rules, by extension or file
+// name.
+var defaultSpacing = map[string]spacingRules{
+	".yaml": {":": "after first"},
+	".yml":  {":": "after first"},
+	".json": {":": "after", ",": "after"},
+	".toml": {"=": "around first"},
+	".sql":  {",": "after", "=": "around"},
+}
+
+// parseSpacing returns the rules of spacings, skipping the operators of
+// more than a character and the unknown words.
+func parseSpacing(spacings spacingRules) []spacingRule {
+	var rules []spacingRule
+	for _, op := range slices.Sorted(maps.Keys(spacings)) {
+		r, size := utf8.DecodeRuneInString(op)
+		if size == 0 || size != len(op) {
+			continue
+		}
+		rule := spacingRule{op: r}
+		for _, word := range strings.Fields(spacings[op]) {
+			switch word {
+			case "before":
+				rule.before = true
+			case "after":
+				rule.after = true
+			case "around":
+				rule.before, rule.after = true, true
+			case "first":
+				rule.first = true
+			}
+		}
+		if rule.before || rule.after {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// commentMarkers start a comment in the files spaced.
+var commentMarkers = []string{"#", "--", "//"}
+
+// operatorChars make a longer operator with the one they precede, such as
+// <= or :=, which gets no space after it.
+const operatorChars = "<>!=:"
+
+// spacingRulesOf returns the spacing rules of path. Go files have none:
+// gofmt spaces them.
+func (i *Ite) spacingRulesOf(path string) []spacingRule {
+	if path == "" || filepath.Ext(path) == defaultFileExtension {
+		return nil
+	}
+	for _, key := range []string{filepath.Base(path), filepath.Ext(path)} {
+		if key == "" {
+			continue
+		}
+		spacings, ok := defaultSpacing[key]
+		custom, customOK := i.config.Spacing[key]
+		if !ok && !customOK {
+			continue
+		}
+		merged := maps.Clone(spacings)
+		if merged == nil {
+			merged = make(spacingRules)
+		}
+		maps.Copy(merged, custom)
+		return parseSpacing(merged)
+	}
+	return nil
+}
+
+// spaceBefore reports whether typing r after before, the text of the line
+// up to the cursor, calls for a space first under rules.
+func spaceBefore(rules []spacingRule, before string, r rune) bool {
+	if unicode.IsSpace(r) || before == "" {
+		return false
+	}
+	var quote rune
+	escaped := false
+	seen := make(map[rune]int) // Operators outside quotes
+	for n, c := range before {
+		switch {
+		case quote != 0 && escaped:
+			escaped = false
+		case quote != 0 && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			for _, marker := range commentMarkers {
+				if strings.HasPrefix(before[n:], marker) {
+					return false
+				}
+			}
+			seen[c]++
+		}
+	}
+	if quote != 0 {
+		return false
+	}
+	last, size := utf8.DecodeLastRuneInString(before)
+	prev, _ := utf8.DecodeLastRuneInString(before[:len(before)-size])
+	for _, rule := range rules {
+		if rule.after && last == rule.op && !strings.ContainsRune(operatorChars, prev) && startsValue(r) &&
+			(!rule.first || seen[rule.op] == 1) {
+			return true
+		}
+		if rule.before && r == rule.op && endsValue(last) && (!rule.first || seen[rule.op] == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsValue reports whether r may start what follows an operator.
+func startsValue(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(`"'([{-$@`, r)
+}
+
+// endsValue reports whether r may end what comes before an operator.
+func endsValue(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(`"')]}_`, r)
+}
+
+// bindAutospacing installs the key binding adding the spaces, before the
+// editor inserts the character typed.
+func (i *Ite) bindAutospacing() {
+	addBindtag(i.editText.Window, spacingBindTag, "Text")
+	Bind(spacingBindTag, "<KeyPress>", Command(func(e *Event) {
+		if !i.config.Autospacing || i.composing || i.largeFile || i.readOnly ||
+			e.State&(ModifierControl|ModifierAlt) != 0 {
+			return
+		}
+		r, ok := keysymRune(e.Keysym)
+		if !ok {
+			return
+		}
+		if from, to := i.editRange(""); from != to {
+			return // Typing replaces the selection
+		}
+		rules := i.spacingRulesOf(i.currentFile)
+		if len(rules) > 0 && spaceBefore(rules, i.editText.Get("insert linestart", "insert")[0], r) &&
+			!i.isProtected("insert", "insert") {
+			i.editText.Insert("insert", " ")
+		}
+	}))
+}
+
+// onToggleAutospacing switches autospacing on or off and persists the
+// choice.
+func (i *Ite) onToggleAutospacing() {
+	i.config.Autospacing = !i.config.Autospacing
+	i.autospacingVar.Set(checkValue(i.config.Autospacing))
+	i.saveConfig()
+}
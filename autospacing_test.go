@@ -0,0 +1,51 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpaceBefore(t *testing.T) {
+	yaml, json, sql := parseSpacing(defaultSpacing[".yaml"]), parseSpacing(defaultSpacing[".json"]), parseSpacing(defaultSpacing[".sql"])
+	for _, tt := range []struct {
+		name   string
+		rules  []spacingRule
+		before string
+		r      rune
+		want   bool
+	}{
+		{"yaml key", yaml, "  name:", 'v', true},
+		{"yaml space typed", yaml, "name:", ' ', false},
+		{"yaml second colon", yaml, "time: 12:", '3', false},
+		{"yaml url", yaml, "url: http:", '/', false},
+		{"yaml list item", yaml, "- name:", '"', true},
+		{"yaml comment", yaml, "# note:", 'x', false},
+		{"json value", json, `{"a":`, '1', true},
+		{"json colon in string", json, `{"a:`, 'b', false},
+		{"json comma", json, `[1,`, '2', true},
+		{"json escaped quote", json, `{"a\":`, 'b', false},
+		{"sql before", sql, "WHERE id", '=', true},
+		{"sql after", sql, "WHERE id =", '1', true},
+		{"sql after unspaced", sql, "WHERE id=", '1', true},
+		{"sql longer operator", sql, "WHERE id<=", '1', false},
+		{"sql not after space", sql, "WHERE id ", '=', false},
+		{"sql in quotes", sql, "SELECT 'a", '=', false},
+		{"sql comment", sql, "-- a", '=', false},
+		{"line start", sql, "", '=', false},
+	} {
+		if got := spaceBefore(tt.rules, tt.before, tt.r); got != tt.want {
+			t.Errorf("%s: spaceBefore(%q, %q) = %v, want %v", tt.name, tt.before, tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestParseSpacing(t *testing.T) {
+	got := parseSpacing(spacingRules{"=": "around first", ",": "after", "<": "none", "->": "around", ":": "before"})
+	want := []spacingRule{{op: ',', after: true}, {op: ':', before: true}, {op: '=', before: true, after: true, first: true}}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseSpacing = %+v, want %+v", got, want)
+	}
+}
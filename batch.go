@@ -0,0 +1,207 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Batch Mode
+// -------------------------------------------------------------------------
+
+// `ite --batch script file...` edits the files with the commands of the
+// script, one per line, and writes them back, without opening a window.
+// The commands are those of the editor, by the ids of the keys file, and
+// do what they do on a buffer:
+//
+//	# Blank lines and lines starting with # are skipped
+//	formatFile
+//	replace --regex 'fmt\.Println\((.*)\)' 'log.Println($1)'
+//	replace TODO FIXME
+//	trimTrailingWhitespace
+//	convertToLF
+//
+// Arguments are split like the run arguments; single quotes keep the
+// backslashes of regular expressions. Files are read and written in
+// their encoding, and keep their line endings unless converted.
+
+// batchCommands are the editor commands a batch script can run.
+var batchCommands = []string{"formatFile", "replace", "trimTrailingWhitespace", "convertToLF", "convertToCRLF"}
+
+// batchStep is a command of a batch script.
+type batchStep struct {
+	id   string
+	spec replaceSpec // Search and replacement of replace
+	line int         // Line of the script, for the errors
+}
+
+// parseBatchScript returns the commands of the batch script.
+func parseBatchScript(script string) ([]batchStep, error) {
+	var steps []batchStep
+	for n, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := splitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		step := batchStep{id: args[0], line: n + 1}
+		args = args[1:]
+		switch step.id {
+		case "replace":
+			if len(args) > 0 && args[0] == "--regex" {
+				step.spec.Regex = true
+				args = args[1:]
+			}
+			if len(args) != 2 {
+				return nil, fmt.Errorf("line %d: usage: replace [--regex] search replacement", n+1)
+			}
+			step.spec.Search, step.spec.Replace = args[0], args[1]
+			if _, err := step.spec.compile(); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		case "formatFile", "trimTrailingWhitespace", "convertToLF", "convertToCRLF":
+			if len(args) > 0 {
+				return nil, fmt.Errorf("line %d: %s takes no arguments", n+1, step.id)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unknown command %q, expected one of %s",
+				n+1, step.id, strings.Join(batchCommands, ", "))
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("no commands")
+	}
+	return steps, nil
+}
+
+// batchBuffer is a file being edited by a batch script.
+type batchBuffer struct {
+	path    string
+	text    string // With LF line breaks, as the editor holds it
+	eol     string
+	changes []string // What the commands did, for the report
+}
+
+// run applies step to b.
+func (b *batchBuffer) run(step batchStep) error {
+	switch step.id {
+	case "formatFile":
+		root := projectRoot(b.path)
+		s, err := readProjectSettings(root)
+		if err != nil {
+			return err
+		}
+		out, err := runFormatter(s, root, b.text)
+		if err != nil {
+			return err
+		}
+		if out != b.text {
+			b.text = out
+			b.changes = append(b.changes, "formatted")
+		}
+	case "replace":
+		out, n, err := replaceText(step.spec, b.text)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			b.text = out
+			b.changes = append(b.changes, fmt.Sprintf(replaceDoneFormat, n))
+		}
+	case "trimTrailingWhitespace":
+		lines := strings.Split(b.text, "\n")
+		trimmed := 0
+		for n, line := range lines {
+			if kept := trimTrailingBlanks(line); kept != line {
+				lines[n] = kept
+				trimmed++
+			}
+		}
+		if trimmed > 0 {
+			b.text = strings.Join(lines, "\n")
+			b.changes = append(b.changes, fmt.Sprintf("%d lines trimmed", trimmed))
+		}
+	case "convertToLF", "convertToCRLF":
+		eol := eolLF
+		if step.id == "convertToCRLF" {
+			eol = eolCRLF
+		}
+		if eol != b.eol {
+			b.eol = eol
+			b.changes = append(b.changes, "converted to "+eol)
+		}
+	}
+	return nil
+}
+
+// batchFile runs steps on the file at path and writes it back if they
+// changed it. It returns what they did.
+func batchFile(path string, steps []batchStep) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text, enc := decodeText(data)
+	b := &batchBuffer{path: path, text: normalizeEOL(text), eol: detectEOL(text)}
+	for _, step := range steps {
+		if err := b.run(step); err != nil {
+			return nil, fmt.Errorf("%s (script line %d): %w", step.id, step.line, err)
+		}
+	}
+	if len(b.changes) == 0 {
+		return nil, nil
+	}
+	out, err := encodeText(applyEOL(b.text, b.eol), enc)
+	if err != nil {
+		return nil, err
+	}
+	return b.changes, writeFileAtomic(path, out, info.Mode().Perm())
+}
+
+// runBatch runs the batch script at scriptPath on files, reporting on
+// stdout what it did to each and the errors on stderr. It returns the
+// exit status: 1 if a file failed, 2 if the script is invalid.
+func runBatch(scriptPath string, files []fileArg, stdout, stderr io.Writer) int {
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ite: %v\n", err)
+		return 2
+	}
+	steps, err := parseBatchScript(string(data))
+	if err != nil {
+		fmt.Fprintf(stderr, "ite: %s: %v\n", scriptPath, err)
+		return 2
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(stderr, "ite: --batch needs files to edit")
+		return 2
+	}
+	status := 0
+	for _, f := range files {
+		changes, err := batchFile(f.path, steps)
+		switch {
+		case err != nil:
+			fmt.Fprintf(stderr, "ite: %s: %v\n", f.path, err)
+			status = 1
+		case len(changes) == 0:
+			fmt.Fprintf(stdout, "%s: unchanged\n", f.path)
+		default:
+			fmt.Fprintf(stdout, "%s: %s\n", f.path, strings.Join(changes, ", "))
+		}
+	}
+	return status
+}
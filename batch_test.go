@@ -0,0 +1,60 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseBatchScript(t *testing.T) {
+	steps, err := parseBatchScript("# Tidy up\n\ntrimTrailingWhitespace\nreplace --regex 'x(\\d)' 'y$1'\nconvertToCRLF\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 3 || steps[1].id != "replace" || !steps[1].spec.Regex || steps[1].spec.Search != `x(\d)` || steps[1].line != 4 {
+		t.Errorf("parseBatchScript = %+v", steps)
+	}
+	for _, script := range []string{"", "save\n", "replace a\n", "replace --regex '(' b\n", "convertToLF now\n"} {
+		if _, err := parseBatchScript(script); err == nil {
+			t.Errorf("parseBatchScript(%q): no error", script)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fix.ite")
+	if err := os.WriteFile(script, []byte("replace --regex 'x(\\d)' 'y$1'\ntrimTrailingWhitespace\nconvertToLF\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edited, same := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(edited, []byte("x1 \r\nx2\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(same, []byte("z\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	files := []fileArg{{path: edited}, {path: same}, {path: filepath.Join(dir, "missing.txt")}}
+	if status := runBatch(script, files, &stdout, &stderr); status != 1 {
+		t.Errorf("status %d, want 1 for the missing file", status)
+	}
+	if data, _ := os.ReadFile(edited); string(data) != "y1\ny2\n" {
+		t.Errorf("a.txt = %q", data)
+	}
+	if info, _ := os.Stat(edited); info.Mode().Perm() != 0o600 {
+		t.Errorf("a.txt mode %v, want it kept", info.Mode().Perm())
+	}
+	want := edited + ": 2 replaced, 1 lines trimmed, converted to LF\n" + same + ": unchanged\n"
+	if stdout.String() != want {
+		t.Errorf("report %q, want %q", stdout.String(), want)
+	}
+	if !strings.Contains(stderr.String(), "missing.txt") {
+		t.Errorf("errors %q, want the missing file", stderr.String())
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Benchmarks
+// -------------------------------------------------------------------------
+
+// Go Benchmarks runs the benchmarks of the module. Each result line is
+// followed by a link offering to profile that benchmark alone, for CPU or
+// memory: the benchmark runs again with the profile flag, then pprof
+// serves the profile in the browser from the Run console. The results are
+// kept in the project's settings directory, and the report compares them
+// with those of the previous run, also in a table window.
+const (
+	tagProfile         = "profile" // Links of the benchmark report: profiling one, opening the table
+	profileLink        = "[profile]"
+	benchTableLink     = "[open as table]"
+	benchFileName      = "bench.json" // Results of the last run, in the project settings directory
+	benchNoise         = 5.0          // Change of ns/op, in percent, reported as faster or slower
+	profileBinary      = "bench.test" // Test binary kept for pprof, in the profile directory
+	statusBenchmarking = "Benchmarking...\n"
+	statusProfiling    = "Profiling...\n"
+)
+
+// benchResultRe matches the result line of a benchmark, e.g.
+// "BenchmarkCut-8   	 1000000	      1034 ns/op".
+var benchResultRe = regexp.MustCompile(`^Benchmark\S*\s+\d+\s+.*\bns/op\b`)
+
+// benchMetricsRe matches the measures of a benchmark printed apart from its
+// name, as test2json splits the result line in two events, e.g.
+// "1000000	      1034 ns/op	      16 B/op".
+var benchMetricsRe = regexp.MustCompile(`^\d+\s+[0-9.]+ ns/op\b`)
+
+// benchResult is the result of a benchmark.
+type benchResult struct {
+	Package  string  `json:"package"`
+	Name     string  `json:"name"`
+	NsOp     float64 `json:"nsOp"`
+	BytesOp  float64 `json:"bytesOp"`
+	AllocsOp float64 `json:"allocsOp"`
+}
+
+// key identifies the benchmark across runs.
+func (r benchResult) key() string {
+	return r.Package + " " + r.Name
+}
+
+// benchProfiles are the profiles a benchmark can be run with: the menu
+// label, the go test flag and the name of the profile file.
+var benchProfiles = []struct{ label, flag, file string }{
+	{"Profile CPU", "-cpuprofile", "cpu.out"},
+	{"Profile Mem", "-memprofile", "mem.out"},
+}
+
+// onGoBench runs the benchmarks of the module of the current file, and
+// none of its tests.
+func (i *Ite) onGoBench() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	d := newTestDecoder()
+	d.profile = i.showProfileMenu
+	d.benchPath = filepath.Join(i.projectDir(), projectConfigDir, benchFileName)
+	d.showBenches = i.showBenchTable
+	i.runCommand(i.console(consoleTest), []string{"test", "-json", "-fullpath", "-run", "^$", "-bench", ".", "-benchmem", "./..."},
+		statusBenchmarking, d)
+}
+
+// benchmarkResult reports the result line of a benchmark as soon as it
+// is printed, as benchmarks don't always end with a pass event, followed
+// by the link profiling it when the run offers one.
+func (d *testDecoder) benchmarkResult(ev testEvent) []consoleMsg {
+	if d.benchPath != "" {
+		d.benches = append(d.benches, parseBenchResult(ev.Package, ev.Test, ev.Output))
+	}
+	msgs := []consoleMsg{{text: ev.Output}}
+	if d.profile != nil {
+		profile, pkg, name := d.profile, ev.Package, ev.Test
+		msgs = append(msgs, consoleMsg{
+			text:  "    " + profileLink + "\n",
+			tag:   tagProfile,
+			click: func() { profile(pkg, name) },
+		})
+	}
+	return msgs
+}
+
+// showProfileMenu pops up, at the mouse pointer, the profiles benchmark
+// name of package pkg can be run with.
+func (i *Ite) showProfileMenu(pkg, name string) {
+	if i.profileMenu == nil {
+		i.profileMenu = Menu(Tearoff(false))
+	}
+	menu := i.profileMenu
+	tclEval("%s delete 0 end", menu)
+	for _, p := range benchProfiles {
+		menu.AddCommand(Lbl(p.label), Command(func() { i.profileBenchmark(pkg, name, p.flag, p.file) }))
+	}
+	xy := strings.Fields(tclEval("winfo pointerxy %s", App))
+	if len(xy) < 2 {
+		return
+	}
+	Popup(menu.Window, winfoInt(xy[0]), winfoInt(xy[1]), nil)
+}
+
+// profileBenchmark runs benchmark name of package pkg alone with the
+// profile flag writing file, then serves the profile with pprof. The
+// files of the previous profile are removed.
+func (i *Ite) profileBenchmark(pkg, name, flag, file string) {
+	i.removeProfileDir()
+	dir, err := os.MkdirTemp("", "ite-profile-")
+	if err != nil {
+		i.showError("Profile: " + err.Error())
+		return
+	}
+	i.profileDir = dir
+	bin, profile := filepath.Join(dir, profileBinary), filepath.Join(dir, file)
+	j := &job{c: i.console(consoleRun), decoder: logDecoder{}}
+	j.steps = []jobStep{
+		{name: "bench", args: []string{"go", "test", "-run", "^$", "-bench", benchmarkPattern(name),
+			"-o", bin, flag, profile, pkg}, required: true},
+		{name: "pprof", args: []string{"go", "tool", "pprof", "-http", "localhost:0", bin, profile}},
+	}
+	i.runJob(j, statusProfiling)
+}
+
+// benchmarkPattern returns the -bench pattern matching the benchmark name
+// alone, sub-benchmarks being matched level by level.
+func benchmarkPattern(name string) string {
+	parts := strings.Split(name, "/")
+	for n, part := range parts {
+		parts[n] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
+
+// removeProfileDir deletes the files of the last profile, if any.
+func (i *Ite) removeProfileDir() {
+	if i.profileDir != "" {
+		os.RemoveAll(i.profileDir)
+		i.profileDir = ""
+	}
+}
+
+// configureProfileTags sets up the console tag of the profile links.
+func (i *Ite) configureProfileTags(c *console) {
+	c.text.TagConfigure(tagProfile, Foreground(theme.Link), Underline(1))
+	c.text.TagBind(tagProfile, "<Button-1>", func() { i.onConsoleClick(c) })
+	c.text.TagBind(tagProfile, "<Enter>", func() {
+		c.text.Configure(Cursor("hand2"))
+	})
+	c.text.TagBind(tagProfile, "<Leave>", func() {
+		c.text.Configure(Cursor("xterm"))
+	})
+}
+
+// benchmarkLine returns the result line of a benchmark from output, an
+// output event of the benchmark with the given key, joining the measures
+// to the name printed before them when test2json split the line.
+func (d *testDecoder) benchmarkLine(key, output string) (string, bool) {
+	if benchResultRe.MatchString(output) {
+		return output, true
+	}
+	pending := d.output[key]
+	if !benchMetricsRe.MatchString(output) || len(pending) == 0 || strings.HasSuffix(pending[len(pending)-1], "\n") {
+		return "", false
+	}
+	line := pending[len(pending)-1] + output
+	if !benchResultRe.MatchString(line) {
+		return "", false
+	}
+	d.output[key] = pending[:len(pending)-1]
+	return line, true
+}
+
+// parseBenchResult reads the measures of the result line of benchmark name
+// of package pkg.
+func parseBenchResult(pkg, name, line string) benchResult {
+	r := benchResult{Package: pkg, Name: name}
+	fields := strings.Fields(line)
+	for n := 1; n < len(fields); n++ {
+		value, err := strconv.ParseFloat(fields[n-1], 64)
+		if err != nil {
+			continue
+		}
+		switch fields[n] {
+		case "ns/op":
+			r.NsOp = value
+		case "B/op":
+			r.BytesOp = value
+		case "allocs/op":
+			r.AllocsOp = value
+		}
+	}
+	return r
+}
+
+// benchmarkReport compares the benchmarks of the run with the previous
+// run, saves them for the next one and returns the comparison, followed
+// by the link showing it as a table.
+func (d *testDecoder) benchmarkReport() []consoleMsg {
+	if d.benchPath == "" || len(d.benches) == 0 {
+		return nil
+	}
+	previous := readBenchResults(d.benchPath)
+	width := 0
+	for _, r := range d.benches {
+		width = max(width, len(r.Name))
+	}
+
+	msgs := []consoleMsg{{text: "\nBenchmarks, ns/op against the previous run\n"}}
+	var rows [][]tableCell
+	faster, slower := 0, 0
+	for _, r := range d.benches {
+		msg := consoleMsg{text: fmt.Sprintf("%-*s %12.2f ns/op", width, r.Name, r.NsOp)}
+		change := tableCell{text: "new"}
+		before := tableCell{text: "-"}
+		if old, ok := previous[r.key()]; ok && old.NsOp > 0 {
+			delta := 100 * (r.NsOp - old.NsOp) / old.NsOp
+			change = tableCell{text: fmt.Sprintf("%+.1f%%", delta), value: delta}
+			before = tableCell{text: fmt.Sprintf("%.2f", old.NsOp), value: old.NsOp}
+			msg.text += fmt.Sprintf("  %+6.1f%%", delta)
+			switch {
+			case delta <= -benchNoise:
+				msg.tag = tagTestPass
+				faster++
+			case delta >= benchNoise:
+				msg.tag = tagTestFail
+				slower++
+			}
+		} else {
+			msg.text += "      new"
+		}
+		msg.text += "\n"
+		msgs = append(msgs, msg)
+		rows = append(rows, []tableCell{
+			{text: r.Name},
+			{text: r.Package},
+			{text: fmt.Sprintf("%.2f", r.NsOp), value: r.NsOp},
+			before,
+			change,
+			{text: fmt.Sprintf("%.0f", r.BytesOp), value: r.BytesOp},
+			{text: fmt.Sprintf("%.0f", r.AllocsOp), value: r.AllocsOp},
+		})
+		previous[r.key()] = r
+	}
+	status := fmt.Sprintf("%d benchmarks, %d faster and %d slower by %.0f%% or more", len(d.benches), faster, slower, benchNoise)
+	if show := d.showBenches; show != nil {
+		msgs = append(msgs, consoleMsg{text: benchTableLink + "\n", tag: tagProfile, click: func() { show(rows, status) }})
+	}
+	if err := writeBenchResults(d.benchPath, previous); err != nil {
+		msgs = append(msgs, consoleMsg{text: "Error saving the benchmark results: " + err.Error() + "\n", tag: tagTestFail})
+	}
+	return msgs
+}
+
+// benchColumns are the columns of the Benchmarks window.
+var benchColumns = []tableColumn{
+	{id: "name", heading: "Benchmark", width: 260},
+	{id: "package", heading: "Package", width: 200},
+	{id: "nsop", heading: "ns/op", numeric: true, width: 90},
+	{id: "previous", heading: "Previous", numeric: true, width: 90},
+	{id: "change", heading: "Change", numeric: true, width: 80},
+	{id: "bop", heading: "B/op", numeric: true, width: 80},
+	{id: "allocs", heading: "allocs/op", numeric: true, width: 80},
+}
+
+// showBenchTable shows the benchmark results of a run in the Benchmarks
+// window.
+func (i *Ite) showBenchTable(rows [][]tableCell, status string) {
+	if i.benchTable == nil {
+		i.benchTable = i.newTableWindow(benchColumns, 0, func() { i.benchTable = nil })
+	}
+	i.benchTable.show("Benchmarks", slices.Clone(rows), status)
+}
+
+// readBenchResults reads the results file at path, by benchmark key. A
+// missing or unreadable file yields no results.
+func readBenchResults(path string) map[string]benchResult {
+	var list []benchResult
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &list)
+	}
+	results := make(map[string]benchResult, len(list))
+	for _, r := range list {
+		results[r.key()] = r
+	}
+	return results
+}
+
+// writeBenchResults writes results to the results file at path, sorted
+// so the file diffs well.
+func writeBenchResults(path string, results map[string]benchResult) error {
+	list := make([]benchResult, 0, len(results))
+	for _, r := range results {
+		list = append(list, r)
+	}
+	slices.SortFunc(list, func(a, b benchResult) int { return strings.Compare(a.key(), b.key()) })
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(list, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), configFilePerms)
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Binary Size
+// -------------------------------------------------------------------------
+
+// Analyze Binary Size builds the command of the current file and sums the
+// sizes of the symbols go tool nm lists in the binary by package, showing
+// them in a table window. A successful Go Build of a command
+// offers it with a link.
+const (
+	tagBinarySize    = "binarysize" // Link analyzing the binary after a build
+	binarySizeLink   = "[analyze binary size]"
+	binarySizeBinary = "size.bin" // Binary analyzed, in a temporary directory
+	metadataPackage  = "(metadata)"
+	otherPackage     = "(other)"
+)
+
+// nmSymbolRe matches a symbol of the go tool nm -size listing, capturing
+// its size, kind and name, e.g. "  4b6b80       7898 T fmt.(*pp).printValue".
+// Names may hold spaces, as in "type:struct { a int }".
+var nmSymbolRe = regexp.MustCompile(`^\s*[0-9a-f]+\s+(\d+)\s+(\S)\s+(.+)$`)
+
+// packageSize is the share of a package in a binary.
+type packageSize struct {
+	pkg     string
+	size    int64 // Bytes of code and data stored in the file
+	symbols int
+}
+
+// binarySizeColumns are the columns of the Binary Size window.
+var binarySizeColumns = []tableColumn{
+	{id: "package", heading: "Package", width: 360},
+	{id: "size", heading: "Size", numeric: true, width: 90},
+	{id: "share", heading: "Share", numeric: true, width: 90},
+	{id: "symbols", heading: "Symbols", numeric: true, width: 90},
+}
+
+// buildDecoder shows the output of Go Build and, when the build of a
+// command printed nothing, which is how go build reports success, a link
+// analyzing the size of its binary.
+type buildDecoder struct {
+	analyze func() // Nil unless the current file belongs to a command
+	output  bool
+}
+
+func (d *buildDecoder) decode(line string) []consoleMsg {
+	d.output = true
+	return []consoleMsg{{text: line}}
+}
+
+func (d *buildDecoder) summary() []consoleMsg {
+	if d.output || d.analyze == nil {
+		return nil
+	}
+	return []consoleMsg{{text: binarySizeLink + "\n", tag: tagBinarySize, click: d.analyze}}
+}
+
+// isCommandFile reports whether src, the content of a Go file, belongs to
+// package main.
+func isCommandFile(path, src string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), path, src, parser.PackageClauseOnly)
+	return err == nil && f.Name.Name == "main"
+}
+
+// onAnalyzeBinarySize builds the command of the current file to a
+// temporary file in the background and shows the size of its packages.
+func (i *Ite) onAnalyzeBinarySize() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Analyze Binary Size needs a saved Go file.")
+		return
+	}
+	if !isCommandFile(i.currentFile, i.editText.Text()) {
+		i.showError("Analyze Binary Size needs a file of package main.")
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	dir := filepath.Dir(i.currentFile)
+	i.showStatusHint("Building " + filepath.Base(dir) + " for size analysis...")
+	go func() {
+		sizes, err := binaryPackageSizes(dir)
+		i.Dispatch(func() {
+			if err != nil {
+				i.showError("Analyze Binary Size: " + err.Error())
+				return
+			}
+			i.showBinarySize(dir, sizes)
+		})
+	}()
+}
+
+// binaryPackageSizes builds the command in dir and returns the size of its
+// packages, largest first.
+func binaryPackageSizes(dir string) ([]packageSize, error) {
+	tmp, err := os.MkdirTemp("", "ite-size-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	bin := filepath.Join(tmp, binarySizeBinary)
+	build := exec.Command("go", "build", "-o", bin, ".")
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		return nil, errors.New(strings.TrimSpace(string(out)))
+	}
+	nm := exec.Command("go", "tool", "nm", "-size", bin)
+	nm.Dir = dir
+	out, err := nm.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go tool nm: %v", err)
+	}
+
+	byPkg := make(map[string]*packageSize)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		m := nmSymbolRe.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		switch m[2] {
+		case "B", "b", "U": // Zeroed at startup or external, not in the file
+			continue
+		}
+		size, _ := strconv.ParseInt(m[1], 10, 64)
+		pkg := symbolPackage(m[3])
+		p := byPkg[pkg]
+		if p == nil {
+			p = &packageSize{pkg: pkg}
+			byPkg[pkg] = p
+		}
+		p.size += size
+		p.symbols++
+	}
+	sizes := make([]packageSize, 0, len(byPkg))
+	for _, p := range byPkg {
+		sizes = append(sizes, *p)
+	}
+	slices.SortFunc(sizes, func(a, b packageSize) int { return cmp.Compare(b.size, a.size) })
+	return sizes, nil
+}
+
+// symbolPackage returns the import path of the package defining symbol,
+// e.g. "fmt" for "fmt.(*pp).printValue" and "type:*net/http.Request".
+// The tables the linker generates are attributed to metadataPackage.
+func symbolPackage(symbol string) string {
+	if strings.HasPrefix(symbol, "go:") {
+		return metadataPackage
+	}
+	if rest, ok := strings.CutPrefix(symbol, "type:"); ok {
+		symbol = strings.TrimLeft(rest, "*")
+		if strings.HasPrefix(symbol, ".") || !strings.ContainsRune(symbol, '.') {
+			return metadataPackage // Unnamed and predeclared types
+		}
+	}
+	// The path ends at the first dot after its last slash, looking only
+	// before any receiver, type arguments or type literal
+	head := symbol
+	if n := strings.IndexAny(head, "([{ ,"); n >= 0 {
+		head = head[:n]
+	}
+	slash := strings.LastIndexByte(head, '/') + 1
+	dot := strings.IndexByte(head[slash:], '.')
+	if dot <= 0 {
+		return otherPackage
+	}
+	return symbol[:slash+dot]
+}
+
+// showBinarySize shows the package sizes of the command built in dir.
+func (i *Ite) showBinarySize(dir string, sizes []packageSize) {
+	if i.binarySize == nil {
+		i.binarySize = i.newTableWindow(binarySizeColumns, 1, func() { i.binarySize = nil })
+	}
+	var total int64
+	for _, s := range sizes {
+		total += s.size
+	}
+	rows := make([][]tableCell, 0, len(sizes))
+	for _, s := range sizes {
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(s.size) / float64(total)
+		}
+		rows = append(rows, []tableCell{
+			{text: s.pkg},
+			{text: formatBytes(s.size), value: float64(s.size)},
+			{text: fmt.Sprintf("%.1f%%", share), value: share},
+			{text: strconv.Itoa(s.symbols), value: float64(s.symbols)},
+		})
+	}
+	status := fmt.Sprintf("%d packages, %s of code and data", len(sizes), formatBytes(total))
+	i.binarySize.show("Binary Size - "+dir, rows, status)
+}
+
+// formatBytes returns n in bytes, KiB or MiB.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// configureBinarySizeTags sets up the console tag of the link analyzing
+// the binary.
+func (i *Ite) configureBinarySizeTags(c *console) {
+	c.text.TagConfigure(tagBinarySize, Foreground(theme.Link), Underline(1))
+	c.text.TagBind(tagBinarySize, "<Button-1>", func() { i.onConsoleClick(c) })
+	c.text.TagBind(tagBinarySize, "<Enter>", func() {
+		c.text.Configure(Cursor("hand2"))
+	})
+	c.text.TagBind(tagBinarySize, "<Leave>", func() {
+		c.text.Configure(Cursor("xterm"))
+	})
+}
@@ -0,0 +1,397 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Block Selection
+// -------------------------------------------------------------------------
+
+// Alt-drag and Alt-Shift with the arrow keys select a rectangle of text:
+// the same columns, counted with tabs expanded, on a run of lines. Typing
+// replaces the block on every line and leaves a cursor at the same column
+// on each, so aligned struct tags and table columns are edited at once;
+// BackSpace and Delete work the same way. Cut and copy take the block as
+// lines, and paste puts one line of the clipboard on each line of the
+// block, or the same text on all of them. Any other key or a click ends
+// the block.
+const (
+	blockBindTag   = "IteBlock"    // Bind tag handling keys while a block is selected
+	tagBlockCursor = "blockcursor" // Editor tag marking the column of an empty block
+)
+
+// blockSelection is a rectangular selection of an editor, between the
+// corner where it started and the one the cursor is on.
+type blockSelection struct {
+	text                  *TextWidget
+	anchorLine, anchorCol int // Columns with tabs expanded
+	line, col             int
+}
+
+// blockRow is the part of a line inside the block.
+type blockRow struct {
+	line     int
+	from, to string // Tk indices, equal on lines ending left of the block
+	short    bool   // The line ends left of the block
+}
+
+// bindBlockSelection installs the mouse and key bindings of block
+// selection, the bind tag going first to see keys before the editor.
+func (i *Ite) bindBlockSelection() {
+	addBindtag(i.editText.Window, blockBindTag, "")
+	Bind(i.editText, "<Alt-Button-1>", Command(func(e *Event) {
+		Focus(i.editText)
+		i.startBlock(mouseIndex(i.editText, e))
+		e.SetReturnCodeBreak()
+	}))
+	Bind(i.editText, "<Alt-B1-Motion>", Command(func(e *Event) {
+		if b := i.activeBlock(); b != nil {
+			b.line, b.col = i.visualIndex(mouseIndex(i.editText, e))
+			i.showBlock()
+		}
+		e.SetReturnCodeBreak()
+	}))
+	for key, step := range map[string][2]int{
+		"<Alt-Shift-Up>":    {-1, 0},
+		"<Alt-Shift-Down>":  {1, 0},
+		"<Alt-Shift-Left>":  {0, -1},
+		"<Alt-Shift-Right>": {0, 1},
+	} {
+		Bind(blockBindTag, key, Command(func(e *Event) {
+			i.extendBlock(step[0], step[1])
+			e.SetReturnCodeBreak()
+		}))
+	}
+	Bind(blockBindTag, "<ButtonPress-1>", Command(func(e *Event) {
+		if e.State&ModifierAlt == 0 {
+			i.endBlock()
+		}
+	}))
+	Bind(blockBindTag, "<KeyPress>", Command(func(e *Event) {
+		if i.activeBlock() != nil && i.onBlockKey(e) {
+			e.SetReturnCodeBreak()
+		}
+	}))
+	for event, run := range map[string]func(){
+		"<<Cut>>":   i.cutBlock,
+		"<<Copy>>":  i.copyBlock,
+		"<<Paste>>": i.pasteBlock,
+	} {
+		Bind(blockBindTag, event, Command(func(e *Event) {
+			if i.activeBlock() != nil {
+				run()
+				e.SetReturnCodeBreak()
+			}
+		}))
+	}
+}
+
+// activeBlock returns the block selection of the active editor, or nil.
+func (i *Ite) activeBlock() *blockSelection {
+	if i.block != nil && i.block.text == i.editText {
+		return i.block
+	}
+	return nil
+}
+
+// startBlock starts an empty block at index.
+func (i *Ite) startBlock(index string) {
+	line, col := i.visualIndex(index)
+	i.block = &blockSelection{text: i.editText, anchorLine: line, anchorCol: col, line: line, col: col}
+	i.showBlock()
+}
+
+// extendBlock moves the cursor corner of the block by lines and columns,
+// starting a block at the cursor if there is none.
+func (i *Ite) extendBlock(lines, cols int) {
+	b := i.activeBlock()
+	if b == nil {
+		i.startBlock(i.editText.Index("insert"))
+		b = i.block
+	}
+	last, _ := parseIndex(i.editText.Index("end-1c"))
+	b.line = min(max(b.line+lines, 1), last)
+	b.col = max(b.col+cols, 0)
+	i.showBlock()
+}
+
+// endBlock ends the block selection, leaving the text selected as is.
+func (i *Ite) endBlock() {
+	if i.block != nil {
+		i.block.text.TagRemove(tagBlockCursor, "1.0", "end")
+		i.block = nil
+		i.updateCursorPosition()
+	}
+}
+
+// bounds returns the lines and columns the block spans.
+func (b *blockSelection) bounds() (top, bottom, left, right int) {
+	return min(b.anchorLine, b.line), max(b.anchorLine, b.line),
+		min(b.anchorCol, b.col), max(b.anchorCol, b.col)
+}
+
+// blockRows returns the parts of the lines inside the block, top first.
+func (i *Ite) blockRows() []blockRow {
+	top, bottom, left, right := i.block.bounds()
+	var rows []blockRow
+	for line := top; line <= bottom; line++ {
+		runes := []rune(lineText(i.editText, line))
+		from, short := runeAtColumn(runes, left)
+		to, _ := runeAtColumn(runes, right)
+		rows = append(rows, blockRow{
+			line:  line,
+			from:  fmt.Sprintf("%d.%d", line, from),
+			to:    fmt.Sprintf("%d.%d", line, to),
+			short: short,
+		})
+	}
+	return rows
+}
+
+// showBlock selects the block and puts the cursor on its moving corner.
+func (i *Ite) showBlock() {
+	i.editText.TagRemove("sel", "1.0", "end")
+	i.editText.TagRemove(tagBlockCursor, "1.0", "end")
+	for _, r := range i.blockRows() {
+		switch {
+		case r.from != r.to:
+			i.editText.TagAdd("sel", r.from, r.to)
+		case !r.short:
+			i.editText.TagAdd(tagBlockCursor, r.from, r.from+" +1c")
+		}
+	}
+	runes := []rune(lineText(i.editText, i.block.line))
+	col, _ := runeAtColumn(runes, i.block.col)
+	i.editText.MarkSet("insert", fmt.Sprintf("%d.%d", i.block.line, col))
+	i.editText.See("insert")
+	i.updateCursorPosition()
+}
+
+// visualIndex returns the line of index and its column with tabs expanded.
+func (i *Ite) visualIndex(index string) (line, col int) {
+	line, char := parseIndex(i.editText.Index(index))
+	runes := []rune(lineText(i.editText, line))
+	return line, visualColumn(runes[:min(char, len(runes))])
+}
+
+// visualColumn returns the width of runes with tabs expanded to the next
+// tab stop.
+func visualColumn(runes []rune) int {
+	col := 0
+	for _, r := range runes {
+		if r == '\t' {
+			col += tabWidth - col%tabWidth
+		} else {
+			col++
+		}
+	}
+	return col
+}
+
+// runeAtColumn returns the index of the first rune of runes at or past the
+// column col with tabs expanded, and whether the line ends before col.
+func runeAtColumn(runes []rune, col int) (int, bool) {
+	for n := range runes {
+		if visualColumn(runes[:n]) >= col {
+			return n, false
+		}
+	}
+	return len(runes), visualColumn(runes) < col
+}
+
+// onBlockKey handles a key pressed while a block is selected, reporting
+// whether it was used.
+func (i *Ite) onBlockKey(e *Event) bool {
+	if e.State&(ModifierControl|ModifierAlt) != 0 || i.composing {
+		return false
+	}
+	if i.modal.mode != "" && i.modal.mode != modeInsert {
+		i.endBlock() // Normal and visual mode keys are commands
+		return false
+	}
+	switch e.Keysym {
+	case "Escape":
+		i.endBlock()
+		i.editText.TagRemove("sel", "1.0", "end")
+		return true
+	case "BackSpace", "Delete":
+		i.deleteInBlock(e.Keysym == "BackSpace")
+		return true
+	case "Tab":
+		i.typeInBlock("\t")
+		return true
+	}
+	kind, ok := classifyKey(e.Keysym, i.isWordChar)
+	if !ok {
+		return false // Modifier keys, which may start a shortcut
+	}
+	r, isRune := keysymRune(e.Keysym)
+	if kind == editNone || !isRune {
+		i.endBlock() // Motion and Return leave the block
+		return false
+	}
+	i.typeInBlock(string(r))
+	return true
+}
+
+// blockRowsProtected reports whether changing any of rows touches a
+// read-only region, showing a hint if so.
+func (i *Ite) blockRowsProtected(rows []blockRow) bool {
+	for _, r := range rows {
+		if i.blockProtected(r.from, r.to) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeInBlock replaces the block with s on every line reaching it and
+// leaves an empty block after s.
+func (i *Ite) typeInBlock(s string) {
+	rows := i.blockRows()
+	if i.blockRowsProtected(rows) {
+		return
+	}
+	i.editGroup(func() {
+		for _, r := range rows {
+			if r.short {
+				continue
+			}
+			i.editText.Delete(r.from, r.to)
+			i.editText.Insert(r.from, s)
+		}
+	})
+	_, _, left, _ := i.block.bounds()
+	col := visualColumn([]rune(strings.Repeat(" ", left) + s))
+	i.block.anchorCol, i.block.col = col, col
+	i.refreshCursorState()
+	i.showBlock()
+}
+
+// deleteInBlock deletes the block or, if it is empty, the character
+// before (backward) or after it on every line.
+func (i *Ite) deleteInBlock(backward bool) {
+	rows := i.blockRows()
+	_, _, left, right := i.block.bounds()
+	if left == right {
+		for n, r := range rows {
+			if backward {
+				line, col := parseIndex(r.from)
+				if r.short || col == 0 {
+					rows[n].to = rows[n].from
+					continue
+				}
+				rows[n].from = fmt.Sprintf("%d.%d", line, col-1)
+			} else if !r.short {
+				rows[n].to = i.editText.Index(r.from + " +1c")
+				if line, _ := parseIndex(rows[n].to); line != r.line {
+					rows[n].to = r.from // Keep the line ending
+				}
+			}
+		}
+	}
+	if i.blockRowsProtected(rows) {
+		return
+	}
+	col := left
+	if left == right && backward && len(rows) > 0 {
+		// Back up to where the deleted character started on the cursor line
+		for _, r := range rows {
+			if r.line == i.block.line && r.from != r.to {
+				runes := []rune(lineText(i.editText, r.line))
+				_, char := parseIndex(r.from)
+				col = visualColumn(runes[:char])
+			}
+		}
+	}
+	i.editGroup(func() {
+		for _, r := range rows {
+			if r.from != r.to {
+				i.editText.Delete(r.from, r.to)
+			}
+		}
+	})
+	i.block.anchorCol, i.block.col = col, col
+	i.refreshCursorState()
+	i.showBlock()
+}
+
+// blockText returns the text of the block, a line per line of the block.
+func (i *Ite) blockText() string {
+	var lines []string
+	for _, r := range i.blockRows() {
+		lines = append(lines, i.editText.Get(r.from, r.to)[0])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// copyBlock puts the text of the block on the clipboard.
+func (i *Ite) copyBlock() {
+	ClipboardClear()
+	ClipboardAppend(i.blockText())
+}
+
+// cutBlock puts the text of the block on the clipboard and deletes it.
+func (i *Ite) cutBlock() {
+	if i.blockRowsProtected(i.blockRows()) {
+		return
+	}
+	i.copyBlock()
+	_, _, left, right := i.block.bounds()
+	if left != right {
+		i.deleteInBlock(false)
+	}
+}
+
+// pasteBlock replaces the block with the clipboard: its first line on the
+// first line of the block and so on, down past the block if the clipboard
+// has more lines, adding lines at the end of the buffer if needed, which
+// ends the block, or its only line on every line of the block.
+func (i *Ite) pasteBlock() {
+	text, _ := readClipboard()
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) == 1 {
+		i.typeInBlock(lines[0])
+		return
+	}
+	b := i.block
+	top, _, left, right := b.bounds()
+	last, _ := parseIndex(i.editText.Index("end-1c"))
+	bottom := top + len(lines) - 1
+	b.anchorLine, b.line = top, min(bottom, last)
+	if i.blockRowsProtected(i.blockRows()) {
+		return
+	}
+	i.editGroup(func() {
+		if bottom > last {
+			i.editText.Insert("end-1c", strings.Repeat("\n", bottom-last))
+			b.line = bottom
+		}
+		for n, r := range i.blockRows() {
+			from := r.from
+			if r.short {
+				// Pad the line out to the block
+				runes := []rune(lineText(i.editText, r.line))
+				pad := strings.Repeat(" ", left-visualColumn(runes))
+				i.editText.Insert(r.from, pad)
+				from = i.editText.Index(fmt.Sprintf("%s +%dc", r.from, len([]rune(pad))))
+			} else if left != right {
+				i.editText.Delete(r.from, r.to)
+			}
+			i.editText.Insert(from, lines[n])
+			if n == 0 {
+				i.editText.MarkSet("insert", fmt.Sprintf("%s +%dc", from, len([]rune(lines[n]))))
+			}
+		}
+	})
+	i.endBlock()
+	i.editText.TagRemove("sel", "1.0", "end")
+	i.refreshCursorState()
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Bookmarks
+// -------------------------------------------------------------------------
+
+// Bookmarks are text marks at the start of their lines, so they follow
+// the edits above them. The bookmarked lines of each file are kept in the
+// session store whenever they are toggled or the file is saved.
+const (
+	bookmarkPrefix = "bookmark" // Prefix of the names of bookmark marks
+	tagBookmark    = "bookmark" // Editor tag of bookmarked lines
+)
+
+// configureBookmarkTag sets up the gutter marker of bookmarked lines,
+// which wins over the change markers.
+func (i *Ite) configureBookmarkTag() {
+	i.editText.TagConfigure(tagBookmark, append(gutterMargin(), Lmargincolor(theme.Warning))...)
+}
+
+// bookmarkMarks returns the names of the bookmark marks of the active
+// buffer.
+func (i *Ite) bookmarkMarks() []string {
+	var marks []string
+	for _, name := range i.editText.MarkNames() {
+		if strings.HasPrefix(name, bookmarkPrefix) {
+			marks = append(marks, name)
+		}
+	}
+	return marks
+}
+
+// bookmarkLines returns the bookmarked lines of the active buffer in
+// ascending order, without duplicates.
+func (i *Ite) bookmarkLines() []int {
+	var lines []int
+	for _, name := range i.bookmarkMarks() {
+		line, _ := parseIndex(i.editText.Index(name))
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
+
+// addBookmark bookmarks line of the active buffer.
+func (i *Ite) addBookmark(line int) {
+	i.bookmarkSeq++
+	name := fmt.Sprintf("%s%d", bookmarkPrefix, i.bookmarkSeq)
+	i.editText.MarkSet(name, fmt.Sprintf("%d.0", line))
+	i.editText.MarkGravity(name, "left")
+}
+
+// clearBookmarks removes the bookmarks of the active buffer, before it
+// gets another file.
+func (i *Ite) clearBookmarks() {
+	if marks := i.bookmarkMarks(); len(marks) > 0 {
+		i.editText.MarkUnset(marks...)
+	}
+	i.editText.TagRemove(tagBookmark, "1.0", "end")
+}
+
+// restoreBookmarks sets the bookmarks stored for the current file.
+func (i *Ite) restoreBookmarks() {
+	last, _ := parseIndex(i.editText.Index("end-1c"))
+	for _, line := range i.session.Bookmarks[i.currentFile] {
+		if line <= last {
+			i.addBookmark(line)
+		}
+	}
+	i.markBookmarks()
+}
+
+// storeBookmarks records the bookmarks of the current file in the session
+// store.
+func (i *Ite) storeBookmarks() {
+	if i.currentFile == "" || i.largeFile {
+		return
+	}
+	lines := i.bookmarkLines()
+	if slices.Equal(lines, i.session.Bookmarks[i.currentFile]) {
+		return
+	}
+	if len(lines) == 0 {
+		delete(i.session.Bookmarks, i.currentFile)
+	} else {
+		i.session.Bookmarks[i.currentFile] = lines
+	}
+	if err := i.session.save(); err != nil {
+		i.showError("Error saving bookmarks: " + err.Error())
+	}
+}
+
+// markBookmarks draws the gutter markers of the bookmarked lines.
+func (i *Ite) markBookmarks() {
+	i.editText.TagRemove(tagBookmark, "1.0", "end")
+	for _, line := range i.bookmarkLines() {
+		i.editText.TagAdd(tagBookmark, fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.0", line+1))
+	}
+}
+
+// onToggleBookmark bookmarks the cursor line, or removes its bookmark.
+func (i *Ite) onToggleBookmark() {
+	line, _ := parseIndex(i.editText.Index("insert"))
+	removed := false
+	for _, name := range i.bookmarkMarks() {
+		if l, _ := parseIndex(i.editText.Index(name)); l == line {
+			i.editText.MarkUnset(name)
+			removed = true
+		}
+	}
+	if !removed {
+		i.addBookmark(line)
+	}
+	i.markBookmarks()
+	i.storeBookmarks()
+}
+
+// onNextBookmark moves the cursor to the next bookmark, wrapping around
+// at the end of the buffer.
+func (i *Ite) onNextBookmark() {
+	i.jumpBookmark(1)
+}
+
+// onPreviousBookmark moves the cursor to the previous bookmark, wrapping
+// around at the start of the buffer.
+func (i *Ite) onPreviousBookmark() {
+	i.jumpBookmark(-1)
+}
+
+// jumpBookmark moves the cursor to the bookmark after the cursor line in
+// direction dir, 1 or -1.
+func (i *Ite) jumpBookmark(dir int) {
+	lines := i.bookmarkLines()
+	if len(lines) == 0 {
+		i.showStatusHint("No bookmarks")
+		return
+	}
+	if dir < 0 {
+		slices.Reverse(lines)
+	}
+	cur, _ := parseIndex(i.editText.Index("insert"))
+	target := lines[0] // Wrap around
+	for _, line := range lines {
+		if (line-cur)*dir > 0 {
+			target = line
+			break
+		}
+	}
+	i.editText.MarkSet("insert", fmt.Sprintf("%d.0", target))
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// onListBookmarks opens a window listing the bookmarks of every file,
+// the current one first, with the text of their lines. Clicking one
+// jumps to it.
+func (i *Ite) onListBookmarks() {
+	i.storeBookmarks()
+	var locs []location
+	var texts []string
+	for _, line := range i.bookmarkLines() {
+		locs = append(locs, location{path: i.currentFile, line: line, col: 1})
+		texts = append(texts, strings.TrimSpace(lineText(i.editText, line)))
+	}
+	var paths []string
+	for path := range i.session.Bookmarks {
+		if !samePath(path, i.currentFile) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		for _, line := range i.session.Bookmarks[path] {
+			locs = append(locs, location{path: path, line: line, col: 1})
+			texts = append(texts, "")
+		}
+	}
+	if len(locs) == 0 {
+		i.showStatusHint("No bookmarks")
+		return
+	}
+
+	dialog := Toplevel()
+	dialog.WmTitle("Bookmarks")
+	list := dialog.Text(textStyle(), Width(80), Height(min(len(locs), 20)), Wrap("none"))
+	scrollbar := dialog.TScrollbar(Command(func(e *Event) { e.Yview(list) }))
+	list.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(list, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+
+	for n, loc := range locs {
+		if n > 0 {
+			list.Insert("end", "\n")
+		}
+		name := filepath.Base(loc.path)
+		if loc.path == "" {
+			name = "Untitled"
+		}
+		list.Insert("end", fmt.Sprintf("%s:%d", name, loc.line), tagLink)
+		if texts[n] != "" {
+			list.Insert("end", "  "+texts[n])
+		}
+	}
+	list.Configure(State("disabled"))
+
+	jump := func(line int) {
+		if line < 1 || line > len(locs) {
+			return
+		}
+		Destroy(dialog)
+		if loc := locs[line-1]; loc.path == i.currentFile {
+			i.editText.MarkSet("insert", fmt.Sprintf("%d.0", loc.line))
+			i.editText.See("insert")
+			i.refreshCursorState()
+			Focus(i.editText)
+		} else {
+			i.showLocation(loc)
+		}
+	}
+	list.TagConfigure(tagLink, Foreground(theme.Link))
+	list.TagBind(tagLink, "<Button-1>", func() {
+		line, _ := parseIndex(list.Index("current"))
+		jump(line)
+	})
+	list.TagBind(tagLink, "<Enter>", func() { list.Configure(Cursor("hand2")) })
+	list.TagBind(tagLink, "<Leave>", func() { list.Configure(Cursor("xterm")) })
+	bindPanelKeys(list, jump)
+	Bind(dialog, "<Escape>", Command(func() { Destroy(dialog) }))
+	Focus(list)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Bracket Matching
+// -------------------------------------------------------------------------
+
+const tagBracket = "bracket" // Editor tag highlighting a matching bracket pair
+
+// bracketPos is the text position of a bracket: 1-based line and
+// character column.
+type bracketPos struct {
+	line, col int
+}
+
+func (p bracketPos) index() string { return fmt.Sprintf("%d.%d", p.line, p.col) }
+
+// matchingBrackets returns the pairs of matching (), [] and {} in src, in
+// both directions. Brackets in strings, runes and comments are not tokens,
+// so the Go scanner skips them; unbalanced ones are left out.
+func matchingBrackets(src string) map[bracketPos]bracketPos {
+	lines := strings.Split(src, "\n")
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0) // Syntax errors don't stop the scan
+
+	opening := map[token.Token]token.Token{token.RPAREN: token.LPAREN, token.RBRACK: token.LBRACK, token.RBRACE: token.LBRACE}
+	type open struct {
+		tok token.Token
+		pos bracketPos
+	}
+	var stack []open
+	pairs := make(map[bracketPos]bracketPos)
+	for {
+		p, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE, token.RPAREN, token.RBRACK, token.RBRACE:
+		default:
+			continue
+		}
+		pos := file.Position(p)
+		bp := bracketPos{pos.Line, runeColumn(lines[pos.Line-1], pos.Column-1)}
+		if _, closing := opening[tok]; !closing {
+			stack = append(stack, open{tok, bp})
+			continue
+		}
+		// Pop up to the matching opener, dropping unclosed ones in between
+		for n := len(stack) - 1; n >= 0; n-- {
+			if stack[n].tok == opening[tok] {
+				pairs[stack[n].pos] = bp
+				pairs[bp] = stack[n].pos
+				stack = stack[:n]
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// bracketAtCursor returns the bracket next to the cursor, preferring the
+// one right after it, and its match.
+func (i *Ite) bracketAtCursor() (at, match bracketPos, ok bool) {
+	line, col := parseIndex(i.editText.Index("insert"))
+	pairs := matchingBrackets(i.editText.Get("1.0", "end-1c")[0])
+	for _, c := range []int{col, col - 1} {
+		at = bracketPos{line, c}
+		if match, ok = pairs[at]; ok {
+			return at, match, true
+		}
+	}
+	return at, match, false
+}
+
+// updateBracketMatch highlights the bracket next to the cursor together
+// with its match.
+func (i *Ite) updateBracketMatch() {
+	i.editText.TagRemove(tagBracket, "1.0", "end")
+	if i.largeFile {
+		return // Matching scans the whole buffer
+	}
+	at, match, ok := i.bracketAtCursor()
+	if !ok {
+		return
+	}
+	for _, p := range []bracketPos{at, match} {
+		i.editText.TagAdd(tagBracket, p.index(), p.index()+" +1c")
+	}
+}
+
+// onJumpToMatchingBracket moves the cursor onto the bracket matching the
+// one next to it.
+func (i *Ite) onJumpToMatchingBracket() {
+	_, match, ok := i.bracketAtCursor()
+	if !ok {
+		return
+	}
+	i.editText.TagRemove("sel", "1.0", "end")
+	i.editText.MarkSet("insert", match.index())
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
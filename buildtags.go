@@ -0,0 +1,167 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"maps"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Build Tags
+// -------------------------------------------------------------------------
+
+// When the current file has a //go:build constraint, the status bar shows
+// a menu of its tags. The tags checked there are passed with -tags to Go
+// Build, Go Run and Go Test, so code guarded by a platform or integration
+// tag compiles without editing the project settings. The choice lasts for
+// the session and is kept while switching files.
+const maxConstraintLines = 50 // Lines looked at for //go:build before the package clause
+
+// buildTagState holds the tags enabled for the commands.
+type buildTagState struct {
+	enabled map[string]bool // Only the enabled tags are present
+	file    string          // File the tags of the segment were read for
+	tags    []string
+	segment *MenubuttonWidget
+	menu    *MenuWidget
+	vars    map[string]*VariableOpt // Check state of the tags of the menu
+}
+
+// fileBuildTags returns the tags, sorted, of the //go:build constraint of
+// the Go source src, nil if it has none.
+func fileBuildTags(src string) []string {
+	lines := strings.SplitN(src, "\n", maxConstraintLines+1)
+	found := make(map[string]bool)
+	for _, line := range lines[:min(len(lines), maxConstraintLines)] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if !constraint.IsGoBuild(line) {
+			continue
+		}
+		expr, err := constraint.Parse(line)
+		if err != nil {
+			continue
+		}
+		expr.Eval(func(tag string) bool {
+			found[tag] = true
+			return true
+		})
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(found))
+}
+
+// buildTagsFlag returns the -tags flag for the tags enabled, "" if none.
+func buildTagsFlag(enabled map[string]bool) string {
+	if len(enabled) == 0 {
+		return ""
+	}
+	return "-tags=" + strings.Join(slices.Sorted(maps.Keys(enabled)), ",")
+}
+
+// tagsArgs returns the arguments passing the enabled tags to a go command.
+func (i *Ite) tagsArgs() []string {
+	if flag := buildTagsFlag(i.buildTags.enabled); flag != "" {
+		return []string{flag}
+	}
+	return nil
+}
+
+// makeBuildTagsMenu adds the build tags segment to the status bar.
+func (i *Ite) makeBuildTagsMenu() {
+	i.buildTags.segment = i.statusMenu("buildTags")
+	i.buildTags.menu = i.buildTags.segment.Menu(Postcommand(i.fillBuildTagsMenu))
+	i.buildTags.segment.Configure(Mnu(i.buildTags.menu))
+}
+
+// updateBuildTagsSegment shows the enabled tags, or "tags" if only the
+// current file has some, reading the file only when it changes.
+func (i *Ite) updateBuildTagsSegment() {
+	if i.currentFile != i.buildTags.file {
+		i.buildTags.file = i.currentFile
+		i.readBuildTags()
+	}
+	text := buildTagsFlag(i.buildTags.enabled)
+	if text == "" && len(i.buildTags.tags) > 0 {
+		text = "tags"
+	}
+	i.buildTags.segment.Configure(Txt(text))
+}
+
+// readBuildTags reads the tags of the constraint of the current buffer.
+func (i *Ite) readBuildTags() {
+	i.buildTags.tags = nil
+	if i.currentFile != "" {
+		i.buildTags.tags = fileBuildTags(i.editText.Get("1.0", fmt.Sprintf("%d.0", maxConstraintLines+1))[0])
+	}
+}
+
+// fillBuildTagsMenu lists the tags of the current file, as it is being
+// edited, and those enabled for other files.
+func (i *Ite) fillBuildTagsMenu() {
+	menu := i.buildTags.menu
+	tclEval("%s delete 0 end", menu)
+	i.readBuildTags()
+	i.updateBuildTagsSegment()
+	tags := slices.Clone(i.buildTags.tags)
+	for tag := range i.buildTags.enabled {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	if i.buildTags.vars == nil {
+		i.buildTags.vars = make(map[string]*VariableOpt)
+	}
+	for _, tag := range tags {
+		v := i.buildTags.vars[tag]
+		if v == nil {
+			v = Variable(checkValue(false))
+			i.buildTags.vars[tag] = v
+		}
+		v.Set(checkValue(i.buildTags.enabled[tag]))
+		entry := menu.AddCheckbutton(Lbl(tag), Command(func() { i.toggleBuildTag(tag) }))
+		menu.EntryConfigure(entry, v)
+	}
+	if len(tags) == 0 {
+		menu.AddCommand(Lbl("No build tags in this file"), State("disabled"))
+		return
+	}
+	menu.AddSeparator()
+	i.addMenuCommand(menu, "clearBuildTags", "")
+}
+
+// toggleBuildTag adds tag to the -tags of the commands, or removes it.
+func (i *Ite) toggleBuildTag(tag string) {
+	if i.buildTags.enabled == nil {
+		i.buildTags.enabled = make(map[string]bool)
+	}
+	i.buildTags.enabled[tag] = !i.buildTags.enabled[tag]
+	if !i.buildTags.enabled[tag] {
+		delete(i.buildTags.enabled, tag)
+	}
+	i.updateBuildTagsSegment()
+	if flag := buildTagsFlag(i.buildTags.enabled); flag != "" {
+		i.showStatusHint("Build, Run and Test use " + flag)
+	} else {
+		i.showStatusHint("Build, Run and Test use no build tags")
+	}
+}
+
+// onClearBuildTags stops passing build tags to the commands.
+func (i *Ite) onClearBuildTags() {
+	clear(i.buildTags.enabled)
+	i.updateBuildTagsSegment()
+	i.showStatusHint("Build, Run and Test use no build tags")
+}
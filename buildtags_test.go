@@ -0,0 +1,36 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFileBuildTags(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{"//go:build integration && !windows\n\npackage p\n", []string{"integration", "windows"}},
+		{"// Copyright.\n\n//go:build (linux || darwin) && cgo\n\npackage p\n", []string{"cgo", "darwin", "linux"}},
+		{"package p\n\n//go:build ignore\n", nil},
+		{"// +build linux\n\npackage p\n", nil},
+		{"package p\n", nil},
+	}
+	for _, tt := range tests {
+		if got := fileBuildTags(tt.src); !slices.Equal(got, tt.want) {
+			t.Errorf("fileBuildTags(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTagsFlag(t *testing.T) {
+	if flag := buildTagsFlag(nil); flag != "" {
+		t.Errorf("buildTagsFlag(nil) = %q", flag)
+	}
+	if flag := buildTagsFlag(map[string]bool{"linux": true, "integration": true}); flag != "-tags=integration,linux" {
+		t.Errorf("buildTagsFlag = %q, want -tags=integration,linux", flag)
+	}
+}
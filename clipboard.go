@@ -0,0 +1,335 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Clipboard Paste
+// -------------------------------------------------------------------------
+
+const pasteBindTag = "ItePaste" // Bind tag replacing the editor's <<Paste>>
+
+// Clipboard targets offered by X11 file managers and rich text editors.
+const (
+	targetURIList     = "text/uri-list"
+	targetGnomeFiles  = "x-special/gnome-copied-files"
+	targetHTML        = "text/html"
+	targetUTF8        = "UTF8_STRING"
+	targetDefaultText = "STRING"
+)
+
+// htmlTagRe matches an HTML tag or comment.
+var htmlTagRe = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+
+// htmlBreakRe matches the HTML tags that end a line of text.
+var htmlBreakRe = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6]|tr|pre)>`)
+
+// bindPaste routes the editor's <<Paste>> through pasteClipboard and its
+// <<PasteSelection>> (middle-click) through pasteSelection. The bind tag
+// sits right before the Text class, after the read-only and undo checks.
+func (i *Ite) bindPaste() {
+	addBindtag(i.editText.Window, pasteBindTag, "Text")
+	Bind(pasteBindTag, "<<Paste>>", Command(func(e *Event) {
+		i.editGroup(i.pasteClipboard)
+		e.SetReturnCodeBreak()
+	}))
+	Bind(pasteBindTag, "<<PasteSelection>>", Command(func(e *Event) {
+		i.pasteSelection(e)
+		e.SetReturnCodeBreak()
+	}))
+}
+
+// pasteClipboard inserts the clipboard at the cursor. A list of files
+// copied in a file manager can be opened instead of pasted as paths, and
+// rich text with no plain text alternative is pasted without its markup.
+func (i *Ite) pasteClipboard() {
+	text, files := readClipboard()
+	if len(files) > 0 {
+		if fi, err := os.Stat(files[0]); err == nil && fi.Mode().IsRegular() {
+			detail := "Yes opens " + i.displayPath(files[0])
+			if len(files) > 1 {
+				detail += fmt.Sprintf(" (the other %d files are ignored)", len(files)-1)
+			}
+			detail += ", No pastes the paths as text."
+			resp := messageBox(Icon("question"), Title("Paste Files"),
+				Msg("The clipboard holds a list of files. Open it?"),
+				Detail(detail), Type("yesnocancel"))
+			switch resp {
+			case "yes":
+				if !i.promptSaveIfModified() {
+					return
+				}
+				if err := i.openFile(files[0]); err != nil {
+					i.showError("Error opening file: " + err.Error())
+				}
+				return
+			case "cancel":
+				return
+			}
+		}
+		text = strings.Join(files, "\n")
+	}
+	if text == "" {
+		return
+	}
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		i.editText.Delete(sel[0], sel[len(sel)-1])
+	}
+	i.editText.Insert("insert", text)
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// readClipboard returns the clipboard contents as plain text, or as the
+// list of paths when it holds files copied in a file manager.
+func readClipboard() (text string, files []string) {
+	var targets []string
+	if tclEval("tk windowingsystem") == "x11" {
+		targets = strings.Fields(clipboardGet("TARGETS"))
+	}
+	switch {
+	case slices.Contains(targets, targetGnomeFiles):
+		// First line is the operation, "copy" or "cut"
+		_, uris, _ := strings.Cut(clipboardGet(targetGnomeFiles), "\n")
+		if files = fileURIs(uris); len(files) > 0 {
+			return "", files
+		}
+	case slices.Contains(targets, targetURIList):
+		if files = fileURIs(clipboardGet(targetURIList)); len(files) > 0 {
+			return "", files
+		}
+	}
+
+	switch {
+	case slices.Contains(targets, targetUTF8):
+		text = clipboardGet(targetUTF8)
+	case len(targets) == 0 || slices.Contains(targets, targetDefaultText):
+		text = clipboardGet(targetDefaultText)
+	}
+	if text == "" && slices.Contains(targets, targetHTML) {
+		text = htmlToText(clipboardGet(targetHTML))
+	}
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\x00", "").Replace(text)
+
+	// Windows hands a copied file list to Tk as one path per line
+	if runtime.GOOS == "windows" {
+		if files = existingPaths(text); len(files) > 0 {
+			return "", files
+		}
+	}
+	return text, nil
+}
+
+// clipboardGet returns the clipboard converted to target, or "" when the
+// clipboard is empty or the owner can't provide that target.
+func clipboardGet(target string) string {
+	return tclEval("if {[catch {clipboard get -type %s} s]} {set s {}}; set s", target)
+}
+
+// fileURIs returns the local paths of the file:// URIs in a text/uri-list,
+// or nil when it names anything else.
+func fileURIs(list string) []string {
+	var paths []string
+	for line := range strings.Lines(list) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+			return nil
+		}
+		path := u.Path
+		if runtime.GOOS == "windows" {
+			path = strings.TrimPrefix(path, "/") // file:///C:/dir
+		}
+		paths = append(paths, filepath.FromSlash(path))
+	}
+	return paths
+}
+
+// existingPaths returns the lines of text when each one is the absolute
+// path of an existing file.
+func existingPaths(text string) []string {
+	var paths []string
+	for line := range strings.Lines(strings.TrimSpace(text)) {
+		path := strings.TrimRight(line, "\n")
+		if !filepath.IsAbs(path) {
+			return nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// htmlToText strips the markup from an HTML fragment, keeping line breaks.
+func htmlToText(s string) string {
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// -------------------------------------------------------------------------
+// Primary Selection
+// -------------------------------------------------------------------------
+
+// pasteSelection inserts the PRIMARY selection at the mouse pointer, as
+// X11 programs do on a middle-click. Releasing the button after dragging
+// it to scroll the view pastes nothing. The other direction needs no code:
+// the editor exports its selection, so text selected in ITE is what other
+// programs paste.
+func (i *Ite) pasteSelection(e *Event) {
+	if tclEval("expr {[info exists ::tk::Priv(mouseMoved)] && $::tk::Priv(mouseMoved)}") == "1" {
+		return
+	}
+	text := primaryGet(targetUTF8)
+	if text == "" {
+		text = primaryGet(targetDefaultText)
+	}
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\x00", "").Replace(text)
+	if text == "" {
+		return
+	}
+	index := mouseIndex(i.editText, e)
+	if i.blockProtected(index, index) {
+		return
+	}
+	i.editGroup(func() {
+		i.editText.MarkSet("insert", index)
+		i.editText.Insert("insert", text)
+	})
+	i.editText.See("insert")
+	i.refreshCursorState()
+	Focus(i.editText)
+}
+
+// primaryGet returns the PRIMARY selection converted to target, or "" when
+// nothing is selected anywhere on the display.
+func primaryGet(target string) string {
+	return tclEval("if {[catch {selection get -selection PRIMARY -type %s} s]} {set s {}}; set s", target)
+}
+
+// -------------------------------------------------------------------------
+// Go String Literals
+// -------------------------------------------------------------------------
+
+// onPasteAsString pastes the clipboard as a Go string literal: a raw
+// `backquoted` string when the text can be one, else a "double-quoted"
+// string with escapes.
+func (i *Ite) onPasteAsString() {
+	text, files := readClipboard()
+	if len(files) > 0 {
+		text = strings.Join(files, "\n")
+	}
+	if text != "" {
+		i.insertAtCursor(goStringLiteral(text))
+	}
+}
+
+// goStringLiteral returns s as a Go string literal, backquoted when that
+// keeps its value.
+func goStringLiteral(s string) string {
+	if canRawString(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// canRawString reports whether s reads the same as a raw string literal:
+// it holds no backquote, no carriage return, which raw strings drop, no
+// byte order mark and no control character but tab and newline.
+func canRawString(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r == '`', r == '\r', r == '\uFEFF':
+			return false
+		case r == '\t', r == '\n':
+		case unicode.IsControl(r):
+			return false
+		}
+	}
+	return true
+}
+
+// onCopyUnquoted copies the value of the Go string or rune literal under
+// the cursor, or of the selected literal, without quotes or escapes.
+func (i *Ite) onCopyUnquoted() {
+	lit := ""
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		lit = strings.TrimSpace(i.editText.Get(sel[0], sel[len(sel)-1])[0])
+	} else {
+		lit = i.literalAtCursor()
+	}
+	if lit == "" {
+		i.showStatusHint("No string literal at the cursor")
+		return
+	}
+	value, err := strconv.Unquote(lit)
+	if err != nil {
+		i.showStatusHint("Not a Go string literal")
+		return
+	}
+	ClipboardClear()
+	ClipboardAppend(value)
+	i.showStatusHint(fmt.Sprintf("Copied %d characters", utf8.RuneCountInString(value)))
+}
+
+// literalAtCursor returns the string or rune literal around the cursor, or
+// "" if there is none. Raw strings may span lines, so the whole buffer is
+// scanned, except in large-file mode where only the cursor line is.
+func (i *Ite) literalAtCursor() string {
+	from := "1.0"
+	if i.largeFile {
+		from = "insert linestart"
+	}
+	before := i.editText.Get(from, "insert")[0]
+	src := before + i.editText.Get("insert", "end-1c")[0]
+	if i.largeFile {
+		src = before + i.editText.Get("insert", "insert lineend")[0]
+	}
+	return literalAt(src, len(before))
+}
+
+// literalAt returns the string or rune literal of Go source src covering
+// the byte offset, or "" if there is none.
+func literalAt(src string, offset int) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+	for {
+		pos, tok, lit := s.Scan()
+		start := file.Offset(pos)
+		if tok == token.EOF || start > offset {
+			return ""
+		}
+		if (tok == token.STRING || tok == token.CHAR) && offset <= start+len(lit) {
+			return lit
+		}
+	}
+}
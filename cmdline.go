@@ -0,0 +1,149 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Command Line
+// -------------------------------------------------------------------------
+
+// cmdLine holds the parsed command line.
+type cmdLine struct {
+	files []fileArg
+	trace string // Execution trace file given with --trace, "" for none
+	batch string // Script given with --batch, run on the files without a window
+}
+
+// fileArg is a file named on the command line.
+type fileArg struct {
+	path string
+	line int // Line to jump to, 0 for the start of the file
+}
+
+// parseArgs parses the command line arguments: file paths, each optionally
+// preceded by +N to start on line N, as in `ite +12 main.go util.go`,
+// --trace FILE to write an execution trace and --batch SCRIPT, or -batch,
+// to edit the files with a script. After --, every argument is a file, so
+// that `ite -- +notes` opens the file "+notes". The files are made
+// absolute, as the editor keys bookmarks and recent files on their paths.
+func parseArgs(args []string) (cmdLine, error) {
+	var cl cmdLine
+	line := 0
+	files := false // After --
+	addFile := func(arg string) error {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		cl.files = append(cl.files, fileArg{path: path, line: line})
+		line = 0
+		return nil
+	}
+	for a := 0; a < len(args); a++ {
+		arg := args[a]
+		if files {
+			if err := addFile(arg); err != nil {
+				return cl, err
+			}
+			continue
+		}
+		if arg == "--" {
+			files = true
+			continue
+		}
+		if path, ok := strings.CutPrefix(arg, "--trace="); ok {
+			cl.trace = path
+			continue
+		}
+		if path, ok := strings.CutPrefix(arg, "--batch="); ok {
+			cl.batch = path
+			continue
+		}
+		if arg == "--trace" || arg == "--batch" || arg == "-batch" {
+			if a+1 == len(args) {
+				return cl, fmt.Errorf("%s needs a file", arg)
+			}
+			a++
+			if arg == "--trace" {
+				cl.trace = args[a]
+			} else {
+				cl.batch = args[a]
+			}
+			continue
+		}
+		if n, ok := strings.CutPrefix(arg, "+"); ok {
+			l, err := strconv.Atoi(n)
+			if err != nil || l < 1 {
+				return cl, fmt.Errorf("invalid line number %q", arg)
+			}
+			line = l
+			continue
+		}
+		if err := addFile(arg); err != nil {
+			return cl, err
+		}
+	}
+	if line > 0 {
+		return cl, fmt.Errorf("+%d isn't followed by a file", line)
+	}
+	return cl, nil
+}
+
+// openArgs opens the first file given on the command line in this window.
+// The editor holds one file per window, so each further file gets a window
+// of its own, in one more instance of ITE: `ite a.go b.go` starts two
+// processes, which are independent of each other from then on.
+func (i *Ite) openArgs(files []fileArg) {
+	if len(files) == 0 {
+		i.offerRecovery() // Of an untitled buffer
+		return
+	}
+	for _, f := range files[1:] {
+		if err := startInstance(f); err != nil {
+			fmt.Fprintf(os.Stderr, "ite: opening %s: %v\n", f.path, err)
+		}
+	}
+
+	f := files[0]
+	if err := i.openFile(f.path); err != nil {
+		if !os.IsNotExist(err) {
+			i.showError("Error opening file: " + err.Error())
+			return
+		}
+		// A new file, created on the first save
+		i.currentFile = f.path
+		i.updateTitle()
+	}
+	if f.line > 0 {
+		i.jumpTo(f.line, 0)
+	}
+}
+
+// startInstance launches a separate ITE process editing f.
+func startInstance(f fileArg) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var args []string
+	if f.line > 0 {
+		args = append(args, fmt.Sprintf("+%d", f.line))
+	}
+	cmd := exec.Command(exe, append(args, "--", f.path)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait() // Reap the child; it lives on independently
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	abs := func(path string) string {
+		p, err := filepath.Abs(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	tests := []struct {
+		args  []string
+		files []fileArg
+		trace string
+		batch string
+		err   bool
+	}{
+		{args: nil},
+		{args: []string{"a.go", "b.go"}, files: []fileArg{{abs("a.go"), 0}, {abs("b.go"), 0}}},
+		{args: []string{"+12", "a.go", "b.go"}, files: []fileArg{{abs("a.go"), 12}, {abs("b.go"), 0}}},
+		{args: []string{"--trace", "t.out", "a.go"}, files: []fileArg{{abs("a.go"), 0}}, trace: "t.out"},
+		{args: []string{"--trace=t.out"}, trace: "t.out"},
+		{args: []string{"--", "+notes", "--trace"}, files: []fileArg{{abs("+notes"), 0}, {abs("--trace"), 0}}},
+		{args: []string{"+3", "--", "+notes"}, files: []fileArg{{abs("+notes"), 3}}},
+		{args: []string{"a.go", "+5"}, err: true},
+		{args: []string{"+0", "a.go"}, err: true},
+		{args: []string{"+x", "a.go"}, err: true},
+		{args: []string{"--trace"}, err: true},
+		{args: []string{"-batch", "fix.ite", "a.go"}, files: []fileArg{{abs("a.go"), 0}}, batch: "fix.ite"},
+		{args: []string{"--batch=fix.ite", "a.go"}, files: []fileArg{{abs("a.go"), 0}}, batch: "fix.ite"},
+		{args: []string{"--batch"}, err: true},
+		{args: []string{"sub/../a.go"}, files: []fileArg{{abs("a.go"), 0}}},
+	}
+	for _, tt := range tests {
+		cl, err := parseArgs(tt.args)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseArgs(%q): no error", tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if !slices.Equal(cl.files, tt.files) || cl.trace != tt.trace || cl.batch != tt.batch {
+			t.Errorf("parseArgs(%q) = %v, %q, %q; want %v, %q, %q", tt.args, cl.files, cl.trace, cl.batch, tt.files, tt.trace, tt.batch)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// decodeCodePage returns s as UTF-8 when the locale says programs write
+// Latin-1, the legacy charset still found in the wild. Other charsets are
+// not decoded.
+func decodeCodePage(s string) (string, bool) {
+	charset := ""
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			_, charset, _ = strings.Cut(v, ".")
+			break
+		}
+	}
+	charset = strings.ToLower(strings.ReplaceAll(charset, "_", "-"))
+	charset, _, _ = strings.Cut(charset, "@") // As in de_DE.ISO-8859-1@euro
+	switch charset {
+	case "iso-8859-1", "iso8859-1", "latin1":
+		return latin1Text([]byte(s)), true
+	}
+	return "", false
+}
@@ -0,0 +1,36 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build windows
+
+package main
+
+import (
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+const cpOEM = 1 // CP_OEMCP, the code page of console programs
+
+var procMultiByteToWideChar = syscall.NewLazyDLL("kernel32.dll").NewProc("MultiByteToWideChar")
+
+// decodeCodePage returns s, text in the OEM code page Windows console
+// programs write in, as UTF-8.
+func decodeCodePage(s string) (string, bool) {
+	if s == "" {
+		return "", true
+	}
+	src := []byte(s)
+	n, _, _ := procMultiByteToWideChar.Call(cpOEM, 0, uintptr(unsafe.Pointer(&src[0])), uintptr(len(src)), 0, 0)
+	if n == 0 {
+		return "", false
+	}
+	wide := make([]uint16, n)
+	n, _, _ = procMultiByteToWideChar.Call(cpOEM, 0, uintptr(unsafe.Pointer(&src[0])), uintptr(len(src)),
+		uintptr(unsafe.Pointer(&wide[0])), n)
+	if n == 0 {
+		return "", false
+	}
+	return string(utf16.Decode(wide[:n])), true
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Color Literals
+// -------------------------------------------------------------------------
+
+// Hex colors such as "#ffffea" get a swatch: their "#" is drawn on the
+// color it names, so the text keeps its indices. Each color has its own
+// tag, named after it.
+const colorTagPrefix = "color" // Prefix of the swatch tags, followed by the color
+
+// colorLiteral matches a hex color, #rgb or #rrggbb, that is not part of
+// a longer word or of an HTML character reference; colorString one
+// starting a string literal, the only ones looked for in Go files, whose
+// comments refer to issues as #123.
+var (
+	colorLiteral = regexp.MustCompile(`(?:^|[^\w&])(#(?:[0-9a-fA-F]{6}|[0-9a-fA-F]{3}))\b`)
+	colorString  = regexp.MustCompile("[\"'`](#(?:[0-9a-fA-F]{6}|[0-9a-fA-F]{3}))\\b")
+)
+
+// hexColor is a hex color of the buffer.
+type hexColor struct {
+	text       string
+	start, end int // Rune columns on its line
+}
+
+// markColors draws the swatches of the hex colors of the active buffer.
+func (i *Ite) markColors() {
+	var old []string
+	for _, tag := range i.editText.TagNames("") {
+		if strings.HasPrefix(tag, colorTagPrefix+"#") {
+			old = append(old, tag)
+		}
+	}
+	if len(old) > 0 {
+		i.editText.TagDelete(old...)
+	}
+	if i.largeFile || i.loading() {
+		return
+	}
+	re := i.colorPattern()
+	for n, line := range strings.Split(i.editText.Text(), "\n") {
+		for _, c := range hexColors(re, line) {
+			tag := colorTagPrefix + expandHexColor(c.text)
+			if len(i.editText.TagRanges(tag)) == 0 {
+				i.editText.TagConfigure(tag, Background(c.text), Foreground(contrastColor(c.text)))
+				tclEval("%s tag lower %s sel", i.editText, tag)
+			}
+			i.editText.TagAdd(tag, fmt.Sprintf("%d.%d", n+1, c.start), fmt.Sprintf("%d.%d", n+1, c.start+1))
+		}
+	}
+}
+
+// colorPattern returns the pattern of the hex colors of the active buffer.
+func (i *Ite) colorPattern() *regexp.Regexp {
+	if filepath.Ext(i.currentFile) == defaultFileExtension {
+		return colorString
+	}
+	return colorLiteral
+}
+
+// hexColors returns the hex colors of line matched by the group of re.
+func hexColors(re *regexp.Regexp, line string) []hexColor {
+	var colors []hexColor
+	for _, m := range re.FindAllStringSubmatchIndex(line, -1) {
+		start := runeColumn(line, m[2])
+		colors = append(colors, hexColor{text: line[m[2]:m[3]], start: start, end: start + m[3] - m[2]})
+	}
+	return colors
+}
+
+// expandHexColor returns the #rrggbb form of the hex color c, in lower
+// case. Colors with 16 bits per channel, #rrrrggggbbbb, lose the lower
+// half of each.
+func expandHexColor(c string) string {
+	c = strings.ToLower(c)
+	switch len(c) {
+	case 4:
+		return string([]byte{'#', c[1], c[1], c[2], c[2], c[3], c[3]})
+	case 13:
+		return "#" + c[1:3] + c[5:7] + c[9:11]
+	}
+	return c
+}
+
+// contrastColor returns black or white, whichever reads better on the hex
+// color c.
+func contrastColor(c string) string {
+	rgb, _ := strconv.ParseUint(expandHexColor(c)[1:], 16, 32)
+	r, g, b := rgb>>16, rgb>>8&0xff, rgb&0xff
+	if 299*r+587*g+114*b > 128*1000 { // Perceived brightness, as in WCAG 1
+		return colBlack
+	}
+	return "#ffffff"
+}
+
+// onPickColor opens a color picker on the hex color at the cursor and
+// writes back the chosen color, keeping the case of the original.
+func (i *Ite) onPickColor() {
+	line, col := parseIndex(i.editText.Index("insert"))
+	var c hexColor
+	found := false
+	for _, h := range hexColors(i.colorPattern(), lineText(i.editText, line)) {
+		if h.start <= col && col <= h.end {
+			c, found = h, true
+			break
+		}
+	}
+	if !found {
+		i.showStatusHint("No hex color at the cursor")
+		return
+	}
+	chosen := ChooseColor(Initialcolor(c.text), Parent(App), Title("Pick Color"))
+	if chosen == "" {
+		return
+	}
+	chosen = expandHexColor(chosen)
+	if strings.ToUpper(c.text) == c.text && strings.ToLower(c.text) != c.text {
+		chosen = strings.ToUpper(chosen)
+	}
+	from, to := fmt.Sprintf("%d.%d", line, c.start), fmt.Sprintf("%d.%d", line, c.end)
+	if chosen == c.text || i.editText.Get(from, to)[0] != c.text || i.blockProtected(from, to) {
+		return
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, chosen)
+	})
+	i.markColors()
+	i.refreshCursorState()
+}
@@ -0,0 +1,303 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Commands
+// -------------------------------------------------------------------------
+
+// command is an action of the editor. The toolbar, the menus, the command
+// palette and the keys file all reach it by its id.
+type command struct {
+	id       string // Name used in the keys file and by menus and toolbar
+	title    string // Label of the palette and of the key list
+	shortcut string // Key bound unless the preset or keys file uses it, e.g. "<Control-F12>"
+	run      func()
+}
+
+// commandRegistry holds the commands by id, in the order they were added,
+// which is the order of the palette.
+type commandRegistry struct {
+	list []*command
+	byID map[string]*command
+	// around, if set, runs every command, which it is given by id, so the
+	// editor can watch them being run, e.g. to record a macro
+	around func(id string, run func())
+}
+
+// add registers c, replacing the command with the same id if any.
+func (r *commandRegistry) add(c command) {
+	if run := c.run; r.around != nil {
+		c.run = func() { r.around(c.id, run) }
+	}
+	if r.byID == nil {
+		r.byID = make(map[string]*command)
+	}
+	if old, ok := r.byID[c.id]; ok {
+		*old = c
+		return
+	}
+	r.list = append(r.list, &c)
+	r.byID[c.id] = &c
+}
+
+// lookup returns the command id, or nil if there is none.
+func (r *commandRegistry) lookup(id string) *command {
+	return r.byID[id]
+}
+
+// commandProviders return the commands added to the built-in ones, see
+// registerCommands.
+var commandProviders []func(*Ite) []command
+
+// registerCommands adds the commands returned by provider to those of the
+// editor, where they join the palette and can be bound in the keys file. A
+// fork adds its actions from a file of its own, without editing the core
+// files:
+//
+//	func init() {
+//		registerCommands(func(i *Ite) []command {
+//			return []command{{
+//				id:       "wordCount",
+//				title:    "Word Count",
+//				shortcut: "<Control-F12>",
+//				run:      func() { i.showStatusHint(...) },
+//			}}
+//		})
+//	}
+//
+// A command with the id of a built-in one replaces it everywhere. It must
+// be called before the editor starts, from init.
+func registerCommands(provider func(*Ite) []command) {
+	commandProviders = append(commandProviders, provider)
+}
+
+// makeCommands builds the registry of the built-in commands followed by
+// the registered ones.
+func (i *Ite) makeCommands() {
+	i.commands.around = i.aroundCommand
+	for _, c := range i.builtinCommands() {
+		i.commands.add(c)
+	}
+	for _, provider := range commandProviders {
+		for _, c := range provider(i) {
+			i.commands.add(c)
+		}
+	}
+}
+
+// builtinCommands returns the commands of the editor, in palette order.
+func (i *Ite) builtinCommands() []command {
+	return []command{
+		{id: "new", title: "New File", run: i.onNew},
+		{id: "open", title: "Open File", run: i.onOpen},
+		{id: "openProjectFolder", title: "Open Folder as Project", run: i.onOpenProjectFolder},
+		{id: "closeProjectFolder", title: "Close Project Folder", run: i.onCloseProjectFolder},
+		{id: "editProjectSettings", title: "Project Settings", run: i.onEditProjectSettings},
+		{id: "generateGitignore", title: "Generate .gitignore", run: i.onGenerateGitignore},
+		{id: "generateLicense", title: "Generate License", run: i.onGenerateLicense},
+		{id: "quickOpen", title: "Quick Open", run: i.onQuickOpen},
+		{id: "switchFile", title: "Switch File", run: i.onSwitchFile},
+		{id: "complete", title: "Complete with Providers", run: i.onComplete},
+		{id: "save", title: "Save", run: i.onSave},
+		{id: "saveAs", title: "Save As", run: i.onSaveAs},
+		{id: "close", title: "Close File", run: i.onCloseFile},
+		{id: "undoFileOp", title: "Undo Last File Operation", run: i.onUndoFileOp},
+		{id: "exportHTML", title: "Export as HTML", run: i.onExportHTML},
+		{id: "print", title: "Print", run: i.onPrint},
+		{id: "exportConsolePDF", title: "Export Console as PDF", run: i.onExportConsolePDF},
+		{id: "undo", title: "Undo", run: i.onUndo},
+		{id: "redo", title: "Redo", run: i.onRedo},
+		{id: "cut", title: "Cut", run: i.onCut},
+		{id: "copy", title: "Copy", run: i.onCopy},
+		{id: "paste", title: "Paste", run: i.onPaste},
+		{id: "goToLine", title: "Go to Line", run: i.onGoToLine},
+		{id: "goToDefinition", title: "Go to Definition", run: i.onGoToDefinition},
+		{id: "showDocumentation", title: "Show Documentation", run: i.onShowDocumentation},
+		{id: "matchBracket", title: "Jump to Matching Bracket", run: i.onJumpToMatchingBracket},
+		{id: "find", title: "Find", run: i.onFind},
+		{id: "replace", title: "Replace", run: i.onReplace},
+		{id: "findInFiles", title: "Find in Files", run: i.onFindInFiles},
+		{id: "structuralReplace", title: "Structural Replace", run: i.onStructuralReplace},
+		{id: "renamePackage", title: "Rename Package", run: i.onRenamePackage},
+		{id: "moveDeclaration", title: "Move Declaration to File", run: i.onMoveDeclaration},
+		{id: "toggleComment", title: "Toggle Line Comment", run: i.onToggleLineComment},
+		{id: "toggleBlockComment", title: "Toggle Block Comment", run: i.onToggleBlockComment},
+		{id: "reflowComment", title: "Reflow Comment", run: i.onReflowComment},
+		{id: "docComment", title: "Insert Doc Comment", run: i.onInsertDocComment},
+		{id: "align", title: "Align", run: i.onAlign},
+		{id: "formatFile", title: "Format File", run: i.onFormatFile},
+		{id: "moveLinesUp", title: "Move Lines Up", shortcut: "<Alt-Up>", run: i.onMoveLinesUp},
+		{id: "moveLinesDown", title: "Move Lines Down", shortcut: "<Alt-Down>", run: i.onMoveLinesDown},
+		{id: "duplicateLines", title: "Duplicate Lines", shortcut: "<Control-Shift-D>", run: i.onDuplicateLines},
+		{id: "deleteLines", title: "Delete Lines", shortcut: "<Control-Shift-K>", run: i.onDeleteLines},
+		{id: "joinLines", title: "Join Lines", run: i.onJoinLines},
+		{id: "sortLines", title: "Sort Lines", run: i.onSortLines},
+		{id: "trimTrailingWhitespace", title: "Trim Trailing Whitespace", run: i.onTrimTrailingWhitespace},
+		{id: "convertToLF", title: "Convert Line Endings to LF", run: i.onConvertToLF},
+		{id: "convertToCRLF", title: "Convert Line Endings to CRLF", run: i.onConvertToCRLF},
+		{id: "recordMacro", title: "Record Macro", shortcut: "<F3>", run: i.onRecordMacro},
+		{id: "stopMacro", title: "Stop Recording Macro", run: i.onStopMacro},
+		{id: "playMacro", title: "Play Macro", shortcut: "<F4>", run: i.onPlayMacro},
+		{id: "playMacroTimes", title: "Play Macro N Times", run: i.onPlayMacroTimes},
+		{id: "gitStatus", title: "Git Status", run: i.onGitStatus},
+		{id: "gitDiff", title: "Git Diff with HEAD", run: i.onGitDiff},
+		{id: "compareWithSaved", title: "Compare with Saved", run: i.onCompareWithSaved},
+		{id: "compareFiles", title: "Compare Files", run: i.onCompareFiles},
+		{id: "reviewPatch", title: "Review Patch", run: i.onReviewPatch},
+		{id: "reviewRange", title: "Review Commits", run: i.onReviewRange},
+		{id: "gitCommit", title: "Git Commit", run: i.onGitCommit},
+		{id: "gitStash", title: "Git Stash", run: i.onGitStash},
+		{id: "gitStashPop", title: "Git Stash Pop", run: i.onGitStashPop},
+		{id: "nextConflict", title: "Next Merge Conflict", run: i.onNextConflict},
+		{id: "previousConflict", title: "Previous Merge Conflict", run: i.onPreviousConflict},
+		{id: "acceptOurs", title: "Resolve Conflict with Ours", run: func() { i.resolveConflict(takeOurs) }},
+		{id: "acceptTheirs", title: "Resolve Conflict with Theirs", run: func() { i.resolveConflict(takeTheirs) }},
+		{id: "acceptBoth", title: "Resolve Conflict with Both", run: func() { i.resolveConflict(takeBoth) }},
+		{id: "pasteAsString", title: "Paste as Go String", run: i.onPasteAsString},
+		{id: "copyUnquoted", title: "Copy Unquoted", run: i.onCopyUnquoted},
+		{id: "toggleBookmark", title: "Toggle Bookmark", run: i.onToggleBookmark},
+		{id: "nextBookmark", title: "Next Bookmark", run: i.onNextBookmark},
+		{id: "previousBookmark", title: "Previous Bookmark", run: i.onPreviousBookmark},
+		{id: "cursorUndo", title: "Cursor Undo", shortcut: "<Control-Alt-z>", run: i.onCursorUndo},
+		{id: "cursorRedo", title: "Cursor Redo", shortcut: "<Control-Alt-Shift-Z>", run: i.onCursorRedo},
+		{id: "listBookmarks", title: "List Bookmarks", run: i.onListBookmarks},
+		{id: "regexTester", title: "Regex Tester", run: i.onRegexTester},
+		{id: "reloadEnvironment", title: "Reload Environment", run: i.onReloadEnvironment},
+		{id: "toggleFold", title: "Toggle Fold", run: i.onToggleFold},
+		{id: "foldAll", title: "Fold All", run: i.onFoldAll},
+		{id: "unfoldAll", title: "Unfold All", run: i.onUnfoldAll},
+		{id: "insertTimestamp", title: "Insert Timestamp", run: i.onInsertTimestamp},
+		{id: "insertUnixTime", title: "Insert Unix Time", run: i.onInsertUnixTime},
+		{id: "insertUUID", title: "Insert UUID", run: i.onInsertUUID},
+		{id: "convertNumber", title: "Convert Number", run: i.onConvertNumber},
+		{id: "pickColor", title: "Pick Color", run: i.onPickColor},
+		{id: "build", title: "Go Build", run: i.onGoBuild},
+		{id: "run", title: "Go Run", run: i.onGoRun},
+		{id: "test", title: "Go Test", run: i.onGoTest},
+		{id: "coverage", title: "Go Test with Coverage", run: i.onGoCoverage},
+		{id: "clearCoverage", title: "Clear Coverage Marks", run: i.onClearCoverage},
+		{id: "bench", title: "Go Benchmarks", run: i.onGoBench},
+		{id: "escapeAnalysis", title: "Escape Analysis", run: i.onEscapeAnalysis},
+		{id: "showAssembly", title: "Show Assembly", run: i.onShowAssembly},
+		{id: "analyzeBinarySize", title: "Analyze Binary Size", run: i.onAnalyzeBinarySize},
+		{id: "runQuery", title: "Run Query", shortcut: "<Control-Alt-Return>", run: i.onRunQuery},
+		{id: "setDatabase", title: "Set Database", run: i.onSetDatabase},
+		{id: "migrateUp", title: "Migrate Up", run: i.onMigrateUp},
+		{id: "migrateDown", title: "Migrate Down", run: i.onMigrateDown},
+		{id: "migrationStatus", title: "Migration Status", run: i.onMigrationStatus},
+		{id: "dockerCompose", title: "Docker Compose", run: i.onDockerCompose},
+		{id: "uncachedTests", title: "Toggle Uncached Tests", run: i.onToggleUncachedTests},
+		{id: "toggleOffline", title: "Toggle Work Offline", run: i.onToggleWorkOffline},
+		{id: "toggleBuildUnsaved", title: "Toggle Building Unsaved Buffers", run: i.onToggleBuildUnsaved},
+		{id: "toggleVendor", title: "Toggle Vendor Directory", run: i.onToggleVendorMode},
+		{id: "toggleAPI", title: "Toggle External Tool API", run: i.onToggleAPI},
+		{id: "clearBuildTags", title: "Clear Build Tags", run: i.onClearBuildTags},
+		{id: "lint", title: "Lint", run: i.onLint},
+		{id: "checkNameSpelling", title: "Check Spelling of Names", run: i.onCheckNameSpelling},
+		{id: "assertSelection", title: "Assert Selection", run: i.onAssertSelection},
+		{id: "mutateSelection", title: "Mutate Selection", run: i.onMutateSelection},
+		{id: "stop", title: "Stop", run: i.onStop},
+		{id: "clearConsole", title: "Clear Console", run: i.onClearConsole},
+		{id: "scrollLock", title: "Toggle Console Scroll Lock", run: i.onToggleScrollLock},
+		{id: "consoleTimestamps", title: "Toggle Console Timestamps", run: i.onToggleConsoleTimestamps},
+		{id: "toggleConsoleLog", title: "Toggle Console Log File", run: i.onToggleConsoleLog},
+		{id: "openConsoleLog", title: "Open Console Log", run: i.onOpenConsoleLog},
+		{id: "toggleLocateInSource", title: "Toggle Locate in Source", run: i.onToggleLocateInSource},
+		{id: "nextStackFrame", title: "Next Stack Frame", shortcut: "<Control-Alt-Down>", run: i.onNextStackFrame},
+		{id: "previousStackFrame", title: "Previous Stack Frame", shortcut: "<Control-Alt-Up>", run: i.onPreviousStackFrame},
+		{id: "goModTidy", title: "Go Mod Tidy", run: i.onGoModTidy},
+		{id: "goModInit", title: "Go Mod Init", run: i.onGoModInit},
+		{id: "goGet", title: "Go Get", run: i.onGoGet},
+		{id: "goModVendor", title: "Go Mod Vendor", run: i.onGoModVendor},
+		{id: "runTask", title: "Run Task", run: i.onRunTask},
+		{id: "runLastTask", title: "Run Last Task", shortcut: "<F9>", run: i.onRunLastTask},
+		{id: "taskHistory", title: "Task History", run: i.onTaskHistory},
+		{id: "httpClient", title: "HTTP Client", run: i.onHTTPClient},
+		{id: "grpcClient", title: "gRPC Client", run: i.onGRPCClient},
+		{id: "openAPIPreview", title: "OpenAPI Preview", run: i.onOpenAPIPreview},
+		{id: "processInspector", title: "Process Inspector", run: i.onProcessInspector},
+		{id: "runProfiles", title: "Run Profiles", run: i.onRunProfiles},
+		{id: "doctor", title: "Doctor", run: i.onDoctor},
+		{id: "undoToSave", title: "Undo to Last Save", run: i.onUndoToLastSave},
+		{id: "protectSelection", title: "Protect Selection", run: i.onProtectSelection},
+		{id: "unprotectSelection", title: "Unprotect Selection", run: i.onUnprotectSelection},
+		{id: "toggleLinkedEditing", title: "Toggle Linked Editing", run: i.onToggleLinkedEditing},
+		{id: "toggleCodeHints", title: "Toggle Signature Help and Hover", run: i.onToggleCodeHints},
+		{id: "toggleReadOnly", title: "Toggle Read-only", run: i.onToggleReadOnly},
+		{id: "toggleAutoClosePairs", title: "Toggle Auto-Closing Pairs", run: i.onToggleAutoClosePairs},
+		{id: "togglePathCompletion", title: "Toggle Path Completion", run: i.onTogglePathCompletion},
+		{id: "toggleAutospacing", title: "Toggle Autospacing", run: i.onToggleAutospacing},
+		{id: "toggleTypewriter", title: "Toggle Typewriter Scrolling", run: i.onToggleTypewriter},
+		{id: "toggleTheme", title: "Toggle Dark Theme", run: i.onToggleTheme},
+		{id: "toggleConsoleTheme", title: "Toggle Dark Console", run: i.onToggleConsoleTheme},
+		{id: "toggleWordWrap", title: "Toggle Word Wrap", run: i.onToggleWordWrap},
+		{id: "toggleWhitespace", title: "Toggle Whitespace", run: i.onToggleWhitespace},
+		{id: "toggleIndentGuides", title: "Toggle Indentation Guides", run: i.onToggleIndentGuides},
+		{id: "toggleInlineDiagnostics", title: "Toggle Inline Diagnostics", run: i.onToggleInlineDiagnostics},
+		{id: "toggleSpellCheck", title: "Toggle Spell Checking", run: i.onToggleSpellCheck},
+		{id: "toggleRelativePaths", title: "Toggle Relative Paths", run: i.onToggleRelativePaths},
+		{id: "toggleOutline", title: "Toggle Outline", run: i.onToggleOutline},
+		{id: "toggleStickyScroll", title: "Toggle Sticky Scroll", run: i.onToggleStickyScroll},
+		{id: "splitSideBySide", title: "Split Editor Side by Side", run: func() { i.onSplit(splitSideBySide) }},
+		{id: "splitStacked", title: "Split Editor Stacked", run: func() { i.onSplit(splitStacked) }},
+		{id: "otherPane", title: "Other Editor Pane", run: i.onOtherPane},
+		{id: "closePane", title: "Close Editor Pane", run: i.onClosePane},
+		{id: "moveToOtherPane", title: "Move File to Other Pane", run: i.onMoveToOtherPane},
+		{id: "focusEditor", title: "Focus Editor", run: func() { i.focusPanel(panelEditor) }},
+		{id: "focusConsole", title: "Focus Console", run: func() { i.focusPanel(panelConsole) }},
+		{id: "focusOutline", title: "Focus Outline", run: func() { i.focusPanel(panelOutline) }},
+		{id: "focusFindResults", title: "Focus Find Results", run: func() { i.focusPanel(panelFindResults) }},
+		{id: "focusNext", title: "Focus Next Panel", run: func() { i.cycleFocus(1) }},
+		{id: "focusPrevious", title: "Focus Previous Panel", run: func() { i.cycleFocus(-1) }},
+		{id: "preferences", title: "Preferences", run: i.onPreferences},
+		{id: "displayScaling", title: "Display Scaling", run: i.onDisplayScaling},
+		{id: "exportSettings", title: "Export Settings", run: i.onExportSettings},
+		{id: "importSettings", title: "Import Settings", run: i.onImportSettings},
+		{id: "resetSettings", title: "Reset Settings", run: i.onResetSettings},
+		{id: "toggleLeftSidebar", title: "Toggle Left Sidebar", run: func() { i.onToggleRegion(regionLeft) }},
+		{id: "toggleRightPanel", title: "Toggle Right Panel", run: func() { i.onToggleRegion(regionRight) }},
+		{id: "toggleBottomPanel", title: "Toggle Bottom Panel", run: func() { i.onToggleRegion(regionBottom) }},
+		{id: "resetLayout", title: "Reset Layout", run: i.onResetLayout},
+		{id: "toggleTrash", title: "Toggle Move Replaced Files to Trash", run: i.onToggleTrash},
+		{id: "toggleGracefulStop", title: "Toggle Stop with SIGTERM", run: i.onToggleGracefulStop},
+		{id: "wordChars", title: "Word Characters", run: i.onWordChars},
+		{id: "undoInterval", title: "Undo Grouping Interval", run: i.onUndoInterval},
+		{id: "commandPalette", title: "Command Palette", run: i.onCommandPalette},
+		{id: "keyBindings", title: "Keyboard Shortcuts", run: i.onKeyBindings},
+		{id: "quit", title: "Exit", run: i.onQuit},
+	}
+}
+
+// addMenuCommand adds the command id to menu, showing its key, labelled
+// label or, if empty, by the title of the command.
+func (i *Ite) addMenuCommand(menu *MenuWidget, id, label string) {
+	c := i.mustCommand(id)
+	if label == "" {
+		label = c.title
+	}
+	menu.AddCommand(Lbl(tr(label)), Accelerator(i.accelerator(id)), Command(c.run))
+}
+
+// addMenuCheck adds the toggle command id to menu as a checkbutton
+// reflecting v.
+func (i *Ite) addMenuCheck(menu *MenuWidget, id, label string, v *VariableOpt) {
+	c := i.mustCommand(id)
+	entry := menu.AddCheckbutton(Lbl(tr(label)), Accelerator(i.accelerator(id)), Command(c.run))
+	menu.EntryConfigure(entry, v)
+}
+
+// mustCommand returns the command id, which menus and toolbar reference
+// by name: a missing one is a programming error.
+func (i *Ite) mustCommand(id string) *command {
+	c := i.commands.lookup(id)
+	if c == nil {
+		panic("unknown command " + id)
+	}
+	return c
+}
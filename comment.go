@@ -0,0 +1,132 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Comment Toggling
+// -------------------------------------------------------------------------
+
+const (
+	lineCommentPrefix = "// "
+	blockCommentOpen  = "/* "
+	blockCommentClose = " */"
+)
+
+// selectedLines returns the lines covered by the selection, or the cursor
+// line when nothing is selected. A selection ending at the start of a line
+// doesn't include that line.
+func (i *Ite) selectedLines() (first, last int) {
+	sel := i.editText.TagRanges("sel")
+	if len(sel) < 2 {
+		line, _ := parseIndex(i.editText.Index("insert"))
+		return line, line
+	}
+	first, _ = parseIndex(sel[0])
+	last, col := parseIndex(sel[len(sel)-1])
+	if col == 0 && last > first {
+		last--
+	}
+	return first, last
+}
+
+// onToggleLineComment comments out the current line or the selected lines
+// with //, or uncomments them when every non-blank one is already a
+// comment. The marker goes at the smallest indentation of the lines, so
+// the code keeps its shape.
+func (i *Ite) onToggleLineComment() {
+	first, last := i.selectedLines()
+	selected := len(i.editText.TagRanges("sel")) >= 2
+	if i.blockProtected(fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.end", last)) {
+		return
+	}
+
+	indent := -1
+	commented := true
+	for line := first; line <= last; line++ {
+		text := lineText(i.editText, line)
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len([]rune(text)) - len([]rune(trimmed)); indent < 0 || n < indent {
+			indent = n
+		}
+		if !strings.HasPrefix(trimmed, "//") {
+			commented = false
+		}
+	}
+	if indent < 0 {
+		return // Only blank lines
+	}
+
+	i.editGroup(func() {
+		for line := first; line <= last; line++ {
+			text := lineText(i.editText, line)
+			trimmed := strings.TrimLeft(text, " \t")
+			if trimmed == "" {
+				continue
+			}
+			if !commented {
+				i.editText.Insert(fmt.Sprintf("%d.%d", line, indent), lineCommentPrefix)
+				continue
+			}
+			start := len([]rune(text)) - len([]rune(trimmed))
+			n := len("//")
+			if strings.HasPrefix(trimmed, lineCommentPrefix) {
+				n = len(lineCommentPrefix)
+			}
+			i.editText.Delete(fmt.Sprintf("%d.%d", line, start), fmt.Sprintf("%d.%d", line, start+n))
+		}
+	})
+	if selected {
+		i.editText.TagAdd("sel", fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.0", last+1))
+	}
+	i.refreshCursorState()
+}
+
+// onToggleBlockComment wraps the selection in /* */, or unwraps it when it
+// already is a block comment. Without a selection it applies to the text
+// of the cursor line.
+func (i *Ite) onToggleBlockComment() {
+	var from, to string
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		from, to = sel[0], sel[len(sel)-1]
+	} else {
+		line, _ := parseIndex(i.editText.Index("insert"))
+		text := lineText(i.editText, line)
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			return
+		}
+		start := len([]rune(text)) - len([]rune(strings.TrimLeft(text, " \t")))
+		from = fmt.Sprintf("%d.%d", line, start)
+		to = fmt.Sprintf("%d.%d", line, start+len([]rune(trimmed)))
+	}
+	if i.blockProtected(from, to) {
+		return
+	}
+
+	text := i.editText.Get(from, to)[0]
+	trimmed := strings.TrimSpace(text)
+	i.editGroup(func() {
+		if strings.HasPrefix(trimmed, "/*") && strings.HasSuffix(trimmed, "*/") && len(trimmed) >= 4 {
+			inner := strings.TrimSuffix(strings.TrimPrefix(trimmed, "/*"), "*/")
+			inner = strings.TrimPrefix(strings.TrimSuffix(inner, " "), " ")
+			lead := text[:strings.Index(text, "/*")]
+			trail := text[strings.LastIndex(text, "*/")+2:]
+			i.editText.Delete(from, to)
+			i.editText.Insert(from, lead+inner+trail)
+		} else {
+			// Close first, so that from stays valid
+			i.editText.Insert(to, blockCommentClose)
+			i.editText.Insert(from, blockCommentOpen)
+		}
+	})
+	i.refreshCursorState()
+}
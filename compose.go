@@ -0,0 +1,156 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Docker Compose
+// -------------------------------------------------------------------------
+
+// The Docker Compose window of a project with a compose file at its root
+// lists the services of the stack. Start and Stop run docker compose up
+// -d and down in the Tasks console, and Tail Logs, or a double click,
+// follows the logs of the service selected in the Logs console until the
+// Stop command, so the containers a service needs run next to the editor.
+const composeLogTail = "100" // Lines of earlier logs shown before following
+
+// composeFiles are the names of the compose file, in the order docker
+// compose looks for them.
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// composePanel is the Docker Compose window.
+type composePanel struct {
+	window   *ToplevelWidget
+	list     *ListboxWidget
+	file     string // Compose file
+	services []string
+}
+
+// findComposeFile returns the compose file of the project at root.
+func findComposeFile(root string) (string, bool) {
+	for _, name := range composeFiles {
+		path := filepath.Join(root, name)
+		if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// composeServices returns the names of the services of the compose file
+// src: the keys of the services mapping, in the order of the file.
+func composeServices(src string) []string {
+	var services []string
+	indent := -1 // Of the service names, -1 outside the services mapping
+	inServices := false
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		if depth == 0 {
+			inServices = strings.TrimSpace(strings.SplitN(trimmed, "#", 2)[0]) == "services:"
+			indent = -1
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if indent < 0 {
+			indent = depth
+		}
+		if depth != indent {
+			continue
+		}
+		name, _, found := strings.Cut(trimmed, ":")
+		if name = strings.Trim(name, `"'`); found && name != "" {
+			services = append(services, name)
+		}
+	}
+	return services
+}
+
+// onDockerCompose opens the Docker Compose window of the project.
+func (i *Ite) onDockerCompose() {
+	file, ok := findComposeFile(i.projectDir())
+	if !ok {
+		i.showError("No compose file found at the root of the project.")
+		return
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		i.showError("Docker Compose: " + err.Error())
+		return
+	}
+	if i.compose != nil {
+		Destroy(i.compose.window)
+	}
+	p := &composePanel{window: Toplevel(), file: file, services: composeServices(string(data))}
+	i.compose = p
+	p.window.WmTitle("Docker Compose: " + relativeTo(i.projectDir(), file))
+	p.list = p.window.Listbox(Font(editorFontFamily, fontSize), Height(min(max(len(p.services), 5), 15)),
+		Width(30), Activestyle("none"), Exportselection(false))
+	for _, s := range p.services {
+		p.list.Insert("end", s)
+	}
+	if len(p.services) > 0 {
+		p.list.SelectionSet(0)
+	}
+	buttons := p.window.TFrame()
+	Grid(buttons.TButton(Txt(tr("Start")), Command(func() { i.runCompose("up", "-d") })), Row(0), Column(0), Padx(px(3)))
+	Grid(buttons.TButton(Txt(tr("Stop")), Command(func() { i.runCompose("down") })), Row(0), Column(1), Padx(px(3)))
+	Grid(buttons.TButton(Txt(tr("Tail Logs")), Command(i.tailComposeLogs)), Row(0), Column(2), Padx(px(3)))
+	Grid(p.list, Row(0), Column(0), Sticky(NEWS), Padx(px(5)), Pady(px(5)))
+	Grid(buttons, Row(1), Column(0), Pady(px(5)))
+	GridRowConfigure(p.window, 0, Weight(1))
+	GridColumnConfigure(p.window, 0, Weight(1))
+	Bind(p.list, "<Double-Button-1>", Command(i.tailComposeLogs))
+	closeWindow := func() {
+		Destroy(p.window)
+		i.compose = nil
+		Focus(i.editText)
+	}
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+}
+
+// composeArgs returns the docker compose command line of file running
+// args.
+func composeArgs(file string, args ...string) []string {
+	return append([]string{"docker", "compose", "-f", file}, args...)
+}
+
+// runCompose runs docker compose with args on the stack of the window in
+// the Tasks console.
+func (i *Ite) runCompose(args ...string) {
+	p := i.compose
+	j := &job{c: i.console(consoleTasks), decoder: plainOutput{}, dir: filepath.Dir(p.file),
+		steps: []jobStep{{name: "docker compose", args: composeArgs(p.file, args...)}}}
+	i.runJob(j, "Running docker compose "+strings.Join(args, " ")+"...\n")
+}
+
+// tailComposeLogs follows the logs of the service selected in the Logs
+// console.
+func (i *Ite) tailComposeLogs() {
+	p := i.compose
+	sel := p.list.Curselection()
+	if len(sel) == 0 || sel[0] >= len(p.services) {
+		i.showStatusHint("Select a service first")
+		return
+	}
+	service := p.services[sel[0]]
+	args := composeArgs(p.file, "logs", "--follow", "--tail", composeLogTail, service)
+	j := &job{c: i.console(consoleLogs), decoder: plainOutput{}, dir: filepath.Dir(p.file),
+		steps: []jobStep{{name: "docker compose logs", args: args}}}
+	i.runJob(j, "Following the logs of "+service+"...\n")
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestComposeServices(t *testing.T) {
+	src := `name: shop
+services:
+  # The database
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: secret
+  "cache":
+    image: redis
+  api: # The service itself
+    build: .
+    depends_on: [db, cache]
+volumes:
+  data:
+`
+	want := []string{"db", "cache", "api"}
+	if got := composeServices(src); !slices.Equal(got, want) {
+		t.Errorf("composeServices = %q, want %q", got, want)
+	}
+	if got := composeServices("version: '3'\n"); got != nil {
+		t.Errorf("composeServices without services = %q", got)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// -------------------------------------------------------------------------
+// Persistent Configuration
+// -------------------------------------------------------------------------
+
+const (
+	configDirName        = "ite"         // Directory below the user config dir
+	configFileName       = "config.toml" // Settings file inside configDirName
+	legacyConfigFileName = "config.json" // Settings file of earlier versions, read when configFileName is missing
+	configDirPerms       = 0755          // -rwxr-xr-x
+	configFilePerms      = 0644          // -rw-r--r--
+
+	// Copies of the user's files, such as swap files and undo journals,
+	// are readable by the user only, whatever the mode of the original
+	privateDirPerms  = 0700 // -rwx------
+	privateFilePerms = 0600 // -rw-------
+)
+
+// Config holds the user preferences that survive between sessions.
+// It is stored as TOML in the user configuration directory
+// (e.g. ~/.config/ite/config.toml on Linux), the keys being the names of
+// the json tags, which earlier versions used for a JSON file.
+type Config struct {
+	TypewriterScrolling bool                    `json:"typewriterScrolling"` // Keep the cursor line centered
+	WordChars           string                  `json:"wordChars"`           // Extra characters treated as part of a word
+	UndoGroupMillis     int                     `json:"undoGroupMillis"`     // Typing pause that starts a new undo step
+	LinkedEditing       bool                    `json:"linkedEditing"`       // Mirror edits of a local identifier
+	Theme               string                  `json:"theme"`               // Name of the color theme
+	GracefulStop        bool                    `json:"gracefulStop"`        // Stop commands with SIGTERM instead of SIGKILL
+	FontSize            int                     `json:"fontSize"`            // Point size of the editor font
+	FontFamily          string                  `json:"fontFamily"`          // Family of the editor font
+	TabWidth            int                     `json:"tabWidth"`            // Columns of a tab stop
+	WrapMode            string                  `json:"wrapMode"`            // Line wrapping: none, char or word
+	AutosaveSeconds     int                     `json:"autosaveSeconds"`     // Interval between swap file writes
+	DefaultDir          string                  `json:"defaultDir"`          // Initial directory of the file dialogs, "" to follow DialogDir
+	DialogDir           string                  `json:"dialogDir"`           // Where the file dialogs open without a DefaultDir: project, file or last
+	LastDialogDir       string                  `json:"lastDialogDir"`       // Directory of the latest file chosen in a file dialog
+	HighlightLine       bool                    `json:"highlightLine"`       // Highlight the line holding the cursor
+	SaveOnFocusLoss     bool                    `json:"saveOnFocusLoss"`     // Save modified files when ITE or an editor pane loses the focus
+	ConsoleTimestamps   bool                    `json:"consoleTimestamps"`   // Show the arrival time of console lines
+	ConsoleLog          bool                    `json:"consoleLog"`          // Copy the console output to a log file per session in .ite/logs
+	ConsoleTheme        string                  `json:"consoleTheme"`        // Theme of the consoles, light or dark, "" for the one of the editor
+	ConsoleColors       map[string]string       `json:"consoleColors"`       // Colors of the consoles over their theme: background, foreground, stdout, stderr
+	GuideColumn         int                     `json:"guideColumn"`         // Column of the vertical guide, 0 for none
+	ReflowColumn        int                     `json:"reflowColumn"`        // Width Reflow Comment fills lines to
+	KeyPreset           string                  `json:"keyPreset"`           // Built-in key bindings: default, vim or emacs
+	ToolPaths           map[string]string       `json:"toolPaths"`           // Go tools found by the setup wizard, "" if missing
+	RelativePaths       bool                    `json:"relativePaths"`       // Show paths relative to the project root
+	UseTrash            bool                    `json:"useTrash"`            // Move files ITE deletes to the trash
+	ShowOutline         bool                    `json:"showOutline"`         // Show the symbol outline sidebar
+	Regions             map[string]regionConfig `json:"regions"`             // Sizes and collapsed state of the layout regions
+	Scale               float64                 `json:"scale"`               // Display scaling factor, 0 to detect it
+	Window              *windowState            `json:"window"`              // Placement of the main window, nil before the first exit
+	Snippets            map[string]string       `json:"snippets"`            // Snippet bodies by abbreviation, added to the built-in ones
+	ModalEditing        bool                    `json:"modalEditing"`        // Vim style normal, insert and visual modes in the editor
+	UncachedTests       bool                    `json:"uncachedTests"`       // Run Go Test with -count=1, bypassing the test cache
+	ShowWhitespace      bool                    `json:"showWhitespace"`      // Draw tabs and trailing blanks
+	IndentGuides        bool                    `json:"indentGuides"`        // Draw a guide per indentation level
+	WorkOffline         bool                    `json:"workOffline"`         // Start commands with GOPROXY=off
+	VendorMode          bool                    `json:"vendorMode"`          // Start commands with -mod=vendor in GOFLAGS
+	BuildUnsaved        bool                    `json:"buildUnsaved"`        // Build and run the unsaved buffers through -overlay instead of saving
+	AutoClosePairs      bool                    `json:"autoClosePairs"`      // Insert the closing bracket or quote of the one typed
+	ExternalAPI         bool                    `json:"externalAPI"`         // Serve the API for external tools on a socket
+	Indentation         map[string]indentStyle  `json:"indentation"`         // Indentation by file extension or name, over the built-in styles
+	Autospacing         bool                    `json:"autospacing"`         // Add the spaces around operators in files other than Go
+	Spacing             map[string]spacingRules `json:"spacing"`             // Spacing rules by file extension or name, over the built-in ones
+	MaxUndoSteps        int                     `json:"maxUndoSteps"`        // Undo steps kept per buffer
+	MaxConsoleLines     int                     `json:"maxConsoleLines"`     // Lines kept per console, the oldest dropped first
+	MaxIndexedFiles     int                     `json:"maxIndexedFiles"`     // Files listed by Quick Open per project
+	SpellCheck          bool                    `json:"spellCheck"`          // Underline misspelled words in Go comments and strings
+	SpellDictionary     string                  `json:"spellDictionary"`     // Word list or Hunspell .dic file, "" to look for one
+	CodeHints           bool                    `json:"codeHints"`           // Signature help and hover types from gopls
+	InlineDiagnostics   bool                    `json:"inlineDiagnostics"`   // Show the message of a problem at the end of its line
+	PathCompletion      bool                    `json:"pathCompletion"`      // List the files a path typed in a string literal may go on with
+	Language            string                  `json:"language"`            // Language of the interface, "" for the one of the locale
+	DatabaseDSN         string                  `json:"databaseDSN"`         // Database Run Query connects to, e.g. postgres://localhost/shop
+	MigrationTool       string                  `json:"migrationTool"`       // Tool applying the migrations, migrate or goose, "" to follow their layout
+	LocateInSource      bool                    `json:"locateInSource"`      // Show the source line of the console line selected
+	StickyScroll        bool                    `json:"stickyScroll"`        // Pin the first line of the funcs and types around the top of the editor
+	CompletionPlugins   []string                `json:"completionPlugins"`   // Plugins allowed the buffer to offer completions, none by default
+}
+
+// defaultConfig returns the settings used when no config file exists.
+func defaultConfig() *Config {
+	return &Config{
+		UndoGroupMillis: defaultUndoGroupMillis,
+		Theme:           lightTheme.Name,
+		FontSize:        defaultFontSize,
+		FontFamily:      defaultFontFamily,
+		TabWidth:        defaultTabWidth,
+		WrapMode:        "word",
+		AutosaveSeconds: defaultAutosaveSeconds,
+		HighlightLine:   true,
+		ReflowColumn:    commentColumn,
+		KeyPreset:       keyPresetDefault,
+		RelativePaths:   true,
+		UseTrash:        true,
+		ShowOutline:     true,
+		StickyScroll:    true,
+		AutoClosePairs:  true,
+		PathCompletion:  true,
+		CodeHints:       true,
+		MaxUndoSteps:    defaultMaxUndoSteps,
+		MaxConsoleLines: defaultMaxConsoleLines,
+		MaxIndexedFiles: defaultMaxIndexedFiles,
+	}
+}
+
+// configDir returns the directory holding ITE's configuration files.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configDirName), nil
+}
+
+// configPath returns the absolute path of the settings file.
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// configExists reports whether the settings file has been written, which
+// tells a first launch from the following ones.
+func configExists() bool {
+	path, err := configPath()
+	if err != nil {
+		return true // Don't insist on setup when there's nowhere to save it
+	}
+	for _, p := range []string{path, filepath.Join(filepath.Dir(path), legacyConfigFileName)} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadConfig reads the settings file, or the JSON file of earlier
+// versions when there is none yet; the next save writes it as TOML. A
+// missing file is not an error: the defaults are returned instead. Fields
+// absent from the file keep their default values.
+func loadConfig() (*Config, error) {
+	cfg := defaultConfig()
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(filepath.Join(filepath.Dir(path), legacyConfigFileName))
+		if err == nil {
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return defaultConfig(), err
+			}
+			return cfg, nil
+		}
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := unmarshalTOML(data, cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("%s: %w", configFileName, err)
+	}
+	return cfg, nil
+}
+
+// configHeader starts the settings file.
+const configHeader = `# Settings of ITE, rewritten whenever they change in the editor:
+# comments and the order of the keys aren't kept.
+
+`
+
+// save writes the settings file, creating the config directory if needed.
+func (c *Config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	return c.saveTo(path)
+}
+
+// saveTo writes the settings to path. Holding a DSN, which usually carries
+// a password, the file is readable by the user only, made so before the
+// DSN is written.
+func (c *Config) saveTo(path string) error {
+	perm := os.FileMode(configFilePerms)
+	if c.DatabaseDSN != "" {
+		perm = privateFilePerms
+		if err := os.Chmod(path, perm); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return writeFileAtomic(path, append([]byte(configHeader), marshalTOML(c)...), perm)
+}
+
+// writePrivateFile writes data to path readable by the user only, also
+// when the file exists with a wider mode.
+func writePrivateFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, privateFilePerms); err != nil {
+		return err
+	}
+	return os.Chmod(path, privateFilePerms)
+}
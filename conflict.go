@@ -0,0 +1,269 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Merge Conflicts
+// -------------------------------------------------------------------------
+
+// Files opened with the conflict markers a merge leaves have each side of
+// their conflicts highlighted. While the cursor is in a conflict a bar in
+// the corner of the editor resolves it by taking our side, their side or
+// both, and moves to the other conflicts; the Git menu does the same.
+const (
+	tagConflict       = "conflict"       // Whole conflict, markers included
+	tagConflictOurs   = "conflictours"   // Lines of the current branch
+	tagConflictTheirs = "conflicttheirs" // Lines of the branch merged
+	tagConflictMarker = "conflictmarker" // Marker lines
+
+	conflictStart = "<<<<<<<"
+	conflictBase  = "|||||||" // Common ancestor, with merge.conflictStyle diff3
+	conflictSep   = "======="
+	conflictEnd   = ">>>>>>>"
+)
+
+// mergeConflict holds the lines, counted from 1, of the markers of a
+// conflict.
+type mergeConflict struct {
+	start, base, sep, end int // base is 0 without a common ancestor section
+}
+
+// conflictResolution tells which sides of a conflict to keep.
+type conflictResolution int
+
+const (
+	takeOurs conflictResolution = iota
+	takeTheirs
+	takeBoth
+)
+
+// isConflictMarker reports whether line is the marker, made of the seven
+// characters of marker alone or followed by a space and a label.
+func isConflictMarker(line, marker string) bool {
+	rest, ok := strings.CutPrefix(line, marker)
+	return ok && (rest == "" || rest[0] == ' ')
+}
+
+// parseConflicts returns the complete conflicts of src, in order.
+func parseConflicts(src string) []mergeConflict {
+	var conflicts []mergeConflict
+	var c mergeConflict
+	for n, line := range strings.Split(src, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case isConflictMarker(line, conflictStart):
+			c = mergeConflict{start: n + 1}
+		case c.start == 0:
+		case isConflictMarker(line, conflictBase) && c.sep == 0:
+			c.base = n + 1
+		case line == conflictSep && c.sep == 0:
+			c.sep = n + 1
+		case isConflictMarker(line, conflictEnd) && c.sep != 0:
+			c.end = n + 1
+			conflicts = append(conflicts, c)
+			c = mergeConflict{}
+		}
+	}
+	return conflicts
+}
+
+// oursEnd returns the line after our side of c.
+func (c mergeConflict) oursEnd() int {
+	if c.base != 0 {
+		return c.base
+	}
+	return c.sep
+}
+
+// resolved returns the lines of c in lines, the lines of the buffer,
+// replaced by the sides res keeps.
+func (c mergeConflict) resolved(lines []string, res conflictResolution) []string {
+	ours := lines[c.start : c.oursEnd()-1]
+	theirs := lines[c.sep : c.end-1]
+	switch res {
+	case takeOurs:
+		return ours
+	case takeTheirs:
+		return theirs
+	}
+	return append(slices.Clone(ours), theirs...)
+}
+
+// configureConflictTags styles the sides and markers of conflicts.
+func (i *Ite) configureConflictTags() {
+	i.editText.TagConfigure(tagConflictOurs, Background(theme.DiffOld))
+	i.editText.TagConfigure(tagConflictTheirs, Background(theme.DiffNew))
+	i.editText.TagConfigure(tagConflictMarker, Foreground(theme.Muted), Background(theme.Frame))
+}
+
+// markConflicts highlights the conflicts of the buffer and returns them.
+func (i *Ite) markConflicts() []mergeConflict {
+	for _, tag := range []string{tagConflict, tagConflictOurs, tagConflictTheirs, tagConflictMarker} {
+		i.editText.TagRemove(tag, "1.0", "end")
+	}
+	if i.largeFile || i.loading() {
+		return nil
+	}
+	conflicts := parseConflicts(i.editText.Text())
+	lines := func(tag string, from, to int) {
+		if from < to {
+			i.editText.TagAdd(tag, fmt.Sprintf("%d.0", from), fmt.Sprintf("%d.0", to))
+		}
+	}
+	for _, c := range conflicts {
+		lines(tagConflict, c.start, c.end+1)
+		lines(tagConflictOurs, c.start+1, c.oursEnd())
+		lines(tagConflictTheirs, c.sep+1, c.end)
+		for _, marker := range []int{c.start, c.base, c.sep, c.end} {
+			if marker != 0 {
+				lines(tagConflictMarker, marker, marker+1)
+			}
+		}
+	}
+	i.updateConflictBar()
+	return conflicts
+}
+
+// detectConflicts marks the conflicts of the file just opened and moves
+// the cursor to the first one.
+func (i *Ite) detectConflicts() {
+	conflicts := i.markConflicts()
+	if len(conflicts) == 0 {
+		return
+	}
+	i.goToConflict(conflicts[0])
+	i.showStatusHint(fmt.Sprintf("%d merge conflicts, resolve them from the bar or Git > Conflicts", len(conflicts)))
+}
+
+// conflictAtCursor returns the conflict holding the cursor, its number
+// counted from 1 and the number of conflicts, or 0 when there is none.
+func (i *Ite) conflictAtCursor() (mergeConflict, int, int) {
+	conflicts := parseConflicts(i.editText.Text())
+	line, _ := parseIndex(i.editText.Index("insert"))
+	for n, c := range conflicts {
+		if line >= c.start && line <= c.end {
+			return c, n + 1, len(conflicts)
+		}
+	}
+	return mergeConflict{}, 0, len(conflicts)
+}
+
+// goToConflict moves the cursor to the first line of c.
+func (i *Ite) goToConflict(c mergeConflict) {
+	index := fmt.Sprintf("%d.0", c.start)
+	i.editText.MarkSet("insert", index)
+	i.editText.See(fmt.Sprintf("%d.0", c.end))
+	i.editText.See(index)
+	i.refreshCursorState()
+}
+
+// onNextConflict moves the cursor to the next conflict, wrapping around.
+func (i *Ite) onNextConflict() {
+	i.stepConflict(1)
+}
+
+// onPreviousConflict moves the cursor to the previous conflict, wrapping
+// around.
+func (i *Ite) onPreviousConflict() {
+	i.stepConflict(-1)
+}
+
+// stepConflict moves the cursor to the conflict after it, or before it
+// when dir is negative.
+func (i *Ite) stepConflict(dir int) {
+	conflicts := parseConflicts(i.editText.Text())
+	if len(conflicts) == 0 {
+		i.showStatusHint("No merge conflicts")
+		return
+	}
+	line, _ := parseIndex(i.editText.Index("insert"))
+	if dir < 0 {
+		slices.Reverse(conflicts)
+	}
+	for _, c := range conflicts {
+		if (dir > 0 && c.start > line) || (dir < 0 && c.end < line) {
+			i.goToConflict(c)
+			return
+		}
+	}
+	i.goToConflict(conflicts[0])
+}
+
+// resolveConflict replaces the conflict at the cursor with the sides res
+// keeps, as one undo step.
+func (i *Ite) resolveConflict(res conflictResolution) {
+	c, _, _ := i.conflictAtCursor()
+	if c.start == 0 {
+		i.showStatusHint("The cursor isn't in a merge conflict")
+		return
+	}
+	from, to := fmt.Sprintf("%d.0", c.start), fmt.Sprintf("%d.0", c.end+1)
+	if i.blockProtected(from, to) {
+		return
+	}
+	lines := strings.Split(i.editText.Text(), "\n")
+	text := strings.Join(c.resolved(lines, res), "\n")
+	if text != "" {
+		text += "\n"
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, text)
+	})
+	i.editText.MarkSet("insert", from)
+	left := i.markConflicts()
+	i.refreshCursorState()
+	i.scheduleGutter()
+	if len(left) == 0 {
+		i.showStatusHint("All merge conflicts resolved")
+	} else {
+		i.showStatusHint(fmt.Sprintf("%d merge conflicts left", len(left)))
+	}
+}
+
+// updateConflictBar shows the conflict bar while the cursor is in a
+// conflict, in the corner of the active editor.
+func (i *Ite) updateConflictBar() {
+	if !slices.Contains(i.editText.TagNames("insert"), tagConflict) {
+		if i.conflictBar != nil {
+			tclEval("place forget %s", i.conflictBar)
+		}
+		return
+	}
+	if i.conflictBarText != i.editText {
+		i.makeConflictBar()
+	}
+	_, n, count := i.conflictAtCursor()
+	i.conflictLabel.Configure(Txt(fmt.Sprintf("Conflict %d of %d", n, count)))
+	Place(i.conflictBar, Relx(1), X(px(-4)), Y(px(4)), Anchor("ne"))
+}
+
+// makeConflictBar creates the conflict bar in the active editor, removing
+// the one of another pane.
+func (i *Ite) makeConflictBar() {
+	if i.conflictBar != nil {
+		Destroy(i.conflictBar)
+	}
+	i.conflictBarText = i.editText
+	i.conflictBar = i.editText.TFrame(Relief(RAISED), Borderwidth(1), Padding(px(4)))
+	i.conflictLabel = i.conflictBar.TLabel()
+	Grid(i.conflictLabel, Row(0), Column(0), Padx(px(4)))
+	for n, b := range []struct{ text, id string }{
+		{"◀", "previousConflict"},
+		{"▶", "nextConflict"},
+		{"Ours", "acceptOurs"},
+		{"Theirs", "acceptTheirs"},
+		{"Both", "acceptBoth"},
+	} {
+		Grid(i.conflictBar.TButton(Txt(b.text), Width(len(b.text)+1), Command(i.mustCommand(b.id).run)), Row(0), Column(n+1))
+	}
+}
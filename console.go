@@ -0,0 +1,308 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Output Console
+// -------------------------------------------------------------------------
+
+const (
+	tagLink      = "link"      // Console tag marking clickable source locations
+	tagFiltered  = "filtered"  // Console tag hiding lines rejected by the filter
+	tagStderr    = "stderr"    // Console tag marking standard error output
+	tagStdout    = "stdout"    // Console tag marking standard output
+	tagTimestamp = "timestamp" // Console tag marking the time a line arrived
+	tagRunStart  = "runstart"  // Console tag marking the first line of a run
+
+	consoleTimeFormat = "[15:04:05] " // Timestamp prefixed to console lines
+	streamMargin      = 4             // Pixels of the stripe left of the output of commands
+)
+
+// Output streams the console shows, the choices of the stream box.
+const (
+	streamBoth = iota
+	streamStdout
+	streamStderr
+)
+
+// streamLabels label the choices of the stream box, by stream shown.
+var streamLabels = []string{"Both Streams", "Stdout Only", "Stderr Only"}
+
+// sourceLocRe matches "file.go:line:col" locations as printed by the Go
+// toolchain (go build, go vet, compiler errors). The column is optional.
+var sourceLocRe = regexp.MustCompile(`((?:[A-Za-z]:)?[^\s:]+\.go):(\d+)(?::(\d+))?`)
+
+// colorNameRe matches what may be a Tk color: #rgb forms or a name.
+var colorNameRe = regexp.MustCompile(`^(#[0-9A-Fa-f]{3,12}|[A-Za-z][A-Za-z0-9 ]*)$`)
+
+// configureConsoleTags colors console c with the console theme and sets
+// up its tags and tag bindings. Clearing the console deletes the tags, so
+// this must run again after every Clear.
+func (i *Ite) configureConsoleTags(c *console) {
+	// The tags of every kind of output take the colors of the console
+	// theme, which may not be the one of the editor
+	editorTheme := theme
+	theme = i.consoleTheme()
+	defer func() { theme = editorTheme }()
+	c.text.Configure(textColors()...)
+	c.text.TagConfigure(tagLink, Foreground(theme.Link), Underline(1))
+	c.text.TagConfigure(tagFiltered, Elide(1))
+	// A stripe left of the lines tells the streams apart where the colors
+	// don't
+	shown := i.shownStream()
+	stdout, stderr := cmp.Or(i.consoleColor("stdout"), theme.Foreground), cmp.Or(i.consoleColor("stderr"), theme.Error)
+	c.text.TagConfigure(tagStdout, Foreground(stdout), Lmargin1(px(streamMargin)), Lmargin2(px(streamMargin)),
+		Lmargincolor(theme.Text), Elide(checkValue(shown == streamStderr)))
+	c.text.TagConfigure(tagStderr, Foreground(stderr), Lmargin1(px(streamMargin)), Lmargin2(px(streamMargin)),
+		Lmargincolor(stderr), Elide(checkValue(shown == streamStdout)))
+	c.text.TagConfigure(tagTimestamp, Foreground(theme.Muted), Elide(checkValue(!i.config.ConsoleTimestamps)))
+	c.text.TagConfigure(tagRunStart, Foreground(theme.Muted))
+	i.configureTestTags(c)
+	i.configureDiffTags(c)
+	i.configureLogTags(c)
+	i.configureServerTags(c)
+	i.configureProfileTags(c)
+	i.configureBinarySizeTags(c)
+	c.text.TagBind(tagLink, "<Button-1>", func() { i.onConsoleLinkClick(c) })
+	c.text.TagBind(tagLink, "<Enter>", func() {
+		c.text.Configure(Cursor("hand2"))
+	})
+	c.text.TagBind(tagLink, "<Leave>", func() {
+		c.text.Configure(Cursor("xterm"))
+	})
+}
+
+// linkifyConsole tags the source locations found in the lines from..to
+// of console c.
+func (i *Ite) linkifyConsole(c *console, from, to int) {
+	for line := from; line <= to; line++ {
+		s := lineText(c.text, line)
+		for _, m := range sourceLocRe.FindAllStringIndex(s, -1) {
+			c.text.TagAdd(tagLink,
+				fmt.Sprintf("%d.%d", line, len([]rune(s[:m[0]]))),
+				fmt.Sprintf("%d.%d", line, len([]rune(s[:m[1]]))))
+		}
+	}
+}
+
+// onConsoleLinkClick jumps the editor to the location under the mouse.
+func (i *Ite) onConsoleLinkClick(c *console) {
+	index := c.text.Index("current")
+	line, col := parseIndex(index)
+	s := lineText(c.text, line)
+	for _, m := range sourceLocRe.FindAllStringSubmatchIndex(s, -1) {
+		from, to := len([]rune(s[:m[0]])), len([]rune(s[:m[1]]))
+		if col < from || col >= to {
+			continue
+		}
+		loc := location{path: s[m[2]:m[3]]}
+		loc.line, _ = strconv.Atoi(s[m[4]:m[5]])
+		loc.col = 1
+		if m[6] >= 0 {
+			loc.col, _ = strconv.Atoi(s[m[6]:m[7]])
+		}
+		loc.path = resolveConsolePath(c, loc.path)
+		i.showLocation(loc)
+		return
+	}
+}
+
+// onConsoleClick runs the action attached to the console line under the
+// mouse, if any.
+func (i *Ite) onConsoleClick(c *console) {
+	line, _ := parseIndex(c.text.Index("current"))
+	if click := c.clicks[line]; click != nil {
+		click()
+	}
+}
+
+// -------------------------------------------------------------------------
+// Console Filter
+// -------------------------------------------------------------------------
+
+// makeConsoleFilter creates the filter bar below the console. Lines not
+// matching the filter are hidden, not deleted, so clearing the filter
+// brings the full log back. The stream box hides the standard output or
+// error of the commands the same way. The bar also holds the console
+// options.
+func (i *Ite) makeConsoleFilter() {
+	i.consoleFilterFrame = i.editFrame2.TFrame()
+	label := i.consoleFilterFrame.TLabel(Txt(tr("Filter:")))
+	i.consoleFilter = i.consoleFilterFrame.TEntry(Textvariable(""))
+	i.consoleFilterRegex = i.consoleFilterFrame.TCheckbutton(
+		Txt(tr("Regex")), Variable(0), Command(i.applyConsoleFilter))
+	var streams []string
+	for _, label := range streamLabels {
+		streams = append(streams, tr(label))
+	}
+	i.consoleStream = i.consoleFilterFrame.TCombobox(Values(streams), State("readonly"), Width(12),
+		Textvariable(streams[streamBoth]))
+	i.consoleTimestamps = i.consoleFilterFrame.TCheckbutton(
+		Txt(tr("Timestamps")), Variable(checkValue(i.config.ConsoleTimestamps)), Command(i.onToggleConsoleTimestamps))
+	i.consoleScrollLock = i.consoleFilterFrame.TCheckbutton(Txt(tr("Scroll Lock")), Variable(0))
+	i.consoleLocate = i.consoleFilterFrame.TCheckbutton(
+		Txt(tr("Locate in Source")), Variable(checkValue(i.config.LocateInSource)), Command(i.onToggleLocateInSource))
+	i.consoleLogCheck = i.consoleFilterFrame.TCheckbutton(
+		Txt(tr("Log to File")), Variable(checkValue(i.config.ConsoleLog)), Command(i.onToggleConsoleLog))
+	clearButton := i.consoleFilterFrame.TButton(Txt(tr("Clear")), Command(i.onClearConsole))
+	Grid(label, Row(0), Column(0), Padx(px(2)))
+	Grid(i.consoleFilter, Row(0), Column(1), Sticky(WE))
+	Grid(i.consoleFilterRegex, Row(0), Column(2), Padx(px(2)))
+	Grid(i.consoleStream, Row(0), Column(3), Padx(px(2)))
+	Grid(i.consoleTimestamps, Row(0), Column(4), Padx(px(2)))
+	Grid(i.consoleScrollLock, Row(0), Column(5), Padx(px(2)))
+	Grid(i.consoleLocate, Row(0), Column(6), Padx(px(2)))
+	Grid(i.consoleLogCheck, Row(0), Column(7), Padx(px(2)))
+	Grid(clearButton, Row(0), Column(8), Padx(px(2)))
+	GridColumnConfigure(i.consoleFilterFrame, 1, Weight(1))
+
+	Bind(i.consoleStream, "<<ComboboxSelected>>", Command(func() {
+		for _, c := range i.consoles {
+			i.configureConsoleTags(c)
+		}
+	}))
+
+	Bind(i.consoleFilter, "<KeyRelease>", Command(i.applyConsoleFilter))
+	Bind(i.consoleFilter, "<Escape>", Command(func() {
+		i.consoleFilter.Configure(Textvariable(""))
+		i.applyConsoleFilter()
+	}))
+}
+
+// applyConsoleFilter compiles the filter typed by the user and applies it
+// to every console.
+func (i *Ite) applyConsoleFilter() {
+	pattern := i.consoleFilter.Textvariable()
+	if i.consoleFilterRegex.Variable() != "1" {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		i.consoleFilter.Configure(Foreground(theme.Error))
+		return // Keep the previous filter while the regex is incomplete
+	}
+	i.consoleFilter.Configure(Foreground(theme.Foreground))
+	if pattern == "" {
+		re = nil
+	}
+	i.filterRe = re
+	for _, c := range i.consoles {
+		last, _ := parseIndex(c.text.Index("end-1c"))
+		i.filterConsoleLines(c, 1, last)
+	}
+}
+
+// shownStream returns the output stream the consoles show, streamBoth,
+// streamStdout or streamStderr.
+func (i *Ite) shownStream() int {
+	if i.consoleStream == nil {
+		return streamBoth
+	}
+	for n, label := range streamLabels {
+		if tr(label) == i.consoleStream.Textvariable() {
+			return n
+		}
+	}
+	return streamBoth
+}
+
+// filterConsoleLines hides the lines from..to of console c that don't
+// match the current filter and shows the others.
+func (i *Ite) filterConsoleLines(c *console, from, to int) {
+	c.text.TagRemove(tagFiltered, fmt.Sprintf("%d.0", from), fmt.Sprintf("%d.0", to+1))
+	if i.filterRe == nil {
+		return
+	}
+	for line := from; line <= to; line++ {
+		if !i.filterRe.MatchString(lineText(c.text, line)) {
+			c.text.TagAdd(tagFiltered, fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.0", line+1))
+		}
+	}
+}
+
+// -------------------------------------------------------------------------
+// Console Options
+// -------------------------------------------------------------------------
+
+// onClearConsole empties the selected console. A command still running
+// keeps writing to it.
+func (i *Ite) onClearConsole() {
+	c := i.selectedConsole()
+	c.text.Configure(State("normal"))
+	c.text.Clear()
+	c.text.Configure(State("disabled"))
+	i.configureConsoleTags(c)
+	c.clicks = make(map[int]func())
+}
+
+// onToggleConsoleTimestamps shows or hides the time at which each console
+// line arrived and persists the choice.
+func (i *Ite) onToggleConsoleTimestamps() {
+	i.config.ConsoleTimestamps = !i.config.ConsoleTimestamps
+	i.consoleTimestamps.Configure(Variable(checkValue(i.config.ConsoleTimestamps)))
+	i.saveConfig()
+	for _, c := range i.consoles {
+		c.text.TagConfigure(tagTimestamp, Elide(checkValue(!i.config.ConsoleTimestamps)))
+	}
+}
+
+// onToggleScrollLock stops or resumes following new console output.
+func (i *Ite) onToggleScrollLock() {
+	locked := i.consoleScrollLock.Variable() == "1"
+	i.consoleScrollLock.Configure(Variable(checkValue(!locked)))
+}
+
+// consoleTheme returns the theme of the consoles: the one set for them,
+// else the one of the editor, with the background and foreground of the
+// ConsoleColors setting.
+func (i *Ite) consoleTheme() *colorTheme {
+	t := theme
+	if i.config.ConsoleTheme != "" {
+		t = themeByName(i.config.ConsoleTheme)
+	}
+	bg, fg := i.consoleColor("background"), i.consoleColor("foreground")
+	if bg == "" && fg == "" {
+		return t
+	}
+	custom := *t
+	custom.Text, custom.Foreground = cmp.Or(bg, t.Text), cmp.Or(fg, t.Foreground)
+	return &custom
+}
+
+// consoleColor returns the color key, "background", "foreground",
+// "stdout" or "stderr", of the ConsoleColors setting, "" if unset or not a
+// color Tk knows.
+func (i *Ite) consoleColor(key string) string {
+	color := strings.TrimSpace(i.config.ConsoleColors[key])
+	if color == "" || !colorNameRe.MatchString(color) || tclEval("expr {![catch {winfo rgb . {%s}}]}", color) != "1" {
+		return ""
+	}
+	return color
+}
+
+// onToggleConsoleTheme switches the consoles between the light and the
+// dark theme and persists the choice. The theme of the editor is kept as
+// following it.
+func (i *Ite) onToggleConsoleTheme() {
+	next := darkTheme.Name
+	if i.consoleTheme().Name == darkTheme.Name {
+		next = lightTheme.Name
+	}
+	i.config.ConsoleTheme = next
+	if next == themeByName(i.config.Theme).Name {
+		i.config.ConsoleTheme = ""
+	}
+	i.saveConfig()
+	i.applyTheme()
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Console Log
+// -------------------------------------------------------------------------
+
+// With Log to File on, the output of the consoles is also appended to a
+// log file of the session in the .ite/logs directory of the project the
+// command runs in, each line with its time and the name of its console,
+// so it can be read after the console is cleared or trimmed.
+const (
+	consoleLogDir        = "logs"            // Directory of the logs inside projectConfigDir
+	consoleLogTimeFormat = "20060102-150405" // Start of the session in the log file names
+)
+
+// consoleLogState holds the log files open in the session.
+type consoleLogState struct {
+	session string              // Start of the session, naming its log files
+	files   map[string]*os.File // Log files by project root, nil after a failed open
+}
+
+// consoleLogText returns text as written to the log of console name: each
+// line prefixed with stamp and the console.
+func consoleLogText(name, stamp, text string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		sb.WriteString(stamp)
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// consoleLogPath returns the path of the log file of the session for the
+// project at root.
+func (i *Ite) consoleLogPath(root string) string {
+	if i.consoleLog.session == "" {
+		i.consoleLog.session = time.Now().Format(consoleLogTimeFormat)
+	}
+	return filepath.Join(root, projectConfigDir, consoleLogDir, "console-"+i.consoleLog.session+".log")
+}
+
+// logConsole appends text, output of console c, to the log of the project
+// of the latest run of c, when logging is on.
+func (i *Ite) logConsole(c *console, text string) {
+	if !i.config.ConsoleLog || c.root == "" {
+		return
+	}
+	f, ok := i.consoleLog.files[c.root]
+	if !ok {
+		f = i.openConsoleLog(c.root)
+	}
+	if f == nil {
+		return
+	}
+	if _, err := f.WriteString(consoleLogText(c.name, time.Now().Format(consoleTimeFormat), text)); err != nil {
+		i.showStatusHint("Writing the console log: " + err.Error())
+		f.Close()
+		i.consoleLog.files[c.root] = nil
+	}
+}
+
+// openConsoleLog opens the log of the session for the project at root,
+// creating it. It returns nil, not trying again, if that fails.
+func (i *Ite) openConsoleLog(root string) *os.File {
+	if i.consoleLog.files == nil {
+		i.consoleLog.files = make(map[string]*os.File)
+	}
+	path := i.consoleLogPath(root)
+	var f *os.File
+	err := os.MkdirAll(filepath.Dir(path), configDirPerms)
+	if err == nil {
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, defaultFilePerms)
+	}
+	if err != nil {
+		i.showStatusHint("Opening the console log: " + err.Error())
+		f = nil
+	}
+	i.consoleLog.files[root] = f
+	return f
+}
+
+// closeConsoleLogs closes the log files of the session.
+func (i *Ite) closeConsoleLogs() {
+	for root, f := range i.consoleLog.files {
+		if f != nil {
+			f.Close()
+		}
+		delete(i.consoleLog.files, root)
+	}
+}
+
+// onToggleConsoleLog starts or stops copying the console output to the
+// log files, and persists the choice.
+func (i *Ite) onToggleConsoleLog() {
+	i.config.ConsoleLog = !i.config.ConsoleLog
+	i.consoleLogCheck.Configure(Variable(checkValue(i.config.ConsoleLog)))
+	i.saveConfig()
+	if !i.config.ConsoleLog {
+		i.closeConsoleLogs()
+		i.showStatusHint("Console output is no longer logged")
+		return
+	}
+	if i.currentFile != "" {
+		i.showStatusHint("Console output is logged to " + relativeTo(i.projectDir(), i.consoleLogPath(i.projectDir())))
+	}
+}
+
+// onOpenConsoleLog opens the log of the session for the current project.
+func (i *Ite) onOpenConsoleLog() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	path := i.consoleLogPath(i.projectDir())
+	if !fileExists(path) {
+		i.showStatusHint("No console output logged for this project in this session")
+		return
+	}
+	i.showLocation(location{path: path, line: 1, col: 1})
+}
@@ -0,0 +1,14 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestConsoleLogText(t *testing.T) {
+	got := consoleLogText("Build", "[10:00:00] ", "main.go:3:1: undefined: x\n\nBuild failed\n")
+	want := "[10:00:00] Build: main.go:3:1: undefined: x\n[10:00:00] Build: \n[10:00:00] Build: Build failed\n"
+	if got != want {
+		t.Errorf("consoleLogText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Test Coverage
+// -------------------------------------------------------------------------
+
+// Go Test with Coverage runs the tests with -cover and records the
+// coverage of every package in the project's settings directory. The
+// report ends with the coverage of each package, how much it moved since
+// the previous run and a sparkline of the last runs.
+const (
+	coverageFileName = "coverage.json" // Coverage history, in the project settings directory
+	maxCoverageRuns  = 20              // Runs remembered per package
+	statusCoverage   = "Testing with coverage...\n"
+)
+
+// coverageLine matches the coverage go test reports for a package.
+var coverageLine = regexp.MustCompile(`coverage: ([0-9.]+)% of statements`)
+
+// sparkTicks draw the coverage history, from the lowest to the highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// coverageSample is the coverage of a package in one run.
+type coverageSample struct {
+	Time    time.Time `json:"time"`
+	Percent float64   `json:"percent"`
+}
+
+// coverageHistory is the content of the coverage file: the samples of
+// each package, oldest first.
+type coverageHistory map[string][]coverageSample
+
+// onGoCoverage runs `go test -cover ./...` for the module of the current
+// file, reports how the coverage of its packages changed and tints the
+// lines of the editor the tests covered and missed.
+func (i *Ite) onGoCoverage() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	args := []string{"test", "-json", "-fullpath", "-cover"}
+	if i.config.UncachedTests {
+		args = append(args, "-count=1")
+	}
+	root := projectRoot(i.currentFile)
+	profile := filepath.Join(root, projectConfigDir, coverProfileName)
+	if err := os.MkdirAll(filepath.Dir(profile), configDirPerms); err != nil {
+		i.showError("Go Test with Coverage: " + err.Error())
+		return
+	}
+	os.Remove(profile) // A failed build leaves no stale profile behind
+	d := newTestDecoder()
+	d.coverage = make(map[string]float64)
+	d.historyPath = filepath.Join(root, projectConfigDir, coverageFileName)
+	d.done = func() { i.Dispatch(func() { i.loadCoverMarks(profile, root) }) }
+	args = append(args, "-coverprofile", profile)
+	i.runCommand(i.console(consoleTest), append(args, "./..."), statusCoverage, d)
+}
+
+// packageCoverage returns the coverage reported in the output of a
+// package, if any.
+func packageCoverage(output []string) (float64, bool) {
+	for _, out := range output {
+		if m := coverageLine.FindStringSubmatch(out); m != nil {
+			percent, err := strconv.ParseFloat(m[1], 64)
+			return percent, err == nil
+		}
+	}
+	return 0, false
+}
+
+// coverageReport adds the coverage of the run to the history file and
+// returns the report of the packages covered. Failing to update the
+// history only loses the trend.
+func (d *testDecoder) coverageReport() []consoleMsg {
+	if len(d.coverage) == 0 {
+		return nil
+	}
+	history := readCoverageHistory(d.historyPath)
+	now := time.Now()
+	pkgs := make([]string, 0, len(d.coverage))
+	for pkg := range d.coverage {
+		pkgs = append(pkgs, pkg)
+	}
+	slices.Sort(pkgs)
+	width := 0
+	for _, pkg := range pkgs {
+		width = max(width, len(pkg))
+	}
+
+	msgs := []consoleMsg{{text: "\nCoverage\n"}}
+	for _, pkg := range pkgs {
+		percent := d.coverage[pkg]
+		samples := history[pkg]
+		msg := consoleMsg{text: fmt.Sprintf("%-*s %5.1f%%", width, pkg, percent)}
+		if len(samples) > 0 {
+			switch delta := percent - samples[len(samples)-1].Percent; {
+			case delta > 0.05:
+				msg.text += fmt.Sprintf(" %+5.1f", delta)
+				msg.tag = tagTestPass
+			case delta < -0.05:
+				msg.text += fmt.Sprintf(" %+5.1f", delta)
+				msg.tag = tagTestFail
+			default:
+				msg.text += "     ="
+			}
+		} else {
+			msg.text += "   new"
+		}
+		samples = append(samples, coverageSample{Time: now, Percent: percent})
+		if len(samples) > maxCoverageRuns {
+			samples = samples[len(samples)-maxCoverageRuns:]
+		}
+		history[pkg] = samples
+		msg.text += "  " + sparkline(samples) + "\n"
+		msgs = append(msgs, msg)
+	}
+	if err := writeCoverageHistory(d.historyPath, history); err != nil {
+		msgs = append(msgs, consoleMsg{text: "Error saving the coverage history: " + err.Error() + "\n", tag: tagTestFail})
+	}
+	return msgs
+}
+
+// sparkline draws the percentages of samples on a 0-100 scale.
+func sparkline(samples []coverageSample) string {
+	var b strings.Builder
+	for _, s := range samples {
+		n := int(s.Percent / 100 * float64(len(sparkTicks)-1))
+		b.WriteRune(sparkTicks[min(max(n, 0), len(sparkTicks)-1)])
+	}
+	return b.String()
+}
+
+// readCoverageHistory reads the coverage file at path. A missing or
+// unreadable file yields an empty history.
+func readCoverageHistory(path string) coverageHistory {
+	history := coverageHistory{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &history)
+	}
+	if history == nil {
+		history = coverageHistory{}
+	}
+	return history
+}
+
+// writeCoverageHistory writes history to the coverage file at path.
+func writeCoverageHistory(path string, history coverageHistory) error {
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(history, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), configFilePerms)
+}
+
+// -------------------------------------------------------------------------
+// Coverage Marks
+// -------------------------------------------------------------------------
+
+// The coverage run also writes a profile, from which the editor tints the
+// statements the tests ran and those they missed, in the current file and
+// in the files opened later, until Clear Coverage Marks or a change of the
+// file since the run.
+const (
+	coverProfileName = "cover.out" // Coverage profile, in the project settings directory
+	tagCovered       = "covered"   // Editor tag of lines run by the tests
+	tagUncovered     = "uncovered" // Editor tag of lines the tests missed
+)
+
+// coverBlock is a block of statements of the coverage profile.
+type coverBlock struct {
+	startLine, endLine int
+	count              int // Times the block ran, 0 if never
+}
+
+// coverMarks are the blocks of the last coverage run, by file.
+type coverMarks struct {
+	time   time.Time // When the profile was written
+	blocks map[string][]coverBlock
+}
+
+// readCoverProfile reads the coverage profile at path, written by the
+// tests of the module rooted at root, and returns its blocks by absolute
+// file path. Blocks of files outside the module are left out.
+func readCoverProfile(path, root string) (*coverMarks, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	mod := modulePath(filepath.Join(root, "go.mod"))
+	if mod == "" {
+		return nil, fmt.Errorf("no module path in %s", filepath.Join(root, "go.mod"))
+	}
+	marks := &coverMarks{time: info.ModTime(), blocks: make(map[string][]coverBlock)}
+	for _, line := range strings.Split(string(data), "\n") {
+		// name.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[1] == "0" {
+			continue
+		}
+		colon := strings.LastIndexByte(fields[0], ':')
+		if colon < 0 {
+			continue
+		}
+		rel, ok := strings.CutPrefix(fields[0][:colon], mod+"/")
+		if !ok {
+			continue
+		}
+		start, end, ok := strings.Cut(fields[0][colon+1:], ",")
+		if !ok {
+			continue
+		}
+		var b coverBlock
+		b.startLine, _ = strconv.Atoi(start[:max(strings.IndexByte(start, '.'), 0)])
+		b.endLine, _ = strconv.Atoi(end[:max(strings.IndexByte(end, '.'), 0)])
+		b.count, _ = strconv.Atoi(fields[2])
+		if b.startLine == 0 || b.endLine < b.startLine {
+			continue
+		}
+		file := filepath.Join(root, filepath.FromSlash(rel))
+		marks.blocks[file] = append(marks.blocks[file], b)
+	}
+	return marks, nil
+}
+
+// modulePath returns the module path declared by the go.mod file at path,
+// or "" if it can't be read.
+func modulePath(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			rest, _, _ = strings.Cut(rest, "//")
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// loadCoverMarks reads the profile of the coverage run in root and tints
+// the current file.
+func (i *Ite) loadCoverMarks(profile, root string) {
+	marks, err := readCoverProfile(profile, root)
+	if err != nil {
+		i.showStatusHint("Coverage marks unavailable: " + err.Error())
+		return
+	}
+	i.coverMarks = marks
+	i.markCoverage()
+}
+
+// markCoverage tints the lines of the current file the last coverage run
+// covered and missed. Lines holding both kinds of statements show as
+// missed. Files changed since the run are left alone, as their lines may
+// have moved.
+func (i *Ite) markCoverage() {
+	i.editText.TagRemove(tagCovered, "1.0", "end")
+	i.editText.TagRemove(tagUncovered, "1.0", "end")
+	if i.coverMarks == nil || i.currentFile == "" {
+		return
+	}
+	var blocks []coverBlock
+	for path, b := range i.coverMarks.blocks {
+		if samePath(path, i.currentFile) {
+			blocks = b
+			break
+		}
+	}
+	if len(blocks) == 0 {
+		return
+	}
+	if info, err := os.Stat(i.currentFile); err != nil || info.ModTime().After(i.coverMarks.time) {
+		return
+	}
+	for _, tag := range []string{tagCovered, tagUncovered} {
+		for _, b := range blocks {
+			if (b.count > 0) == (tag == tagCovered) {
+				i.editText.TagAdd(tag, fmt.Sprintf("%d.0", b.startLine), fmt.Sprintf("%d.0", b.endLine+1))
+			}
+		}
+	}
+}
+
+// onClearCoverage removes the coverage tints of all panes and forgets the
+// last run.
+func (i *Ite) onClearCoverage() {
+	i.coverMarks = nil
+	for _, p := range i.panes {
+		p.text.TagRemove(tagCovered, "1.0", "end")
+		p.text.TagRemove(tagUncovered, "1.0", "end")
+	}
+}
+
+// configureCoverageTags styles the coverage tints below the selection
+// and the cursor line.
+func (i *Ite) configureCoverageTags() {
+	i.editText.TagConfigure(tagCovered, Background(theme.DiffNew))
+	i.editText.TagConfigure(tagUncovered, Background(theme.DiffOld))
+	tclEval("%s tag lower %s sel", i.editText, tagCovered)
+	tclEval("%s tag lower %s sel", i.editText, tagUncovered)
+}
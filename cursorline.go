@@ -0,0 +1,63 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Current Line and Column Guide
+// -------------------------------------------------------------------------
+
+const tagCurrentLine = "currentline" // Text tag marking the cursor line
+
+// guideColumns are the columns offered for the column guide, 0 for none.
+var guideColumns = []int{0, 80, 100}
+
+// configureCurrentLineTag styles the cursor line tag and puts it below all
+// the other tags, so the selection and the highlights stay visible on it.
+func (i *Ite) configureCurrentLineTag() {
+	i.editText.TagConfigure(tagCurrentLine, Background(theme.CurrentLine))
+	tclEval("%s tag lower %s", i.editText, tagCurrentLine)
+}
+
+// updateCurrentLine moves the highlight to the line holding the cursor.
+// The tag covers the newline so the background reaches the right edge.
+func (i *Ite) updateCurrentLine() {
+	i.editText.TagRemove(tagCurrentLine, "1.0", "end")
+	if i.config.HighlightLine {
+		i.editText.TagAdd(tagCurrentLine, "insert linestart", "insert lineend +1c")
+	}
+}
+
+// makeColumnGuide creates the vertical line drawn at the configured
+// column. makeProseGuide must run first: the guide uses its font.
+func (i *Ite) makeColumnGuide() {
+	i.columnGuide = i.editText.Frame(Background(theme.Guide))
+	i.updateColumnGuide()
+}
+
+// updateColumnGuide places the column guide, or hides it when no column
+// is configured.
+func (i *Ite) updateColumnGuide() {
+	column := i.config.GuideColumn
+	if column <= 0 {
+		Place(i.columnGuide, Width(0))
+		return
+	}
+	x := textInset + i.editorFont.Measure(i.editText.Window, strings.Repeat("0", column))
+	Place(i.columnGuide, X(x), Y(0), Width(1), Relheight(1))
+}
+
+// guideColumnLabel names a column guide choice in the Preferences dialog.
+func guideColumnLabel(column int) string {
+	if column <= 0 {
+		return "none"
+	}
+	return strconv.Itoa(column)
+}
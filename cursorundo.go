@@ -0,0 +1,105 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+// -------------------------------------------------------------------------
+// Cursor Undo
+// -------------------------------------------------------------------------
+
+// Cursor Undo (Ctrl+Alt+Z) moves the cursor back to where it was before
+// it left a line, without undoing any edit, and Cursor Redo (Ctrl+Alt+
+// Shift+Z) forward again. Moves within a line and those of edits adding or
+// removing lines are not recorded, and a run of single line steps, holding
+// the Down arrow, counts as one move. Each pane has its own history, which
+// restarts with the buffer.
+const maxCursorHistory = 100
+
+// cursorHistory holds the cursor positions of a buffer, as text indices.
+type cursorHistory struct {
+	back    []string // Positions left, oldest first
+	forward []string // Positions undone, latest undone last
+	at      string   // Position last seen
+	lines   int      // Lines of the buffer when at was seen
+	stepped bool     // at was reached by a single line step
+}
+
+// moved records that the cursor went to pos, the buffer having lines
+// lines: a change in their number means an edit moved the cursor.
+func (h *cursorHistory) moved(pos string, lines int) {
+	if pos == h.at && lines == h.lines {
+		return
+	}
+	edited := lines != h.lines
+	from, _ := parseIndex(h.at)
+	to, _ := parseIndex(pos)
+	step := from-to == 1 || to-from == 1
+	if h.at != "" && from != to && !edited && !(step && h.stepped) {
+		if n := len(h.back); n == 0 || h.back[n-1] != h.at {
+			h.back = append(h.back, h.at)
+			if len(h.back) > maxCursorHistory {
+				h.back = h.back[1:]
+			}
+		}
+		h.forward = nil
+	}
+	if h.at != "" && from != to {
+		h.stepped = step && !edited
+	}
+	h.at, h.lines = pos, lines
+}
+
+// undo returns the position before the current one, moving to it.
+func (h *cursorHistory) undo() (string, bool) {
+	if len(h.back) == 0 {
+		return "", false
+	}
+	h.forward = append(h.forward, h.at)
+	h.at = h.back[len(h.back)-1]
+	h.back = h.back[:len(h.back)-1]
+	h.stepped = false
+	return h.at, true
+}
+
+// redo returns the position undone last, moving to it.
+func (h *cursorHistory) redo() (string, bool) {
+	if len(h.forward) == 0 {
+		return "", false
+	}
+	h.back = append(h.back, h.at)
+	h.at = h.forward[len(h.forward)-1]
+	h.forward = h.forward[:len(h.forward)-1]
+	h.stepped = false
+	return h.at, true
+}
+
+// recordCursor adds the cursor of the editor to its history.
+func (i *Ite) recordCursor() {
+	lines, _ := parseIndex(i.editText.Index("end"))
+	i.cursors.moved(i.editText.Index("insert"), lines)
+}
+
+// onCursorUndo moves the cursor to its previous position.
+func (i *Ite) onCursorUndo() {
+	i.stepCursorHistory(i.cursors.undo, "No earlier cursor position")
+}
+
+// onCursorRedo moves the cursor to the position Cursor Undo left.
+func (i *Ite) onCursorRedo() {
+	i.stepCursorHistory(i.cursors.redo, "No later cursor position")
+}
+
+// stepCursorHistory moves the cursor to the position step returns, or
+// shows none if there is none.
+func (i *Ite) stepCursorHistory(step func() (string, bool), none string) {
+	pos, ok := step()
+	if !ok {
+		i.showStatusHint(none)
+		return
+	}
+	// The buffer may have shrunk since; record the position Tk clamps it to
+	pos = i.editText.Index(pos)
+	i.cursors.at = pos
+	line, col := parseIndex(pos)
+	i.jumpTo(line, col)
+}
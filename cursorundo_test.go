@@ -0,0 +1,35 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestCursorHistory(t *testing.T) {
+	var h cursorHistory
+	for _, pos := range []string{"1.0", "1.4", "10.2", "11.2", "12.2", "13.0", "40.7"} {
+		h.moved(pos, 50)
+	}
+	h.moved("41.0", 51) // Return typed, not recorded
+	h.moved("41.3", 51)
+	// 11.2 and 12.2 are in a run of single line steps
+	for _, want := range []string{"13.0", "10.2", "1.4"} {
+		if got, ok := h.undo(); !ok || got != want {
+			t.Fatalf("undo() = %q, %v; want %q", got, ok, want)
+		}
+	}
+	if got, ok := h.undo(); ok {
+		t.Fatalf("undo() at the oldest position = %q", got)
+	}
+	if got, ok := h.redo(); !ok || got != "10.2" {
+		t.Fatalf("redo() = %q, %v; want 10.2", got, ok)
+	}
+	h.moved(h.at, 51) // The jump to the position
+	h.moved("20.0", 51)
+	if got, ok := h.redo(); ok {
+		t.Errorf("redo() after a new move = %q", got)
+	}
+	if got, _ := h.undo(); got != "10.2" {
+		t.Errorf("undo() after a new move = %q, want 10.2", got)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Dialogs
+// -------------------------------------------------------------------------
+
+// messageBox shows a modal message box and returns the button chosen. The
+// UI tests replace it to answer the prompts without a user.
+var messageBox = MessageBox
+
+// promptString opens a small modal dialog asking for a single line of text.
+// onOK is called with the entered value when the user confirms.
+func (i *Ite) promptString(title, label, initial string, onOK func(string)) {
+	dialog := Toplevel()
+	dialog.WmTitle(title)
+
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	Grid(frame.TLabel(Txt(label)), Row(0), Column(0), Sticky(W), Pady(px(5)))
+	entry := frame.TEntry(Width(40), Textvariable(initial))
+	Grid(entry, Row(1), Column(0), Pady(px(5)))
+	Focus(entry)
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(2), Column(0), Pady(px(10)))
+
+	confirm := func() {
+		value := entry.Textvariable()
+		Destroy(dialog)
+		Focus(i.editText)
+		onOK(value)
+	}
+	cancel := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+
+	Grid(btnFrame.TButton(Txt(tr("OK")), Command(confirm)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt(tr("Cancel")), Command(cancel)), Row(0), Column(1), Padx(px(5)))
+
+	Bind(entry, "<Return>", Command(confirm))
+	Bind(dialog, "<Escape>", Command(cancel))
+}
+
+// showTextWindow opens a window displaying text read-only.
+func showTextWindow(title, text string) {
+	dialog := Toplevel()
+	dialog.WmTitle(title)
+	lines := strings.Count(text, "\n") + 1
+	view := dialog.Text(textStyle(), Width(80), Height(min(max(lines, 5), 30)))
+	view.Insert("end", text)
+	view.Configure(State("disabled"))
+	scrollbar := dialog.TScrollbar(Command(func(e *Event) { e.Yview(view) }))
+	view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(view, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+	Bind(dialog, "<Escape>", Command(func() { Destroy(dialog) }))
+}
+
+// -------------------------------------------------------------------------
+// Error Dialog
+// -------------------------------------------------------------------------
+
+const (
+	errorSummaryMax = 160 // Longest error message shown without a Details section
+	errorWrapLength = 420 // Width in pixels at which the summary wraps
+)
+
+// splitErrorMessage divides msg into a one-line summary and, when msg is
+// too long or spans several lines, the full text to show as details.
+func splitErrorMessage(msg string) (summary, detail string) {
+	msg = strings.TrimSpace(msg)
+	first, _, multiline := strings.Cut(msg, "\n")
+	runes := []rune(first)
+	if !multiline && len(runes) <= errorSummaryMax {
+		return msg, ""
+	}
+	if len(runes) > errorSummaryMax {
+		first = string(runes[:errorSummaryMax]) + "…"
+	}
+	return first, msg
+}
+
+// showErrorDetail opens a modal error dialog with msg. A non-empty detail,
+// such as the full stderr of a tool or a stack trace, goes into a
+// collapsible "Details" section. Copy puts the whole error on the
+// clipboard. Like MessageBox, it returns once the dialog is closed.
+func (i *Ite) showErrorDetail(msg, detail string) {
+	dialog := Toplevel()
+	dialog.WmTitle(tr("Error"))
+	WmTransient(dialog, App)
+
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Sticky(NEWS), Padx(px(10)), Pady(px(10)))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+	Grid(frame.TLabel(Txt(msg), Wraplength(px(errorWrapLength)), Justify("left")),
+		Row(0), Column(0), Sticky(W), Pady(px(5)))
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(2), Column(0), Sticky(E), Pady(px(5)))
+	closeDialog := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+	copyError := func() {
+		ClipboardClear()
+		if detail != "" {
+			ClipboardAppend(detail)
+		} else {
+			ClipboardAppend(msg)
+		}
+	}
+
+	col := 0
+	if detail != "" {
+		detailFrame := frame.TFrame()
+		view := detailFrame.Text(textStyle(), Width(80), Height(15), Wrap("none"))
+		view.Insert("end", detail)
+		view.Configure(State("disabled"))
+		scrollbar := detailFrame.TScrollbar(Command(func(e *Event) { e.Yview(view) }))
+		view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+		Grid(view, Row(0), Column(0), Sticky(NEWS))
+		Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+		GridRowConfigure(detailFrame, 0, Weight(1))
+		GridColumnConfigure(detailFrame, 0, Weight(1))
+		GridColumnConfigure(frame, 0, Weight(1))
+
+		shown := false
+		var toggle *TButtonWidget
+		toggle = btnFrame.TButton(Txt(tr("Details ▸")), Command(func() {
+			shown = !shown
+			if shown {
+				Grid(detailFrame, Row(1), Column(0), Sticky(NEWS))
+				GridRowConfigure(frame, 1, Weight(1))
+				toggle.Configure(Txt(tr("Details ▾")))
+			} else {
+				GridRemove(detailFrame.Window)
+				GridRowConfigure(frame, 1, Weight(0))
+				toggle.Configure(Txt(tr("Details ▸")))
+			}
+		}))
+		Grid(toggle, Row(0), Column(col), Padx(px(5)))
+		col++
+	}
+	Grid(btnFrame.TButton(Txt(tr("Copy")), Command(copyError)), Row(0), Column(col), Padx(px(5)))
+	ok := btnFrame.TButton(Txt(tr("OK")), Command(closeDialog))
+	Grid(ok, Row(0), Column(col+1), Padx(px(5)))
+	Focus(ok)
+
+	Bind(dialog, "<Return>", Command(closeDialog))
+	Bind(dialog, "<Escape>", Command(closeDialog))
+	WmProtocol(dialog.Window, "WM_DELETE_WINDOW", closeDialog)
+
+	// Modal like MessageBox: block input elsewhere until closed
+	tclEval("tkwait visibility %[1]s; grab set %[1]s; tkwait window %[1]s", dialog)
+}
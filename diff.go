@@ -0,0 +1,289 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Line Diff
+// -------------------------------------------------------------------------
+
+// diffOp is the kind of a diffLine.
+type diffOp int
+
+const (
+	diffEqual  diffOp = iota // Line in both texts
+	diffDelete               // Line only in the old text
+	diffInsert               // Line only in the new text
+)
+
+// diffLine is a line of the edit script turning one text into another.
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// maxDiffLines is the most lines, of both texts together and leaving out
+// their common start and end, that the callers of lineDiff compare: its
+// time grows with their number times the number of changes.
+const maxDiffLines = 10000
+
+// commonEnds returns the number of lines a and b share at their start,
+// pre, and after that at their end, suf.
+func commonEnds(a, b []string) (pre, suf int) {
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	return pre, suf
+}
+
+// diffTooLarge reports whether a and b differ in more than maxDiffLines
+// lines, too many for lineDiff to compare without the user waiting.
+func diffTooLarge(a, b []string) bool {
+	pre, suf := commonEnds(a, b)
+	return len(a)+len(b)-2*(pre+suf) > maxDiffLines
+}
+
+// lineDiff returns a shortest edit script from a to b using the linear
+// space variant of Myers' O(ND) algorithm: the memory used grows with the
+// length of the texts only, not with the number of changes.
+func lineDiff(a, b []string) []diffLine {
+	return appendDiff(make([]diffLine, 0, max(len(a), len(b))), a, b)
+}
+
+// appendDiff appends to script the edit script from a to b.
+func appendDiff(script []diffLine, a, b []string) []diffLine {
+	pre, suf := commonEnds(a, b)
+	for _, l := range a[:pre] {
+		script = append(script, diffLine{diffEqual, l})
+	}
+	common := a[len(a)-suf:]
+	a, b = a[pre:len(a)-suf], b[pre:len(b)-suf]
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		script = appendReplace(script, a, b)
+	case len(a) == 1 || len(b) == 1:
+		script = appendSingleDiff(script, a, b)
+	default:
+		x, y, ok := middleSnake(a, b)
+		if ok {
+			script = appendDiff(script, a[:x], b[:y])
+			script = appendDiff(script, a[x:], b[y:])
+		} else {
+			script = appendReplace(script, a, b)
+		}
+	}
+	for _, l := range common {
+		script = append(script, diffLine{diffEqual, l})
+	}
+	return script
+}
+
+// appendReplace appends to script the deletion of a and the insertion of
+// b.
+func appendReplace(script []diffLine, a, b []string) []diffLine {
+	for _, l := range a {
+		script = append(script, diffLine{diffDelete, l})
+	}
+	for _, l := range b {
+		script = append(script, diffLine{diffInsert, l})
+	}
+	return script
+}
+
+// appendSingleDiff appends to script the edit script from a to b when one
+// of them is a single line, which is either kept, if the other text has
+// it, or replaced.
+func appendSingleDiff(script []diffLine, a, b []string) []diffLine {
+	if len(a) == 1 {
+		for n, l := range b {
+			if l == a[0] {
+				script = appendReplace(script, nil, b[:n])
+				script = append(script, diffLine{diffEqual, l})
+				return appendReplace(script, nil, b[n+1:])
+			}
+		}
+	} else {
+		for n, l := range a {
+			if l == b[0] {
+				script = appendReplace(script, a[:n], nil)
+				script = append(script, diffLine{diffEqual, l})
+				return appendReplace(script, a[n+1:], nil)
+			}
+		}
+	}
+	return appendReplace(script, a, b)
+}
+
+// middleSnake finds where a shortest edit script from a to b crosses the
+// middle of the edit graph, searching from both ends at once. It returns
+// the point (x, y) splitting a and b in two halves to diff on their own,
+// or ok false when the texts have no line in common.
+func middleSnake(a, b []string) (x, y int, ok bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	vf, vb := make([]int, 2*maxD), make([]int, 2*maxD) // Furthest x on each diagonal, forward and backward
+	for k := range vf {
+		vf[k], vb[k] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+	delta := n - m
+	front := delta%2 != 0 // The paths meet while going forward
+	// Diagonals past the edges of the graph are skipped
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1] // Down: insertion
+			} else {
+				x = vf[offset+k-1] + 1 // Right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			vf[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				if kb := offset + delta - k; kb >= 0 && kb < len(vb) && vb[kb] != -1 && x >= n-vb[kb] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			var x int // Counted from the end of a
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x, y = x+1, y+1
+			}
+			vb[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !front:
+				if kf := offset + delta - k; kf >= 0 && kf < len(vf) && vf[kf] != -1 {
+					if fx := vf[kf]; fx >= n-x {
+						return fx, fx - (kf - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+const (
+	diffContext = 3          // Unchanged lines shown around each change
+	tagDiffHunk = "diffhunk" // Diff window tag of headers
+)
+
+// showDiffWindow opens a window with the changes from a to b in unified
+// format, removed and added lines highlighted.
+func showDiffWindow(title, nameA, nameB, a, b string) {
+	linesA, linesB := strings.Split(a, "\n"), strings.Split(b, "\n")
+	var script []diffLine
+	tooLarge := diffTooLarge(linesA, linesB)
+	if !tooLarge {
+		script = lineDiff(linesA, linesB)
+	}
+
+	dialog := Toplevel()
+	dialog.WmTitle(title)
+	view := dialog.Text(textStyle(), Width(100), Height(30), Wrap("none"))
+	view.TagConfigure(tagDiffDel, Background(theme.DiffOld))
+	view.TagConfigure(tagDiffAdd, Background(theme.DiffNew))
+	view.TagConfigure(tagDiffHunk, Foreground(theme.Muted))
+	scrollbar := dialog.TScrollbar(Command(func(e *Event) { e.Yview(view) }))
+	view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(view, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+	Bind(dialog, "<Escape>", Command(func() { Destroy(dialog) }))
+
+	view.Insert("end", fmt.Sprintf("--- %s\n+++ %s\n", nameA, nameB), tagDiffHunk)
+	hunks := 0
+	for _, h := range diffHunks(script) {
+		hunks++
+		view.Insert("end", fmt.Sprintf("@@ -%d +%d @@\n", h.lineA, h.lineB), tagDiffHunk)
+		for _, l := range h.lines {
+			switch l.op {
+			case diffDelete:
+				view.Insert("end", "-"+l.text+"\n", tagDiffDel)
+			case diffInsert:
+				view.Insert("end", "+"+l.text+"\n", tagDiffAdd)
+			default:
+				view.Insert("end", " "+l.text+"\n")
+			}
+		}
+	}
+	switch {
+	case tooLarge:
+		view.Insert("end", fmt.Sprintf("The texts differ in more than %d lines, too many to compare.\n", maxDiffLines))
+	case hunks == 0:
+		view.Insert("end", "No differences\n")
+	}
+	view.Configure(State("disabled"))
+}
+
+// diffHunk is a group of nearby changes with their context.
+type diffHunk struct {
+	lineA, lineB int // 1-based first line in each text
+	lines        []diffLine
+}
+
+// diffHunks splits an edit script into hunks, keeping diffContext
+// unchanged lines around the changes.
+func diffHunks(script []diffLine) []diffHunk {
+	var hunks []diffHunk
+	var cur *diffHunk
+	lineA, lineB := 1, 1
+	for n, l := range script {
+		near := false
+		for k := max(n-diffContext, 0); k <= min(n+diffContext, len(script)-1); k++ {
+			if script[k].op != diffEqual {
+				near = true
+				break
+			}
+		}
+		switch {
+		case near && cur == nil:
+			hunks = append(hunks, diffHunk{lineA: lineA, lineB: lineB})
+			cur = &hunks[len(hunks)-1]
+			fallthrough
+		case near:
+			cur.lines = append(cur.lines, l)
+		default:
+			cur = nil
+		}
+		if l.op != diffInsert {
+			lineA++
+		}
+		if l.op != diffDelete {
+			lineB++
+		}
+	}
+	return hunks
+}
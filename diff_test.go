@@ -0,0 +1,120 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkScript fails the test unless script turns a into b with the fewest
+// insertions and deletions.
+func checkScript(t *testing.T, a, b []string, script []diffLine) {
+	t.Helper()
+	var gotA, gotB []string
+	changes := 0
+	for _, l := range script {
+		if l.op != diffInsert {
+			gotA = append(gotA, l.text)
+		}
+		if l.op != diffDelete {
+			gotB = append(gotB, l.text)
+		}
+		if l.op != diffEqual {
+			changes++
+		}
+	}
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+		t.Fatalf("lineDiff(%q, %q) = %v, doesn't turn one into the other", a, b, script)
+	}
+	if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+		t.Fatalf("lineDiff(%q, %q) makes %d changes, want %d", a, b, changes, want)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and
+// b, by dynamic programming.
+func lcsLength(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for x := range a {
+		for y := range b {
+			if a[x] == b[y] {
+				cur[y+1] = prev[y] + 1
+			} else {
+				cur[y+1] = max(prev[y+1], cur[y])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", ""},
+		{"a", "a"},
+		{"a", "b"},
+		{"a\nb\nc", "a\nc"},
+		{"a\nc", "a\nb\nc"},
+		{"a\nb\nc\nd", "d\nc\nb\na"},
+		{"x\ny", "y\nx\ny\nz"},
+		{"a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc"},
+	}
+	for _, tt := range tests {
+		a, b := strings.Split(tt.a, "\n"), strings.Split(tt.b, "\n")
+		checkScript(t, a, b, lineDiff(a, b))
+	}
+}
+
+func TestLineDiffRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	text := func() []string {
+		lines := make([]string, rnd.Intn(30))
+		for n := range lines {
+			lines[n] = string(rune('a' + rnd.Intn(4)))
+		}
+		return lines
+	}
+	for range 2000 {
+		a, b := text(), text()
+		checkScript(t, a, b, lineDiff(a, b))
+	}
+}
+
+func TestLineDiffMemory(t *testing.T) {
+	a, b := make([]string, 6000), make([]string, 6000)
+	for n := range a {
+		a[n], b[n] = fmt.Sprint("a", n), fmt.Sprint("b", n%100)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	script := lineDiff(a, b)
+	runtime.ReadMemStats(&after)
+	if len(script) != len(a)+len(b) {
+		t.Fatalf("lineDiff of texts without common lines has %d lines, want %d", len(script), len(a)+len(b))
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+		t.Fatalf("lineDiff of 6000 lines allocated %d MB", alloc>>20)
+	}
+}
+
+func TestDiffTooLarge(t *testing.T) {
+	same := strings.Split(strings.Repeat("x\n", 3*maxDiffLines), "\n")
+	edited := slices.Clone(same)
+	edited[maxDiffLines] = "y"
+	if diffTooLarge(same, edited) {
+		t.Error("a one line change in a long text is too large to diff")
+	}
+	other := make([]string, maxDiffLines)
+	for n := range other {
+		other[n] = fmt.Sprint(n)
+	}
+	if !diffTooLarge(other, edited) {
+		t.Error("texts differing everywhere aren't too large to diff")
+	}
+}
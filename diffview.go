@@ -0,0 +1,368 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Diff View
+// -------------------------------------------------------------------------
+
+// Compare with Saved shows the changes of the buffer since it was saved
+// side by side: removed lines on the left, added ones on the right,
+// changed ones on both, with a filler where a side has no line. A change
+// picked with a click or the arrows can be reverted in the buffer. Compare
+// Files shows two files the same way, without reverting.
+const (
+	tagDiffFiller  = "difffiller"  // Rows standing for lines of the other side
+	tagDiffCurrent = "diffcurrent" // Rows of the change picked
+)
+
+// diffBlock is a run of changed lines, between line a0 and a1 of the old
+// text and b0 and b1 of the new one, counted from 0, shown on the rows
+// from row of the view.
+type diffBlock struct {
+	a0, a1, b0, b1 int
+	row, rows      int
+}
+
+// diffView is a side-by-side diff window.
+type diffView struct {
+	window      *ToplevelWidget
+	left, right *TextWidget
+	status      *TLabelWidget
+	pane        *editorPane // Buffer compared with its file, nil for two files
+	pathA       string
+	pathB       string // "" when the new side is the buffer
+	old, new    string // Texts compared
+	blocks      []diffBlock
+	current     int // Index of the change picked in blocks
+}
+
+// onCompareWithSaved compares the buffer with its file on disk.
+func (i *Ite) onCompareWithSaved() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.largeFile {
+		i.showError("Compare with Saved isn't available in large-file mode.")
+		return
+	}
+	v := &diffView{pane: i.active, pathA: i.currentFile}
+	if err := i.openDiffView(v); err != nil {
+		i.showError("Compare with Saved: " + err.Error())
+	}
+}
+
+// onCompareFiles asks for two files and compares them.
+func (i *Ite) onCompareFiles() {
+	a := GetOpenFile(Title("Compare Files: First File"), Initialdir(i.defaultDir()))
+	if len(a) == 0 {
+		return
+	}
+	b := GetOpenFile(Title("Compare Files: Second File"), Initialdir(filepath.Dir(a[0])))
+	if len(b) == 0 {
+		return
+	}
+	i.rememberDialogDir(b[0])
+	if err := i.openDiffView(&diffView{pathA: a[0], pathB: b[0]}); err != nil {
+		i.showError("Compare Files: " + err.Error())
+	}
+}
+
+// openDiffView opens the window of v and shows the diff.
+func (i *Ite) openDiffView(v *diffView) error {
+	if err := i.readDiffSides(v); err != nil {
+		return err
+	}
+	v.window = Toplevel()
+	nameB := "Buffer"
+	if v.pathB != "" {
+		nameB = i.displayPath(v.pathB)
+	}
+	v.window.WmTitle(fmt.Sprintf("Diff - %s ↔ %s", filepath.Base(v.pathA), filepath.Base(nameB)))
+
+	scrollbar := v.window.TScrollbar(Command(func(e *Event) {
+		e.Yview(v.left)
+		e.Yview(v.right)
+	}))
+	for col, side := range []struct {
+		text **TextWidget
+		name string
+	}{
+		{&v.left, i.displayPath(v.pathA)},
+		{&v.right, nameB},
+	} {
+		Grid(v.window.TLabel(Txt(side.name)), Row(0), Column(col), Sticky(W), Padx(px(5)))
+		text := v.window.Text(textStyle(), Width(70), Height(30), Wrap("none"))
+		text.TagConfigure(tagDiffDel, Background(theme.DiffOld))
+		text.TagConfigure(tagDiffAdd, Background(theme.DiffNew))
+		text.TagConfigure(tagDiffFiller, Background(theme.Frame))
+		text.TagConfigure(tagDiffCurrent, Relief(RIDGE), Borderwidth(1))
+		Grid(text, Row(1), Column(col), Sticky(NEWS), Padx(px(2)))
+		GridColumnConfigure(v.window, col, Weight(1))
+		Bind(text, "<ButtonRelease-1>", Command(func(e *Event) {
+			row, _ := parseIndex(mouseIndex(text, e))
+			i.pickDiffRow(v, row)
+		}))
+		*side.text = text
+	}
+	// Each view follows the other, whichever scrolls
+	sync := func(from, to *TextWidget) Opt {
+		return Yscrollcommand(func(e *Event) {
+			e.ScrollSet(scrollbar)
+			tclEval("if {[lindex [%[1]s yview] 0] != [lindex [%[2]s yview] 0]} {%[2]s yview moveto [lindex [%[1]s yview] 0]}", from, to)
+		})
+	}
+	v.left.Configure(sync(v.left, v.right))
+	v.right.Configure(sync(v.right, v.left))
+	Grid(scrollbar, Row(1), Column(2), Sticky(NS))
+	GridRowConfigure(v.window, 1, Weight(1))
+
+	bar := v.window.TFrame()
+	Grid(bar, Row(2), Column(0), Columnspan(3), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	type button struct {
+		label string
+		run   func()
+	}
+	buttons := []button{
+		{"◀ Previous", func() { i.stepDiffBlock(v, -1) }},
+		{"Next ▶", func() { i.stepDiffBlock(v, 1) }},
+	}
+	if v.pane != nil {
+		buttons = append(buttons, button{"Revert Change", func() { i.revertDiffBlock(v) }})
+	}
+	buttons = append(buttons, button{"Refresh", func() { i.refreshDiffView(v) }},
+		button{"Export PDF...", func() { i.onExportDiffPDF(v) }})
+	for col, b := range buttons {
+		Grid(bar.TButton(Txt(b.label), Command(b.run)), Row(0), Column(col), Padx(px(2)))
+	}
+	v.status = bar.TLabel()
+	Grid(v.status, Row(0), Column(len(buttons)), Sticky(W), Padx(px(10)))
+	Bind(v.window, "<Escape>", Command(func() { Destroy(v.window) }))
+	Bind(v.window, "<F5>", Command(func() { i.refreshDiffView(v) }))
+	Bind(v.window, "<n>", Command(func() { i.stepDiffBlock(v, 1) }))
+	Bind(v.window, "<p>", Command(func() { i.stepDiffBlock(v, -1) }))
+
+	i.showDiff(v)
+	if len(v.blocks) > 0 {
+		i.pickDiffBlock(v, 0)
+	}
+	return nil
+}
+
+// readDiffSides reads the texts v compares: the file and the buffer of
+// its pane, or two files. Texts differing in too many lines to diff
+// without freezing the editor are refused.
+func (i *Ite) readDiffSides(v *diffView) error {
+	data, err := os.ReadFile(v.pathA)
+	if err != nil {
+		return err
+	}
+	v.old = decodedText(data)
+	if v.pane == nil {
+		data, err := os.ReadFile(v.pathB)
+		if err != nil {
+			return err
+		}
+		v.new = decodedText(data)
+	} else {
+		if !slices.Contains(i.panes, v.pane) {
+			return fmt.Errorf("the pane of %s is closed", filepath.Base(v.pathA))
+		}
+		i.withPane(v.pane, func() {
+			if samePath(i.currentFile, v.pathA) {
+				v.new = i.editText.Text()
+			} else {
+				err = fmt.Errorf("%s is no longer in its pane", filepath.Base(v.pathA))
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if diffTooLarge(strings.Split(v.old, "\n"), strings.Split(v.new, "\n")) {
+		return fmt.Errorf("the texts differ in more than %d lines, too many to compare", maxDiffLines)
+	}
+	return nil
+}
+
+// showDiff fills the views of v with the diff of its texts, aligning the
+// lines of both sides.
+func (i *Ite) showDiff(v *diffView) {
+	a, b := strings.Split(v.old, "\n"), strings.Split(v.new, "\n")
+	script := lineDiff(a, b)
+	v.blocks = nil
+	for _, t := range []*TextWidget{v.left, v.right} {
+		t.Configure(State("normal"))
+		t.Delete("1.0", "end")
+	}
+	row, lineA, lineB := 1, 0, 0
+	for n := 0; n < len(script); {
+		if script[n].op == diffEqual {
+			v.left.Insert("end", script[n].text+"\n")
+			v.right.Insert("end", script[n].text+"\n")
+			row, lineA, lineB, n = row+1, lineA+1, lineB+1, n+1
+			continue
+		}
+		var del, ins []string
+		for ; n < len(script) && script[n].op != diffEqual; n++ {
+			if script[n].op == diffDelete {
+				del = append(del, script[n].text)
+			} else {
+				ins = append(ins, script[n].text)
+			}
+		}
+		rows := max(len(del), len(ins))
+		fillDiffSide(v.left, del, rows, tagDiffDel)
+		fillDiffSide(v.right, ins, rows, tagDiffAdd)
+		v.blocks = append(v.blocks, diffBlock{
+			a0: lineA, a1: lineA + len(del),
+			b0: lineB, b1: lineB + len(ins),
+			row: row, rows: rows,
+		})
+		row, lineA, lineB = row+rows, lineA+len(del), lineB+len(ins)
+	}
+	for _, t := range []*TextWidget{v.left, v.right} {
+		t.Delete("end-2c", "end-1c") // The newline after the last row
+		t.Configure(State("disabled"))
+	}
+	v.current = min(v.current, len(v.blocks)-1)
+	i.updateDiffStatus(v)
+}
+
+// fillDiffSide adds lines to text, tagged tag, then fillers up to rows
+// rows.
+func fillDiffSide(text *TextWidget, lines []string, rows int, tag string) {
+	for _, line := range lines {
+		text.Insert("end", line+"\n", tag)
+	}
+	for range rows - len(lines) {
+		text.Insert("end", "\n", tagDiffFiller)
+	}
+}
+
+// updateDiffStatus shows the number of changes of v and the one picked.
+func (i *Ite) updateDiffStatus(v *diffView) {
+	switch {
+	case len(v.blocks) == 0:
+		v.status.Configure(Txt("No differences"))
+	case v.current < 0:
+		v.status.Configure(Txt(fmt.Sprintf("%d changes", len(v.blocks))))
+	default:
+		v.status.Configure(Txt(fmt.Sprintf("Change %d of %d", v.current+1, len(v.blocks))))
+	}
+}
+
+// pickDiffBlock makes the change n of v the current one and shows it.
+func (i *Ite) pickDiffBlock(v *diffView, n int) {
+	v.current = n
+	for _, t := range []*TextWidget{v.left, v.right} {
+		t.TagRemove(tagDiffCurrent, "1.0", "end")
+		if n >= 0 {
+			b := v.blocks[n]
+			t.TagAdd(tagDiffCurrent, fmt.Sprintf("%d.0", b.row), fmt.Sprintf("%d.0", b.row+b.rows))
+			t.See(fmt.Sprintf("%d.0", b.row+b.rows))
+			t.See(fmt.Sprintf("%d.0", b.row))
+		}
+	}
+	i.updateDiffStatus(v)
+}
+
+// pickDiffRow makes the change shown on row of v the current one.
+func (i *Ite) pickDiffRow(v *diffView, row int) {
+	for n, b := range v.blocks {
+		if row >= b.row && row < b.row+b.rows {
+			i.pickDiffBlock(v, n)
+			return
+		}
+	}
+}
+
+// stepDiffBlock picks the change after the current one of v, or before it
+// when dir is negative, wrapping around.
+func (i *Ite) stepDiffBlock(v *diffView, dir int) {
+	if len(v.blocks) == 0 {
+		return
+	}
+	n := v.current + dir
+	if v.current < 0 && dir < 0 {
+		n = len(v.blocks) - 1
+	}
+	i.pickDiffBlock(v, (n+len(v.blocks))%len(v.blocks))
+}
+
+// refreshDiffView reads the sides of v again and shows their diff.
+func (i *Ite) refreshDiffView(v *diffView) {
+	if err := i.readDiffSides(v); err != nil {
+		i.showError("Diff: " + err.Error())
+		return
+	}
+	i.showDiff(v)
+	if v.current >= 0 {
+		i.pickDiffBlock(v, v.current)
+	}
+}
+
+// revertDiffBlock puts the saved lines of the current change of v back in
+// the buffer, as one undo step.
+func (i *Ite) revertDiffBlock(v *diffView) {
+	if v.current < 0 {
+		return
+	}
+	shown := v.new
+	if err := i.readDiffSides(v); err != nil {
+		i.showError("Revert Change: " + err.Error())
+		return
+	}
+	if v.new != shown {
+		i.showDiff(v)
+		i.pickDiffBlock(v, v.current)
+		i.showStatusHint("The buffer changed, the diff is refreshed: pick the change again")
+		return
+	}
+	b := v.blocks[v.current]
+	old := strings.Split(v.old, "\n")[b.a0:b.a1]
+	lines := strings.Count(v.new, "\n") + 1
+	from, to := fmt.Sprintf("%d.0", b.b0+1), fmt.Sprintf("%d.0", b.b1+1)
+	text := strings.Join(old, "\n")
+	switch {
+	case b.b1 < lines:
+		if len(old) > 0 {
+			text += "\n"
+		}
+	case b.b0 == 0: // The whole buffer
+		to = "end-1c"
+	default:
+		// The change ends the buffer, which has no newline after it
+		from, to = fmt.Sprintf("%d.0 lineend", b.b0), "end-1c"
+		if len(old) > 0 {
+			text = "\n" + text
+		}
+	}
+	i.withPane(v.pane, func() {
+		if i.blockProtected(from, to) {
+			return
+		}
+		i.editGroup(func() {
+			i.editText.Delete(from, to)
+			i.editText.Insert(from, text)
+		})
+		i.editText.MarkSet("insert", fmt.Sprintf("%d.0", b.b0+1))
+		i.editText.See("insert")
+		i.refreshCursorState()
+		i.scheduleGutter()
+	})
+	i.refreshDiffView(v)
+	i.pickDiffBlock(v, min(v.current, len(v.blocks)-1))
+}
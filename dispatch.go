@@ -0,0 +1,53 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"context"
+	"sync"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// UI Dispatch
+// -------------------------------------------------------------------------
+
+// dispatcher hands functions from background goroutines to the Tk thread,
+// the only one allowed to touch widgets.
+type dispatcher struct {
+	mu      sync.Mutex
+	queue   []func()
+	pending bool // A flush has been posted to Tcl and hasn't run yet
+}
+
+// Dispatch queues f to run on the Tk thread. It is safe for concurrent use
+// and returns at once. The functions queued before the Tk thread gets to
+// them run in a batch, in the order they were queued, woken by a single
+// Tcl event rather than a timer.
+func (i *Ite) Dispatch(f func()) {
+	d := &i.dispatch
+	d.mu.Lock()
+	d.queue = append(d.queue, f)
+	post := !d.pending
+	d.pending = true
+	d.mu.Unlock()
+	if post {
+		PostEvent(i.flushDispatch, false)
+	}
+}
+
+// flushDispatch runs the queued functions on the Tk thread.
+func (i *Ite) flushDispatch() {
+	d := &i.dispatch
+	d.mu.Lock()
+	queue := d.queue
+	d.queue, d.pending = nil, false
+	d.mu.Unlock()
+	for _, f := range queue {
+		endRegion := traceRegion(context.Background(), "dispatch")
+		f()
+		endRegion()
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Doc Comment Template
+// -------------------------------------------------------------------------
+
+const docParamHintMin = 3 // Parameters from which the template lists them
+
+// docTarget is a declaration a doc comment can be written for.
+type docTarget struct {
+	name   string
+	line   int      // Line the comment goes above
+	params []string // Parameter names of a func, for the hints
+}
+
+// onInsertDocComment writes a doc comment skeleton above the func, method,
+// type, const or var declared at the cursor. Following the Go convention,
+// it starts with the declared name; funcs taking docParamHintMin or more
+// parameters also get a list of them to describe. The cursor is left
+// after the name, ready for the rest of the sentence.
+func (i *Ite) onInsertDocComment() {
+	line, _ := parseIndex(i.editText.Index("insert"))
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, i.currentFile, i.editText.Text(), parser.ParseComments|parser.SkipObjectResolution)
+	if file == nil {
+		i.showStatusHint("Not a Go file")
+		return
+	}
+	target, documented, ok := docTargetAt(fset, file, line)
+	switch {
+	case !ok:
+		i.showStatusHint("No declaration at the cursor")
+		return
+	case documented:
+		i.showStatusHint(target.name + " already has a doc comment")
+		return
+	}
+	at := fmt.Sprintf("%d.0", target.line)
+	if i.blockProtected(at, at) {
+		return
+	}
+
+	prefix := leadingSpace(lineText(i.editText, target.line)) + "// "
+	lines := []string{prefix + target.name + " "}
+	if len(target.params) >= docParamHintMin {
+		lines = append(lines, strings.TrimSpace(prefix))
+		for _, p := range target.params {
+			lines = append(lines, prefix+"  - "+p+": ")
+		}
+	}
+	i.editGroup(func() {
+		i.editText.Insert(at, strings.Join(lines, "\n")+"\n")
+	})
+	i.editText.MarkSet("insert", fmt.Sprintf("%d.end", target.line))
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// docTargetAt returns the declaration whose header covers line: the
+// signature of a func, or a type, const or var spec. documented reports
+// whether it already has a doc comment.
+func docTargetAt(fset *token.FileSet, file *ast.File, line int) (target docTarget, documented, ok bool) {
+	lineOf := func(p token.Pos) int { return fset.Position(p).Line }
+	covers := func(from, to token.Pos) bool { return lineOf(from) <= line && line <= lineOf(to) }
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			end := d.End()
+			if d.Body != nil {
+				end = d.Body.Lbrace
+			}
+			if !covers(d.Pos(), end) {
+				continue
+			}
+			var params []string
+			for _, field := range d.Type.Params.List {
+				for _, name := range field.Names {
+					if name.Name != "_" {
+						params = append(params, name.Name)
+					}
+				}
+			}
+			return docTarget{name: d.Name.Name, line: lineOf(d.Pos()), params: params}, d.Doc != nil, true
+		case *ast.GenDecl:
+			if !covers(d.Pos(), d.End()) || d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				var name *ast.Ident
+				var doc *ast.CommentGroup
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					name, doc = s.Name, s.Doc
+				case *ast.ValueSpec:
+					name, doc = s.Names[0], s.Doc
+				}
+				if name == nil || (d.Lparen.IsValid() && !covers(spec.Pos(), spec.End())) {
+					continue
+				}
+				if !d.Lparen.IsValid() {
+					// A single spec is documented above the keyword
+					return docTarget{name: name.Name, line: lineOf(d.Pos())}, d.Doc != nil, true
+				}
+				return docTarget{name: name.Name, line: lineOf(spec.Pos())}, doc != nil, true
+			}
+			return docTarget{}, false, false
+		}
+	}
+	return docTarget{}, false, false
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Toolchain Doctor
+// -------------------------------------------------------------------------
+
+// Doctor window tags.
+const (
+	tagDoctorOK      = "doctorok"
+	tagDoctorMissing = "doctormissing"
+	tagDoctorWarn    = "doctorwarn"
+)
+
+// doctorTool is an external program the doctor looks for.
+type doctorTool struct {
+	name        string
+	required    bool
+	versionArgs []string // Arguments printing the version, nil if there are none
+	purpose     string   // What ITE uses the tool for
+	install     string   // How to install the tool
+}
+
+// doctorTools lists the programs ITE runs or integrates with.
+var doctorTools = []doctorTool{
+	{"go", true, []string{"version"}, "build, run and test", "download Go from https://go.dev/dl/"},
+	{"gofmt", true, nil, "formatting", "gofmt ships with Go; reinstall Go"},
+	{"gopls", false, []string{"version"}, "Go to Definition, signature help and hover", "go install golang.org/x/tools/gopls@latest"},
+	{"staticcheck", false, []string{"-version"}, "Lint", "go install honnef.co/go/tools/cmd/staticcheck@latest"},
+	{"dlv", false, []string{"version"}, "debugging", "go install github.com/go-delve/delve/cmd/dlv@latest"},
+	{"golangci-lint", false, []string{"--version"}, "extended linting", "see https://golangci-lint.run/welcome/install/"},
+	{"git", false, []string{"--version"}, "version control", "install git with your package manager or from https://git-scm.com"},
+}
+
+// doctorResult is the outcome of checking one tool.
+type doctorResult struct {
+	tool     doctorTool
+	path     string   // Where the tool was found on PATH, "" if it wasn't
+	version  string   // First line of the version output
+	problems []string // PATH issues and other warnings
+}
+
+// onDoctor opens the doctor window and checks the toolchain in the
+// background, so that "Go Build does nothing" can be diagnosed at a glance.
+func (i *Ite) onDoctor() {
+	if i.doctor != nil {
+		Destroy(i.doctor)
+	}
+	dialog := Toplevel()
+	dialog.WmTitle("Doctor")
+	view := dialog.Text(textStyle(), Width(90), Height(30), Wrap("word"))
+	view.TagConfigure(tagDoctorOK, Foreground(theme.Success))
+	view.TagConfigure(tagDoctorMissing, Foreground(theme.Error))
+	view.TagConfigure(tagDoctorWarn, Foreground(theme.Warning))
+	view.Insert("end", "Checking tools...\n")
+	view.Configure(State("disabled"))
+	scrollbar := dialog.TScrollbar(Command(func(e *Event) { e.Yview(view) }))
+	view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(view, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+
+	closeDialog := func() {
+		Destroy(dialog)
+		i.doctor = nil
+	}
+	btnFrame := dialog.TFrame()
+	Grid(btnFrame, Row(1), Column(0), Columnspan(2), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Check Again"), Command(i.onDoctor)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Close"), Command(closeDialog)), Row(0), Column(1), Padx(px(5)))
+	WmProtocol(dialog.Window, "WM_DELETE_WINDOW", closeDialog)
+	Bind(dialog, "<Escape>", Command(closeDialog))
+	i.doctor = view
+
+	go func() {
+		results := checkToolchain()
+		i.Dispatch(func() { i.showDoctorResults(results) })
+	}()
+}
+
+// checkToolchain looks up every tool of doctorTools.
+func checkToolchain() []doctorResult {
+	binDirs := goBinDirs()
+	var results []doctorResult
+	for _, tool := range doctorTools {
+		results = append(results, checkTool(tool, binDirs))
+	}
+
+	// gofmt should come from the same installation as go
+	goPath, gofmtPath := results[0].path, results[1].path
+	if goPath != "" && gofmtPath != "" && filepath.Dir(goPath) != filepath.Dir(gofmtPath) {
+		results[1].problems = append(results[1].problems,
+			"not in the same directory as go ("+filepath.Dir(goPath)+"): the versions may differ")
+	}
+	return results
+}
+
+// checkTool finds tool on PATH, reads its version and looks for PATH
+// issues: copies shadowed by an earlier one, or an installation in a Go
+// bin directory missing from PATH.
+func checkTool(tool doctorTool, binDirs []string) doctorResult {
+	res := doctorResult{tool: tool}
+	copies := findOnPath(tool.name)
+	if len(copies) == 0 {
+		for _, dir := range binDirs {
+			if path := executableIn(dir, tool.name); path != "" {
+				res.problems = append(res.problems,
+					"installed in "+dir+", which is not on PATH; add it to PATH")
+			}
+		}
+		return res
+	}
+	res.path = copies[0]
+	for _, shadowed := range copies[1:] {
+		res.problems = append(res.problems, "also found at "+shadowed+", shadowed by "+res.path)
+	}
+	if tool.versionArgs != nil {
+		out, err := exec.Command(res.path, tool.versionArgs...).CombinedOutput()
+		version, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		if err != nil {
+			res.problems = append(res.problems, "failed to report its version: "+err.Error())
+		}
+		res.version = version
+	}
+	return res
+}
+
+// goBinDirs returns the directories `go install` puts programs in.
+func goBinDirs() []string {
+	if out, err := exec.Command("go", "env", "GOBIN", "GOPATH").Output(); err == nil {
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if len(lines) == 2 {
+			if lines[0] != "" {
+				return []string{lines[0]}
+			}
+			var dirs []string
+			for _, dir := range filepath.SplitList(lines[1]) {
+				dirs = append(dirs, filepath.Join(dir, "bin"))
+			}
+			return dirs
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return []string{filepath.Join(home, "go", "bin")}
+	}
+	return nil
+}
+
+// findOnPath returns every executable named name on PATH, in search order.
+func findOnPath(name string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if path := executableIn(dir, name); path != "" {
+			found = append(found, path)
+		}
+	}
+	return found
+}
+
+// executableIn returns the path of the program name in dir, or "".
+func executableIn(dir, name string) string {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		return ""
+	}
+	return path
+}
+
+// showDoctorResults shows the toolchain report and the memory usage, if
+// the doctor window is still open.
+func (i *Ite) showDoctorResults(results []doctorResult) {
+	if i.doctor != nil {
+		showDoctorReport(i.doctor, results)
+		i.showMemoryUsage(i.doctor)
+		i.showStartupProfile(i.doctor)
+	}
+}
+
+// showDoctorReport replaces the content of view with the results.
+func showDoctorReport(view *TextWidget, results []doctorResult) {
+	view.Configure(State("normal"))
+	view.Delete("1.0", "end")
+	for _, res := range results {
+		kind := "optional"
+		if res.tool.required {
+			kind = "required"
+		}
+		switch {
+		case res.path == "" && res.tool.required:
+			view.Insert("end", "✗ "+res.tool.name, tagDoctorMissing)
+		case res.path == "":
+			view.Insert("end", "– "+res.tool.name, tagDoctorWarn)
+		default:
+			view.Insert("end", "✓ "+res.tool.name, tagDoctorOK)
+		}
+		view.Insert("end", " ("+kind+", for "+res.tool.purpose+")\n")
+		if res.path == "" {
+			view.Insert("end", "    not found on PATH\n    install: "+res.tool.install+"\n")
+		} else {
+			view.Insert("end", "    "+res.path+"\n")
+			if res.version != "" {
+				view.Insert("end", "    "+res.version+"\n")
+			}
+		}
+		for _, p := range res.problems {
+			view.Insert("end", "    ! "+p+"\n", tagDoctorWarn)
+		}
+		view.Insert("end", "\n")
+	}
+	view.Insert("end", "PATH:\n")
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		view.Insert("end", "    "+dir+"\n")
+	}
+	view.Configure(State("disabled"))
+}
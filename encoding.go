@@ -0,0 +1,193 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Text Encodings
+// -------------------------------------------------------------------------
+
+// Encodings of the files ITE reads and writes, as named in the status bar.
+// The buffer always holds the text as Unicode; the encoding only matters
+// when the file is loaded and saved.
+const (
+	encUTF8    = "UTF-8"
+	encUTF8BOM = "UTF-8 BOM"
+	encUTF16LE = "UTF-16LE" // Written with a byte order mark
+	encUTF16BE = "UTF-16BE" // Written with a byte order mark
+	encLatin1  = "Latin-1"  // ISO-8859-1
+)
+
+// encodings lists the encodings offered by the status bar menu.
+var encodings = []string{encUTF8, encUTF8BOM, encUTF16LE, encUTF16BE, encLatin1}
+
+// Byte order marks.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+const utf16SniffLen = 1024 // Bytes looked at to recognize UTF-16 without a BOM
+
+// decodeText detects the encoding of data and returns its text. A byte
+// order mark settles it; otherwise text with a NUL in most of its even or
+// odd bytes is UTF-16, and anything that isn't valid UTF-8 is Latin-1,
+// where every byte is a character.
+func decodeText(data []byte) (text, enc string) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return string(data[len(bomUTF8):]), encUTF8BOM
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], binary.LittleEndian), encUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], binary.BigEndian), encUTF16BE
+	}
+	if enc := sniffUTF16(data); enc == encUTF16LE {
+		return decodeUTF16(data, binary.LittleEndian), enc
+	} else if enc == encUTF16BE {
+		return decodeUTF16(data, binary.BigEndian), enc
+	}
+	if utf8.Valid(data) {
+		return string(data), encUTF8
+	}
+	return latin1Text(data), encLatin1
+}
+
+// latin1Text returns the text of Latin-1 data, where every byte is a
+// character.
+func latin1Text(data []byte) string {
+	runes := make([]rune, len(data))
+	for n, b := range data {
+		runes[n] = rune(b)
+	}
+	return string(runes)
+}
+
+// outputText returns a line of command output as valid UTF-8. Output in
+// the legacy code page of the system is transcoded, and bytes that still
+// aren't text become U+FFFD rather than garbling the console.
+func outputText(line string) string {
+	if utf8.ValidString(line) {
+		return line
+	}
+	if text, ok := decodeCodePage(line); ok {
+		return text
+	}
+	return strings.ToValidUTF8(line, string(utf8.RuneError))
+}
+
+// decodedText returns the text of data as the buffer holds it, whatever
+// its encoding and line endings.
+func decodedText(data []byte) string {
+	text, _ := decodeText(data)
+	return normalizeEOL(text)
+}
+
+// sniffUTF16 recognizes UTF-16 text without a byte order mark by the NUL
+// high bytes of its ASCII characters. It returns "" for other data.
+func sniffUTF16(data []byte) string {
+	data = data[:min(len(data), utf16SniffLen)]
+	pairs := len(data) / 2
+	if pairs < 2 {
+		return ""
+	}
+	even, odd := 0, 0
+	for n := 0; n+1 < len(data); n += 2 {
+		if data[n] == 0 {
+			even++
+		}
+		if data[n+1] == 0 {
+			odd++
+		}
+	}
+	switch {
+	case odd > pairs/2 && even == 0:
+		return encUTF16LE
+	case even > pairs/2 && odd == 0:
+		return encUTF16BE
+	}
+	return ""
+}
+
+// decodeUTF16 returns the text of UTF-16 data in the given byte order.
+// A trailing odd byte becomes U+FFFD.
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(data)/2)
+	for n := range units {
+		units[n] = order.Uint16(data[2*n:])
+	}
+	text := string(utf16.Decode(units))
+	if len(data)%2 != 0 {
+		text += string(utf8.RuneError)
+	}
+	return text
+}
+
+// encodeText returns text in the encoding enc. It fails when text holds a
+// character that enc can't represent.
+func encodeText(text, enc string) ([]byte, error) {
+	switch enc {
+	case encUTF8BOM:
+		return append(bytes.Clone(bomUTF8), text...), nil
+	case encUTF16LE, encUTF16BE:
+		var order binary.AppendByteOrder = binary.LittleEndian
+		data := bytes.Clone(bomUTF16LE)
+		if enc == encUTF16BE {
+			order, data = binary.BigEndian, bytes.Clone(bomUTF16BE)
+		}
+		for _, u := range utf16.Encode([]rune(text)) {
+			data = order.AppendUint16(data, u)
+		}
+		return data, nil
+	case encLatin1:
+		data := make([]byte, 0, len(text))
+		for n, r := range text {
+			if r > 0xFF {
+				line := strings.Count(text[:n], "\n") + 1
+				return nil, fmt.Errorf("%q on line %d can't be written in %s", r, line, encLatin1)
+			}
+			data = append(data, byte(r))
+		}
+		return data, nil
+	}
+	return []byte(text), nil
+}
+
+// makeEncodingMenu creates the status bar button showing the encoding of
+// the buffer, whose menu changes the encoding used by the next save.
+func (i *Ite) makeEncodingMenu() {
+	i.statusEncoding = i.statusMenu("encoding")
+	i.statusEncoding.Configure(Txt(encUTF8))
+	menu := i.statusEncoding.Menu()
+	for _, enc := range encodings {
+		menu.AddCommand(Lbl(enc), Command(func() { i.onSelectEncoding(enc) }))
+	}
+	i.statusEncoding.Configure(Mnu(menu))
+}
+
+// onSelectEncoding makes enc the encoding the buffer is saved in. The
+// buffer counts as modified until it is saved in the new encoding.
+func (i *Ite) onSelectEncoding(enc string) {
+	if enc == orDefault(i.encoding, encUTF8) {
+		return
+	}
+	if _, err := encodeText(i.editText.Text(), enc); err != nil {
+		i.showError("Can't change the encoding: " + err.Error())
+		return
+	}
+	i.encoding = enc
+	i.editText.SetModified(true)
+	i.showStatusHint("Saving as " + enc)
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Environment Changes
+// -------------------------------------------------------------------------
+
+// ITE and the commands it starts keep the environment ITE started with.
+// When the user changes it, say by editing a shell profile, ITE finds out
+// on getting the focus back: a login shell reports the fresh values of
+// the variables the Go tools depend on, the first ones seen being the
+// baseline the later ones are compared to.
+const (
+	envCheckInterval = 30 * time.Second // Least time between two checks
+	envTimeout       = 10 * time.Second // Time the login shell has to report
+	envMarker        = "__ITE_ENV__"    // Line before the variables in the shell output
+)
+
+// envWatched are the variables checked for changes.
+var envWatched = []string{"GOPATH", "GOFLAGS", "PATH"}
+
+// envState is the state of the environment checks.
+type envState struct {
+	checked  time.Time         // End of the last check
+	checking bool              // A check is running
+	baseline map[string]string // Values last reported by the login shell, nil before the first check
+}
+
+// bindEnvCheck checks the environment when ITE gets the focus, at most
+// once per envCheckInterval.
+func (i *Ite) bindEnvCheck() {
+	Bind(App, "<FocusIn>", Command(func() {
+		if i.env.checking || time.Since(i.env.checked) < envCheckInterval {
+			return
+		}
+		i.env.checking = true
+		go func() {
+			fresh, err := freshEnv(envWatched)
+			i.Dispatch(func() {
+				i.env.checking = false
+				i.env.checked = time.Now()
+				if err == nil {
+					i.warnEnvChanges(fresh)
+				}
+			})
+		}()
+	}))
+}
+
+// warnEnvChanges offers to reload the variables that changed since the
+// last check and differ from ITE's environment. Declining keeps the fresh
+// values as the baseline, so the same change isn't reported again.
+func (i *Ite) warnEnvChanges(fresh map[string]string) {
+	baseline := i.env.baseline
+	i.env.baseline = fresh
+	if baseline == nil {
+		return
+	}
+	var changed []string
+	for _, name := range envChanges(fresh) {
+		if fresh[name] != baseline[name] {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	resp := messageBox(Icon("warning"), Title("Environment Changed"),
+		Msg(strings.Join(changed, ", ")+" changed since ITE started."),
+		Detail("Commands started from ITE still use the old values. Reload the environment?"),
+		Type("yesno"))
+	if resp == "yes" {
+		applyEnv(fresh, changed)
+		i.applyModuleMode()
+		i.showStatusHint("Environment reloaded: " + strings.Join(changed, ", "))
+	}
+}
+
+// onReloadEnvironment gives ITE, and the commands it starts, the values
+// of the watched variables set up by the user's login shell.
+func (i *Ite) onReloadEnvironment() {
+	go func() {
+		fresh, err := freshEnv(envWatched)
+		i.Dispatch(func() {
+			if err != nil {
+				i.showError("Error reading the environment: " + err.Error())
+				return
+			}
+			i.env.baseline = fresh
+			changed := envChanges(fresh)
+			if len(changed) == 0 {
+				i.showStatusHint("Environment unchanged")
+				return
+			}
+			applyEnv(fresh, changed)
+			i.applyModuleMode()
+			i.showStatusHint("Environment reloaded: " + strings.Join(changed, ", "))
+		})
+	}()
+}
+
+// envChanges returns the names of the variables whose value in fresh
+// differs from the value set outside ITE.
+func envChanges(fresh map[string]string) []string {
+	var changed []string
+	for _, name := range envWatched {
+		if fresh[name] != userEnv(name) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// applyEnv sets the named variables of ITE's environment to their values
+// in fresh, removing the empty ones.
+func applyEnv(fresh map[string]string, names []string) {
+	for _, name := range names {
+		if _, ok := userModuleEnv[name]; ok {
+			userModuleEnv[name] = fresh[name] // Applied with the module mode
+		}
+		if fresh[name] == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, fresh[name])
+		}
+	}
+}
+
+// parseEnv returns the values of the named variables in output, lines of
+// NAME=value after the envMarker line. Names missing have empty values.
+func parseEnv(output string, names []string) (map[string]string, bool) {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	_, vars, found := strings.Cut(output, envMarker+"\n")
+	if !found {
+		return nil, false
+	}
+	env := make(map[string]string, len(names))
+	for _, line := range strings.Split(vars, "\n") {
+		name, value, ok := strings.Cut(line, "=")
+		for _, n := range names {
+			if ok && strings.EqualFold(name, n) { // Windows has Path
+				env[n] = value
+			}
+		}
+	}
+	return env, true
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// freshEnv returns the values of the named variables in a new login
+// shell of the user, which reads the shell profile again.
+func freshEnv(names []string) (map[string]string, error) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), envTimeout)
+	defer cancel()
+	// env rather than the shell's own syntax, which differs in fish
+	output, err := exec.CommandContext(ctx, shell, "-l", "-c", "echo "+envMarker+"; env").Output()
+	if err != nil {
+		return nil, err
+	}
+	env, ok := parseEnv(string(output), names)
+	if !ok {
+		return nil, errors.New(shell + " printed no environment")
+	}
+	return env, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// freshEnv returns the values the named variables get in a new process:
+// the user's value or else the system one, and both joined for Path, as
+// set in the registry by the System Properties dialog or setx.
+func freshEnv(names []string) (map[string]string, error) {
+	var script strings.Builder
+	fmt.Fprintf(&script, "Write-Output '%s'; ", envMarker)
+	for _, name := range names {
+		fmt.Fprintf(&script, "$m = [Environment]::GetEnvironmentVariable('%[1]s', 'Machine'); "+
+			"$u = [Environment]::GetEnvironmentVariable('%[1]s', 'User'); ", name)
+		if strings.EqualFold(name, "PATH") {
+			script.WriteString("$v = (@($m, $u) | Where-Object { $_ }) -join ';'; ")
+		} else {
+			script.WriteString("$v = if ($u) { $u } else { $m }; ")
+		}
+		fmt.Fprintf(&script, "Write-Output ('%s=' + $v); ", name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), envTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script.String()).Output()
+	if err != nil {
+		return nil, err
+	}
+	env, ok := parseEnv(string(output), names)
+	if !ok {
+		return nil, errors.New("powershell printed no environment")
+	}
+	return env, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Line Endings
+// -------------------------------------------------------------------------
+
+// Line endings of the files ITE reads and writes, as named in the status
+// bar. Like the encoding, they only matter when the file is loaded and
+// saved: the buffer always breaks lines with "\n".
+const (
+	eolLF   = "LF"
+	eolCRLF = "CRLF"
+)
+
+// lineEndings lists the line endings offered by the status bar menu.
+var lineEndings = []string{eolLF, eolCRLF}
+
+// detectEOL returns the line ending used by most lines of text. Text
+// without line breaks counts as LF.
+func detectEOL(text string) string {
+	crlf := strings.Count(text, "\r\n")
+	if crlf > 0 && crlf >= strings.Count(text, "\n")-crlf {
+		return eolCRLF
+	}
+	return eolLF
+}
+
+// normalizeEOL returns text with its CRLF line breaks turned into LF, as
+// the buffer holds them. A lone CR is left alone.
+func normalizeEOL(text string) string {
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
+
+// applyEOL returns the buffer text with its line breaks written as eol.
+func applyEOL(text, eol string) string {
+	if eol == eolCRLF {
+		return strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	return text
+}
+
+// makeEOLMenu creates the status bar button showing the line endings of
+// the buffer, whose menu converts the buffer to the other kind.
+func (i *Ite) makeEOLMenu() {
+	i.statusEOL = i.statusMenu("eol")
+	i.statusEOL.Configure(Txt(eolLF))
+	menu := i.statusEOL.Menu()
+	for _, eol := range lineEndings {
+		menu.AddCommand(Lbl(eol), Command(func() { i.onSelectEOL(eol) }))
+	}
+	i.statusEOL.Configure(Mnu(menu))
+}
+
+// onSelectEOL converts the line endings of the buffer to eol. The buffer
+// counts as modified until it is saved with the new line endings.
+func (i *Ite) onSelectEOL(eol string) {
+	if eol == orDefault(i.eol, eolLF) {
+		return
+	}
+	i.eol = eol
+	i.editText.SetModified(true)
+	i.refreshCursorState()
+	i.showStatusHint("Line endings converted to " + eol)
+}
+
+// onConvertToLF converts the line endings of the buffer to LF.
+func (i *Ite) onConvertToLF() {
+	i.onSelectEOL(eolLF)
+}
+
+// onConvertToCRLF converts the line endings of the buffer to CRLF.
+func (i *Ite) onConvertToCRLF() {
+	i.onSelectEOL(eolCRLF)
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestDetectEOL(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", eolLF},
+		{"no break", eolLF},
+		{"a\nb\n", eolLF},
+		{"a\r\nb\r\n", eolCRLF},
+		{"a\r\nb\nc\r\n", eolCRLF},
+		{"a\r\nb\nc\n", eolLF},
+		{"a\r\nb\n", eolCRLF}, // A tie goes to CRLF
+		{"a\rb\r", eolLF},
+	}
+	for _, tt := range tests {
+		if got := detectEOL(tt.text); got != tt.want {
+			t.Errorf("detectEOL(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEOLRoundTrip(t *testing.T) {
+	tests := []struct {
+		disk, buffer string
+	}{
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\r\nb", "a\nb"},
+		{"lone\rcr\r\n", "lone\rcr\n"},
+	}
+	for _, tt := range tests {
+		if got := normalizeEOL(tt.disk); got != tt.buffer {
+			t.Errorf("normalizeEOL(%q) = %q, want %q", tt.disk, got, tt.buffer)
+		}
+		if got := applyEOL(tt.buffer, eolCRLF); got != tt.disk {
+			t.Errorf("applyEOL(%q, CRLF) = %q, want %q", tt.buffer, got, tt.disk)
+		}
+		if got := applyEOL(tt.buffer, eolLF); got != tt.buffer {
+			t.Errorf("applyEOL(%q, LF) = %q, want it unchanged", tt.buffer, got)
+		}
+	}
+}
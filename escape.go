@@ -0,0 +1,94 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Escape Analysis
+// -------------------------------------------------------------------------
+
+// Escape Analysis builds the package of the current file with -m, which
+// makes the compiler explain its escape analysis and inlining decisions.
+// The console lists them all, where the filter narrows them down, and the
+// editor underlines the lines whose values escape to the heap and those
+// where inlining happens, the notes shown when the mouse rests on them.
+const (
+	noteEscape       = "escape"     // Kind of the notes about values escaping to the heap
+	noteInline       = "inline"     // Kind of the notes about inlining
+	tagEscapeNote    = "escapenote" // Editor tag underlining lines with escape notes
+	tagInlineNote    = "inlinenote" // Editor tag underlining lines with inlining notes
+	statusCompiling  = "Analyzing escapes and inlining...\n"
+	escapeBuildFlags = "-gcflags=-m"
+)
+
+// escapeDecoder passes the compiler notes through, extracting those marked
+// in the editor, and counts them for the summary.
+type escapeDecoder struct {
+	dir              string // Directory the build runs in, for relative paths
+	escapes, inlines int
+}
+
+func (d *escapeDecoder) decode(line string) []consoleMsg {
+	m := diagnosticRe.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		return []consoleMsg{{text: line}}
+	}
+	kind := compilerNoteKind(m[4])
+	switch kind {
+	case noteEscape:
+		d.escapes++
+	case noteInline:
+		d.inlines++
+	default:
+		return []consoleMsg{{text: line}}
+	}
+	note := &diagnostic{location: location{path: m[1], col: 1}, message: m[4], kind: kind}
+	note.line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		note.col, _ = strconv.Atoi(m[3])
+	}
+	if !filepath.IsAbs(note.path) {
+		note.path = filepath.Join(d.dir, note.path)
+	}
+	return []consoleMsg{{text: line, diag: note}}
+}
+
+func (d *escapeDecoder) summary() []consoleMsg {
+	return []consoleMsg{{text: fmt.Sprintf("\n%d values moved or escaping to the heap, %d inlining notes\n", d.escapes, d.inlines)}}
+}
+
+// compilerNoteKind returns the kind of the compiler note msg, or "" for
+// the notes not marked in the editor, such as "x does not escape".
+func compilerNoteKind(msg string) string {
+	switch {
+	case strings.HasSuffix(msg, "escapes to heap"), strings.HasPrefix(msg, "moved to heap:"), strings.HasPrefix(msg, "leaking param"):
+		return noteEscape
+	case strings.HasPrefix(msg, "can inline "), strings.HasPrefix(msg, "inlining call to "):
+		return noteInline
+	}
+	return ""
+}
+
+// onEscapeAnalysis builds the package of the current file with -m and
+// marks the notes of the compiler in the editor. The build output is
+// discarded.
+func (i *Ite) onEscapeAnalysis() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Escape Analysis needs a saved Go file.")
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	i.clearDiagnostics(noteEscape, noteInline)
+	i.runCommand(i.console(consoleBuild), []string{"build", escapeBuildFlags, "-o", os.DevNull, i.currentPackage()},
+		statusCompiling, &escapeDecoder{dir: i.projectDir()})
+}
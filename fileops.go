@@ -0,0 +1,306 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// File Operations Journal
+// -------------------------------------------------------------------------
+
+// The files ITE creates, replaces, rewrites or renames on the user's
+// behalf, outside of saving the buffer, are recorded in a journal kept in
+// the config directory, so that Undo Last File Operation can take the last
+// operation back. The content a step replaces is copied next to the
+// journal first: undoing doesn't depend on a trash that may be disabled or
+// out of reach.
+
+const (
+	fileOpsDirName  = "fileops"      // Journal and backups, inside the config directory
+	fileOpsFileName = "journal.json" // Journal inside fileOpsDirName
+	maxFileOps      = 20             // Operations kept, the oldest dropped first
+)
+
+// Kinds of fileOpStep.
+const (
+	fileCreated  = "created"  // Path didn't exist before
+	fileModified = "modified" // Path held the content of Backup
+	fileRenamed  = "renamed"  // Path was renamed to NewPath
+)
+
+// fileOp is an operation of the journal, made of the steps taken, in
+// order.
+type fileOp struct {
+	Title string       `json:"title"` // Shown when undoing, e.g. "Rename Package foo"
+	Time  time.Time    `json:"time"`
+	Steps []fileOpStep `json:"steps"`
+}
+
+// fileOpStep is a change of a single file or directory.
+type fileOpStep struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	NewPath string `json:"newPath,omitempty"` // Where Path went, for fileRenamed
+	Backup  string `json:"backup,omitempty"`  // Copy of the replaced content, for fileModified
+}
+
+// fileOpRecorder collects the steps of an operation as it is carried out.
+type fileOpRecorder struct {
+	dir string // Directory of the journal, "" if unavailable
+	op  fileOp
+	err error // First failure to back a file up
+}
+
+// fileOpsDir returns the directory of the journal, creating it readable
+// by the user only: backups hold the content of the user's files.
+func fileOpsDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, fileOpsDirName)
+	if err := os.MkdirAll(dir, privateDirPerms); err != nil {
+		return "", err
+	}
+	return dir, os.Chmod(dir, privateDirPerms)
+}
+
+// loadFileOps reads the journal of dir, oldest operation first.
+func loadFileOps(dir string) ([]fileOp, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fileOpsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ops []fileOp
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileOpsFileName, err)
+	}
+	return ops, nil
+}
+
+// saveFileOps writes ops as the journal of dir.
+func saveFileOps(dir string, ops []fileOp) error {
+	data, err := json.MarshalIndent(ops, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(filepath.Join(dir, fileOpsFileName), append(data, '\n'))
+}
+
+// removeBackups deletes the backups of the steps of op.
+func removeBackups(op fileOp) {
+	for _, s := range op.Steps {
+		if s.Backup != "" {
+			os.Remove(s.Backup)
+		}
+	}
+}
+
+// beginFileOp starts recording the operation called title.
+func (i *Ite) beginFileOp(title string) *fileOpRecorder {
+	r := &fileOpRecorder{op: fileOp{Title: title, Time: time.Now()}}
+	r.dir, r.err = fileOpsDir()
+	return r
+}
+
+// created records that path is about to be created, if it doesn't exist
+// yet; otherwise it is about to be modified.
+func (r *fileOpRecorder) created(path string) {
+	if fileExists(path) {
+		r.modifying(path)
+		return
+	}
+	r.op.Steps = append(r.op.Steps, fileOpStep{Kind: fileCreated, Path: absPath(path)})
+}
+
+// modifying backs up path, about to be rewritten or replaced.
+func (r *fileOpRecorder) modifying(path string) {
+	if r.dir == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		r.op.Steps = append(r.op.Steps, fileOpStep{Kind: fileCreated, Path: absPath(path)})
+		return
+	}
+	var backup *os.File
+	if err == nil {
+		backup, err = os.CreateTemp(r.dir, "backup-*-"+filepath.Base(path))
+	}
+	if err == nil {
+		_, err = backup.Write(data)
+		if cerr := backup.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(backup.Name())
+		}
+	}
+	if err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("backing up %s: %w", filepath.Base(path), err)
+		}
+		return
+	}
+	r.op.Steps = append(r.op.Steps, fileOpStep{Kind: fileModified, Path: absPath(path), Backup: backup.Name()})
+}
+
+// renamed records that oldPath was renamed to newPath.
+func (r *fileOpRecorder) renamed(oldPath, newPath string) {
+	r.op.Steps = append(r.op.Steps, fileOpStep{Kind: fileRenamed, Path: absPath(oldPath), NewPath: absPath(newPath)})
+}
+
+// absPath returns path made absolute, or unchanged if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// commitFileOp adds the operation recorded by r to the journal, dropping
+// the oldest ones past maxFileOps. A failure is shown in the status bar:
+// the operation itself went through.
+func (i *Ite) commitFileOp(r *fileOpRecorder) {
+	if len(r.op.Steps) == 0 {
+		return
+	}
+	err := r.err
+	if r.dir != "" {
+		var ops []fileOp
+		ops, err = loadFileOps(r.dir)
+		ops = append(ops, r.op)
+		if len(ops) > maxFileOps {
+			for _, op := range ops[:len(ops)-maxFileOps] {
+				removeBackups(op)
+			}
+			ops = slices.Clone(ops[len(ops)-maxFileOps:])
+		}
+		if serr := saveFileOps(r.dir, ops); err == nil {
+			err = serr
+		}
+		if err == nil {
+			err = r.err
+		}
+	}
+	if err != nil {
+		i.showStatusHint("Can't record " + r.op.Title + " for undo: " + err.Error())
+	}
+}
+
+// undoFileOp takes back the steps of op, last first: created files are
+// handed to discard, modified ones get their backup back and renamed ones
+// their old name. It returns the paths changed, stopping at the first
+// step that fails.
+func undoFileOp(op fileOp, discard func(path string) error) (changed []string, err error) {
+	for _, s := range slices.Backward(op.Steps) {
+		switch s.Kind {
+		case fileCreated:
+			if !fileExists(s.Path) {
+				continue // Already gone
+			}
+			err = discard(s.Path)
+		case fileModified:
+			var data []byte
+			if data, err = os.ReadFile(s.Backup); err == nil {
+				err = writeFileAtomic(s.Path, data, defaultFilePerms)
+			}
+		case fileRenamed:
+			if _, serr := os.Lstat(s.Path); serr == nil {
+				err = fmt.Errorf("%s exists", s.Path)
+			} else {
+				err = os.Rename(s.NewPath, s.Path)
+			}
+		default:
+			err = fmt.Errorf("unknown step %q", s.Kind)
+		}
+		if err != nil {
+			return changed, fmt.Errorf("%s: %w", filepath.Base(s.Path), err)
+		}
+		changed = append(changed, s.Path)
+	}
+	return changed, nil
+}
+
+// onUndoFileOp asks to take back the last operation of the journal and
+// does, then reloads the buffers of the files it touched.
+func (i *Ite) onUndoFileOp() {
+	dir, err := fileOpsDir()
+	var ops []fileOp
+	if err == nil {
+		ops, err = loadFileOps(dir)
+	}
+	if err != nil {
+		i.showError("Error reading the file operations: " + err.Error())
+		return
+	}
+	if len(ops) == 0 {
+		i.showStatusHint("No file operation to undo")
+		return
+	}
+	op := ops[len(ops)-1]
+	var paths []string
+	for _, s := range op.Steps {
+		paths = append(paths, relativeTo(i.projectDir(), s.Path))
+	}
+	answer := messageBox(Icon("question"), Title("Undo File Operation"), Type("yesno"),
+		Msg(fmt.Sprintf("Undo %s of %s (%s)?", op.Title, op.Time.Format(time.DateTime), strings.Join(paths, ", "))))
+	if answer != "yes" {
+		return
+	}
+	changed, err := undoFileOp(op, i.discardFile)
+	i.reloadChangedBuffers(op, changed)
+	if err != nil {
+		i.showError("Error undoing " + op.Title + ": " + err.Error())
+		return
+	}
+	removeBackups(op)
+	if err := saveFileOps(dir, ops[:len(ops)-1]); err != nil {
+		i.showError("Error saving the file operations: " + err.Error())
+		return
+	}
+	i.showStatusHint("Undid " + op.Title)
+}
+
+// reloadChangedBuffers reloads the unmodified buffers of the files
+// changed by undoing op, changed listing the paths of the steps taken
+// back. Buffers of files inside a directory renamed back follow it.
+func (i *Ite) reloadChangedBuffers(op fileOp, changed []string) {
+	for _, p := range i.panes {
+		i.withPane(p, func() {
+			if i.currentFile == "" || i.editText.Modified() {
+				return
+			}
+			file := absPath(i.currentFile)
+			for _, s := range op.Steps {
+				if s.Kind != fileRenamed || !slices.Contains(changed, s.Path) {
+					continue
+				}
+				if rel, err := filepath.Rel(s.NewPath, file); err == nil && filepath.IsLocal(rel) {
+					file = filepath.Join(s.Path, rel)
+				} else if file == s.NewPath {
+					file = s.Path
+				}
+			}
+			if file != absPath(i.currentFile) || slices.Contains(changed, file) && fileExists(file) {
+				i.currentFile = file
+				i.reloadActiveBuffer()
+			}
+		})
+	}
+}
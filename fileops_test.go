@@ -0,0 +1,94 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUndoFileOp(t *testing.T) {
+	journal, work := t.TempDir(), t.TempDir()
+	write := func(name, text string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(work, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	write("old.go", "package old\n")
+	if err := os.Mkdir(filepath.Join(work, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite a file, create another and rename a directory
+	r := &fileOpRecorder{dir: journal, op: fileOp{Title: "test"}}
+	r.modifying(filepath.Join(work, "old.go"))
+	write("old.go", "package renamed\n")
+	r.created(filepath.Join(work, "new.go"))
+	write("new.go", "package new\n")
+	if err := os.Rename(filepath.Join(work, "pkg"), filepath.Join(work, "lib")); err != nil {
+		t.Fatal(err)
+	}
+	r.renamed(filepath.Join(work, "pkg"), filepath.Join(work, "lib"))
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+
+	if err := saveFileOps(journal, []fileOp{r.op}); err != nil {
+		t.Fatal(err)
+	}
+	ops, err := loadFileOps(journal)
+	if err != nil || len(ops) != 1 || len(ops[0].Steps) != 3 {
+		t.Fatalf("loadFileOps = %+v, %v", ops, err)
+	}
+
+	changed, err := undoFileOp(ops[0], os.Remove)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 3 {
+		t.Errorf("changed = %q, want 3 paths", changed)
+	}
+	if got := read("old.go"); got != "package old\n" {
+		t.Errorf("old.go = %q after undo", got)
+	}
+	if fileExists(filepath.Join(work, "new.go")) {
+		t.Error("new.go still exists after undo")
+	}
+	if !fileExists(filepath.Join(work, "pkg")) || fileExists(filepath.Join(work, "lib")) {
+		t.Error("pkg not renamed back")
+	}
+}
+
+func TestUndoFileOpConflict(t *testing.T) {
+	work := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(work, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	op := fileOp{Steps: []fileOpStep{{Kind: fileRenamed, Path: filepath.Join(work, "a"), NewPath: filepath.Join(work, "b")}}}
+	if _, err := undoFileOp(op, os.Remove); err == nil {
+		t.Error("renaming back over an existing directory succeeded")
+	}
+	if !fileExists(filepath.Join(work, "b")) {
+		t.Error("b was moved")
+	}
+}
+
+func TestLoadFileOpsMissing(t *testing.T) {
+	ops, err := loadFileOps(t.TempDir())
+	if err != nil || ops != nil {
+		t.Errorf("loadFileOps of an empty directory = %v, %v", ops, err)
+	}
+}
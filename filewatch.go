@@ -0,0 +1,238 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// External Change Detection
+// -------------------------------------------------------------------------
+
+const fileWatchInterval = 2 * time.Second // How often the open file is checked
+
+// fileStamp identifies a version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statStamp returns the current stamp of path.
+func statStamp(path string) (fileStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+// recordDiskStamp remembers the version of the current file the buffer
+// was loaded from or saved to.
+func (i *Ite) recordDiskStamp() {
+	i.diskStamp, _ = statStamp(i.currentFile)
+}
+
+// changedOnDisk reports whether another program modified the current file
+// since it was loaded or saved.
+func (i *Ite) changedOnDisk() bool {
+	if i.currentFile == "" || i.diskStamp == (fileStamp{}) {
+		return false
+	}
+	stamp, err := statStamp(i.currentFile)
+	return err == nil && stamp != i.diskStamp
+}
+
+// watchFile polls the open files for external changes, then reschedules
+// itself. While commands run, changes are left for offerReloads to batch.
+func (i *Ite) watchFile() {
+	defer TclAfter(fileWatchInterval, i.watchFile)
+	if i.changePrompt || i.runningJobs() > 0 {
+		return
+	}
+	if i.changedOnDisk() {
+		i.promptFileChanged()
+	} else if other := i.otherPane(); other != nil {
+		// Another program may have saved over the file of the other pane
+		i.offerReloads()
+	}
+}
+
+// promptFileChanged asks what to do about a file changed on disk: reload
+// it, keep the buffer (the next save overwrites the file), or look at the
+// differences first.
+func (i *Ite) promptFileChanged() {
+	i.changePrompt = true
+	path := i.currentFile
+
+	dialog := Toplevel()
+	dialog.WmTitle("File Changed")
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	msg := filepath.Base(path) + " changed on disk."
+	if i.editText.Modified() {
+		msg += "\nThe buffer also has unsaved changes."
+	}
+	Grid(frame.TLabel(Txt(msg)), Row(0), Column(0), Sticky(W), Pady(px(5)))
+
+	done := func() {
+		i.changePrompt = false
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+	reload := func() {
+		done()
+		i.reloadBuffer(path)
+	}
+	keep := func() {
+		done()
+		i.keepBuffer(path)
+	}
+	diff := func() { i.diffWithDisk(path) }
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(1), Column(0), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Reload"), Command(reload)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Keep Mine"), Command(keep)), Row(0), Column(1), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Diff"), Command(diff)), Row(0), Column(2), Padx(px(5)))
+	WmProtocol(dialog.Window, "WM_DELETE_WINDOW", keep)
+}
+
+// reloadBuffer replaces the buffers of path with the file on disk.
+func (i *Ite) reloadBuffer(path string) {
+	for _, p := range i.panes {
+		i.withPane(p, func() {
+			if path == i.currentFile {
+				i.reloadActiveBuffer()
+			}
+		})
+	}
+}
+
+// reloadActiveBuffer replaces the buffer of the active pane with its file
+// on disk, keeping the cursor where it was.
+func (i *Ite) reloadActiveBuffer() {
+	insert := i.editText.Index("insert")
+	if err := i.openFile(i.currentFile); err != nil {
+		i.showError("Error reloading file: " + err.Error())
+		return
+	}
+	i.editText.MarkSet("insert", insert)
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// keepBuffer ignores the change on disk of path: the buffers stay as they
+// are and the next save overwrites the file.
+func (i *Ite) keepBuffer(path string) {
+	for _, p := range i.panes {
+		i.withPane(p, func() {
+			if path == i.currentFile {
+				i.recordDiskStamp()
+				i.editText.SetModified(true) // The buffer no longer matches the file
+				i.refreshCursorState()
+			}
+		})
+	}
+}
+
+// diffWithDisk shows the differences between the buffer of path and the
+// file on disk.
+func (i *Ite) diffWithDisk(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		i.showError("Error reading file: " + err.Error())
+		return
+	}
+	name := filepath.Base(path)
+	showDiffWindow(name+" - Changes on Disk", name+" (buffer)", name+" (disk)",
+		i.editText.Get("1.0", "end-1c")[0], decodedText(data))
+}
+
+// -------------------------------------------------------------------------
+// Reload After Commands
+// -------------------------------------------------------------------------
+
+// changedBuffers returns the paths of the open files that another program,
+// such as go generate or a formatter, modified since they were loaded, and
+// which of them have unsaved changes.
+func (i *Ite) changedBuffers() (paths []string, modified map[string]bool) {
+	modified = make(map[string]bool)
+	for _, p := range i.panes {
+		i.withPane(p, func() {
+			if !i.changedOnDisk() {
+				return
+			}
+			if !slices.Contains(paths, i.currentFile) {
+				paths = append(paths, i.currentFile)
+			}
+			modified[i.currentFile] = modified[i.currentFile] || i.editText.Modified()
+		})
+	}
+	return paths, modified
+}
+
+// offerReloads runs once the last command finished. Rather than asking
+// about every file a command rewrote, it lists them all in one dialog
+// where each can be reloaded or kept. Unmodified buffers are checked for
+// reload by default; buffers with unsaved edits are not.
+func (i *Ite) offerReloads() {
+	if i.changePrompt {
+		return
+	}
+	paths, modifiedPaths := i.changedBuffers()
+	if len(paths) == 0 {
+		return
+	}
+	i.changePrompt = true
+
+	dialog := Toplevel()
+	dialog.WmTitle("Files Changed")
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	Grid(frame.TLabel(Txt("These files changed on disk. Reload the checked ones:")),
+		Row(0), Column(0), Columnspan(2), Sticky(W), Pady(px(5)))
+
+	checks := make([]*TCheckbuttonWidget, len(paths))
+	for n, path := range paths {
+		label := i.displayPath(path)
+		modified := modifiedPaths[path]
+		if modified {
+			label += " (unsaved changes)"
+		}
+		checks[n] = frame.TCheckbutton(Txt(label), Variable(checkValue(!modified)))
+		Grid(checks[n], Row(n+1), Column(0), Sticky(W))
+		Grid(frame.TButton(Txt("Diff"), Command(func() { i.diffWithDisk(path) })),
+			Row(n+1), Column(1), Padx(px(5)))
+	}
+
+	apply := func(reload bool) {
+		i.changePrompt = false
+		checked := make([]bool, len(checks))
+		for n, check := range checks {
+			checked[n] = reload && check.Variable() == "1"
+		}
+		Destroy(dialog)
+		Focus(i.editText)
+		for n, path := range paths {
+			if checked[n] {
+				i.reloadBuffer(path)
+			} else {
+				i.keepBuffer(path)
+			}
+		}
+	}
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(len(paths)+1), Column(0), Columnspan(2), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Reload"), Command(func() { apply(true) })), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Keep All"), Command(func() { apply(false) })), Row(0), Column(1), Padx(px(5)))
+	Bind(dialog, "<Escape>", Command(func() { apply(false) }))
+	WmProtocol(dialog.Window, "WM_DELETE_WINDOW", func() { apply(false) })
+}
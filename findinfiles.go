@@ -0,0 +1,343 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Find in Files
+// -------------------------------------------------------------------------
+
+const (
+	maxFindHits     = 2000     // Results listed before the search gives up
+	maxFindFileSize = 10 << 20 // Larger files are skipped
+	binarySniffLen  = 8000     // Bytes checked for NUL to detect binary files
+	findPreviewLen  = 200      // Longest line preview, in characters
+)
+
+// findHit is a line matching a Find in Files search.
+type findHit struct {
+	path string
+	line int // 1-based
+	col  int // 1-based UTF-8 byte column of the match
+	text string
+}
+
+// findResult is the outcome of a search, delivered to showFindResult.
+type findResult struct {
+	id        int // Search the result belongs to
+	hits      []findHit
+	files     int  // Files searched
+	truncated bool // More than maxFindHits matched
+	err       error
+}
+
+// findPanel holds the widgets of the Find in Files window.
+type findPanel struct {
+	window  *ToplevelWidget
+	pattern *TEntryWidget
+	regex   *TCheckbuttonWidget
+	fold    *TCheckbuttonWidget
+	status  *TLabelWidget
+	results *TextWidget
+	root    string
+	exclude []string        // Exclude patterns of the project
+	hits    map[int]findHit // Hits by results line
+	id      int             // Latest search
+}
+
+// onFindInFiles opens the Find in Files window. Searches run over the
+// project of the current file, skipping what .gitignore excludes and
+// binary files.
+func (i *Ite) onFindInFiles() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.find != nil {
+		Destroy(i.find.window)
+	}
+	p := &findPanel{window: Toplevel(), root: i.projectDir(), exclude: i.projectSettingsOrError().Exclude}
+	p.window.WmTitle("Find in Files - " + p.root)
+
+	top := p.window.TFrame()
+	Grid(top, Row(0), Column(0), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	Grid(top.TLabel(Txt("Find:")), Row(0), Column(0), Padx(px(2)))
+	initial := ""
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		if s := i.editText.Get(sel[0], sel[len(sel)-1])[0]; !strings.Contains(s, "\n") {
+			initial = s
+		}
+	}
+	p.pattern = top.TEntry(Width(50), Textvariable(initial))
+	Grid(p.pattern, Row(0), Column(1), Sticky(WE))
+	p.regex = top.TCheckbutton(Txt("Regex"), Variable(0))
+	Grid(p.regex, Row(0), Column(2), Padx(px(2)))
+	p.fold = top.TCheckbutton(Txt("Ignore case"), Variable(0))
+	Grid(p.fold, Row(0), Column(3), Padx(px(2)))
+	Grid(top.TButton(Txt("Search"), Command(i.runFindInFiles)), Row(0), Column(4), Padx(px(2)))
+	GridColumnConfigure(top, 1, Weight(1))
+
+	p.results = p.window.Text(textStyle(), Width(100), Height(25), Wrap("none"), State("disabled"))
+	scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.results) }))
+	p.results.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(p.results, Row(1), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(1), Column(1), Sticky(NS))
+	p.status = p.window.TLabel(Txt(""))
+	Grid(p.status, Row(2), Column(0), Sticky(W), Padx(px(5)))
+	GridRowConfigure(p.window, 1, Weight(1))
+	GridColumnConfigure(p.window, 0, Weight(1))
+
+	p.results.TagConfigure(tagLink, Foreground(theme.Link))
+	p.results.TagConfigure(tagFindMatch, Background(theme.Selection))
+	p.results.TagBind(tagLink, "<Button-1>", func() {
+		line, _ := parseIndex(p.results.Index("current"))
+		if hit, ok := p.hits[line]; ok {
+			i.showLocation(location{path: hit.path, line: hit.line, col: hit.col})
+		}
+	})
+	bindPanelKeys(p.results, func(line int) {
+		if hit, ok := p.hits[line]; ok {
+			i.showLocation(location{path: hit.path, line: hit.line, col: hit.col})
+		}
+	})
+	p.results.TagBind(tagLink, "<Enter>", func() { p.results.Configure(Cursor("hand2")) })
+	p.results.TagBind(tagLink, "<Leave>", func() { p.results.Configure(Cursor("xterm")) })
+
+	closeWindow := func() {
+		Destroy(p.window)
+		i.find = nil
+	}
+	Bind(p.pattern, "<Return>", Command(i.runFindInFiles))
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	i.bindFocusKeys(p.window.Window)
+	Focus(p.pattern)
+	i.find = p
+}
+
+const tagFindMatch = "findmatch" // Find in Files tag of the matched text
+
+// runFindInFiles starts a search with the pattern of the Find in Files
+// window, superseding any search still running.
+func (i *Ite) runFindInFiles() {
+	p := i.find
+	pattern := p.pattern.Textvariable()
+	if pattern == "" {
+		return
+	}
+	if p.regex.Variable() != "1" {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if p.fold.Variable() == "1" {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		p.status.Configure(Txt("Invalid regex: " + err.Error()))
+		return
+	}
+	p.id++
+	id, root, exclude := p.id, p.root, p.exclude
+	p.status.Configure(Txt("Searching..."))
+	i.goBackground(func(ctx context.Context) {
+		res := searchFiles(ctx, root, exclude, re)
+		res.id = id
+		i.Dispatch(func() { i.showFindResult(res) })
+	})
+}
+
+// searchFiles looks for re in the text files below root but those
+// matching exclude, several files at a time, until ctx is canceled. Hits
+// are sorted by path and line.
+func searchFiles(ctx context.Context, root string, exclude []string, re *regexp.Regexp) findResult {
+	paths := make(chan string)
+	var res findResult
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				hits := searchFile(path, re)
+				mu.Lock()
+				res.files++
+				res.hits = append(res.hits, hits...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	res.err = walkProject(root, exclude, func(path string) error {
+		mu.Lock()
+		full := len(res.hits) >= maxFindHits
+		mu.Unlock()
+		if full || ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		paths <- path
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+
+	slices.SortFunc(res.hits, func(a, b findHit) int {
+		if c := strings.Compare(a.path, b.path); c != 0 {
+			return c
+		}
+		return a.line - b.line
+	})
+	if len(res.hits) > maxFindHits {
+		res.hits = res.hits[:maxFindHits]
+		res.truncated = true
+	}
+	return res
+}
+
+// walkProject calls visit with each regular file below root, skipping the
+// .git directory, what .gitignore excludes and what matches the exclude
+// patterns, given like those of .gitignore. visit can return
+// filepath.SkipAll to stop the walk.
+func walkProject(root string, exclude []string, visit func(path string) error) error {
+	var ignore gitignore
+	for _, pattern := range exclude {
+		if rule, ok := parseIgnoreRule("", pattern); ok {
+			ignore.rules = append(ignore.rules, rule)
+		}
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable entries are skipped
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			if rel != "." && (d.Name() == ".git" || ignore.ignored(filepath.ToSlash(rel), true)) {
+				return filepath.SkipDir
+			}
+			ignore.load(root, rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || ignore.ignored(filepath.ToSlash(rel), false) {
+			return nil
+		}
+		return visit(path)
+	})
+}
+
+// searchFile returns the lines of the file at path matching re. Binary
+// and very large files yield nothing.
+func searchFile(path string, re *regexp.Regexp) []findHit {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() > maxFindFileSize {
+		return nil
+	}
+	head := make([]byte, binarySniffLen)
+	n, _ := io.ReadFull(f, head)
+	if bytes.IndexByte(head[:n], 0) >= 0 {
+		return nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil
+	}
+
+	var hits []findHit
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxFindFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if loc := re.FindStringIndex(text); loc != nil {
+			hits = append(hits, findHit{path: path, line: line, col: loc[0] + 1, text: text})
+		}
+	}
+	return hits
+}
+
+// showFindResult shows the result of a search, unless a newer one was
+// started or the window closed since.
+func (i *Ite) showFindResult(res findResult) {
+	if i.find != nil && res.id == i.find.id {
+		i.find.show(res)
+	}
+}
+
+// show lists the hits of res, one "path:line: text" line each, with the
+// matched text highlighted.
+func (p *findPanel) show(res findResult) {
+	p.hits = make(map[int]findHit)
+	p.results.Configure(State("normal"))
+	p.results.Delete("1.0", "end")
+	for n, hit := range res.hits {
+		line := n + 1
+		loc := fmt.Sprintf("%s:%d:", relativeTo(p.root, hit.path), hit.line)
+		text := strings.TrimLeft(hit.text, " \t")
+		trim := len(hit.text) - len(text)
+		if runes := []rune(text); len(runes) > findPreviewLen {
+			text = string(runes[:findPreviewLen]) + "…"
+		}
+		p.results.Insert("end", loc, tagLink)
+		p.results.Insert("end", " "+text+"\n")
+		p.hits[line] = hit
+
+		// Highlight the match when it is within the preview
+		start := hit.col - 1 - trim
+		if start >= 0 && start < len(text) {
+			if m := p.matchLen(hit, start+trim); m > 0 {
+				from := len([]rune(loc)) + 1 + runeColumn(text, start)
+				to := len([]rune(loc)) + 1 + runeColumn(text, min(start+m, len(text)))
+				p.results.TagAdd(tagFindMatch, fmt.Sprintf("%d.%d", line, from), fmt.Sprintf("%d.%d", line, to))
+			}
+		}
+	}
+	p.results.Configure(State("disabled"))
+
+	status := fmt.Sprintf("%d matches in %d files", len(res.hits), res.files)
+	switch {
+	case res.err != nil:
+		status += " (" + res.err.Error() + ")"
+	case res.truncated:
+		status += fmt.Sprintf(" (stopped after %d)", maxFindHits)
+	}
+	p.status.Configure(Txt(status))
+}
+
+// matchLen returns the length in bytes of the match of hit starting at
+// byte offset start of its line.
+func (p *findPanel) matchLen(hit findHit, start int) int {
+	pattern := p.pattern.Textvariable()
+	if p.regex.Variable() != "1" {
+		return len(pattern)
+	}
+	if p.fold.Variable() == "1" {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0
+	}
+	if loc := re.FindStringIndex(hit.text[start:]); loc != nil && loc[0] == 0 {
+		return loc[1]
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Test Fixtures
+// -------------------------------------------------------------------------
+
+// Resting the mouse on a string literal naming a file under testdata
+// shows its first lines in the hover, and Go to Definition, or a
+// Ctrl+click, on the literal opens the file. The path is resolved from
+// the directory of the Go file, where go test runs.
+const (
+	fixtureDir         = "testdata"
+	maxFixturePreview  = 4096 // Bytes of the file read for the preview
+	fixtureHoverPrefix = `"`  // Marks the hover of a literal, whose index follows
+)
+
+// stringLiteralAt returns the value of the Go string literal of line
+// holding the character column col, and the column it starts at.
+func stringLiteralAt(line string, col int) (value string, start int, ok bool) {
+	runes := []rune(line)
+	for n := 0; n < len(runes); n++ {
+		quote := runes[n]
+		if quote == '/' && n+1 < len(runes) && runes[n+1] == '/' {
+			return "", 0, false // Comment
+		}
+		if quote == '\'' {
+			for n++; n < len(runes) && runes[n] != '\''; n++ {
+				if runes[n] == '\\' {
+					n++
+				}
+			}
+			continue
+		}
+		if quote != '"' && quote != '`' {
+			continue
+		}
+		end := n + 1
+		for ; end < len(runes) && runes[end] != quote; end++ {
+			if quote == '"' && runes[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(runes) {
+			return "", 0, false // Left open
+		}
+		if n <= col && col <= end {
+			value, err := strconv.Unquote(string(runes[n : end+1]))
+			return value, n, err == nil
+		}
+		n = end
+	}
+	return "", 0, false
+}
+
+// isFixturePath reports whether the literal value names a path under a
+// testdata directory.
+func isFixturePath(value string) bool {
+	if strings.ContainsAny(value, "\n\t") {
+		return false
+	}
+	elems := strings.Split(filepath.ToSlash(value), "/")
+	return slices.Contains(elems[:len(elems)-1], fixtureDir)
+}
+
+// fixturePreview returns the start of the file at path as shown in the
+// hover: its first lines, or its size if it is binary.
+func fixturePreview(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxFixturePreview))
+	if err != nil {
+		return "", err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return fmt.Sprintf("Binary file, %d bytes", info.Size()), nil
+	}
+	if len(data) == 0 {
+		return "Empty file", nil
+	}
+	for len(data) > 0 && !utf8.Valid(data) {
+		data = data[:len(data)-1] // Cut in a character
+	}
+	text, _ := decodeText(data)
+	return strings.TrimRight(normalizeEOL(text), "\n"), nil
+}
+
+// fixtureAt returns the path of the testdata file named by the literal
+// around the character column col of line of the current buffer, and the
+// column the literal starts at.
+func (i *Ite) fixtureAt(line, col int) (path string, start int, ok bool) {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		return "", 0, false
+	}
+	value, start, ok := stringLiteralAt(lineText(i.editText, line), col)
+	if !ok || !isFixturePath(value) {
+		return "", 0, false
+	}
+	path = filepath.FromSlash(value)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(i.currentFile), path)
+	}
+	if fi, err := os.Stat(path); err != nil || !fi.Mode().IsRegular() {
+		return "", 0, false
+	}
+	return path, start, true
+}
+
+// fixtureHover starts the hover of the testdata file under the mouse at
+// index, reporting whether there is one.
+func (i *Ite) fixtureHover(index string) bool {
+	line, col := parseIndex(index)
+	path, start, ok := i.fixtureAt(line, col)
+	if !ok {
+		return false
+	}
+	key := fmt.Sprintf("%s%d.%d", fixtureHoverPrefix, line, start)
+	if key == i.hint.hoverWord {
+		return true
+	}
+	i.hideHover()
+	i.hint.hoverWord = key
+	i.hint.hoverTimer = TclAfter(hoverDelay, func() {
+		i.hint.hoverTimer = ""
+		i.showFixture(path)
+	})
+	return true
+}
+
+// showFixture shows the start of the file at path at the mouse.
+func (i *Ite) showFixture(path string) {
+	preview, err := fixturePreview(path)
+	if err != nil {
+		preview = err.Error()
+	}
+	head := relativeTo(i.projectDir(), path)
+	xy := strings.Fields(tclEval("winfo pointerxy %s", App))
+	if len(xy) < 2 {
+		return
+	}
+	i.showHint(hintHover, winfoInt(xy[0])+12, winfoInt(xy[1])+16, head, [2]int{0, len(head)}, preview)
+}
+
+// openFixture opens the testdata file named by the literal at the cursor,
+// reporting whether there is one.
+func (i *Ite) openFixture() bool {
+	line, col := parseIndex(i.editText.Index("insert"))
+	path, _, ok := i.fixtureAt(line, col)
+	if !ok {
+		return false
+	}
+	i.showLocation(location{path: path, line: 1, col: 1})
+	return true
+}
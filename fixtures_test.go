@@ -0,0 +1,65 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStringLiteralAt(t *testing.T) {
+	line := "\tf := open(\"testdata/a\\\"b.txt\", '\"', `raw/x`) // \"no\""
+	for _, tt := range []struct {
+		col   int
+		value string
+		start int
+		ok    bool
+	}{
+		{col: 11, value: `testdata/a"b.txt`, start: 11, ok: true},
+		{col: 20, value: `testdata/a"b.txt`, start: 11, ok: true},
+		{col: 5},
+		{col: 33}, // The rune literal
+		{col: 40, value: "raw/x", start: 37, ok: true},
+		{col: 52}, // The comment
+	} {
+		value, start, ok := stringLiteralAt(line, tt.col)
+		if ok != tt.ok || value != tt.value || start != tt.start {
+			t.Errorf("stringLiteralAt(col %d) = %q, %d, %v; want %q, %d, %v",
+				tt.col, value, start, ok, tt.value, tt.start, tt.ok)
+		}
+	}
+}
+
+func TestIsFixturePath(t *testing.T) {
+	for value, want := range map[string]bool{
+		"testdata/in.txt":         true,
+		"./testdata/golden/x.out": true,
+		"../other/testdata/y":     true,
+		"testdata":                false,
+		"mytestdata/in.txt":       false,
+		"testdata/a\nb":           false,
+	} {
+		if got := isFixturePath(value); got != want {
+			t.Errorf("isFixturePath(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestFixturePreview(t *testing.T) {
+	dir := t.TempDir()
+	for name, tt := range map[string]struct{ data, want string }{
+		"in.txt":  {"first\r\nsecond\r\n", "first\nsecond"},
+		"img.png": {"\x89PNG\x00\x01", "Binary file, 6 bytes"},
+		"empty":   {"", "Empty file"},
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := fixturePreview(path); err != nil || got != tt.want {
+			t.Errorf("fixturePreview(%s) = %q, %v; want %q", name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Panel Focus
+// -------------------------------------------------------------------------
+
+const (
+	focusRingWidth = 2            // Thickness of the ring around the focused panel
+	tagCursorLine  = "cursorline" // Line of the keyboard cursor in read-only panels
+)
+
+// Panels reachable from the keyboard, in F6 order.
+const (
+	panelEditor = iota
+	panelOtherEditor
+	panelConsole
+	panelOutline
+	panelFindResults
+	panelCount
+)
+
+// panelWidget returns the widget taking the focus for panel, or nil when
+// the panel is not open.
+func (i *Ite) panelWidget(panel int) *Window {
+	switch panel {
+	case panelEditor:
+		return i.editText.Window
+	case panelOtherEditor:
+		if other := i.otherPane(); other != nil {
+			return other.text.Window
+		}
+	case panelConsole:
+		if i.placed[regionRight] {
+			return i.selectedConsole().text.Window
+		}
+	case panelOutline:
+		if i.placed[regionLeft] && i.config.ShowOutline {
+			return i.outlineList.Window
+		}
+	case panelFindResults:
+		if i.find != nil {
+			return i.find.results.Window
+		}
+	}
+	return nil
+}
+
+// focusedPanel returns the panel holding the keyboard focus, or -1.
+func (i *Ite) focusedPanel() int {
+	focused := tclEval("focus")
+	for panel := range panelCount {
+		if w := i.panelWidget(panel); w != nil && w.String() == focused {
+			return panel
+		}
+	}
+	return -1
+}
+
+// focusPanel moves the keyboard focus to panel, raising its window.
+func (i *Ite) focusPanel(panel int) {
+	w := i.panelWidget(panel)
+	if w == nil {
+		i.showStatusHint("Panel not open")
+		return
+	}
+	if panel == panelFindResults {
+		tclEval("raise %s", i.find.window)
+	}
+	Focus(w)
+}
+
+// cycleFocus moves the focus to the next open panel, or to the previous
+// one when delta is negative.
+func (i *Ite) cycleFocus(delta int) {
+	panel := max(i.focusedPanel(), 0)
+	for range panelCount {
+		panel = (panel + delta + panelCount) % panelCount
+		if i.panelWidget(panel) != nil {
+			i.focusPanel(panel)
+			return
+		}
+	}
+}
+
+// bindPanelKeys lets the keyboard drive a read-only text panel: the arrow
+// keys move a highlighted cursor line and Return runs activate on it.
+func bindPanelKeys(text *TextWidget, activate func(line int)) {
+	mark := func() {
+		text.TagConfigure(tagCursorLine, Background(theme.Selection)) // Follows theme changes
+		line, _ := parseIndex(text.Index("insert"))
+		text.TagRemove(tagCursorLine, "1.0", "end")
+		text.TagAdd(tagCursorLine, fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.0", line+1))
+	}
+	Bind(text, "<FocusIn>", Command(mark))
+	Bind(text, "<KeyRelease>", Command(mark))
+	Bind(text, "<FocusOut>", Command(func() { text.TagRemove(tagCursorLine, "1.0", "end") }))
+	Bind(text, "<Return>", Command(func() {
+		line, _ := parseIndex(text.Index("insert"))
+		activate(line)
+	}))
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Code Folding
+// -------------------------------------------------------------------------
+
+// A fold hides the body of a func, of a type or of a parenthesized
+// declaration block, or the rest of a long comment, behind its first line.
+// Each folded region has its own elided tag, so that it follows the edits
+// around it; the regions that can be folded come from the parse made for
+// the outline.
+const (
+	foldCommentLines = 4          // Lines from which a comment block can be folded
+	foldBindTag      = "IteFold"  // Bind tag of the clicks on the gutter
+	foldTagPrefix    = "fold#"    // Prefix of the elided tags of folded regions
+	tagFoldable      = "foldable" // Editor tag of the first line of a region that can be folded
+	tagFolded        = "folded"   // Editor tag of the first line of a folded region
+)
+
+// foldRegion is a region that can be folded: line stays visible, and the
+// lines after it up to last are hidden.
+type foldRegion struct {
+	line, last int
+}
+
+// foldRegions returns the regions of the parsed Go file that can be
+// folded: bodies of funcs and func literals, struct and interface types,
+// parenthesized declaration blocks, and long comments.
+func foldRegions(fset *token.FileSet, file *ast.File) []foldRegion {
+	var regions []foldRegion
+	lineOf := func(p token.Pos) int { return fset.Position(p).Line }
+	// Between brackets, the line of the closing one stays visible
+	brackets := func(open, close token.Pos) {
+		if open.IsValid() && close.IsValid() && lineOf(close)-lineOf(open) >= 2 {
+			regions = append(regions, foldRegion{lineOf(open), lineOf(close) - 1})
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				brackets(n.Body.Lbrace, n.Body.Rbrace)
+			}
+		case *ast.FuncLit:
+			brackets(n.Body.Lbrace, n.Body.Rbrace)
+		case *ast.GenDecl:
+			brackets(n.Lparen, n.Rparen)
+		case *ast.StructType:
+			brackets(n.Fields.Opening, n.Fields.Closing)
+		case *ast.InterfaceType:
+			brackets(n.Methods.Opening, n.Methods.Closing)
+		}
+		return true
+	})
+	for _, c := range file.Comments {
+		first, last := lineOf(c.Pos()), lineOf(c.End())
+		if last-first+1 >= foldCommentLines {
+			regions = append(regions, foldRegion{first, last})
+		}
+	}
+	return regions
+}
+
+// bindFolding makes a click on the gutter of the first line of a region
+// fold or unfold it.
+func (i *Ite) bindFolding() {
+	addBindtag(i.editText.Window, foldBindTag, "Text")
+	Bind(foldBindTag, "<Button-1>", Command(func(e *Event) {
+		if i.onGutterClick(e) {
+			e.SetReturnCodeBreak()
+		}
+	}))
+}
+
+// onGutterClick toggles the fold of the line clicked, if the click fell
+// left of the text of the line. It reports whether it handled the click.
+func (i *Ite) onGutterClick(e *Event) bool {
+	index := mouseIndex(i.editText, e)
+	bbox := strings.Fields(tclEval("%s bbox {%s linestart}", i.editText, index))
+	if len(bbox) == 0 {
+		return false
+	}
+	var x int
+	if _, err := fmt.Sscan(bbox[0], &x); err != nil || e.X >= x {
+		return false
+	}
+	line, _ := parseIndex(index)
+	r, ok := i.foldAt(line, true)
+	if !ok {
+		return false
+	}
+	i.toggleFold(r)
+	return true
+}
+
+// foldAt returns the innermost region starting at line or, unless exact,
+// holding it.
+func (i *Ite) foldAt(line int, exact bool) (foldRegion, bool) {
+	var best foldRegion
+	found := false
+	for _, r := range i.folds {
+		if r.line == line || (!exact && r.line < line && line <= r.last) {
+			if !found || r.last-r.line < best.last-best.line {
+				best, found = r, true
+			}
+		}
+	}
+	return best, found
+}
+
+// foldTag returns the elided tag hiding r, or "" if r is not folded.
+func (i *Ite) foldTag(r foldRegion) string {
+	for _, tag := range i.editText.TagNames(fmt.Sprintf("%d.0", r.line+1)) {
+		if strings.HasPrefix(tag, foldTagPrefix) {
+			if ranges := i.editText.TagRanges(tag); len(ranges) >= 2 {
+				if l, _ := parseIndex(ranges[0]); l == r.line+1 {
+					return tag
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// foldTags returns the elided tags of the folded regions.
+func (i *Ite) foldTags() []string {
+	var tags []string
+	for _, tag := range i.editText.TagNames("") {
+		if strings.HasPrefix(tag, foldTagPrefix) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// fold hides the lines of r, moving the cursor out of them.
+func (i *Ite) fold(r foldRegion) {
+	if i.foldTag(r) != "" {
+		return
+	}
+	i.foldSeq++
+	tag := fmt.Sprintf("%s%d", foldTagPrefix, i.foldSeq)
+	i.editText.TagConfigure(tag, Elide(1))
+	i.editText.TagAdd(tag, fmt.Sprintf("%d.0", r.line+1), fmt.Sprintf("%d.0", r.last+1))
+	if line, _ := parseIndex(i.editText.Index("insert")); line > r.line && line <= r.last {
+		i.editText.MarkSet("insert", fmt.Sprintf("%d.end", r.line))
+	}
+}
+
+// toggleFold folds r, or unfolds it when it is folded.
+func (i *Ite) toggleFold(r foldRegion) {
+	if tag := i.foldTag(r); tag != "" {
+		i.editText.TagDelete(tag)
+	} else {
+		i.fold(r)
+	}
+	i.markFolds()
+	i.refreshCursorState()
+}
+
+// onToggleFold folds or unfolds the innermost region at the cursor.
+func (i *Ite) onToggleFold() {
+	line, _ := parseIndex(i.editText.Index("insert"))
+	r, ok := i.foldAt(line, false)
+	if !ok {
+		i.showStatusHint("Nothing to fold here")
+		return
+	}
+	i.toggleFold(r)
+	i.editText.See("insert")
+}
+
+// onFoldAll folds every region of the buffer.
+func (i *Ite) onFoldAll() {
+	for _, r := range i.folds {
+		i.fold(r)
+	}
+	i.markFolds()
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// onUnfoldAll shows the whole buffer again.
+func (i *Ite) onUnfoldAll() {
+	i.clearFolds()
+	i.markFolds()
+	i.editText.See("insert")
+}
+
+// clearFolds unfolds every region, as when the buffer gets another file.
+func (i *Ite) clearFolds() {
+	if tags := i.foldTags(); len(tags) > 0 {
+		i.editText.TagDelete(tags...)
+	}
+}
+
+// markFolds draws the gutter markers of the regions that can be folded
+// and of the folded ones.
+func (i *Ite) markFolds() {
+	i.editText.TagRemove(tagFoldable, "1.0", "end")
+	i.editText.TagRemove(tagFolded, "1.0", "end")
+	for _, r := range i.folds {
+		i.editText.TagAdd(tagFoldable, fmt.Sprintf("%d.0", r.line), fmt.Sprintf("%d.0", r.line+1))
+	}
+	for _, tag := range i.foldTags() {
+		if ranges := i.editText.TagRanges(tag); len(ranges) >= 2 {
+			line, _ := parseIndex(ranges[0])
+			i.editText.TagAdd(tagFolded, fmt.Sprintf("%d.0", line-1), fmt.Sprintf("%d.0", line))
+		}
+	}
+}
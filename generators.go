@@ -0,0 +1,281 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// File Generators
+// -------------------------------------------------------------------------
+
+// Generate .gitignore and Generate License fill a file at the project root
+// from a built-in template and open it unsaved for review: nothing is
+// written until the buffer is saved. An existing file is loaded with the
+// template in place of its text, one undo step away from the original.
+const (
+	gitignoreFileName = ".gitignore"
+	licenseFileName   = "LICENSE"
+)
+
+// gitignoreTemplate ignores the artifacts of the go command and common
+// editor files; %s is the name of the binary of the module.
+const gitignoreTemplate = `# Binaries
+/%s
+*.exe
+*.exe~
+*.dll
+*.so
+*.dylib
+
+# Test binaries and profiles
+*.test
+*.out
+*.prof
+coverage.*
+
+# Workspace files, local to each checkout
+go.work
+go.work.sum
+
+# Dependencies, when not committed
+# vendor/
+
+# Environment
+.env
+
+# Editors and operating systems
+.idea/
+.vscode/
+*.swp
+*~
+.DS_Store
+`
+
+// licenseTemplates are the license texts offered, by SPDX identifier.
+// {{year}} and {{holder}} are replaced by the year and the copyright
+// holder.
+var licenseTemplates = map[string]string{
+	"MIT": `MIT License
+
+Copyright (c) {{year}} {{holder}}
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`,
+	"BSD-2-Clause": `BSD 2-Clause License
+
+Copyright (c) {{year}}, {{holder}}
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`,
+	"BSD-3-Clause": `BSD 3-Clause License
+
+Copyright (c) {{year}}, {{holder}}
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`,
+	"ISC": `ISC License
+
+Copyright (c) {{year}} {{holder}}
+
+Permission to use, copy, modify, and/or distribute this software for any
+purpose with or without fee is hereby granted, provided that the above
+copyright notice and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+`,
+	"Unlicense": `This is free and unencumbered software released into the public domain.
+
+Anyone is free to copy, modify, publish, use, compile, sell, or
+distribute this software, either in source code form or as a compiled
+binary, for any purpose, commercial or non-commercial, and by any
+means.
+
+In jurisdictions that recognize copyright laws, the author or authors
+of this software dedicate any and all copyright interest in the
+software to the public domain. We make this dedication for the benefit
+of the public at large and to the detriment of our heirs and
+successors. We intend this dedication to be an overt act of
+relinquishment in perpetuity of all present and future rights to this
+software under copyright law.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+IN NO EVENT SHALL THE AUTHORS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+OTHER DEALINGS IN THE SOFTWARE.
+
+For more information, please refer to <https://unlicense.org>
+`,
+}
+
+// generatorRoot returns the directory generated files go to: the root of
+// the project of the current file, or the default directory.
+func (i *Ite) generatorRoot() string {
+	if i.currentFile == "" {
+		return i.defaultDir()
+	}
+	return i.projectDir()
+}
+
+// onGenerateGitignore opens a .gitignore for Go at the project root.
+func (i *Ite) onGenerateGitignore() {
+	root := i.generatorRoot()
+	name := filepath.Base(root)
+	if mod := modulePath(filepath.Join(root, "go.mod")); mod != "" {
+		name = importName(mod)
+	}
+	i.openGenerated(filepath.Join(root, gitignoreFileName), fmt.Sprintf(gitignoreTemplate, name))
+}
+
+// onGenerateLicense asks for a license and its copyright holder and opens
+// a LICENSE file at the project root.
+func (i *Ite) onGenerateLicense() {
+	root := i.generatorRoot()
+	names := make([]string, 0, len(licenseTemplates))
+	for name := range licenseTemplates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	holder, _ := runGit(root, "", "config", "user.name")
+
+	dialog := Toplevel()
+	dialog.WmTitle("Generate License")
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	Grid(frame.TLabel(Txt("License:")), Row(0), Column(0), Sticky(W), Pady(px(5)))
+	licenseBox := frame.TCombobox(Values(names), State("readonly"), Width(20), Textvariable("MIT"))
+	Grid(licenseBox, Row(0), Column(1), Sticky(W), Pady(px(5)))
+	Grid(frame.TLabel(Txt("Copyright holder:")), Row(1), Column(0), Sticky(W), Pady(px(5)))
+	holderEntry := frame.TEntry(Width(40), Textvariable(strings.TrimSpace(holder)))
+	Grid(holderEntry, Row(1), Column(1), Sticky(W), Pady(px(5)))
+	Focus(holderEntry)
+
+	confirm := func() {
+		license, holder := licenseBox.Textvariable(), strings.TrimSpace(holderEntry.Textvariable())
+		Destroy(dialog)
+		Focus(i.editText)
+		text := strings.ReplaceAll(licenseTemplates[license], "{{year}}", strconv.Itoa(time.Now().Year()))
+		text = strings.ReplaceAll(text, "{{holder}}", orDefault(holder, "the authors"))
+		i.openGenerated(filepath.Join(root, licenseFileName), text)
+	}
+	cancel := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(2), Column(0), Columnspan(2), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("OK"), Command(confirm)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Cancel"), Command(cancel)), Row(0), Column(1), Padx(px(5)))
+	Bind(holderEntry, "<Return>", Command(confirm))
+	Bind(dialog, "<Escape>", Command(cancel))
+}
+
+// openGenerated opens text as the unsaved content of path, loading path
+// first when it exists so saving replaces it.
+func (i *Ite) openGenerated(path, text string) {
+	if !i.promptSaveIfModified() {
+		return
+	}
+	if fileExists(path) {
+		resp := messageBox(Icon("question"), Title("Generate"), Type("okcancel"),
+			Msg(filepath.Base(path)+" already exists."),
+			Detail("The template replaces its text in the editor. The file changes only when you save."))
+		if resp != "ok" {
+			return
+		}
+		if err := i.openFile(path); err != nil {
+			i.showError("Error opening file: " + err.Error())
+			return
+		}
+		i.editGroup(func() {
+			i.editText.Delete("1.0", "end-1c")
+			i.editText.Insert("1.0", text)
+		})
+	} else {
+		i.onNew()
+		i.currentFile = path
+		i.editText.Insert("1.0", text)
+		i.updateTitle()
+	}
+	i.editText.MarkSet("insert", "1.0")
+	i.editText.See("insert")
+	i.refreshCursorState()
+	i.updateGutter()
+	i.showStatusHint("Review " + filepath.Base(path) + " and save it to write the file")
+}
@@ -0,0 +1,474 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Git
+// -------------------------------------------------------------------------
+
+// ITE drives the git binary rather than reading repositories itself. Every
+// git command runs in a goroutine and hands its result to the UI thread.
+const (
+	gitDiffMaxLines = 5000 // Changed lines beyond which the markers don't look for moved lines
+
+	tagGitAdded   = "gitadded"   // Editor tag of lines added since HEAD
+	tagGitChanged = "gitchanged" // Editor tag of lines changed since HEAD
+	tagGitDeleted = "gitdeleted" // Editor tag of lines following lines deleted since HEAD
+)
+
+// runGit runs git with args in dir, feeding it stdin, and returns its
+// output. A failure carries the message git printed.
+func runGit(dir, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+	}
+	return string(out), err
+}
+
+// gitHead returns the text of the file at path as committed in HEAD.
+func gitHead(path string) (string, error) {
+	dir, name := filepath.Split(path)
+	out, err := runGit(dir, "", "show", "HEAD:./"+name)
+	if err != nil {
+		return "", err
+	}
+	return decodedText([]byte(out)), nil
+}
+
+// -------------------------------------------------------------------------
+// Change Markers
+// -------------------------------------------------------------------------
+
+// configureGitTags sets up the tags coloring the gutter of the lines
+// changed since HEAD.
+func (i *Ite) configureGitTags() {
+	margin := gutterMargin()
+	i.editText.TagConfigure(tagGitAdded, append(margin, Lmargincolor(theme.Success))...)
+	i.editText.TagConfigure(tagGitChanged, append(margin, Lmargincolor(theme.Link))...)
+	i.editText.TagConfigure(tagGitDeleted, append(margin, Lmargincolor(theme.Error))...)
+}
+
+// refreshGitBase fetches in the background the HEAD version of path, which
+// the gutter markers of its buffer compare with. Files outside a
+// repository, or not yet committed, get no markers.
+func (i *Ite) refreshGitBase(path string) {
+	if path == "" {
+		return
+	}
+	go func() {
+		head, err := gitHead(path)
+		i.Dispatch(func() {
+			if err != nil {
+				delete(i.gitHeads, path)
+			} else {
+				i.gitHeads[path] = head
+			}
+			if p := i.paneEditing(path); p != nil {
+				i.withPane(p, i.updateGutter)
+			}
+		})
+	}()
+}
+
+// markGitChanges marks the lines of the active buffer that differ from
+// the HEAD version of its file.
+func (i *Ite) markGitChanges() {
+	for _, tag := range []string{tagGitAdded, tagGitChanged, tagGitDeleted} {
+		i.editText.TagRemove(tag, "1.0", "end")
+	}
+	head, ok := i.gitHeads[i.currentFile]
+	if !ok || i.largeFile || i.loading() {
+		return
+	}
+	for tag, lines := range gitMarks(head, i.editText.Get("1.0", "end-1c")[0]) {
+		for _, line := range lines {
+			i.editText.TagAdd(tag, fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.0", line+1))
+		}
+	}
+}
+
+// gitMarks returns the lines of text to mark, by tag, as changed from
+// base: added lines, changed lines, and the lines following deleted ones.
+func gitMarks(base, text string) map[string][]int {
+	a, b := strings.Split(base, "\n"), strings.Split(text, "\n")
+	// Only the lines between the common prefix and suffix are diffed
+	pre, suf := commonEnds(a, b)
+	a, b = a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	marks := map[string][]int{}
+	mark := func(tag string, line int) {
+		marks[tag] = append(marks[tag], min(line, pre+len(b)+suf))
+	}
+	if len(a)+len(b) > gitDiffMaxLines {
+		// Too costly to diff as the user types: the region changed as a whole
+		for n := range b {
+			mark(tagGitChanged, pre+n+1)
+		}
+		if len(b) == 0 {
+			mark(tagGitDeleted, pre+1)
+		}
+		return marks
+	}
+
+	script := lineDiff(a, b)
+	line := pre + 1
+	for n := 0; n < len(script); {
+		if script[n].op == diffEqual {
+			line++
+			n++
+			continue
+		}
+		dels, ins := 0, 0
+		for ; n < len(script) && script[n].op != diffEqual; n++ {
+			if script[n].op == diffDelete {
+				dels++
+			} else {
+				ins++
+			}
+		}
+		for k := range ins {
+			if k < dels {
+				mark(tagGitChanged, line)
+			} else {
+				mark(tagGitAdded, line)
+			}
+			line++
+		}
+		if ins == 0 {
+			mark(tagGitDeleted, line)
+		}
+	}
+	return marks
+}
+
+// -------------------------------------------------------------------------
+// Status
+// -------------------------------------------------------------------------
+
+// gitFile is a changed file of `git status`.
+type gitFile struct {
+	code string // Two-letter status: index, then working tree
+	path string // Relative to the repository root
+	orig string // Former path of a renamed file, "" otherwise
+}
+
+// gitState is the status of a repository.
+type gitState struct {
+	root   string
+	branch string
+	files  []gitFile
+	err    error
+}
+
+// gitStatus returns the status of the repository holding dir.
+func gitStatus(dir string) gitState {
+	root, err := runGit(dir, "", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return gitState{err: err}
+	}
+	st := gitState{root: filepath.FromSlash(strings.TrimSpace(root))}
+	out, err := runGit(st.root, "", "status", "--porcelain=v1", "--branch", "-z")
+	if err != nil {
+		st.err = err
+		return st
+	}
+	st.branch, st.files = parseGitStatus(out)
+	return st
+}
+
+// parseGitStatus reads the output of `git status --porcelain=v1 --branch
+// -z`: NUL-terminated entries, the former path of a rename following its
+// entry.
+func parseGitStatus(out string) (branch string, files []gitFile) {
+	entries := strings.Split(out, "\x00")
+	for n := 0; n < len(entries); n++ {
+		e := entries[n]
+		if b, ok := strings.CutPrefix(e, "## "); ok {
+			branch = b
+			continue
+		}
+		if len(e) < 4 {
+			continue
+		}
+		f := gitFile{code: e[:2], path: e[3:]}
+		if (f.code[0] == 'R' || f.code[0] == 'C') && n+1 < len(entries) {
+			n++
+			f.orig = entries[n]
+		}
+		files = append(files, f)
+	}
+	return branch, files
+}
+
+// gitPanel is the window listing the changes of the repository.
+type gitPanel struct {
+	window *ToplevelWidget
+	branch *TLabelWidget
+	list   *TextWidget
+	state  gitState
+	dir    string
+}
+
+// onGitStatus opens the Git window with the status of the repository of
+// the current file. Clicking a file opens it.
+func (i *Ite) onGitStatus() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.git != nil {
+		Destroy(i.git.window)
+	}
+	p := &gitPanel{window: Toplevel(), dir: filepath.Dir(i.currentFile)}
+	p.window.WmTitle("Git")
+
+	top := p.window.TFrame()
+	Grid(top, Row(0), Column(0), Columnspan(2), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	p.branch = top.TLabel(Txt("Reading status..."))
+	Grid(p.branch, Row(0), Column(0), Sticky(W))
+	Grid(top.TButton(Txt("Refresh"), Command(i.refreshGitPanel)), Row(0), Column(1), Padx(px(2)))
+	Grid(top.TButton(Txt("Diff"), Command(i.onGitDiff)), Row(0), Column(2), Padx(px(2)))
+	Grid(top.TButton(Txt("Commit..."), Command(i.onGitCommit)), Row(0), Column(3), Padx(px(2)))
+	GridColumnConfigure(top, 0, Weight(1))
+
+	p.list = p.window.Text(textStyle(), Width(80), Height(20), Wrap("none"), State("disabled"))
+	scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.list) }))
+	p.list.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(p.list, Row(1), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(1), Column(1), Sticky(NS))
+	GridRowConfigure(p.window, 1, Weight(1))
+	GridColumnConfigure(p.window, 0, Weight(1))
+
+	open := func(line int) {
+		if line >= 1 && line <= len(p.state.files) {
+			f := p.state.files[line-1]
+			i.showLocation(location{path: filepath.Join(p.state.root, filepath.FromSlash(f.path)), line: 1, col: 1})
+		}
+	}
+	p.list.TagConfigure(tagLink, Foreground(theme.Link))
+	p.list.TagBind(tagLink, "<Button-1>", func() {
+		line, _ := parseIndex(p.list.Index("current"))
+		open(line)
+	})
+	p.list.TagBind(tagLink, "<Enter>", func() { p.list.Configure(Cursor("hand2")) })
+	p.list.TagBind(tagLink, "<Leave>", func() { p.list.Configure(Cursor("xterm")) })
+	bindPanelKeys(p.list, open)
+
+	closeWindow := func() {
+		Destroy(p.window)
+		i.git = nil
+	}
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	i.bindFocusKeys(p.window.Window)
+	Focus(p.list)
+	i.git = p
+	i.refreshGitPanel()
+}
+
+// refreshGitPanel reads the status shown by the Git window again.
+func (i *Ite) refreshGitPanel() {
+	p := i.git
+	if p == nil {
+		return
+	}
+	go func() {
+		st := gitStatus(p.dir)
+		i.Dispatch(func() {
+			if i.git == p {
+				p.show(st)
+			}
+		})
+	}()
+}
+
+// show lists the changed files of st, one per line.
+func (p *gitPanel) show(st gitState) {
+	p.state = st
+	p.list.Configure(State("normal"))
+	defer p.list.Configure(State("disabled"))
+	p.list.Delete("1.0", "end")
+	if st.err != nil {
+		p.branch.Configure(Txt("Not a git repository"))
+		p.list.Insert("end", st.err.Error())
+		return
+	}
+	p.window.WmTitle("Git - " + st.root)
+	p.branch.Configure(Txt("Branch: " + st.branch))
+	if len(st.files) == 0 {
+		p.list.Insert("end", "Nothing to commit, working tree clean")
+		return
+	}
+	for n, f := range st.files {
+		if n > 0 {
+			p.list.Insert("end", "\n")
+		}
+		name := f.path
+		if f.orig != "" {
+			name = f.orig + " -> " + f.path
+		}
+		p.list.Insert("end", f.code+" ")
+		p.list.Insert("end", name, tagLink)
+	}
+}
+
+// -------------------------------------------------------------------------
+// Diff and Commit
+// -------------------------------------------------------------------------
+
+// onGitDiff shows the changes of the buffer, saved or not, since the HEAD
+// version of its file.
+func (i *Ite) onGitDiff() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	path, text := i.currentFile, i.editText.Text()
+	go func() {
+		head, err := gitHead(path)
+		i.Dispatch(func() {
+			if err != nil {
+				i.showError("Git Diff: " + err.Error())
+				return
+			}
+			name := filepath.Base(path)
+			showDiffWindow("Diff with HEAD - "+name, "HEAD:"+name, name, head, text)
+		})
+	}()
+}
+
+// onGitCommit opens the commit dialog for the repository of the current
+// file, after offering to save the buffer: git commits what is on disk.
+func (i *Ite) onGitCommit() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if !i.promptSaveIfModified() {
+		return
+	}
+	dir := filepath.Dir(i.currentFile)
+	go func() {
+		st := gitStatus(dir)
+		i.Dispatch(func() { i.showCommitDialog(st) })
+	}()
+}
+
+// showCommitDialog lets the user pick the changed files of st to stage and
+// write the commit message.
+func (i *Ite) showCommitDialog(st gitState) {
+	switch {
+	case st.err != nil:
+		i.showError("Git Commit: " + st.err.Error())
+		return
+	case len(st.files) == 0:
+		i.showStatusHint("Nothing to commit")
+		return
+	}
+	dialog := Toplevel()
+	dialog.WmTitle("Commit - " + st.branch)
+
+	files := dialog.TFrame()
+	Grid(files, Row(0), Column(0), Columnspan(2), Sticky(WE), Padx(px(10)), Pady(px(5)))
+	checks := make([]*TCheckbuttonWidget, len(st.files))
+	for n, f := range st.files {
+		checks[n] = files.TCheckbutton(Txt(f.code+" "+f.path), Variable(1))
+		Grid(checks[n], Row(n), Column(0), Sticky(W))
+	}
+
+	Grid(dialog.TLabel(Txt("Message:")), Row(1), Column(0), Sticky(W), Padx(px(10)))
+	msg := dialog.Text(textStyle(), Width(commitMsgColumn), Height(8), Wrap("word"))
+	Grid(msg, Row(2), Column(0), Columnspan(2), Sticky(NEWS), Padx(px(10)), Pady(px(5)))
+	GridRowConfigure(dialog, 2, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+
+	btnFrame := dialog.TFrame()
+	Grid(btnFrame, Row(3), Column(0), Columnspan(2), Pady(px(10)))
+	var commitBtn *TButtonWidget
+	commit := func() {
+		message := strings.TrimSpace(msg.Get("1.0", "end-1c")[0])
+		var picked []gitFile
+		for n, f := range st.files {
+			if checks[n].Variable() == "1" {
+				picked = append(picked, f)
+			}
+		}
+		switch {
+		case message == "":
+			i.showError("The commit message is empty")
+			return
+		case len(picked) == 0:
+			i.showError("No files selected to commit")
+			return
+		}
+		commitBtn.Configure(State("disabled"))
+		go func() {
+			out, err := gitCommit(st.root, message, picked)
+			i.Dispatch(func() {
+				if err != nil {
+					commitBtn.Configure(State("normal"))
+					i.showError("Git Commit: " + err.Error())
+					return
+				}
+				Destroy(dialog)
+				i.showStatusHint(strings.SplitN(strings.TrimSpace(out), "\n", 2)[0])
+				i.afterGitCommit()
+			})
+		}()
+	}
+	commitBtn = btnFrame.TButton(Txt("Commit"), Command(commit))
+	Grid(commitBtn, Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Cancel"), Command(func() { Destroy(dialog) })), Row(0), Column(1), Padx(px(5)))
+	Bind(dialog, "<Escape>", Command(func() { Destroy(dialog) }))
+	Bind(msg, "<Control-Return>", Command(commit))
+	Focus(msg)
+}
+
+// gitCommit stages files in the repository at root and commits them, and
+// only them, with message.
+func gitCommit(root, message string, files []gitFile) (string, error) {
+	var paths, all []string
+	for _, f := range files {
+		paths = append(paths, f.path)
+		all = append(all, f.path)
+		if f.orig != "" {
+			all = append(all, f.orig)
+		}
+	}
+	if _, err := runGit(root, "", append([]string{"add", "--all", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	return runGit(root, message, append([]string{"commit", "--file=-", "--"}, all...)...)
+}
+
+// afterGitCommit brings the Git window and the gutter markers of the open
+// files up to date with the new HEAD.
+func (i *Ite) afterGitCommit() {
+	i.refreshGitPanel()
+	i.storePane(i.active)
+	for _, p := range i.panes {
+		i.refreshGitBase(p.file)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Branches and Stash
+// -------------------------------------------------------------------------
+
+// The status bar shows the branch of the repository of the current file.
+// Its menu lists the local branches, checking one out on a click, and
+// stashes or restores the changes of the working tree. Buffers of files
+// the switch changed are reloaded when they have no unsaved edits; the
+// others are offered for reload.
+
+// gitBranches is the branch state of a repository, read in the background
+// so the menu opens without waiting for git.
+type gitBranches struct {
+	root     string
+	current  string   // "" when HEAD is detached
+	local    []string // Local branches
+	stashes  int
+	detached string // Abbreviated commit of a detached HEAD
+}
+
+// makeBranchMenu creates the status bar button of the branch menu.
+func (i *Ite) makeBranchMenu() {
+	i.statusBranch = i.statusMenu("branch")
+	i.branchMenu = i.statusBranch.Menu(Postcommand(i.fillBranchMenu))
+	i.statusBranch.Configure(Mnu(i.branchMenu))
+}
+
+// refreshBranches reads the branches of the repository of the current
+// file in the background and shows the current one.
+func (i *Ite) refreshBranches() {
+	dir := filepath.Dir(i.currentFile)
+	if i.currentFile == "" {
+		dir = i.defaultDir()
+	}
+	go func() {
+		b, err := readBranches(dir)
+		i.Dispatch(func() {
+			if err != nil {
+				i.branches = gitBranches{}
+				i.statusBranch.Configure(Txt(""))
+				return
+			}
+			i.branches = b
+			name := b.current
+			if name == "" {
+				name = "(" + b.detached + ")"
+			}
+			i.statusBranch.Configure(Txt("⎇ " + name))
+		})
+	}()
+}
+
+// readBranches returns the branch state of the repository holding dir.
+func readBranches(dir string) (gitBranches, error) {
+	root, err := runGit(dir, "", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return gitBranches{}, err
+	}
+	b := gitBranches{root: filepath.FromSlash(strings.TrimSpace(root))}
+	out, err := runGit(b.root, "", "branch", "--format=%(refname:short)")
+	if err != nil {
+		return b, err
+	}
+	b.local = strings.Fields(out)
+	current, _ := runGit(b.root, "", "branch", "--show-current")
+	b.current = strings.TrimSpace(current)
+	if b.current == "" {
+		head, _ := runGit(b.root, "", "rev-parse", "--short", "HEAD")
+		b.detached = strings.TrimSpace(head)
+	}
+	stashes, _ := runGit(b.root, "", "stash", "list")
+	b.stashes = strings.Count(stashes, "\n")
+	return b, nil
+}
+
+// fillBranchMenu lists the branches last read in the branch menu as it
+// opens.
+func (i *Ite) fillBranchMenu() {
+	m := i.branchMenu
+	tclEval("%s delete 0 end", m)
+	b := i.branches
+	if b.root == "" {
+		m.AddCommand(Lbl("Not in a Git repository"), State("disabled"))
+		return
+	}
+	for _, name := range b.local {
+		label := "  " + name
+		if name == b.current {
+			label = "● " + name
+		}
+		m.AddCommand(Lbl(label), Command(func() { i.checkoutBranch(name) }))
+	}
+	m.AddSeparator()
+	m.AddCommand(Lbl("Stash Changes"), Command(i.onGitStash))
+	popState := "normal"
+	if b.stashes == 0 {
+		popState = "disabled"
+	}
+	m.AddCommand(Lbl(fmt.Sprintf("Pop Stash (%d)", b.stashes)), Command(i.onGitStashPop), State(popState))
+	m.AddCommand(Lbl("Refresh"), Command(i.refreshBranches))
+}
+
+// confirmUnsavedForGit warns that action leaves the unsaved edits of the
+// open buffers out, reporting whether to go on.
+func (i *Ite) confirmUnsavedForGit(action string) bool {
+	var names []string
+	for _, p := range i.panes {
+		file := p.file
+		if p == i.active {
+			file = i.currentFile
+		}
+		if p.text.Modified() {
+			names = append(names, filepath.Base(orDefault(file, "Untitled")))
+		}
+	}
+	if len(names) == 0 {
+		return true
+	}
+	resp := messageBox(Icon("warning"), Title(action), Type("okcancel"),
+		Msg(strings.Join(names, ", ")+" has unsaved changes."),
+		Detail(action+" works on the files on disk and leaves the unsaved changes out. "+
+			"Buffers with unsaved changes are offered for reload afterwards."))
+	return resp == "ok"
+}
+
+// checkoutBranch switches the repository to the local branch name.
+func (i *Ite) checkoutBranch(name string) {
+	if name == i.branches.current || !i.confirmUnsavedForGit("Checkout") {
+		return
+	}
+	i.runBranchGit("Switched to "+name, "switch", name)
+}
+
+// onGitStash stashes the changes of the working tree, untracked files
+// included.
+func (i *Ite) onGitStash() {
+	if i.confirmUnsavedForGit("Stash") {
+		i.runBranchGit("Changes stashed", "stash", "push", "--include-untracked")
+	}
+}
+
+// onGitStashPop restores the last stashed changes.
+func (i *Ite) onGitStashPop() {
+	if i.confirmUnsavedForGit("Pop Stash") {
+		i.runBranchGit("Stash restored", "stash", "pop")
+	}
+}
+
+// runBranchGit runs git with args in the repository of the branch menu in
+// the background, then reports done and brings the buffers, the gutter
+// markers and the Git window up to date.
+func (i *Ite) runBranchGit(done string, args ...string) {
+	root := i.branches.root
+	if root == "" {
+		i.showError("git " + args[0] + ": not in a Git repository")
+		return
+	}
+	i.showStatusHint("git " + strings.Join(args, " ") + "...")
+	go func() {
+		_, err := runGit(root, "", args...)
+		i.Dispatch(func() {
+			if err != nil {
+				i.showError("git " + args[0] + ": " + err.Error())
+			} else {
+				i.showStatusHint(done)
+			}
+			i.reloadAfterGit()
+			i.afterGitCommit()
+			i.refreshBranches()
+		})
+	}()
+}
+
+// reloadAfterGit reloads the buffers of files git changed that have no
+// unsaved edits and offers to reload the others.
+func (i *Ite) reloadAfterGit() {
+	paths, modified := i.changedBuffers()
+	for _, path := range paths {
+		if !modified[path] {
+			i.reloadBuffer(path)
+		}
+	}
+	i.offerReloads()
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// -------------------------------------------------------------------------
+// .gitignore Matching
+// -------------------------------------------------------------------------
+
+// ignoreRule is a pattern of a .gitignore file.
+type ignoreRule struct {
+	base    string // Directory of the .gitignore file, slash separated and relative to the root
+	re      *regexp.Regexp
+	negate  bool // The pattern started with "!"
+	dirOnly bool // The pattern ended with "/"
+}
+
+// gitignore holds the rules of the .gitignore files met while walking a
+// directory tree.
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// load adds the rules of the .gitignore file in dir, given relative to the
+// root of the walk (as "." for the root itself), if there is one.
+func (g *gitignore) load(root, dir string) {
+	f, err := os.Open(filepath.Join(root, dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	base := filepath.ToSlash(dir)
+	if base == "." {
+		base = ""
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(base, scanner.Text()); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+}
+
+// parseIgnoreRule converts a .gitignore line into a rule. Blank lines and
+// comments yield none.
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // Escaped leading ! or #
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A slash anywhere but at the end anchors the pattern to base
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for n := 0; n < len(line); n++ {
+		switch c := line[n]; {
+		case strings.HasPrefix(line[n:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			n += 2
+		case strings.HasPrefix(line[n:], "**"):
+			sb.WriteString(".*")
+			n++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[n:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := line[n+1 : n+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			n += end
+		default:
+			r, size := utf8.DecodeRuneInString(line[n:])
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			n += size - 1
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.re = re
+	return rule, true
+}
+
+// ignored reports whether the file or directory at rel, a slash separated
+// path relative to the root of the walk, is excluded. As in git, the last
+// matching rule decides.
+func (g *gitignore) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		p := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			p = rel[len(r.base)+1:]
+		}
+		if r.re.MatchString(p) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
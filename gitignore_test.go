@@ -0,0 +1,58 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestGitignore(t *testing.T) {
+	g := &gitignore{}
+	for _, line := range []string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/root.txt",
+		"café.txt",
+		"docs/**/draft-?.md",
+		"[ab]*.tmp",
+		"données/",
+	} {
+		if rule, ok := parseIgnoreRule("", line); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	if rule, ok := parseIgnoreRule("sub", "*.out"); ok {
+		g.rules = append(g.rules, rule)
+	}
+	tests := []struct {
+		rel     string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"logs/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"root.txt", false, true},
+		{"src/root.txt", false, false},
+		{"café.txt", false, true},
+		{"src/café.txt", false, true},
+		{"cafe.txt", false, false},
+		{"docs/draft-1.md", false, true},
+		{"docs/a/b/draft-2.md", false, true},
+		{"docs/draft-10.md", false, false},
+		{"a1.tmp", false, true},
+		{"c1.tmp", false, false},
+		{"données", true, true},
+		{"sub/x.out", false, true},
+		{"x.out", false, false},
+	}
+	for _, tt := range tests {
+		if got := g.ignored(tt.rel, tt.isDir); got != tt.ignored {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.ignored)
+		}
+	}
+}
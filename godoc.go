@@ -0,0 +1,235 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Documentation
+// -------------------------------------------------------------------------
+
+const (
+	tagDocCode    = "doccode"    // Documentation tag of declarations and code blocks
+	tagDocHeading = "docheading" // Documentation tag of the headings of doc comments
+)
+
+// docPanel is the window showing the documentation of a symbol.
+type docPanel struct {
+	window *ToplevelWidget
+	view   *TextWidget
+}
+
+// onShowDocumentation shows the documentation of the identifier at the
+// cursor: the hover text of gopls when it is installed, otherwise the
+// output of go doc for the qualified name there, e.g. strings.Cut.
+func (i *Ite) onShowDocumentation() {
+	line, col := parseIndex(i.editText.Index("insert"))
+	text := lineText(i.editText, line)
+	name := qualifiedNameAt(text, col, i.isWordChar)
+	if name == "" {
+		i.showStatusHint("No identifier at the cursor")
+		return
+	}
+	dir := "."
+	if i.currentFile != "" {
+		dir = filepath.Dir(i.currentFile)
+	}
+	gopls, err := exec.LookPath("gopls")
+	useGopls := err == nil && filepath.Ext(i.currentFile) == defaultFileExtension
+	var pos string
+	if useGopls {
+		// gopls reads the file from disk
+		if i.editText.Modified() {
+			i.onSave()
+		}
+		runes := []rune(text)
+		pos = fmt.Sprintf("%s:%d:%d", i.currentFile, line, len(string(runes[:min(col, len(runes))]))+1)
+	}
+
+	go func() {
+		var doc string
+		var err error
+		if useGopls {
+			doc, err = goplsHover(gopls, dir, pos)
+		}
+		if doc == "" {
+			doc, err = goDoc(dir, name)
+		}
+		i.Dispatch(func() { i.showDocumentation(name, doc, err) })
+	}()
+}
+
+// qualifiedNameAt returns the identifier at or right before the rune
+// column col of line, with the names it is selected from, as in
+// "http.DefaultClient.Do".
+func qualifiedNameAt(line string, col int, isWord func(rune) bool) string {
+	runes := []rune(line)
+	col = min(col, len(runes))
+	inName := func(r rune) bool { return r == '.' || isWord(r) }
+	start, end := col, col
+	for start > 0 && inName(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWord(runes[end]) {
+		end++
+	}
+	return strings.Trim(string(runes[start:end]), ".")
+}
+
+// goplsHover returns the hover text of gopls for the identifier at pos,
+// a file:line:column position.
+func goplsHover(gopls, dir, pos string) (string, error) {
+	cmd := exec.Command(gopls, "definition", "-json", pos)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	var def struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(output, &def); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(def.Description), nil
+}
+
+// goDoc returns the output of go doc for name, run in dir.
+func goDoc(dir, name string) (string, error) {
+	cmd := exec.Command("go", "doc", name)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if err != nil {
+		if text != "" {
+			err = errors.New(text)
+		}
+		return "", err
+	}
+	return text, nil
+}
+
+// showDocumentation shows doc, the documentation of name, in the
+// documentation window, opening it next to the cursor.
+func (i *Ite) showDocumentation(name, doc string, err error) {
+	if err != nil {
+		i.showError("Show Documentation: " + err.Error())
+		return
+	}
+	if doc == "" {
+		i.showStatusHint("No documentation for " + name)
+		return
+	}
+	p := i.docs
+	if p == nil {
+		p = &docPanel{window: Toplevel()}
+		WmTransient(p.window, App)
+		p.view = p.window.Text(textStyle(), Width(80), Height(20), Wrap("word"))
+		scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.view) }))
+		p.view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+		Grid(p.view, Row(0), Column(0), Sticky(NEWS))
+		Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+		GridRowConfigure(p.window, 0, Weight(1))
+		GridColumnConfigure(p.window, 0, Weight(1))
+		p.view.TagConfigure(tagDocCode, Background(theme.CurrentLine))
+		p.view.TagConfigure(tagDocHeading, Font(editorFontFamily, fontSize, "bold"))
+
+		closeWindow := func() {
+			Destroy(p.window)
+			i.docs = nil
+			Focus(i.editText)
+		}
+		Bind(p.window, "<Escape>", Command(closeWindow))
+		WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+		if bbox := strings.Fields(tclEval("%s bbox insert", i.editText)); len(bbox) >= 4 {
+			x := winfoInt(tclEval("winfo rootx %s", i.editText)) + winfoInt(bbox[0])
+			y := winfoInt(tclEval("winfo rooty %s", i.editText)) + winfoInt(bbox[1]) + winfoInt(bbox[3])
+			WmGeometry(p.window.Window, fmt.Sprintf("+%d+%d", x, y))
+		}
+		i.docs = p
+	} else {
+		WmDeiconify(p.window.Window)
+		tclEval("raise %s", p.window)
+	}
+	p.window.WmTitle("Documentation: " + name)
+
+	p.view.Configure(State("normal"))
+	p.view.Delete("1.0", "end")
+	for n, line := range docLines(doc) {
+		if n > 0 {
+			p.view.Insert("end", "\n")
+		}
+		if line.tag == "" {
+			p.view.Insert("end", line.text)
+		} else {
+			p.view.Insert("end", line.text, line.tag)
+		}
+	}
+	p.view.Configure(State("disabled"))
+	Focus(p.view)
+}
+
+// docLine is a line of documentation with the tag formatting it, "" for
+// plain text.
+type docLine struct {
+	text, tag string
+}
+
+// docLines splits documentation into lines and formats them: the
+// declaration opening it and the declarations listed by go doc, the code
+// blocks, indented deeper than the text, and the headings.
+func docLines(doc string) []docLine {
+	lines := strings.Split(doc, "\n")
+	var out []docLine
+	textIndent := -1 // Indentation of the text after the first paragraph
+	declaration := true
+	inDecl := false // Inside the brackets of a declaration spanning lines
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := len(line) - len(trimmed)
+		tag := ""
+		switch {
+		case inDecl:
+			tag = tagDocCode
+			inDecl = indent > 0 || (trimmed != "}" && trimmed != ")")
+		case trimmed == "":
+			declaration = false
+		case declaration || (indent == 0 && isDeclLine(trimmed)):
+			tag = tagDocCode
+			inDecl = indent == 0 && (strings.HasSuffix(trimmed, "{") || strings.HasSuffix(trimmed, "("))
+		default:
+			if textIndent < 0 {
+				textIndent = indent
+			}
+			switch {
+			case indent > textIndent:
+				tag = tagDocCode
+			case strings.HasPrefix(trimmed, "# "):
+				tag = tagDocHeading
+				line = line[:indent] + strings.TrimPrefix(trimmed, "# ")
+			}
+		}
+		out = append(out, docLine{line, tag})
+	}
+	return out
+}
+
+// isDeclLine reports whether line starts a Go declaration.
+func isDeclLine(line string) bool {
+	for _, kw := range []string{"package ", "func ", "type ", "var ", "const ", "field ", "method "} {
+		if strings.HasPrefix(line, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Go Mod Commands
+// -------------------------------------------------------------------------
+
+// makeGoModMenu creates the toolbar menu button of the module commands.
+func (i *Ite) makeGoModMenu(parent *TFrameWidget) *TMenubuttonWidget {
+	button := parent.TMenubutton(Txt("Go Mod"))
+	menu := button.Menu()
+	menu.AddCommand(Lbl("go mod tidy"), Command(i.onGoModTidy))
+	menu.AddCommand(Lbl("go mod init..."), Command(i.onGoModInit))
+	menu.AddCommand(Lbl("go get..."), Command(i.onGoGet))
+	menu.AddCommand(Lbl("go mod vendor"), Command(i.onGoModVendor))
+	button.Configure(Mnu(menu))
+	return button
+}
+
+// runGoMod runs a go command changing the module files in the Build
+// console, from the root of the project, or from the working directory
+// when no file is open.
+func (i *Ite) runGoMod(args ...string) {
+	if i.currentFile != "" && i.editText.Modified() {
+		i.onSave()
+	}
+	i.runCommand(i.console(consoleBuild), args, "Running go "+strings.Join(args, " ")+"...\n", plainOutput{})
+}
+
+// onGoModTidy runs `go mod tidy`.
+func (i *Ite) onGoModTidy() { i.runGoMod("mod", "tidy") }
+
+// onGoModVendor runs `go mod vendor`.
+func (i *Ite) onGoModVendor() { i.runGoMod("mod", "vendor") }
+
+// onGoModInit asks for a module path and runs `go mod init` with it. The
+// path proposed is the name of the directory.
+func (i *Ite) onGoModInit() {
+	dir := "."
+	if i.currentFile != "" {
+		dir = filepath.Dir(i.currentFile)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		i.showError("go mod init: " + i.displayPath(filepath.Join(dir, "go.mod")) + " already exists")
+		return
+	}
+	i.promptString("Go Mod Init", "Module path:", filepath.Base(dir), func(path string) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return
+		}
+		i.runGoMod("mod", "init", path)
+	})
+}
+
+// onGoGet asks for one or more packages, each optionally followed by
+// @version, and adds them to the module with `go get`.
+func (i *Ite) onGoGet() {
+	i.promptString("Go Get", "Packages (path[@version], separated by spaces):", "", func(value string) {
+		pkgs := strings.Fields(value)
+		if len(pkgs) == 0 {
+			return
+		}
+		i.runGoMod(append([]string{"get"}, pkgs...)...)
+	})
+}
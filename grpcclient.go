@@ -0,0 +1,261 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// gRPC Client Panel
+// -------------------------------------------------------------------------
+
+// The gRPC client panel calls a method of a running server with a JSON
+// request and shows the response, through grpcurl, found in the PATH.
+// The methods are listed by server reflection, or from the .proto files
+// given, relative to the project root.
+const (
+	grpcDefaultAddress = "localhost:50051"
+	grpcReflection     = "grpc.reflection." // Prefix of the reflection services, left out of the list
+)
+
+// grpcPanel holds the widgets of the gRPC client window.
+type grpcPanel struct {
+	window    *ToplevelWidget
+	address   *TEntryWidget
+	protos    *TEntryWidget // Proto files, separated by spaces, "" to use reflection
+	plaintext *VariableOpt  // Connect without TLS
+	method    *TComboboxWidget
+	metadata  *TextWidget // One "Name: value" per line
+	body      *TextWidget
+	response  *TextWidget
+}
+
+// grpcTarget is the server called and how its methods are described.
+type grpcTarget struct {
+	address   string
+	root      string   // Directory the proto files are relative to
+	protos    []string // Empty to use reflection
+	plaintext bool
+}
+
+// grpcurlArgs returns the grpcurl options reaching t, the metadata
+// headers added, before the address.
+func grpcurlArgs(t grpcTarget, metadata []string) []string {
+	var args []string
+	if t.plaintext {
+		args = append(args, "-plaintext")
+	}
+	if len(t.protos) > 0 {
+		args = append(args, "-import-path", t.root)
+		for _, proto := range t.protos {
+			args = append(args, "-proto", proto)
+		}
+	}
+	for _, m := range metadata {
+		args = append(args, "-H", m)
+	}
+	return append(args, t.address)
+}
+
+// parseGRPCList returns the names grpcurl list printed, one per line,
+// without the reflection services.
+func parseGRPCList(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if name := strings.TrimSpace(line); name != "" && !strings.HasPrefix(name, grpcReflection) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// grpcurl runs grpcurl with args from dir, input as standard input,
+// returning its output or the errors it printed.
+func grpcurl(dir, input string, args ...string) (string, error) {
+	cmd := exec.Command("grpcurl", args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return string(out), errors.New(msg)
+		}
+		return string(out), fmt.Errorf("grpcurl: %v", err)
+	}
+	return string(out), nil
+}
+
+// grpcMethods returns the methods of the services of t, as
+// package.Service/Method.
+func grpcMethods(t grpcTarget) ([]string, error) {
+	out, err := grpcurl(t.root, "", append(grpcurlArgs(t, nil), "list")...)
+	if err != nil {
+		return nil, err
+	}
+	var methods []string
+	for _, service := range parseGRPCList(out) {
+		out, err := grpcurl(t.root, "", append(grpcurlArgs(t, nil), "list", service)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range parseGRPCList(out) {
+			// grpcurl prints package.Service.Method
+			if n := strings.LastIndexByte(m, '.'); n >= 0 {
+				m = m[:n] + "/" + m[n+1:]
+			}
+			methods = append(methods, m)
+		}
+	}
+	slices.Sort(methods)
+	return methods, nil
+}
+
+// onGRPCClient opens the gRPC client panel, or raises it if already open.
+func (i *Ite) onGRPCClient() {
+	if i.grpc != nil {
+		WmDeiconify(i.grpc.window.Window)
+		tclEval("raise %s", i.grpc.window)
+		Focus(i.grpc.address)
+		return
+	}
+	p := &grpcPanel{window: Toplevel()}
+	p.window.WmTitle("gRPC Client")
+
+	top := p.window.TFrame()
+	Grid(top, Row(0), Column(0), Columnspan(2), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	p.address = top.TEntry(Width(24), Textvariable(grpcDefaultAddress))
+	p.plaintext = Variable(1)
+	p.protos = top.TEntry(Width(30), Textvariable(""))
+	Grid(top.TLabel(Txt("Address")), Row(0), Column(0), Padx(px(2)))
+	Grid(p.address, Row(0), Column(1), Sticky(WE), Padx(px(2)))
+	Grid(top.TCheckbutton(Txt("Plaintext"), p.plaintext), Row(0), Column(2), Padx(px(2)))
+	Grid(top.TLabel(Txt("Proto files")), Row(0), Column(3), Padx(px(2)))
+	Grid(p.protos, Row(0), Column(4), Sticky(WE), Padx(px(2)))
+	Grid(top.TButton(Txt("List Methods"), Command(i.listGRPCMethods)), Row(0), Column(5), Padx(px(2)))
+	p.method = top.TCombobox(Width(40), Textvariable(""))
+	Grid(top.TLabel(Txt("Method")), Row(1), Column(0), Padx(px(2)), Pady(px(3)))
+	Grid(p.method, Row(1), Column(1), Columnspan(4), Sticky(WE), Padx(px(2)), Pady(px(3)))
+	Grid(top.TButton(Txt("Send"), Command(i.sendGRPCRequest)), Row(1), Column(5), Padx(px(2)), Pady(px(3)))
+	GridColumnConfigure(top, 1, Weight(1))
+	GridColumnConfigure(top, 4, Weight(1))
+
+	Grid(p.window.TLabel(Txt("Metadata")), Row(1), Column(0), Sticky(W), Padx(px(5)))
+	p.metadata = p.window.Text(textStyle(), Width(60), Height(5))
+	Grid(p.metadata, Row(2), Column(0), Sticky(NEWS), Padx(px(5)))
+	Grid(p.window.TLabel(Txt("Request (JSON)")), Row(3), Column(0), Sticky(W), Padx(px(5)))
+	p.body = p.window.Text(textStyle(), Width(60), Height(15))
+	p.body.Insert("1.0", "{}")
+	Grid(p.body, Row(4), Column(0), Sticky(NEWS), Padx(px(5)), Pady(px(5)))
+
+	Grid(p.window.TLabel(Txt("Response")), Row(1), Column(1), Sticky(W), Padx(px(5)))
+	p.response = p.window.Text(textStyle(), Width(80))
+	p.response.Configure(State("disabled"))
+	Grid(p.response, Row(2), Column(1), Rowspan(3), Sticky(NEWS), Padx(px(5)), Pady(px(5)))
+
+	GridColumnConfigure(p.window, 0, Weight(1))
+	GridColumnConfigure(p.window, 1, Weight(2))
+	GridRowConfigure(p.window, 4, Weight(1))
+
+	Bind(p.address, "<Return>", Command(i.listGRPCMethods))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", func() {
+		Destroy(p.window)
+		i.grpc = nil
+	})
+	Focus(p.address)
+	i.grpc = p
+}
+
+// grpcTarget returns the server of the panel.
+func (i *Ite) grpcTarget() grpcTarget {
+	p := i.grpc
+	return grpcTarget{
+		address:   strings.TrimSpace(p.address.Textvariable()),
+		root:      i.projectDir(),
+		protos:    strings.Fields(p.protos.Textvariable()),
+		plaintext: p.plaintext.Get() == "1",
+	}
+}
+
+// listGRPCMethods fills the method list of the panel in the background.
+func (i *Ite) listGRPCMethods() {
+	t := i.grpcTarget()
+	i.showGRPCResult("Listing the methods of " + t.address + " ...\n")
+	go func() {
+		methods, err := grpcMethods(t)
+		i.Dispatch(func() {
+			if i.grpc == nil {
+				return
+			}
+			if err != nil {
+				i.showGRPCResult("Error: " + err.Error())
+				return
+			}
+			i.grpc.method.Configure(Values(methods))
+			if len(methods) > 0 && i.grpc.method.Textvariable() == "" {
+				i.grpc.method.Configure(Textvariable(methods[0]))
+			}
+			i.showGRPCResult(fmt.Sprintf("%d methods", len(methods)))
+		})
+	}()
+}
+
+// sendGRPCRequest calls the method of the panel with its request in the
+// background.
+func (i *Ite) sendGRPCRequest() {
+	p := i.grpc
+	method := strings.TrimSpace(p.method.Textvariable())
+	if method == "" {
+		i.showGRPCResult("Error: choose a method, after List Methods")
+		return
+	}
+	header, err := parseHeaders(p.metadata.Get("1.0", "end-1c")[0])
+	if err != nil {
+		i.showGRPCResult("Error: " + err.Error())
+		return
+	}
+	var metadata []string
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			metadata = append(metadata, name+": "+value)
+		}
+	}
+	t := i.grpcTarget()
+	body := p.body.Get("1.0", "end-1c")[0]
+	args := append([]string{"-d", "@"}, grpcurlArgs(t, metadata)...)
+	args = append(args, method)
+	i.showGRPCResult(method + " ...\n")
+	go func() {
+		start := time.Now()
+		out, err := grpcurl(t.root, body, args...)
+		text := fmt.Sprintf("%s (%v)\n\n%s", method, time.Since(start).Round(time.Millisecond), out)
+		if err != nil {
+			text += "Error: " + err.Error()
+		}
+		i.Dispatch(func() { i.showGRPCResult(text) })
+	}()
+}
+
+// showGRPCResult replaces the response view of the panel, if still open.
+func (i *Ite) showGRPCResult(text string) {
+	if i.grpc == nil {
+		return
+	}
+	r := i.grpc.response
+	r.Configure(State("normal"))
+	r.Delete("1.0", "end")
+	r.Insert("1.0", text)
+	r.Configure(State("disabled"))
+}
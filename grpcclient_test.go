@@ -0,0 +1,29 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGRPCurlArgs(t *testing.T) {
+	target := grpcTarget{address: "localhost:50051", root: "/src/shop", plaintext: true}
+	want := []string{"-plaintext", "-H", "authorization: Bearer x", "localhost:50051"}
+	if got := grpcurlArgs(target, []string{"authorization: Bearer x"}); !slices.Equal(got, want) {
+		t.Errorf("grpcurlArgs = %q, want %q", got, want)
+	}
+	target = grpcTarget{address: "api:443", root: "/src/shop", protos: []string{"api/shop.proto", "api/user.proto"}}
+	want = []string{"-import-path", "/src/shop", "-proto", "api/shop.proto", "-proto", "api/user.proto", "api:443"}
+	if got := grpcurlArgs(target, nil); !slices.Equal(got, want) {
+		t.Errorf("grpcurlArgs with protos = %q, want %q", got, want)
+	}
+}
+
+func TestParseGRPCList(t *testing.T) {
+	out := "grpc.reflection.v1alpha.ServerReflection\nshop.v1.Orders\n\nshop.v1.Users\n"
+	if got, want := parseGRPCList(out), []string{"shop.v1.Orders", "shop.v1.Users"}; !slices.Equal(got, want) {
+		t.Errorf("parseGRPCList = %q, want %q", got, want)
+	}
+}
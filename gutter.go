@@ -0,0 +1,67 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Gutter
+// -------------------------------------------------------------------------
+
+// The gutter is a narrow left margin of the editor whose color marks
+// lines: changed since HEAD, bookmarked, or starting a region that can be
+// folded. Every line gets the margin,
+// so that the markers don't shift the text; large files have none.
+const (
+	gutterDelay = 300 // Milliseconds after the last keystroke before the markers update
+	gutterWidth = 5   // Pixels of the margin
+
+	tagGutter = "gutter" // Editor tag giving the lines their margin
+)
+
+// gutterMargin returns the margin options of the gutter tags.
+func gutterMargin() Opts {
+	return Opts{Lmargin1(px(gutterWidth)), Lmargin2(px(gutterWidth))}
+}
+
+// scheduleGutter updates the gutter markers once typing pauses.
+func (i *Ite) scheduleGutter() {
+	if i.gutterTimer != "" {
+		TclAfterCancel(i.gutterTimer)
+	}
+	i.gutterTimer = TclAfter(gutterDelay, func() {
+		i.gutterTimer = ""
+		i.updateGutter()
+	})
+}
+
+// updateGutter lays out the gutter of the active buffer and its markers,
+// and draws the color swatches, the whitespace, the misspellings and the
+// indentation guides.
+func (i *Ite) updateGutter() {
+	i.editText.TagRemove(tagGutter, "1.0", "end")
+	if !i.largeFile && !i.loading() {
+		i.editText.TagAdd(tagGutter, "1.0", "end")
+	}
+	i.markGitChanges()
+	i.markBookmarks()
+	i.markColors()
+	i.markWhitespace()
+	i.markSpelling()
+	i.markConflicts()
+	i.scheduleIndentGuides()
+	i.scheduleVirtualText()
+}
+
+// configureGutterTags sets up the gutter and its markers, later tags
+// drawing over earlier ones: a folded region always shows.
+func (i *Ite) configureGutterTags() {
+	i.editText.TagConfigure(tagGutter, gutterMargin()...)
+	i.editText.TagConfigure(tagFoldable, append(gutterMargin(), Lmargincolor(theme.Guide))...)
+	i.configureGitTags()
+	i.configureBookmarkTag()
+	i.editText.TagConfigure(tagFolded, append(gutterMargin(), Lmargincolor(theme.Link), Underline(1))...)
+}
@@ -0,0 +1,325 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// UI Test Harness
+// -------------------------------------------------------------------------
+
+// The UI tests drive a real editor window. Tk runs on the main goroutine,
+// which TestMain keeps for itself, serving the calls of the tests and the
+// Tk events in between; the tests run on another goroutine and reach the
+// editor through the harness only. They type with synthetic key events,
+// answer the message boxes from a script and check the buffer and the
+// consoles. The settings live in a temporary directory, so the tests never
+// see the user's config.
+//
+// The tests need a display: on X11 systems DISPLAY, or XVFB_DISPLAY naming
+// a virtual one, as in
+//
+//	Xvfb :99 & XVFB_DISPLAY=:99 go test
+//
+// Without a display they are skipped.
+
+// uiTimeout is how long waitFor waits for the editor to catch up.
+const uiTimeout = 30 * time.Second
+
+var (
+	testIte   *Ite        // Editor shared by the UI tests
+	uiCalls   chan func() // Calls to run on the Tk goroutine
+	noDisplay string      // Why the UI tests are skipped, if they are
+)
+
+func init() {
+	// Tk belongs to the thread that initializes it
+	runtime.LockOSThread()
+}
+
+func TestMain(m *testing.M) {
+	if !haveDisplay() {
+		noDisplay = "no display; set DISPLAY or XVFB_DISPLAY to run the UI tests"
+		os.Exit(m.Run())
+	}
+	dir, err := isolateConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ite: %v\n", err)
+		os.Exit(1)
+	}
+	if err := InitializeExtension("eval"); err != nil {
+		fmt.Fprintf(os.Stderr, "ite: %v\n", err)
+		os.Exit(1)
+	}
+	if Error != nil {
+		fmt.Fprintf(os.Stderr, "ite: %v\n", Error)
+		os.Exit(1)
+	}
+	testIte = NewIte()
+	WmDeiconify(App)
+	tclEval("update")
+	testIte.onWindowShown()
+
+	uiCalls = make(chan func())
+	result := make(chan int)
+	go func() { result <- m.Run() }()
+	for {
+		select {
+		case f := <-uiCalls:
+			f()
+		case code := <-result:
+			Finalize()
+			os.RemoveAll(dir)
+			os.Exit(code)
+		case <-time.After(10 * time.Millisecond):
+		}
+		tclEval("update")
+	}
+}
+
+// haveDisplay reports whether Tk has a display to open its windows on,
+// taking XVFB_DISPLAY for DISPLAY on X11 systems.
+func haveDisplay() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return true
+	}
+	if os.Getenv("DISPLAY") != "" {
+		return true
+	}
+	if d := os.Getenv("XVFB_DISPLAY"); d != "" {
+		os.Setenv("DISPLAY", d)
+		return true
+	}
+	return false
+}
+
+// isolateConfig points the config directory to a new temporary directory,
+// returned, and writes the default settings there, so that the setup
+// wizard doesn't run.
+func isolateConfig() (string, error) {
+	dir, err := os.MkdirTemp("", "ite-test-")
+	if err != nil {
+		return "", err
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	os.Setenv("AppData", filepath.Join(dir, "config"))
+	if runtime.GOOS == "darwin" {
+		os.Setenv("HOME", dir)
+	}
+	if err := defaultConfig().save(); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// -------------------------------------------------------------------------
+// Harness
+// -------------------------------------------------------------------------
+
+// harness drives the editor for a test.
+type harness struct {
+	t       *testing.T
+	i       *Ite
+	answers []string // Buttons chosen in the next message boxes
+	asked   int      // Message boxes shown
+}
+
+// newHarness returns the harness of test t, with an empty, unmodified
+// buffer and the editor focused. It skips the test without a display.
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	if noDisplay != "" {
+		t.Skip(noDisplay)
+	}
+	h := &harness{t: t, i: testIte}
+	h.do(func() {
+		messageBox = h.messageBox
+		h.i.endSearch(true)
+		h.i.editText.SetModified(false)
+		h.i.onNew()
+		tclEval("focus -force %s", h.i.editText)
+	})
+	t.Cleanup(func() {
+		h.do(func() { messageBox = MessageBox })
+		if len(h.answers) > 0 {
+			t.Errorf("%d message box answers left unused: %q", len(h.answers), h.answers)
+		}
+	})
+	return h
+}
+
+// do runs f on the Tk goroutine and processes the pending events.
+func (h *harness) do(f func()) {
+	done := make(chan struct{})
+	uiCalls <- func() {
+		defer close(done)
+		f()
+		tclEval("update")
+	}
+	<-done
+}
+
+// answer scripts the buttons chosen in the next message boxes, such as
+// "yes", "no" or "cancel".
+func (h *harness) answer(buttons ...string) {
+	h.answers = append(h.answers, buttons...)
+}
+
+// messageBox stands for the real message box, taking the next scripted
+// answer. Without one it cancels.
+func (h *harness) messageBox(...Opt) string {
+	h.asked++
+	if len(h.answers) == 0 {
+		h.t.Errorf("unexpected message box")
+		return "cancel"
+	}
+	answer := h.answers[0]
+	h.answers = h.answers[1:]
+	return answer
+}
+
+// keysyms names the keys of the characters typed by typeText other than
+// letters and digits.
+var keysyms = map[rune]string{
+	'\n': "Return", '\t': "Tab", ' ': "space", '!': "exclam", '"': "quotedbl",
+	'#': "numbersign", '$': "dollar", '%': "percent", '&': "ampersand",
+	'\'': "apostrophe", '(': "parenleft", ')': "parenright", '*': "asterisk",
+	'+': "plus", ',': "comma", '-': "minus", '.': "period", '/': "slash",
+	':': "colon", ';': "semicolon", '<': "less", '=': "equal", '>': "greater",
+	'?': "question", '@': "at", '[': "bracketleft", '\\': "backslash",
+	']': "bracketright", '^': "asciicircum", '_': "underscore", '`': "grave",
+	'{': "braceleft", '|': "bar", '}': "braceright", '~': "asciitilde",
+}
+
+// typeText types s, key by key, in the window with the focus.
+func (h *harness) typeText(s string) {
+	h.t.Helper()
+	for _, r := range s {
+		keysym, ok := keysyms[r]
+		if !ok {
+			if r > 0x7f || !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				h.t.Fatalf("typeText: no key for %q", r)
+			}
+			keysym = string(r)
+		}
+		h.press(keysym)
+	}
+}
+
+// press presses and releases a key given as a Tk event pattern without
+// the brackets, such as "Escape" or "Control-Return", in the window with
+// the focus.
+func (h *harness) press(key string) {
+	h.t.Helper()
+	var focus string
+	h.do(func() {
+		focus = tclEval("focus")
+		if focus == "" {
+			return
+		}
+		mods, keysym := "", key
+		if n := strings.LastIndex(key, "-"); n > 0 {
+			mods, keysym = key[:n+1], key[n+1:]
+		}
+		tclEval("event generate %s <%sKeyPress> -keysym %s", focus, mods, keysym)
+		tclEval("event generate %s <%sKeyRelease> -keysym %s", focus, mods, keysym)
+	})
+	if focus == "" {
+		h.t.Fatalf("pressing %s: no window has the focus", key)
+	}
+}
+
+// run runs the command id of the palette.
+func (h *harness) run(id string) {
+	h.t.Helper()
+	var found bool
+	h.do(func() {
+		if c := h.i.commands.lookup(id); c != nil {
+			found = true
+			c.run()
+		}
+	})
+	if !found {
+		h.t.Fatalf("no command %q", id)
+	}
+}
+
+// setText replaces the buffer with text, left unmodified, and puts the
+// cursor at its start.
+func (h *harness) setText(text string) {
+	h.do(func() {
+		h.i.editText.Delete("1.0", "end")
+		h.i.editText.Insert("1.0", text)
+		h.i.editText.MarkSet("insert", "1.0")
+		h.i.editText.SetModified(false)
+		h.i.refreshCursorState()
+	})
+}
+
+// text returns the text of the buffer.
+func (h *harness) text() string {
+	var text string
+	h.do(func() { text = h.i.editText.Get("1.0", "end-1c")[0] })
+	return text
+}
+
+// cursor returns the index of the insertion cursor.
+func (h *harness) cursor() string {
+	var index string
+	h.do(func() { index = h.i.editText.Index("insert") })
+	return index
+}
+
+// consoleText returns the text of the console called name. Unlike the
+// other accessors it must run on the Tk goroutine, as in the conditions of
+// waitFor.
+func (h *harness) consoleText(name string) string {
+	return h.i.console(name).text.Get("1.0", "end-1c")[0]
+}
+
+// wantText fails the test unless the buffer holds want.
+func (h *harness) wantText(want string) {
+	h.t.Helper()
+	if got := h.text(); got != want {
+		h.t.Fatalf("buffer is %q, want %q", got, want)
+	}
+}
+
+// wantCursor fails the test unless the insertion cursor is at index want.
+func (h *harness) wantCursor(want string) {
+	h.t.Helper()
+	if got := h.cursor(); got != want {
+		h.t.Fatalf("cursor at %s, want %s", got, want)
+	}
+}
+
+// waitFor waits for cond, checked on the Tk goroutine, to hold, failing the
+// test after uiTimeout. what describes cond in the failure.
+func (h *harness) waitFor(what string, cond func() bool) {
+	h.t.Helper()
+	deadline := time.Now().Add(uiTimeout)
+	for {
+		var ok bool
+		h.do(func() { ok = cond() })
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Health Checks
+// -------------------------------------------------------------------------
+
+// Once the user stops typing for healthDelay, cheap checks of the buffer
+// run in the background: parse errors, TODO comments and whether the
+// coverage marks still match the file. A badge in the status bar sums them
+// up; clicking it goes to the first parse error, else to the first TODO.
+// Every keystroke postpones the checks, and results of a buffer that has
+// changed since they started are dropped.
+const (
+	healthDelay = 2 * time.Second // Pause in typing before the checks run
+	healthOK    = "✓"
+)
+
+// todoRe matches the markers of the TODO comments counted by the checks.
+var todoRe = regexp.MustCompile(`//.*\b(TODO|FIXME|XXX)\b|/\*.*\b(TODO|FIXME|XXX)\b`)
+
+// healthReport is the result of the checks of a buffer.
+type healthReport struct {
+	parseErrors   int
+	firstError    int // Line of the first parse error, 0 if none
+	todos         int
+	firstTodo     int  // Line of the first TODO, 0 if none
+	coverageStale bool // The file changed since the coverage run that marked it
+}
+
+// summary returns the text of the badge of r.
+func (r healthReport) summary() string {
+	var parts []string
+	switch {
+	case r.parseErrors == 1:
+		parts = append(parts, "1 parse error")
+	case r.parseErrors > 1:
+		parts = append(parts, fmt.Sprintf("%d parse errors", r.parseErrors))
+	}
+	if r.todos > 0 {
+		parts = append(parts, fmt.Sprintf("%d TODO", r.todos))
+	}
+	if r.coverageStale {
+		parts = append(parts, "coverage stale")
+	}
+	if len(parts) == 0 {
+		return healthOK
+	}
+	return "⚠ " + strings.Join(parts, " · ")
+}
+
+// scheduleHealthCheck runs the checks once the user pauses typing.
+func (i *Ite) scheduleHealthCheck() {
+	if i.healthTimer != "" {
+		TclAfterCancel(i.healthTimer)
+	}
+	i.healthSeq++ // Results of a running check are stale
+	i.healthTimer = TclAfter(healthDelay, func() {
+		i.healthTimer = ""
+		i.runHealthCheck()
+	})
+}
+
+// runHealthCheck checks the buffer in the background and shows the
+// report in the status bar.
+func (i *Ite) runHealthCheck() {
+	if i.largeFile {
+		i.statusHealth.Configure(Txt(""))
+		return
+	}
+	seq := i.healthSeq
+	path, src := i.currentFile, i.editText.Text()
+	var coveredAt time.Time
+	if i.coverMarks != nil && len(i.editText.TagRanges(tagCovered))+len(i.editText.TagRanges(tagUncovered)) > 0 {
+		coveredAt = i.coverMarks.time
+	}
+	modified := i.editText.Modified()
+	goSource := path == "" || filepath.Ext(path) == defaultFileExtension
+	go func() {
+		r := checkHealth(path, src, goSource)
+		if !coveredAt.IsZero() {
+			if info, err := os.Stat(path); modified || err != nil || info.ModTime().After(coveredAt) {
+				r.coverageStale = true
+			}
+		}
+		i.Dispatch(func() {
+			if seq != i.healthSeq {
+				return
+			}
+			i.health = r
+			i.showHealth()
+		})
+	}()
+}
+
+// checkHealth returns the report of src, the text of path, parsed when
+// goSource is set.
+func checkHealth(path, src string, goSource bool) healthReport {
+	var r healthReport
+	if goSource && strings.TrimSpace(src) != "" {
+		_, err := parser.ParseFile(token.NewFileSet(), path, src, parser.AllErrors|parser.SkipObjectResolution)
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			r.parseErrors, r.firstError = len(list), list[0].Pos.Line
+		}
+	}
+	for n, line := range strings.Split(src, "\n") {
+		if todoRe.MatchString(line) {
+			if r.todos == 0 {
+				r.firstTodo = n + 1
+			}
+			r.todos++
+		}
+	}
+	return r
+}
+
+// showHealth shows the last report in the status bar.
+func (i *Ite) showHealth() {
+	i.statusHealth.Configure(Txt(i.health.summary()), Foreground(i.healthColor()))
+}
+
+// healthColor returns the color of the badge of the last report.
+func (i *Ite) healthColor() string {
+	switch {
+	case i.health.parseErrors > 0:
+		return theme.Error
+	case i.health.todos > 0 || i.health.coverageStale:
+		return theme.Warning
+	}
+	return theme.Success
+}
+
+// onHealthClick goes to the first parse error, or to the first TODO.
+func (i *Ite) onHealthClick() {
+	switch {
+	case i.health.firstError > 0:
+		i.jumpTo(i.health.firstError, 0)
+	case i.health.firstTodo > 0:
+		i.jumpTo(i.health.firstTodo, 0)
+	case i.health.coverageStale:
+		i.showStatusHint("The file changed since the coverage run: run Go Coverage again")
+	}
+}
@@ -0,0 +1,509 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Signature Help and Hover
+// -------------------------------------------------------------------------
+
+// While the cursor is inside the parentheses of a call, a tooltip shows
+// the signature of the function called, with the parameter being typed in
+// bold, and its documentation, from gopls signature. Resting the mouse on
+// an identifier shows its type or declaration, from gopls definition.
+//
+// gopls reads the files from disk: opening a call saves the buffer first,
+// as Go to Definition does, while hovering over a modified buffer shows
+// nothing rather than saving it under the user's hands.
+
+const (
+	hintBindTag     = "IteHint"              // Bind tag of the mouse and the keys hiding the tooltip
+	tagHintParam    = "hintparam"            // Tooltip tag of the active parameter
+	hoverDelay      = 600 * time.Millisecond // Rest of the mouse before the hover
+	maxHintLines    = 8                      // Lines of documentation shown in the tooltip
+	maxHintWidth    = 80                     // Characters per line of the tooltip
+	callContextSize = 20                     // Lines searched for the open call above the cursor
+)
+
+// funcDeclRe matches the start of a declaration of a function or a
+// method up to its name.
+var funcDeclRe = regexp.MustCompile(`^\s*func\s*(\([^)]*\)\s*)?$`)
+
+// Kinds of tooltip.
+const (
+	hintSignature = "signature"
+	hintHover     = "hover"
+)
+
+// hintState is the tooltip of the signature help and of the hover.
+type hintState struct {
+	window *ToplevelWidget // Tooltip, nil when hidden
+	view   *TextWidget
+	kind   string // What the tooltip shows, hintSignature or hintHover
+
+	call      string // Index of the parenthesis of the call helped, "" if none
+	dismissed string // Call whose signature Escape hid, "" if none
+	signature string // Signature of the call, "" until gopls answers
+	doc       string // Documentation of the function called
+	params    [][2]int
+
+	hoverWord  string // Index of the identifier under the mouse, "" if none
+	hoverTimer string // Pending hover request, "" if none
+
+	// Latest gopls requests, answers to earlier ones are dropped
+	signatureSeq int
+	hoverSeq     int
+}
+
+// bindHints installs the bindings hiding the tooltip and starting the
+// hover.
+func (i *Ite) bindHints() {
+	addBindtag(i.editText.Window, hintBindTag, "")
+	Bind(hintBindTag, "<Motion>", Command(i.onHintMotion))
+	Bind(hintBindTag, "<Leave>", Command(i.hideHover))
+	Bind(hintBindTag, "<KeyPress>", Command(i.hideHover))
+	Bind(hintBindTag, "<ButtonPress>", Command(i.hideHints))
+	Bind(hintBindTag, "<Escape>", Command(func() {
+		i.hint.dismissed = i.hint.call
+		i.hideHint(hintSignature)
+	}))
+	Bind(hintBindTag, "<FocusOut>", Command(i.hideHints))
+}
+
+// onToggleCodeHints switches signature help and hover on or off and
+// persists the choice.
+func (i *Ite) onToggleCodeHints() {
+	i.config.CodeHints = !i.config.CodeHints
+	i.codeHintsVar.Set(checkValue(i.config.CodeHints))
+	i.saveConfig()
+	if !i.config.CodeHints {
+		i.hideHints()
+	}
+}
+
+// hintsEnabled reports whether the current buffer gets code hints.
+func (i *Ite) hintsEnabled() bool {
+	return i.config.CodeHints && filepath.Ext(i.currentFile) == defaultFileExtension &&
+		!i.largeFile && !i.loading()
+}
+
+// showHint shows head in the tooltip, the characters of bold in bold,
+// and doc below it, at the screen position x, y.
+func (i *Ite) showHint(kind string, x, y int, head string, bold [2]int, doc string) {
+	if i.hint.window == nil {
+		w := Toplevel()
+		tclEval("wm overrideredirect %s 1", w)
+		WmTransient(w, App)
+		view := w.Text(textStyle(), Wrap("word"), Background(theme.CurrentLine),
+			Borderwidth(1), Relief("solid"), Padx(px(4)), Pady(px(2)))
+		Grid(view, Row(0), Column(0), Sticky(NEWS))
+		view.TagConfigure(tagHintParam, Font(editorFontFamily, fontSize, "bold"))
+		i.hint.window, i.hint.view = w, view
+	}
+	i.hint.kind = kind
+	view := i.hint.view
+	view.Configure(State("normal"))
+	view.Delete("1.0", "end")
+	view.Insert("end", head[:bold[0]])
+	view.Insert("end", head[bold[0]:bold[1]], tagHintParam)
+	view.Insert("end", head[bold[1]:])
+	lines := strings.Split(head, "\n")
+	if doc != "" {
+		docLines := strings.Split(doc, "\n")
+		if len(docLines) > maxHintLines {
+			docLines = append(docLines[:maxHintLines], "...")
+		}
+		view.Insert("end", "\n\n"+strings.Join(docLines, "\n"))
+		lines = append(lines, "")
+		lines = append(lines, docLines...)
+	}
+	width := 0
+	for _, line := range lines {
+		width = max(width, utf8.RuneCountInString(line))
+	}
+	width = min(width, maxHintWidth)
+	height := 0
+	for _, line := range lines {
+		height += max(1, (utf8.RuneCountInString(line)+width-1)/max(width, 1))
+	}
+	view.Configure(Width(max(width, 1)), Height(height), State("disabled"))
+	WmGeometry(i.hint.window.Window, fmt.Sprintf("+%d+%d", x, y))
+	tclEval("raise %s", i.hint.window)
+}
+
+// hideHint hides the tooltip if it shows kind.
+func (i *Ite) hideHint(kind string) {
+	if i.hint.window != nil && i.hint.kind == kind {
+		Destroy(i.hint.window)
+		i.hint.window, i.hint.view = nil, nil
+	}
+}
+
+// hideHints hides the tooltip and forgets the call and the identifier it
+// was shown for.
+func (i *Ite) hideHints() {
+	i.hideHint(hintSignature)
+	i.hideHover()
+	i.hint.call = ""
+}
+
+// -------------------------------------------------------------------------
+// Signature Help
+// -------------------------------------------------------------------------
+
+// updateSignatureHelp shows the signature of the call around the cursor,
+// asking gopls for it when the cursor enters a new call, and hides it out
+// of calls. It runs after every key.
+func (i *Ite) updateSignatureHelp() {
+	if !i.hintsEnabled() {
+		i.hideHints()
+		return
+	}
+	before := i.editText.Get(fmt.Sprintf("insert -%d lines linestart", callContextSize), "insert")[0]
+	open, arg, ok := callContext(before)
+	if !ok {
+		i.hideHint(hintSignature)
+		i.hint.call = ""
+		return
+	}
+	call := i.editText.Index(fmt.Sprintf("insert -%d chars", utf8.RuneCountInString(before[open:])))
+	if call != i.hint.call {
+		i.hideHint(hintSignature)
+		i.hint.call, i.hint.signature, i.hint.dismissed = call, "", ""
+		i.requestSignature(call)
+		return
+	}
+	if i.hint.signature == "" || call == i.hint.dismissed {
+		return // Waiting for gopls, or hidden
+	}
+	var bold [2]int
+	if n := len(i.hint.params); arg < n {
+		bold = i.hint.params[arg]
+	} else if n > 0 && strings.Contains(i.hint.signature[i.hint.params[n-1][0]:i.hint.params[n-1][1]], "...") {
+		bold = i.hint.params[n-1] // More variadic arguments
+	}
+	x, y, ok := i.indexScreenPosition(call)
+	if !ok {
+		return
+	}
+	i.showHint(hintSignature, x, y, i.hint.signature, bold, i.hint.doc)
+}
+
+// requestSignature asks gopls for the signature of the call whose open
+// parenthesis is at index call, saving the buffer first.
+func (i *Ite) requestSignature(call string) {
+	gopls, err := exec.LookPath("gopls")
+	if err != nil {
+		return
+	}
+	if i.editText.Modified() {
+		i.autosaveBuffer() // gopls reads the file from disk
+		if i.editText.Modified() {
+			return
+		}
+	}
+	pos := i.goplsPosition(call + " +1 chars")
+	dir := filepath.Dir(i.currentFile)
+	text := i.editText
+	i.hint.signatureSeq++
+	seq := i.hint.signatureSeq
+	go func() {
+		cmd := exec.Command(gopls, "signature", pos)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		i.Dispatch(func() {
+			if err != nil || seq != i.hint.signatureSeq || text != i.editText || call != i.hint.call {
+				return
+			}
+			i.hint.signature, i.hint.doc = parseSignature(string(output))
+			i.hint.params = signatureParams(i.hint.signature)
+			if i.hint.signature != "" {
+				i.updateSignatureHelp()
+			}
+		})
+	}()
+}
+
+// goplsPosition returns the file:line:column position of index in the
+// current file, with the column in bytes, as gopls takes it.
+func (i *Ite) goplsPosition(index string) string {
+	line, col := parseIndex(i.editText.Index(index))
+	runes := []rune(lineText(i.editText, line))
+	return fmt.Sprintf("%s:%d:%d", i.currentFile, line, len(string(runes[:min(col, len(runes))]))+1)
+}
+
+// indexScreenPosition returns the screen position right below the
+// character at index, if visible.
+func (i *Ite) indexScreenPosition(index string) (x, y int, ok bool) {
+	bbox := strings.Fields(tclEval("%s bbox %s", i.editText, index))
+	if len(bbox) < 4 {
+		return 0, 0, false
+	}
+	x = winfoInt(tclEval("winfo rootx %s", i.editText)) + winfoInt(bbox[0])
+	y = winfoInt(tclEval("winfo rooty %s", i.editText)) + winfoInt(bbox[1]) + winfoInt(bbox[3])
+	return x, y, true
+}
+
+// callContext finds the innermost call left open at the end of src: the
+// byte offset of its open parenthesis and the argument being typed,
+// counted from 0. Strings, runes and comments are skipped; parentheses of
+// declarations and of statements such as if don't count as calls.
+func callContext(src string) (open, arg int, ok bool) {
+	type bracket struct {
+		pos    int
+		commas int
+	}
+	var stack []bracket
+	for n := 0; n < len(src); n++ {
+		switch c := src[n]; c {
+		case '"', '\'', '`':
+			end := literalEnd(src, n)
+			if end < 0 {
+				return 0, 0, false // The cursor is inside a literal
+			}
+			n = end
+		case '/':
+			if strings.HasPrefix(src[n:], "//") {
+				end := strings.IndexByte(src[n:], '\n')
+				if end < 0 {
+					return 0, 0, false
+				}
+				n += end
+			} else if strings.HasPrefix(src[n:], "/*") {
+				end := strings.Index(src[n+2:], "*/")
+				if end < 0 {
+					return 0, 0, false
+				}
+				n += end + 3
+			}
+		case '(', '[', '{':
+			stack = append(stack, bracket{pos: n})
+		case ')', ']', '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if len(stack) > 0 {
+				stack[len(stack)-1].commas++
+			}
+		}
+	}
+	if len(stack) == 0 {
+		return 0, 0, false
+	}
+	top := stack[len(stack)-1]
+	if src[top.pos] != '(' || !isCallee(src[:top.pos]) {
+		return 0, 0, false
+	}
+	return top.pos, top.commas, true
+}
+
+// literalEnd returns the offset of the quote closing the literal opened
+// at offset start of src, or -1 if it isn't closed.
+func literalEnd(src string, start int) int {
+	quote := src[start]
+	for n := start + 1; n < len(src); n++ {
+		switch src[n] {
+		case quote:
+			return n
+		case '\\':
+			if quote != '`' {
+				n++
+			}
+		case '\n':
+			if quote != '`' {
+				return n // Unterminated, don't let it swallow the lines below
+			}
+		}
+	}
+	return -1
+}
+
+// isCallee reports whether the code before an open parenthesis ends with
+// an expression called, not with a keyword or a declared name.
+func isCallee(src string) bool {
+	src = strings.TrimRight(src, " \t")
+	if strings.HasSuffix(src, ")") || strings.HasSuffix(src, "]") {
+		return true // Call of a call result or of an instantiation
+	}
+	end := len(src)
+	start := end
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(src[:start])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		start -= size
+	}
+	word := src[start:end]
+	if word == "" || token.Lookup(word).IsKeyword() {
+		return false
+	}
+	rest := src[:strings.LastIndexByte(src[:start], '\n')+1]
+	return !funcDeclRe.MatchString(src[len(rest):start]) // A declaration, not a call
+}
+
+// parseSignature splits the output of gopls signature into the signature
+// and its documentation.
+func parseSignature(output string) (signature, doc string) {
+	signature, doc, _ = strings.Cut(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(signature), strings.TrimSpace(doc)
+}
+
+// signatureParams returns the byte ranges of the parameters in a
+// signature such as "Cut(s string, sep string) (before string, ...)".
+func signatureParams(signature string) [][2]int {
+	open := -1
+	depth := 0
+	for n, c := range signature {
+		if c == '[' {
+			depth++
+		} else if c == ']' {
+			depth--
+		} else if c == '(' && depth == 0 {
+			open = n
+			break
+		}
+	}
+	if open < 0 {
+		return nil
+	}
+	var params [][2]int
+	start := open + 1
+	depth = 0
+	for n := start; n < len(signature); n++ {
+		switch signature[n] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if p := trimRange(signature, start, n); p[0] < p[1] {
+				params = append(params, p)
+			}
+			return params
+		case ',':
+			if depth == 0 {
+				params = append(params, trimRange(signature, start, n))
+				start = n + 1
+			}
+		}
+	}
+	return params
+}
+
+// trimRange returns the range start, end of s without its surrounding
+// spaces.
+func trimRange(s string, start, end int) [2]int {
+	for start < end && s[start] == ' ' {
+		start++
+	}
+	for end > start && s[end-1] == ' ' {
+		end--
+	}
+	return [2]int{start, end}
+}
+
+// -------------------------------------------------------------------------
+// Hover
+// -------------------------------------------------------------------------
+
+// onHintMotion starts the hover delay when the mouse moves onto another
+// identifier or testdata file name, and hides the hover of the previous
+// one.
+func (i *Ite) onHintMotion(e *Event) {
+	if !i.hintsEnabled() {
+		return
+	}
+	index := mouseIndex(i.editText, e)
+	if i.fixtureHover(index) {
+		return
+	}
+	line, col := parseIndex(index)
+	start, end := wordBounds([]rune(lineText(i.editText, line)), col, i.isWordChar)
+	word := ""
+	if start < end && col < end {
+		word = fmt.Sprintf("%d.%d", line, start)
+	}
+	if word == i.hint.hoverWord {
+		return
+	}
+	i.hideHover()
+	i.hint.hoverWord = word
+	if word != "" {
+		i.hint.hoverTimer = TclAfter(hoverDelay, func() {
+			i.hint.hoverTimer = ""
+			i.requestHover(word)
+		})
+	}
+}
+
+// hideHover cancels the pending hover and hides the one shown.
+func (i *Ite) hideHover() {
+	if i.hint.hoverTimer != "" {
+		TclAfterCancel(i.hint.hoverTimer)
+		i.hint.hoverTimer = ""
+	}
+	i.hint.hoverWord = ""
+	i.hideHint(hintHover)
+}
+
+// requestHover asks gopls for the declaration of the identifier at index
+// word and shows it at the mouse.
+func (i *Ite) requestHover(word string) {
+	gopls, err := exec.LookPath("gopls")
+	if err != nil || i.editText.Modified() || !i.hintsEnabled() {
+		return
+	}
+	pos := i.goplsPosition(word)
+	dir := filepath.Dir(i.currentFile)
+	text := i.editText
+	i.hint.hoverSeq++
+	seq := i.hint.hoverSeq
+	go func() {
+		desc, err := goplsHover(gopls, dir, pos)
+		i.Dispatch(func() {
+			if err != nil || seq != i.hint.hoverSeq || text != i.editText || word != i.hint.hoverWord {
+				return
+			}
+			head := hoverType(desc)
+			xy := strings.Fields(tclEval("winfo pointerxy %s", App))
+			if head == "" || len(xy) < 2 {
+				return
+			}
+			i.showHint(hintHover, winfoInt(xy[0])+12, winfoInt(xy[1])+16, head, [2]int{}, "")
+		})
+	}()
+}
+
+// hoverType returns the declaration opening the hover text of gopls,
+// inside a ```go block, or else its first line.
+func hoverType(desc string) string {
+	desc = strings.TrimSpace(desc)
+	if code, ok := strings.CutPrefix(desc, "```go\n"); ok {
+		code, _, _ = strings.Cut(code, "```")
+		lines := strings.Split(strings.TrimSpace(code), "\n")
+		if len(lines) > maxHintLines {
+			lines = append(lines[:maxHintLines], "...")
+		}
+		return strings.Join(lines, "\n")
+	}
+	first, _, _ := strings.Cut(desc, "\n")
+	return first
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestCallContext(t *testing.T) {
+	tests := []struct {
+		src  string
+		open int // -1 outside of calls
+		arg  int
+	}{
+		{"strings.Cut(", 11, 0},
+		{"strings.Cut(s, ", 11, 1},
+		{"f(g(x), ", 1, 1},
+		{"f(g(x, ", 3, 1},
+		{`f("a, (b", `, 1, 1},
+		{"f('(', ", 1, 1},
+		{"f(a, // (x,\n", 1, 1},
+		{"f(x)", -1, 0},
+		{"f(`raw", -1, 0},
+		{"if (", -1, 0},
+		{"func f(", -1, 0},
+		{"func (r *T) M(", -1, 0},
+		{"f(func() {", -1, 0},
+		{"Map[int, string](", 16, 0},
+		{"x := T{a: f(1, ", 11, 1},
+	}
+	for _, tt := range tests {
+		open, arg, ok := callContext(tt.src)
+		if tt.open < 0 {
+			if ok {
+				t.Errorf("callContext(%q) = %d, %d, want no call", tt.src, open, arg)
+			}
+			continue
+		}
+		if !ok || open != tt.open || arg != tt.arg {
+			t.Errorf("callContext(%q) = %d, %d, %v, want %d, %d", tt.src, open, arg, ok, tt.open, tt.arg)
+		}
+	}
+}
+
+func TestSignatureParams(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      []string
+	}{
+		{"Cut(s string, sep string) (before string, after string, found bool)", []string{"s string", "sep string"}},
+		{"Println(a ...any) (n int, err error)", []string{"a ...any"}},
+		{"Close() error", nil},
+		{"Map[K comparable, V any](m map[K]V, f func(K, V) bool)", []string{"m map[K]V", "f func(K, V) bool"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range signatureParams(tt.signature) {
+			got = append(got, tt.signature[p[0]:p[1]])
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("signatureParams(%q) = %q, want %q", tt.signature, got, tt.want)
+			continue
+		}
+		for n := range got {
+			if got[n] != tt.want[n] {
+				t.Errorf("signatureParams(%q) = %q, want %q", tt.signature, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	sig, doc := parseSignature("Cut(s string, sep string) (before string, after string, found bool)\n\nCut slices s around sep.\n")
+	if sig != "Cut(s string, sep string) (before string, after string, found bool)" || doc != "Cut slices s around sep." {
+		t.Errorf("parseSignature = %q, %q", sig, doc)
+	}
+}
+
+func TestHoverType(t *testing.T) {
+	tests := []struct{ desc, want string }{
+		{"```go\nvar count int\n```\n\nCount of the items.", "var count int"},
+		{"```go\nfunc strings.Cut(s string, sep string) (before string, after string, found bool)\n```", "func strings.Cut(s string, sep string) (before string, after string, found bool)"},
+		{"package fmt\n\nPackage fmt implements formatted I/O.", "package fmt"},
+	}
+	for _, tt := range tests {
+		if got := hoverType(tt.desc); got != tt.want {
+			t.Errorf("hoverType(%q) = %q, want %q", tt.desc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// HTTP Client Panel
+// -------------------------------------------------------------------------
+
+const (
+	httpTimeout    = 30 * time.Second
+	httpDefaultURL = "http://localhost:8080/"
+	httpMaxBody    = 1 << 20 // Response bytes shown, the rest is cut
+)
+
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// httpPanel holds the widgets of the HTTP client window.
+type httpPanel struct {
+	window   *ToplevelWidget
+	method   *TComboboxWidget
+	url      *TEntryWidget
+	headers  *TextWidget // One "Name: value" per line
+	body     *TextWidget
+	response *TextWidget
+}
+
+// httpResult is the outcome of a request, delivered to the UI thread.
+type httpResult struct {
+	text string
+	err  error
+}
+
+// onHTTPClient opens the HTTP client panel, or raises it if already open.
+func (i *Ite) onHTTPClient() {
+	if i.http != nil {
+		WmDeiconify(i.http.window.Window)
+		tclEval("raise %s", i.http.window)
+		Focus(i.http.url)
+		return
+	}
+	p := &httpPanel{window: Toplevel()}
+	p.window.WmTitle("HTTP Client")
+
+	top := p.window.TFrame()
+	Grid(top, Row(0), Column(0), Columnspan(2), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	p.method = top.TCombobox(Values(httpMethods), Width(8), Textvariable("GET"))
+	p.url = top.TEntry(Textvariable(httpDefaultURL))
+	send := top.TButton(Txt("Send"), Command(i.sendHTTPRequest))
+	Grid(p.method, Row(0), Column(0), Padx(px(2)))
+	Grid(p.url, Row(0), Column(1), Sticky(WE), Padx(px(2)))
+	Grid(send, Row(0), Column(2), Padx(px(2)))
+	GridColumnConfigure(top, 1, Weight(1))
+
+	Grid(p.window.TLabel(Txt("Headers")), Row(1), Column(0), Sticky(W), Padx(px(5)))
+	p.headers = p.window.Text(textStyle(), Width(60), Height(5))
+	Grid(p.headers, Row(2), Column(0), Sticky(NEWS), Padx(px(5)))
+	Grid(p.window.TLabel(Txt("Body")), Row(3), Column(0), Sticky(W), Padx(px(5)))
+	p.body = p.window.Text(textStyle(), Width(60), Height(15))
+	Grid(p.body, Row(4), Column(0), Sticky(NEWS), Padx(px(5)), Pady(px(5)))
+
+	Grid(p.window.TLabel(Txt("Response")), Row(1), Column(1), Sticky(W), Padx(px(5)))
+	p.response = p.window.Text(textStyle(), Width(80))
+	p.response.Configure(State("disabled"))
+	Grid(p.response, Row(2), Column(1), Rowspan(3), Sticky(NEWS), Padx(px(5)), Pady(px(5)))
+
+	GridColumnConfigure(p.window, 0, Weight(1))
+	GridColumnConfigure(p.window, 1, Weight(2))
+	GridRowConfigure(p.window, 4, Weight(1))
+
+	Bind(p.url, "<Return>", Command(i.sendHTTPRequest))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", func() {
+		Destroy(p.window)
+		i.http = nil
+	})
+	Focus(p.url)
+	i.http = p
+}
+
+// sendHTTPRequest sends the request described by the panel in the
+// background; the response is dispatched to showHTTPResult.
+func (i *Ite) sendHTTPRequest() {
+	p := i.http
+	method := strings.ToUpper(strings.TrimSpace(p.method.Textvariable()))
+	url := strings.TrimSpace(p.url.Textvariable())
+	body := p.body.Get("1.0", "end-1c")[0]
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		i.showHTTPResult(httpResult{err: err})
+		return
+	}
+	header, err := parseHeaders(p.headers.Get("1.0", "end-1c")[0])
+	if err != nil {
+		i.showHTTPResult(httpResult{err: err})
+		return
+	}
+	req.Header = header
+	i.showHTTPResult(httpResult{text: method + " " + url + " ...\n"})
+
+	go func() {
+		start := time.Now()
+		client := &http.Client{Timeout: httpTimeout}
+		resp, err := client.Do(req)
+		if err != nil {
+			i.Dispatch(func() { i.showHTTPResult(httpResult{err: err}) })
+			return
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(io.LimitReader(resp.Body, httpMaxBody+1))
+		if err != nil {
+			i.Dispatch(func() { i.showHTTPResult(httpResult{err: err}) })
+			return
+		}
+		res := httpResult{text: formatResponse(resp, data, time.Since(start))}
+		i.Dispatch(func() { i.showHTTPResult(res) })
+	}()
+}
+
+// parseHeaders parses "Name: value" lines; blank lines are ignored.
+func parseHeaders(text string) (http.Header, error) {
+	header := make(http.Header)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header line %q, want \"Name: value\"", line)
+		}
+		header.Add(textproto.TrimString(name), textproto.TrimString(value))
+	}
+	return header, nil
+}
+
+// formatResponse renders the status line, the headers and the body of a
+// response, pretty-printing JSON bodies.
+func formatResponse(resp *http.Response, data []byte, elapsed time.Duration) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s (%v)\n", resp.Proto, resp.Status, elapsed.Round(time.Millisecond))
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+	sb.WriteString("\n")
+
+	truncated := len(data) > httpMaxBody
+	if truncated {
+		data = data[:httpMaxBody]
+	}
+	var pretty bytes.Buffer
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") && json.Indent(&pretty, data, "", "  ") == nil {
+		sb.Write(pretty.Bytes())
+	} else {
+		sb.Write(data)
+	}
+	if truncated {
+		fmt.Fprintf(&sb, "\n... (truncated at %d bytes)", httpMaxBody)
+	}
+	return sb.String()
+}
+
+// showHTTPResult replaces the response view of the panel, if still open.
+func (i *Ite) showHTTPResult(res httpResult) {
+	if i.http == nil {
+		return
+	}
+	text := res.text
+	if res.err != nil {
+		text = "Error: " + res.err.Error()
+	}
+	r := i.http.response
+	r.Configure(State("normal"))
+	r.Delete("1.0", "end")
+	r.Insert("1.0", text)
+	r.Configure(State("disabled"))
+}
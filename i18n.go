@@ -0,0 +1,85 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Translations
+// -------------------------------------------------------------------------
+
+// Messages are written in English in the code and translated where they
+// are shown, by tr, from the catalog of the language of the interface,
+// keyed by the English text. A message missing from a catalog shows in
+// English. The language is chosen in the preferences or, by default,
+// taken from the locale of the environment, and applies from the start.
+const defaultLanguage = "en"
+
+// catalogs holds the translations by language code, English aside.
+var catalogs = map[string]map[string]string{
+	"it": messagesIT,
+}
+
+// languages are the choices of the Language preference, "" following the
+// environment.
+var languages = []string{"", defaultLanguage, "it"}
+
+// language is the language of the interface, set at startup.
+var language = defaultLanguage
+
+// uiLanguage returns the language of the interface for the setting of
+// the preferences or, if empty, the locale in LC_ALL, LC_MESSAGES or
+// LANG, as read by getenv. Languages without a catalog fall back to
+// English.
+func uiLanguage(setting string, getenv func(string) string) string {
+	lang := setting
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang != "" {
+			break
+		}
+		lang = getenv(name)
+	}
+	// A locale reads language_TERRITORY.codeset@modifier, as in it_IT.UTF-8
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang = strings.ToLower(lang)
+	if _, ok := catalogs[lang]; !ok {
+		return defaultLanguage
+	}
+	return lang
+}
+
+// tr returns the translation of msg into the language of the interface.
+func tr(msg string) string {
+	if t, ok := catalogs[language][msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// trf formats args with the translation of format.
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// languageLabel returns how the Language preference shows lang.
+func languageLabel(lang string) string {
+	if lang == "" {
+		return "auto"
+	}
+	return lang
+}
+
+// languageSetting returns the setting chosen as label in the preferences.
+func languageSetting(label string) string {
+	if label == "auto" || !slices.Contains(languages, label) {
+		return ""
+	}
+	return label
+}
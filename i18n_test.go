@@ -0,0 +1,52 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestUILanguage(t *testing.T) {
+	tests := []struct {
+		setting string
+		env     map[string]string
+		want    string
+	}{
+		{"", map[string]string{"LANG": "it_IT.UTF-8"}, "it"},
+		{"", map[string]string{"LC_ALL": "it_CH@euro", "LANG": "en_US.UTF-8"}, "it"},
+		{"", map[string]string{"LC_MESSAGES": "C", "LANG": "it_IT.UTF-8"}, "en"},
+		{"", map[string]string{"LANG": "de_DE.UTF-8"}, "en"},
+		{"", nil, "en"},
+		{"en", map[string]string{"LANG": "it_IT.UTF-8"}, "en"},
+		{"it", nil, "it"},
+	}
+	for _, tt := range tests {
+		getenv := func(name string) string { return tt.env[name] }
+		if got := uiLanguage(tt.setting, getenv); got != tt.want {
+			t.Errorf("uiLanguage(%q, %v) = %q, want %q", tt.setting, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer func(lang string) { language = lang }(language)
+	language = "it"
+	if got := tr("Save As..."); got != "Salva con nome..." {
+		t.Errorf("tr(%q) = %q", "Save As...", got)
+	}
+	if got := tr("Not in the catalog"); got != "Not in the catalog" {
+		t.Errorf("tr of a missing message = %q, want it in English", got)
+	}
+	language = defaultLanguage
+	if got := tr("Save As..."); got != "Save As..." {
+		t.Errorf("tr in English = %q", got)
+	}
+}
+
+func TestItalianCatalogCoversCommands(t *testing.T) {
+	i := newBareIte()
+	for _, c := range i.builtinCommands() {
+		if _, ok := messagesIT[c.title]; !ok {
+			t.Errorf("no Italian title for command %s, %q", c.id, c.title)
+		}
+	}
+}
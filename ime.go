@@ -0,0 +1,89 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Composed Input
+// -------------------------------------------------------------------------
+
+// keysymUnknown is the keysym Tk reports for a key event carrying text
+// without a key of its own, such as a string committed by an input method.
+const keysymUnknown = "??"
+
+// Keysym names of the printable ASCII (from 0x20) and Latin-1 (from 0xa0)
+// characters. Their keysym values equal the character codes.
+var (
+	asciiKeysyms = strings.Fields(`space exclam quotedbl numbersign dollar
+		percent ampersand apostrophe parenleft parenright asterisk plus comma
+		minus period slash 0 1 2 3 4 5 6 7 8 9 colon semicolon less equal
+		greater question at A B C D E F G H I J K L M N O P Q R S T U V W X Y Z
+		bracketleft backslash bracketright asciicircum underscore grave
+		a b c d e f g h i j k l m n o p q r s t u v w x y z
+		braceleft bar braceright asciitilde`)
+	latin1Keysyms = strings.Fields(`nobreakspace exclamdown cent sterling
+		currency yen brokenbar section diaeresis copyright ordfeminine
+		guillemotleft notsign hyphen registered macron degree plusminus
+		twosuperior threesuperior acute mu paragraph periodcentered cedilla
+		onesuperior masculine guillemotright onequarter onehalf threequarters
+		questiondown Agrave Aacute Acircumflex Atilde Adiaeresis Aring AE
+		Ccedilla Egrave Eacute Ecircumflex Ediaeresis Igrave Iacute Icircumflex
+		Idiaeresis ETH Ntilde Ograve Oacute Ocircumflex Otilde Odiaeresis
+		multiply Oslash Ugrave Uacute Ucircumflex Udiaeresis Yacute THORN ssharp
+		agrave aacute acircumflex atilde adiaeresis aring ae ccedilla egrave
+		eacute ecircumflex ediaeresis igrave iacute icircumflex idiaeresis eth
+		ntilde ograve oacute ocircumflex otilde odiaeresis division oslash
+		ugrave uacute ucircumflex udiaeresis yacute thorn ydiaeresis`)
+)
+
+// keysymRunes maps the keysym names above to their characters.
+var keysymRunes = func() map[string]rune {
+	m := make(map[string]rune, len(asciiKeysyms)+len(latin1Keysyms))
+	for n, name := range asciiKeysyms {
+		m[name] = rune(0x20 + n)
+	}
+	for n, name := range latin1Keysyms {
+		m[name] = rune(0xa0 + n)
+	}
+	return m
+}()
+
+// keysymRune returns the character typed by a key with the given keysym:
+// a named ASCII or Latin-1 keysym, as produced by dead keys and compose
+// sequences, a Unicode keysym such as "U20AC", or a keysym that is the
+// character itself. ok is false for keys that type nothing.
+func keysymRune(keysym string) (r rune, ok bool) {
+	if r, ok := keysymRunes[keysym]; ok {
+		return r, true
+	}
+	if r, size := utf8.DecodeRuneInString(keysym); size == len(keysym) && r != utf8.RuneError {
+		return r, true
+	}
+	if hex, found := strings.CutPrefix(keysym, "U"); found && len(hex) >= 4 {
+		if n, err := strconv.ParseUint(hex, 16, 32); err == nil && utf8.ValidRune(rune(n)) {
+			return rune(n), true
+		}
+	}
+	return 0, false
+}
+
+// bindComposition tracks input method composition in the editor. While
+// text is being composed Tk shows it in the buffer as marked text, which
+// the per-keystroke features must leave alone until it is committed.
+// Tk reports composition this way on macOS; elsewhere the input method
+// draws the text itself and commits it as ordinary key events.
+func (i *Ite) bindComposition() {
+	Bind(i.editText, "<<TkStartIMEMarkedText>>", Command(func() { i.composing = true }))
+	Bind(i.editText, "<<TkEndIMEMarkedText>>", Command(func() {
+		i.composing = false
+		TclAfterIdle(i.onEditorKeyRelease) // After Tk commits the text
+	}))
+}
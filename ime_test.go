@@ -0,0 +1,75 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestKeysymRune(t *testing.T) {
+	tests := []struct {
+		keysym string
+		r      rune
+		ok     bool
+	}{
+		{"a", 'a', true},
+		{"Z", 'Z', true},
+		{"space", ' ', true},
+		{"braceleft", '{', true},
+		{"asciitilde", '~', true},
+		// Results of dead keys, e.g. dead_acute then e
+		{"eacute", 'é', true},
+		{"Udiaeresis", 'Ü', true},
+		{"ccedilla", 'ç', true},
+		{"nobreakspace", ' ', true},
+		{"ydiaeresis", 'ÿ', true},
+		// Results of Multi_key sequences, e.g. Multi_key = e
+		{"U20AC", '€', true},
+		{"U1F600", '😀', true},
+		{"ß", 'ß', true},
+		{"中", '中', true},
+		// Keys typing nothing themselves
+		{"dead_acute", 0, false},
+		{"dead_circumflex", 0, false},
+		{"Multi_key", 0, false},
+		{"Shift_L", 0, false},
+		{"Return", 0, false},
+		{"F1", 0, false},
+		{keysymUnknown, 0, false},
+		{"U12", 0, false},
+		{"UD800", 0, false},
+		{"Uzzzz", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		r, ok := keysymRune(tt.keysym)
+		if r != tt.r || ok != tt.ok {
+			t.Errorf("keysymRune(%q) = %q, %v; want %q, %v", tt.keysym, r, ok, tt.r, tt.ok)
+		}
+	}
+}
+
+func TestComposition(t *testing.T) {
+	h := newHarness(t)
+	var autoClose bool
+	h.do(func() { autoClose, h.i.config.AutoClosePairs = h.i.config.AutoClosePairs, true })
+	t.Cleanup(func() { h.do(func() { h.i.config.AutoClosePairs = autoClose }) })
+	h.do(func() {
+		tclEval("event generate %s <<TkStartIMEMarkedText>>", h.i.editText)
+	})
+	// The keys of the input method leave the text to it: no pair, no indent
+	h.typeText("f(\n")
+	h.wantText("f(\n")
+	h.do(func() {
+		if !h.i.composing {
+			t.Error("not composing after <<TkStartIMEMarkedText>>")
+		}
+		tclEval("event generate %s <<TkEndIMEMarkedText>>", h.i.editText)
+	})
+	h.do(func() {
+		if h.i.composing {
+			t.Error("still composing after <<TkEndIMEMarkedText>>")
+		}
+	})
+	h.typeText("g(")
+	h.wantText("f(\ng()")
+}
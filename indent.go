@@ -0,0 +1,145 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Auto-indentation
+// -------------------------------------------------------------------------
+
+const (
+	defaultIndentWidth = 4    // Spaces per level in files indented with spaces
+	indentSampleLines  = 1000 // Lines of a large file looked at by indentUnit
+)
+
+// bindAutoIndent replaces the newline handling of the editor: a new line
+// keeps the indentation of the previous one, one level deeper after an
+// opening bracket, and a closing brace typed on a blank line dedents.
+// The keys pressed with Control or Alt, which Tk also matches to these
+// bindings, are left to the key bindings, e.g. Alt-Return to Convert
+// Number.
+func (i *Ite) bindAutoIndent() {
+	for _, key := range []string{"<Return>", "<KP_Enter>"} {
+		Bind(i.editText, key, Command(func(e *Event) {
+			if i.composing {
+				return // The key commits the composed text
+			}
+			if e.State&(ModifierControl|ModifierAlt) != 0 {
+				return
+			}
+			i.smartNewline()
+			e.SetReturnCodeBreak()
+		}))
+	}
+	Bind(i.editText, "<braceright>", Command(func(e *Event) {
+		if i.composing || e.State&(ModifierControl|ModifierAlt) != 0 {
+			return
+		}
+		i.insertCloseBrace()
+		e.SetReturnCodeBreak()
+	}))
+}
+
+// smartNewline breaks the line at the cursor and indents the new line.
+// Between a pair of brackets, as in "{|}", the closing bracket moves to a
+// line of its own below the cursor.
+func (i *Ite) smartNewline() {
+	i.deleteSelectionAtCursor()
+	before := i.editText.Get("insert linestart", "insert")[0]
+	after := i.editText.Get("insert", "insert lineend")[0]
+	indent := leadingSpace(before)
+	trimmed := strings.TrimRight(before, " \t")
+
+	inner := indent
+	if strings.HasSuffix(trimmed, "{") || strings.HasSuffix(trimmed, "(") || strings.HasSuffix(trimmed, "[") {
+		inner += i.indentUnit()
+	}
+	i.editText.Insert("insert", "\n"+inner)
+	if inner != indent && closesBracket(trimmed, after) {
+		i.editText.Insert("insert", "\n"+indent)
+		i.editText.MarkSet("insert", "insert -1 lines lineend")
+	}
+	i.editText.See("insert")
+}
+
+// insertCloseBrace types "}", first removing one level of indentation when
+// the cursor is preceded by whitespace only. Over a brace the editor closed
+// itself, the cursor steps past it instead.
+func (i *Ite) insertCloseBrace() {
+	if i.skipAutoClosed("}") {
+		i.editText.See("insert")
+		return
+	}
+	i.deleteSelectionAtCursor()
+	before := i.editText.Get("insert linestart", "insert")[0]
+	if before != "" && strings.TrimLeft(before, " \t") == "" {
+		unit := i.indentUnit()
+		n := 1 // A tab
+		if !strings.HasSuffix(before, "\t") {
+			n = len(before) - len(strings.TrimRight(before, " "))
+			n = min(n, len(unit))
+		}
+		i.editText.Delete(fmt.Sprintf("insert -%dc", n), "insert")
+	}
+	i.editText.Insert("insert", "}")
+	i.editText.See("insert")
+}
+
+// deleteSelectionAtCursor deletes the selection when the cursor is in it,
+// as Tk does before inserting typed text.
+func (i *Ite) deleteSelectionAtCursor() {
+	from, to := i.editRange("")
+	if from != to {
+		i.editText.Delete(from, to)
+	}
+}
+
+// indentUnit returns the text of one indentation level: the one set for
+// the type of the file, see indentStyleOf, else a tab in files indented
+// with tabs, otherwise the smallest run of leading spaces found in the
+// buffer, or in the first lines of a large file.
+func (i *Ite) indentUnit() string {
+	if style, ok := i.indentStyleOf(i.currentFile); ok {
+		return style.unit()
+	}
+	src := i.editText.Text()
+	if i.largeFile {
+		src = i.editText.Get("1.0", fmt.Sprintf("%d.0", indentSampleLines))[0]
+	}
+	width := 0
+	for _, line := range strings.Split(src, "\n") {
+		indent := leadingSpace(line)
+		switch {
+		case indent == "" || strings.TrimSpace(line) == "":
+			continue
+		case indent[0] == '\t':
+			return "\t"
+		case width == 0 || len(indent) < width:
+			width = len(indent)
+		}
+	}
+	if width == 0 {
+		width = defaultIndentWidth
+	}
+	return strings.Repeat(" ", width)
+}
+
+// leadingSpace returns the run of spaces and tabs at the start of s.
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// closesBracket reports whether after starts with the bracket closing the
+// one at the end of before.
+func closesBracket(before, after string) bool {
+	pairs := map[byte]byte{'{': '}', '(': ')', '[': ']'}
+	after = strings.TrimLeft(after, " \t")
+	return before != "" && after != "" && pairs[before[len(before)-1]] == after[0]
+}
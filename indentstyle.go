@@ -0,0 +1,192 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Indentation Settings
+// -------------------------------------------------------------------------
+
+// The indentation of a file, tabs or spaces and how wide, comes from its
+// extension, or its name for files without one such as Makefile. The
+// indentation map of the config file overrides the built-in entries:
+//
+//	"indentation": {".yaml": {"spaces": true, "width": 2}, ".c": {"width": 8}}
+//
+// Files of other types are indented like the buffer already is. Tab and
+// Shift-Tab over a selection indent and dedent its lines.
+const indentBindTag = "IteIndent"
+
+// indentStyle is the indentation of a type of file.
+type indentStyle struct {
+	Spaces bool `json:"spaces"` // Indent with spaces rather than tabs
+	Width  int  `json:"width"`  // Columns of a level, 0 for the tab width of the preferences
+}
+
+// defaultIndentation are the built-in indentation styles, by extension or
+// file name.
+var defaultIndentation = map[string]indentStyle{
+	".go":      {},
+	"go.mod":   {},
+	"Makefile": {},
+	".yaml":    {Spaces: true, Width: 2},
+	".yml":     {Spaces: true, Width: 2},
+	".json":    {Spaces: true, Width: 2},
+	".toml":    {Spaces: true, Width: 2},
+	".md":      {Spaces: true, Width: 4},
+	".py":      {Spaces: true, Width: 4},
+}
+
+// indentStyleOf returns the indentation style of path and whether there is
+// one for its type.
+func (i *Ite) indentStyleOf(path string) (indentStyle, bool) {
+	if path == "" {
+		return indentStyle{}, false
+	}
+	for _, key := range []string{filepath.Base(path), filepath.Ext(path)} {
+		if key == "" {
+			continue
+		}
+		if style, ok := i.config.Indentation[key]; ok {
+			return style, true
+		}
+		if style, ok := defaultIndentation[key]; ok {
+			return style, true
+		}
+	}
+	return indentStyle{}, false
+}
+
+// width returns the columns of a level of s.
+func (s indentStyle) width() int {
+	if s.Width < 1 || s.Width > maxTabWidth {
+		return tabWidth
+	}
+	return s.Width
+}
+
+// unit returns the text of a level of s.
+func (s indentStyle) unit() string {
+	if s.Spaces {
+		return strings.Repeat(" ", s.width())
+	}
+	return "\t"
+}
+
+// applyTabStops sets the tab stops of the editor to the width of the
+// indentation style of the current file.
+func (i *Ite) applyTabStops() {
+	width := tabWidth
+	if style, ok := i.indentStyleOf(i.currentFile); ok && !style.Spaces {
+		width = style.width()
+	}
+	i.editText.Configure(Tabs(tabStopsOf(width)))
+}
+
+// bindIndentKeys installs Tab and Shift-Tab, which indent and dedent the
+// lines of the selection and insert spaces in files indented with them.
+// They go after the snippet bindings, which use the keys between tab
+// stops.
+func (i *Ite) bindIndentKeys() {
+	addBindtag(i.editText.Window, indentBindTag, "Text")
+	Bind(indentBindTag, "<Tab>", Command(func(e *Event) {
+		if i.composing || e.State&(ModifierControl|ModifierAlt) != 0 {
+			return
+		}
+		if i.onTab() {
+			e.SetReturnCodeBreak()
+		}
+	}))
+	backTab := []string{"<Shift-Tab>"}
+	if tclEval("tk windowingsystem") == "x11" {
+		backTab = append(backTab, "<ISO_Left_Tab>")
+	}
+	for _, key := range backTab {
+		Bind(indentBindTag, key, Command(func(e *Event) {
+			if i.composing {
+				return
+			}
+			i.shiftLines(-1)
+			e.SetReturnCodeBreak()
+		}))
+	}
+}
+
+// onTab indents the lines of the selection or, in a file indented with
+// spaces, inserts spaces up to the next level. It reports whether it did,
+// false leaving the key to the editor, which inserts a tab.
+func (i *Ite) onTab() bool {
+	if from, to := i.editRange(""); from != to {
+		i.shiftLines(1)
+		return true
+	}
+	style, ok := i.indentStyleOf(i.currentFile)
+	if !ok || !style.Spaces {
+		return false
+	}
+	if i.blockProtected("insert", "insert") {
+		return true
+	}
+	col := visualColumn([]rune(i.editText.Get("insert linestart", "insert")[0]))
+	width := style.width()
+	i.editText.Insert("insert", strings.Repeat(" ", width-col%width))
+	i.editText.See("insert")
+	return true
+}
+
+// shiftLines adds a level of indentation to the lines of the selection, or
+// of the cursor, when dir is 1, or removes one when dir is -1. The
+// selection grows to cover the whole lines.
+func (i *Ite) shiftLines(dir int) {
+	from, to := i.editRange("")
+	first, _ := parseIndex(i.editText.Index(from))
+	last, lastCol := parseIndex(i.editText.Index(to))
+	if last > first && lastCol == 0 {
+		last-- // The selection ends at the start of the next line
+	}
+	start, end := fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.end", last)
+	if i.blockProtected(start, end) {
+		return
+	}
+	unit := i.indentUnit()
+	width := len(unit)
+	if unit == "\t" {
+		width = tabWidth
+	}
+	i.editGroup(func() {
+		for line := first; line <= last; line++ {
+			text := i.editText.Get(fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.end", line))[0]
+			if dir > 0 {
+				if strings.TrimSpace(text) != "" {
+					i.editText.Insert(fmt.Sprintf("%d.0", line), unit)
+				}
+				continue
+			}
+			if n := dedentLength(text, width); n > 0 {
+				i.editText.Delete(fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.%d", line, n))
+			}
+		}
+	})
+	if from != to {
+		i.editText.TagRemove("sel", "1.0", "end")
+		i.editText.TagAdd("sel", start, fmt.Sprintf("%d.0 +1 lines", last))
+	}
+	i.refreshCursorState()
+}
+
+// dedentLength returns the number of characters at the start of line
+// making up a level of indentation: a tab, or up to width spaces.
+func dedentLength(line string, width int) int {
+	if strings.HasPrefix(line, "\t") {
+		return 1
+	}
+	return min(len(line)-len(strings.TrimLeft(line, " ")), width)
+}
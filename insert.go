@@ -0,0 +1,55 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// -------------------------------------------------------------------------
+// Insert Helpers
+// -------------------------------------------------------------------------
+
+// insertAtCursor inserts text at the cursor as one undo step, replacing
+// the selection if there is one.
+func (i *Ite) insertAtCursor(text string) {
+	if i.blockProtectedSelection() {
+		return
+	}
+	i.editGroup(func() {
+		if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+			i.editText.Delete(sel[0], sel[len(sel)-1])
+		}
+		i.editText.Insert("insert", text)
+	})
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// onInsertTimestamp inserts the current local time in RFC 3339 format.
+func (i *Ite) onInsertTimestamp() {
+	i.insertAtCursor(time.Now().Format(time.RFC3339))
+}
+
+// onInsertUnixTime inserts the current Unix time in seconds.
+func (i *Ite) onInsertUnixTime() {
+	i.insertAtCursor(strconv.FormatInt(time.Now().Unix(), 10))
+}
+
+// onInsertUUID inserts a random (version 4) UUID.
+func (i *Ite) onInsertUUID() {
+	i.insertAtCursor(newUUID())
+}
+
+// newUUID returns a random UUID as defined by RFC 9562, version 4.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])         // Never fails
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Console Tabs and Job Queue
+// -------------------------------------------------------------------------
+
+// Names of the console tabs, one per kind of command.
+const (
+	consoleBuild   = "Build"
+	consoleRun     = "Run"
+	consoleTest    = "Test"
+	consoleLint    = "Lint"
+	consolePlugins = "Plugins" // Output of the plugins, see plugins.go
+	consoleTasks   = "Tasks"   // Tasks of the project, see tasks.go
+	consoleLogs    = "Logs"    // Logs of the compose services, see compose.go
+)
+
+const (
+	maxJobs       = 2                                            // Commands allowed to run at the same time
+	statusWaiting = "Waiting for another command to finish...\n" // Shown while a job is queued
+)
+
+// console is a tab of the output panel. Every kind of command writes to
+// its own console, so a build and a test run can proceed side by side.
+type console struct {
+	name   string
+	frame  *TFrameWidget
+	text   *TextWidget
+	clicks map[int]func() // Actions of clickable lines, by line
+	runID  int            // Identifier of the latest run, 0 if none
+	runDir string         // Working directory of the latest run
+	root   string         // Project root of the latest run, "" if unknown
+	queued *job           // Run waiting for a free job slot, nil if none
+	active *job           // Started run, nil when idle; guarded by Ite.procMu
+}
+
+// job is a command run, started as soon as fewer than maxJobs are running.
+type job struct {
+	c       *console
+	run     int
+	dir     string   // Working directory of the steps
+	env     []string // Variables added to the environment, as KEY=VALUE
+	steps   []jobStep
+	decoder outputDecoder
+	done    func(res procResult, stopped bool) // Run on the UI thread after the last step, if set
+
+	// Running process, guarded by Ite.procMu
+	proc    process // Program of the current step, nil between steps
+	stopped bool    // Set when the user stopped the job
+}
+
+// jobStep is one of the programs run in turn by a job.
+type jobStep struct {
+	name     string   // Reported in the status line, e.g. "build"
+	args     []string // Program and its arguments
+	dir      string   // Working directory, the job's when empty
+	required bool     // Skip the remaining steps when this one fails
+}
+
+// makeConsoles creates the notebook holding one console per command kind.
+// The tabs are built by buildConsoles.
+func (i *Ite) makeConsoles() {
+	i.consoleTabs = i.editFrame2.TNotebook()
+}
+
+// buildConsoles creates the console tabs, unless already built. It runs
+// once the window is up, or sooner when a command needs a console.
+func (i *Ite) buildConsoles() {
+	if len(i.consoles) > 0 {
+		return
+	}
+	for _, name := range []string{consoleBuild, consoleRun, consoleTest, consoleLint, consoleTasks, consoleLogs, consolePlugins} {
+		c := &console{name: name, frame: i.consoleTabs.TFrame(), clicks: make(map[int]func())}
+		c.text = c.frame.Text(textStyle(), State("disabled"))
+		scrollbar := c.frame.TScrollbar(Command(func(e *Event) { e.Yview(c.text) }))
+		c.text.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+		Grid(c.text, Row(0), Column(0), Sticky(NEWS))
+		Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+		GridRowConfigure(c.frame, 0, Weight(1))
+		GridColumnConfigure(c.frame, 0, Weight(1))
+		i.consoleTabs.Add(c.frame.Window, Txt(name))
+		i.consoles = append(i.consoles, c)
+		i.configureConsoleTags(c)
+		i.bindLocate(c)
+		bindPanelKeys(c.text, func(line int) {
+			if click := c.clicks[line]; click != nil {
+				click()
+			}
+		})
+	}
+}
+
+// console returns the console with the given name.
+func (i *Ite) console(name string) *console {
+	i.buildConsoles()
+	for _, c := range i.consoles {
+		if c.name == name {
+			return c
+		}
+	}
+	panic("unknown console " + name)
+}
+
+// selectedConsole returns the console of the selected tab.
+func (i *Ite) selectedConsole() *console {
+	i.buildConsoles()
+	selected := i.consoleTabs.Select(nil)
+	for _, c := range i.consoles {
+		if c.frame.String() == selected {
+			return c
+		}
+	}
+	return i.consoles[0]
+}
+
+// runConsole returns the console of the run that produced message run, or
+// nil when that run is no longer the latest of its console.
+func (i *Ite) runConsole(run int) *console {
+	for _, c := range i.consoles {
+		if c.runID == run {
+			return c
+		}
+	}
+	return nil
+}
+
+// enqueue starts j right away if a job slot is free, otherwise queues it.
+func (i *Ite) enqueue(j *job) {
+	if !j.c.limited() || i.runningJobs() < maxJobs {
+		i.startJob(j)
+		return
+	}
+	j.c.queued = j
+	i.jobQueue = append(i.jobQueue, j)
+	i.appendConsole(j.c, tr(statusWaiting), "")
+}
+
+// dequeue drops the job waiting in c, if any.
+func (i *Ite) dequeue(c *console) {
+	if c.queued == nil {
+		return
+	}
+	i.jobQueue = slices.DeleteFunc(i.jobQueue, func(j *job) bool { return j == c.queued })
+	c.queued = nil
+}
+
+// jobDone releases the slot of the finished job of run and starts the
+// next queued one, if any.
+func (i *Ite) jobDone(run int) {
+	delete(i.jobs, run)
+	if len(i.jobQueue) > 0 && i.runningJobs() < maxJobs {
+		j := i.jobQueue[0]
+		i.jobQueue = i.jobQueue[1:]
+		j.c.queued = nil
+		i.startJob(j)
+	}
+}
+
+// runningJobs returns the number of job slots in use.
+func (i *Ite) runningJobs() int {
+	n := 0
+	for _, j := range i.jobs {
+		if j.c.limited() {
+			n++
+		}
+	}
+	return n
+}
+
+// limited reports whether runs in c take one of the maxJobs slots. Go Run
+// and the logs followed don't: they may run for the whole session.
+func (c *console) limited() bool {
+	return c.name != consoleRun && c.name != consoleLogs
+}
@@ -0,0 +1,334 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// -------------------------------------------------------------------------
+// Persistent Undo Journal
+// -------------------------------------------------------------------------
+
+// The editor's undo stack is lost when a file is closed or reloaded. The
+// journal keeps, for every file, the edits between its saved versions, so
+// once the undo stack is exhausted Undo keeps going back through earlier
+// saves, even after a restart, and Redo comes forward again.
+
+const (
+	journalDirName  = "undo" // Directory below the config dir holding the journals
+	maxJournalSteps = 100    // Saved versions remembered per file
+)
+
+// undoJournal is the history of the saved versions of a file.
+type undoJournal struct {
+	Path  string     `json:"path"`
+	Steps []undoStep `json:"steps"` // Oldest first
+	Text  string     `json:"text"`  // Content after the last step, as saved
+}
+
+// undoStep turns a saved version into the next one.
+type undoStep struct {
+	Time  time.Time  `json:"time"`  // When the newer version was saved or loaded
+	Edits []textEdit `json:"edits"` // Top to bottom, lines of the older version
+}
+
+// textEdit replaces a run of lines.
+type textEdit struct {
+	Line int      `json:"line"` // 0-based first line replaced, in the older version
+	Old  []string `json:"old"`  // Lines removed
+	New  []string `json:"new"`  // Lines inserted
+}
+
+// journalPath returns the journal file of path, named after a hash of its
+// absolute path.
+func journalPath(path string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, journalDirName, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadJournal reads the journal of path. A missing or unreadable journal
+// yields an empty one: the history is a convenience, not the user's data.
+func loadJournal(path string) *undoJournal {
+	j := &undoJournal{Path: path}
+	file, err := journalPath(path)
+	if err != nil {
+		return j
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return j
+	}
+	var saved undoJournal
+	if json.Unmarshal(data, &saved) != nil || !samePath(saved.Path, path) {
+		return j
+	}
+	saved.Path = path
+	return &saved
+}
+
+// save writes the journal next to the other configuration files. As it
+// holds a copy of the file, it is readable by the user only.
+func (j *undoJournal) save() error {
+	file, err := journalPath(j.Path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, privateDirPerms); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, privateDirPerms); err != nil {
+		return err
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return writePrivateFile(file, data)
+}
+
+// record appends the step from the latest version to text, if they
+// differ, dropping the oldest steps beyond maxJournalSteps. It returns the
+// number of steps dropped.
+func (j *undoJournal) record(text string) (dropped int) {
+	edits := textEdits(j.Text, text)
+	if len(edits) == 0 {
+		return 0
+	}
+	j.Steps = append(j.Steps, undoStep{Time: time.Now(), Edits: edits})
+	j.Text = text
+	dropped = max(len(j.Steps)-maxJournalSteps, 0)
+	j.Steps = j.Steps[dropped:]
+	return dropped
+}
+
+// textEdits returns the line edits turning from into to. Texts too
+// different to diff quickly yield a single edit replacing the lines between
+// their common start and end.
+func textEdits(from, to string) []textEdit {
+	if from == to {
+		return nil
+	}
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+	if diffTooLarge(a, b) {
+		pre, suf := commonEnds(a, b)
+		return []textEdit{{Line: pre, Old: a[pre : len(a)-suf], New: b[pre : len(b)-suf]}}
+	}
+	var edits []textEdit
+	var cur *textEdit
+	line := 0
+	for _, l := range lineDiff(a, b) {
+		if l.op == diffEqual {
+			cur = nil
+			line++
+			continue
+		}
+		if cur == nil {
+			edits = append(edits, textEdit{Line: line})
+			cur = &edits[len(edits)-1]
+		}
+		if l.op == diffDelete {
+			cur.Old = append(cur.Old, l.text)
+			line++
+		} else {
+			cur.New = append(cur.New, l.text)
+		}
+	}
+	return edits
+}
+
+// applyEdits applies edits to text, or reverts them when reverse is set.
+// ok is false when text doesn't hold the lines the edits expect.
+func applyEdits(text string, edits []textEdit, reverse bool) (result string, ok bool) {
+	lines := strings.Split(text, "\n")
+	var out []string
+	next, shift := 0, 0 // Next line of lines to copy; growth of the newer version so far
+	for _, e := range edits {
+		at, remove, insert := e.Line, e.Old, e.New
+		if reverse {
+			at, remove, insert = e.Line+shift, e.New, e.Old
+		}
+		shift += len(e.New) - len(e.Old)
+		if at < next || at+len(remove) > len(lines) || !slices.Equal(lines[at:at+len(remove)], remove) {
+			return "", false
+		}
+		out = append(out, lines[next:at]...)
+		out = append(out, insert...)
+		next = at + len(remove)
+	}
+	out = append(out, lines[next:]...)
+	return strings.Join(out, "\n"), true
+}
+
+// -------------------------------------------------------------------------
+// Journal Integration
+// -------------------------------------------------------------------------
+
+// openJournal loads the journal of the file just read into the buffer.
+// When the file changed since the journal was last written, by another
+// program or while reloading, the change becomes a step of its own.
+func (i *Ite) openJournal(path, text string) {
+	j := i.journal
+	if j == nil || !samePath(j.Path, path) {
+		j = loadJournal(path)
+	}
+	fresh := len(j.Steps) == 0 && j.Text == ""
+	if fresh {
+		j.Text = text
+	} else if j.Text != text {
+		j.record(text)
+		i.saveJournal(j)
+	}
+	i.journal = j
+	i.journalBase = len(j.Steps)
+}
+
+// recordJournal adds the version of the file just saved to the journal.
+func (i *Ite) recordJournal(text string) {
+	if i.journal == nil || !samePath(i.journal.Path, i.currentFile) {
+		// A new name: start a history without a known bottom of the undo stack
+		i.journal = loadJournal(i.currentFile)
+		i.journalBase = -1
+		if len(i.journal.Steps) == 0 && i.journal.Text == "" {
+			i.journal.Text = text
+			i.saveJournal(i.journal)
+			return
+		}
+	}
+	if dropped := i.journal.record(text); dropped > 0 && i.journalBase >= 0 {
+		i.journalBase = max(i.journalBase-dropped, -1)
+	}
+	i.saveJournal(i.journal)
+}
+
+// saveJournal writes j, reporting failures in the status bar only.
+func (i *Ite) saveJournal(j *undoJournal) {
+	if err := j.save(); err != nil {
+		i.showStatusHint("Undo history not saved: " + err.Error())
+	}
+}
+
+// closeJournal forgets the journal of the buffer, e.g. for a new file.
+func (i *Ite) closeJournal() {
+	i.journal = nil
+	i.journalBase = -1
+}
+
+// resetUndo empties the editor's undo stack; its bottom is then the
+// current buffer.
+func (i *Ite) resetUndo() {
+	tclEval("%s edit reset", i.editText)
+	i.undo.lastKind = editNone
+}
+
+// journalUndo goes back to the previous saved version once the editor's
+// undo stack is exhausted. It reports whether it handled the undo.
+func (i *Ite) journalUndo() bool {
+	if tclEval("%s edit canundo", i.editText) == "1" || i.journal == nil || i.journalBase <= 0 {
+		return false
+	}
+	step := i.journal.Steps[i.journalBase-1]
+	if !i.moveThroughJournal(step, true) {
+		return true
+	}
+	i.journalBase--
+	i.showStatusHint("Back to the version saved before " + step.Time.Format(time.DateTime))
+	return true
+}
+
+// journalRedo moves forward to the next saved version when the buffer is
+// at a version of the journal. It reports whether it handled the redo.
+func (i *Ite) journalRedo() bool {
+	if tclEval("%s edit canundo", i.editText) == "1" || tclEval("%s edit canredo", i.editText) == "1" ||
+		i.journal == nil || i.journalBase < 0 || i.journalBase >= len(i.journal.Steps) {
+		return false
+	}
+	step := i.journal.Steps[i.journalBase]
+	if !i.moveThroughJournal(step, false) {
+		return true
+	}
+	i.journalBase++
+	i.showStatusHint("Forward to the version saved " + step.Time.Format(time.DateTime))
+	return true
+}
+
+// moveThroughJournal applies or reverts step to the buffer. The editor's
+// undo stack restarts from the result.
+func (i *Ite) moveThroughJournal(step undoStep, reverse bool) bool {
+	text, ok := applyEdits(i.editText.Get("1.0", "end-1c")[0], step.Edits, reverse)
+	if !ok {
+		i.journalBase = -1
+		i.showStatusHint("Undo history doesn't match the buffer")
+		return false
+	}
+	i.replaceBuffer(text)
+	i.resetUndo()
+	i.editText.SetModified(text != i.journal.Text)
+	i.refreshCursorState()
+	return true
+}
+
+// onUndoToLastSave reverts the buffer to the file as last saved, as a
+// single step that can itself be undone.
+func (i *Ite) onUndoToLastSave() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if !i.editText.Modified() {
+		i.showStatusHint("No changes since the last save")
+		return
+	}
+	saved := ""
+	if i.journal != nil && samePath(i.journal.Path, i.currentFile) {
+		saved = i.journal.Text
+	} else {
+		data, err := os.ReadFile(i.currentFile)
+		if err != nil {
+			i.showError("Error reading file: " + err.Error())
+			return
+		}
+		saved = decodedText(data)
+	}
+	i.editGroup(func() { i.replaceBuffer(saved) })
+	i.editText.SetModified(false)
+	i.refreshCursorState()
+}
+
+// replaceBuffer changes the buffer into text, touching only the range
+// between the common prefix and suffix so marks and tags elsewhere stay.
+func (i *Ite) replaceBuffer(text string) {
+	old := []rune(i.editText.Get("1.0", "end-1c")[0])
+	runes := []rune(text)
+	prefix := 0
+	for prefix < len(old) && prefix < len(runes) && old[prefix] == runes[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(runes)-prefix &&
+		old[len(old)-1-suffix] == runes[len(runes)-1-suffix] {
+		suffix++
+	}
+	from := fmt.Sprintf("1.0 + %d chars", prefix)
+	to := fmt.Sprintf("1.0 + %d chars", len(old)-suffix)
+	i.editText.Delete(from, to)
+	i.editText.Insert(from, string(runes[prefix:len(runes)-suffix]))
+}
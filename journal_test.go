@@ -0,0 +1,38 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTextEdits(t *testing.T) {
+	var long, regenerated []string
+	for n := range maxDiffLines {
+		long = append(long, fmt.Sprint("line ", n))
+		regenerated = append(regenerated, fmt.Sprint("new line ", n))
+	}
+	tests := []struct{ from, to string }{
+		{"a\nb\nc", "a\nb\nc"},
+		{"a\nb\nc", "a\nx\nc"},
+		{"a\nb\nc", "x\na\nb\nc\ny"},
+		{"a\nb\nc\nd", "b\nd"},
+		{"", "a\nb"},
+		{"head\n" + strings.Join(long, "\n") + "\ntail", "head\n" + strings.Join(regenerated, "\n") + "\ntail"},
+	}
+	for _, tt := range tests {
+		edits := textEdits(tt.from, tt.to)
+		if got, ok := applyEdits(tt.from, edits, false); !ok || got != tt.to {
+			t.Errorf("applying textEdits(%.20q, %.20q) gives %.20q, %v", tt.from, tt.to, got, ok)
+		}
+		if got, ok := applyEdits(tt.to, edits, true); !ok || got != tt.from {
+			t.Errorf("reverting textEdits(%.20q, %.20q) gives %.20q, %v", tt.from, tt.to, got, ok)
+		}
+	}
+	if edits := textEdits("head\n"+strings.Join(long, "\n"), "head\n"+strings.Join(regenerated, "\n")); len(edits) != 1 || edits[0].Line != 1 {
+		t.Errorf("texts too different to diff give %d edits, want one from line 1", len(edits))
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Keyboard Shortcuts
+// -------------------------------------------------------------------------
+
+const (
+	keysFileName = "keys"    // Key bindings file inside the config dir
+	keysBindTag  = "IteKeys" // Bind tag running shortcuts before the editor's own keys
+)
+
+// keySeqRe matches the Tk event sequences accepted in the keys file,
+// e.g. "<Control-w>", "<Control-Shift-P>", "<F5>" or, for a chord typed
+// in several steps, "<Control-x><Control-s>" or "<Control-x>k".
+// Digits need the Key- prefix, "<Control-Key-1>", or Tk reads a mouse button.
+var keySeqRe = regexp.MustCompile(`^<((Control|Shift|Alt|Meta|Mod[1-5]|Command|Option)-)*(Key-)?[A-Za-z0-9_]+>` +
+	`(<((Control|Shift|Alt|Meta|Mod[1-5]|Command|Option)-)*(Key-)?[A-Za-z0-9_]+>|[A-Za-z0-9])*$`)
+
+// keysymLabels maps the names of punctuation keys to the character shown
+// in key labels.
+var keysymLabels = map[string]string{
+	"bracketleft":  "[",
+	"bracketright": "]",
+	"slash":        "/",
+	"question":     "?",
+	"percent":      "%",
+	"period":       ".",
+	"comma":        ",",
+	"minus":        "-",
+	"plus":         "+",
+	"equal":        "=",
+	"backslash":    "\\",
+	"bar":          "|",
+}
+
+// Names of the built-in key binding presets.
+const (
+	keyPresetDefault = "default"
+	keyPresetVim     = "vim"
+	keyPresetEmacs   = "emacs"
+)
+
+// keyPresets holds the built-in key bindings, by preset name.
+var keyPresets = map[string]func() map[string]string{
+	keyPresetDefault: defaultKeys,
+	keyPresetVim:     vimKeys,
+	keyPresetEmacs:   emacsKeys,
+}
+
+// defaultKeys returns the built-in key bindings, from event sequence to
+// action name.
+func defaultKeys() map[string]string {
+	return map[string]string{
+		"<Control-n>":            "new",
+		"<Control-o>":            "open",
+		"<Control-p>":            "quickOpen",
+		"<Control-e>":            "switchFile",
+		"<Control-space>":        "complete",
+		"<Control-s>":            "save",
+		"<Control-Shift-s>":      "saveAs",
+		"<Control-w>":            "close",
+		"<Control-q>":            "quit",
+		"<Control-b>":            "build",
+		"<Control-r>":            "run",
+		"<Control-t>":            "test",
+		"<Control-g>":            "goToLine",
+		"<Control-f>":            "find",
+		"<Control-h>":            "replace",
+		"<Control-Shift-F>":      "findInFiles",
+		"<Control-z>":            "undo",
+		"<Control-y>":            "redo",
+		"<Control-Shift-T>":      "toggleTypewriter",
+		"<Alt-z>":                "toggleWordWrap",
+		"<Control-Shift-P>":      "commandPalette",
+		"<F12>":                  "goToDefinition",
+		"<F1>":                   "showDocumentation",
+		"<Control-F2>":           "toggleBookmark",
+		"<F2>":                   "nextBookmark",
+		"<Shift-F2>":             "previousBookmark",
+		"<Control-braceleft>":    "toggleFold",
+		"<Alt-Return>":           "convertNumber",
+		"<Control-bracketright>": "matchBracket",
+		"<Control-slash>":        "toggleComment",
+		"<Control-question>":     "toggleBlockComment",
+		"<Alt-q>":                "reflowComment",
+		"<Control-Key-1>":        "focusEditor",
+		"<Control-Key-2>":        "focusConsole",
+		"<Control-Key-3>":        "focusOutline",
+		"<Control-Key-4>":        "focusFindResults",
+		"<F6>":                   "focusNext",
+		"<Shift-F6>":             "focusPrevious",
+		"<Control-backslash>":    "splitSideBySide",
+		"<Control-Shift-bar>":    "splitStacked",
+		"<Control-Tab>":          "otherPane",
+		"<Control-Alt-b>":        "toggleLeftSidebar",
+		"<Control-j>":            "toggleBottomPanel",
+	}
+}
+
+// vimKeys returns key bindings following Vim where it has a Ctrl key for
+// the action, with the Go tools on function keys. Editing stays modeless.
+func vimKeys() map[string]string {
+	return map[string]string{
+		"<Control-s>":            "save",
+		"<Control-q>":            "quit",
+		"<Control-z>":            "undo",
+		"<Control-r>":            "redo",
+		"<Control-g>":            "goToLine",
+		"<Control-bracketright>": "goToDefinition",
+		"<Control-p>":            "commandPalette",
+		"<Control-slash>":        "toggleComment",
+		"<Control-question>":     "toggleBlockComment",
+		"<F5>":                   "run",
+		"<F6>":                   "test",
+		"<F7>":                   "build",
+		"<F8>":                   "lint",
+		"<Control-w>w":           "focusNext",
+		"<Control-w>W":           "focusPrevious",
+		"<Control-w>v":           "splitSideBySide",
+		"<Control-w>s":           "splitStacked",
+		"<Control-w>c":           "closePane",
+		"<Control-w>p":           "otherPane",
+		"<Control-w>L":           "moveToOtherPane",
+		"<Control-w>equal":       "resetLayout",
+		"<Control-n>":            "complete",
+	}
+}
+
+// emacsKeys returns Emacs style key bindings. Plain Ctrl keys are left to
+// the text widget, whose class bindings already move the cursor the Emacs
+// way (Ctrl+F, Ctrl+B, Ctrl+N, Ctrl+P, Ctrl+A, Ctrl+E, Ctrl+K...).
+func emacsKeys() map[string]string {
+	return map[string]string{
+		"<Control-x><Control-f>":         "open",
+		"<Control-x><Control-s>":         "save",
+		"<Control-x><Control-w>":         "saveAs",
+		"<Control-x>k":                   "close",
+		"<Control-x><Control-c>":         "quit",
+		"<Control-slash>":                "undo",
+		"<Control-question>":             "redo",
+		"<Control-s>":                    "find",
+		"<Alt-percent>":                  "replace",
+		"<Alt-g>g":                       "goToLine",
+		"<Alt-period>":                   "goToDefinition",
+		"<Control-Alt-n>":                "matchBracket",
+		"<Alt-x>":                        "commandPalette",
+		"<Alt-semicolon>":                "toggleComment",
+		"<Control-x><Control-semicolon>": "toggleBlockComment",
+		"<Alt-q>":                        "reflowComment",
+		"<Control-c><Control-b>":         "build",
+		"<Control-c><Control-r>":         "run",
+		"<Control-c><Control-t>":         "test",
+		"<Control-c><Control-k>":         "stop",
+		"<Control-x>o":                   "focusNext",
+		"<Control-x>2":                   "splitStacked",
+		"<Control-x>3":                   "splitSideBySide",
+		"<Control-x>0":                   "closePane",
+		"<Control-x>plus":                "resetLayout",
+		"<Control-x>b":                   "switchFile",
+		"<Alt-slash>":                    "complete",
+	}
+}
+
+// keysPath returns the absolute path of the key bindings file.
+func keysPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, keysFileName), nil
+}
+
+// loadKeys returns the key bindings of the named preset, completed by the
+// shortcuts of the commands on keys the preset leaves free, and overridden
+// by the keys file, a JSON object mapping event sequences to action names, e.g.
+//
+//	{"<Control-w>": "close", "<Control-t>": ""}
+//
+// An empty action removes a preset binding. Invalid entries are skipped
+// and reported in the returned error; the rest still apply.
+func loadKeys(commands *commandRegistry, preset string) (map[string]string, error) {
+	presetKeys, ok := keyPresets[preset]
+	if !ok {
+		presetKeys = defaultKeys
+	}
+	keys := presetKeys()
+	for _, c := range commands.list {
+		if _, taken := keys[c.shortcut]; c.shortcut != "" && !taken {
+			keys[c.shortcut] = c.id
+		}
+	}
+	path, err := keysPath()
+	if err != nil {
+		return keys, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keys, nil
+		}
+		return keys, err
+	}
+	var user map[string]string
+	if err := json.Unmarshal(data, &user); err != nil {
+		return keys, fmt.Errorf("%s: %v", path, err)
+	}
+
+	var problems []string
+	for seq, name := range user {
+		switch {
+		case !keySeqRe.MatchString(seq):
+			problems = append(problems, fmt.Sprintf("invalid key %q", seq))
+		case name == "":
+			delete(keys, seq)
+		case commands.lookup(name) == nil && !strings.HasPrefix(name, pluginCommandPrefix):
+			problems = append(problems, fmt.Sprintf("unknown action %q for %s", name, seq))
+		default:
+			keys[seq] = name
+		}
+	}
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return keys, fmt.Errorf("%s: %s", path, strings.Join(problems, "; "))
+	}
+	return keys, nil
+}
+
+// bindKeys installs the key bindings. Outside the editor they are handled
+// by the main window; in the editor a dedicated bind tag runs them ahead
+// of the text widget's class bindings, which would otherwise also act on
+// keys such as Ctrl+T (transpose) or Ctrl+O (open line).
+func (i *Ite) bindKeys() {
+	addBindtag(i.editText.Window, keysBindTag, "Text")
+	for seq, name := range i.keys {
+		// Plugin commands are looked up when run, as plugins add them
+		// once started
+		run := func() {
+			if c := i.commands.lookup(name); c != nil {
+				c.run()
+			}
+		}
+		Bind(App, seq, Command(run))
+		Bind(keysBindTag, seq, Command(func(e *Event) {
+			run()
+			e.SetReturnCodeBreak()
+		}))
+	}
+}
+
+// bindFocusKeys installs the panel focus shortcuts in toplevel window w,
+// which doesn't see the bindings of the main window.
+func (i *Ite) bindFocusKeys(w *Window) {
+	for seq, name := range i.keys {
+		if c := i.commands.lookup(name); c != nil && strings.HasPrefix(name, "focus") {
+			Bind(w, seq, Command(c.run))
+		}
+	}
+}
+
+// accelerator returns the menu label of the first key bound to the named
+// action, or "" if it has none.
+func (i *Ite) accelerator(name string) string {
+	var seqs []string
+	for seq, n := range i.keys {
+		if n == name {
+			seqs = append(seqs, seq)
+		}
+	}
+	if len(seqs) == 0 {
+		return ""
+	}
+	slices.Sort(seqs)
+	return keyLabel(seqs[0])
+}
+
+// keyLabel turns an event sequence into the label shown to users, e.g.
+// "<Control-Shift-p>" into "Ctrl+Shift+P" and "<Control-x>k" into
+// "Ctrl+X K".
+func keyLabel(seq string) string {
+	var events []string
+	for seq != "" {
+		event := seq[:1]
+		if seq[0] == '<' {
+			end := strings.IndexByte(seq, '>')
+			if end < 0 {
+				end = len(seq) - 1
+			}
+			event = seq[1:end]
+			seq = seq[end+1:]
+		} else {
+			seq = seq[1:]
+		}
+		events = append(events, eventLabel(event))
+	}
+	return strings.Join(events, " ")
+}
+
+// eventLabel is keyLabel for a single event, given without angle brackets.
+func eventLabel(event string) string {
+	parts := strings.Split(event, "-")
+	parts = slices.DeleteFunc(parts, func(p string) bool { return p == "Key" })
+	for n, p := range parts {
+		switch {
+		case p == "Control":
+			parts[n] = "Ctrl"
+		case keysymLabels[p] != "":
+			parts[n] = keysymLabels[p]
+		case len(p) == 1:
+			parts[n] = strings.ToUpper(p)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// onKeyBindings shows the current key bindings and offers to edit the
+// keys file.
+func (i *Ite) onKeyBindings() {
+	var lines []string
+	for seq, name := range i.keys {
+		title := ""
+		if c := i.commands.lookup(name); c != nil {
+			title = tr(c.title)
+		}
+		lines = append(lines, fmt.Sprintf("%-20s %-18s %s", keyLabel(seq), name, title))
+	}
+	slices.Sort(lines)
+
+	dialog := Toplevel()
+	dialog.WmTitle("Keyboard Shortcuts")
+	text := dialog.Text(textStyle(), Width(70), Height(len(lines)+1))
+	text.Insert("end", strings.Join(lines, "\n"))
+	text.Configure(State("disabled"))
+	Grid(text, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+
+	path, _ := keysPath()
+	note := dialog.TLabel(Txt("Bindings are read from " + path + " at startup."))
+	Grid(note, Row(1), Column(0), Padx(px(10)), Sticky(W))
+
+	btnFrame := dialog.TFrame()
+	Grid(btnFrame, Row(2), Column(0), Pady(px(10)))
+	edit := func() {
+		Destroy(dialog)
+		i.editKeysFile()
+	}
+	closeDialog := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+	Grid(btnFrame.TButton(Txt("Edit Keys File"), Command(edit)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Close"), Command(closeDialog)), Row(0), Column(1), Padx(px(5)))
+	Bind(dialog, "<Escape>", Command(closeDialog))
+}
+
+// editKeysFile opens the keys file in the editor, first writing the
+// current bindings to it if it doesn't exist yet.
+func (i *Ite) editKeysFile() {
+	if !i.promptSaveIfModified() {
+		return
+	}
+	path, err := keysPath()
+	if err != nil {
+		i.showError("Error locating keys file: " + err.Error())
+		return
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		data, _ := json.MarshalIndent(i.keys, "", "\t")
+		if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+			i.showError("Error creating keys file: " + err.Error())
+			return
+		}
+		if err := os.WriteFile(path, append(data, '\n'), configFilePerms); err != nil {
+			i.showError("Error creating keys file: " + err.Error())
+			return
+		}
+	}
+	if err := i.openFile(path); err != nil {
+		i.showError("Error opening file: " + err.Error())
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Large Files
+// -------------------------------------------------------------------------
+
+// Files of largeFileSize bytes or more open in large-file mode: the text
+// is inserted a chunk at a time from idle callbacks, so the window keeps
+// redrawing and answering input while it loads, and the features that
+// scan the whole buffer on every keystroke or timer tick are off:
+// bracket matching, the prose guide, the outline, linked editing, line
+// wrapping, swap files and the saved version history. Saves of these
+// files, and of any buffer of backgroundSaveSize bytes or more, are written
+// in the background while a spinner turns in the status bar.
+const (
+	largeFileSize      = 4 << 20   // Size from which a file opens in large-file mode
+	loadChunkSize      = 256 << 10 // Bytes inserted per idle callback while loading
+	backgroundSaveSize = 1 << 20   // Size from which saves are written in the background
+)
+
+// errStillLoading reports a save attempted before the buffer is complete.
+var errStillLoading = errors.New("the file is still loading")
+
+// bufferLoad is a large file being inserted into an editor in chunks.
+type bufferLoad struct {
+	text   *TextWidget
+	path   string
+	data   string
+	offset int // Bytes of data inserted so far
+}
+
+// setLargeFile turns large-file mode on or off for the active buffer.
+func (i *Ite) setLargeFile(on bool) {
+	i.largeFile = on
+	i.editText.Configure(Wrap(i.editorWrap()))
+}
+
+// editorWrap returns the -wrap option of the active editor: long lines of
+// a large file are not wrapped, as laying them out is slow.
+func (i *Ite) editorWrap() string {
+	if i.largeFile {
+		return "none"
+	}
+	return wrapMode
+}
+
+// openLargeFile starts loading text, the contents of path, into the
+// active editor. The editor stays read-only until the last chunk is in.
+func (i *Ite) openLargeFile(path, text string) {
+	i.setLargeFile(true)
+	i.closeJournal() // Keeping a copy of every saved version is too costly
+	i.currentFile = path
+	i.recordDiskStamp()
+	i.refreshRunProfiles()
+	i.updateTitle()
+	i.refreshOutline()
+
+	l := &bufferLoad{text: i.editText, path: path, data: text}
+	i.loads[i.editText] = l
+	tclEval("%s configure -undo 0", i.editText)
+	i.editText.Configure(State("disabled"))
+	i.loadChunk(l)
+}
+
+// loadChunk inserts the next chunk of l, ending at a line break when
+// there is one, then schedules the following chunk for the next idle
+// time. It does nothing once another file replaced l in its editor.
+func (i *Ite) loadChunk(l *bufferLoad) {
+	if i.loads[l.text] != l {
+		return
+	}
+	end := min(l.offset+loadChunkSize, len(l.data))
+	if end < len(l.data) {
+		if nl := strings.LastIndexByte(l.data[l.offset:end], '\n'); nl >= 0 {
+			end = l.offset + nl + 1
+		} else {
+			for end > l.offset && !utf8.RuneStart(l.data[end]) {
+				end-- // Don't split a character
+			}
+		}
+	}
+	l.text.Configure(State("normal"))
+	l.text.Insert("end", l.data[l.offset:end])
+	l.text.Configure(State("disabled"))
+	l.text.SetModified(false)
+	l.offset = end
+	if l.offset == len(l.data) {
+		i.finishLoad(l)
+		return
+	}
+	if l.text == i.editText {
+		i.showStatusHint(fmt.Sprintf("Loading %s: %d%%", filepath.Base(l.path), 100*l.offset/len(l.data)))
+	}
+	TclAfterIdle(func() { i.loadChunk(l) })
+}
+
+// finishLoad makes the editor of l editable once its file is complete.
+func (i *Ite) finishLoad(l *bufferLoad) {
+	delete(i.loads, l.text)
+	p := i.paneOf(l.text)
+	if p == nil {
+		return
+	}
+	i.withPane(p, func() {
+		i.editText.Configure(State("normal"))
+		tclEval("%s configure -undo 1", i.editText)
+		i.resetUndo()
+		i.protectHeader()
+		i.markDiagnostics()
+		i.editText.SetModified(false)
+		i.refreshCursorState()
+	})
+	if l.text == i.editText {
+		i.showStatusHint(fmt.Sprintf("Loaded %s (%d MB)", filepath.Base(l.path), len(l.data)>>20))
+	}
+}
+
+// cancelLoad stops loading a large file into the active editor, before
+// the editor gets another buffer.
+func (i *Ite) cancelLoad() {
+	if i.loads[i.editText] == nil {
+		return
+	}
+	delete(i.loads, i.editText)
+	i.editText.Configure(State("normal"))
+	tclEval("%s configure -undo 1", i.editText)
+}
+
+// loading reports whether the active buffer is still loading.
+func (i *Ite) loading() bool {
+	return i.loads[i.editText] != nil
+}
+
+// paneOf returns the pane of the editor text, or nil once it's gone.
+func (i *Ite) paneOf(text *TextWidget) *editorPane {
+	for _, p := range i.panes {
+		if p.text == text || (p == i.active && i.editText == text) {
+			return p
+		}
+	}
+	return nil
+}
+
+// backgroundSave is a write of writeInBackground.
+type backgroundSave struct {
+	text    *TextWidget   // Editor whose buffer is saved
+	content string        // Text saved
+	written chan struct{} // Closed once the file is written
+	err     error         // Error of the write, set before written is closed
+}
+
+// writeInBackground saves data, the encoding of content, to the current
+// file from a goroutine. The buffer counts as saved right away; should the
+// write fail, it is marked modified again and the error shown.
+func (i *Ite) writeInBackground(data []byte, content string) error {
+	path := i.currentFile
+	if i.saving[path] != nil {
+		return errors.New("a save of " + filepath.Base(path) + " is still in progress")
+	}
+	s := &backgroundSave{text: i.editText, content: content, written: make(chan struct{})}
+	i.saving[path] = s
+	i.diskStamp = fileStamp{} // The write isn't a change made by another program
+	i.editText.SetModified(false)
+	i.removeSwap()
+	i.updateTitle()
+	i.refreshCursorState()
+	i.showStatusHint("Saving " + filepath.Base(path) + "...")
+	i.updateJobsSegment()
+
+	go func() {
+		s.err = writeFileAtomic(path, data, defaultFilePerms)
+		close(s.written)
+		i.Dispatch(func() { i.finishSave(path, s) })
+	}()
+	return nil
+}
+
+// finishSaves waits for the writes of writeInBackground in progress and
+// finishes them, reporting whether all succeeded.
+func (i *Ite) finishSaves() bool {
+	ok := true
+	for _, path := range slices.Sorted(maps.Keys(i.saving)) {
+		s := i.saving[path]
+		<-s.written
+		ok = ok && s.err == nil
+		i.finishSave(path, s)
+	}
+	return ok
+}
+
+// finishSave updates the buffer that was being saved to path by
+// writeInBackground, if it still holds that file. It does nothing if
+// the save s was finished already.
+func (i *Ite) finishSave(path string, s *backgroundSave) {
+	if i.saving[path] != s {
+		return
+	}
+	delete(i.saving, path)
+	text, content, err := s.text, s.content, s.err
+	if p := i.paneOf(text); p != nil {
+		i.withPane(p, func() {
+			if i.currentFile != path {
+				return
+			}
+			if err != nil {
+				i.editText.SetModified(true)
+				i.refreshCursorState()
+				return
+			}
+			i.recordDiskStamp()
+			if !i.largeFile {
+				i.recordJournal(content)
+				i.refreshOutline()
+				i.storeBookmarks()
+				i.refreshGitBase(path)
+			}
+			i.refreshCursorState()
+		})
+	}
+	if err != nil {
+		i.showError("Error saving file: " + err.Error())
+		return
+	}
+	i.showStatusHint("Saved " + filepath.Base(path))
+	i.notifyPlugins("save", path)
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strconv"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Layout Manager
+// -------------------------------------------------------------------------
+
+// Docking regions around the editor. Panels such as the outline or the
+// consoles dock into one of them; the user resizes them by dragging the
+// sashes and can collapse each one.
+const (
+	regionLeft   = "left"   // Sidebar left of the editor
+	regionRight  = "right"  // Panel right of the editor
+	regionBottom = "bottom" // Panel below the editor and the side regions
+)
+
+const minRegionSize = 60 // Smallest width or height restored for a region, in pixels at 96 DPI
+
+// defaultRegionSizes are the initial sizes of the regions, in pixels at
+// 96 DPI.
+var defaultRegionSizes = map[string]int{
+	regionLeft:   220,
+	regionRight:  600,
+	regionBottom: 200,
+}
+
+// regionConfig is the persisted state of a region.
+type regionConfig struct {
+	Size      int  `json:"size"`      // Width, or height for the bottom region, in pixels
+	Collapsed bool `json:"collapsed"` // Hidden by the user
+}
+
+// region is a collapsible area of the main window holding docked panels.
+type region struct {
+	name   string
+	frame  *TFrameWidget
+	panels []*dockedPanel // Top to bottom
+	menu   *VariableOpt   // Checkbutton state of the View menu entry
+}
+
+// dockedPanel is a panel placed in a region.
+type dockedPanel struct {
+	w     *Window
+	shown bool
+}
+
+// makeRegions creates the paned windows of the layout. It must run before
+// the panels are created, so they stack above the panes they dock into.
+func (i *Ite) makeRegions() {
+	i.mainPane = TPanedwindow(Orient("vertical"))
+	i.centerPane = i.mainPane.TPanedwindow(Orient("horizontal"))
+	i.editorRegion = i.centerPane.TFrame()
+	GridRowConfigure(i.editorRegion, 0, Weight(1))
+	GridColumnConfigure(i.editorRegion, 0, Weight(1))
+	i.regions = make(map[string]*region)
+	for _, name := range []string{regionLeft, regionRight, regionBottom} {
+		parent := i.centerPane.Window
+		if name == regionBottom {
+			parent = i.mainPane.Window
+		}
+		r := &region{name: name, frame: parent.TFrame()}
+		GridColumnConfigure(r.frame, 0, Weight(1))
+		i.regions[name] = r
+	}
+}
+
+// regionConfig returns the persisted state of the named region.
+func (i *Ite) regionConfig(name string) regionConfig {
+	rc, ok := i.config.Regions[name]
+	if !ok || rc.Size < px(minRegionSize) {
+		rc.Size = px(defaultRegionSizes[name])
+	}
+	return rc
+}
+
+// dock adds panel w to the named region, below the panels already there.
+func (i *Ite) dock(name string, w *Window, shown bool) {
+	r := i.regions[name]
+	r.panels = append(r.panels, &dockedPanel{w: w, shown: shown})
+}
+
+// setPanelShown shows or hides a docked panel. A region without visible
+// panels collapses.
+func (i *Ite) setPanelShown(w *Window, shown bool) {
+	for _, r := range i.regions {
+		for _, p := range r.panels {
+			if p.w == w {
+				p.shown = shown
+			}
+		}
+	}
+	i.arrangeRegions()
+}
+
+// regionVisible reports whether r should take space in the window: it
+// isn't collapsed and has a panel to show.
+func (i *Ite) regionVisible(r *region) bool {
+	if i.regionConfig(r.name).Collapsed {
+		return false
+	}
+	for _, p := range r.panels {
+		if p.shown {
+			return true
+		}
+	}
+	return false
+}
+
+// arrangeRegions places the editor and the visible regions in the paned
+// windows and restores their sizes.
+func (i *Ite) arrangeRegions() {
+	i.recordRegionSizes()
+	for _, pane := range []*TPanedwindowWidget{i.mainPane, i.centerPane} {
+		tclEval("foreach p [%[1]s panes] {%[1]s forget $p}", pane)
+	}
+
+	i.placed = make(map[string]bool)
+	for name, r := range i.regions {
+		i.placed[name] = i.regionVisible(r)
+	}
+	if i.placed[regionLeft] {
+		i.centerPane.Add(i.regions[regionLeft].frame.Window, Weight(0))
+	}
+	i.centerPane.Add(i.editorRegion.Window, Weight(1))
+	if i.placed[regionRight] {
+		i.centerPane.Add(i.regions[regionRight].frame.Window, Weight(1))
+	}
+	i.mainPane.Add(i.centerPane.Window, Weight(1))
+	if i.placed[regionBottom] {
+		i.mainPane.Add(i.regions[regionBottom].frame.Window, Weight(0))
+	}
+
+	for _, r := range i.regions {
+		row := 0
+		for _, p := range r.panels {
+			if p.shown {
+				Grid(p.w, In(r.frame), Row(row), Column(0), Sticky(NEWS))
+				GridRowConfigure(r.frame, row, Weight(1))
+				row++
+			} else {
+				GridRemove(p.w)
+			}
+		}
+		if r.menu != nil {
+			r.menu.Set(checkValue(!i.regionConfig(r.name).Collapsed))
+		}
+	}
+	TclAfterIdle(i.restoreRegionSizes)
+}
+
+// restoreRegionSizes moves the sashes to give each visible region its
+// persisted size.
+func (i *Ite) restoreRegionSizes() {
+	tclEval("update idletasks")
+	width := winfoInt(WinfoWidth(i.centerPane.Window))
+	height := winfoInt(WinfoHeight(i.mainPane.Window))
+	if width <= 1 || height <= 1 {
+		return // Not mapped yet
+	}
+	sash := 0
+	if i.placed[regionLeft] {
+		tclEval("%s sashpos %d %d", i.centerPane, sash, i.regionConfig(regionLeft).Size)
+		sash++
+	}
+	if i.placed[regionRight] {
+		tclEval("%s sashpos %d %d", i.centerPane, sash, width-i.regionConfig(regionRight).Size)
+	}
+	if i.placed[regionBottom] {
+		tclEval("%s sashpos 0 %d", i.mainPane, height-i.regionConfig(regionBottom).Size)
+	}
+}
+
+// recordRegionSizes stores the current sizes of the placed regions in the
+// settings, so they survive collapsing and restarts.
+func (i *Ite) recordRegionSizes() {
+	if i.placed == nil {
+		return // Not arranged yet
+	}
+	width := winfoInt(WinfoWidth(i.centerPane.Window))
+	height := winfoInt(WinfoHeight(i.mainPane.Window))
+	if width <= 1 || height <= 1 {
+		return
+	}
+	set := func(name string, size int) {
+		if size < px(minRegionSize) {
+			return
+		}
+		rc := i.regionConfig(name)
+		rc.Size = size
+		if i.config.Regions == nil {
+			i.config.Regions = make(map[string]regionConfig)
+		}
+		i.config.Regions[name] = rc
+	}
+	sash := 0
+	if i.placed[regionLeft] {
+		set(regionLeft, winfoInt(tclEval("%s sashpos %d", i.centerPane, sash)))
+		sash++
+	}
+	if i.placed[regionRight] {
+		set(regionRight, width-winfoInt(tclEval("%s sashpos %d", i.centerPane, sash)))
+	}
+	if i.placed[regionBottom] {
+		set(regionBottom, height-winfoInt(tclEval("%s sashpos 0", i.mainPane)))
+	}
+}
+
+// onToggleRegion collapses or expands the named region and persists the
+// choice.
+func (i *Ite) onToggleRegion(name string) {
+	rc := i.regionConfig(name)
+	rc.Collapsed = !rc.Collapsed
+	if i.config.Regions == nil {
+		i.config.Regions = make(map[string]regionConfig)
+	}
+	i.config.Regions[name] = rc
+	i.saveConfig()
+	i.arrangeRegions()
+}
+
+// onResetLayout brings the regions back to their default sizes, expanded,
+// and gives the panes of a split view the same size.
+func (i *Ite) onResetLayout() {
+	i.placed = nil // Drop the current sizes instead of recording them
+	i.config.Regions = nil
+	i.saveConfig()
+	i.arrangeRegions()
+	if len(i.panes) > 1 {
+		i.arrangePanes(i.splitOrient)
+	}
+}
+
+// winfoInt converts a pixel count reported by Tk, 0 if malformed.
+func winfoInt(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// -------------------------------------------------------------------------
+// Line Operations
+// -------------------------------------------------------------------------
+
+// The line commands work on the lines of the selection, or the line of
+// the cursor, each as a single undo step: moving them up and down past
+// their neighbor, duplicating them below, deleting them, joining them and
+// sorting them.
+
+// hasSelection reports whether text is selected.
+func (i *Ite) hasSelection() bool {
+	return len(i.editText.TagRanges("sel")) >= 2
+}
+
+// lastLine returns the number of the last line of the buffer.
+func (i *Ite) lastLine() int {
+	n, _ := parseIndex(i.editText.Index("end -1c"))
+	return n
+}
+
+// lineSpan returns the text of lines first to last, without the final
+// newline.
+func (i *Ite) lineSpan(first, last int) string {
+	return i.editText.Get(fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.end", last))[0]
+}
+
+// replaceLines replaces lines first to last with text, as one undo step,
+// unless they are read-only. It reports whether it did.
+func (i *Ite) replaceLines(first, last int, text string) bool {
+	from, to := fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.end", last)
+	if i.blockProtected(from, to) {
+		return false
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, text)
+	})
+	return true
+}
+
+// selectLines selects lines first to last when selected is set, and puts
+// the cursor at line and column col.
+func (i *Ite) selectLines(first, last int, selected bool, line, col int) {
+	i.editText.TagRemove("sel", "1.0", "end")
+	if selected {
+		i.editText.TagAdd("sel", fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.0 +1 lines", last))
+	}
+	i.editText.MarkSet("insert", fmt.Sprintf("%d.%d", line, col))
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// onMoveLinesUp moves the lines up past the line above them.
+func (i *Ite) onMoveLinesUp() { i.moveLines(-1) }
+
+// onMoveLinesDown moves the lines down past the line below them.
+func (i *Ite) onMoveLinesDown() { i.moveLines(1) }
+
+// moveLines moves the lines past their neighbor in direction dir, 1 or -1.
+func (i *Ite) moveLines(dir int) {
+	first, last := i.selectedLines()
+	selected := i.hasSelection()
+	if (dir < 0 && first == 1) || (dir > 0 && last >= i.lastLine()) {
+		return
+	}
+	line, col := parseIndex(i.editText.Index("insert"))
+	block := i.lineSpan(first, last)
+	var ok bool
+	if dir < 0 {
+		ok = i.replaceLines(first-1, last, block+"\n"+i.lineSpan(first-1, first-1))
+	} else {
+		ok = i.replaceLines(first, last+1, i.lineSpan(last+1, last+1)+"\n"+block)
+	}
+	if ok {
+		i.selectLines(first+dir, last+dir, selected, line+dir, col)
+	}
+}
+
+// onDuplicateLines inserts a copy of the lines below them and moves the
+// cursor into the copy.
+func (i *Ite) onDuplicateLines() {
+	first, last := i.selectedLines()
+	selected := i.hasSelection()
+	line, col := parseIndex(i.editText.Index("insert"))
+	block := i.lineSpan(first, last)
+	if i.replaceLines(first, last, block+"\n"+block) {
+		n := last - first + 1
+		i.selectLines(first+n, last+n, selected, line+n, col)
+	}
+}
+
+// onDeleteLines deletes the lines, newline included.
+func (i *Ite) onDeleteLines() {
+	first, last := i.selectedLines()
+	from, to := fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.0", last+1)
+	if last >= i.lastLine() {
+		to = fmt.Sprintf("%d.end", last)
+		if first > 1 {
+			from = fmt.Sprintf("%d.end", first-1) // Take the newline before
+		}
+	}
+	if i.blockProtected(from, to) {
+		return
+	}
+	i.editGroup(func() { i.editText.Delete(from, to) })
+	line := min(first, i.lastLine())
+	i.selectLines(line, line, false, line, 0)
+}
+
+// onJoinLines joins the lines of the selection, or the line of the cursor
+// and the next one, separated by a space instead of the newline and the
+// indentation of the following line.
+func (i *Ite) onJoinLines() {
+	first, last := i.selectedLines()
+	if last == first {
+		last++
+	}
+	if last > i.lastLine() {
+		return
+	}
+	lines := strings.Split(i.lineSpan(first, last), "\n")
+	joined := lines[0]
+	col := 0
+	for _, l := range lines[1:] {
+		l = strings.TrimLeft(l, " \t")
+		joined = strings.TrimRight(joined, " \t")
+		col = len([]rune(joined))
+		if l != "" && joined != "" {
+			joined += " "
+		}
+		joined += l
+	}
+	if i.replaceLines(first, last, joined) {
+		i.selectLines(first, first, false, first, col)
+	}
+}
+
+// onSortLines sorts the selected lines.
+func (i *Ite) onSortLines() {
+	first, last := i.selectedLines()
+	selected := i.hasSelection()
+	if !selected || first == last {
+		i.showStatusHint("Select the lines to sort")
+		return
+	}
+	lines := strings.Split(i.lineSpan(first, last), "\n")
+	if slices.IsSorted(lines) {
+		i.showStatusHint("The lines are already sorted")
+		return
+	}
+	slices.Sort(lines)
+	if i.replaceLines(first, last, strings.Join(lines, "\n")) {
+		i.selectLines(first, last, true, last+1, 0)
+	}
+}
+
+// trimTrailingBlanks returns text without the spaces and tabs ending its
+// lines.
+func trimTrailingBlanks(text string) string {
+	lines := strings.Split(text, "\n")
+	for n, line := range lines {
+		lines[n] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// onTrimTrailingWhitespace deletes the blanks ending the selected lines,
+// or every line, as one undo step, but in read-only regions.
+func (i *Ite) onTrimTrailingWhitespace() {
+	first, last := i.selectedLines()
+	if !i.hasSelection() {
+		first = 1
+		last, _ = parseIndex(i.editText.Index("end-1c"))
+	}
+	lines := strings.Split(i.lineSpan(first, last), "\n")
+	trimmed := 0
+	i.editGroup(func() {
+		for n, line := range lines {
+			kept := trimTrailingBlanks(line)
+			if kept == line {
+				continue
+			}
+			from := fmt.Sprintf("%d.%d", first+n, utf8.RuneCountInString(kept))
+			to := fmt.Sprintf("%d.end", first+n)
+			if i.isProtected(from, to) {
+				continue
+			}
+			i.editText.Delete(from, to)
+			trimmed++
+		}
+	})
+	i.refreshCursorState()
+	i.showStatusHint(fmt.Sprintf("%d lines trimmed", trimmed))
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Linked Editing
+// -------------------------------------------------------------------------
+
+const tagLinked = "linked" // Editor tag marking the occurrences being edited
+
+// linkedEdit is an active linked editing session: every occurrence of a
+// local identifier is delimited by a pair of marks, and whatever is typed
+// into the first one is mirrored into the others.
+//
+// gopls does not expose linkedEditingRange on its command line, so the
+// occurrences are found by resolving the identifier in the parsed buffer,
+// which also works on unsaved code.
+type linkedEdit struct {
+	count int    // Number of occurrences, 0 when no session is active
+	text  string // Identifier text last mirrored
+}
+
+// bindLinkedEditing installs the bindings that start and end sessions.
+func (i *Ite) bindLinkedEditing() {
+	Bind(i.editText, "<KeyPress>", Command(i.onLinkedKeyPress))
+	Bind(i.editText, "<Button-1>", Command(i.endLinkedEdit))
+	Bind(i.editText, "<Escape>", Command(i.endLinkedEdit))
+}
+
+// onToggleLinkedEditing switches linked editing on or off and persists the
+// choice.
+func (i *Ite) onToggleLinkedEditing() {
+	i.config.LinkedEditing = !i.config.LinkedEditing
+	i.linkedVar.Set(checkValue(i.config.LinkedEditing))
+	i.saveConfig()
+	if !i.config.LinkedEditing {
+		i.endLinkedEdit()
+	}
+}
+
+// onLinkedKeyPress starts a session when the user begins to change an
+// identifier by typing or deleting.
+func (i *Ite) onLinkedKeyPress(e *Event) {
+	if !i.config.LinkedEditing || i.linked.count > 0 || e.State&ModifierControl != 0 || i.composing || i.largeFile {
+		return
+	}
+	kind, _ := classifyKey(e.Keysym, i.isWordChar)
+	if kind != editWord && kind != editDelete {
+		return
+	}
+	if len(i.editText.TagRanges("sel")) > 0 {
+		return
+	}
+	i.startLinkedEdit()
+}
+
+// startLinkedEdit looks up the local identifier at the cursor and, if it
+// occurs more than once in its function, opens a session on it.
+func (i *Ite) startLinkedEdit() {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, "", i.editText.Text(), parser.AllErrors)
+	if f == nil {
+		return
+	}
+	file := fset.File(f.Pos())
+	line, col := parseIndex(i.editText.Index("insert"))
+	if line > file.LineCount() {
+		return
+	}
+	runes := []rune(lineText(i.editText, line))
+	pos := file.LineStart(line) + token.Pos(len(string(runes[:min(col, len(runes))])))
+
+	ids := identOccurrences(f, pos)
+	if len(ids) < 2 {
+		return
+	}
+	for n, id := range ids {
+		p := fset.Position(id.Pos())
+		text := lineText(i.editText, p.Line)
+		start := fmt.Sprintf("%d.%d", p.Line, runeColumn(text, p.Column-1))
+		end := fmt.Sprintf("%d.%d", p.Line, runeColumn(text, p.Column-1+len(id.Name)))
+		i.editText.MarkSet(linkedMark("Start", n), start)
+		i.editText.MarkGravity(linkedMark("Start", n), "left")
+		i.editText.MarkSet(linkedMark("End", n), end)
+		i.editText.TagAdd(tagLinked, start, end)
+	}
+	i.linked = linkedEdit{count: len(ids), text: ids[0].Name}
+}
+
+// syncLinkedEdit copies the edited occurrence into all the others. The
+// session ends once the cursor leaves the occurrence or its text is no
+// longer an identifier.
+func (i *Ite) syncLinkedEdit() {
+	if i.linked.count == 0 {
+		return
+	}
+	start, end := linkedMark("Start", 0), linkedMark("End", 0)
+	text := i.editText.Get(start, end)[0]
+	if indexLess(i.editText, "insert", start) || indexLess(i.editText, end, "insert") ||
+		!token.IsIdentifier(text) {
+		i.endLinkedEdit()
+		return
+	}
+	if text == i.linked.text {
+		return
+	}
+	for n := 1; n < i.linked.count; n++ {
+		start, end := linkedMark("Start", n), linkedMark("End", n)
+		i.editText.Delete(start, end)
+		i.editText.Insert(start, text, tagLinked)
+	}
+	i.editText.TagAdd(tagLinked, start, end)
+	i.linked.text = text
+}
+
+// endLinkedEdit closes the current session, if any.
+func (i *Ite) endLinkedEdit() {
+	for n := range i.linked.count {
+		i.editText.MarkUnset(linkedMark("Start", n), linkedMark("End", n))
+	}
+	i.editText.TagRemove(tagLinked, "1.0", "end")
+	i.linked = linkedEdit{}
+}
+
+// linkedMark returns the name of the start or end mark of occurrence n.
+func linkedMark(side string, n int) string {
+	return fmt.Sprintf("linked%s%d", side, n)
+}
+
+// identOccurrences returns the identifier at pos followed by its other
+// occurrences, provided it is declared inside the innermost function
+// enclosing pos. Package level names are left alone: renaming them in a
+// single function would break the code.
+func identOccurrences(f *ast.File, pos token.Pos) []*ast.Ident {
+	var ident *ast.Ident
+	var fn ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil || pos < n.Pos() || pos > n.End() {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.Ident:
+			ident = n
+		case *ast.FuncDecl, *ast.FuncLit:
+			fn = n // Inner functions are visited later
+		}
+		return true
+	})
+	if ident == nil || ident.Obj == nil || ident.Obj.Kind == ast.Fun || fn == nil {
+		return nil
+	}
+	if decl := ident.Obj.Pos(); decl < fn.Pos() || decl >= fn.End() {
+		return nil
+	}
+
+	ids := []*ast.Ident{ident}
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id != ident && id.Obj == ident.Obj {
+			ids = append(ids, id)
+		}
+		return true
+	})
+	return ids
+}
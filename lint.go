@@ -0,0 +1,200 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Lint (go vet and staticcheck)
+// -------------------------------------------------------------------------
+
+const (
+	tagDiagnostic = "diagnostic" // Editor tag underlining lines with diagnostics
+	statusLinting = "Linting...\n"
+)
+
+// diagnosticRe matches a "file.go:line:col: message" diagnostic as printed
+// by go vet and staticcheck.
+var diagnosticRe = regexp.MustCompile(`^((?:[A-Za-z]:)?[^\s:]+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// diagnostic is a problem reported by a linter or, with a kind, a note
+// of the compiler about its optimizations.
+type diagnostic struct {
+	location
+	message string
+	kind    string // "" for a problem, noteEscape, noteInline or noteTypo for a note
+}
+
+// lintDecoder passes linter output through, extracting the diagnostics so
+// they can be marked in the editor, and counts them for the summary.
+type lintDecoder struct {
+	dir   string // Directory the linters run in, for relative paths
+	count int
+}
+
+func (d *lintDecoder) decode(line string) []consoleMsg {
+	m := diagnosticRe.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		return []consoleMsg{{text: line}}
+	}
+	d.count++
+	diag := &diagnostic{location: location{path: m[1], col: 1}, message: m[4]}
+	diag.line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		diag.col, _ = strconv.Atoi(m[3])
+	}
+	if !filepath.IsAbs(diag.path) {
+		diag.path = filepath.Join(d.dir, diag.path)
+	}
+	return []consoleMsg{{text: line, diag: diag}}
+}
+
+func (d *lintDecoder) summary() []consoleMsg {
+	if d.count == 0 {
+		return []consoleMsg{{text: "\nNo problems found\n", tag: tagTestPass}}
+	}
+	return []consoleMsg{{text: fmt.Sprintf("\n%d problems found\n", d.count), tag: tagTestFail}}
+}
+
+// onLint runs `go vet ./...`, followed by `staticcheck ./...` when it is
+// installed, on the module of the current file. Diagnostics are listed in
+// the console, where they link to the source, and underlined in the editor.
+func (i *Ite) onLint() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	steps := [][]string{{"go", "vet", "./..."}}
+	if staticcheck, err := exec.LookPath("staticcheck"); err == nil {
+		steps = append(steps, []string{staticcheck, "./..."})
+	}
+	i.clearDiagnostics("")
+	i.runCommands(i.console(consoleLint), steps, statusLinting, &lintDecoder{dir: i.projectDir()})
+}
+
+// addDiagnostic records d and underlines it if it is in the current file.
+func (i *Ite) addDiagnostic(d diagnostic) {
+	i.diagnostics = append(i.diagnostics, d)
+	i.markDiagnostic(d)
+	i.scheduleVirtualText()
+}
+
+// markDiagnostics underlines the lines of the current file that have
+// diagnostics. It must run again after the editor is cleared.
+func (i *Ite) markDiagnostics() {
+	for _, d := range i.diagnostics {
+		i.markDiagnostic(d)
+	}
+}
+
+func (i *Ite) markDiagnostic(d diagnostic) {
+	if !samePath(d.path, i.currentFile) {
+		return
+	}
+	i.editText.TagAdd(diagnosticTag(d.kind), fmt.Sprintf("%d.0", d.line), fmt.Sprintf("%d.end", d.line))
+}
+
+// clearDiagnostics forgets the diagnostics of kinds, "" for the problems,
+// and removes their marks.
+func (i *Ite) clearDiagnostics(kinds ...string) {
+	i.diagnostics = slices.DeleteFunc(i.diagnostics, func(d diagnostic) bool {
+		return slices.Contains(kinds, d.kind)
+	})
+	for _, kind := range kinds {
+		i.editText.TagRemove(diagnosticTag(kind), "1.0", "end")
+	}
+	i.scheduleVirtualText()
+}
+
+// diagnosticLens returns the messages of the problems of diags in the
+// lines first to last of path, as virtual text: the first one of a line
+// and how many more it has.
+func diagnosticLens(diags []diagnostic, path string, first, last int) []virtualText {
+	var lens []virtualText
+	more := make(map[int]int)
+	for _, d := range diags {
+		if d.kind != "" || d.line < first || d.line > last || !samePath(d.path, path) {
+			continue
+		}
+		if _, seen := more[d.line]; seen {
+			more[d.line]++
+			continue
+		}
+		more[d.line] = 0
+		lens = append(lens, virtualText{line: d.line, text: d.message, color: theme.Error})
+	}
+	for n, v := range lens {
+		if more[v.line] > 0 {
+			lens[n].text = fmt.Sprintf("%s (+%d)", v.text, more[v.line])
+		}
+	}
+	return lens
+}
+
+// inlineDiagnostics is the virtual text source of the problems, when
+// Inline Diagnostics is on.
+func (i *Ite) inlineDiagnostics(path string, first, last int) []virtualText {
+	if !i.config.InlineDiagnostics {
+		return nil
+	}
+	return diagnosticLens(i.diagnostics, path, first, last)
+}
+
+// onToggleInlineDiagnostics shows or hides the messages of the problems
+// at the end of their lines, and persists the choice.
+func (i *Ite) onToggleInlineDiagnostics() {
+	i.config.InlineDiagnostics = !i.config.InlineDiagnostics
+	i.lensVar.Set(checkValue(i.config.InlineDiagnostics))
+	i.saveConfig()
+	i.scheduleVirtualText()
+}
+
+// diagnosticTag returns the editor tag marking diagnostics of kind.
+func diagnosticTag(kind string) string {
+	switch kind {
+	case noteEscape:
+		return tagEscapeNote
+	case noteInline:
+		return tagInlineNote
+	case noteTypo:
+		return tagTypoNote
+	}
+	return tagDiagnostic
+}
+
+// configureDiagnosticTag styles the diagnostic underlines and shows the
+// messages of the diagnostics of a line when the mouse rests on it.
+func (i *Ite) configureDiagnosticTag() {
+	i.editText.TagConfigure(tagDiagnostic, Underline(1), Underlinefg(theme.Error))
+	i.editText.TagConfigure(tagEscapeNote, Underline(1), Underlinefg(theme.Warning))
+	i.editText.TagConfigure(tagInlineNote, Underline(1), Underlinefg(theme.Success))
+	i.editText.TagConfigure(tagTypoNote, Underline(1), Underlinefg(theme.Muted))
+	for _, kind := range []string{"", noteEscape, noteInline, noteTypo} {
+		i.editText.TagBind(diagnosticTag(kind), "<Enter>", func() {
+			line, _ := parseIndex(i.editText.Index("current"))
+			var msgs []string
+			for _, d := range i.diagnostics {
+				if d.line == line && d.kind == kind && samePath(d.path, i.currentFile) {
+					msgs = append(msgs, d.message)
+				}
+			}
+			if len(msgs) > 0 {
+				i.showStatusHint(strings.Join(msgs, "; "))
+			}
+		})
+	}
+}
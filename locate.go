@@ -0,0 +1,130 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Locate in Source
+// -------------------------------------------------------------------------
+
+// In Locate in Source mode, moving the cursor of a console to a line with
+// a source location, by click or by keys, shows that line in the editor
+// while the focus stays in the console. The function line of a stack
+// frame, "main.run(...)", locates its file line below. Next and Previous
+// Stack Frame walk the frames of a goroutine trace in either mode.
+
+const (
+	tagLocated    = "located"   // Editor tag of the line located from the console
+	locateBindTag = "IteLocate" // Bind tag of the console lines selected
+)
+
+// stackFrameRe matches the file line of a frame of a stack trace, as in
+// "\t/src/main.go:42 +0x1f", after the timestamp of the console, if any.
+var stackFrameRe = regexp.MustCompile(`^(?:\[\d\d:\d\d:\d\d\] )?\s+((?:[A-Za-z]:)?[^\s:]+\.go):(\d+)(?: \+0x[0-9a-f]+)?\s*$`)
+
+// bindLocate locates the line the cursor of console c moves to.
+func (i *Ite) bindLocate(c *console) {
+	addBindtag(c.text.Window, locateBindTag, "")
+	locate := func() {
+		if i.config.LocateInSource {
+			c := i.selectedConsole() // The tag is shared by the consoles
+			line, _ := parseIndex(c.text.Index("insert"))
+			i.locateConsoleLine(c, line, true)
+		}
+	}
+	Bind(locateBindTag, "<ButtonRelease-1>", Command(locate))
+	Bind(locateBindTag, "<KeyRelease-Up>", Command(locate))
+	Bind(locateBindTag, "<KeyRelease-Down>", Command(locate))
+}
+
+// onToggleLocateInSource switches Locate in Source on or off and persists
+// the choice.
+func (i *Ite) onToggleLocateInSource() {
+	i.config.LocateInSource = !i.config.LocateInSource
+	i.consoleLocate.Configure(Variable(checkValue(i.config.LocateInSource)))
+	i.saveConfig()
+	if !i.config.LocateInSource {
+		i.editText.TagRemove(tagLocated, "1.0", "end")
+	}
+}
+
+// consoleLineLocation returns the source location of line of console c,
+// or, on the function line of a stack frame, of the file line below.
+func consoleLineLocation(c *console, line int) (location, bool) {
+	m := sourceLocRe.FindStringSubmatch(lineText(c.text, line))
+	if m == nil {
+		m = stackFrameRe.FindStringSubmatch(lineText(c.text, line+1))
+	}
+	if m == nil {
+		return location{}, false
+	}
+	loc := location{path: resolveConsolePath(c, m[1]), col: 1}
+	loc.line, _ = strconv.Atoi(m[2])
+	if len(m) > 3 && m[3] != "" {
+		loc.col, _ = strconv.Atoi(m[3])
+	}
+	return loc, fileExists(loc.path)
+}
+
+// locateConsoleLine shows the source location of line of console c in
+// the editor, highlighted, keeping the focus in c if keepFocus is set. It
+// reports whether the line has a location.
+func (i *Ite) locateConsoleLine(c *console, line int, keepFocus bool) bool {
+	loc, ok := consoleLineLocation(c, line)
+	if !ok {
+		return false
+	}
+	i.showLocation(loc)
+	if !samePath(loc.path, i.currentFile) {
+		return true // Left where it was, to save the buffer
+	}
+	i.editText.TagConfigure(tagLocated, Background(theme.Selection))
+	i.editText.TagRemove(tagLocated, "1.0", "end")
+	i.editText.TagAdd(tagLocated, fmt.Sprintf("%d.0", loc.line), fmt.Sprintf("%d.0", loc.line+1))
+	if keepFocus {
+		Focus(c.text)
+	}
+	return true
+}
+
+// onNextStackFrame moves the console cursor to the next frame of a stack
+// trace in the selected console and locates it.
+func (i *Ite) onNextStackFrame() { i.walkStack(1) }
+
+// onPreviousStackFrame moves the console cursor to the previous frame.
+func (i *Ite) onPreviousStackFrame() { i.walkStack(-1) }
+
+// walkStack moves the cursor of the selected console to the next stack
+// frame in direction dir, 1 or -1, whose file exists, and locates it. The
+// focus stays in the console if it was there.
+func (i *Ite) walkStack(dir int) {
+	c := i.selectedConsole()
+	inConsole := tclEval("focus") == c.text.String()
+	line, _ := parseIndex(c.text.Index("insert"))
+	last, _ := parseIndex(c.text.Index("end-1c"))
+	for n := line + dir; n >= 1 && n <= last; n += dir {
+		if !stackFrameRe.MatchString(lineText(c.text, n)) {
+			continue
+		}
+		if _, ok := consoleLineLocation(c, n); !ok {
+			continue
+		}
+		index := fmt.Sprintf("%d.0", n)
+		c.text.MarkSet("insert", index)
+		c.text.See(index)
+		c.text.TagConfigure(tagCursorLine, Background(theme.Selection))
+		c.text.TagRemove(tagCursorLine, "1.0", "end")
+		c.text.TagAdd(tagCursorLine, index, fmt.Sprintf("%d.0", n+1))
+		i.locateConsoleLine(c, n, inConsole)
+		return
+	}
+	i.showStatusHint("No more stack frames in the " + c.name + " console")
+}
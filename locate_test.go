@@ -0,0 +1,31 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "testing"
+
+func TestStackFrameRe(t *testing.T) {
+	tests := []struct {
+		line, path, lineNo string
+	}{
+		{"\t/home/u/proj/main.go:42 +0x1f", "/home/u/proj/main.go", "42"},
+		{"[15:04:05] \tmain.go:7 +0x2b", "main.go", "7"},
+		{"\tC:/src/proj/main.go:12", "C:/src/proj/main.go", "12"},
+		{"main.main()", "", ""},
+		{"./main.go:4:2: undefined: y", "", ""},
+		{"goroutine 1 [running]:", "", ""},
+	}
+	for _, tt := range tests {
+		m := stackFrameRe.FindStringSubmatch(tt.line)
+		if tt.path == "" {
+			if m != nil {
+				t.Errorf("%q matched as a frame of %q", tt.line, m[1])
+			}
+			continue
+		}
+		if m == nil || m[1] != tt.path || m[2] != tt.lineNo {
+			t.Errorf("frame of %q = %q, want %s:%s", tt.line, m, tt.path, tt.lineNo)
+		}
+	}
+}
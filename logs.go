@@ -0,0 +1,127 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Log Colorization
+// -------------------------------------------------------------------------
+
+// Console tags of log lines by level. JSON log lines use the same tags
+// with the tagLogJSON prefix, which makes them clickable.
+const (
+	tagLogDebug = "logdebug"
+	tagLogWarn  = "logwarn"
+	tagLogError = "logerror"
+	tagLogJSON  = "json"
+)
+
+var (
+	// levelKVRe matches the level of slog and logrus text output,
+	// e.g. `time=... level=WARN msg=...`.
+	levelKVRe = regexp.MustCompile(`\blevel=("?)(\w+)`)
+
+	// zapConsoleRe matches the level column of zap's console encoder,
+	// e.g. "2024-01-02T15:04:05.000Z\tERROR\tmain.go:12\tmsg".
+	zapConsoleRe = regexp.MustCompile(`^\S+\t([A-Z]+)\t`)
+
+	// logrusTTYRe matches logrus output on a terminal, e.g. "WARN[0001] msg".
+	logrusTTYRe = regexp.MustCompile(`^([A-Z]{4})\[\d+\]`)
+)
+
+// logDecoder colors the output of go run by log level and links servers
+// it announces.
+type logDecoder struct{}
+
+func (logDecoder) decode(line string) []consoleMsg {
+	msgs := []consoleMsg{{text: line, tag: logLineTag(line)}}
+	return append(msgs, serverMessages(line)...)
+}
+
+func (logDecoder) summary() []consoleMsg { return nil }
+
+// logLineTag returns the console tag for a line of program output, or ""
+// if it is not recognized as a log line or has a plain level.
+func logLineTag(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") {
+		var record map[string]any
+		if json.Unmarshal([]byte(trimmed), &record) == nil {
+			// slog uses "level", zap "level" or "L", logrus "level"
+			for _, key := range []string{"level", "L", "severity"} {
+				if level, ok := record[key].(string); ok {
+					return tagLogJSON + levelTag(level)
+				}
+			}
+			return tagLogJSON
+		}
+	}
+	if m := levelKVRe.FindStringSubmatch(line); m != nil {
+		return levelTag(m[2])
+	}
+	if m := zapConsoleRe.FindStringSubmatch(line); m != nil {
+		return levelTag(m[1])
+	}
+	if m := logrusTTYRe.FindStringSubmatch(line); m != nil {
+		return levelTag(m[1])
+	}
+	return ""
+}
+
+// levelTag maps a log level name, in any of the spellings used by the
+// common logging packages, to its console tag.
+func levelTag(level string) string {
+	switch strings.ToUpper(level) {
+	case "DEBUG", "DEBU", "TRACE", "TRAC":
+		return tagLogDebug
+	case "WARN", "WARNING":
+		return tagLogWarn
+	case "ERROR", "ERRO", "FATAL", "FATA", "PANIC", "PANI", "DPANIC", "CRITICAL":
+		return tagLogError
+	}
+	return "" // INFO and unknown levels keep the default color
+}
+
+// configureLogTags sets up the console tags of log lines.
+func (i *Ite) configureLogTags(c *console) {
+	colors := map[string]string{
+		tagLogDebug: theme.Muted,
+		tagLogWarn:  theme.Warning,
+		tagLogError: theme.Error,
+		"":          theme.Foreground,
+	}
+	for tag, color := range colors {
+		if tag != "" {
+			c.text.TagConfigure(tag, Foreground(color))
+		}
+		jsonTag := tagLogJSON + tag
+		c.text.TagConfigure(jsonTag, Foreground(color))
+		c.text.TagBind(jsonTag, "<Button-1>", func() { i.onJSONLogClick(c) })
+		c.text.TagBind(jsonTag, "<Enter>", func() {
+			c.text.Configure(Cursor("hand2"))
+		})
+		c.text.TagBind(jsonTag, "<Leave>", func() {
+			c.text.Configure(Cursor("xterm"))
+		})
+	}
+}
+
+// onJSONLogClick shows the JSON log line under the mouse pretty-printed.
+func (i *Ite) onJSONLogClick(c *console) {
+	line, _ := parseIndex(c.text.Index("current"))
+	text := strings.TrimSpace(lineText(c.text, line))
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(text), "", "  "); err != nil {
+		return
+	}
+	showTextWindow("Log Record", out.String())
+}
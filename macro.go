@@ -0,0 +1,351 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Macros
+// -------------------------------------------------------------------------
+
+// Record Macro captures the keys typed in the editor and the commands run
+// from the menus, the toolbar, the palette or their keys until recording
+// stops. Play Macro replays them, the keys as generated key events, so the
+// editor handles them as if typed again. The last macro is kept in the
+// config directory across runs.
+const (
+	macroFileName = "macro.json" // Last macro inside configDirName
+	macroBindTag  = "IteMacro"   // Records the keys of the editor
+	macroMaxTimes = 10000        // Upper bound of Play Macro N Times
+)
+
+// macroStep is a step of a macro: a key, with the modifier state it was
+// pressed with, or a command.
+type macroStep struct {
+	Key     string `json:"key,omitempty"` // Keysym, e.g. "a" or "Return"
+	State   int    `json:"state,omitempty"`
+	Command string `json:"command,omitempty"`
+}
+
+// macroRecorder is the state of macro recording and playback.
+type macroRecorder struct {
+	recording bool
+	playing   bool
+	running   bool        // A command is running, the commands it runs aren't recorded
+	steps     []macroStep // Macro being recorded
+	last      []macroStep // Last macro recorded, nil until loaded
+	chord     []macroStep // Keys held back as they start a bound sequence
+	seqs      map[string]bool
+	prefixes  map[string]bool // Sequences that a longer bound one starts with
+}
+
+// macroCommands aren't recorded: they drive the recording itself.
+var macroCommands = map[string]bool{
+	"recordMacro":    true,
+	"stopMacro":      true,
+	"playMacro":      true,
+	"playMacroTimes": true,
+	"commandPalette": true, // The command picked is recorded instead
+}
+
+// modifierKeysyms are keys only recorded as the state of the others.
+var modifierKeysyms = map[string]bool{
+	"Shift_L": true, "Shift_R": true, "Control_L": true, "Control_R": true,
+	"Alt_L": true, "Alt_R": true, "Meta_L": true, "Meta_R": true,
+	"Super_L": true, "Super_R": true, "Caps_Lock": true, "Num_Lock": true,
+	"ISO_Level3_Shift": true,
+}
+
+// macroStateMask keeps the modifiers replayed with a key: Shift, Control
+// and Alt.
+const macroStateMask = int(ModifierShift | ModifierControl | ModifierAlt)
+
+// keyEventRe matches an event of a key sequence, e.g. "<Control-x>" or "k".
+var keyEventRe = regexp.MustCompile(`<([^>]+)>|([^<])`)
+
+// keysymRe matches the keysyms macros hold, which are passed to Tcl
+// unquoted.
+var keysymRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// macroPath returns the absolute path of the file of the last macro.
+func macroPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, macroFileName), nil
+}
+
+// bindMacro records the keys of the editor while a macro is recorded,
+// ahead of every other binding.
+func (i *Ite) bindMacro() {
+	addBindtag(i.editText.Window, macroBindTag, "")
+	Bind(macroBindTag, "<KeyPress>", Command(func(e *Event) {
+		if i.macro.recording && !i.macro.playing && !modifierKeysyms[e.Keysym] && keysymRe.MatchString(e.Keysym) {
+			i.recordMacroKey(macroStep{Key: e.Keysym, State: int(e.State) & macroStateMask})
+		}
+	}))
+}
+
+// aroundCommand runs the command id with run, recording it in the macro
+// being recorded unless another command runs it, and in the trace.
+func (i *Ite) aroundCommand(id string, run func()) {
+	_, endTask := traceTask("command " + id)
+	defer endTask()
+	m := &i.macro
+	if m.recording && !m.playing && !m.running && !macroCommands[id] {
+		m.chord = nil
+		m.steps = append(m.steps, macroStep{Command: id})
+	}
+	if m.running {
+		run()
+		return
+	}
+	m.running = true
+	defer func() { m.running = false }()
+	run()
+}
+
+// recordMacroKey records key unless it is part of a sequence bound to a
+// command, which is recorded instead when the sequence completes.
+func (i *Ite) recordMacroKey(key macroStep) {
+	m := &i.macro
+	chord := append(slices.Clone(m.chord), key)
+	seq := macroSequence(chord)
+	switch {
+	case m.seqs[seq]:
+		m.chord = nil
+	case m.prefixes[seq]:
+		m.chord = chord
+	case len(m.chord) > 0:
+		// The keys held back start no bound sequence after all: they
+		// reached the editor, and key may start another one
+		m.steps = append(m.steps, m.chord...)
+		m.chord = nil
+		i.recordMacroKey(key)
+	default:
+		m.steps = append(m.steps, key)
+	}
+}
+
+// macroSequence returns the normalized key sequence of keys, see
+// normalizeKeyEvent.
+func macroSequence(keys []macroStep) string {
+	events := make([]string, len(keys))
+	for n, k := range keys {
+		var mods []string
+		if k.State&int(ModifierControl) != 0 {
+			mods = append(mods, "control")
+		}
+		if k.State&int(ModifierAlt) != 0 {
+			mods = append(mods, "alt")
+		}
+		events[n] = normalizeKeyEvent(append(mods, k.Key))
+	}
+	return strings.Join(events, " ")
+}
+
+// normalizeKeyEvent returns the event made of the modifiers and keysym of
+// parts so that the same key compares equal however it is written: lower
+// case, without Shift, which the keysym already reflects, with the
+// modifiers sorted.
+func normalizeKeyEvent(parts []string) string {
+	var mods []string
+	key := strings.ToLower(parts[len(parts)-1])
+	for _, p := range parts[:len(parts)-1] {
+		switch p = strings.ToLower(p); p {
+		case "shift", "key", "keypress":
+		case "ctrl":
+			mods = append(mods, "control")
+		case "mod1", "meta":
+			mods = append(mods, "alt")
+		default:
+			mods = append(mods, p)
+		}
+	}
+	slices.Sort(mods)
+	return strings.Join(append(mods, key), "-")
+}
+
+// macroSequences returns the normalized key sequences of the bindings and
+// the sequences longer ones start with.
+func (i *Ite) macroSequences() (seqs, prefixes map[string]bool) {
+	seqs, prefixes = make(map[string]bool), make(map[string]bool)
+	for seq := range i.keys {
+		var events []string
+		for _, m := range keyEventRe.FindAllStringSubmatch(seq, -1) {
+			event := m[1]
+			if event == "" {
+				event = m[2]
+			}
+			events = append(events, normalizeKeyEvent(strings.Split(event, "-")))
+		}
+		if len(events) == 0 {
+			continue
+		}
+		seqs[strings.Join(events, " ")] = true
+		for n := 1; n < len(events); n++ {
+			prefixes[strings.Join(events[:n], " ")] = true
+		}
+	}
+	return seqs, prefixes
+}
+
+// onRecordMacro starts recording a macro, or stops the recording.
+func (i *Ite) onRecordMacro() {
+	m := &i.macro
+	if m.recording {
+		i.onStopMacro()
+		return
+	}
+	if m.playing {
+		return
+	}
+	m.recording = true
+	m.steps, m.chord = nil, nil
+	m.seqs, m.prefixes = i.macroSequences()
+	i.updateCursorPosition()
+	i.showStatusHint("Recording macro, Stop Recording Macro ends it")
+}
+
+// onStopMacro stops recording, making the macro recorded the last one.
+func (i *Ite) onStopMacro() {
+	m := &i.macro
+	if !m.recording {
+		return
+	}
+	m.recording = false
+	m.steps = append(m.steps, m.chord...)
+	m.chord = nil
+	i.updateCursorPosition()
+	if len(m.steps) == 0 {
+		i.showStatusHint("Macro empty, the last one is kept")
+		return
+	}
+	m.last = m.steps
+	m.steps = nil
+	if err := saveMacro(m.last); err != nil {
+		i.showError("Error saving macro: " + err.Error())
+		return
+	}
+	i.showStatusHint(fmt.Sprintf("Macro recorded, %d steps", len(m.last)))
+}
+
+// onPlayMacro plays the last macro once.
+func (i *Ite) onPlayMacro() {
+	i.playMacro(1)
+}
+
+// onPlayMacroTimes asks how many times to play the last macro and plays
+// it.
+func (i *Ite) onPlayMacroTimes() {
+	i.promptString("Play Macro", "Number of times:", "2", func(s string) {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 || n > macroMaxTimes {
+			i.showError(fmt.Sprintf("Play Macro: give a number from 1 to %d.", macroMaxTimes))
+			return
+		}
+		i.playMacro(n)
+	})
+}
+
+// playMacro plays the last macro times times, as one undo step.
+func (i *Ite) playMacro(times int) {
+	m := &i.macro
+	if m.recording {
+		i.showStatusHint("Stop recording the macro before playing it")
+		return
+	}
+	if m.playing {
+		return
+	}
+	if m.last == nil {
+		steps, err := loadMacro()
+		if err != nil {
+			i.showError("Error loading macro: " + err.Error())
+			return
+		}
+		m.last = steps
+	}
+	if len(m.last) == 0 {
+		i.showStatusHint("No macro recorded")
+		return
+	}
+	for _, step := range m.last {
+		if step.Command != "" && i.commands.lookup(step.Command) == nil {
+			i.showError("Play Macro: unknown command " + step.Command)
+			return
+		}
+	}
+
+	m.playing = true
+	defer func() { m.playing = false }()
+	Focus(i.editText)
+	i.editGroup(func() {
+		for range times {
+			for _, step := range m.last {
+				if step.Command != "" {
+					i.commands.lookup(step.Command).run()
+					continue
+				}
+				tclEval("event generate %s <KeyPress> -keysym %s -state %d", i.editText, step.Key, step.State)
+			}
+		}
+	})
+	i.refreshCursorState()
+	if times > 1 {
+		i.showStatusHint(fmt.Sprintf("Macro played %d times", times))
+	}
+}
+
+// loadMacro reads the last macro. A missing file yields none.
+func loadMacro() ([]macroStep, error) {
+	path, err := macroPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []macroStep{}, nil
+		}
+		return nil, err
+	}
+	var steps []macroStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	steps = slices.DeleteFunc(steps, func(s macroStep) bool {
+		return s.Command == "" && !keysymRe.MatchString(s.Key)
+	})
+	return steps, nil
+}
+
+// saveMacro writes steps as the last macro, creating the config directory
+// if needed.
+func saveMacro(steps []macroStep) error {
+	path, err := macroPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(steps, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), configFilePerms)
+}
@@ -7,46 +7,39 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	. "modernc.org/tk9.0"
 )
 
-// -------------------------------------------------------------------------
-// Theme Configuration
-// -------------------------------------------------------------------------
-
-// Color palette definitions used throughout the application to maintain
-// a consistent visual theme.
-const (
-	colBlack        = "#000000"
-	colExtremeBlack = "#101010"
-	colApricotWhite = "#ffffea" // Main background for text areas
-	colCoolYellow   = "#eceb91" // Selection background
-	colWaterDew     = "#eaffff" // Frame backgrounds
-	colSaltWater    = "#d4ffff" // Active button background
-	colHighBall     = "#8d8c39" // Scrollbar trough
-	colRed          = "#ff0000" // Error/Unsaved status
-	colDarkGreen    = "#006400" // Saved status
-)
-
 // -------------------------------------------------------------------------
 // Application Configuration
 // -------------------------------------------------------------------------
 
 const (
-	defaultWindowSize    = "1250x600"
-	pollInterval         = 100 * time.Millisecond // Frequency for checking build output
-	buildChannelBuffer   = 1                      // Buffer size for async command output
-	defaultFilePerms     = 0644                   // -rw-r--r--
+	defaultWindowSize    = "1250x600" // At 96 DPI, see defaultGeometry
+	consoleBacklog       = 256        // Command output messages waiting for the UI before commands block
+	defaultFilePerms     = 0644       // -rw-r--r--
 	defaultFileExtension = ".go"
+	defaultFontSize      = 13 // Point size of the editor font
+	defaultFontFamily    = "GoMono"
 )
 
+// fontSize is the point size of the editor font in use.
+var fontSize = defaultFontSize
+
 // UI Status messages displayed in the bottom bar or window title.
 const (
 	statusUntitled = "Untitled - ITE"
@@ -65,55 +58,310 @@ const (
 // UI widgets and manages the application state (current file, build processes).
 type Ite struct {
 	// Editor components
-	editFrame       *TFrameWidget
-	editFrame2      *TFrameWidget
-	toolbarFrame    *TFrameWidget
-	editText        *TextWidget       // Main code editor
-	editText2       *TextWidget       // Output console
-	editVScrollbar  *TScrollbarWidget // Editor scrollbar
-	editVScrollbar2 *TScrollbarWidget // Console scrollbar
+	menubar        *MenuWidget
+	editFrame      *TFrameWidget
+	editFrame2     *TFrameWidget
+	toolbarFrame   *TFrameWidget
+	editText       *TextWidget       // Main code editor
+	editVScrollbar *TScrollbarWidget // Editor scrollbar
+	consoleTabs    *TNotebookWidget  // Output consoles, one tab per command kind
+	consoles       []*console        // Output consoles, in tab order, built on first use
+	startup        *startupProfile   // Timing of the startup phases
+	runProfileBox  *TComboboxWidget  // Run profile used by Go Run
+
+	// Split view
+	panes       []*editorPane       // Editor panes, in display order
+	active      *editorPane         // Pane whose state is in the fields below
+	paneCount   int                 // Panes created so far, for their bind tags
+	splitPane   *TPanedwindowWidget // Holds the panes in the editor region
+	splitOrient string              // Orientation of splitPane
+
+	// Layout regions
+	mainPane     *TPanedwindowWidget // Splits the bottom region from the rest
+	centerPane   *TPanedwindowWidget // Splits the side regions from the editor
+	editorRegion *TFrameWidget       // Pane holding the editor
+	regions      map[string]*region  // Docking regions, by name
+	placed       map[string]bool     // Regions currently in the panes
+
+	// Outline sidebar
+	outlineFrame *TFrameWidget
+	outlineList  *ListboxWidget // Declarations of the buffer, nil until first shown
+	outline      []outlineEntry // Entries of outlineList, in order
+	outlineSrc   string         // Buffer text the outline was built from
+	outlineTimer string         // Pending idle rebuild, "" if none
+
+	// Console filter bar
+	consoleFilterFrame *TFrameWidget
+	consoleTimestamps  *TCheckbuttonWidget // Shows the arrival time of console lines
+	consoleScrollLock  *TCheckbuttonWidget // Stops the consoles following new output
+	consoleLogCheck    *TCheckbuttonWidget // Logs the console output to a file
+	consoleLocate      *TCheckbuttonWidget // Shows the source line of the console line selected
+	consoleFilter      *TEntryWidget       // Substring or regex typed by the user
+	consoleFilterRegex *TCheckbuttonWidget // Treat the filter as a regex
+	consoleStream      *TComboboxWidget    // Output stream shown, both by default
+	proseGuide         *FrameWidget        // Column guide for prose lines
+	columnGuide        *FrameWidget        // Guide at the column chosen in the preferences
+	indentGuides       []*FrameWidget      // Indentation guides, the hidden ones kept for reuse
+	conflictBar        *TFrameWidget       // Resolves the merge conflict at the cursor
+	conflictBarText    *TextWidget         // Editor the conflict bar is in
+	conflictLabel      *TLabelWidget       // Number of the conflict at the cursor
+	guidesPending      bool                // The indentation guides are to be redrawn
+	editorFont         *FontFace           // Editor font, used for measuring columns
 
 	// Status bar components
 	statusFrame       *TFrameWidget
-	statusLabelCursor *TLabelWidget // Displays Line:Column
-	statusLabelFile   *TLabelWidget // Displays Saved/Unsaved status
+	statusLabelCursor *TLabelWidget     // Displays Line:Column
+	statusLabelFile   *TLabelWidget     // Displays Saved/Unsaved status
+	statusLabelServer *TLabelWidget     // Address of the server started by Go Run
+	statusBranch      *MenubuttonWidget // Git branch of the current file, with a menu to switch it
+	branchMenu        *MenuWidget
+	branches          gitBranches       // Branches of the repository of the current file
+	statusLabelModule *TLabelWidget     // Module mode, offline or vendor, empty when online
+	statusEncoding    *MenubuttonWidget // Encoding of the buffer, with a menu to change it
+	statusEOL         *MenubuttonWidget // Line endings of the buffer, with a menu to convert them
+	statusSelection   *TLabelWidget     // Size of the selection
+	statusModuleName  *TLabelWidget     // Path of the module of the current file
+	statusJobs        *TLabelWidget     // Spinner and consoles of the running commands
+	statusHealth      *TLabelWidget     // Summary of the health checks
+	statusReadOnly    *TLabelWidget     // Lock shown in read-only mode
+	statusProvider    *TLabelWidget     // Completion providers enabled, empty when none
+	statusSegments    []*statusSegment  // Widgets of the status bar, in order
+	moduleFile        string            // File statusModuleName was read for
+	spinning          bool              // The spinner of statusJobs is animated
+	spinnerFrame      int
+	statusHint        string              // Transient message shown after the cursor position
+	statusHintUntil   time.Time           // Time at which statusHint expires
+	composing         bool                // An input method is composing text in the editor
+	assertDir         string              // Temporary files of the last Assert or Mutate Selection, "" if none
+	buildOverlays     map[*console]string // Overlay directory of the latest build of each console
+
+	// View options
+	typewriterVar    *VariableOpt // Checkbutton state for typewriter scrolling
+	darkThemeVar     *VariableOpt // Checkbutton state for the dark theme
+	consoleDarkVar   *VariableOpt // Checkbutton state for the dark theme of the consoles
+	gracefulStopVar  *VariableOpt // Checkbutton state for stopping with SIGTERM
+	uncachedVar      *VariableOpt // Checkbutton state for running tests with -count=1
+	offlineVar       *VariableOpt // Checkbutton state for working offline
+	vendorVar        *VariableOpt // Checkbutton state for using the vendor directory
+	buildUnsavedVar  *VariableOpt // Checkbutton state for building unsaved buffers through an overlay
+	relativePathsVar *VariableOpt // Checkbutton state for project relative paths
+	useTrashVar      *VariableOpt // Checkbutton state for moving files to the trash
+	linkedVar        *VariableOpt // Checkbutton state for linked editing
+	autoPairsVar     *VariableOpt // Checkbutton state for auto-closing pairs
+	readOnlyVar      *VariableOpt // Checkbutton state for the read-only mode of the buffer
+	readOnly         bool         // The buffer refuses edits, see setReadOnly
+	apiVar           *VariableOpt // Checkbutton state for the external tool API
+	api              *http.Server // External tool API, nil when off
+	apiSocket        string       // Socket the API listens on
+	outlineVar       *VariableOpt // Checkbutton state for the outline sidebar
+	stickyVar        *VariableOpt // Checkbutton state for sticky scroll
+	wordWrapVar      *VariableOpt // Checkbutton state for word wrap
+	whitespaceVar    *VariableOpt // Checkbutton state for visible whitespace
+	indentGuidesVar  *VariableOpt // Checkbutton state for the indentation guides
+	lensVar          *VariableOpt // Checkbutton state for the inline diagnostics
+	spellCheckVar    *VariableOpt // Checkbutton state for spell checking
+	codeHintsVar     *VariableOpt // Checkbutton state for signature help and hover
+	completionVar    *VariableOpt // Checkbutton state for path completion
+	autospacingVar   *VariableOpt // Checkbutton state for autospacing
 
 	// Internal State
-	currentFile string      // Absolute path to the currently open file
-	buildChan   chan string // Channel to pass async command output to the UI thread
+	undo         undoGrouper       // Undo step tracking for the main editor
+	cursors      cursorHistory     // Cursor positions for Cursor Undo
+	linked       linkedEdit        // Active linked editing session
+	block        *blockSelection   // Rectangular selection, nil when there is none
+	macro        macroRecorder     // Macro being recorded and the last one
+	snippet      snippetEdit       // Active snippet session
+	modal        modalState        // Mode of modal editing, shared by the panes
+	config       *Config           // User preferences persisted between sessions
+	session      *session          // State of the files persisted between sessions
+	keys         map[string]string // Key bindings, from event sequence to action name
+	commands     commandRegistry   // Commands by id
+	currentFile  string            // Absolute path to the currently open file
+	encoding     string            // Encoding currentFile is saved in, "" for UTF-8
+	eol          string            // Line endings currentFile is saved with, "" for LF
+	dispatch     dispatcher        // Functions queued by goroutines for the UI thread
+	consoleOut   []consoleMsg      // Command output waiting for the UI thread, guarded by outMu
+	outMu        sync.Mutex
+	outCond      *sync.Cond        // Signaled when consoleOut is drained
+	http         *httpPanel        // HTTP client panel, nil when closed
+	grpc         *grpcPanel        // gRPC client panel, nil when closed
+	openAPI      *openAPIPanel     // OpenAPI Preview window, nil when closed
+	inspector    *processInspector // Process inspector window, nil when closed
+	compose      *composePanel     // Docker Compose window, nil when closed
+	doctor       *TextWidget       // Report view of the doctor window, nil when closed
+	find         *findPanel        // Find in Files window, nil when closed
+	quickOpen    *quickOpenPanel   // Quick Open window, nil when closed
+	switcher     *switcherPanel    // Switch File window, nil when closed
+	recentFiles  []string          // Files opened in the session, the latest first
+	search       *searchBar        // Incremental search bar, nil until first opened
+	asm          *asmPanel         // Assembly window, nil when closed
+	binarySize   *tableWindow      // Binary Size window, nil when closed
+	benchTable   *tableWindow      // Benchmarks window, nil when closed
+	queryTable   *tableWindow      // Query Results window, nil when closed
+	plugins      []*plugin         // Running plugins
+	pluginsMenu  *MenuWidget       // Menu of the plugin commands, nil until one is added
+	coverMarks   *coverMarks       // Blocks of the last coverage run, nil if none
+	health       healthReport      // Last report of the health checks
+	healthTimer  string            // Pending health checks, "" if none
+	healthSeq    int               // Bumped by every edit, dropping the reports of older checks
+	files        fileIndex         // Files of the project, listed by Quick Open
+	structural   *structPanel      // Structural Replace window, nil when closed
+	rename       *renamePanel      // Rename Package preview, nil when closed
+	git          *gitPanel         // Git window, nil when closed
+	regex        *regexPanel       // Regex Tester window, nil when closed
+	docs         *docPanel         // Documentation window, nil when closed
+	env          envState          // Checks of the environment for changes
+	numberMenu   *MenuWidget       // Popup menu of Convert Number, nil until first used
+	spell        spellState        // Spell checker of comments and strings
+	tasks        taskState         // Runs of the project tasks
+	buildTags    buildTagState     // Build tags passed to Build, Run and Test
+	consoleLog   consoleLogState   // Log files of the console output
+	hint         hintState         // Tooltip of the signature help and the hover
+	completion   completionState   // List of the path completions
+	completing   completionRequest // Latest completion asked of the providers
+	virtual      virtualTextState  // Virtual text of the editors
+	sticky       stickyScroll      // Declarations pinned to the top of the active editor
+	profileMenu  *MenuWidget       // Popup menu of the benchmark profiles, nil until first used
+	profileDir   string            // Temporary files of the last benchmark profile, "" if none
+	filterRe     *regexp.Regexp    // Console filter, nil to show every line
+	diagnostics  []diagnostic      // Problems found by the latest Lint run
+	runID        int               // Identifier of the latest command run
+	jobs         map[int]*job      // Started command runs, by identifier
+	jobQueue     []*job            // Command runs waiting for a job slot
+	runner       runner            // Starts the programs of the command runs
+	serverURL    string            // Server announced by the running program, "" if none
+	diskStamp    fileStamp         // Version of currentFile on disk the buffer matches
+	changePrompt bool              // Set while asking about an external change
+	swapFile     string            // Swap file written for the buffer, "" if none
+	swapFailed   bool              // A failure to write a swap file was reported
+	journal      *undoJournal      // Saved versions of currentFile, nil if none
+	journalBase  int               // Journal version at the bottom of the undo stack, -1 if none
+	normalWindow windowState       // Placement of the main window when not maximized
+
+	// Large files
+	largeFile bool                        // The buffer is in large-file mode
+	loads     map[*TextWidget]*bufferLoad // Large files still loading, by editor
+	saving    map[string]*backgroundSave  // Files being written by writeInBackground
+
+	// Gutter
+	gutterTimer string            // Pending gutter marker update, "" if none
+	gitHeads    map[string]string // HEAD versions of the open files, by path
+	bookmarkSeq int               // Last number given to a bookmark mark
+	folds       []foldRegion      // Regions of the buffer that can be folded
+	foldSeq     int               // Last number given to a fold tag
+
+	// Guards the running processes of the consoles, shared with the
+	// goroutines streaming their output
+	procMu sync.Mutex
+
+	// Background work, see shutdown.go
+	quitCtx          context.Context    // Canceled when the editor quits
+	cancelBackground context.CancelFunc // Cancels quitCtx
+	background       sync.WaitGroup     // Goroutines of goBackground still running
 }
 
 // main is the entry point of the application.
 func main() {
-	NewIte().Run()
+	cl, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ite: %v\nusage: ite [--trace file] [--batch script] [--] [[+line] file ...]\n", err)
+		os.Exit(2)
+	}
+	if cl.batch != "" {
+		os.Exit(runBatch(cl.batch, cl.files, os.Stdout, os.Stderr))
+	}
+	// Raw Tcl access for the Tk features the bindings do not wrap
+	if err := InitializeExtension("eval"); err != nil {
+		fmt.Fprintf(os.Stderr, "ite: %v\n", err)
+		os.Exit(1)
+	}
+	if cl.trace != "" {
+		if err := startTrace(cl.trace); err != nil {
+			fmt.Fprintf(os.Stderr, "ite: starting trace: %v\n", err)
+			os.Exit(1)
+		}
+		defer stopTrace()
+	}
+	i := NewIte()
+	i.openArgs(cl.files)
+	i.startup.mark("files")
+	i.Run()
 }
 
-// Run initializes the window geometry and enters the main Tk event loop.
+// Run restores the window placement and enters the main Tk event loop.
 // This method blocks until the window is closed.
 func (i *Ite) Run() {
-	WmGeometry(App, defaultWindowSize)
+	i.restoreWindow()
+	i.trackWindow()
 	WmDeiconify(App)
+	TclAfterIdle(i.onWindowShown)
 	App.Wait()
 }
 
 // NewIte initializes a new instance of the editor.
 // It sets up the window title, protocol handlers, widget layout, global styles,
-// and starts the background polling loop.
+// and starts the timers for swap files and file watching.
 func NewIte() *Ite {
+	startup := newStartupProfile()
+	firstRun := !configExists()
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ite: loading config: %v\n", err)
+	}
 	i := &Ite{
-		buildChan: make(chan string, buildChannelBuffer),
+		config:   cfg,
+		jobs:     make(map[int]*job),
+		runner:   execRunner{},
+		loads:    make(map[*TextWidget]*bufferLoad),
+		saving:   make(map[string]*backgroundSave),
+		gitHeads: make(map[string]string),
+		startup:  startup,
+	}
+	if i.session, err = loadSession(); err != nil {
+		fmt.Fprintf(os.Stderr, "ite: loading session: %v\n", err)
 	}
-	App.WmTitle(statusUntitled)
+	i.outCond = sync.NewCond(&i.outMu)
+	i.quitCtx, i.cancelBackground = context.WithCancel(context.Background())
+	applyScale(cfg.Scale)
+	startup.mark("config")
+	if firstRun {
+		i.runSetupWizard()
+		startup.skip()
+	}
+	theme = themeByName(cfg.Theme)
+	applyPreferenceGlobals(cfg)
+	language = uiLanguage(cfg.Language, os.Getenv)
+	i.makeCommands()
+	keys, keysErr := loadKeys(&i.commands, cfg.KeyPreset)
+	i.keys = keys
+	startup.mark("commands")
+	App.WmTitle(tr(statusUntitled))
 	// Intercept the close button to prompt for unsaved changes
 	WmProtocol(App, "WM_DELETE_WINDOW", i.onQuit)
 
 	i.makeWidgets()
+	startup.mark("widgets")
 	i.makeLayout()
+	startup.mark("layout")
+	i.applyModuleMode()
+	i.refreshBranches()
 	i.bindShortcuts()
+	i.registerVirtualText("diagnostics", i.inlineDiagnostics)
+	i.bindFocusSave()
+	i.bindEnvCheck()
 	i.applyGlobalStyle()
+	startup.mark("bindings")
 
-	// Start the polling loop to bridge background goroutines with the UI thread
-	TclAfter(pollInterval, i.pollBuildOutput)
+	if keysErr != nil {
+		TclAfterIdle(func() { i.showError("Error in key bindings: " + keysErr.Error()) })
+	}
+
+	TclAfter(i.autosaveInterval(), i.autosaveSwap)
+	TclAfter(fileWatchInterval, i.watchFile)
+	TclAfterIdle(i.startPlugins)
+	if cfg.ExternalAPI {
+		TclAfterIdle(i.startAPI)
+	}
 	return i
 }
 
@@ -125,36 +373,48 @@ func NewIte() *Ite {
 func (i *Ite) applyGlobalStyle() {
 	// Configure Button styles
 	StyleConfigure("TButton",
-		Background(colWaterDew),
-		Foreground(colExtremeBlack),
+		Background(theme.Frame),
+		Foreground(theme.ButtonText),
 		Font("GoMono", 11, "bold"))
-	StyleMap("TButton", Background, "active", colSaltWater)
+	StyleMap("TButton", Background, "active", theme.Active)
 
 	// Configure Scrollbar styles
 	StyleConfigure("Vertical.TScrollbar",
-		Background(colApricotWhite),
-		Troughcolor(colHighBall),
+		Background(theme.Text),
+		Troughcolor(theme.Trough),
 		Borderwidth(1),
 		Arrowsize(0))
-	StyleMap("TScrollbar", Background, "active", colApricotWhite)
+	StyleMap("TScrollbar", Background, "active", theme.Text)
 
-	// Configure Frame and Window background
-	StyleConfigure("TFrame", Background(colWaterDew))
-	App.Configure(Background(colApricotWhite))
+	// Configure Frame, Label and Window background
+	StyleConfigure("TFrame", Background(theme.Frame))
+	StyleConfigure("TLabel", Background(theme.Frame), Foreground(theme.Foreground))
+	StyleConfigure("TCheckbutton", Background(theme.Frame), Foreground(theme.Foreground))
+	App.Configure(Background(theme.Text))
 }
 
 // textStyle returns the default configuration options for text widgets.
 func textStyle() Opts {
-	return Opts{
-		Font("GoMono", 13),
-		Background(colApricotWhite),
-		Foreground(colBlack),
-		Insertbackground(colBlack),    // Cursor color
-		Selectbackground(colCoolYellow), // Highlight color
-		Selectforeground(colBlack),
-		Tabs("1c"), // 1 tab width
-		Wrap("word"),
+	opts := Opts{
+		Font(editorFontFamily, fontSize),
+		Tabs(tabStops()),
+		Wrap(wrapMode),
 		Undo(true), // Enable built-in undo/redo stack
+		Highlightthickness(focusRingWidth),
+	}
+	return append(opts, textColors()...)
+}
+
+// textColors returns the color options of text widgets in the current theme.
+func textColors() Opts {
+	return Opts{
+		Background(theme.Text),
+		Foreground(theme.Foreground),
+		Insertbackground(theme.Foreground), // Cursor color
+		Selectbackground(theme.Selection),  // Highlight color
+		Selectforeground(theme.Foreground),
+		Highlightcolor(theme.Link), // Focus ring
+		Highlightbackground(theme.Frame),
 	}
 }
 
@@ -174,7 +434,13 @@ func (i *Ite) createEditorPanel() (*TFrameWidget, *TextWidget, *TScrollbarWidget
 	// Establish the link from Text widget back to Scrollbar
 	text.Configure(Yscrollcommand(func(event *Event) {
 		event.ScrollSet(scrollbar)
-	}))
+		i.scheduleIndentGuides()
+		i.scheduleVirtualText()
+		i.scheduleStickyScroll()
+	}), Xscrollcommand(func(*Event) {
+		i.scheduleIndentGuides()
+		i.scheduleVirtualText()
+	}), Maxundo(i.undoLimit()))
 
 	return frame, text, scrollbar
 }
@@ -183,54 +449,322 @@ func (i *Ite) createEditorPanel() (*TFrameWidget, *TextWidget, *TScrollbarWidget
 func (i *Ite) makeEditor() {
 	// Main editor
 	i.editFrame, i.editText, i.editVScrollbar = i.createEditorPanel()
+	i.active = &editorPane{}
+	i.panes = []*editorPane{i.active}
 
 	// Output panel
-	i.editFrame2, i.editText2, i.editVScrollbar2 = i.createEditorPanel()
+	i.editFrame2 = TFrame()
+	i.makeConsoles()
+
+	i.makeConsoleFilter()
+	i.makeProseGuide()
+	i.makeColumnGuide()
+	i.configureEditorTags()
+}
+
+// configureEditorTags sets up the styles of the text tags used in the main
+// editor. Clearing the editor deletes all tags, so this must run again
+// after every Clear.
+func (i *Ite) configureEditorTags() {
+	i.editText.TagConfigure(tagOverflow, Background(theme.Overflow))
+	i.editText.TagConfigure(tagReadOnly, Background(theme.Protected))
+	i.editText.TagConfigure(tagLinked, Underline(1))
+	i.editText.TagConfigure(tagBlockCursor, Underline(1), Background(theme.Selection))
+	i.configureDiagnosticTag()
+	i.editText.TagConfigure(tagBracket, Background(theme.Selection), Foreground(theme.Link))
+	i.configureCurrentLineTag()
+	i.configureWhitespaceTags()
+	i.configureCoverageTags()
+	i.configureGutterTags()
+	i.configureConflictTags()
+	i.configureSearchTags()
+	i.configureSpellingTag()
+	i.configureSQLTags()
 }
 
 // makeToolbar creates the top control bar with operation buttons.
 func (i *Ite) makeToolbar() {
 	i.toolbarFrame = TFrame(Relief(RAISED))
-	buttons := []struct {
-		text string
-		cmd  func()
-	}{
-		{"New", i.onNew},
-		{"Open", i.onOpen},
-		{"Save", i.onSave},
-		{"Save As", i.onSaveAs},
-		{"Cut", i.onCut},
-		{"Copy", i.onCopy},
-		{"Paste", i.onPaste},
-		{"Undo", i.onUndo},
-		{"Redo", i.onRedo},
-		{"Go to Line", i.onGoToLine},
-		{"Go Build", i.onGoBuild},
-		{"Go Run", i.onGoRun},
-		{"Exit", i.onQuit},
-	}
-
-	for col, btn := range buttons {
-		b := i.toolbarFrame.TButton(Txt(btn.text), Command(btn.cmd))
+	buttons := []struct{ text, id string }{
+		{"New", "new"},
+		{"Open", "open"},
+		{"Save", "save"},
+		{"Save As", "saveAs"},
+		{"Cut", "cut"},
+		{"Copy", "copy"},
+		{"Paste", "paste"},
+		{"Undo", "undo"},
+		{"Redo", "redo"},
+		{"Go to Line", "goToLine"},
+		{"Go Build", "build"},
+		{"Go Run", "run"},
+		{"Go Test", "test"},
+		{"Lint", "lint"},
+		{"Stop", "stop"},
+		{"Exit", "quit"},
+	}
+
+	col := 0
+	for _, btn := range buttons {
+		b := i.toolbarFrame.TButton(Txt(tr(btn.text)), Command(i.mustCommand(btn.id).run))
 		Grid(b, Row(0), Column(col), Sticky(W))
+		col++
+		if btn.id == "redo" {
+			lock := i.toolbarFrame.TCheckbutton(Txt(tr("Read-only")), i.readOnlyVar,
+				Command(i.mustCommand("toggleReadOnly").run))
+			Grid(lock, Row(0), Column(col), Sticky(W), Padx(px(4)))
+			col++
+		}
+		if btn.id == "run" {
+			Grid(i.makeRunProfileSelector(i.toolbarFrame), Row(0), Column(col), Sticky(W), Padx(px(2)))
+			col++
+		}
+		if btn.id == "lint" {
+			Grid(i.makeGoModMenu(i.toolbarFrame), Row(0), Column(col), Sticky(W))
+			col++
+			Grid(i.makeTaskMenu(i.toolbarFrame), Row(0), Column(col), Sticky(W))
+			col++
+		}
 	}
 }
 
-// makeStatusbar creates the bottom labels for cursor position and file status.
-func (i *Ite) makeStatusbar() {
-	i.statusFrame = TFrame(Relief(SUNKEN))
-	i.statusLabelCursor = i.statusFrame.TLabel(
-		Txt("Line:Column 0:0"),
-		Background(colApricotWhite),
-		Font("GoMono", 11))
-	i.statusLabelFile = i.statusFrame.TLabel(
-		Txt(statusNotSaved),
-		Background(colApricotWhite),
-		Font("GoMono", 11))
+// makeMenubar creates the window menu holding the less frequent actions
+// and the view options.
+func (i *Ite) makeMenubar() {
+	i.menubar = Menu()
+
+	fileMenu := i.menubar.Menu()
+	i.addMenuCommand(fileMenu, "new", "New")
+	i.addMenuCommand(fileMenu, "open", "Open...")
+	i.addMenuCommand(fileMenu, "openProjectFolder", "Open Folder as Project...")
+	i.addMenuCommand(fileMenu, "closeProjectFolder", "")
+	i.addMenuCommand(fileMenu, "editProjectSettings", "")
+	generateMenu := fileMenu.Menu()
+	i.addMenuCommand(generateMenu, "generateGitignore", ".gitignore")
+	i.addMenuCommand(generateMenu, "generateLicense", "License...")
+	fileMenu.AddCascade(Lbl(tr("Generate")), Mnu(generateMenu))
+	i.addMenuCommand(fileMenu, "save", "")
+	i.addMenuCommand(fileMenu, "saveAs", "Save As...")
+	i.addMenuCommand(fileMenu, "close", "")
+	i.addMenuCommand(fileMenu, "undoFileOp", "Undo Last File Operation...")
+	fileMenu.AddSeparator()
+	i.addMenuCommand(fileMenu, "compareWithSaved", "Compare with Saved...")
+	i.addMenuCommand(fileMenu, "compareFiles", "Compare Files...")
+	fileMenu.AddSeparator()
+	i.addMenuCommand(fileMenu, "exportHTML", "Export as HTML...")
+	i.addMenuCommand(fileMenu, "print", "Print...")
+	i.addMenuCommand(fileMenu, "exportConsolePDF", "Export Console as PDF...")
+	fileMenu.AddSeparator()
+	i.addMenuCommand(fileMenu, "quit", "")
+	i.menubar.AddCascade(Lbl(tr("File")), Underline(0), Mnu(fileMenu))
+
+	editMenu := i.menubar.Menu()
+	i.addMenuCommand(editMenu, "undoToSave", "")
+	editMenu.AddSeparator()
+	i.addMenuCommand(editMenu, "pasteAsString", "")
+	i.addMenuCommand(editMenu, "copyUnquoted", "")
+	insertMenu := editMenu.Menu()
+	i.addMenuCommand(insertMenu, "insertTimestamp", "Timestamp (RFC 3339)")
+	i.addMenuCommand(insertMenu, "insertUnixTime", "Unix Time")
+	i.addMenuCommand(insertMenu, "insertUUID", "UUID")
+	editMenu.AddCascade(Lbl(tr("Insert")), Mnu(insertMenu))
+	i.addMenuCommand(editMenu, "convertNumber", "Convert Number...")
+	i.addMenuCommand(editMenu, "pickColor", "Pick Color...")
+	editMenu.AddSeparator()
+	i.addMenuCommand(editMenu, "find", "Find...")
+	i.addMenuCommand(editMenu, "replace", "Replace...")
+	i.addMenuCommand(editMenu, "findInFiles", "Find in Files...")
+	i.addMenuCommand(editMenu, "structuralReplace", "Structural Replace...")
+	i.addMenuCommand(editMenu, "renamePackage", "Rename Package...")
+	i.addMenuCommand(editMenu, "moveDeclaration", "Move Declaration to File...")
+	i.addMenuCommand(editMenu, "commandPalette", "Command Palette...")
+	editMenu.AddSeparator()
+	i.addMenuCommand(editMenu, "toggleComment", "")
+	i.addMenuCommand(editMenu, "toggleBlockComment", "")
+	i.addMenuCommand(editMenu, "reflowComment", "")
+	i.addMenuCommand(editMenu, "docComment", "")
+	i.addMenuCommand(editMenu, "align", "")
+	i.addMenuCommand(editMenu, "formatFile", "")
+	linesMenu := editMenu.Menu()
+	for _, id := range []string{"moveLinesUp", "moveLinesDown", "duplicateLines", "deleteLines", "joinLines", "sortLines", "trimTrailingWhitespace"} {
+		i.addMenuCommand(linesMenu, id, "")
+	}
+	editMenu.AddCascade(Lbl(tr("Lines")), Mnu(linesMenu))
+	editMenu.AddSeparator()
+	macroMenu := editMenu.Menu()
+	i.addMenuCommand(macroMenu, "recordMacro", "Record")
+	i.addMenuCommand(macroMenu, "stopMacro", "Stop Recording")
+	i.addMenuCommand(macroMenu, "playMacro", "Play")
+	i.addMenuCommand(macroMenu, "playMacroTimes", "Play N Times...")
+	editMenu.AddCascade(Lbl(tr("Macro")), Mnu(macroMenu))
+	editMenu.AddSeparator()
+	i.addMenuCommand(editMenu, "protectSelection", "")
+	i.addMenuCommand(editMenu, "unprotectSelection", "")
+	editMenu.AddSeparator()
+	i.linkedVar = Variable(checkValue(i.config.LinkedEditing))
+	i.addMenuCheck(editMenu, "toggleLinkedEditing", "Linked Editing", i.linkedVar)
+	i.autoPairsVar = Variable(checkValue(i.config.AutoClosePairs))
+	i.addMenuCheck(editMenu, "toggleAutoClosePairs", "Auto-Closing Pairs", i.autoPairsVar)
+	i.codeHintsVar = Variable(checkValue(i.config.CodeHints))
+	i.addMenuCheck(editMenu, "toggleCodeHints", "Signature Help and Hover", i.codeHintsVar)
+	i.completionVar = Variable(checkValue(i.config.PathCompletion))
+	i.addMenuCheck(editMenu, "togglePathCompletion", "Path Completion", i.completionVar)
+	i.autospacingVar = Variable(checkValue(i.config.Autospacing))
+	i.addMenuCheck(editMenu, "toggleAutospacing", "Autospacing", i.autospacingVar)
+	i.readOnlyVar = Variable(checkValue(false))
+	i.addMenuCheck(editMenu, "toggleReadOnly", "Read-only", i.readOnlyVar)
+	i.menubar.AddCascade(Lbl(tr("Edit")), Underline(0), Mnu(editMenu))
+
+	viewMenu := i.menubar.Menu()
+	i.typewriterVar = Variable(checkValue(i.config.TypewriterScrolling))
+	i.addMenuCheck(viewMenu, "toggleTypewriter", "Typewriter Scrolling", i.typewriterVar)
+	i.darkThemeVar = Variable(checkValue(i.config.Theme == darkTheme.Name))
+	i.addMenuCheck(viewMenu, "toggleTheme", "Dark Theme", i.darkThemeVar)
+	i.consoleDarkVar = Variable(checkValue(i.consoleTheme().Name == darkTheme.Name))
+	i.addMenuCheck(viewMenu, "toggleConsoleTheme", "Dark Console", i.consoleDarkVar)
+	i.relativePathsVar = Variable(checkValue(i.config.RelativePaths))
+	i.addMenuCheck(viewMenu, "toggleRelativePaths", "Relative Paths", i.relativePathsVar)
+	i.outlineVar = Variable(checkValue(i.config.ShowOutline))
+	i.addMenuCheck(viewMenu, "toggleOutline", "Outline", i.outlineVar)
+	i.stickyVar = Variable(checkValue(i.config.StickyScroll))
+	i.addMenuCheck(viewMenu, "toggleStickyScroll", "Sticky Scroll", i.stickyVar)
+	i.wordWrapVar = Variable(checkValue(wrapMode != "none"))
+	i.addMenuCheck(viewMenu, "toggleWordWrap", "Word Wrap", i.wordWrapVar)
+	i.whitespaceVar = Variable(checkValue(i.config.ShowWhitespace))
+	i.addMenuCheck(viewMenu, "toggleWhitespace", "Show Whitespace", i.whitespaceVar)
+	i.indentGuidesVar = Variable(checkValue(i.config.IndentGuides))
+	i.addMenuCheck(viewMenu, "toggleIndentGuides", "Indentation Guides", i.indentGuidesVar)
+	i.lensVar = Variable(checkValue(i.config.InlineDiagnostics))
+	i.addMenuCheck(viewMenu, "toggleInlineDiagnostics", "Inline Diagnostics", i.lensVar)
+	i.spellCheckVar = Variable(checkValue(i.config.SpellCheck))
+	i.addMenuCheck(viewMenu, "toggleSpellCheck", "Spell Checking", i.spellCheckVar)
+	viewMenu.AddSeparator()
+	i.addMenuCommand(viewMenu, "splitSideBySide", "Split Side by Side")
+	i.addMenuCommand(viewMenu, "splitStacked", "Split Stacked")
+	i.addMenuCommand(viewMenu, "otherPane", "Other Pane")
+	i.addMenuCommand(viewMenu, "moveToOtherPane", "Move to Other Pane")
+	i.addMenuCommand(viewMenu, "closePane", "Close Pane")
+	viewMenu.AddSeparator()
+	i.addMenuCommand(viewMenu, "toggleFold", "")
+	i.addMenuCommand(viewMenu, "foldAll", "")
+	i.addMenuCommand(viewMenu, "unfoldAll", "")
+	viewMenu.AddSeparator()
+	for _, r := range []struct{ name, id, label string }{
+		{regionLeft, "toggleLeftSidebar", "Left Sidebar"},
+		{regionRight, "toggleRightPanel", "Right Panel"},
+		{regionBottom, "toggleBottomPanel", "Bottom Panel"},
+	} {
+		i.regions[r.name].menu = Variable(checkValue(!i.regionConfig(r.name).Collapsed))
+		i.addMenuCheck(viewMenu, r.id, r.label, i.regions[r.name].menu)
+	}
+	i.addMenuCommand(viewMenu, "resetLayout", "")
+	i.menubar.AddCascade(Lbl(tr("View")), Underline(0), Mnu(viewMenu))
+
+	navigateMenu := i.menubar.Menu()
+	i.addMenuCommand(navigateMenu, "quickOpen", "Quick Open...")
+	i.addMenuCommand(navigateMenu, "switchFile", "Switch File...")
+	i.addMenuCommand(navigateMenu, "goToDefinition", "")
+	i.addMenuCommand(navigateMenu, "showDocumentation", "")
+	i.addMenuCommand(navigateMenu, "matchBracket", "")
+	navigateMenu.AddSeparator()
+	i.addMenuCommand(navigateMenu, "cursorUndo", "")
+	i.addMenuCommand(navigateMenu, "cursorRedo", "")
+	navigateMenu.AddSeparator()
+	i.addMenuCommand(navigateMenu, "toggleBookmark", "")
+	i.addMenuCommand(navigateMenu, "nextBookmark", "")
+	i.addMenuCommand(navigateMenu, "previousBookmark", "")
+	i.addMenuCommand(navigateMenu, "listBookmarks", "List Bookmarks...")
+	i.menubar.AddCascade(Lbl(tr("Navigate")), Underline(0), Mnu(navigateMenu))
+
+	toolsMenu := i.menubar.Menu()
+	i.addMenuCommand(toolsMenu, "coverage", "")
+	i.addMenuCommand(toolsMenu, "clearCoverage", "")
+	i.addMenuCommand(toolsMenu, "bench", "")
+	i.addMenuCommand(toolsMenu, "escapeAnalysis", "")
+	i.addMenuCommand(toolsMenu, "checkNameSpelling", "")
+	i.addMenuCommand(toolsMenu, "showAssembly", "")
+	i.addMenuCommand(toolsMenu, "analyzeBinarySize", "")
+	i.addMenuCommand(toolsMenu, "assertSelection", "")
+	i.addMenuCommand(toolsMenu, "mutateSelection", "")
+	toolsMenu.AddSeparator()
+	i.addMenuCommand(toolsMenu, "httpClient", "HTTP Client...")
+	i.addMenuCommand(toolsMenu, "grpcClient", "gRPC Client...")
+	i.addMenuCommand(toolsMenu, "openAPIPreview", "OpenAPI Preview...")
+	i.addMenuCommand(toolsMenu, "processInspector", "Process Inspector...")
+	i.addMenuCommand(toolsMenu, "regexTester", "Regex Tester...")
+	i.addMenuCommand(toolsMenu, "runQuery", "")
+	i.addMenuCommand(toolsMenu, "setDatabase", "Database...")
+	migrationsMenu := toolsMenu.Menu()
+	i.addMenuCommand(migrationsMenu, "migrateUp", "Apply Pending")
+	i.addMenuCommand(migrationsMenu, "migrateDown", "Roll Back Latest")
+	i.addMenuCommand(migrationsMenu, "migrationStatus", "")
+	toolsMenu.AddCascade(Lbl(tr("Migrations")), Mnu(migrationsMenu))
+	i.addMenuCommand(toolsMenu, "runProfiles", "Run Profiles...")
+	i.addMenuCommand(toolsMenu, "runTask", "Run Task...")
+	i.addMenuCommand(toolsMenu, "dockerCompose", "Docker Compose...")
+	i.addMenuCommand(toolsMenu, "taskHistory", "Task History...")
+	toolsMenu.AddSeparator()
+	i.addMenuCommand(toolsMenu, "reloadEnvironment", "")
+	i.menubar.AddCascade(Lbl(tr("Tools")), Underline(0), Mnu(toolsMenu))
+
+	gitMenu := i.menubar.Menu()
+	i.addMenuCommand(gitMenu, "gitStatus", "Status...")
+	i.addMenuCommand(gitMenu, "gitDiff", "Diff with HEAD")
+	i.addMenuCommand(gitMenu, "gitCommit", "Commit...")
+	gitMenu.AddSeparator()
+	i.addMenuCommand(gitMenu, "reviewPatch", "Review Patch...")
+	i.addMenuCommand(gitMenu, "reviewRange", "Review Commits...")
+	gitMenu.AddSeparator()
+	i.addMenuCommand(gitMenu, "gitStash", "Stash Changes")
+	i.addMenuCommand(gitMenu, "gitStashPop", "Pop Stash")
+	gitMenu.AddSeparator()
+	conflictMenu := gitMenu.Menu()
+	i.addMenuCommand(conflictMenu, "nextConflict", "Next")
+	i.addMenuCommand(conflictMenu, "previousConflict", "Previous")
+	conflictMenu.AddSeparator()
+	i.addMenuCommand(conflictMenu, "acceptOurs", "Take Ours")
+	i.addMenuCommand(conflictMenu, "acceptTheirs", "Take Theirs")
+	i.addMenuCommand(conflictMenu, "acceptBoth", "Take Both")
+	gitMenu.AddCascade(Lbl(tr("Conflicts")), Mnu(conflictMenu))
+	i.menubar.AddCascade(Lbl(tr("Git")), Underline(0), Mnu(gitMenu))
+
+	helpMenu := i.menubar.Menu()
+	i.addMenuCommand(helpMenu, "doctor", "Doctor...")
+	i.menubar.AddCascade(Lbl(tr("Help")), Underline(0), Mnu(helpMenu))
+
+	settingsMenu := i.menubar.Menu()
+	i.addMenuCommand(settingsMenu, "preferences", "Preferences...")
+	settingsMenu.AddSeparator()
+	i.addMenuCommand(settingsMenu, "wordChars", "Word Characters...")
+	i.addMenuCommand(settingsMenu, "undoInterval", "Undo Grouping Interval...")
+	i.addMenuCommand(settingsMenu, "keyBindings", "Keyboard Shortcuts...")
+	i.addMenuCommand(settingsMenu, "displayScaling", "Display Scaling...")
+	settingsMenu.AddSeparator()
+	i.addMenuCommand(settingsMenu, "exportSettings", "Export Settings...")
+	i.addMenuCommand(settingsMenu, "importSettings", "Import Settings...")
+	i.addMenuCommand(settingsMenu, "resetSettings", "Reset Settings...")
+	settingsMenu.AddSeparator()
+	i.useTrashVar = Variable(checkValue(i.config.UseTrash))
+	i.addMenuCheck(settingsMenu, "toggleTrash", "Move Replaced Files to Trash", i.useTrashVar)
+	i.gracefulStopVar = Variable(checkValue(i.config.GracefulStop))
+	i.addMenuCheck(settingsMenu, "toggleGracefulStop", "Stop with SIGTERM", i.gracefulStopVar)
+	i.uncachedVar = Variable(checkValue(i.config.UncachedTests))
+	i.addMenuCheck(settingsMenu, "uncachedTests", "Run Tests Uncached (-count=1)", i.uncachedVar)
+	i.offlineVar = Variable(checkValue(i.config.WorkOffline))
+	i.addMenuCheck(settingsMenu, "toggleOffline", "Work Offline (GOPROXY=off)", i.offlineVar)
+	i.buildUnsavedVar = Variable(checkValue(i.config.BuildUnsaved))
+	i.addMenuCheck(settingsMenu, "toggleBuildUnsaved", "Build Unsaved Buffers Without Saving", i.buildUnsavedVar)
+	i.vendorVar = Variable(checkValue(i.config.VendorMode))
+	i.addMenuCheck(settingsMenu, "toggleVendor", "Use Vendor Directory (-mod=vendor)", i.vendorVar)
+	i.apiVar = Variable(checkValue(i.config.ExternalAPI))
+	i.addMenuCheck(settingsMenu, "toggleAPI", "External Tool API", i.apiVar)
+	i.menubar.AddCascade(Lbl(tr("Settings")), Underline(0), Mnu(settingsMenu))
 }
 
 // makeWidgets orchestrates the creation of all UI components.
 func (i *Ite) makeWidgets() {
+	i.makeRegions()
+	i.makeMenubar()
 	i.makeToolbar()
 	i.makeEditor()
 	i.makeStatusbar()
@@ -238,55 +772,76 @@ func (i *Ite) makeWidgets() {
 
 // makeLayout defines the grid geometry for the main application window.
 func (i *Ite) makeLayout() {
+	App.Configure(Mnu(i.menubar))
+
 	// Toolbar (Row 0, spans entire width)
-	Grid(i.toolbarFrame, Row(0), Column(0), Columnspan(2), Sticky(WE))
+	Grid(i.toolbarFrame, Row(0), Column(0), Sticky(WE))
 
-	// Main Editor Panel (Row 1, Column 0)
-	Grid(i.editText, Row(0), Column(0), Sticky(NEWS))
-	Grid(i.editVScrollbar, Row(0), Column(1), Sticky(NS))
-	GridRowConfigure(i.editFrame, 0, Weight(1))
-	GridColumnConfigure(i.editFrame, 0, Weight(1))
-	Grid(i.editFrame, Row(1), Column(0), Sticky(NEWS))
+	// Main Editor Panel (center region)
+	i.layoutEditorPanel()
+	i.arrangePanes(splitSideBySide)
+
+	// Outline Sidebar (left region), built once shown
+	if i.config.ShowOutline {
+		i.ensureOutline()
+	}
 
-	// Output Panel (Row 1, Column 1)
-	Grid(i.editText2, Row(0), Column(0), Sticky(NEWS))
-	Grid(i.editVScrollbar2, Row(0), Column(1), Sticky(NS))
+	// Output Panel (right region)
+	Grid(i.consoleTabs, Row(0), Column(0), Sticky(NEWS))
+	Grid(i.consoleFilterFrame, Row(1), Column(0), Sticky(WE))
 	GridRowConfigure(i.editFrame2, 0, Weight(1))
 	GridColumnConfigure(i.editFrame2, 0, Weight(1))
-	Grid(i.editFrame2, Row(1), Column(1), Sticky(NEWS))
+	i.dock(regionRight, i.editFrame2.Window, true)
+
+	// Docking regions (Row 1, spans entire width)
+	Grid(i.mainPane, Row(1), Column(0), Sticky(NEWS))
+	i.arrangeRegions()
 
-	// Status Bar (Row 2, spans entire width)
-	Grid(i.statusLabelCursor, Row(0), Column(0), Sticky(WE))
-	Grid(i.statusLabelFile, Row(0), Column(1), Sticky(WE))
-	GridColumnConfigure(i.statusFrame, 0, Weight(1))
-	Grid(i.statusFrame, Row(2), Column(0), Columnspan(2), Sticky(WE))
+	// Status Bar (Row 3, spans entire width), the search bar going above
+	i.layoutStatusbar()
+	Grid(i.statusFrame, Row(3), Column(0), Sticky(WE))
 
 	// Global Grid Weights (Resizing behavior)
-	GridColumnConfigure(App, 0, Weight(1)) // Editor takes 1 part
-	GridColumnConfigure(App, 1, Weight(3)) // Output takes 3 parts (seems large, but per design)
+	GridColumnConfigure(App, 0, Weight(1)) // Regions share the width through their sashes
 	GridRowConfigure(App, 1, Weight(1))    // Content area expands vertically
 }
 
-// bindShortcuts maps keyboard shortcuts to application functions.
+// layoutEditorPanel places the text and scrollbar of the active editor
+// pane in its frame.
+func (i *Ite) layoutEditorPanel() {
+	Grid(i.editText, Row(0), Column(0), Sticky(NEWS))
+	Grid(i.editVScrollbar, Row(0), Column(1), Sticky(NS))
+	GridRowConfigure(i.editFrame, 0, Weight(1))
+	GridColumnConfigure(i.editFrame, 0, Weight(1))
+}
+
+// bindShortcuts maps keyboard shortcuts to application functions and
+// installs the editor's own bindings.
 func (i *Ite) bindShortcuts() {
-	shortcuts := map[string]func(){
-		"<Control-n>":       i.onNew,
-		"<Control-o>":       i.onOpen,
-		"<Control-s>":       i.onSave,
-		"<Control-Shift-s>": i.onSaveAs,
-		"<Control-q>":       i.onQuit,
-		"<Control-b>":       i.onGoBuild,
-		"<Control-r>":       i.onGoRun,
-		"<Control-g>":       i.onGoToLine,
-		"<Control-z>":       i.onUndo,
-		"<Control-y>":       i.onRedo,
-	}
-	for key, cmd := range shortcuts {
-		Bind(App, key, Command(cmd))
-	}
+	i.bindKeys()
 	// Bind cursor movement events to update status bar
-	Bind(i.editText, "<ButtonRelease-1>", Command(i.updateCursorPosition))
-	Bind(i.editText, "<KeyRelease>", Command(i.updateCursorPosition))
+	Bind(i.editText, "<ButtonRelease-1>", Command(i.refreshCursorState))
+	Bind(i.editText, "<KeyRelease>", Command(i.onEditorKeyRelease))
+	Bind(i.editText, "<<Selection>>", Command(i.updateCursorPosition))
+	i.bindMouseSelection()
+	i.bindUndoGrouping()
+	i.bindReadOnly()
+	i.bindBlockSelection()
+	i.bindPaste()
+	i.bindComposition()
+	i.bindLinkedEditing()
+	i.bindHints()
+	i.bindPathCompletion()
+	i.bindAutoIndent()
+	i.bindAutoPairs()
+	i.bindAutospacing()
+	i.bindSnippets()
+	i.bindIndentKeys()
+	Bind(i.editText, "<Control-Button-1>", Command(i.onControlClick))
+	i.bindFolding()
+	i.bindModal()
+	i.bindMacro()
+	i.bindPane(i.active)
 }
 
 // -------------------------------------------------------------------------
@@ -296,102 +851,354 @@ func (i *Ite) bindShortcuts() {
 // onNew clears the editor to start a new file, checking for unsaved changes first.
 func (i *Ite) onNew() {
 	if i.promptSaveIfModified() {
+		if i.currentFile != "" {
+			i.notifyPlugins("close", i.currentFile)
+		}
+		i.removeSwap()
+		i.endLinkedEdit()
+		i.endSnippet()
+		i.cancelLoad()
+		i.setLargeFile(false)
 		i.editText.Clear()
+		i.clearBookmarks()
+		i.clearFolds()
+		i.configureEditorTags()
 		i.currentFile = ""
-		App.WmTitle(statusUntitled)
+		i.setReadOnly(false)
+		i.encoding = encUTF8
+		i.eol = eolLF
+		i.diskStamp = fileStamp{}
+		i.closeJournal()
+		i.resetUndo()
+		i.cursors = cursorHistory{}
+		i.updateTitle()
 		i.editText.SetModified(false)
-		i.updateCursorPosition()
+		i.refreshCursorState()
+		i.refreshOutline()
+		i.updateGutter()
 	}
 }
 
+// onCloseFile closes the current file, leaving an empty untitled buffer.
+func (i *Ite) onCloseFile() {
+	i.onNew()
+}
+
 // onOpen launches a file picker dialog and loads the selected file.
 func (i *Ite) onOpen() {
 	if !i.promptSaveIfModified() {
 		return
 	}
-	paths := GetOpenFile(Title("Open"), Initialdir("."), Filetypes([]FileType{
+	paths := GetOpenFile(Title("Open"), Initialdir(i.defaultDir()), Filetypes([]FileType{
 		{TypeName: "Go Files", Extensions: []string{"*.go"}, MacType: ""},
 		{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
 	}))
 	if len(paths) == 0 {
 		return
 	}
-	path := paths[0]
+	i.rememberDialogDir(paths[0])
+	if err := i.openFile(paths[0]); err != nil {
+		i.showError("Error opening file: " + err.Error())
+	}
+}
+
+// openFile loads path into the editor, replacing the current buffer
+// without asking to save it.
+func (i *Ite) openFile(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		i.showError("Error opening file: " + err.Error())
-		return
+		return err
 	}
+	i.removeSwap()
+	i.endLinkedEdit()
+	i.endSnippet()
+	i.endBlock()
+	i.cancelLoad()
 	i.editText.Clear()
-	i.editText.Insert("1.0", string(data))
+	i.clearBookmarks()
+	i.clearFolds()
+	i.cursors = cursorHistory{}
+	i.configureEditorTags()
+	i.setReadOnly(!fileWritable(path))
+	text, enc := decodeText(data)
+	i.encoding, i.eol = enc, detectEOL(text)
+	text = normalizeEOL(text)
+	i.visitFile(path)
+	if len(data) >= largeFileSize {
+		i.openLargeFile(path, text)
+		return nil
+	}
+	i.setLargeFile(false)
+	i.editText.Insert("1.0", text)
+	i.resetUndo()
+	i.openJournal(path, text)
+	i.protectHeader()
+	i.markDiagnostics()
+	i.editText.MarkSet("insert", "1.0")
 	i.currentFile = path
-	App.WmTitle(fmt.Sprintf("%s - ITE", filepath.Base(i.currentFile)))
+	i.recordDiskStamp()
+	i.refreshRunProfiles()
+	i.updateTitle()
 	i.editText.SetModified(false)
-	i.updateCursorPosition()
+	i.refreshCursorState()
+	i.refreshOutline()
+	i.restoreBookmarks()
+	i.updateGutter()
+	i.refreshGitBase(path)
+	i.refreshBranches()
+	i.offerRecovery()
+	i.markCoverage()
+	i.detectConflicts()
+	i.scheduleHealthCheck()
+	i.notifyPlugins("open", path)
+	return nil
 }
 
 // onSave writes the current content to disk. If no file is associated, calls Save As.
 func (i *Ite) onSave() {
+	i.save()
+}
+
+// save is onSave, reporting whether the buffer was saved: not when the
+// file changed on disk, which is asked about instead, nor when writing
+// fails or the Save As offered is cancelled.
+func (i *Ite) save() bool {
 	if i.currentFile == "" {
 		i.onSaveAs()
-		return
+		return !i.editText.Modified()
 	}
-	content := i.editText.Text()
-	if err := os.WriteFile(i.currentFile, []byte(content), defaultFilePerms); err != nil {
+	if i.readOnly {
+		i.offerWritableCopy(filepath.Base(i.currentFile) + " is open read-only.")
+		return !i.editText.Modified()
+	}
+	if i.changedOnDisk() {
+		// Don't clobber changes made by other programs unasked
+		if !i.changePrompt {
+			i.promptFileChanged()
+		}
+		return false
+	}
+	i.formatOnSave()
+	if err := i.writeBuffer(); errors.Is(err, fs.ErrPermission) {
+		i.offerWritableCopy("You can't write " + filepath.Base(i.currentFile) + ".")
+		return !i.editText.Modified()
+	} else if err != nil {
 		i.showError("Error saving file: " + err.Error())
-		return
+		return false
+	}
+	return true
+}
+
+// writeBuffer saves the editor content to the current file, atomically,
+// and marks the buffer as saved. Large buffers are written in the
+// background.
+func (i *Ite) writeBuffer() error {
+	if i.loading() {
+		return errStillLoading
+	}
+	content := i.editText.Text()
+	data, err := encodeText(applyEOL(content, i.eol), i.encoding)
+	if err != nil {
+		return err
+	}
+	if i.largeFile || len(data) >= backgroundSaveSize {
+		return i.writeInBackground(data, content)
 	}
-	App.WmTitle(fmt.Sprintf("%s - ITE", filepath.Base(i.currentFile)))
+	if err := writeFileAtomic(i.currentFile, data, defaultFilePerms); err != nil {
+		return err
+	}
+	i.recordDiskStamp()
+	i.recordJournal(content)
+	i.updateTitle()
 	i.editText.SetModified(false)
-	i.updateCursorPosition()
+	i.removeSwap()
+	i.refreshCursorState()
+	i.refreshOutline()
+	i.storeBookmarks()
+	i.refreshGitBase(i.currentFile)
+	i.notifyPlugins("save", i.currentFile)
+	return nil
 }
 
 // onSaveAs launches a file picker to save the content to a new location.
 func (i *Ite) onSaveAs() {
-	path := GetSaveFile(Title("Save as..."), Initialdir("."), Filetypes([]FileType{
+	path := GetSaveFile(Title("Save as..."), Initialdir(i.defaultDir()), Filetypes([]FileType{
 		{TypeName: "Go Files", Extensions: []string{"*.go"}, MacType: ""},
 		{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
 	}))
 	if path == "" {
 		return
 	}
+	i.rememberDialogDir(path)
 	if filepath.Ext(path) == "" {
 		path += defaultFileExtension
 	}
+	var op *fileOpRecorder
+	if !samePath(path, i.currentFile) {
+		op = i.beginFileOp("Save As " + filepath.Base(path))
+		op.created(path)
+	}
+	if op != nil && fileExists(path) {
+		// The file dialog confirmed the overwrite; keep the old file
+		// restorable if possible, but save anyway
+		if err := i.discardFile(path); err != nil {
+			i.showStatusHint("Old " + filepath.Base(path) + " overwritten: " + err.Error())
+		}
+	}
 	i.currentFile = path
+	i.diskStamp = fileStamp{} // The file dialog confirmed any overwrite
+	i.setReadOnly(false)
+	i.refreshRunProfiles()
 	i.onSave()
+	if op != nil {
+		i.commitFileOp(op)
+	}
 }
 
 // -------------------------------------------------------------------------
 // Edit Operations
 // -------------------------------------------------------------------------
 
-func (i *Ite) onCut()   { i.editText.Cut() }
-func (i *Ite) onCopy()  { i.editText.Copy() }
-func (i *Ite) onPaste() { i.editText.Paste() }
-func (i *Ite) onUndo()  { i.editText.Undo() }
-func (i *Ite) onRedo()  { i.editText.Redo() }
+// onCut cuts the selection unless it touches a read-only region.
+func (i *Ite) onCut() {
+	if i.activeBlock() != nil {
+		i.cutBlock()
+	} else if !i.blockProtectedSelection() {
+		i.editGroup(i.editText.Cut)
+	}
+}
+
+func (i *Ite) onCopy() {
+	if i.activeBlock() != nil {
+		i.copyBlock()
+	} else {
+		i.editText.Copy()
+	}
+}
+
+// onPaste pastes the clipboard unless the text it replaces, or the cursor,
+// lies in a read-only region.
+func (i *Ite) onPaste() {
+	if i.activeBlock() != nil {
+		i.pasteBlock()
+	} else if !i.blockProtectedSelection() {
+		i.editGroup(i.pasteClipboard)
+	}
+}
+
+// onUndo undoes the last edit. Past the start of the editor's undo stack
+// it goes back to the previously saved version of the file.
+func (i *Ite) onUndo() {
+	i.breakUndoGroup()
+	if !i.journalUndo() {
+		i.editText.Undo()
+	}
+}
+
+// onRedo redoes the last undone edit or saved version.
+func (i *Ite) onRedo() {
+	i.breakUndoGroup()
+	if !i.journalRedo() {
+		i.editText.Redo()
+	}
+}
 
 // onQuit attempts to close the application, checking for unsaved changes.
 func (i *Ite) onQuit() {
-	if i.promptSaveIfModified() {
-		Destroy(App)
+	if !i.finishSaves() {
+		return // The error is shown and the buffer modified again
+	}
+	for _, p := range i.panes {
+		// Focus the pane asked about, so closing the dialog keeps it active
+		i.activatePane(p)
+		Focus(i.editText)
+		if !i.promptSaveIfModified() {
+			return
+		}
 	}
+	if !i.finishSaves() { // Including the saves asked for above
+		return
+	}
+	i.shutdown()
+	for _, p := range i.panes {
+		i.withPane(p, i.removeSwap)
+	}
+	i.removeAssertOverlay()
+	i.removeBuildOverlays()
+	i.closeConsoleLogs()
+	i.removeProfileDir()
+	i.stopPlugins()
+	i.stopAPI()
+	i.recordRegionSizes()
+	i.recordWindow()
+	i.saveConfig()
+	Destroy(App)
 }
 
-// updateCursorPosition updates the status bar with the current cursor location
-// and visual indication of whether the file has been modified (unsaved).
+// updateCursorPosition updates the status bar with the current cursor location,
+// the size of the selection, if any, and visual indication of whether the
+// file has been modified (unsaved).
 func (i *Ite) updateCursorPosition() {
-	pos := i.editText.Index("insert")
-	i.statusLabelCursor.Configure(Txt("Line:Column " + pos))
+	status := tr("Line:Column") + " " + i.editText.Index("insert")
+	if mode := i.modeStatus(); mode != "" {
+		status = mode + "  " + status
+	}
+	if i.macro.recording {
+		status = "REC  " + status
+	}
+	if time.Now().Before(i.statusHintUntil) {
+		status += " - " + i.statusHint
+	}
+	i.statusLabelCursor.Configure(Txt(status))
+	carets := 0
+	if b := i.activeBlock(); b != nil {
+		top, bottom, _, _ := b.bounds()
+		carets = bottom - top + 1
+	}
+	i.statusSelection.Configure(Txt(selectionInfo(i.editText, carets)))
+	i.updateModuleSegment()
+	i.updateBuildTagsSegment()
+	i.statusEncoding.Configure(Txt(orDefault(i.encoding, encUTF8)))
+	i.statusEOL.Configure(Txt(orDefault(i.eol, eolLF)))
 	if i.editText.Modified() {
 		i.statusLabelFile.Configure(
-			Foreground(colRed),
-			Txt(statusNotSaved))
+			Foreground(theme.Error),
+			Txt(tr(statusNotSaved)))
 	} else {
 		i.statusLabelFile.Configure(
-			Foreground(colDarkGreen),
-			Txt(statusSaved))
+			Foreground(theme.Success),
+			Txt(tr(statusSaved)))
+	}
+}
+
+// refreshCursorState updates everything that depends on the cursor position
+// or the buffer contents: the status bar, the prose guide and the bracket
+// highlight.
+func (i *Ite) refreshCursorState() {
+	i.recordCursor()
+	i.scheduleVirtualText()
+	i.updateCursorPosition()
+	i.updateCurrentLine()
+	i.updateProseGuide()
+	i.updateBracketMatch()
+	i.updateConflictBar()
+}
+
+// onEditorKeyRelease runs the per-keystroke hooks of the main editor.
+func (i *Ite) onEditorKeyRelease() {
+	defer traceRegion(context.Background(), "key")()
+	if i.composing {
+		return // Wait for the input method to commit the text
+	}
+	i.syncLinkedEdit()
+	i.refreshCursorState()
+	i.scheduleOutline()
+	i.scheduleGutter()
+	i.scheduleHealthCheck()
+	i.updateSignatureHelp()
+	i.updatePathCompletion()
+	if i.config.TypewriterScrolling {
+		i.centerCursorLine()
 	}
 }
 
@@ -402,15 +1209,15 @@ func (i *Ite) onGoToLine() {
 
 	// Dialog Layout
 	frame := dialog.TFrame()
-	Grid(frame, Row(0), Column(0), Padx(10), Pady(10))
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
 	label := frame.TLabel(Txt("Line:Column (e.g. 12.5)"))
-	Grid(label, Row(0), Column(0), Sticky(W), Pady(5))
+	Grid(label, Row(0), Column(0), Sticky(W), Pady(px(5)))
 	entry := frame.TEntry(Width(20), Textvariable(""))
-	Grid(entry, Row(1), Column(0), Pady(5))
+	Grid(entry, Row(1), Column(0), Pady(px(5)))
 	Focus(entry)
 
 	btnFrame := frame.TFrame()
-	Grid(btnFrame, Row(2), Column(0), Pady(10))
+	Grid(btnFrame, Row(2), Column(0), Pady(px(10)))
 
 	// Navigation Logic
 	goToLine := func() {
@@ -440,17 +1247,17 @@ func (i *Ite) onGoToLine() {
 		// Move cursor and scroll
 		i.editText.MarkSet("insert", index)
 		i.editText.See(index)
-		i.updateCursorPosition()
+		i.refreshCursorState()
 		Destroy(dialog)
 		Focus(i.editText)
 	}
 
 	okBtn := btnFrame.TButton(Txt("OK"), Command(goToLine))
-	Grid(okBtn, Row(0), Column(0), Padx(5))
+	Grid(okBtn, Row(0), Column(0), Padx(px(5)))
 	cancelBtn := btnFrame.TButton(Txt("Cancel"), Command(func() {
 		Destroy(dialog)
 	}))
-	Grid(cancelBtn, Row(0), Column(1), Padx(5))
+	Grid(cancelBtn, Row(0), Column(1), Padx(px(5)))
 
 	// Dialog shortcuts
 	Bind(entry, "<Return>", Command(goToLine))
@@ -461,95 +1268,403 @@ func (i *Ite) onGoToLine() {
 // Build and Execution Logic
 // -------------------------------------------------------------------------
 
-// runCommand executes a Go command asynchronously.
-// It sends the resulting output to i.buildChan to be picked up by the UI poller.
-func (i *Ite) runCommand(args []string, initialMsg string) {
-	// Reset output view
-	i.editText2.Configure(State("normal"))
-	i.editText2.Clear()
-	i.editText2.Insert("1.0", initialMsg)
-	i.editText2.Configure(State("disabled"))
-
-	go func() {
-		cmd := exec.Command("go", args...)
-		if i.currentFile != "" {
-			cmd.Dir = filepath.Dir(i.currentFile)
-		}
+// consoleMsg is a chunk of command output delivered to the UI thread.
+type consoleMsg struct {
+	run    int         // Identifier of the command run that produced the text
+	text   string      // Output text, usually a single line with its newline
+	tag    string      // Optional console tag applied to the text
+	click  func()      // Run on the UI thread when the text is clicked, if set
+	url    string      // Address of a server announced by the text, if any
+	diag   *diagnostic // Linter diagnostic reported by the text, if any
+	stdout bool        // The command wrote the text to standard output
+	stderr bool        // The command wrote the text to standard error
+	done   bool        // Set on the last message of a run
+}
 
-		output, err := cmd.CombinedOutput()
-		var msg string
+// outputDecoder turns the raw output lines of a command into console text.
+type outputDecoder interface {
+	decode(line string) []consoleMsg // Called for every line of output
+	summary() []consoleMsg           // Called once the output ends
+}
 
-		// Format output message
-		if err != nil {
-			if len(output) == 0 {
-				msg = fmt.Sprintf("%s failed: %v\n", args[0], err)
-			} else {
-				msg = fmt.Sprintf("%s failed:\n%s", strings.Title(args[0]), string(output))
+// plainOutput shows command output unchanged.
+type plainOutput struct{}
+
+func (plainOutput) decode(line string) []consoleMsg { return []consoleMsg{{text: line}} }
+func (plainOutput) summary() []consoleMsg           { return nil }
+
+// runCommand starts a Go command in console c, from the root of the
+// project, and streams its combined stdout and stderr line by line, as
+// rendered by decoder, to the console through sendConsole. Any command
+// still running in c is stopped first. The command waits in a queue while
+// maxJobs others are running.
+func (i *Ite) runCommand(c *console, args []string, initialMsg string, decoder outputDecoder) {
+	i.runCommands(c, [][]string{append([]string{"go"}, args...)}, initialMsg, decoder)
+}
+
+// runCommands is runCommand for a sequence of programs, each given with its
+// arguments, run one after the other through the same decoder. Stopping
+// one skips the rest.
+func (i *Ite) runCommands(c *console, steps [][]string, initialMsg string, decoder outputDecoder) {
+	j := &job{c: c, decoder: decoder}
+	for _, args := range steps {
+		j.steps = append(j.steps, jobStep{name: commandName(args), args: args})
+	}
+	i.runJob(j, initialMsg)
+}
+
+// runJob is runCommands for a job set up by the caller, which fills in
+// the console, steps, decoder and, optionally, extra environment and the
+// working directory, the root of the project by default.
+func (i *Ite) runJob(j *job, initialMsg string) {
+	c := j.c
+	i.stopCommand(c)
+	i.dequeue(c)
+	i.runID++
+	c.runID = i.runID
+
+	// Output of earlier runs stays above, a blank line apart
+	if c.name == consoleRun {
+		i.setServerBadge("")
+	}
+	if c.text.Index("end-1c") != "1.0" {
+		i.appendConsole(c, "\n", "")
+	}
+	i.appendConsole(c, tr(initialMsg), tagRunStart)
+	i.consoleTabs.Select(c.frame)
+
+	if j.dir == "" && i.currentFile != "" {
+		j.dir = i.projectDir()
+	}
+	c.runDir = j.dir
+	c.root = ""
+	if i.currentFile != "" {
+		c.root = i.projectDir()
+	}
+	j.run = c.runID
+	i.enqueue(j)
+}
+
+// startJob runs the steps of j in the background.
+func (i *Ite) startJob(j *job) {
+	i.jobs[j.run] = j
+	i.updateJobsSegment()
+	i.procMu.Lock()
+	j.c.active = j
+	i.procMu.Unlock()
+	pending := slices.Collect(maps.Values(i.saving))
+	i.goBackground(func(context.Context) {
+		for _, s := range pending {
+			<-s.written // Commands must see the files being saved complete
+		}
+		ctx, endTask := traceTask("job " + j.c.name)
+		defer endTask()
+		defer func() {
+			i.procMu.Lock()
+			if j.c.active == j {
+				j.c.active = nil
+			}
+			i.procMu.Unlock()
+		}()
+		for n, step := range j.steps {
+			endStep := traceRegion(ctx, "step "+step.name)
+			stopped, res := i.runStep(j, step)
+			endStep()
+			last := n == len(j.steps)-1 || stopped || (res.err != nil && step.required)
+			if last {
+				for _, msg := range j.decoder.summary() {
+					msg.run = j.run
+					i.sendConsole(msg)
+				}
+			}
+			i.sendConsole(consoleMsg{run: j.run, text: commandStatus(step.name, res, stopped), done: last})
+			if last {
+				if j.done != nil {
+					i.Dispatch(func() { j.done(res, stopped) })
+				}
+				return
 			}
-		} else if len(output) == 0 {
-			msg = fmt.Sprintf("%s successful\n", strings.Title(args[0]))
-		} else {
-			msg = fmt.Sprintf("%s output:\n%s", strings.Title(args[0]), string(output))
 		}
+	})
+}
 
-		// Non-blocking send to UI channel.
-		// If the channel is full, we drain it to ensure the latest message is sent.
-		select {
-		case i.buildChan <- msg:
-		default:
-			select {
-			case <-i.buildChan: // Drain old message
-			default:
-			}
-			i.buildChan <- msg
+// runStep runs a single program of job j and waits for it, streaming its
+// output. It reports whether the user stopped the job.
+func (i *Ite) runStep(j *job, step jobStep) (stopped bool, res procResult) {
+	spec := procSpec{args: step.args, dir: j.dir, env: j.env}
+	if step.dir != "" {
+		spec.dir = step.dir
+	}
+
+	i.procMu.Lock()
+	if j.stopped {
+		// Stopped between two steps
+		i.procMu.Unlock()
+		return true, procResult{}
+	}
+	p, err := i.runner.start(spec)
+	if err != nil {
+		i.procMu.Unlock()
+		return false, procResult{exitCode: -1, err: err}
+	}
+	j.proc = p
+	i.procMu.Unlock()
+
+	res = streamOutput(p, func(line string, stderr bool) {
+		for _, msg := range j.decoder.decode(outputText(line)) {
+			msg.run, msg.stdout, msg.stderr = j.run, !stderr, stderr
+			i.sendConsole(msg)
 		}
-	}()
+	})
+
+	i.procMu.Lock()
+	stopped = j.stopped
+	j.proc = nil
+	i.procMu.Unlock()
+	return stopped, res
+}
+
+// commandName returns the name under which the program run with args is
+// reported: the subcommand for the go tool, the program name otherwise.
+func commandName(args []string) string {
+	if args[0] == "go" && len(args) > 1 {
+		return args[1]
+	}
+	return strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+}
+
+// commandStatus formats the final console line of a command run, with
+// the time the program took, if it ran.
+func commandStatus(name string, res procResult, stopped bool) string {
+	title := strings.Title(name)
+	took := ""
+	if res.duration > 0 {
+		took = fmt.Sprintf(" (%v)", res.duration.Round(time.Millisecond))
+	}
+	switch {
+	case stopped:
+		return title + " stopped" + took + "\n"
+	case res.err != nil:
+		return fmt.Sprintf("%s failed: %v%s\n", title, res.err, took)
+	default:
+		return title + " successful" + took + "\n"
+	}
 }
 
-// onGoBuild triggers 'go build' on the current project.
+// stopCommand kills the command running in c, if any, together with every
+// process it spawned (go run builds and then execs a child binary).
+func (i *Ite) stopCommand(c *console) error {
+	return i.signalCommand(c, true)
+}
+
+// signalCommand stops the command running in c, if any: with force set it
+// is killed (SIGKILL), otherwise asked to terminate (SIGTERM), which lets
+// servers shut down cleanly.
+func (i *Ite) signalCommand(c *console, force bool) error {
+	i.procMu.Lock()
+	defer i.procMu.Unlock()
+	j := c.active
+	if j == nil {
+		return nil
+	}
+	j.stopped = true
+	if j.proc == nil {
+		return nil // Between two steps
+	}
+	return j.proc.signal(force)
+}
+
+// onStop handles the Stop button: it stops the command of the selected
+// console, using the stop signal chosen in the settings, or drops it from
+// the queue.
+func (i *Ite) onStop() {
+	c := i.selectedConsole()
+	if c.queued != nil {
+		i.dequeue(c)
+		i.appendConsole(c, commandStatus(c.name, procResult{}, true), "")
+		return
+	}
+	if err := i.signalCommand(c, !i.config.GracefulStop); err != nil {
+		i.showError("Error stopping process: " + err.Error())
+	}
+}
+
+// onGoBuild triggers 'go build' on the build target of the current
+// project, from its root.
 func (i *Ite) onGoBuild() {
 	if i.currentFile == "" {
 		i.showError(statusNoFile)
 		return
 	}
-	if i.editText.Modified() {
-		i.onSave()
+	d := &buildDecoder{}
+	if isCommandFile(i.currentFile, i.editText.Text()) {
+		d.analyze = i.onAnalyzeBinarySize
+	}
+	s := i.projectSettingsOrError()
+	j := &job{c: i.console(consoleBuild), decoder: d, dir: i.projectDir()}
+	args := append(append([]string{"go", "build"}, i.tagsArgs()...), s.buildTarget())
+	j.steps = []jobStep{{name: "build", args: args}}
+	if err := i.prepareBuild(j); err != nil {
+		i.showError("Go Build: " + err.Error())
+		return
 	}
-	i.runCommand([]string{"build", "./..."}, statusBuilding)
+	i.runJob(j, statusBuilding)
 }
 
-// onGoRun triggers 'go run' on the current directory.
+// onGoRun triggers 'go run' on the current directory, with the arguments,
+// environment and working directory of the selected run profile.
 func (i *Ite) onGoRun() {
 	if i.currentFile == "" {
 		i.showError(statusNoFile)
 		return
 	}
-	if i.editText.Modified() {
-		i.onSave()
+	j, err := i.runProfileJob()
+	if err == nil {
+		err = i.prepareBuild(j)
 	}
-	i.runCommand([]string{"run", "."}, statusRunning)
+	if err != nil {
+		i.showError("Go Run: " + err.Error())
+		return
+	}
+	i.runJob(j, statusRunning)
 }
 
-// pollBuildOutput checks the build channel for messages from background goroutines.
-// This is necessary because Tk widgets must only be updated from the main thread.
-func (i *Ite) pollBuildOutput() {
-	select {
-	case msg := <-i.buildChan:
-		i.editText2.Configure(State("normal"))
-		i.editText2.Clear()
-		i.editText2.Insert("1.0", msg)
-		i.editText2.Configure(State("disabled"))
-	default:
-		// No messages
+// sendConsole queues msg, from a command goroutine, for the consoles. It
+// blocks while consoleBacklog messages are waiting, so a command flooding
+// its output can't outrun the UI.
+func (i *Ite) sendConsole(msg consoleMsg) {
+	i.outMu.Lock()
+	for len(i.consoleOut) >= consoleBacklog && i.quitCtx.Err() == nil {
+		i.outCond.Wait()
+	}
+	i.consoleOut = append(i.consoleOut, msg)
+	first := len(i.consoleOut) == 1
+	i.outMu.Unlock()
+	if first {
+		i.Dispatch(i.flushConsoleOutput)
+	}
+}
+
+// flushConsoleOutput shows the queued command output on the UI thread.
+// Output of runs other than the latest one of each console is discarded;
+// consecutive messages for the same console sharing a tag are inserted
+// together.
+func (i *Ite) flushConsoleOutput() {
+	i.outMu.Lock()
+	msgs := i.consoleOut
+	i.consoleOut = nil
+	i.outCond.Broadcast()
+	i.outMu.Unlock()
+
+	var sb strings.Builder
+	var c *console
+	tag := ""
+	finished := false
+	flush := func() {
+		if sb.Len() > 0 {
+			i.appendConsole(c, sb.String(), tag)
+			sb.Reset()
+		}
+	}
+	for _, msg := range msgs {
+		if msg.done {
+			i.jobDone(msg.run)
+			finished = true
+		}
+		mc := i.runConsole(msg.run)
+		if mc == nil {
+			continue
+		}
+		mtag := msg.tag
+		if mtag == "" && msg.stderr {
+			mtag = tagStderr
+		} else if mtag == "" && msg.stdout {
+			mtag = tagStdout
+		}
+		if mc != c || mtag != tag || msg.click != nil {
+			flush()
+			c, tag = mc, mtag
+		}
+		if msg.click != nil {
+			line, _ := parseIndex(c.text.Index("end-1c"))
+			c.clicks[line] = msg.click
+		}
+		if msg.diag != nil {
+			i.addDiagnostic(*msg.diag)
+		}
+		if msg.url != "" {
+			i.setServerBadge(msg.url)
+		}
+		if msg.done && c.name == consoleRun {
+			i.setServerBadge("")
+		}
+		sb.WriteString(msg.text)
+	}
+	flush()
+	if finished && i.runningJobs() == 0 {
+		// Commands such as go generate or gofmt may have rewritten open files
+		i.offerReloads()
+	}
+}
+
+// appendConsole adds text, with the given tag if not empty, at the end of
+// console c, turns source locations in it into links and scrolls to it
+// unless scroll lock is on. Every line gets a timestamp, shown only when
+// enabled, and is logged to a file when that is on.
+func (i *Ite) appendConsole(c *console, text, tag string) {
+	i.logConsole(c, text)
+	if i.config.RelativePaths {
+		text = relativizeLocations(text, c.runDir, c.root)
+	}
+	first, _ := parseIndex(c.text.Index("end-1c"))
+	stamp := time.Now().Format(consoleTimeFormat)
+	c.text.Configure(State("normal"))
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if line != "\n" && strings.HasSuffix(c.text.Index("end-1c"), ".0") {
+			c.text.Insert("end", stamp, tagTimestamp)
+		}
+		if tag != "" {
+			c.text.Insert("end", line, tag)
+		} else {
+			c.text.Insert("end", line)
+		}
+	}
+	c.text.Configure(State("disabled"))
+	last, _ := parseIndex(c.text.Index("end-1c"))
+	i.linkifyConsole(c, first, last)
+	i.filterConsoleLines(c, first, last)
+	i.trimConsole(c)
+	if i.consoleScrollLock.Variable() != "1" {
+		c.text.See("end")
 	}
-	// Schedule next poll
-	TclAfter(pollInterval, i.pollBuildOutput)
 }
 
 // -------------------------------------------------------------------------
 // Helper Functions
 // -------------------------------------------------------------------------
 
+// addBindtag inserts tag into the bind tags of w, right before the tag
+// named before, or first when before is not among them.
+func addBindtag(w *Window, tag, before string) {
+	current := Bindtags(w)
+	pos := slices.Index(current, before)
+	if pos < 0 {
+		pos = 0
+	}
+	tags := make([]any, 0, len(current)+1)
+	for _, t := range current[:pos] {
+		tags = append(tags, t)
+	}
+	tags = append(tags, tag)
+	for _, t := range current[pos:] {
+		tags = append(tags, t)
+	}
+	Bindtags(w, tags...)
+}
+
 // promptSaveIfModified checks if the current file has unsaved changes.
 // Returns true if the action can proceed (saved, discarded, or not modified),
 // or false if the user cancelled.
@@ -557,11 +1672,10 @@ func (i *Ite) promptSaveIfModified() bool {
 	if !i.editText.Modified() {
 		return true
 	}
-	resp := MessageBox(Icon("question"), Title("Unsaved Changes"), Msg("Save changes?"), Detail("Your changes will be lost if you don't save them."), Type("yesnocancel"))
+	resp := messageBox(Icon("question"), Title("Unsaved Changes"), Msg("Save changes?"), Detail("Your changes will be lost if you don't save them."), Type("yesnocancel"))
 	switch resp {
 	case "yes":
-		i.onSave()
-		return true
+		return i.save() // A file changed on disk is asked about first
 	case "no":
 		return true // Discard changes
 	case "cancel":
@@ -571,7 +1685,16 @@ func (i *Ite) promptSaveIfModified() bool {
 	}
 }
 
-// showError displays a modal error dialog.
+// saveConfig persists the user preferences, reporting failures to the user.
+func (i *Ite) saveConfig() {
+	if err := i.config.save(); err != nil {
+		i.showError("Error saving settings: " + err.Error())
+	}
+}
+
+// showError displays a modal error dialog. Long or multi-line messages
+// are summarized, with the full text under Details.
 func (i *Ite) showError(msg string) {
-	MessageBox(Icon("error"), Title("Error"), Msg(msg), Type("ok"))
+	summary, detail := splitErrorMessage(msg)
+	i.showErrorDetail(tr(summary), detail)
 }
@@ -0,0 +1,95 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Memory Guardrails
+// -------------------------------------------------------------------------
+
+// The structures growing with the length of a session are capped, the
+// oldest data going first: the undo history of each buffer, the lines of
+// each console and the files of the Quick Open index. The caps are the
+// maxUndoSteps, maxConsoleLines and maxIndexedFiles settings of the config
+// file; the Doctor window shows how much of them is in use.
+const (
+	defaultMaxUndoSteps    = 1000   // Undo steps kept per buffer
+	defaultMaxConsoleLines = 20000  // Lines kept per console
+	defaultMaxIndexedFiles = 100000 // Files indexed per project
+)
+
+// orLimit returns n, or fallback when n isn't a positive cap.
+func orLimit(n, fallback int) int {
+	if n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// undoLimit returns the undo steps kept per buffer.
+func (i *Ite) undoLimit() int {
+	return orLimit(i.config.MaxUndoSteps, defaultMaxUndoSteps)
+}
+
+// consoleLineLimit returns the lines kept per console.
+func (i *Ite) consoleLineLimit() int {
+	return orLimit(i.config.MaxConsoleLines, defaultMaxConsoleLines)
+}
+
+// indexLimit returns the files indexed per project.
+func (i *Ite) indexLimit() int {
+	return orLimit(i.config.MaxIndexedFiles, defaultMaxIndexedFiles)
+}
+
+// trimConsole deletes the oldest lines of c beyond its cap, moving the
+// actions of the clickable lines with the lines left.
+func (i *Ite) trimConsole(c *console) {
+	lines, _ := parseIndex(c.text.Index("end-1c"))
+	excess := lines - i.consoleLineLimit()
+	if excess <= 0 {
+		return
+	}
+	c.text.Configure(State("normal"))
+	c.text.Delete("1.0", fmt.Sprintf("%d.0", excess+1))
+	c.text.Configure(State("disabled"))
+	clicks := make(map[int]func(), len(c.clicks))
+	for line, click := range c.clicks {
+		if line > excess {
+			clicks[line-excess] = click
+		}
+	}
+	c.clicks = clicks
+}
+
+// showMemoryUsage adds to the Doctor window view the memory used by Go
+// and by the capped structures.
+func (i *Ite) showMemoryUsage(view *TextWidget) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	var b strings.Builder
+	b.WriteString("\nMemory:\n")
+	fmt.Fprintf(&b, "    Go heap: %s, %s obtained from the system\n",
+		formatBytes(int64(stats.HeapAlloc)), formatBytes(int64(stats.Sys)))
+	chars := 0
+	for _, p := range i.panes {
+		chars += len(p.text.Get("1.0", "end-1c")[0])
+	}
+	fmt.Fprintf(&b, "    Buffers: %d, %s of text, up to %d undo steps each\n",
+		len(i.panes), formatBytes(int64(chars)), i.undoLimit())
+	for _, c := range i.consoles {
+		lines, _ := parseIndex(c.text.Index("end-1c"))
+		fmt.Fprintf(&b, "    %s console: %d of %d lines\n", c.name, lines, i.consoleLineLimit())
+	}
+	fmt.Fprintf(&b, "    File index: %d of %d files\n", len(i.files.files), i.indexLimit())
+	view.Configure(State("normal"))
+	view.Insert("end", b.String())
+	view.Configure(State("disabled"))
+}
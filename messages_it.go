@@ -0,0 +1,361 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+// messagesIT is the Italian catalog, by English message.
+var messagesIT = map[string]string{
+	// Menus
+	"Conflicts": "Conflitti",
+	"Edit":      "Modifica",
+	"File":      "File",
+	"Generate":  "Genera",
+	"Git":       "Git",
+	"Help":      "Aiuto",
+	"Insert":    "Inserisci",
+	"Lines":     "Righe",
+	"Macro":     "Macro",
+	"Navigate":  "Naviga",
+	"Plugins":   "Plugin",
+	"Settings":  "Impostazioni",
+	"Tools":     "Strumenti",
+	"View":      "Visualizza",
+
+	// Commands
+	"Align":                        "Allinea",
+	"Analyze Binary Size":          "Analizza dimensione del binario",
+	"Run Query":                    "Esegui query",
+	"Migrate Up":                   "Applica le migrazioni",
+	"Migrate Down":                 "Annulla l'ultima migrazione",
+	"Migration Status":             "Stato delle migrazioni",
+	"Docker Compose":               "Docker Compose",
+	"Set Database":                 "Imposta database",
+	"Assert Selection":             "Verifica selezione",
+	"Check Spelling of Names":      "Controlla ortografia dei nomi",
+	"Clear Build Tags":             "Azzera tag di build",
+	"Clear Console":                "Pulisci console",
+	"Clear Coverage Marks":         "Rimuovi segni di copertura",
+	"Close Editor Pane":            "Chiudi riquadro dell'editor",
+	"Close File":                   "Chiudi file",
+	"Close Project Folder":         "Chiudi cartella del progetto",
+	"Command Palette":              "Tavolozza dei comandi",
+	"Compare Files":                "Confronta file",
+	"Compare with Saved":           "Confronta con il salvato",
+	"Convert Line Endings to CRLF": "Converti fine riga in CRLF",
+	"Convert Line Endings to LF":   "Converti fine riga in LF",
+	"Convert Number":               "Converti numero",
+	"Copy":                         "Copia",
+	"Copy Unquoted":                "Copia senza virgolette",
+	"Cut":                          "Taglia",
+	"Delete Lines":                 "Elimina righe",
+	"Display Scaling":              "Scala di visualizzazione",
+	"Export Settings":              "Esporta impostazioni",
+	"Import Settings":              "Importa impostazioni",
+	"Reset Settings":               "Ripristina impostazioni",
+	"Doctor":                       "Diagnostica",
+	"Duplicate Lines":              "Duplica righe",
+	"Escape Analysis":              "Analisi di escape",
+	"Exit":                         "Esci",
+	"Export Console as PDF":        "Esporta console in PDF",
+	"Export as HTML":               "Esporta in HTML",
+	"Find":                         "Trova",
+	"Find in Files":                "Trova nei file",
+	"Focus Console":                "Vai alla console",
+	"Focus Editor":                 "Vai all'editor",
+	"Focus Find Results":           "Vai ai risultati della ricerca",
+	"Focus Next Panel":             "Vai al pannello successivo",
+	"Focus Outline":                "Vai alla struttura",
+	"Focus Previous Panel":         "Vai al pannello precedente",
+	"Fold All":                     "Comprimi tutto",
+	"Format File":                  "Formatta file",
+	"Generate .gitignore":          "Genera .gitignore",
+	"Generate License":             "Genera licenza",
+	"Git Commit":                   "Git Commit",
+	"Git Diff with HEAD":           "Git Diff con HEAD",
+	"Git Stash":                    "Git Stash",
+	"Git Stash Pop":                "Git Stash Pop",
+	"Git Status":                   "Git Status",
+	"Go Benchmarks":                "Go Benchmark",
+	"Go Build":                     "Go Build",
+	"Go Get":                       "Go Get",
+	"Go Mod Init":                  "Go Mod Init",
+	"Go Mod Tidy":                  "Go Mod Tidy",
+	"Go Mod Vendor":                "Go Mod Vendor",
+	"Go Run":                       "Go Run",
+	"Go Test":                      "Go Test",
+	"Go Test with Coverage":        "Go Test con copertura",
+	"Go to Definition":             "Vai alla definizione",
+	"Go to Line":                   "Vai alla riga",
+	"OpenAPI Preview":              "Anteprima OpenAPI",
+	"gRPC Client":                  "Client gRPC",
+	"HTTP Client":                  "Client HTTP",
+	"Insert Doc Comment":           "Inserisci commento di documentazione",
+	"Insert Timestamp":             "Inserisci data e ora",
+	"Insert UUID":                  "Inserisci UUID",
+	"Insert Unix Time":             "Inserisci ora Unix",
+	"Join Lines":                   "Unisci righe",
+	"Jump to Matching Bracket":     "Vai alla parentesi corrispondente",
+	"Keyboard Shortcuts":           "Scorciatoie da tastiera",
+	"Lint":                         "Lint",
+	"List Bookmarks":               "Elenca segnalibri",
+	"Move Declaration to File":     "Sposta dichiarazione in un file",
+	"Move Lines Down":              "Sposta righe in basso",
+	"Move Lines Up":                "Sposta righe in alto",
+	"Mutate Selection":             "Muta selezione",
+	"New File":                     "Nuovo file",
+	"Next Bookmark":                "Segnalibro successivo",
+	"Next Merge Conflict":          "Conflitto di merge successivo",
+	"Next Stack Frame":             "Frame dello stack successivo",
+	"Open Console Log":             "Apri log della console",
+	"Open File":                    "Apri file",
+	"Open Folder as Project":       "Apri cartella come progetto",
+	"Other Editor Pane":            "Altro riquadro dell'editor",
+	"Paste":                        "Incolla",
+	"Paste as Go String":           "Incolla come stringa Go",
+	"Pick Color":                   "Scegli colore",
+	"Play Macro":                   "Esegui macro",
+	"Play Macro N Times":           "Esegui macro N volte",
+	"Preferences":                  "Preferenze",
+	"Previous Bookmark":            "Segnalibro precedente",
+	"Cursor Undo":                  "Annulla posizione del cursore",
+	"Cursor Redo":                  "Ripristina posizione del cursore",
+	"Previous Merge Conflict":      "Conflitto di merge precedente",
+	"Previous Stack Frame":         "Frame dello stack precedente",
+	"Print":                        "Stampa",
+	"Process Inspector":            "Ispettore dei processi",
+	"Project Settings":             "Impostazioni del progetto",
+	"Protect Selection":            "Proteggi selezione",
+	"Quick Open":                   "Apertura rapida",
+	"Complete with Providers":      "Completa con i fornitori",
+	"Completions from":             "Completamenti da",
+	"Completions:":                 "Completamenti:",
+	"No completion provider enabled: see the Plugins menu": "Nessun fornitore di completamenti attivo: vedi il menu Plugin",
+	"Move File to Other Pane":                              "Sposta il file nell'altro riquadro",
+	"Move to Other Pane":                                   "Sposta nell'altro riquadro",
+	"Reset Layout":                                         "Ripristina disposizione",
+	"Save the buffer first to move it":                     "Salva prima il buffer per spostarlo",
+	"Switch File":                                          "Cambia file",
+	"Record Macro":                                         "Registra macro",
+	"Redo":                                                 "Ripeti",
+	"Reflow Comment":                                       "Riformatta commento",
+	"Regex Tester":                                         "Tester di espressioni regolari",
+	"Reload Environment":                                   "Ricarica ambiente",
+	"Rename Package":                                       "Rinomina pacchetto",
+	"Replace":                                              "Sostituisci",
+	"Resolve Conflict with Both":                           "Risolvi conflitto con entrambi",
+	"Resolve Conflict with Ours":                           "Risolvi conflitto con i nostri",
+	"Resolve Conflict with Theirs":                         "Risolvi conflitto con i loro",
+	"Review Commits":                                       "Revisiona commit",
+	"Review Patch":                                         "Revisiona patch",
+	"Run Last Task":                                        "Esegui ultimo task",
+	"Run Profiles":                                         "Profili di esecuzione",
+	"Run Task":                                             "Esegui task",
+	"Save":                                                 "Salva",
+	"Save As":                                              "Salva con nome",
+	"Show Assembly":                                        "Mostra assembly",
+	"Show Documentation":                                   "Mostra documentazione",
+	"Sort Lines":                                           "Ordina righe",
+	"Split Editor Side by Side":                            "Dividi editor affiancato",
+	"Split Editor Stacked":                                 "Dividi editor sovrapposto",
+	"Stop":                                                 "Ferma",
+	"Stop Recording Macro":                                 "Ferma registrazione macro",
+	"Structural Replace":                                   "Sostituzione strutturale",
+	"Task History":                                         "Cronologia dei task",
+	"Toggle Auto-Closing Pairs":                            "Attiva/disattiva chiusura automatica delle coppie",
+	"Toggle Autospacing":                                   "Attiva/disattiva spaziatura automatica",
+	"Toggle Path Completion":                               "Attiva/disattiva completamento dei percorsi",
+	"Toggle Block Comment":                                 "Attiva/disattiva commento a blocco",
+	"Toggle Bookmark":                                      "Attiva/disattiva segnalibro",
+	"Toggle Bottom Panel":                                  "Mostra/nascondi pannello inferiore",
+	"Toggle Building Unsaved Buffers":                      "Attiva/disattiva build dei buffer non salvati",
+	"Toggle Console Log File":                              "Attiva/disattiva log della console su file",
+	"Toggle Console Scroll Lock":                           "Attiva/disattiva blocco scorrimento della console",
+	"Toggle Console Timestamps":                            "Mostra/nascondi orari della console",
+	"Toggle Dark Console":                                  "Attiva/disattiva console scura",
+	"Dark Console":                                         "Console scura",
+	"Toggle Dark Theme":                                    "Attiva/disattiva tema scuro",
+	"Toggle External Tool API":                             "Attiva/disattiva API per strumenti esterni",
+	"Toggle Fold":                                          "Comprimi/espandi",
+	"Toggle Indentation Guides":                            "Mostra/nascondi guide di indentazione",
+	"Toggle Inline Diagnostics":                            "Mostra/nascondi diagnostica in linea",
+	"Toggle Left Sidebar":                                  "Mostra/nascondi barra laterale sinistra",
+	"Toggle Line Comment":                                  "Attiva/disattiva commento di riga",
+	"Toggle Linked Editing":                                "Attiva/disattiva modifica collegata",
+	"Toggle Locate in Source":                              "Attiva/disattiva individua nel sorgente",
+	"Toggle Move Replaced Files to Trash":                  "Attiva/disattiva spostamento nel cestino dei file sostituiti",
+	"Toggle Outline":                                       "Mostra/nascondi struttura",
+	"Toggle Sticky Scroll":                                 "Attiva/disattiva intestazioni fisse",
+	"Toggle Read-only":                                     "Attiva/disattiva sola lettura",
+	"Toggle Relative Paths":                                "Attiva/disattiva percorsi relativi",
+	"Toggle Right Panel":                                   "Mostra/nascondi pannello destro",
+	"Toggle Signature Help and Hover":                      "Attiva/disattiva aiuto firme e suggerimenti",
+	"Toggle Spell Checking":                                "Attiva/disattiva controllo ortografico",
+	"Toggle Stop with SIGTERM":                             "Attiva/disattiva arresto con SIGTERM",
+	"Toggle Typewriter Scrolling":                          "Attiva/disattiva scorrimento a macchina da scrivere",
+	"Toggle Uncached Tests":                                "Attiva/disattiva test senza cache",
+	"Toggle Vendor Directory":                              "Attiva/disattiva directory vendor",
+	"Toggle Whitespace":                                    "Mostra/nascondi spazi",
+	"Toggle Word Wrap":                                     "Attiva/disattiva a capo automatico",
+	"Toggle Work Offline":                                  "Attiva/disattiva lavoro offline",
+	"Trim Trailing Whitespace":                             "Elimina spazi a fine riga",
+	"Undo":                                                 "Annulla",
+	"Undo Grouping Interval":                               "Intervallo di raggruppamento dell'annullamento",
+	"Undo Last File Operation":                             "Annulla ultima operazione sui file",
+	"Undo to Last Save":                                    "Annulla fino all'ultimo salvataggio",
+	"Unfold All":                                           "Espandi tutto",
+	"Unprotect Selection":                                  "Rimuovi protezione della selezione",
+	"Word Characters":                                      "Caratteri delle parole",
+
+	// Menu entries
+	"Auto-Closing Pairs":                   "Chiusura automatica delle coppie",
+	"Autospacing":                          "Spaziatura automatica",
+	"Path Completion":                      "Completamento dei percorsi",
+	"Build Unsaved Buffers Without Saving": "Build dei buffer non salvati senza salvarli",
+	"Close Pane":                           "Chiudi riquadro",
+	"Command Palette...":                   "Tavolozza dei comandi...",
+	"Commit...":                            "Commit...",
+	"Compare Files...":                     "Confronta file...",
+	"Compare with Saved...":                "Confronta con il salvato...",
+	"Convert Number...":                    "Converti numero...",
+	"Dark Theme":                           "Tema scuro",
+	"Diff with HEAD":                       "Diff con HEAD",
+	"Display Scaling...":                   "Scala di visualizzazione...",
+	"Export Settings...":                   "Esporta impostazioni...",
+	"Import Settings...":                   "Importa impostazioni...",
+	"Reset Settings...":                    "Ripristina impostazioni...",
+	"Doctor...":                            "Diagnostica...",
+	"Export Console as PDF...":             "Esporta console in PDF...",
+	"Export as HTML...":                    "Esporta in HTML...",
+	"External Tool API":                    "API per strumenti esterni",
+	"Find in Files...":                     "Trova nei file...",
+	"Find...":                              "Trova...",
+	"HTTP Client...":                       "Client HTTP...",
+	"gRPC Client...":                       "Client gRPC...",
+	"OpenAPI Preview...":                   "Anteprima OpenAPI...",
+	"Regenerate":                           "Rigenera",
+	"Migrations":                           "Migrazioni",
+	"Docker Compose...":                    "Docker Compose...",
+	"Start":                                "Avvia",
+	"Tail Logs":                            "Segui i log",
+	"Apply Pending":                        "Applica quelle in sospeso",
+	"Roll Back Latest":                     "Annulla l'ultima",
+	"Indentation Guides":                   "Guide di indentazione",
+	"Inline Diagnostics":                   "Diagnostica in linea",
+	"Keyboard Shortcuts...":                "Scorciatoie da tastiera...",
+	"License...":                           "Licenza...",
+	"Linked Editing":                       "Modifica collegata",
+	"List Bookmarks...":                    "Elenca segnalibri...",
+	"Move Declaration to File...":          "Sposta dichiarazione in un file...",
+	"Move Replaced Files to Trash":         "Sposta nel cestino i file sostituiti",
+	"New":                                  "Nuovo",
+	"Next":                                 "Successivo",
+	"Open Folder as Project...":            "Apri cartella come progetto...",
+	"Open...":                              "Apri...",
+	"Other Pane":                           "Altro riquadro",
+	"Outline":                              "Struttura",
+	"Sticky Scroll":                        "Intestazioni fisse",
+	"Pick Color...":                        "Scegli colore...",
+	"Play":                                 "Esegui",
+	"Play N Times...":                      "Esegui N volte...",
+	"Pop Stash":                            "Ripristina stash",
+	"Preferences...":                       "Preferenze...",
+	"Previous":                             "Precedente",
+	"Print...":                             "Stampa...",
+	"Process Inspector...":                 "Ispettore dei processi...",
+	"Quick Open...":                        "Apertura rapida...",
+	"Switch File...":                       "Cambia file...",
+	"(other pane)":                         "(altro riquadro)",
+	"Read-only":                            "Sola lettura",
+	"Record":                               "Registra",
+	"Regex Tester...":                      "Tester di espressioni regolari...",
+	"Relative Paths":                       "Percorsi relativi",
+	"Rename Package...":                    "Rinomina pacchetto...",
+	"Replace...":                           "Sostituisci...",
+	"Review Commits...":                    "Revisiona commit...",
+	"Review Patch...":                      "Revisiona patch...",
+	"Run Profiles...":                      "Profili di esecuzione...",
+	"Run Task...":                          "Esegui task...",
+	"Run Tests Uncached (-count=1)":        "Esegui test senza cache (-count=1)",
+	"Save As...":                           "Salva con nome...",
+	"Show Whitespace":                      "Mostra spazi",
+	"Signature Help and Hover":             "Aiuto firme e suggerimenti",
+	"Spell Checking":                       "Controllo ortografico",
+	"Split Side by Side":                   "Dividi affiancato",
+	"Split Stacked":                        "Dividi sovrapposto",
+	"Stash Changes":                        "Metti da parte le modifiche",
+	"Status...":                            "Stato...",
+	"Stop Recording":                       "Ferma registrazione",
+	"Stop with SIGTERM":                    "Ferma con SIGTERM",
+	"Structural Replace...":                "Sostituzione strutturale...",
+	"Take Both":                            "Prendi entrambi",
+	"Take Ours":                            "Prendi i nostri",
+	"Take Theirs":                          "Prendi i loro",
+	"Task History...":                      "Cronologia dei task...",
+	"Timestamp (RFC 3339)":                 "Data e ora (RFC 3339)",
+	"Typewriter Scrolling":                 "Scorrimento a macchina da scrivere",
+	"Undo Grouping Interval...":            "Intervallo di raggruppamento dell'annullamento...",
+	"Undo Last File Operation...":          "Annulla ultima operazione sui file...",
+	"Unix Time":                            "Ora Unix",
+	"Use Vendor Directory (-mod=vendor)":   "Usa la directory vendor (-mod=vendor)",
+	"Word Characters...":                   "Caratteri delle parole...",
+	"Word Wrap":                            "A capo automatico",
+	"Work Offline (GOPROXY=off)":           "Lavora offline (GOPROXY=off)",
+
+	// Toolbar, status bar and console
+	"Filter:":                             "Filtro:",
+	"Regex":                               "Regex",
+	"Both Streams":                        "Entrambi i flussi",
+	"Stdout Only":                         "Solo stdout",
+	"Stderr Only":                         "Solo stderr",
+	"Timestamps":                          "Orari",
+	"Scroll Lock":                         "Blocca scorrimento",
+	"Locate in Source":                    "Individua nel sorgente",
+	"Log to File":                         "Log su file",
+	"Clear":                               "Pulisci",
+	"Line:Column":                         "Riga:Colonna",
+	"Not saved":                           "Non salvato",
+	"Saved":                               "Salvato",
+	"Untitled - ITE":                      "Senza titolo - ITE",
+	"Building...\n":                       "Compilazione...\n",
+	"Running...\n":                        "Esecuzione...\n",
+	"Linting...\n":                        "Analisi con i linter...\n",
+	"Testing with coverage...\n":          "Test con copertura...\n",
+	"Benchmarking...\n":                   "Benchmark in corso...\n",
+	"Profiling...\n":                      "Profilazione...\n",
+	"Analyzing escapes and inlining...\n": "Analisi di escape e inlining...\n",
+	"Checking the spelling of names...\n": "Controllo dell'ortografia dei nomi...\n",
+	"Waiting for another command to finish...\n": "In attesa che termini un altro comando...\n",
+	"No file open. Please save first.":           "Nessun file aperto. Salvare prima il file.",
+
+	// Dialogs
+	"OK":                                     "OK",
+	"Cancel":                                 "Annulla",
+	"Error":                                  "Errore",
+	"Details ▸":                              "Dettagli ▸",
+	"Details ▾":                              "Dettagli ▾",
+	"Browse...":                              "Sfoglia...",
+	"Font family:":                           "Tipo di carattere:",
+	"Font size:":                             "Dimensione del carattere:",
+	"Tab width:":                             "Larghezza della tabulazione:",
+	"Line wrap:":                             "A capo:",
+	"Column guide:":                          "Guida di colonna:",
+	"Reflow width:":                          "Larghezza di riformattazione:",
+	"Autosave every (s):":                    "Salvataggio automatico ogni (s):",
+	"Default directory:":                     "Directory predefinita:",
+	"Otherwise open dialogs in:":             "Altrimenti apri le finestre in:",
+	"Project root":                           "Radice del progetto",
+	"Current file's directory":               "Directory del file corrente",
+	"Last used directory":                    "Ultima directory usata",
+	"Language:":                              "Lingua:",
+	"Highlight current line":                 "Evidenzia la riga corrente",
+	"Save files when switching away":         "Salva i file quando si cambia finestra",
+	"Vim modal editing":                      "Modifica modale stile Vim",
+	"Show whitespace":                        "Mostra spazi",
+	"Indentation guides":                     "Guide di indentazione",
+	"Invalid preferences:\n":                 "Preferenze non valide:\n",
+	"The language changes at the next start": "La lingua cambia al prossimo avvio",
+	"Replace the settings, key bindings and macro with those of": "Sostituire impostazioni, scorciatoie e macro con quelle di",
+	"Restore the default settings, key bindings and macro?":      "Ripristinare impostazioni, scorciatoie e macro predefinite?",
+	"Settings imported: some apply at the next start":            "Impostazioni importate: alcune valgono dal prossimo avvio",
+	"Settings reset: some apply at the next start":               "Impostazioni ripristinate: alcune valgono dal prossimo avvio",
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Database Migrations
+// -------------------------------------------------------------------------
+
+// Migrate Up, Migrate Down and Migration Status run the migration tool of
+// the project in the Tasks console, against the database Run Query
+// connects to. The migrations are the first directory of the project
+// holding golang-migrate files, 1_create_users.up.sql and its .down.sql,
+// or goose files, SQL files annotated with -- +goose Up. The tool is the
+// one of the layout unless the settings name one; Down rolls back a
+// single migration.
+const (
+	toolMigrate = "migrate" // github.com/golang-migrate/migrate
+	toolGoose   = "goose"   // github.com/pressly/goose
+
+	migrateUpSuffix = ".up.sql"
+	gooseAnnotation = "-- +goose"
+	migrationSniff  = 4096 // Bytes of a SQL file looked at for the goose annotation
+)
+
+// migrationSet is a directory of migrations and the tool applying them.
+type migrationSet struct {
+	tool string
+	dir  string
+}
+
+// findMigrations returns the first directory below root holding
+// migrations, in the order of the walk.
+func findMigrations(root string) (migrationSet, bool) {
+	var found migrationSet
+	walkProject(root, []string{"vendor/", "node_modules/"}, func(path string) error {
+		name := filepath.Base(path)
+		switch {
+		case strings.HasSuffix(name, migrateUpSuffix):
+			found = migrationSet{tool: toolMigrate, dir: filepath.Dir(path)}
+		case strings.HasSuffix(name, sqlFileExtension) && isGooseMigration(path):
+			found = migrationSet{tool: toolGoose, dir: filepath.Dir(path)}
+		default:
+			return nil
+		}
+		return filepath.SkipAll
+	})
+	return found, found.dir != ""
+}
+
+// isGooseMigration reports whether the SQL file at path carries goose
+// annotations.
+func isGooseMigration(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head, _ := io.ReadAll(io.LimitReader(f, migrationSniff))
+	return bytes.Contains(head, []byte(gooseAnnotation))
+}
+
+// mysqlDriverDSN returns the MySQL DSN u in the form of the Go driver,
+// user:password@tcp(host:port)/database, which both tools take.
+func mysqlDriverDSN(u *url.URL) string {
+	dsn := ""
+	if u.User != nil {
+		dsn = u.User.String() + "@"
+	}
+	dsn += "tcp(" + u.Host + ")" + u.Path
+	if u.RawQuery != "" {
+		dsn += "?" + u.RawQuery
+	}
+	return dsn
+}
+
+// migrationArgs returns the command line running the action, "up",
+// "down" or "status", of the tool of set against the database of dsn.
+func migrationArgs(set migrationSet, dsn, action string) ([]string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	driver, database := "", dsn
+	scheme, rest, _ := strings.Cut(dsn, ":")
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		driver = "postgres"
+	case "mysql":
+		driver, database = "mysql", mysqlDriverDSN(u)
+	case "sqlite", "sqlite3":
+		driver, database = "sqlite3", strings.TrimPrefix(rest, "//")
+	default:
+		switch strings.ToLower(filepath.Ext(dsn)) {
+		case ".db", ".sqlite", ".sqlite3":
+			driver = "sqlite3"
+		default:
+			return nil, fmt.Errorf("unknown database %q: use a postgres://, mysql:// or sqlite: DSN", dsn)
+		}
+	}
+	if set.tool == toolGoose {
+		return []string{toolGoose, "-dir", set.dir, driver, database, action}, nil
+	}
+	if driver != "postgres" {
+		database = driver + "://" + database
+	}
+	args := []string{toolMigrate, "-path", set.dir, "-database", database}
+	switch action {
+	case "down":
+		return append(args, "down", "1"), nil
+	case "status":
+		return append(args, "version"), nil
+	}
+	return append(args, action), nil
+}
+
+// runMigrations runs the migration action in the Tasks console.
+func (i *Ite) runMigrations(action string) {
+	root := i.projectDir()
+	set, ok := findMigrations(root)
+	if !ok {
+		i.showError("No migrations found: expected golang-migrate .up.sql or goose annotated .sql files in the project.")
+		return
+	}
+	if tool := i.config.MigrationTool; tool == toolMigrate || tool == toolGoose {
+		set.tool = tool
+	}
+	if i.config.DatabaseDSN == "" {
+		i.showError("Set the database of the migrations with Tools > Database... first.")
+		return
+	}
+	args, err := migrationArgs(set, i.config.DatabaseDSN, action)
+	if err != nil {
+		i.showError("Migrations: " + err.Error())
+		return
+	}
+	if action == "down" {
+		answer := messageBox(Icon("warning"), Title("Migrate Down"), Type("yesno"),
+			Msg("Roll back the latest migration of "+relativeTo(root, set.dir)+"?"))
+		if answer != "yes" {
+			return
+		}
+	}
+	msg := fmt.Sprintf("Running %s %s on %s...\n", set.tool, action, relativeTo(root, set.dir))
+	j := &job{c: i.console(consoleTasks), decoder: plainOutput{}, dir: root,
+		steps: []jobStep{{name: set.tool, args: args}}}
+	i.runJob(j, msg)
+}
+
+// onMigrateUp applies the pending migrations.
+func (i *Ite) onMigrateUp() { i.runMigrations("up") }
+
+// onMigrateDown rolls back the latest migration.
+func (i *Ite) onMigrateDown() { i.runMigrations("down") }
+
+// onMigrationStatus shows the migrations applied.
+func (i *Ite) onMigrationStatus() { i.runMigrations("status") }
@@ -0,0 +1,63 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindMigrations(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("queries/list.sql", "SELECT 1;")
+	if _, ok := findMigrations(root); ok {
+		t.Error("findMigrations found migrations among queries")
+	}
+	write("db/schema/001_users.sql", "-- +goose Up\nCREATE TABLE users (id int);\n")
+	set, ok := findMigrations(root)
+	if !ok || set.tool != toolGoose || set.dir != filepath.Join(root, "db", "schema") {
+		t.Errorf("findMigrations = %+v, %v, want goose in db/schema", set, ok)
+	}
+	write("db/migrations/1_users.up.sql", "CREATE TABLE users (id int);")
+	set, ok = findMigrations(root)
+	if !ok || set.tool != toolMigrate || set.dir != filepath.Join(root, "db", "migrations") {
+		t.Errorf("findMigrations = %+v, %v, want migrate in db/migrations", set, ok)
+	}
+}
+
+func TestMigrationArgs(t *testing.T) {
+	migrate, goose := migrationSet{tool: toolMigrate, dir: "m"}, migrationSet{tool: toolGoose, dir: "m"}
+	for _, tt := range []struct {
+		set    migrationSet
+		dsn    string
+		action string
+		want   []string
+	}{
+		{migrate, "postgres://localhost/shop", "up", []string{"migrate", "-path", "m", "-database", "postgres://localhost/shop", "up"}},
+		{migrate, "mysql://u:pw@db:3306/shop", "down", []string{"migrate", "-path", "m", "-database", "mysql://u:pw@tcp(db:3306)/shop", "down", "1"}},
+		{migrate, "sqlite:shop.db", "status", []string{"migrate", "-path", "m", "-database", "sqlite3://shop.db", "version"}},
+		{goose, "postgres://localhost/shop", "status", []string{"goose", "-dir", "m", "postgres", "postgres://localhost/shop", "status"}},
+		{goose, "mysql://u@db/shop?parseTime=true", "up", []string{"goose", "-dir", "m", "mysql", "u@tcp(db)/shop?parseTime=true", "up"}},
+		{goose, "data/shop.sqlite", "down", []string{"goose", "-dir", "m", "sqlite3", "data/shop.sqlite", "down"}},
+	} {
+		got, err := migrationArgs(tt.set, tt.dsn, tt.action)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("migrationArgs(%s, %q, %s) = %q, %v, want %q", tt.set.tool, tt.dsn, tt.action, got, err, tt.want)
+		}
+	}
+	if _, err := migrationArgs(migrate, "oracle://db", "up"); err == nil {
+		t.Error("migrationArgs of an unknown database succeeded")
+	}
+}
@@ -0,0 +1,694 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Modal Editing
+// -------------------------------------------------------------------------
+
+// Modal editing emulates the normal, insert and visual modes of Vim. It is
+// a layer intercepting the keys before any binding of the editor: in
+// normal and visual mode the keys typed are parsed as commands, in insert
+// mode they go through untouched. Keys with Ctrl or Alt always go through,
+// so the key bindings of the preset keep working in every mode.
+const (
+	modalBindTag = "IteModal"   // Bind tag of the key interception, first of the editor
+	modalAnchor  = "modalStart" // Mark of the end of the visual selection that stays put
+
+	modeNormal     = "NORMAL"
+	modeInsert     = "INSERT"
+	modeVisual     = "VISUAL"
+	modeVisualLine = "VISUAL LINE"
+)
+
+// vimMotions are the motions of normal mode, also taken by the operators.
+var vimMotions = []string{"h", "j", "k", "l", "0", "^", "$", "w", "b", "e", "G", "gg"}
+
+// vimCommands are the commands of normal mode that are not motions.
+const vimCommands = "xXDCYspPuiaIAoOvVJ:"
+
+// modalKeysyms maps the keys that are not characters to the command
+// they run in normal and visual mode.
+var modalKeysyms = map[string]string{
+	"Left":      "h",
+	"Right":     "l",
+	"Up":        "k",
+	"Down":      "j",
+	"Home":      "0",
+	"End":       "$",
+	"BackSpace": "h",
+	"space":     "l",
+	"Return":    "j",
+	"KP_Enter":  "j",
+	"Delete":    "x",
+}
+
+// modalState is the state of modal editing, shared by the panes.
+type modalState struct {
+	mode     string        // Current mode, "" when modal editing is off
+	pending  string        // Keys typed so far of an incomplete command
+	chord    bool          // The last key started a key binding typed in several steps
+	register string        // Text last deleted or yanked
+	linewise bool          // The register holds whole lines
+	ex       *TEntryWidget // Command line of :w, :q..., nil until first used
+}
+
+// vimCommand is a complete command of normal mode.
+type vimCommand struct {
+	count int    // Count typed before the command, 0 for none
+	op    byte   // Operator, 'd', 'c' or 'y', or 0 for none
+	key   string // Motion, text object or command; the operator again for whole lines
+	arg   rune   // Character taken by r
+}
+
+// bindModal installs the key interception layer in the editor, ahead of
+// its own bindings.
+func (i *Ite) bindModal() {
+	addBindtag(i.editText.Window, modalBindTag, i.editText.String())
+	Bind(modalBindTag, "<KeyPress>", Command(func(e *Event) {
+		if i.onModalKey(e) {
+			e.SetReturnCodeBreak()
+		}
+	}))
+	if i.config.ModalEditing && i.modal.mode == "" {
+		i.modal.mode = modeNormal
+	}
+	i.editText.Configure(Blockcursor(i.modal.mode != "" && i.modal.mode != modeInsert))
+}
+
+// setModalEditing turns modal editing on, in normal mode, or off.
+func (i *Ite) setModalEditing(on bool) {
+	switch {
+	case on && i.modal.mode == "":
+		i.setMode(modeNormal)
+	case !on && i.modal.mode != "":
+		i.setMode("")
+	}
+}
+
+// setMode switches to mode, "" turning modal editing off.
+func (i *Ite) setMode(mode string) {
+	prev := i.modal.mode
+	i.modal.mode, i.modal.pending = mode, ""
+	if (prev == modeVisual || prev == modeVisualLine) && mode != modeVisual && mode != modeVisualLine {
+		i.editText.TagRemove("sel", "1.0", "end")
+		i.editText.MarkUnset(modalAnchor)
+	}
+	if prev == modeInsert {
+		i.breakUndoGroup()
+	}
+	block := mode != "" && mode != modeInsert
+	for _, p := range i.panes {
+		i.withPane(p, func() { i.editText.Configure(Blockcursor(block)) })
+	}
+	i.updateCursorPosition()
+}
+
+// modeStatus returns the mode shown in the status bar, with the keys of
+// an incomplete command.
+func (i *Ite) modeStatus() string {
+	if i.modal.mode == "" {
+		return ""
+	}
+	return strings.TrimSpace("-- " + i.modal.mode + " -- " + i.modal.pending)
+}
+
+// onModalKey handles a key pressed in the editor. It reports whether the
+// key was taken as a command, or must go on to the editor's bindings.
+func (i *Ite) onModalKey(e *Event) bool {
+	m := &i.modal
+	if m.mode == "" || i.composing {
+		return false
+	}
+	if m.chord {
+		m.chord = false
+		return false
+	}
+	if e.State&(ModifierControl|ModifierAlt) != 0 {
+		m.chord = i.chordPrefix(e)
+		return false
+	}
+	if e.Keysym == "Escape" {
+		i.onModalEscape()
+		return false // Linked editing and snippets end too
+	}
+	if m.mode == modeInsert {
+		return false
+	}
+
+	key, ok := modalKeysyms[e.Keysym]
+	if !ok {
+		r, isRune := keysymRune(e.Keysym)
+		switch {
+		case e.Keysym == "Tab":
+			return true
+		case !isRune || e.Keysym == keysymUnknown:
+			return false // Shift, function keys...
+		}
+		key = string(r)
+	}
+	if (m.mode == modeVisual || m.mode == modeVisualLine) && m.pending == "" && strings.Contains("dxcsyvV", key) {
+		i.onVisualKey(key)
+		return true
+	}
+	m.pending += key
+	cmd, complete, valid := parseVimCommand(m.pending)
+	switch {
+	case !valid:
+		m.pending = ""
+	case complete:
+		m.pending = ""
+		i.runVimCommand(cmd)
+	}
+	i.updateCursorPosition()
+	return true
+}
+
+// chordPrefix reports whether the key with Ctrl or Alt of e starts a key
+// binding typed in several steps, like Ctrl+W W, whose next key must not
+// be taken as a command.
+func (i *Ite) chordPrefix(e *Event) bool {
+	prefix := "<"
+	if e.State&ModifierControl != 0 {
+		prefix += "Control-"
+	}
+	if e.State&ModifierAlt != 0 {
+		prefix += "Alt-"
+	}
+	prefix += e.Keysym + ">"
+	for seq := range i.keys {
+		if len(seq) > len(prefix) && strings.HasPrefix(seq, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// onModalEscape goes back to normal mode, dropping an incomplete command.
+func (i *Ite) onModalEscape() {
+	switch i.modal.mode {
+	case modeInsert:
+		if _, col := parseIndex(i.editText.Index("insert")); col > 0 {
+			i.editText.MarkSet("insert", "insert -1c")
+		}
+		i.setMode(modeNormal)
+	case modeVisual, modeVisualLine:
+		i.setMode(modeNormal)
+	default:
+		i.modal.pending = ""
+		i.updateCursorPosition()
+	}
+}
+
+// parseVimCommand parses the keys typed in normal mode. It reports
+// whether they make a complete command and, if not, whether more keys can
+// still complete them.
+func parseVimCommand(keys string) (cmd vimCommand, complete, valid bool) {
+	rest := keys
+	count := func() int {
+		n := 0
+		for rest != "" && rest[0] >= '0' && rest[0] <= '9' && (n > 0 || rest[0] != '0') {
+			n = n*10 + int(rest[0]-'0')
+			rest = rest[1:]
+		}
+		return n
+	}
+	cmd.count = count()
+	if rest != "" && strings.IndexByte("dcy", rest[0]) >= 0 {
+		cmd.op, rest = rest[0], rest[1:]
+		if n := count(); n > 0 {
+			cmd.count = max(cmd.count, 1) * n
+		}
+	}
+	if rest == "" {
+		return cmd, false, true
+	}
+
+	switch first := rest[0]; {
+	case cmd.op != 0 && first == cmd.op:
+		cmd.key = rest
+		return cmd, true, len(rest) == 1
+	case cmd.op != 0 && (first == 'i' || first == 'a'):
+		cmd.key = rest
+		return cmd, rest == "iw" || rest == "aw", len(rest) == 1 || rest == "iw" || rest == "aw"
+	case first == 'g':
+		cmd.key = rest
+		return cmd, rest == "gg", len(rest) == 1 || rest == "gg"
+	case cmd.op == 0 && first == 'r':
+		if len(rest) == 1 {
+			return cmd, false, true
+		}
+		r, size := utf8.DecodeRuneInString(rest[1:])
+		cmd.key, cmd.arg = "r", r
+		return cmd, true, 1+size == len(rest)
+	}
+	cmd.key = rest
+	valid = isVimMotion(rest) || (cmd.op == 0 && len(rest) == 1 && strings.Contains(vimCommands, rest))
+	return cmd, valid, valid
+}
+
+// isVimMotion reports whether key is a motion.
+func isVimMotion(key string) bool {
+	for _, m := range vimMotions {
+		if key == m {
+			return true
+		}
+	}
+	return false
+}
+
+// runVimCommand runs a complete command of normal or visual mode.
+func (i *Ite) runVimCommand(cmd vimCommand) {
+	t := i.editText
+	n := max(cmd.count, 1)
+	switch {
+	case cmd.op != 0:
+		i.vimOperator(cmd)
+	case isVimMotion(cmd.key):
+		t.MarkSet("insert", i.vimTarget(cmd.key, cmd.count))
+		i.updateVisual()
+	default:
+		switch cmd.key {
+		case "x":
+			i.vimOperate('d', "insert", i.lineLimit(fmt.Sprintf("insert +%dc", n)), false)
+		case "X":
+			i.vimOperate('d', i.lineLimit(fmt.Sprintf("insert -%dc", n)), "insert", false)
+		case "D":
+			i.vimOperator(vimCommand{op: 'd', key: "$"})
+		case "C":
+			i.vimOperator(vimCommand{op: 'c', key: "$"})
+		case "Y":
+			i.vimOperator(vimCommand{count: cmd.count, op: 'y', key: "y"})
+		case "s":
+			i.vimOperate('c', "insert", i.lineLimit(fmt.Sprintf("insert +%dc", n)), false)
+		case "p", "P":
+			i.vimPut(cmd.key == "P", n)
+		case "u":
+			for range n {
+				i.onUndo()
+			}
+		case "r":
+			i.vimReplace(cmd.arg, n)
+		case "J":
+			i.vimJoin(n)
+		case "i":
+			i.setMode(modeInsert)
+		case "a":
+			if t.Index("insert") != t.Index("insert lineend") {
+				t.MarkSet("insert", "insert +1c")
+			}
+			i.setMode(modeInsert)
+		case "I":
+			t.MarkSet("insert", i.vimTarget("^", 0))
+			i.setMode(modeInsert)
+		case "A":
+			t.MarkSet("insert", "insert lineend")
+			i.setMode(modeInsert)
+		case "o":
+			t.MarkSet("insert", "insert lineend")
+			i.smartNewline()
+			i.setMode(modeInsert)
+		case "O":
+			indent := leadingSpace(t.Get("insert linestart", "insert lineend")[0])
+			if !i.blockProtected(t.Index("insert linestart"), t.Index("insert linestart")) {
+				i.editGroup(func() { t.Insert("insert linestart", indent+"\n") })
+				t.MarkSet("insert", "insert -1 lines lineend")
+				i.setMode(modeInsert)
+			}
+		case "v", "V":
+			i.onVisualKey(cmd.key)
+		case ":":
+			i.openExLine()
+		}
+	}
+	t.See("insert")
+	i.refreshCursorState()
+}
+
+// lineLimit returns index, kept on the line of the cursor.
+func (i *Ite) lineLimit(index string) string {
+	t := i.editText
+	switch {
+	case indexLess(t, t.Index("insert lineend"), index):
+		return t.Index("insert lineend")
+	case indexLess(t, index, t.Index("insert linestart")):
+		return t.Index("insert linestart")
+	}
+	return t.Index(index)
+}
+
+// vimTarget returns where motion key, repeated count times, takes the
+// cursor. G and gg take the count as a line number.
+func (i *Ite) vimTarget(key string, count int) string {
+	t := i.editText
+	switch key {
+	case "G":
+		if count == 0 {
+			return t.Index("end-1c linestart")
+		}
+		return t.Index(fmt.Sprintf("%d.0", count))
+	case "gg":
+		return t.Index(fmt.Sprintf("%d.0", max(count, 1)))
+	}
+	index := t.Index("insert")
+	for range max(count, 1) {
+		index = i.vimStep(key, index)
+	}
+	return index
+}
+
+// vimStep returns where motion key takes the cursor from index, once.
+func (i *Ite) vimStep(key, index string) string {
+	t := i.editText
+	at := func(expr string) string { return t.Index(index + " " + expr) }
+	switch key {
+	case "h":
+		if index != at("linestart") {
+			return at("-1c")
+		}
+	case "l":
+		if index != at("lineend") {
+			return at("+1c")
+		}
+	case "j":
+		return at("+1 lines")
+	case "k":
+		return at("-1 lines")
+	case "0":
+		return at("linestart")
+	case "^":
+		indent := leadingSpace(t.Get(at("linestart"), at("lineend"))[0])
+		return at(fmt.Sprintf("linestart +%dc", utf8.RuneCountInString(indent)))
+	case "$":
+		return at("lineend")
+	case "w":
+		return t.Index(tclEval("tk::TextNextPos %s {%s} tcl_startOfNextWord", t, index))
+	case "b":
+		return t.Index(tclEval("tk::TextPrevPos %s {%s} tcl_startOfPreviousWord", t, index))
+	case "e":
+		end := t.Index(tclEval("tk::TextNextPos %s {%s +1c} tcl_endOfWord", t, index))
+		return t.Index(end + " -1c")
+	}
+	return index
+}
+
+// vimOperator applies the operator of cmd to the text its motion or text
+// object goes over.
+func (i *Ite) vimOperator(cmd vimCommand) {
+	t := i.editText
+	insert := t.Index("insert")
+	switch key := cmd.key; key {
+	case string(cmd.op):
+		i.vimOperate(cmd.op, "insert linestart", fmt.Sprintf("insert linestart +%d lines", max(cmd.count, 1)), true)
+	case "iw", "aw":
+		from, to := t.Index("insert wordstart"), t.Index("insert wordend")
+		if key == "aw" {
+			for c := t.Get(to, to+" +1c")[0]; c == " " || c == "\t"; c = t.Get(to, to+" +1c")[0] {
+				to = t.Index(to + " +1c")
+			}
+		}
+		i.vimOperate(cmd.op, from, to, false)
+	case "j", "k", "G", "gg":
+		from, to := insert, i.vimTarget(key, cmd.count)
+		if indexLess(t, to, from) {
+			from, to = to, from
+		}
+		i.vimOperate(cmd.op, from+" linestart", to+" linestart +1 lines", true)
+	default:
+		if key == "w" && cmd.op == 'c' {
+			key = "e" // As in Vim, cw changes up to the end of the word
+		}
+		target := i.vimTarget(key, cmd.count)
+		switch key {
+		case "e":
+			target = t.Index(target + " +1c")
+		case "w":
+			if l, _ := parseIndex(target); l != indexLine(insert) {
+				target = t.Index("insert lineend")
+			}
+		}
+		from, to := insert, target
+		if indexLess(t, to, from) {
+			from, to = to, from
+		}
+		i.vimOperate(cmd.op, from, to, false)
+	}
+}
+
+// indexLine returns the line of a "line.column" index.
+func indexLine(index string) int {
+	line, _ := parseIndex(index)
+	return line
+}
+
+// vimOperate applies operator op to the text from..to: yanking it,
+// deleting it, or changing it, which deletes it and enters insert mode.
+// Whole lines are kept as such in the register.
+func (i *Ite) vimOperate(op byte, from, to string, linewise bool) {
+	t := i.editText
+	from, to = t.Index(from), t.Index(to)
+	text := t.Get(from, to)[0]
+	if text == "" && op != 'c' {
+		return
+	}
+	i.modal.register, i.modal.linewise = text, linewise
+	if op == 'y' {
+		if !linewise {
+			t.MarkSet("insert", from)
+		}
+		return
+	}
+
+	delFrom, delTo := from, to
+	switch {
+	case linewise && op == 'c':
+		delTo = t.Index(to + " -1c") // Keep an empty line to type on
+	case linewise && to == t.Index("end") && from != "1.0":
+		delFrom, delTo = t.Index(from+" -1c"), t.Index("end-1c") // The last lines go with the newline before them
+	}
+	if i.blockProtected(delFrom, delTo) {
+		return
+	}
+	i.editGroup(func() {
+		t.Delete(delFrom, delTo)
+		if linewise && op == 'c' {
+			t.Insert(delFrom, leadingSpace(text))
+		}
+	})
+	switch {
+	case op == 'c':
+		t.MarkSet("insert", delFrom)
+		if linewise {
+			t.MarkSet("insert", "insert lineend")
+		}
+		i.setMode(modeInsert)
+	case linewise:
+		t.MarkSet("insert", delFrom)
+		t.MarkSet("insert", i.vimTarget("^", 0))
+	default:
+		t.MarkSet("insert", delFrom)
+	}
+}
+
+// vimPut pastes the register n times, after the cursor or, if before,
+// before it. Whole lines go below or above the cursor line.
+func (i *Ite) vimPut(before bool, n int) {
+	t := i.editText
+	text := strings.Repeat(i.modal.register, n)
+	if text == "" {
+		return
+	}
+	at := t.Index("insert")
+	switch {
+	case i.modal.linewise && before:
+		at = t.Index("insert linestart")
+	case i.modal.linewise && t.Index("insert lineend +1c") == t.Index("end"):
+		at = t.Index("insert lineend") // Below the last line
+		text = "\n" + strings.TrimSuffix(text, "\n")
+	case i.modal.linewise:
+		at = t.Index("insert linestart +1 lines")
+	case !before && at != t.Index("insert lineend"):
+		at = t.Index("insert +1c")
+	}
+	if i.blockProtected(at, at) {
+		return
+	}
+	i.editGroup(func() { t.Insert(at, text) })
+	if i.modal.linewise {
+		t.MarkSet("insert", at)
+		if !before && strings.HasPrefix(text, "\n") {
+			t.MarkSet("insert", "insert +1 lines linestart")
+		}
+		t.MarkSet("insert", i.vimTarget("^", 0))
+	} else {
+		t.MarkSet("insert", fmt.Sprintf("%s +%dc", at, utf8.RuneCountInString(text)-1))
+	}
+}
+
+// vimReplace replaces n characters from the cursor with r.
+func (i *Ite) vimReplace(r rune, n int) {
+	t := i.editText
+	from, to := t.Index("insert"), t.Index(fmt.Sprintf("insert +%dc", n))
+	if indexLine(to) != indexLine(from) || r == '\n' || i.blockProtected(from, to) {
+		return
+	}
+	i.editGroup(func() {
+		t.Delete(from, to)
+		t.Insert(from, strings.Repeat(string(r), n))
+	})
+	t.MarkSet("insert", fmt.Sprintf("%s +%dc", from, n-1))
+}
+
+// vimJoin joins the cursor line and the n-1 lines below, at least one,
+// with single spaces.
+func (i *Ite) vimJoin(n int) {
+	t := i.editText
+	i.editGroup(func() {
+		for range max(n-1, 1) {
+			end := t.Index("insert lineend")
+			if t.Index(end+" +1c") == t.Index("end") {
+				return
+			}
+			next := t.Get(end+" +1c", end+" +1 lines lineend")[0]
+			indent := utf8.RuneCountInString(leadingSpace(next))
+			to := t.Index(fmt.Sprintf("%s +%dc", end, 1+indent))
+			if i.blockProtected(end, to) {
+				return
+			}
+			t.Delete(end, to)
+			if strings.TrimSpace(next) != "" {
+				t.Insert(end, " ")
+			}
+			t.MarkSet("insert", end)
+		}
+	})
+}
+
+// onVisualKey handles the keys acting on the selection of visual mode,
+// or entering it.
+func (i *Ite) onVisualKey(key string) {
+	t := i.editText
+	m := &i.modal
+	switch key {
+	case "v", "V":
+		mode := modeVisual
+		if key == "V" {
+			mode = modeVisualLine
+		}
+		if m.mode == mode {
+			i.setMode(modeNormal)
+			break
+		}
+		if m.mode == modeNormal {
+			t.MarkSet(modalAnchor, "insert")
+			t.MarkGravity(modalAnchor, "left")
+		}
+		i.setMode(mode)
+		i.updateVisual()
+	default:
+		from, to := modalAnchor, "insert"
+		if indexLess(t, to, from) {
+			from, to = to, from
+		}
+		linewise := m.mode == modeVisualLine
+		if linewise {
+			from, to = from+" linestart", to+" linestart +1 lines"
+		} else {
+			to += " +1c"
+		}
+		op := map[string]byte{"d": 'd', "x": 'd', "c": 'c', "s": 'c', "y": 'y'}[key]
+		i.setMode(modeNormal) // Before the change of c enters insert mode
+		i.vimOperate(op, from, to, linewise)
+	}
+	t.See("insert")
+	i.refreshCursorState()
+}
+
+// updateVisual selects the text from the anchor to the cursor in visual
+// mode, whole lines in visual line mode.
+func (i *Ite) updateVisual() {
+	t := i.editText
+	if i.modal.mode != modeVisual && i.modal.mode != modeVisualLine {
+		return
+	}
+	from, to := modalAnchor, "insert"
+	if indexLess(t, to, from) {
+		from, to = to, from
+	}
+	if i.modal.mode == modeVisualLine {
+		from, to = from+" linestart", to+" lineend +1c"
+	} else {
+		to += " +1c"
+	}
+	t.TagRemove("sel", "1.0", "end")
+	t.TagAdd("sel", from, to)
+}
+
+// -------------------------------------------------------------------------
+// Ex Commands
+// -------------------------------------------------------------------------
+
+// openExLine opens the command line of the : commands over the cursor
+// position of the status bar.
+func (i *Ite) openExLine() {
+	m := &i.modal
+	if m.ex == nil {
+		m.ex = i.statusFrame.TEntry(Font("GoMono", 11))
+		closeLine := func() {
+			GridRemove(m.ex.Window)
+			Focus(i.editText)
+		}
+		Bind(m.ex, "<Return>", Command(func() {
+			cmd := strings.TrimPrefix(m.ex.Textvariable(), ":")
+			closeLine()
+			i.runExCommand(strings.TrimSpace(cmd))
+		}))
+		Bind(m.ex, "<Escape>", Command(closeLine))
+		Bind(m.ex, "<KeyRelease>", Command(func() {
+			if m.ex.Textvariable() == "" {
+				closeLine() // Backspace over the colon, as in Vim
+			}
+		}))
+	}
+	m.ex.Configure(Textvariable(":"))
+	m.ex.Icursor("end")
+	Grid(m.ex, Row(0), Column(0), Sticky(WE))
+	Focus(m.ex)
+}
+
+// runExCommand runs a : command: w, q, q!, wq, x or a line number.
+func (i *Ite) runExCommand(cmd string) {
+	if line, err := strconv.Atoi(cmd); err == nil {
+		i.jumpTo(max(line, 1), 0)
+		return
+	}
+	switch cmd {
+	case "":
+	case "w":
+		i.onSave()
+	case "q":
+		i.onQuit()
+	case "q!", "qa!":
+		for _, p := range i.panes {
+			i.withPane(p, func() { i.editText.SetModified(false) })
+		}
+		i.onQuit()
+	case "wq", "x":
+		i.onSave()
+		if !i.editText.Modified() {
+			i.onQuit()
+		}
+	default:
+		i.showStatusHint("Not an editor command: " + cmd)
+	}
+}
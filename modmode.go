@@ -0,0 +1,122 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Module Mode
+// -------------------------------------------------------------------------
+
+// Working offline sets GOPROXY=off and using the vendor directory adds
+// -mod=vendor to GOFLAGS, in ITE's environment, so every command it starts
+// inherits them. The values the user set are kept aside and restored when
+// the mode is switched off; the status bar shows the mode while either is
+// on.
+const (
+	modeOffline = "offline"
+	modeVendor  = "vendor"
+)
+
+// moduleEnvNames are the variables the module mode overrides.
+var moduleEnvNames = []string{"GOFLAGS", "GOPROXY"}
+
+// userModuleEnv holds the values of moduleEnvNames set outside ITE, taken
+// when the mode is first applied and updated by reloading the environment.
+var userModuleEnv map[string]string
+
+// userEnv returns the value of the variable name set outside ITE, which
+// differs from its value in ITE's environment if the module mode overrides
+// it.
+func userEnv(name string) string {
+	if v, ok := userModuleEnv[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
+
+// applyModuleMode sets GOFLAGS and GOPROXY for the module mode of the
+// config and shows it in the status bar.
+func (i *Ite) applyModuleMode() {
+	if userModuleEnv == nil {
+		userModuleEnv = make(map[string]string, len(moduleEnvNames))
+		for _, name := range moduleEnvNames {
+			userModuleEnv[name] = os.Getenv(name)
+		}
+	}
+	goflags := moduleFlags(userModuleEnv["GOFLAGS"], i.config.VendorMode)
+	goproxy := userModuleEnv["GOPROXY"]
+	if i.config.WorkOffline {
+		goproxy = "off"
+	}
+	setEnv("GOFLAGS", goflags)
+	setEnv("GOPROXY", goproxy)
+
+	var mode []string
+	if i.config.WorkOffline {
+		mode = append(mode, modeOffline)
+	}
+	if i.config.VendorMode {
+		mode = append(mode, modeVendor)
+	}
+	i.statusLabelModule.Configure(Txt(strings.Join(mode, "+")))
+}
+
+// moduleFlags returns goflags with its -mod flag replaced by -mod=vendor
+// if vendor is set, and unchanged otherwise.
+func moduleFlags(goflags string, vendor bool) string {
+	if !vendor {
+		return goflags
+	}
+	var flags []string
+	for _, f := range strings.Fields(goflags) {
+		if !strings.HasPrefix(f, "-mod=") && !strings.HasPrefix(f, "--mod=") {
+			flags = append(flags, f)
+		}
+	}
+	return strings.Join(append(flags, "-mod=vendor"), " ")
+}
+
+// setEnv sets the variable name of ITE's environment to value, removing it
+// if value is empty.
+func setEnv(name, value string) {
+	if value == "" {
+		os.Unsetenv(name)
+	} else {
+		os.Setenv(name, value)
+	}
+}
+
+// onToggleWorkOffline switches commands between fetching modules from the
+// proxy and using only the module cache, and persists the choice.
+func (i *Ite) onToggleWorkOffline() {
+	i.config.WorkOffline = !i.config.WorkOffline
+	i.offlineVar.Set(checkValue(i.config.WorkOffline))
+	i.saveConfig()
+	i.applyModuleMode()
+	if i.config.WorkOffline {
+		i.showStatusHint("Working offline (GOPROXY=off)")
+	} else {
+		i.showStatusHint("Working online, GOPROXY=" + orDefault(userModuleEnv["GOPROXY"], "default"))
+	}
+}
+
+// onToggleVendorMode switches commands between the module cache and the
+// vendor directory of the module, and persists the choice.
+func (i *Ite) onToggleVendorMode() {
+	i.config.VendorMode = !i.config.VendorMode
+	i.vendorVar.Set(checkValue(i.config.VendorMode))
+	i.saveConfig()
+	i.applyModuleMode()
+	if i.config.VendorMode {
+		i.showStatusHint("Commands use the vendor directory (-mod=vendor)")
+	} else {
+		i.showStatusHint("Commands use the module cache")
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -------------------------------------------------------------------------
+// Move Declaration
+// -------------------------------------------------------------------------
+
+// Move Declaration moves the top-level declaration at the cursor, with its
+// doc and line comments, to the end of another file of the package,
+// created if missing. The imports it uses are added to that file, which is
+// then formatted, and those no longer used are removed from the current
+// one, all in one undo step.
+
+// importRef is an import of a file: the name it is referred to by and the
+// quoted path.
+type importRef struct {
+	name, path string
+	explicit   bool // The import spec names the package
+}
+
+// onMoveDeclaration asks for the file to move the declaration at the
+// cursor to and moves it.
+func (i *Ite) onMoveDeclaration() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Move Declaration needs a saved Go file.")
+		return
+	}
+	src := i.editText.Get("1.0", "end-1c")[0]
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, i.currentFile, src, parser.ParseComments)
+	if err != nil {
+		i.showError("Move Declaration needs a file without syntax errors: " + err.Error())
+		return
+	}
+	line, _ := parseIndex(i.editText.Index("insert"))
+	decl, start, end := declAt(fset, f, src, line)
+	if decl == nil {
+		i.showStatusHint("No top-level declaration at the cursor")
+		return
+	}
+	if i.blockProtected(i.byteIndex(src, start), i.byteIndex(src, end)) {
+		return
+	}
+	name := declName(decl)
+	i.promptString("Move Declaration", "Move "+name+" to the file of the package:", strings.ToLower(name)+defaultFileExtension, func(target string) {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			return
+		}
+		if filepath.Ext(target) == "" {
+			target += defaultFileExtension
+		}
+		if filepath.Base(target) != target {
+			i.showError("Move Declaration: give the name of a file in the directory of the package.")
+			return
+		}
+		target = filepath.Join(filepath.Dir(i.currentFile), target)
+		if samePath(target, i.currentFile) {
+			return
+		}
+		if err := i.moveDeclaration(f, decl, src, start, end, target); err != nil {
+			i.showError("Move Declaration: " + err.Error())
+			return
+		}
+		i.showStatusHint(fmt.Sprintf("Moved %s to %s", name, filepath.Base(target)))
+	})
+}
+
+// declAt returns the top-level declaration of f holding line, with the
+// byte range of src it spans from the line of its doc comment to the end
+// of its last line, a blank line after it included.
+func declAt(fset *token.FileSet, f *ast.File, src string, line int) (ast.Decl, int, int) {
+	for _, decl := range f.Decls {
+		first, last := fset.Position(decl.Pos()).Line, fset.Position(decl.End()).Line
+		doc := declDoc(decl)
+		if doc != nil {
+			first = fset.Position(doc.Pos()).Line
+		}
+		if line < first || line > last {
+			continue
+		}
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			return nil, 0, 0
+		}
+		start := fset.Position(decl.Pos()).Offset
+		if doc != nil {
+			start = fset.Position(doc.Pos()).Offset
+		}
+		start = strings.LastIndexByte(src[:start], '\n') + 1
+		end := fset.Position(decl.End()).Offset
+		if rest, _, _ := strings.Cut(src[end:], "\n"); strings.TrimSpace(rest) == "" || strings.HasPrefix(strings.TrimSpace(rest), "//") {
+			end += len(rest)
+			if end < len(src) {
+				end++ // The newline
+			}
+			if end < len(src) && src[end] == '\n' {
+				end++
+			}
+		}
+		return decl, start, end
+	}
+	return nil, 0, 0
+}
+
+// declDoc returns the doc comment of decl, or nil.
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// declName returns the name of the function or of the first name declared
+// by decl.
+func declName(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Name.Name
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				return s.Name.Name
+			case *ast.ValueSpec:
+				return s.Names[0].Name
+			}
+		}
+	}
+	return "decl"
+}
+
+// fileImports returns the imports of f that are referred to by a name.
+func fileImports(f *ast.File) []importRef {
+	var refs []importRef
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		ref := importRef{name: importName(importPath), path: imp.Path.Value}
+		if imp.Name != nil {
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				continue
+			}
+			ref.name, ref.explicit = imp.Name.Name, true
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// qualifiers returns the names node qualifies identifiers with, as in
+// fmt.Println: the package names it refers to.
+func qualifiers(node ast.Node) map[string]bool {
+	used := make(map[string]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// moveDeclaration moves decl, the text between the byte offsets start and
+// end of src, the source of f in the buffer, to the end of target.
+func (i *Ite) moveDeclaration(f *ast.File, decl ast.Decl, src string, start, end int, target string) error {
+	used := qualifiers(decl)
+	var needed []importRef
+	for _, ref := range fileImports(f) {
+		if used[ref.name] {
+			needed = append(needed, ref)
+		}
+	}
+
+	pane := i.paneEditing(target)
+	var dst string
+	if pane != nil {
+		dst = pane.text.Get("1.0", "end-1c")[0]
+	} else if data, err := os.ReadFile(target); err == nil {
+		dst = string(data)
+	} else if os.IsNotExist(err) {
+		dst = "package " + f.Name.Name + "\n"
+	} else {
+		return err
+	}
+	moved, err := appendDeclaration(dst, f.Name.Name, strings.TrimRight(src[start:end], "\n"), needed)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filepath.Base(target), err)
+	}
+
+	clean := !i.editText.Modified()
+	if pane != nil {
+		i.withPane(pane, func() {
+			i.editGroup(func() {
+				i.editText.Delete("1.0", "end-1c")
+				i.editText.Insert("1.0", moved)
+			})
+			i.refreshCursorState()
+		})
+	} else {
+		op := i.beginFileOp("Move Declaration to " + filepath.Base(target))
+		op.created(target)
+		if err := os.WriteFile(target, []byte(moved), defaultFilePerms); err != nil {
+			return err
+		}
+		i.commitFileOp(op)
+	}
+
+	i.editGroup(func() {
+		i.editText.Delete(i.byteIndex(src, start), i.byteIndex(src, end))
+		rest := i.editText.Get("1.0", "end-1c")[0]
+		for _, r := range unusedImportRanges(rest, needed) {
+			i.editText.Delete(i.byteIndex(rest, r[0]), i.byteIndex(rest, r[1]))
+		}
+	})
+	i.refreshCursorState()
+	i.refreshOutline()
+	i.updateGutter()
+	if clean {
+		i.onSave()
+	}
+	return nil
+}
+
+// appendDeclaration returns dst, the source of a file of package pkg, with
+// code appended and the imports added, formatted.
+func appendDeclaration(dst, pkg, code string, imports []importRef) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", dst, parser.ImportsOnly)
+	if err != nil {
+		return "", err
+	}
+	if f.Name.Name != pkg {
+		return "", fmt.Errorf("belongs to package %s, not %s", f.Name.Name, pkg)
+	}
+	have := make(map[string]bool)
+	for _, imp := range f.Imports {
+		have[imp.Path.Value] = true
+	}
+	var specs []string
+	for _, ref := range imports {
+		if have[ref.path] {
+			continue
+		}
+		if ref.explicit {
+			specs = append(specs, ref.name+" "+ref.path)
+		} else {
+			specs = append(specs, ref.path)
+		}
+	}
+
+	// New imports go in the last import declaration, made a block, or in
+	// a new one after the package clause
+	var insert, cut int // Text between insert and cut is replaced by text
+	var text string
+	var last *ast.GenDecl
+	for _, d := range f.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	switch {
+	case len(specs) == 0:
+	case last == nil:
+		insert = fset.Position(f.Name.End()).Offset
+		cut = insert
+		text = "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+	case last.Lparen.IsValid():
+		insert = fset.Position(last.Rparen).Offset
+		cut = insert
+		text = "\t" + strings.Join(specs, "\n\t") + "\n"
+	default:
+		insert, cut = fset.Position(last.Pos()).Offset, fset.Position(last.End()).Offset
+		spec := dst[fset.Position(last.Specs[0].Pos()).Offset:cut]
+		text = "import (\n\t" + strings.Join(append([]string{spec}, specs...), "\n\t") + "\n)"
+	}
+	out := dst[:insert] + text + strings.TrimRight(dst[cut:], "\n") + "\n\n" + code + "\n"
+	formatted, err := format.Source([]byte(out))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// unusedImportRanges returns the byte ranges of src, in reverse order, to
+// delete to remove the imports among candidates no longer used by src.
+func unusedImportRanges(src string, candidates []importRef) [][2]int {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil
+	}
+	used := qualifiers(f)
+	unused := make(map[string]bool)
+	for _, ref := range candidates {
+		if !used[ref.name] {
+			unused[ref.path] = true
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	// lines returns the range of the lines of src from pos to end
+	lines := func(pos, end token.Pos) [2]int {
+		from := fset.Position(pos).Offset
+		from = strings.LastIndexByte(src[:from], '\n') + 1
+		to := fset.Position(end).Offset
+		if n := strings.IndexByte(src[to:], '\n'); n >= 0 {
+			to += n + 1
+		} else {
+			to = len(src)
+		}
+		return [2]int{from, to}
+	}
+	var ranges [][2]int
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var drop []ast.Spec
+		for _, spec := range gen.Specs {
+			if imp := spec.(*ast.ImportSpec); unused[imp.Path.Value] && (imp.Name == nil || imp.Name.Name != "_") {
+				drop = append(drop, spec)
+			}
+		}
+		if len(drop) == len(gen.Specs) {
+			ranges = append(ranges, lines(gen.Pos(), gen.End()))
+			continue
+		}
+		for _, spec := range drop {
+			ranges = append(ranges, lines(spec.Pos(), spec.End()))
+		}
+	}
+	slices.Reverse(ranges)
+	return ranges
+}
+
+// byteIndex returns the Tk index of the byte offset n of src, the text of
+// the buffer.
+func (i *Ite) byteIndex(src string, n int) string {
+	return i.editText.Index(fmt.Sprintf("1.0 +%dc", utf8.RuneCountInString(src[:n])))
+}
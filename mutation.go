@@ -0,0 +1,177 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Mutate Selection
+// -------------------------------------------------------------------------
+
+// Mutate Selection is a quick probe of the tests: it changes the selected
+// expression the way a bug would, runs the tests of the package on the
+// changed file and reports whether any of them failed. Like Assert
+// Selection, the changed file reaches the go command through an overlay,
+// so the file on disk is never written to.
+
+// mutatedOps are the operators a mutation swaps, each with the one
+// replacing it: a comparison becomes its negation.
+var mutatedOps = map[token.Token]token.Token{
+	token.EQL:  token.NEQ,
+	token.NEQ:  token.EQL,
+	token.LSS:  token.GEQ,
+	token.GEQ:  token.LSS,
+	token.GTR:  token.LEQ,
+	token.LEQ:  token.GTR,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+}
+
+// mutation is a change of an expression.
+type mutation struct {
+	offset int    // Byte offset of the change in the expression
+	old    string // Text replaced
+	new    string // Text replacing it
+}
+
+// apply returns expr with the mutation made.
+func (m mutation) apply(expr string) string {
+	return expr[:m.offset] + m.new + expr[m.offset+len(m.old):]
+}
+
+// mutationDecoder reports the test run of a mutant: the mutant is killed
+// when a test fails, and survives when all of them pass.
+type mutationDecoder struct {
+	*testDecoder
+	mutant      string // Mutated expression
+	buildFailed bool   // A package failed outside its tests
+}
+
+func (d *mutationDecoder) decode(line string) []consoleMsg {
+	var ev testEvent
+	if json.Unmarshal([]byte(line), &ev) == nil && ev.Action == "fail" && ev.Test == "" {
+		d.buildFailed = true
+	}
+	return d.testDecoder.decode(line)
+}
+
+func (d *mutationDecoder) summary() []consoleMsg {
+	msgs := d.testDecoder.summary()
+	switch {
+	case d.failed > 0:
+		return append(msgs, consoleMsg{text: fmt.Sprintf("Mutant killed: %d tests caught %s\n", d.failed, d.mutant), tag: tagTestPass})
+	case d.buildFailed:
+		return append(msgs, consoleMsg{text: "Mutant not tested: the package failed to build or run\n", tag: tagTestSkip})
+	case d.passed == 0:
+		return append(msgs, consoleMsg{text: "Mutant not tested: no test ran\n", tag: tagTestSkip})
+	}
+	return append(msgs, consoleMsg{text: fmt.Sprintf("Mutant survived: no test caught %s\n", d.mutant), tag: tagTestFail})
+}
+
+// onMutateSelection flips the first comparison or logical operator of the
+// selected expression, or adds one to its first integer constant, and
+// runs the tests on the mutant: those of the enclosing test function in a
+// test file, those of the package otherwise.
+func (i *Ite) onMutateSelection() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Mutate Selection needs a saved Go file.")
+		return
+	}
+	sel := i.editText.TagRanges("sel")
+	if len(sel) < 2 {
+		i.showError("Select an expression to mutate.")
+		return
+	}
+	expr := i.editText.Get(sel[0], sel[len(sel)-1])[0]
+	x, err := parser.ParseExpr(expr)
+	if err != nil {
+		i.showError("Mutate Selection: not an expression: " + err.Error())
+		return
+	}
+	m, ok := findMutation(x)
+	if !ok {
+		i.showError("Mutate Selection: the expression has no comparison, logical operator or integer constant to change.")
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	mutant := m.apply(expr)
+	src := i.editText.Get("1.0", sel[0])[0] + mutant + i.editText.Get(sel[len(sel)-1], "end-1c")[0]
+
+	args := []string{"test", "-json", "-fullpath", "-count=1"}
+	line, _ := parseIndex(sel[0])
+	if name := enclosingTest(i.currentFile, i.editText.Text(), line); name != "" {
+		args = append(args, "-run", "^"+name+"$")
+	}
+	overlay, err := i.writeAssertOverlay(i.currentFile, src)
+	if err != nil {
+		i.showError("Mutate Selection: " + err.Error())
+		return
+	}
+	d := &mutationDecoder{testDecoder: newTestDecoder(), mutant: mutant}
+	i.runCommand(i.console(consoleTest), append(args, "-overlay", overlay, i.currentPackage()),
+		fmt.Sprintf("Testing the mutant %s of %s...\n", mutant, expr), d)
+}
+
+// findMutation returns the change of the first operator of x listed in
+// mutatedOps or, lacking one, of its first integer constant.
+func findMutation(x ast.Expr) (mutation, bool) {
+	var op, constant *mutation
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			if to, ok := mutatedOps[n.Op]; ok && op == nil {
+				op = &mutation{offset: int(n.OpPos) - 1, old: n.Op.String(), new: to.String()}
+			}
+		case *ast.BasicLit:
+			if n.Kind != token.INT || constant != nil {
+				break
+			}
+			if v, err := strconv.ParseInt(strings.ReplaceAll(n.Value, "_", ""), 0, 64); err == nil {
+				constant = &mutation{offset: int(n.ValuePos) - 1, old: n.Value, new: strconv.FormatInt(v+1, 10)}
+			}
+		}
+		return op == nil
+	})
+	switch {
+	case op != nil:
+		return *op, true
+	case constant != nil:
+		return *constant, true
+	}
+	return mutation{}, false
+}
+
+// enclosingTest returns the name of the test function of the test file
+// path, with content src, holding line, or "" if there is none.
+func enclosingTest(path, src string, line int) string {
+	if !strings.HasSuffix(path, "_test.go") {
+		return ""
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+			continue
+		}
+		if fset.Position(fn.Pos()).Line <= line && line <= fset.Position(fn.End()).Line {
+			return fn.Name.Name
+		}
+	}
+	return ""
+}
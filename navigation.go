@@ -0,0 +1,144 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Go to Definition
+// -------------------------------------------------------------------------
+
+// location is a position in a source file, as reported by gopls or found
+// in compiler output.
+type location struct {
+	path string
+	line int // 1-based line
+	col  int // 1-based UTF-8 byte column, as used by gopls
+	err  error
+}
+
+// definitionRe matches the "file:line:col" prefix of gopls definition output.
+var definitionRe = regexp.MustCompile(`^(.+?):(\d+):(\d+)`)
+
+// onGoToDefinition resolves the identifier under the cursor with
+// `gopls definition` and jumps to it, opening its file if needed. On the
+// name of a testdata file it opens the file.
+// The lookup runs in the background; the result is dispatched to
+// showDefinition.
+func (i *Ite) onGoToDefinition() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.openFixture() {
+		return
+	}
+	gopls, err := exec.LookPath("gopls")
+	if err != nil {
+		i.showError("Go to Definition requires gopls: " + err.Error())
+		return
+	}
+	// gopls reads the file from disk
+	if i.editText.Modified() {
+		i.onSave()
+	}
+
+	line, col := parseIndex(i.editText.Index("insert"))
+	runes := []rune(lineText(i.editText, line))
+	byteCol := len(string(runes[:min(col, len(runes))])) + 1
+	pos := fmt.Sprintf("%s:%d:%d", i.currentFile, line, byteCol)
+	dir := filepath.Dir(i.currentFile)
+
+	go func() {
+		cmd := exec.Command(gopls, "definition", pos)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		def := parseDefinition(output, err)
+		i.Dispatch(func() { i.showDefinition(def) })
+	}()
+}
+
+// onControlClick moves the cursor under the mouse and jumps to the
+// definition of the identifier there.
+func (i *Ite) onControlClick(e *Event) {
+	i.editText.MarkSet("insert", mouseIndex(i.editText, e))
+	i.onGoToDefinition()
+	e.SetReturnCodeBreak()
+}
+
+// parseDefinition extracts the target location from gopls output.
+func parseDefinition(output []byte, err error) location {
+	text := strings.TrimSpace(string(output))
+	if err != nil {
+		if text != "" {
+			err = errors.New(text)
+		}
+		return location{err: err}
+	}
+	m := definitionRe.FindStringSubmatch(text)
+	if m == nil {
+		return location{err: errors.New("no definition found")}
+	}
+	line, _ := strconv.Atoi(m[2])
+	col, _ := strconv.Atoi(m[3])
+	return location{path: m[1], line: line, col: col}
+}
+
+// showDefinition shows the result of a Go to Definition lookup.
+func (i *Ite) showDefinition(def location) {
+	if def.err != nil {
+		i.showError("Go to Definition: " + def.err.Error())
+		return
+	}
+	i.showLocation(def)
+}
+
+// showLocation opens the file holding loc, if it isn't the current one,
+// and moves the cursor to it.
+func (i *Ite) showLocation(loc location) {
+	if !samePath(loc.path, i.currentFile) {
+		if !i.promptSaveIfModified() {
+			return
+		}
+		if err := i.openFile(loc.path); err != nil {
+			i.showError("Error opening file: " + err.Error())
+			return
+		}
+	}
+	i.jumpTo(loc.line, runeColumn(lineText(i.editText, loc.line), loc.col-1))
+}
+
+// jumpTo moves the cursor to the given line and character column, scrolls
+// it into view and focuses the editor.
+func (i *Ite) jumpTo(line, col int) {
+	index := fmt.Sprintf("%d.%d", line, col)
+	i.editText.TagRemove("sel", "1.0", "end")
+	i.editText.MarkSet("insert", index)
+	i.editText.See(index)
+	i.refreshCursorState()
+	Focus(i.editText)
+}
+
+// samePath reports whether a and b name the same file.
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"math/big"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Number Base Conversion
+// -------------------------------------------------------------------------
+
+// numberBase is a way of writing an integer literal in Go.
+type numberBase struct {
+	name   string
+	prefix string
+	base   int
+}
+
+// numberBases lists the bases an integer literal can be converted to.
+var numberBases = []numberBase{
+	{"Decimal", "", 10},
+	{"Hexadecimal", "0x", 16},
+	{"Octal", "0o", 8},
+	{"Binary", "0b", 2},
+}
+
+// intLiteral is an integer literal of the buffer.
+type intLiteral struct {
+	text       string
+	value      *big.Int
+	start, end int // Rune columns on its line
+}
+
+// onConvertNumber pops up, at the cursor, a menu converting the integer
+// literal under it to the other bases.
+func (i *Ite) onConvertNumber() {
+	line, col := parseIndex(i.editText.Index("insert"))
+	lit, ok := intLiteralAt(lineText(i.editText, line), col)
+	if !ok {
+		i.showStatusHint("No integer literal at the cursor")
+		return
+	}
+	if i.numberMenu == nil {
+		i.numberMenu = Menu(Tearoff(false))
+	}
+	menu := i.numberMenu
+	tclEval("%s delete 0 end", menu)
+	for _, b := range numberBases {
+		text := formatInt(lit.value, b)
+		if text == lit.text {
+			continue
+		}
+		menu.AddCommand(Lbl(fmt.Sprintf("%s: %s", b.name, text)), Command(func() {
+			i.replaceLiteral(line, lit, text)
+		}))
+	}
+	bbox := strings.Fields(tclEval("%s bbox insert", i.editText))
+	if len(bbox) < 4 {
+		return
+	}
+	x := winfoInt(tclEval("winfo rootx %s", i.editText)) + winfoInt(bbox[0])
+	y := winfoInt(tclEval("winfo rooty %s", i.editText)) + winfoInt(bbox[1]) + winfoInt(bbox[3])
+	Popup(menu.Window, x, y, nil)
+}
+
+// replaceLiteral writes text in place of lit, on line.
+func (i *Ite) replaceLiteral(line int, lit intLiteral, text string) {
+	from, to := fmt.Sprintf("%d.%d", line, lit.start), fmt.Sprintf("%d.%d", line, lit.end)
+	if i.editText.Get(from, to)[0] != lit.text || i.blockProtected(from, to) {
+		return
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, text)
+	})
+	i.refreshCursorState()
+}
+
+// intLiteralAt returns the integer literal of line at or right before
+// the rune column col.
+func intLiteralAt(line string, col int) (intLiteral, bool) {
+	src := []byte(line)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return intLiteral{}, false
+		}
+		start := runeColumn(line, file.Offset(pos))
+		end := start + len(lit) // Literals are ASCII
+		if start > col {
+			return intLiteral{}, false
+		}
+		if tok != token.INT || col > end {
+			continue
+		}
+		value, ok := new(big.Int).SetString(lit, 0)
+		if !ok {
+			return intLiteral{}, false
+		}
+		return intLiteral{text: lit, value: value, start: start, end: end}, true
+	}
+}
+
+// formatInt writes n as a Go literal in base b.
+func formatInt(n *big.Int, b numberBase) string {
+	return b.prefix + n.Text(b.base)
+}
@@ -0,0 +1,386 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// OpenAPI Preview
+// -------------------------------------------------------------------------
+
+// OpenAPI Preview lists the operations of the OpenAPI or Swagger spec of
+// the project, and clicking one opens its handler: the function whose
+// swaggo comment routes it, // @Router /users/{id} [get], or else the one
+// named after its operationId. With swaggo annotations in the project,
+// Regenerate runs swag init in the Tasks console and lists the new spec.
+const swagSpec = "docs/swagger.json" // Spec written by swag init
+
+// openAPIFiles are the spec files looked for, relative to the project
+// root, in order.
+var openAPIFiles = []string{
+	"openapi.yaml", "openapi.yml", "openapi.json",
+	"swagger.yaml", "swagger.yml", "swagger.json",
+	"api/openapi.yaml", "api/openapi.yml", "api/openapi.json",
+	swagSpec, "docs/swagger.yaml",
+}
+
+// apiMethods are the operations of a path item, in the order listed.
+var apiMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var (
+	// swagRouterRe matches a swaggo route comment, capturing the path and
+	// the method
+	swagRouterRe = regexp.MustCompile(`^\s*//\s*@Router\s+(\S+)\s+\[(\w+)\]`)
+	// funcNameRe matches a function declaration, capturing its name
+	funcNameRe = regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?(\w+)`)
+)
+
+// apiOperation is an operation of a spec.
+type apiOperation struct {
+	method  string // Lower case, as in the spec
+	path    string
+	summary string
+	id      string // operationId, "" if none
+}
+
+// openAPIPanel is the OpenAPI Preview window.
+type openAPIPanel struct {
+	window   *ToplevelWidget
+	view     *TextWidget
+	regen    *TButtonWidget
+	handlers map[int]location // Handlers of the operations, by view line
+}
+
+// findOpenAPISpec returns the spec file of the project at root.
+func findOpenAPISpec(root string) (string, bool) {
+	for _, name := range openAPIFiles {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// parseOpenAPI returns the operations of the spec data read from path, a
+// JSON or YAML file, sorted by path.
+func parseOpenAPI(path string, data []byte) ([]apiOperation, error) {
+	var ops []apiOperation
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var spec struct {
+			Paths map[string]map[string]json.RawMessage `json:"paths"`
+		}
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return nil, err
+		}
+		for p, item := range spec.Paths {
+			for method, raw := range item {
+				var op struct {
+					Summary     string `json:"summary"`
+					OperationID string `json:"operationId"`
+				}
+				if slices.Contains(apiMethods, method) && json.Unmarshal(raw, &op) == nil {
+					ops = append(ops, apiOperation{method: method, path: p, summary: op.Summary, id: op.OperationID})
+				}
+			}
+		}
+	} else {
+		ops = openAPIFromYAML(string(data))
+	}
+	slices.SortStableFunc(ops, func(a, b apiOperation) int {
+		return cmp.Or(strings.Compare(a.path, b.path),
+			cmp.Compare(slices.Index(apiMethods, a.method), slices.Index(apiMethods, b.method)))
+	})
+	return ops, nil
+}
+
+// yamlEntry splits the trimmed YAML line of a mapping into its key and
+// value, unquoted.
+func yamlEntry(line string) (key, value string, ok bool) {
+	if k, found := strings.CutSuffix(line, ":"); found {
+		key = k
+	} else if n := strings.Index(line, ": "); n >= 0 {
+		key, value = line[:n], strings.TrimSpace(line[n+2:])
+	} else {
+		return "", "", false
+	}
+	if n := strings.Index(value, " #"); n >= 0 {
+		value = strings.TrimSpace(value[:n])
+	}
+	return strings.Trim(key, `"'`), strings.Trim(value, `"'`), true
+}
+
+// openAPIFromYAML returns the operations of the YAML spec src, reading
+// the paths mapping by indentation.
+func openAPIFromYAML(src string) []apiOperation {
+	var ops []apiOperation
+	inPaths := false
+	pathIndent, methodIndent, cur := -1, -1, -1
+	path := ""
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		key, value, ok := yamlEntry(trimmed)
+		if depth == 0 {
+			inPaths = ok && key == "paths"
+			pathIndent = -1
+			continue
+		}
+		if !inPaths || !ok {
+			continue
+		}
+		if pathIndent < 0 {
+			pathIndent = depth
+		}
+		switch {
+		case depth == pathIndent:
+			path, methodIndent, cur = key, -1, -1
+		case depth > pathIndent && (methodIndent < 0 || depth == methodIndent):
+			methodIndent, cur = depth, -1
+			if slices.Contains(apiMethods, key) {
+				ops = append(ops, apiOperation{method: key, path: path})
+				cur = len(ops) - 1
+			}
+		case cur >= 0 && key == "summary" && ops[cur].summary == "":
+			ops[cur].summary = value
+		case cur >= 0 && key == "operationId":
+			ops[cur].id = value
+		}
+	}
+	return ops
+}
+
+// goHandlers holds the functions of a project an operation may lead to.
+type goHandlers struct {
+	routes map[string]location // Functions routed by swaggo comments, by "method path"
+	funcs  map[string]location // Functions, by name in lower case
+	swag   bool                // Some function has swaggo annotations
+}
+
+// routeKey returns the key of an operation in goHandlers.routes.
+func routeKey(method, path string) string {
+	return strings.ToLower(method) + " " + path
+}
+
+// scan adds the functions of the Go file at path, whose content is src,
+// to h.
+func (h *goHandlers) scan(path, src string) {
+	var routes []string // Routes of the doc comment read
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if m := swagRouterRe.FindStringSubmatch(text); m != nil {
+			routes = append(routes, routeKey(m[2], m[1]))
+			h.swag = true
+			continue
+		}
+		if m := funcNameRe.FindStringSubmatch(text); m != nil {
+			loc := location{path: path, line: line, col: 1}
+			for _, r := range routes {
+				h.routes[r] = loc
+			}
+			if _, dup := h.funcs[strings.ToLower(m[1])]; !dup {
+				h.funcs[strings.ToLower(m[1])] = loc
+			}
+		}
+		if !strings.HasPrefix(strings.TrimSpace(text), "//") {
+			routes = nil
+		}
+	}
+}
+
+// findGoHandlers scans the Go files of the project at root.
+func findGoHandlers(root string) goHandlers {
+	h := goHandlers{routes: make(map[string]location), funcs: make(map[string]location)}
+	walkProject(root, []string{"vendor/"}, func(path string) error {
+		if filepath.Ext(path) != defaultFileExtension || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		if data, err := os.ReadFile(path); err == nil {
+			h.scan(path, string(data))
+		}
+		return nil
+	})
+	return h
+}
+
+// handler returns the function handling op.
+func (h goHandlers) handler(op apiOperation) (location, bool) {
+	if loc, ok := h.routes[routeKey(op.method, op.path)]; ok {
+		return loc, true
+	}
+	if op.id == "" {
+		return location{}, false
+	}
+	loc, ok := h.funcs[strings.ToLower(op.id)]
+	return loc, ok
+}
+
+// onOpenAPIPreview opens the OpenAPI Preview window of the project.
+func (i *Ite) onOpenAPIPreview() {
+	if i.openAPI == nil {
+		p := &openAPIPanel{window: Toplevel()}
+		p.view = p.window.Text(textStyle(), Width(90), Height(25), Wrap("none"))
+		scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.view) }))
+		p.view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+		p.regen = p.window.TButton(Txt(tr("Regenerate")), Command(i.regenerateOpenAPI))
+		Grid(p.view, Row(0), Column(0), Sticky(NEWS))
+		Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+		Grid(p.regen, Row(1), Column(0), Columnspan(2), Sticky(W), Padx(px(5)), Pady(px(3)))
+		GridRowConfigure(p.window, 0, Weight(1))
+		GridColumnConfigure(p.window, 0, Weight(1))
+		p.view.TagConfigure(tagLink, Foreground(theme.Link))
+		p.view.TagBind(tagLink, "<Button-1>", func() {
+			line, _ := parseIndex(p.view.Index("current"))
+			i.openAPIHandler(line)
+		})
+		p.view.TagBind(tagLink, "<Enter>", func() { p.view.Configure(Cursor("hand2")) })
+		p.view.TagBind(tagLink, "<Leave>", func() { p.view.Configure(Cursor("xterm")) })
+		bindPanelKeys(p.view, i.openAPIHandler)
+		closeWindow := func() {
+			Destroy(p.window)
+			i.openAPI = nil
+			Focus(i.editText)
+		}
+		Bind(p.window, "<Escape>", Command(closeWindow))
+		WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+		i.openAPI = p
+	}
+	WmDeiconify(i.openAPI.window.Window)
+	tclEval("raise %s", i.openAPI.window)
+	i.loadOpenAPI()
+}
+
+// loadOpenAPI reads the spec and the handlers of the project in the
+// background and lists them. Without a spec, a project annotated for
+// swaggo generates one first.
+func (i *Ite) loadOpenAPI() {
+	root := i.projectDir()
+	i.showOpenAPIText("Reading the spec...")
+	go func() {
+		h := findGoHandlers(root)
+		spec, found := findOpenAPISpec(root)
+		var ops []apiOperation
+		var err error
+		if found {
+			var data []byte
+			if data, err = os.ReadFile(spec); err == nil {
+				ops, err = parseOpenAPI(spec, data)
+			}
+		}
+		i.Dispatch(func() {
+			p := i.openAPI
+			if p == nil {
+				return
+			}
+			regen := "disabled"
+			if h.swag {
+				regen = "normal"
+			}
+			p.regen.Configure(State(regen))
+			switch {
+			case !found && h.swag:
+				i.regenerateOpenAPI()
+			case !found:
+				i.showOpenAPIText("No OpenAPI spec or swaggo annotations found in the project.")
+			case err != nil:
+				i.showOpenAPIText(fmt.Sprintf("%s: %v", relativeTo(root, spec), err))
+			default:
+				i.fillOpenAPI(relativeTo(root, spec), ops, h)
+			}
+		})
+	}()
+}
+
+// regenerateOpenAPI runs swag init at the project root and lists the spec
+// it writes.
+func (i *Ite) regenerateOpenAPI() {
+	if _, err := exec.LookPath("swag"); err != nil {
+		i.showOpenAPIText("swag is not installed: go install github.com/swaggo/swag/cmd/swag@latest")
+		return
+	}
+	root := i.projectDir()
+	i.showOpenAPIText("Running swag init...")
+	j := &job{c: i.console(consoleTasks), decoder: plainOutput{}, dir: root,
+		steps: []jobStep{{name: "swag", args: []string{"swag", "init"}}}}
+	j.done = func(res procResult, stopped bool) {
+		if i.openAPI == nil || stopped {
+			return
+		}
+		if res.err != nil {
+			i.showOpenAPIText("swag init failed, see the Tasks console.")
+			return
+		}
+		i.loadOpenAPI()
+	}
+	i.runJob(j, "Running swag init...\n")
+}
+
+// showOpenAPIText replaces the content of the window with a message.
+func (i *Ite) showOpenAPIText(text string) {
+	p := i.openAPI
+	if p == nil {
+		return
+	}
+	p.handlers = nil
+	p.view.Configure(State("normal"))
+	p.view.Delete("1.0", "end")
+	p.view.Insert("end", text)
+	p.view.Configure(State("disabled"))
+}
+
+// fillOpenAPI lists the operations of the spec, those with a handler as
+// links.
+func (i *Ite) fillOpenAPI(spec string, ops []apiOperation, h goHandlers) {
+	p := i.openAPI
+	i.showOpenAPIText("")
+	p.window.WmTitle("OpenAPI Preview: " + spec)
+	p.handlers = make(map[int]location)
+	p.view.Configure(State("normal"))
+	for n, op := range ops {
+		if n > 0 {
+			p.view.Insert("end", "\n")
+		}
+		route := fmt.Sprintf("%-7s %s", strings.ToUpper(op.method), op.path)
+		if loc, ok := h.handler(op); ok {
+			p.handlers[n+1] = loc
+			p.view.Insert("end", route, tagLink)
+		} else {
+			p.view.Insert("end", route)
+		}
+		if text := cmp.Or(op.summary, op.id); text != "" {
+			p.view.Insert("end", "  "+text)
+		}
+	}
+	if len(ops) == 0 {
+		p.view.Insert("end", "The spec has no operations.")
+	}
+	p.view.Configure(State("disabled"))
+	Focus(p.view)
+}
+
+// openAPIHandler opens the handler of the operation of the view line.
+func (i *Ite) openAPIHandler(line int) {
+	if loc, ok := i.openAPI.handlers[line]; ok {
+		i.showLocation(loc)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseOpenAPI(t *testing.T) {
+	yaml := `openapi: 3.0.0
+info:
+  title: Shop
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+    get:
+      summary: Get a user # By id
+      operationId: getUser
+      responses:
+        "200":
+          description: OK
+    delete:
+      operationId: deleteUser
+  "/orders":
+    post:
+      summary: "Place an order"
+components:
+  schemas: {}
+`
+	json := `{"paths": {"/users/{id}": {"delete": {"operationId": "deleteUser"}, "get": {"summary": "Get a user", "operationId": "getUser"}, "parameters": []},
+		"/orders": {"post": {"summary": "Place an order"}}}}`
+	want := []apiOperation{
+		{method: "post", path: "/orders", summary: "Place an order"},
+		{method: "get", path: "/users/{id}", summary: "Get a user", id: "getUser"},
+		{method: "delete", path: "/users/{id}", id: "deleteUser"},
+	}
+	for _, tt := range []struct{ path, data string }{{"openapi.yaml", yaml}, {"swagger.json", json}} {
+		got, err := parseOpenAPI(tt.path, []byte(tt.data))
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("parseOpenAPI(%s) = %+v, %v, want %+v", tt.path, got, err, want)
+		}
+	}
+}
+
+func TestGoHandlers(t *testing.T) {
+	h := goHandlers{routes: make(map[string]location), funcs: make(map[string]location)}
+	h.scan("users.go", `package api
+
+// GetUser returns a user.
+//
+//	@Summary	Get a user
+//	@Router		/users/{id} [get]
+func (s *Server) GetUser(w http.ResponseWriter, r *http.Request) {}
+
+func DeleteUser(w http.ResponseWriter, r *http.Request) {}
+`)
+	if !h.swag {
+		t.Error("scan found no swaggo annotations")
+	}
+	for _, tt := range []struct {
+		op   apiOperation
+		line int
+	}{
+		{apiOperation{method: "get", path: "/users/{id}"}, 7},
+		{apiOperation{method: "delete", path: "/users/{id}", id: "deleteUser"}, 9},
+		{apiOperation{method: "post", path: "/users"}, 0},
+	} {
+		loc, ok := h.handler(tt.op)
+		if ok != (tt.line > 0) || loc.line != tt.line {
+			t.Errorf("handler(%s %s) = %+v, %v, want line %d", tt.op.method, tt.op.path, loc, ok, tt.line)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Symbol Outline
+// -------------------------------------------------------------------------
+
+const (
+	outlineDelay = 800 * time.Millisecond // Typing pause before the outline is rebuilt
+	outlineWidth = 28                     // Width of the sidebar in characters
+)
+
+// outlineEntry is a top-level declaration listed in the outline.
+type outlineEntry struct {
+	label string // e.g. "func main" or "method (*Ite) onSave"
+	line  int    // 1-based line of the name
+	col   int    // Byte column of the name, 0-based
+}
+
+// ensureOutline builds the outline sidebar, which is left out of the
+// startup while hidden, and docks it in the left region.
+func (i *Ite) ensureOutline() {
+	if i.outlineList != nil {
+		return
+	}
+	i.makeOutline()
+	i.dock(regionLeft, i.outlineFrame.Window, i.config.ShowOutline)
+	i.fillOutline()
+}
+
+// makeOutline creates the sidebar listing the declarations of the buffer.
+func (i *Ite) makeOutline() {
+	i.outlineFrame = TFrame()
+	i.outlineList = i.outlineFrame.Listbox(Width(outlineWidth), Font(editorFontFamily, fontSize),
+		Activestyle("none"), Exportselection(false), Highlightthickness(focusRingWidth))
+	i.configureOutlineColors()
+	scrollbar := i.outlineFrame.TScrollbar(Command(func(e *Event) { e.Yview(i.outlineList) }))
+	i.outlineList.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(i.outlineList, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	GridRowConfigure(i.outlineFrame, 0, Weight(1))
+	GridColumnConfigure(i.outlineFrame, 0, Weight(1))
+	Bind(i.outlineList, "<<ListboxSelect>>", Command(i.onOutlineSelect))
+	Bind(i.outlineList, "<Return>", Command(func() { Focus(i.editText) }))
+}
+
+// configureOutlineColors applies the theme to the outline.
+func (i *Ite) configureOutlineColors() {
+	if i.outlineList == nil {
+		return
+	}
+	i.outlineList.Configure(Background(theme.Text), Foreground(theme.Foreground),
+		Highlightcolor(theme.Link), Highlightbackground(theme.Frame))
+}
+
+// onOutlineSelect jumps to the declaration clicked in the outline. While
+// the outline has the keyboard focus it keeps it, so the arrow keys browse
+// the declarations and Return goes to the editor.
+func (i *Ite) onOutlineSelect() {
+	sel := i.outlineList.Curselection()
+	if len(sel) == 0 || sel[0] >= len(i.outline) {
+		return
+	}
+	browsing := tclEval("focus") == i.outlineList.String()
+	e := i.outline[sel[0]]
+	i.jumpTo(e.line, runeColumn(lineText(i.editText, e.line), e.col))
+	if browsing {
+		Focus(i.outlineList)
+	}
+}
+
+// scheduleOutline rebuilds the outline once the user pauses typing.
+func (i *Ite) scheduleOutline() {
+	if i.outlineTimer != "" {
+		TclAfterCancel(i.outlineTimer)
+	}
+	i.outlineTimer = TclAfter(outlineDelay, func() {
+		i.outlineTimer = ""
+		i.refreshOutline()
+	})
+}
+
+// refreshOutline parses the buffer and lists its declarations. Parse errors
+// don't clear the outline: whatever the parser recovered is shown. Large
+// files have no outline.
+func (i *Ite) refreshOutline() {
+	src := ""
+	if !i.largeFile {
+		src = i.editText.Get("1.0", "end-1c")[0]
+	}
+	i.highlightSQL(src)
+	if src == i.outlineSrc {
+		return
+	}
+	i.outlineSrc = src
+	var entries []outlineEntry
+	var folds []foldRegion
+	var scopes []stickyScope
+	if i.currentFile == "" || filepath.Ext(i.currentFile) == defaultFileExtension {
+		fset := token.NewFileSet()
+		file, _ := parser.ParseFile(fset, "", src, parser.ParseComments|parser.SkipObjectResolution)
+		if file != nil {
+			entries = outlineEntries(fset, file)
+			folds = foldRegions(fset, file)
+			scopes = stickyScopes(fset, file)
+		}
+	}
+	i.outline = entries
+	i.fillOutline()
+	i.folds = folds
+	i.markFolds()
+	i.sticky.scopes = scopes
+	i.scheduleStickyScroll()
+}
+
+// fillOutline lists the declarations in the outline, if built.
+func (i *Ite) fillOutline() {
+	if i.outlineList == nil {
+		return
+	}
+	i.outlineList.Delete(0, "end")
+	for _, e := range i.outline {
+		i.outlineList.Insert("end", e.label)
+	}
+}
+
+// outlineEntries returns the funcs, methods, types and consts declared at
+// the top level of the parsed Go file, in source order.
+func outlineEntries(fset *token.FileSet, file *ast.File) []outlineEntry {
+	var entries []outlineEntry
+	add := func(label string, name *ast.Ident) {
+		pos := fset.Position(name.Pos())
+		entries = append(entries, outlineEntry{label: label, line: pos.Line, col: pos.Column - 1})
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				add("func "+d.Name.Name, d.Name)
+			} else {
+				add("method ("+receiverType(d.Recv.List[0].Type)+") "+d.Name.Name, d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add("type "+s.Name.Name, s.Name)
+				case *ast.ValueSpec:
+					if d.Tok != token.CONST {
+						continue
+					}
+					for _, name := range s.Names {
+						if name.Name != "_" {
+							add("const "+name.Name, name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// receiverType formats the type of a method receiver, e.g. "*Ite".
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+// onToggleOutline shows or hides the outline sidebar and persists the
+// choice.
+func (i *Ite) onToggleOutline() {
+	i.config.ShowOutline = !i.config.ShowOutline
+	i.outlineVar.Set(checkValue(i.config.ShowOutline))
+	i.saveConfig()
+	i.ensureOutline()
+	i.setPanelShown(i.outlineFrame.Window, i.config.ShowOutline)
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Building Unsaved Buffers
+// -------------------------------------------------------------------------
+
+// Go Build and Go Run save the current buffer first. With Build Unsaved
+// Buffers on they save nothing: the Go files with unsaved changes are
+// copied to a temporary directory, which -overlay makes the go command
+// read in place of the files on disk, so experimental edits can be tried
+// without writing them. Each console keeps the overlay of its latest run,
+// until the next one or the exit.
+
+// writeBufferOverlay writes buffers, the text of Go files by absolute
+// path, and the overlay file mapping them to their copies into the new
+// temporary directory dir. It returns dir and the path of the overlay
+// file.
+func writeBufferOverlay(buffers map[string]string) (dir, overlay string, err error) {
+	dir, err = os.MkdirTemp("", "ite-overlay-")
+	if err != nil {
+		return "", "", err
+	}
+	replace := make(map[string]string, len(buffers))
+	for n, path := range slices.Sorted(maps.Keys(buffers)) {
+		// Numbered, as files of different packages may share a name
+		copyPath := filepath.Join(dir, fmt.Sprintf("%d-%s", n, filepath.Base(path)))
+		if err := os.WriteFile(copyPath, []byte(buffers[path]), defaultFilePerms); err != nil {
+			os.RemoveAll(dir)
+			return "", "", err
+		}
+		replace[path] = copyPath
+	}
+	data, err := json.Marshal(map[string]map[string]string{"Replace": replace})
+	if err == nil {
+		overlay = filepath.Join(dir, "overlay.json")
+		err = os.WriteFile(overlay, data, defaultFilePerms)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return dir, overlay, nil
+}
+
+// addGoFlags inserts flags after the subcommand of the go steps of j.
+func addGoFlags(j *job, flags ...string) {
+	for n, step := range j.steps {
+		if len(step.args) > 1 && step.args[0] == "go" {
+			args := slices.Concat(step.args[:2], flags, step.args[2:])
+			j.steps[n].args = args
+		}
+	}
+}
+
+// prepareBuild readies the files job j builds: it saves the current
+// buffer or, with Build Unsaved Buffers on, has the go steps of j read
+// the Go files with unsaved changes from an overlay.
+func (i *Ite) prepareBuild(j *job) error {
+	if !i.config.BuildUnsaved {
+		if i.editText.Modified() {
+			i.onSave()
+		}
+		return nil
+	}
+	buffers := i.modifiedBuffers()
+	for path := range buffers {
+		if !strings.HasSuffix(path, defaultFileExtension) {
+			delete(buffers, path)
+		}
+	}
+	i.removeBuildOverlay(j.c)
+	if len(buffers) == 0 {
+		return nil
+	}
+	dir, overlay, err := writeBufferOverlay(buffers)
+	if err != nil {
+		return fmt.Errorf("writing the unsaved buffers: %w", err)
+	}
+	if i.buildOverlays == nil {
+		i.buildOverlays = make(map[*console]string)
+	}
+	i.buildOverlays[j.c] = dir
+	addGoFlags(j, "-overlay="+overlay)
+	return nil
+}
+
+// removeBuildOverlay deletes the overlay of the latest run of console c,
+// if any.
+func (i *Ite) removeBuildOverlay(c *console) {
+	if dir, ok := i.buildOverlays[c]; ok {
+		os.RemoveAll(dir)
+		delete(i.buildOverlays, c)
+	}
+}
+
+// removeBuildOverlays deletes the overlays of every console.
+func (i *Ite) removeBuildOverlays() {
+	for c := range i.buildOverlays {
+		i.removeBuildOverlay(c)
+	}
+}
+
+// onToggleBuildUnsaved switches Go Build and Go Run between saving the
+// current buffer and building the unsaved buffers as they are, and
+// persists the choice.
+func (i *Ite) onToggleBuildUnsaved() {
+	i.config.BuildUnsaved = !i.config.BuildUnsaved
+	i.buildUnsavedVar.Set(checkValue(i.config.BuildUnsaved))
+	i.saveConfig()
+	if i.config.BuildUnsaved {
+		i.showStatusHint("Build and Run use the unsaved buffers (-overlay), saving nothing")
+	} else {
+		i.showStatusHint("Build and Run save the current buffer first")
+	}
+}
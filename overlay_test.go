@@ -0,0 +1,56 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteBufferOverlay(t *testing.T) {
+	root := t.TempDir()
+	buffers := map[string]string{
+		filepath.Join(root, "a", "main.go"): "package main\n",
+		filepath.Join(root, "b", "main.go"): "package b\n",
+	}
+	dir, overlay, err := writeBufferOverlay(buffers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, err := os.ReadFile(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o struct{ Replace map[string]string }
+	if err := json.Unmarshal(data, &o); err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Replace) != len(buffers) {
+		t.Fatalf("overlay replaces %v, want the %d buffers", o.Replace, len(buffers))
+	}
+	for path, text := range buffers {
+		copied, err := os.ReadFile(o.Replace[path])
+		if err != nil || string(copied) != text {
+			t.Errorf("copy of %s = %q, %v, want %q", path, copied, err, text)
+		}
+	}
+}
+
+func TestAddGoFlags(t *testing.T) {
+	j := &job{steps: []jobStep{
+		{args: []string{"go", "build", "-o", "bin", "."}},
+		{args: []string{"bin", "-v"}},
+	}}
+	addGoFlags(j, "-overlay=o.json")
+	if want := []string{"go", "build", "-overlay=o.json", "-o", "bin", "."}; !slices.Equal(j.steps[0].args, want) {
+		t.Errorf("go step = %q, want %q", j.steps[0].args, want)
+	}
+	if want := []string{"bin", "-v"}; !slices.Equal(j.steps[1].args, want) {
+		t.Errorf("program step = %q, want %q", j.steps[1].args, want)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build !unix
+
+package main
+
+import (
+	"io/fs"
+	"os"
+)
+
+// preserveOwner does nothing on systems without Unix file owners.
+func preserveOwner(*os.File, fs.FileInfo) {}
@@ -0,0 +1,25 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build unix
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// preserveOwner gives f the owner and group of the file described by
+// info. Only the superuser can give files away, so failures are ignored:
+// the group alone may still change, to another group of the user.
+func preserveOwner(f *os.File, info fs.FileInfo) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	if f.Chown(int(st.Uid), int(st.Gid)) != nil {
+		f.Chown(-1, int(st.Gid))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Command Palette
+// -------------------------------------------------------------------------
+
+// paletteCommand is an entry of the command palette.
+type paletteCommand struct {
+	name string
+	run  func()
+}
+
+// paletteCommands returns the registered commands but the palette itself,
+// followed by the replace presets of the current project.
+func (i *Ite) paletteCommands() []paletteCommand {
+	var cmds []paletteCommand
+	for _, c := range i.commands.list {
+		if c.id != "commandPalette" {
+			cmds = append(cmds, paletteCommand{tr(c.title), c.run})
+		}
+	}
+
+	presets, err := i.loadPresets()
+	if err != nil {
+		i.showStatusHint("Error reading presets: " + err.Error())
+	}
+	for _, p := range presets {
+		cmds = append(cmds, paletteCommand{"Replace Preset: " + p.Name, func() { i.runReplace(p) }})
+	}
+	for _, p := range presets {
+		cmds = append(cmds, paletteCommand{"Delete Replace Preset: " + p.Name, func() { i.deletePreset(p.Name) }})
+	}
+	return cmds
+}
+
+// onCommandPalette opens the command palette: typing filters the commands,
+// Return or a double-click runs the selected one.
+func (i *Ite) onCommandPalette() {
+	cmds := i.paletteCommands()
+
+	dialog := Toplevel()
+	dialog.WmTitle("Command Palette")
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	entry := frame.TEntry(Width(50), Textvariable(""))
+	Grid(entry, Row(0), Column(0), Sticky(WE), Pady(px(5)))
+	list := frame.Listbox(Width(50), Height(15), Background(theme.Text))
+	Grid(list, Row(1), Column(0), Sticky(NEWS))
+	Focus(entry)
+
+	var shown []paletteCommand
+	filter := func() {
+		words := strings.Fields(strings.ToLower(entry.Textvariable()))
+		shown = shown[:0]
+		list.Delete(0, "end")
+	next:
+		for _, c := range cmds {
+			name := strings.ToLower(c.name)
+			for _, w := range words {
+				if !strings.Contains(name, w) {
+					continue next
+				}
+			}
+			shown = append(shown, c)
+			list.Insert("end", c.name)
+		}
+		if len(shown) > 0 {
+			list.SelectionSet(0)
+		}
+	}
+	run := func() {
+		sel := list.Curselection()
+		if len(sel) == 0 || sel[0] >= len(shown) {
+			return
+		}
+		cmd := shown[sel[0]]
+		Destroy(dialog)
+		Focus(i.editText)
+		cmd.run()
+	}
+	move := func(delta int) {
+		sel := list.Curselection()
+		if len(sel) == 0 || len(shown) == 0 {
+			return
+		}
+		n := min(max(sel[0]+delta, 0), len(shown)-1)
+		list.SelectionClear(0, "end")
+		list.SelectionSet(n)
+		list.See(n)
+	}
+	filter()
+
+	Bind(entry, "<KeyRelease>", Command(func(e *Event) {
+		switch e.Keysym {
+		case "Up", "Down", "Return", "Escape":
+		default:
+			filter()
+		}
+	}))
+	Bind(entry, "<Up>", Command(func() { move(-1) }))
+	Bind(entry, "<Down>", Command(func() { move(1) }))
+	Bind(entry, "<Return>", Command(run))
+	Bind(list, "<Double-Button-1>", Command(run))
+	Bind(dialog, "<Escape>", Command(func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}))
+}
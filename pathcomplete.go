@@ -0,0 +1,262 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Path Completion
+// -------------------------------------------------------------------------
+
+// Typing a path in a string literal, "./testdata/in", or in a //go:embed
+// directive lists the files and directories it may go on with below the
+// cursor. Up and Down choose one, Tab or Return insert it, Escape closes
+// the list. Paths are completed from the directory of the file, where
+// embed patterns, go test and go run resolve them, else from the project
+// root.
+const (
+	completeBindTag = "IteComplete" // Bind tag of the keys of the list
+	maxCompletions  = 50            // Entries listed at most
+	completeRows    = 8             // Height of the list
+	embedDirective  = "//go:embed "
+)
+
+// completionState is the list of path completions.
+type completionState struct {
+	window *ToplevelWidget // List, nil when hidden
+	list   *ListboxWidget
+	items  []string
+	start  string // Index of the start of the last path element typed
+	plugin bool   // The items are completions of the providers, see onComplete
+}
+
+// pathLiteralPrefix returns the path typed at the end of before, the
+// text of a line up to the cursor: the content of the string literal
+// left open, or the last pattern of a //go:embed directive. ok is false
+// when the cursor isn't in one or it doesn't look like a path.
+func pathLiteralPrefix(before string) (prefix string, ok bool) {
+	if rest, found := strings.CutPrefix(strings.TrimLeftFunc(before, unicode.IsSpace), embedDirective); found {
+		prefix = rest[strings.LastIndexAny(rest, " \t")+1:]
+		return prefix, !strings.ContainsAny(prefix, `"`+"`")
+	}
+	quote, start := rune(0), 0
+	escaped := false
+	for n, r := range before {
+		switch {
+		case quote == 0 && (r == '"' || r == '`'):
+			quote, start = r, n+1
+		case quote == 0 && r == '/' && strings.HasPrefix(before[n:], "//"):
+			return "", false // Comment
+		case quote == '"' && escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case r == quote:
+			quote = 0
+		}
+	}
+	if quote == 0 {
+		return "", false
+	}
+	prefix = before[start:]
+	if prefix == "" || strings.ContainsFunc(prefix, unicode.IsSpace) || strings.ContainsAny(prefix, `\%{}$*?:`) {
+		return "", false
+	}
+	return prefix, strings.Contains(prefix, "/") || strings.HasPrefix(prefix, ".")
+}
+
+// pathCompletions returns the names in base/dir of prefix, dir being
+// prefix up to its last slash, starting with the rest of prefix, the
+// directories ending with a slash. Hidden names are left out unless the
+// rest starts with a dot.
+func pathCompletions(base, prefix string) []string {
+	dir, partial := "", prefix
+	if n := strings.LastIndex(prefix, "/"); n >= 0 {
+		dir, partial = prefix[:n+1], prefix[n+1:]
+	}
+	entries, err := os.ReadDir(filepath.Join(base, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, partial) || name == partial ||
+			(strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".")) {
+			continue
+		}
+		if e.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+		if len(names) == maxCompletions {
+			break
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// bindPathCompletion installs the keys of the list, first to see them
+// before the editor.
+func (i *Ite) bindPathCompletion() {
+	addBindtag(i.editText.Window, completeBindTag, "")
+	handle := func(fn func()) func(*Event) {
+		return func(e *Event) {
+			if i.completion.window != nil {
+				fn()
+				e.SetReturnCodeBreak()
+			}
+		}
+	}
+	Bind(completeBindTag, "<Down>", Command(handle(func() { i.moveCompletion(1) })))
+	Bind(completeBindTag, "<Up>", Command(handle(func() { i.moveCompletion(-1) })))
+	Bind(completeBindTag, "<Tab>", Command(handle(i.acceptCompletion)))
+	Bind(completeBindTag, "<Return>", Command(handle(i.acceptCompletion)))
+	Bind(completeBindTag, "<Escape>", Command(handle(i.hideCompletion)))
+	Bind(completeBindTag, "<ButtonPress>", Command(i.hideCompletion))
+	Bind(completeBindTag, "<FocusOut>", Command(i.hideCompletion))
+}
+
+// onTogglePathCompletion switches path completion on or off and persists
+// the choice.
+func (i *Ite) onTogglePathCompletion() {
+	i.config.PathCompletion = !i.config.PathCompletion
+	i.completionVar.Set(checkValue(i.config.PathCompletion))
+	i.saveConfig()
+	if !i.config.PathCompletion {
+		i.hideCompletion()
+	}
+}
+
+// updatePathCompletion lists the completions of the path typed before the
+// cursor, or hides the list out of paths. It runs after every key, the
+// list opening on typing only.
+func (i *Ite) updatePathCompletion() {
+	if i.completion.plugin {
+		if i.undo.lastKind == editNone {
+			return // Choosing among the completions of the providers
+		}
+		i.hideCompletion()
+	}
+	if !i.config.PathCompletion || i.largeFile || i.loading() ||
+		(i.completion.window == nil && i.undo.lastKind == editNone) {
+		i.hideCompletion()
+		return
+	}
+	prefix, ok := pathLiteralPrefix(i.editText.Get("insert linestart", "insert")[0])
+	if !ok {
+		i.hideCompletion()
+		return
+	}
+	items := pathCompletions(i.completionBase(prefix), prefix)
+	if len(items) == 0 {
+		i.hideCompletion()
+		return
+	}
+	partial := prefix[strings.LastIndex(prefix, "/")+1:]
+	i.completion.start = i.editText.Index(fmt.Sprintf("insert -%d chars", utf8.RuneCountInString(partial)))
+	i.showCompletion(items)
+}
+
+// completionBase returns the directory prefix is completed from: that of
+// the current file if the directories of prefix are there, else the
+// project root.
+func (i *Ite) completionBase(prefix string) string {
+	if i.currentFile == "" {
+		return i.projectDir()
+	}
+	base := filepath.Dir(i.currentFile)
+	dir := filepath.Join(base, filepath.FromSlash(prefix[:strings.LastIndex(prefix, "/")+1]))
+	if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+		return base
+	}
+	return i.projectDir()
+}
+
+// showCompletion lists items below the cursor, the first one selected
+// unless they are already listed.
+func (i *Ite) showCompletion(items []string) {
+	x, y, ok := i.indexScreenPosition("insert")
+	if !ok {
+		i.hideCompletion()
+		return
+	}
+	if i.completion.window == nil {
+		w := Toplevel()
+		tclEval("wm overrideredirect %s 1", w)
+		WmTransient(w, App)
+		list := w.Listbox(Font(editorFontFamily, fontSize), Background(theme.CurrentLine),
+			Borderwidth(1), Relief("solid"), Activestyle("none"), Exportselection(false))
+		Grid(list, Row(0), Column(0), Sticky(NEWS))
+		i.completion.window, i.completion.list = w, list
+	}
+	if slices.Equal(items, i.completion.items) {
+		return // Keep the selection
+	}
+	i.completion.items = items
+	list := i.completion.list
+	list.Delete(0, "end")
+	width := 1
+	for _, item := range items {
+		list.Insert("end", item)
+		width = max(width, utf8.RuneCountInString(item))
+	}
+	list.Configure(Width(width+1), Height(min(len(items), completeRows)))
+	list.SelectionSet(0)
+	list.See(0)
+	WmGeometry(i.completion.window.Window, fmt.Sprintf("+%d+%d", x, y))
+	tclEval("raise %s", i.completion.window)
+}
+
+// hideCompletion closes the list.
+func (i *Ite) hideCompletion() {
+	if i.completion.window != nil {
+		Destroy(i.completion.window)
+		i.completion = completionState{}
+	}
+}
+
+// selectedCompletion returns the row of the entry selected.
+func (i *Ite) selectedCompletion() int {
+	if sel := i.completion.list.Curselection(); len(sel) > 0 && sel[0] < len(i.completion.items) {
+		return sel[0]
+	}
+	return 0
+}
+
+// moveCompletion selects the entry delta rows from the selected one.
+func (i *Ite) moveCompletion(delta int) {
+	list := i.completion.list
+	n := min(max(i.selectedCompletion()+delta, 0), len(i.completion.items)-1)
+	list.SelectionClear(0, "end")
+	list.SelectionSet(n)
+	list.See(n)
+}
+
+// acceptCompletion puts the selected entry in place of the path element
+// typed, listing what follows a directory.
+func (i *Ite) acceptCompletion() {
+	item := i.completion.items[i.selectedCompletion()]
+	i.editGroup(func() {
+		i.editText.Delete(i.completion.start, "insert")
+		i.editText.Insert("insert", item)
+	})
+	i.refreshCursorState()
+	if strings.HasSuffix(item, "/") && !i.completion.plugin {
+		i.updatePathCompletion()
+	} else {
+		i.hideCompletion()
+	}
+}
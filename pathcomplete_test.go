@@ -0,0 +1,63 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPathLiteralPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		before, prefix string
+		ok             bool
+	}{
+		{`	f, err := os.Open("./testdata/in`, "./testdata/in", true},
+		{"	data := readFile(`testdata/", "testdata/", true},
+		{`	name := "a\"b/c`, `a\"b/c`, false},
+		{`	fmt.Println("hello`, "", false},
+		{`	fmt.Printf("%s/%d`, "", false},
+		{`	s := "done" + "x/`, "x/", true},
+		{`	s := "done" // ./testdata/`, "", false},
+		{`	s := "a b/c`, "", false},
+		{`//go:embed static/*.html templ`, "templ", true},
+		{`//go:embed `, "", true},
+		{`	x := "./a" + y`, "", false},
+	} {
+		prefix, ok := pathLiteralPrefix(tt.before)
+		if ok != tt.ok || (ok && prefix != tt.prefix) {
+			t.Errorf("pathLiteralPrefix(%q) = %q, %v; want %q, %v", tt.before, prefix, ok, tt.prefix, tt.ok)
+		}
+	}
+}
+
+func TestPathCompletions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"testdata/input.txt", "testdata/inner/x.txt", "testdata/.hidden", "testdata/out.txt", "main.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range []struct {
+		prefix string
+		want   []string
+	}{
+		{"./testdata/in", []string{"inner/", "input.txt"}},
+		{"testdata/", []string{"inner/", "input.txt", "out.txt"}},
+		{"testdata/.", []string{".hidden"}},
+		{"./", []string{"main.go", "testdata/"}},
+		{"testdata/out.txt", nil},
+		{"missing/", nil},
+	} {
+		if got := pathCompletions(dir, tt.prefix); !slices.Equal(got, tt.want) {
+			t.Errorf("pathCompletions(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Path Display
+// -------------------------------------------------------------------------
+
+// relativeTo returns path relative to root when it lies inside root,
+// otherwise path unchanged.
+func relativeTo(root, path string) string {
+	if root == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// displayPath returns path as shown to the user: relative to the root of
+// the current project when relative paths are on.
+func (i *Ite) displayPath(path string) string {
+	if !i.config.RelativePaths || i.currentFile == "" {
+		return path
+	}
+	return relativeTo(i.projectDir(), path)
+}
+
+// updateTitle shows the current file in the window title and sets the tab
+// stops of its type.
+func (i *Ite) updateTitle() {
+	i.applyTabStops()
+	if i.currentFile == "" {
+		App.WmTitle(tr(statusUntitled))
+		return
+	}
+	name := filepath.Base(i.currentFile)
+	if i.config.RelativePaths {
+		name = i.displayPath(i.currentFile)
+	}
+	App.WmTitle(fmt.Sprintf("%s - ITE", name))
+}
+
+// relativizeLocations rewrites the source locations in console text
+// printed by a command run in dir, to be relative to the project root.
+func relativizeLocations(text, dir, root string) string {
+	return sourceLocRe.ReplaceAllStringFunc(text, func(loc string) string {
+		m := sourceLocRe.FindStringSubmatchIndex(loc)
+		path := loc[m[2]:m[3]]
+		abs := path
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(dir, abs)
+		}
+		return relativeTo(root, abs) + loc[m[3]:]
+	})
+}
+
+// resolveConsolePath turns a path printed in console c into an absolute
+// one. Relative paths are tried against the project root, where
+// relativizeLocations puts them, then against the run directory.
+func resolveConsolePath(c *console, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if c.root != "" {
+		if abs := filepath.Join(c.root, path); fileExists(abs) {
+			return abs
+		}
+	}
+	return filepath.Join(c.runDir, path)
+}
+
+// onToggleRelativePaths switches between paths relative to the project
+// root and absolute ones, and persists the choice. Console output already
+// printed keeps its paths.
+func (i *Ite) onToggleRelativePaths() {
+	i.config.RelativePaths = !i.config.RelativePaths
+	i.relativePathsVar.Set(checkValue(i.config.RelativePaths))
+	i.saveConfig()
+	i.updateTitle()
+}
+
+// fileExists reports whether path names an existing file.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,384 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// PDF Export
+// -------------------------------------------------------------------------
+
+// Diff views, patch reviews and consoles can be exported as PDF. The pages
+// are drawn in the Courier font built into every PDF reader, at a fixed
+// size whatever the zoom of the editor, black on white: the colors of the
+// theme are replaced by the print palette below, the background of the
+// changed lines kept light enough to print in grey. Characters outside
+// Latin-1 print as "?".
+const (
+	pdfExtension  = ".pdf"
+	pdfFontSize   = 8.0  // Points
+	pdfLeading    = 10.0 // Points between the baselines of two rows
+	pdfMargin     = 36.0 // Points around the text
+	pdfCharWidth  = 0.6  // Advance of a Courier character, in ems
+	pdfPageLong   = 842.0
+	pdfPageShort  = 595.0 // A4, in points
+	pdfSideGutter = " | " // Between the sides of a side-by-side diff
+)
+
+// printColors are the colors of the exported pages.
+const (
+	printText    = "#000000"
+	printMuted   = "#666666"
+	printError   = "#b00000"
+	printSuccess = "#006400"
+	printWarning = "#8a4b00"
+	printDel     = "#ffd8d8" // Background of removed lines
+	printAdd     = "#d8f5d8" // Background of added lines
+	printFiller  = "#eeeeee" // Background of the rows missing from a side
+)
+
+// printTagStyles are the print colors of the console tags, as foreground
+// and background.
+var printTagStyles = map[string][2]string{
+	tagStderr:    {printError, ""},
+	tagTestFail:  {printError, ""},
+	tagLogError:  {printError, ""},
+	tagLogWarn:   {printWarning, ""},
+	tagTestPass:  {printSuccess, ""},
+	tagTestSkip:  {printMuted, ""},
+	tagLogDebug:  {printMuted, ""},
+	tagRunStart:  {printMuted, ""},
+	tagTimestamp: {printMuted, ""},
+	tagDiffHunk:  {printMuted, ""},
+	tagDiffDel:   {"", printDel},
+	tagDiffAdd:   {"", printAdd},
+}
+
+// printRun is text printed in one style; empty colors are the defaults.
+type printRun struct {
+	text   string
+	fg, bg string
+}
+
+// printLine is a line of an exported page.
+type printLine []printRun
+
+// tagsRun returns the run of text in the print style of the first of tags
+// that has one.
+func tagsRun(text string, tags []string) printRun {
+	run := printRun{text: text}
+	for _, tag := range tags {
+		if style, ok := printTagStyles[tag]; ok {
+			run.fg = orDefault(run.fg, style[0])
+			run.bg = orDefault(run.bg, style[1])
+		}
+	}
+	return run
+}
+
+// askPDFPath asks for the file to export to, proposing name. It returns ""
+// when cancelled.
+func (i *Ite) askPDFPath(name string) string {
+	path := GetSaveFile(Title("Export as PDF..."), Initialdir(i.defaultDir()),
+		Initialfile(name+pdfExtension), Filetypes([]FileType{
+			{TypeName: "PDF Files", Extensions: []string{"*.pdf"}, MacType: ""},
+			{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
+		}))
+	if path == "" {
+		return ""
+	}
+	i.rememberDialogDir(path)
+	if filepath.Ext(path) == "" {
+		path += pdfExtension
+	}
+	return path
+}
+
+// writePDFFile asks for a file and writes the pages of lines to it.
+func (i *Ite) writePDFFile(name, title string, lines []printLine, landscape bool) {
+	path := i.askPDFPath(name)
+	if path == "" {
+		return
+	}
+	if err := os.WriteFile(path, renderPDF(title, lines, landscape), configFilePerms); err != nil {
+		i.showError("Error exporting: " + err.Error())
+		return
+	}
+	i.showStatusHint("Exported " + filepath.Base(path))
+}
+
+// onExportConsolePDF exports the lines of the selected console shown by
+// the filter, without the timestamps when they are hidden. Each line is
+// printed in the style of its last character, which is how the consoles
+// color whole lines.
+func (i *Ite) onExportConsolePDF() {
+	c := i.selectedConsole()
+	var lines []printLine
+	last, _ := parseIndex(c.text.Index("end -1c"))
+	for n := 1; n <= last; n++ {
+		start := fmt.Sprintf("%d.0", n)
+		if slices.Contains(c.text.TagNames(start), tagFiltered) {
+			continue
+		}
+		text := tclEval("%s get -displaychars %s {%s lineend}", c.text, start, start)
+		if text == "" {
+			lines = append(lines, nil)
+			continue
+		}
+		tags := c.text.TagNames(start + " lineend -1c")
+		lines = append(lines, printLine{tagsRun(text, tags)})
+	}
+	i.writePDFFile("console-"+strings.ToLower(c.name), c.name+" Console", lines, false)
+}
+
+// onExportDiffPDF exports the diff of v side by side, on landscape pages.
+func (i *Ite) onExportDiffPDF(v *diffView) {
+	nameB := "Buffer"
+	if v.pathB != "" {
+		nameB = i.displayPath(v.pathB)
+	}
+	half := (pdfColumns(true) - len(pdfSideGutter)) / 2
+	lines := sideBySide(printLine{{text: i.displayPath(v.pathA), fg: printMuted}},
+		printLine{{text: nameB, fg: printMuted}}, half)
+	left, right := strings.Split(v.left.Text(), "\n"), strings.Split(v.right.Text(), "\n")
+	for n := range min(len(left), len(right)) {
+		index := fmt.Sprintf("%d.0", n+1)
+		lines = append(lines, sideBySide(
+			printLine{diffSideRun(left[n], v.left.TagNames(index))},
+			printLine{diffSideRun(right[n], v.right.TagNames(index))}, half)...)
+	}
+	name := strings.TrimSuffix(filepath.Base(v.pathA), filepath.Ext(v.pathA)) + "-diff"
+	i.writePDFFile(name, "Diff of "+i.displayPath(v.pathA)+" and "+nameB, lines, true)
+}
+
+// diffSideRun returns the row text of a side of a diff view, tagged tags,
+// in its print style.
+func diffSideRun(text string, tags []string) printRun {
+	if slices.Contains(tags, tagDiffFiller) {
+		return printRun{bg: printFiller}
+	}
+	return tagsRun(text, tags)
+}
+
+// exportPatchPDF exports the files of the patch named name.
+func (i *Ite) exportPatchPDF(name string, files []*patchFile) {
+	var lines []printLine
+	for n, f := range files {
+		if n > 0 {
+			lines = append(lines, nil)
+		}
+		lines = append(lines,
+			printLine{{text: "--- " + f.oldPath, fg: printMuted}},
+			printLine{{text: "+++ " + f.newPath, fg: printMuted}})
+		for _, l := range f.lines {
+			run := printRun{text: l.text}
+			switch {
+			case l.header:
+				run.fg = printMuted
+			case l.op == diffDelete:
+				run.bg = printDel
+			case l.op == diffInsert:
+				run.bg = printAdd
+			}
+			lines = append(lines, printLine{run})
+		}
+	}
+	i.writePDFFile(strings.NewReplacer(" ", "_", "/", "_", ".", "_").Replace(name), "Review of "+name, lines, false)
+}
+
+// sideBySide returns the rows showing left and right next to each other,
+// each wrapped to half columns.
+func sideBySide(left, right printLine, half int) []printLine {
+	l, r := wrapPrintLine(left, half), wrapPrintLine(right, half)
+	rows := make([]printLine, max(len(l), len(r)))
+	for n := range rows {
+		var row printLine
+		if n < len(l) {
+			row = append(row, l[n]...)
+		}
+		row = append(row, padRun(row, half))
+		if n < len(r) {
+			row = append(row, printRun{text: pdfSideGutter, fg: printMuted})
+			row = append(row, r[n]...)
+		}
+		rows[n] = row
+	}
+	return rows
+}
+
+// padRun returns the run of blanks widening line to width columns, in the
+// background of its last run so a colored row spans its whole side.
+func padRun(line printLine, width int) printRun {
+	n, bg := 0, ""
+	for _, run := range line {
+		n += len([]rune(run.text))
+		bg = run.bg
+	}
+	return printRun{text: strings.Repeat(" ", max(width-n, 0)), bg: bg}
+}
+
+// wrapPrintLine returns line, its tabs expanded, split into rows of at
+// most width columns. An empty line is a single empty row.
+func wrapPrintLine(line printLine, width int) []printLine {
+	rows := []printLine{nil}
+	col := 0
+	for _, run := range line {
+		var b strings.Builder
+		flush := func() {
+			if b.Len() > 0 || run.text == "" {
+				rows[len(rows)-1] = append(rows[len(rows)-1], printRun{text: b.String(), fg: run.fg, bg: run.bg})
+			}
+			b.Reset()
+		}
+		for _, r := range run.text {
+			chars := string(r)
+			if r == '\t' {
+				chars = strings.Repeat(" ", tabWidth-col%tabWidth)
+			}
+			for _, c := range chars {
+				if col == width {
+					flush()
+					rows = append(rows, nil)
+					col = 0
+				}
+				b.WriteRune(c)
+				col++
+			}
+		}
+		flush()
+	}
+	return rows
+}
+
+// pdfColumns returns the characters fitting in a row of a page.
+func pdfColumns(landscape bool) int {
+	width := pdfPageShort
+	if landscape {
+		width = pdfPageLong
+	}
+	return int((width - 2*pdfMargin) / (pdfFontSize * pdfCharWidth))
+}
+
+// renderPDF returns the PDF document printing lines under title, wrapped
+// to the width of the pages, with the title and the page number at the
+// top of each page.
+func renderPDF(title string, lines []printLine, landscape bool) []byte {
+	width, height := pdfPageShort, pdfPageLong
+	if landscape {
+		width, height = height, width
+	}
+	cols := pdfColumns(landscape)
+	var rows []printLine
+	for _, line := range lines {
+		rows = append(rows, wrapPrintLine(line, cols)...)
+	}
+	perPage := int((height-2*pdfMargin)/pdfLeading) - 2 // Below the heading
+	pages := max((len(rows)+perPage-1)/perPage, 1)
+
+	var objects []string // Object n+1 is objects[n]
+	add := func(obj string) int {
+		objects = append(objects, obj)
+		return len(objects)
+	}
+	add("<< /Type /Catalog /Pages 2 0 R >>")
+	add("") // The page tree, once the pages are known
+	font := add("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	var kids []string
+	stamp := time.Now().Format("2006-01-02 15:04")
+	for p := range pages {
+		var c bytes.Buffer
+		heading := printLine{{text: fmt.Sprintf("%s  -  %s  -  page %d of %d", title, stamp, p+1, pages), fg: printMuted}}
+		drawPDFRow(&c, wrapPrintLine(heading, cols)[0], height-pdfMargin)
+		for n, row := range rows[min(p*perPage, len(rows)):min((p+1)*perPage, len(rows))] {
+			drawPDFRow(&c, row, height-pdfMargin-float64(n+2)*pdfLeading)
+		}
+		content := add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", c.Len(), c.String()))
+		page := add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(width), pdfNumber(height), font, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for n, obj := range objects {
+		offsets[n] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", n+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// drawPDFRow writes the drawing of row, with its baseline at y, to the
+// content stream c.
+func drawPDFRow(c *bytes.Buffer, row printLine, y float64) {
+	advance := pdfFontSize * pdfCharWidth
+	x := pdfMargin
+	for _, run := range row {
+		w := float64(len([]rune(run.text))) * advance
+		if run.bg != "" && w > 0 {
+			fmt.Fprintf(c, "%s rg %s %s %s %s re f\n", pdfColor(run.bg),
+				pdfNumber(x), pdfNumber(y-pdfLeading*0.25), pdfNumber(w), pdfNumber(pdfLeading))
+		}
+		x += w
+	}
+	x = pdfMargin
+	for _, run := range row {
+		if strings.TrimSpace(run.text) != "" {
+			fmt.Fprintf(c, "BT %s rg /F1 %s Tf %s %s Td (%s) Tj ET\n", pdfColor(orDefault(run.fg, printText)),
+				pdfNumber(pdfFontSize), pdfNumber(x), pdfNumber(y), pdfString(run.text))
+		}
+		x += float64(len([]rune(run.text))) * advance
+	}
+}
+
+// pdfColor returns the operands setting the "#rrggbb" color s.
+func pdfColor(s string) string {
+	v, _ := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	return fmt.Sprintf("%s %s %s", pdfNumber(float64(v>>16&0xff)/255),
+		pdfNumber(float64(v>>8&0xff)/255), pdfNumber(float64(v&0xff)/255))
+}
+
+// pdfNumber formats f as a PDF number, to the thousandth.
+func pdfNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 3, 64)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// pdfString returns s as the body of a PDF literal string in the
+// WinAnsi encoding of the font.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,337 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Plugins
+// -------------------------------------------------------------------------
+
+// Plugins are programs in the plugins directory of the config dir, started
+// with the editor. They talk to it over stdin and stdout, one JSON object
+// per line. The editor sends events:
+//
+//	{"event": "start", "version": 1, "path": "/src/main.go"}
+//	{"event": "open", "path": "/src/main.go"}
+//	{"event": "save", "path": "/src/main.go"}
+//	{"event": "close", "path": "/src/main.go"}
+//	{"event": "command", "id": "sort", "path": "/src/main.go", "text": "...",
+//	 "cursor": "12.4", "selection": {"start": "10.0", "end": "14.0"}}
+//	{"event": "complete", "id": "7", "path": "/src/main.go", "text": "...", "cursor": "12.4"}
+//
+// where "command" reports a click on a menu entry of the plugin and carries
+// the buffer, "complete" asks a completion provider for the completions at
+// the cursor, and the plugin sends requests:
+//
+//	{"request": "menu", "id": "sort", "label": "Sort Lines"}
+//	{"request": "edit", "start": "10.0", "end": "14.0", "text": "..."}
+//	{"request": "console", "text": "sorted 4 lines"}
+//	{"request": "status", "text": "sorted"}
+//	{"request": "provider", "label": "Local Model"}
+//	{"request": "completions", "id": "7", "start": "12.2", "items": ["Println", "Printf"]}
+//
+// A menu entry goes to the Plugins menu and becomes the command
+// "plugin.NAME.ID", NAME being the file name of the plugin, which the
+// palette offers and the keys file can bind. An edit replaces the text
+// between two Tk indices of the buffer, as one undo step; one giving a
+// "path" is dropped unless that file is open. What plugins write to
+// stderr goes to the Plugins console too. A provider request offers
+// completions, see completionProvider, answered with completions replacing
+// the text from start to the cursor.
+const (
+	pluginsDirName      = "plugins"
+	pluginProtocol      = 1         // Version sent in the start event
+	pluginCommandPrefix = "plugin." // Prefix of the ids of plugin commands
+	pluginBacklog       = 64        // Events waiting for a plugin before they are dropped
+)
+
+// pluginMessage is an event sent to a plugin or a request from one.
+type pluginMessage struct {
+	Event     string       `json:"event,omitempty"`
+	Request   string       `json:"request,omitempty"`
+	Version   int          `json:"version,omitempty"`
+	ID        string       `json:"id,omitempty"`
+	Label     string       `json:"label,omitempty"`
+	Path      string       `json:"path,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Cursor    string       `json:"cursor,omitempty"`
+	Selection *pluginRange `json:"selection,omitempty"`
+	Start     string       `json:"start,omitempty"`
+	End       string       `json:"end,omitempty"`
+	Items     []string     `json:"items,omitempty"`
+}
+
+// pluginRange is a range of the buffer, as Tk indices.
+type pluginRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// plugin is a running plugin process.
+type plugin struct {
+	name    string
+	cmd     *exec.Cmd
+	events  chan pluginMessage // Written to stdin in order by a goroutine
+	stopped bool               // Set when the editor stopped the plugin
+	offers  string             // Label of the completions it provides, "" if none
+	allowed *VariableOpt       // Menu state of its completions, enabled or not
+}
+
+// pluginsDir returns the absolute path of the plugins directory.
+func pluginsDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, pluginsDirName), nil
+}
+
+// startPlugins starts the programs of the plugins directory.
+func (i *Ite) startPlugins() {
+	dir, err := pluginsDir()
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			i.pluginLog("Error reading plugins: "+err.Error()+"\n", tagStderr)
+		}
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !isPluginProgram(path) {
+			continue
+		}
+		if err := i.startPlugin(path); err != nil {
+			i.pluginLog(fmt.Sprintf("%s: %v\n", e.Name(), err), tagStderr)
+		}
+	}
+}
+
+// isPluginProgram reports whether path is a program: an executable file
+// or, on Windows, a file with an executable extension.
+func isPluginProgram(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode()&0111 != 0
+}
+
+// startPlugin starts the plugin program path and sends it the start event.
+func (i *Ite) startPlugin(path string) error {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	cmd := exec.Command(path)
+	cmd.Dir = filepath.Dir(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p := &plugin{name: name, cmd: cmd, events: make(chan pluginMessage, pluginBacklog)}
+	i.plugins = append(i.plugins, p)
+
+	go func() {
+		enc := json.NewEncoder(stdin)
+		for ev := range p.events {
+			if enc.Encode(ev) != nil {
+				break
+			}
+		}
+		stdin.Close()
+		for range p.events {
+			// Drain events sent after the plugin exited
+		}
+	}()
+	go func() {
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() {
+			line := name + ": " + sc.Text() + "\n"
+			i.Dispatch(func() { i.pluginLog(line, tagStderr) })
+		}
+	}()
+	go func() {
+		sc := bufio.NewScanner(stdout)
+		sc.Buffer(nil, 64<<20) // Edits carry whole files
+		for sc.Scan() {
+			var req pluginMessage
+			if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+				msg := fmt.Sprintf("%s: invalid message: %v\n", name, err)
+				i.Dispatch(func() { i.pluginLog(msg, tagStderr) })
+				continue
+			}
+			i.Dispatch(func() { i.handlePluginRequest(p, req) })
+		}
+		io.Copy(io.Discard, stdout)
+		err := cmd.Wait()
+		i.Dispatch(func() {
+			if err != nil && !p.stopped {
+				i.pluginLog(fmt.Sprintf("%s exited: %v\n", name, err), tagStderr)
+			}
+			if p.offers != "" {
+				p.offers = "" // It completes no more
+				i.updateProviderStatus()
+			}
+		})
+	}()
+	p.send(pluginMessage{Event: "start", Version: pluginProtocol, Path: i.currentFile})
+	return nil
+}
+
+// send queues ev for the plugin, dropping it if the plugin doesn't keep
+// up, so a stuck plugin never blocks the editor.
+func (p *plugin) send(ev pluginMessage) {
+	select {
+	case p.events <- ev:
+	default:
+	}
+}
+
+// notifyPlugins sends the buffer event kind, for file path, to the plugins.
+func (i *Ite) notifyPlugins(kind, path string) {
+	for _, p := range i.plugins {
+		p.send(pluginMessage{Event: kind, Path: path})
+	}
+}
+
+// handlePluginRequest carries out request req of plugin p.
+func (i *Ite) handlePluginRequest(p *plugin, req pluginMessage) {
+	switch req.Request {
+	case "menu":
+		i.addPluginMenu(p, req.ID, req.Label)
+	case "edit":
+		i.applyPluginEdit(p, req)
+	case "console":
+		i.pluginLog(strings.TrimSuffix(req.Text, "\n")+"\n", "")
+	case "status":
+		i.showStatusHint(req.Text)
+	case "provider":
+		i.registerProvider(p, req.Label)
+	case "completions":
+		i.showProviderCompletions(p, req)
+	default:
+		i.pluginLog(fmt.Sprintf("%s: unknown request %q\n", p.name, req.Request), tagStderr)
+	}
+}
+
+// addPluginMenu registers the command id of plugin p and lists it in the
+// Plugins menu, created with its first entry.
+func (i *Ite) addPluginMenu(p *plugin, id, label string) {
+	if id == "" {
+		i.pluginLog(p.name+": menu request without id\n", tagStderr)
+		return
+	}
+	if label == "" {
+		label = id
+	}
+	cmdID := pluginCommandPrefix + p.name + "." + id
+	exists := i.commands.lookup(cmdID) != nil
+	i.commands.add(command{id: cmdID, title: "Plugin: " + label, run: func() { i.runPluginCommand(p, id) }})
+	if exists {
+		return
+	}
+	i.addMenuCommand(i.ensurePluginsMenu(), cmdID, label)
+}
+
+// ensurePluginsMenu returns the Plugins menu, created with its first
+// entry.
+func (i *Ite) ensurePluginsMenu() *MenuWidget {
+	if i.pluginsMenu == nil {
+		i.pluginsMenu = i.menubar.Menu()
+		i.menubar.AddCascade(Lbl(tr("Plugins")), Underline(0), Mnu(i.pluginsMenu))
+	}
+	return i.pluginsMenu
+}
+
+// runPluginCommand sends the command event id, with the buffer, to p.
+func (i *Ite) runPluginCommand(p *plugin, id string) {
+	ev := pluginMessage{
+		Event:  "command",
+		ID:     id,
+		Path:   i.currentFile,
+		Text:   i.editText.Text(),
+		Cursor: i.editText.Index("insert"),
+	}
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		ev.Selection = &pluginRange{Start: sel[0], End: sel[len(sel)-1]}
+	}
+	p.send(ev)
+}
+
+// applyPluginEdit replaces the text between the indices of req with its
+// text, as one undo step.
+func (i *Ite) applyPluginEdit(p *plugin, req pluginMessage) {
+	if req.Path != "" && !samePath(req.Path, i.currentFile) {
+		i.pluginLog(fmt.Sprintf("%s: edit of %s dropped, the file is not open\n", p.name, req.Path), tagStderr)
+		return
+	}
+	if req.Start == "" {
+		req.Start = "insert"
+	}
+	if req.End == "" {
+		req.End = req.Start
+	}
+	start, end := i.editText.Index(req.Start), i.editText.Index(req.End)
+	if indexLess(i.editText, end, start) {
+		start, end = end, start
+	}
+	if i.blockProtected(start, end) {
+		return
+	}
+	i.editGroup(func() {
+		i.editText.Delete(start, end)
+		i.editText.Insert(start, req.Text)
+	})
+	i.refreshCursorState()
+	i.updateGutter()
+}
+
+// pluginLog writes text to the Plugins console.
+func (i *Ite) pluginLog(text, tag string) {
+	i.appendConsole(i.console(consolePlugins), text, tag)
+}
+
+// stopPlugins closes the input of the plugins, which should make them
+// exit, and kills those still running.
+func (i *Ite) stopPlugins() {
+	for _, p := range i.plugins {
+		p.stopped = true
+		close(p.events)
+		killProcessGroup(p.cmd)
+	}
+	i.plugins = nil
+}
@@ -0,0 +1,331 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Preferences
+// -------------------------------------------------------------------------
+
+const (
+	defaultTabWidth        = 4  // Columns of a tab stop
+	defaultAutosaveSeconds = 30 // How often a modified buffer is written to its swap file
+	maxTabWidth            = 16
+	minAutosaveSeconds     = 5
+	maxAutosaveSeconds     = 3600
+)
+
+// wrapModes are the values of the text widget -wrap option.
+var wrapModes = []string{"none", "char", "word"}
+
+// Editor appearance in use, set from the settings by applyPreferenceGlobals.
+var (
+	editorFontFamily = defaultFontFamily
+	tabWidth         = defaultTabWidth
+	wrapMode         = "word"
+)
+
+// applyPreferenceGlobals copies the settings read by textStyle and the
+// other widget constructors, falling back to the defaults for values
+// out of range.
+func applyPreferenceGlobals(cfg *Config) {
+	editorFontFamily = orDefault(cfg.FontFamily, defaultFontFamily)
+	fontSize = cfg.FontSize
+	if fontSize < minFontSize || fontSize > maxFontSize {
+		fontSize = defaultFontSize
+	}
+	tabWidth = cfg.TabWidth
+	if tabWidth < 1 || tabWidth > maxTabWidth {
+		tabWidth = defaultTabWidth
+	}
+	wrapMode = cfg.WrapMode
+	if !slices.Contains(wrapModes, wrapMode) {
+		wrapMode = "word"
+	}
+}
+
+// orDefault returns s, or fallback when s is empty.
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// tabStops returns the -tabs value placing a stop every tabWidth columns
+// of the editor font.
+func tabStops() string {
+	return tabStopsOf(tabWidth)
+}
+
+// tabStopsOf returns the -tabs value placing a stop every columns columns
+// of the editor font.
+func tabStopsOf(columns int) string {
+	width := winfoInt(tclEval("font measure {{%s} %d} 0", editorFontFamily, fontSize))
+	if width <= 0 {
+		return "1c"
+	}
+	return strconv.Itoa(width * columns)
+}
+
+// autosaveInterval returns how often buffers are written to swap files.
+func (i *Ite) autosaveInterval() time.Duration {
+	seconds := i.config.AutosaveSeconds
+	if seconds < minAutosaveSeconds || seconds > maxAutosaveSeconds {
+		seconds = defaultAutosaveSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Where the file dialogs open when no default directory is set.
+const (
+	dialogDirProject = "project" // Root of the current project, the default
+	dialogDirFile    = "file"    // Directory of the current file
+	dialogDirLast    = "last"    // Directory of the latest file chosen
+)
+
+// dialogDirModes are the values of DialogDir, with their labels.
+var dialogDirModes = []struct{ mode, label string }{
+	{dialogDirProject, "Project root"},
+	{dialogDirFile, "Current file's directory"},
+	{dialogDirLast, "Last used directory"},
+}
+
+// dialogDirLabel returns the label, translated, of the DialogDir mode.
+func dialogDirLabel(mode string) string {
+	for _, m := range dialogDirModes {
+		if m.mode == mode {
+			return tr(m.label)
+		}
+	}
+	return tr(dialogDirModes[0].label)
+}
+
+// dialogDirSetting returns the DialogDir mode of label, as shown by
+// dialogDirLabel.
+func dialogDirSetting(label string) string {
+	for _, m := range dialogDirModes {
+		if tr(m.label) == label {
+			return m.mode
+		}
+	}
+	return dialogDirProject
+}
+
+// defaultDir returns the directory the file dialogs open in: the default
+// directory of the settings if any, else the one DialogDir picks, falling
+// back to the project root.
+func (i *Ite) defaultDir() string {
+	isDir := func(dir string) bool {
+		fi, err := os.Stat(dir)
+		return dir != "" && err == nil && fi.IsDir()
+	}
+	if isDir(i.config.DefaultDir) {
+		return i.config.DefaultDir
+	}
+	switch i.config.DialogDir {
+	case dialogDirFile:
+		if i.currentFile != "" && isDir(filepath.Dir(i.currentFile)) {
+			return filepath.Dir(i.currentFile)
+		}
+	case dialogDirLast:
+		if isDir(i.config.LastDialogDir) {
+			return i.config.LastDialogDir
+		}
+	}
+	return i.projectDir()
+}
+
+// rememberDialogDir records the directory of path, chosen in a file
+// dialog, for the dialogs opening in the last used directory. It is
+// saved with the settings on exit.
+func (i *Ite) rememberDialogDir(path string) {
+	if dir, err := filepath.Abs(filepath.Dir(path)); err == nil {
+		i.config.LastDialogDir = dir
+	}
+}
+
+// applyPreferences updates the open widgets after the settings changed.
+func (i *Ite) applyPreferences() {
+	applyPreferenceGlobals(i.config)
+	font := Font(editorFontFamily, fontSize)
+	for _, p := range i.panes {
+		i.withPane(p, func() {
+			i.editText.Configure(font, Wrap(i.editorWrap()))
+			i.applyTabStops()
+			i.updateCurrentLine()
+			i.markWhitespace()
+		})
+	}
+	for _, c := range i.consoles {
+		c.text.Configure(font, Tabs(tabStops()))
+	}
+	if i.outlineList != nil {
+		i.outlineList.Configure(font)
+	}
+	if i.editorFont != nil {
+		i.editorFont.Delete()
+	}
+	i.editorFont = NewFont(Family(editorFontFamily), Size(fontSize))
+	for _, p := range i.panes {
+		i.withPane(p, i.updateColumnGuide)
+	}
+	i.updateProseGuide()
+	i.scheduleIndentGuides()
+	i.scheduleVirtualText()
+	i.updateViewChecks()
+}
+
+// onPreferences opens the Preferences dialog. The settings are saved to
+// the config file and applied to the open windows on OK.
+func (i *Ite) onPreferences() {
+	dialog := Toplevel()
+	dialog.WmTitle(tr("Preferences"))
+	WmTransient(dialog, App)
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+
+	row := 0
+	field := func(label string, w Widget) {
+		Grid(frame.TLabel(Txt(tr(label))), Row(row), Column(0), Sticky(W), Pady(px(3)))
+		Grid(w, Row(row), Column(1), Sticky(W), Pady(px(3)))
+		row++
+	}
+	families := FontFamilies()
+	slices.Sort(families)
+	familyBox := frame.TCombobox(Values(families), Width(30), Textvariable(editorFontFamily))
+	field("Font family:", familyBox)
+	sizeBox := frame.TSpinbox(From(minFontSize), To(maxFontSize), Increment(1), Width(5),
+		Textvariable(strconv.Itoa(fontSize)))
+	field("Font size:", sizeBox)
+	tabBox := frame.TSpinbox(From(1), To(maxTabWidth), Increment(1), Width(5),
+		Textvariable(strconv.Itoa(tabWidth)))
+	field("Tab width:", tabBox)
+	wrapBox := frame.TCombobox(Values(wrapModes), State("readonly"), Width(8), Textvariable(wrapMode))
+	field("Line wrap:", wrapBox)
+	var guideLabels []string
+	for _, column := range guideColumns {
+		guideLabels = append(guideLabels, guideColumnLabel(column))
+	}
+	guideBox := frame.TCombobox(Values(guideLabels), State("readonly"), Width(8),
+		Textvariable(guideColumnLabel(i.config.GuideColumn)))
+	field("Column guide:", guideBox)
+	reflowBox := frame.TSpinbox(From(minReflowColumn), To(maxReflowColumn), Increment(1), Width(5),
+		Textvariable(strconv.Itoa(i.config.ReflowColumn)))
+	field("Reflow width:", reflowBox)
+	lineCheck := frame.TCheckbutton(Txt(tr("Highlight current line")), Variable(checkValue(i.config.HighlightLine)))
+	Grid(lineCheck, Row(row), Column(1), Sticky(W), Pady(px(3)))
+	row++
+	focusSaveCheck := frame.TCheckbutton(Txt(tr("Save files when switching away")),
+		Variable(checkValue(i.config.SaveOnFocusLoss)))
+	Grid(focusSaveCheck, Row(row), Column(1), Sticky(W), Pady(px(3)))
+	row++
+	modalCheck := frame.TCheckbutton(Txt(tr("Vim modal editing")), Variable(checkValue(i.config.ModalEditing)))
+	Grid(modalCheck, Row(row), Column(1), Sticky(W), Pady(px(3)))
+	row++
+	whitespaceCheck := frame.TCheckbutton(Txt(tr("Show whitespace")), Variable(checkValue(i.config.ShowWhitespace)))
+	Grid(whitespaceCheck, Row(row), Column(1), Sticky(W), Pady(px(3)))
+	row++
+	guidesCheck := frame.TCheckbutton(Txt(tr("Indentation guides")), Variable(checkValue(i.config.IndentGuides)))
+	Grid(guidesCheck, Row(row), Column(1), Sticky(W), Pady(px(3)))
+	row++
+	autosaveBox := frame.TSpinbox(From(minAutosaveSeconds), To(maxAutosaveSeconds), Increment(5), Width(5),
+		Textvariable(strconv.Itoa(int(i.autosaveInterval()/time.Second))))
+	field("Autosave every (s):", autosaveBox)
+
+	dirFrame := frame.TFrame()
+	dirEntry := dirFrame.TEntry(Width(30), Textvariable(i.config.DefaultDir))
+	Grid(dirEntry, Row(0), Column(0))
+	Grid(dirFrame.TButton(Txt(tr("Browse...")), Command(func() {
+		if dir := ChooseDirectory(Initialdir(i.defaultDir()), Parent(dialog)); dir != "" {
+			dirEntry.Configure(Textvariable(dir))
+		}
+	})), Row(0), Column(1), Padx(px(5)))
+	field("Default directory:", dirFrame)
+	var dialogDirLabels []string
+	for _, m := range dialogDirModes {
+		dialogDirLabels = append(dialogDirLabels, tr(m.label))
+	}
+	dialogDirBox := frame.TCombobox(Values(dialogDirLabels), State("readonly"), Width(24),
+		Textvariable(dialogDirLabel(i.config.DialogDir)))
+	field("Otherwise open dialogs in:", dialogDirBox)
+	var languageLabels []string
+	for _, lang := range languages {
+		languageLabels = append(languageLabels, languageLabel(lang))
+	}
+	languageBox := frame.TCombobox(Values(languageLabels), State("readonly"), Width(8),
+		Textvariable(languageLabel(i.config.Language)))
+	field("Language:", languageBox)
+
+	closeDialog := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+	ok := func() {
+		var problems []string
+		number := func(name string, box *TSpinboxWidget, lo, hi int) int {
+			n, err := strconv.Atoi(strings.TrimSpace(box.Textvariable()))
+			if err != nil || n < lo || n > hi {
+				problems = append(problems, fmt.Sprintf("%s must be a number from %d to %d", name, lo, hi))
+			}
+			return n
+		}
+		size := number("Font size", sizeBox, minFontSize, maxFontSize)
+		tabs := number("Tab width", tabBox, 1, maxTabWidth)
+		autosave := number("Autosave interval", autosaveBox, minAutosaveSeconds, maxAutosaveSeconds)
+		reflow := number("Reflow width", reflowBox, minReflowColumn, maxReflowColumn)
+		dir := strings.TrimSpace(dirEntry.Textvariable())
+		if dir != "" {
+			if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+				problems = append(problems, "Default directory "+dir+" doesn't exist")
+			}
+		}
+		if len(problems) > 0 {
+			i.showError(tr("Invalid preferences:\n") + strings.Join(problems, "\n"))
+			return
+		}
+		i.config.FontFamily = strings.TrimSpace(familyBox.Textvariable())
+		i.config.FontSize = size
+		i.config.TabWidth = tabs
+		i.config.WrapMode = wrapBox.Textvariable()
+		i.config.GuideColumn, _ = strconv.Atoi(guideBox.Textvariable()) // 0 for "none"
+		i.config.ReflowColumn = reflow
+		i.config.HighlightLine = lineCheck.Variable() == "1"
+		i.config.SaveOnFocusLoss = focusSaveCheck.Variable() == "1"
+		i.config.ModalEditing = modalCheck.Variable() == "1"
+		i.config.ShowWhitespace = whitespaceCheck.Variable() == "1"
+		i.config.IndentGuides = guidesCheck.Variable() == "1"
+		i.config.AutosaveSeconds = autosave
+		i.config.DefaultDir = dir
+		i.config.DialogDir = dialogDirSetting(dialogDirBox.Textvariable())
+		lang := languageSetting(languageBox.Textvariable())
+		languageChanged := lang != i.config.Language
+		i.config.Language = lang
+		i.saveConfig()
+		closeDialog()
+		i.applyPreferences()
+		i.setModalEditing(i.config.ModalEditing)
+		if languageChanged {
+			i.showStatusHint("The language changes at the next start")
+		}
+	}
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(row), Column(0), Columnspan(2), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt(tr("OK")), Command(ok)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt(tr("Cancel")), Command(closeDialog)), Row(0), Column(1), Padx(px(5)))
+	Bind(dialog, "<Return>", Command(ok))
+	Bind(dialog, "<Escape>", Command(closeDialog))
+}
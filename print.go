@@ -0,0 +1,180 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Printing and HTML Export
+// -------------------------------------------------------------------------
+
+// Export as HTML writes the buffer, or the lines of the selection, as a
+// page with line numbers and inline styles, Go source colored by token, so
+// it can be shared or pasted into documents. Print writes the same page to
+// a temporary file and opens it in the browser, which prints it; the
+// colors are those of the light theme, which suit paper.
+const (
+	printFileName = "ite-print.html" // Page printed, in the temporary directory
+	htmlExtension = ".html"
+)
+
+// htmlTokenColors are the colors of the Go tokens in exported listings.
+var htmlTokenColors = map[string]string{
+	"keyword": lightTheme.Link,
+	"comment": lightTheme.Muted,
+	"string":  lightTheme.Success,
+	"number":  lightTheme.Warning,
+}
+
+// onExportHTML asks for a file and exports the listing to it.
+func (i *Ite) onExportHTML() {
+	name := "untitled"
+	if i.currentFile != "" {
+		name = filepath.Base(i.currentFile)
+	}
+	path := GetSaveFile(Title("Export as HTML..."), Initialdir(i.defaultDir()),
+		Initialfile(name+htmlExtension), Filetypes([]FileType{
+			{TypeName: "HTML Files", Extensions: []string{"*.html"}, MacType: ""},
+			{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
+		}))
+	if path == "" {
+		return
+	}
+	i.rememberDialogDir(path)
+	if filepath.Ext(path) == "" {
+		path += htmlExtension
+	}
+	if err := os.WriteFile(path, []byte(i.listingHTML(false)), configFilePerms); err != nil {
+		i.showError("Error exporting: " + err.Error())
+		return
+	}
+	i.showStatusHint("Exported " + filepath.Base(path))
+}
+
+// onPrint opens the listing in the browser, asking it to print the page.
+func (i *Ite) onPrint() {
+	path := filepath.Join(os.TempDir(), printFileName)
+	if err := os.WriteFile(path, []byte(i.listingHTML(true)), configFilePerms); err != nil {
+		i.showError("Error preparing the page: " + err.Error())
+		return
+	}
+	openBrowser("file://" + filepath.ToSlash(path))
+	i.showStatusHint("Opened the listing in the browser for printing")
+}
+
+// listingHTML returns the page listing the selected lines, or the whole
+// buffer if there is no selection, asking the browser to print it when
+// forPrint is set.
+func (i *Ite) listingHTML(forPrint bool) string {
+	first, last := 1, 0
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		first, _ = parseIndex(sel[0])
+		end := i.editText.Index(sel[len(sel)-1] + " -1c")
+		last, _ = parseIndex(end)
+	}
+	title := "Untitled"
+	if i.currentFile != "" {
+		title = i.displayPath(i.currentFile)
+	}
+	goSource := i.currentFile == "" || filepath.Ext(i.currentFile) == defaultFileExtension
+	return renderListing(title, i.editText.Text(), goSource, first, last, forPrint)
+}
+
+// renderListing returns the page titled title listing lines first to last
+// of src, all of them from first if last is 0, numbered and, when
+// goSource is set, colored by token.
+func renderListing(title, src string, goSource bool, first, last int, forPrint bool) string {
+	src = strings.TrimSuffix(src, "\n")
+	var body string
+	if goSource {
+		body = highlightGo(src)
+	} else {
+		body = html.EscapeString(src)
+	}
+	lines := strings.Split(body, "\n")
+	if last == 0 || last > len(lines) {
+		last = len(lines)
+	}
+	width := len(fmt.Sprint(last))
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	b.WriteString("</head>\n")
+	if forPrint {
+		b.WriteString("<body onload=\"window.print()\">\n")
+	} else {
+		b.WriteString("<body>\n")
+	}
+	fmt.Fprintf(&b, "<pre style=\"font-family: %s, monospace; font-size: 10pt; tab-size: 4; color: %s; background: %s; padding: 0.5em\">",
+		editorFontFamily, lightTheme.Foreground, lightTheme.Text)
+	for n := first; n <= last; n++ {
+		fmt.Fprintf(&b, "<span style=\"color: %s; user-select: none\">%*d  </span>%s\n",
+			lightTheme.Muted, width, n, lines[n-1])
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// highlightGo returns src escaped for HTML, its keywords, comments,
+// literals and numbers wrapped in colored spans. Spans never cross lines,
+// so the result can be split into lines.
+func highlightGo(src string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	var b strings.Builder
+	done := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		var class string
+		switch {
+		case tok == token.COMMENT:
+			class = "comment"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "string"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "number"
+		case tok.IsKeyword():
+			class = "keyword"
+		default:
+			continue
+		}
+		start := file.Offset(pos)
+		if tok.IsKeyword() {
+			lit = tok.String()
+		}
+		end := start + len(lit)
+		if start < done || end > len(src) {
+			continue
+		}
+		b.WriteString(html.EscapeString(src[done:start]))
+		for n, line := range strings.Split(src[start:end], "\n") {
+			if n > 0 {
+				b.WriteString("\n")
+			}
+			if line != "" {
+				fmt.Fprintf(&b, "<span style=\"color: %s\">%s</span>", htmlTokenColors[class], html.EscapeString(line))
+			}
+		}
+		done = end
+	}
+	b.WriteString(html.EscapeString(src[done:]))
+	return b.String()
+}
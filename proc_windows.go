@@ -0,0 +1,50 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a new process group, so that
+// killProcessGroup also reaches the processes it spawns.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// killProcessGroup kills cmd and its whole process tree.
+func killProcessGroup(cmd *exec.Cmd) error {
+	pid := strconv.Itoa(cmd.Process.Pid)
+	return exec.Command("taskkill", "/T", "/F", "/PID", pid).Run()
+}
+
+// terminateProcessGroup asks cmd and its whole process tree to exit.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	pid := strconv.Itoa(cmd.Process.Pid)
+	return exec.Command("taskkill", "/T", "/PID", pid).Run()
+}
+
+// stillActive is the exit code of a process still running.
+const stillActive = 259
+
+// processAlive reports whether the process pid is running.
+func processAlive(pid int) bool {
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
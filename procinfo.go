@@ -0,0 +1,129 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Process Inspector
+// -------------------------------------------------------------------------
+
+const inspectInterval = time.Second // Resource sampling period
+
+// procSample is a measurement of the resources used by a process group.
+type procSample struct {
+	time  time.Time
+	cpu   time.Duration // CPU time used so far
+	rss   uint64        // Resident memory in bytes
+	procs int           // Number of processes in the group
+}
+
+// processInspector holds the widgets of the process inspector window.
+type processInspector struct {
+	window *ToplevelWidget
+	status *TLabelWidget
+	last   procSample
+}
+
+// onProcessInspector opens a window showing the PID, start time, sampled
+// CPU and memory usage and launch environment of the running Go Run
+// command.
+func (i *Ite) onProcessInspector() {
+	c := i.console(consoleRun)
+	i.procMu.Lock()
+	var proc process
+	if j := c.active; j != nil {
+		proc = j.proc
+	}
+	i.procMu.Unlock()
+	if proc == nil {
+		i.showError("No process is running.")
+		return
+	}
+	if i.inspector != nil {
+		Destroy(i.inspector.window)
+	}
+	pid := proc.pid()
+
+	p := &processInspector{window: Toplevel()}
+	p.window.WmTitle(fmt.Sprintf("Process %d", pid))
+	info := fmt.Sprintf("Command: %s\nPID: %d\nStarted: %s",
+		strings.Join(proc.args(), " "), pid, proc.started().Format(time.DateTime))
+	Grid(p.window.TLabel(Txt(info), Justify("left")), Row(0), Column(0), Sticky(W), Padx(px(10)), Pady(px(5)))
+	p.status = p.window.TLabel(Txt("Sampling..."), Justify("left"))
+	Grid(p.status, Row(1), Column(0), Sticky(W), Padx(px(10)))
+
+	Grid(p.window.TLabel(Txt("Environment")), Row(2), Column(0), Sticky(W), Padx(px(10)), Pady(px(5)))
+	env := slices.Sorted(slices.Values(proc.environ()))
+	envText := p.window.Text(textStyle(), Width(80), Height(15), Wrap("none"))
+	envText.Insert("end", strings.Join(env, "\n"))
+	envText.Configure(State("disabled"))
+	Grid(envText, Row(3), Column(0), Sticky(NEWS), Padx(px(10)))
+	GridRowConfigure(p.window, 3, Weight(1))
+	GridColumnConfigure(p.window, 0, Weight(1))
+
+	stop := func(force bool) {
+		if err := i.signalCommand(c, force); err != nil {
+			i.showError("Error stopping process: " + err.Error())
+		}
+	}
+	closeWindow := func() {
+		Destroy(p.window)
+		i.inspector = nil
+	}
+	btnFrame := p.window.TFrame()
+	Grid(btnFrame, Row(4), Column(0), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Terminate (SIGTERM)"), Command(func() { stop(false) })), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Kill (SIGKILL)"), Command(func() { stop(true) })), Row(0), Column(1), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Close"), Command(closeWindow)), Row(0), Column(2), Padx(px(5)))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	Bind(p.window, "<Escape>", Command(closeWindow))
+
+	i.inspector = p
+	i.sampleProcess(c, p, pid)
+}
+
+// sampleProcess updates the resource usage shown by the inspector p of the
+// command of c every inspectInterval until the process exits or the
+// window is closed.
+func (i *Ite) sampleProcess(c *console, p *processInspector, pid int) {
+	if i.inspector != p {
+		return // Closed or replaced
+	}
+	i.procMu.Lock()
+	running := c.active != nil && c.active.proc != nil && c.active.proc.pid() == pid
+	i.procMu.Unlock()
+	if !running {
+		p.status.Configure(Txt("Process exited"))
+		return
+	}
+
+	s, err := sampleProcessGroup(pid)
+	switch {
+	case err != nil:
+		p.status.Configure(Txt(err.Error()))
+		return
+	case !p.last.time.IsZero():
+		cpu := float64(s.cpu-p.last.cpu) / float64(s.time.Sub(p.last.time)) * 100
+		p.status.Configure(Txt(fmt.Sprintf("CPU: %.1f%%   RSS: %.1f MiB   Processes: %d",
+			cpu, float64(s.rss)/(1<<20), s.procs)))
+	}
+	p.last = s
+	TclAfter(inspectInterval, func() { i.sampleProcess(c, p, pid) })
+}
+
+// onToggleGracefulStop switches the Stop button between SIGTERM and
+// SIGKILL and persists the choice.
+func (i *Ite) onToggleGracefulStop() {
+	i.config.GracefulStop = !i.config.GracefulStop
+	i.gracefulStopVar.Set(checkValue(i.config.GracefulStop))
+	i.saveConfig()
+}
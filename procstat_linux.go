@@ -0,0 +1,51 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const clockTicks = 100 // USER_HZ, the unit of CPU times in /proc
+
+// sampleProcessGroup adds up the CPU time and resident memory of the
+// processes in the group pgid, as reported by /proc.
+func sampleProcessGroup(pgid int) (procSample, error) {
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return procSample{}, err
+	}
+	s := procSample{time: time.Now()}
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // The process exited meanwhile
+		}
+		// The command name in parentheses may contain spaces
+		_, rest, ok := strings.Cut(string(data), ") ")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 22 {
+			continue
+		}
+		if pgrp, _ := strconv.Atoi(fields[2]); pgrp != pgid {
+			continue
+		}
+		utime, _ := strconv.ParseUint(fields[11], 10, 64)
+		stime, _ := strconv.ParseUint(fields[12], 10, 64)
+		rss, _ := strconv.ParseUint(fields[21], 10, 64)
+		s.cpu += time.Duration(utime+stime) * time.Second / clockTicks
+		s.rss += rss * uint64(os.Getpagesize())
+		s.procs++
+	}
+	return s, nil
+}
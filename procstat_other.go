@@ -0,0 +1,13 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build !linux
+
+package main
+
+import "errors"
+
+// sampleProcessGroup is only implemented on Linux.
+func sampleProcessGroup(pgid int) (procSample, error) {
+	return procSample{}, errors.New("resource sampling is only supported on Linux")
+}
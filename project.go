@@ -0,0 +1,328 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Projects
+// -------------------------------------------------------------------------
+
+// A project is the folder opened as project when it holds the current
+// file, otherwise the module of the current file: the closest parent with
+// a go.mod file or a project settings file. Build, run, Quick Open and the
+// searches all work from its root. The settings are in .ite/project.toml:
+//
+//	build = "./cmd/ite"              # Target of Go Build, ./... by default
+//	run = "./cmd/ite"                # Package of Go Run, the current file's by default
+//	run_args = "-v notes.txt"        # Arguments of Go Run without a run profile
+//	exclude = ["testdata", "*.pb.go"] # Left out of searches, as in .gitignore
+//
+//	[format]
+//	command = "gofumpt"              # Reads the source on stdin, gofmt by default
+//	args = ["-extra"]
+//	on_save = true                   # Format Go files when saving them
+//
+//	[tasks.generate]                 # Shell commands run from the Tasks menu, see tasks.go
+//	command = "go generate ./..."
+//	dir = "internal/api"             # Relative to the root, the root by default
+const (
+	projectFileName  = "project.toml" // Project settings inside projectConfigDir
+	defaultFormatter = "gofmt"
+)
+
+// projectTemplate is the content of a new project settings file.
+const projectTemplate = `# Settings of the project, see project.go of ITE.
+
+# build = "./..."
+# run = "."
+# run_args = ""
+# exclude = ["testdata"]
+
+[format]
+# command = "gofmt"
+# args = []
+# on_save = false
+
+# [tasks.generate]
+# command = "go generate ./..."
+# dir = ""
+`
+
+// projectSettings are the settings of a project.
+type projectSettings struct {
+	Build   string   // Target of Go Build, "./..." when empty
+	Run     string   // Package of Go Run, the current file's when empty
+	RunArgs string   // Arguments of Go Run without a run profile
+	Exclude []string // Patterns of .gitignore left out of searches
+	Format  formatSettings
+	Tasks   map[string]taskSettings // Shell commands, by name
+}
+
+// formatSettings choose the formatter of the Go files of a project.
+type formatSettings struct {
+	Command string   // Program reading the source on stdin, defaultFormatter when empty
+	Args    []string // Arguments of Command
+	OnSave  bool     // Format Go files when saving them
+}
+
+// projectDir returns the root of the current project: the folder opened
+// as project if it holds the current file, else the module of the current
+// file, else the working directory.
+func (i *Ite) projectDir() string {
+	folder := i.session.Project
+	if folder != "" && (i.currentFile == "" || relativeTo(folder, i.currentFile) != i.currentFile) {
+		return folder
+	}
+	if i.currentFile != "" {
+		return projectRoot(i.currentFile)
+	}
+	dir, _ := filepath.Abs(".")
+	return dir
+}
+
+// projectSettingsPath returns the settings file of the current project.
+func (i *Ite) projectSettingsPath() string {
+	return filepath.Join(i.projectDir(), projectConfigDir, projectFileName)
+}
+
+// loadProjectSettings reads the settings of the current project. A
+// missing file yields the defaults.
+func (i *Ite) loadProjectSettings() (projectSettings, error) {
+	return readProjectSettings(i.projectDir())
+}
+
+// readProjectSettings reads the settings of the project at root.
+func readProjectSettings(root string) (projectSettings, error) {
+	var s projectSettings
+	data, err := os.ReadFile(filepath.Join(root, projectConfigDir, projectFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	values, err := parseTOML(string(data))
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", projectFileName, err)
+	}
+	for key, v := range values {
+		if err := s.set(key, v); err != nil {
+			return s, fmt.Errorf("%s: %s: %w", projectFileName, key, err)
+		}
+	}
+	return s, nil
+}
+
+// set stores the value of key, "format.command" for the command of the
+// format table, "tasks.generate.command" for the command of the task
+// generate.
+func (s *projectSettings) set(key string, v any) error {
+	var ok bool
+	switch key {
+	case "build":
+		s.Build, ok = v.(string)
+	case "run":
+		s.Run, ok = v.(string)
+	case "run_args":
+		s.RunArgs, ok = v.(string)
+	case "exclude":
+		s.Exclude, ok = v.([]string)
+	case "format.command":
+		s.Format.Command, ok = v.(string)
+	case "format.args":
+		s.Format.Args, ok = v.([]string)
+	case "format.on_save":
+		s.Format.OnSave, ok = v.(bool)
+	default:
+		if task, found := strings.CutPrefix(key, "tasks."); found {
+			return s.setTask(task, v)
+		}
+		return errors.New("unknown setting")
+	}
+	if !ok {
+		return fmt.Errorf("unexpected value %v", v)
+	}
+	return nil
+}
+
+// projectSettingsOrError returns the settings of the current project,
+// showing the error reading them, if any, and falling back to the
+// defaults.
+func (i *Ite) projectSettingsOrError() projectSettings {
+	s, err := i.loadProjectSettings()
+	if err != nil {
+		i.showError("Error reading project settings: " + err.Error())
+		return projectSettings{}
+	}
+	return s
+}
+
+// onOpenProjectFolder makes a chosen folder the project of the files it
+// holds, even without a go.mod file.
+func (i *Ite) onOpenProjectFolder() {
+	dir := ChooseDirectory(Initialdir(i.projectDir()), Parent(App), Title("Open Folder as Project"))
+	if dir == "" {
+		return
+	}
+	i.setProjectFolder(dir)
+	i.showStatusHint("Project: " + dir)
+}
+
+// onCloseProjectFolder goes back to projects following the modules.
+func (i *Ite) onCloseProjectFolder() {
+	if i.session.Project == "" {
+		i.showStatusHint("No folder is open as project")
+		return
+	}
+	i.setProjectFolder("")
+	i.showStatusHint("Project: " + i.projectDir())
+}
+
+// setProjectFolder sets the folder opened as project, "" for none, and
+// remembers it across runs.
+func (i *Ite) setProjectFolder(dir string) {
+	i.session.Project = dir
+	if err := i.session.save(); err != nil {
+		i.showError("Error saving session: " + err.Error())
+	}
+}
+
+// onEditProjectSettings opens the settings file of the current project,
+// creating it from a template first if needed.
+func (i *Ite) onEditProjectSettings() {
+	path := i.projectSettingsPath()
+	if !fileExists(path) {
+		if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+			i.showError("Error creating project settings: " + err.Error())
+			return
+		}
+		if err := os.WriteFile(path, []byte(projectTemplate), configFilePerms); err != nil {
+			i.showError("Error creating project settings: " + err.Error())
+			return
+		}
+	}
+	i.showLocation(location{path: path, line: 1, col: 1})
+}
+
+// buildTarget returns the packages built by Go Build.
+func (s projectSettings) buildTarget() string {
+	if s.Build == "" {
+		return "./..."
+	}
+	return s.Build
+}
+
+// runTarget returns the package run by Go Run, relative to the project
+// root: the configured one, or the package of the current file.
+func (i *Ite) runTarget(s projectSettings) string {
+	if s.Run != "" {
+		return s.Run
+	}
+	return i.currentPackage()
+}
+
+// currentPackage returns the package of the current file as the go
+// command takes it from the project root, e.g. "./cmd/ite".
+func (i *Ite) currentPackage() string {
+	rel, err := filepath.Rel(i.projectDir(), filepath.Dir(i.currentFile))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Dir(i.currentFile)
+	}
+	if rel == "." {
+		return rel
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+// -------------------------------------------------------------------------
+// Formatting
+// -------------------------------------------------------------------------
+
+// onFormatFile formats the buffer with the formatter of the project.
+func (i *Ite) onFormatFile() {
+	if err := i.formatBuffer(i.projectSettingsOrError()); err != nil {
+		i.showError("Format: " + err.Error())
+	}
+}
+
+// formatOnSave formats a Go buffer before it is saved, when the project
+// asks for it. A failing formatter leaves the buffer alone and only shows
+// a hint, so that broken code can still be saved.
+func (i *Ite) formatOnSave() {
+	if filepath.Ext(i.currentFile) != defaultFileExtension || i.largeFile {
+		return
+	}
+	s, err := i.loadProjectSettings()
+	if err != nil || !s.Format.OnSave {
+		return
+	}
+	if err := i.formatBuffer(s); err != nil {
+		i.showStatusHint("Not formatted: " + err.Error())
+	}
+}
+
+// formatBuffer pipes the buffer through the formatter of s and replaces
+// it with the result, as one undo step, keeping the cursor line.
+func (i *Ite) formatBuffer(s projectSettings) error {
+	if i.loading() {
+		return errStillLoading
+	}
+	src := i.editText.Text()
+	out, err := runFormatter(s, i.projectDir(), src)
+	if err != nil {
+		return err
+	}
+	if out == src {
+		return nil
+	}
+	if i.blockProtected("1.0", "end") {
+		return nil
+	}
+	insert := i.editText.Index("insert")
+	top, _, _ := strings.Cut(i.editText.Yview(), " ")
+	i.editGroup(func() {
+		i.editText.Delete("1.0", "end")
+		i.editText.Insert("1.0", out)
+	})
+	i.editText.MarkSet("insert", insert)
+	i.editText.Yviewmoveto(top)
+	i.refreshCursorState()
+	i.updateGutter()
+	i.scheduleOutline()
+	return nil
+}
+
+// runFormatter returns src formatted by the formatter of s, run in dir.
+// The error is the first line the formatter wrote to standard error, if
+// any.
+func runFormatter(s projectSettings, dir, src string) (string, error) {
+	name := s.Format.Command
+	if name == "" {
+		name = defaultFormatter
+	}
+	cmd := exec.Command(name, s.Format.Args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(src)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Prose Line-Length Budget
+// -------------------------------------------------------------------------
+
+const (
+	commitMsgColumn = 72 // Soft wrap column for commit messages
+	commentColumn   = 80 // Soft wrap column for comments in source files
+	proseTabWidth   = 4  // Columns counted for a tab when measuring prose
+	textInset       = 3  // Pixels between the text widget edge and column 0
+
+	tagOverflow = "overflow" // Text tag marking prose past its budget
+)
+
+// commitMessageFiles lists the file names git hands to $EDITOR for prose.
+var commitMessageFiles = map[string]bool{
+	"COMMIT_EDITMSG": true,
+	"MERGE_MSG":      true,
+	"SQUASH_MSG":     true,
+	"TAG_EDITMSG":    true,
+}
+
+// isCommitMessage reports whether path is a git message file.
+func isCommitMessage(path string) bool {
+	return commitMessageFiles[filepath.Base(path)]
+}
+
+// proseBudgets returns, for every line of src, the column budget that applies
+// to it, or 0 when the line is code and has no budget. In commit messages
+// every non-comment line is prose; elsewhere only comment lines are.
+func proseBudgets(path, src string) []int {
+	lines := strings.Split(src, "\n")
+	budgets := make([]int, len(lines))
+	if isCommitMessage(path) {
+		for n, line := range lines {
+			if !strings.HasPrefix(line, "#") {
+				budgets[n] = commitMsgColumn
+			}
+		}
+		return budgets
+	}
+
+	inBlock := false
+	for n, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			budgets[n] = commentColumn
+			if strings.Contains(trimmed, "*/") {
+				inBlock = false
+			}
+		case strings.HasPrefix(trimmed, "//"):
+			budgets[n] = commentColumn
+		case strings.HasPrefix(trimmed, "/*"):
+			budgets[n] = commentColumn
+			inBlock = !strings.Contains(trimmed[2:], "*/")
+		}
+	}
+	return budgets
+}
+
+// overflowOffset returns the character offset in line at which its display
+// width first exceeds limit columns, or -1 when the line fits.
+func overflowOffset(line string, limit int) int {
+	col := 0
+	for n, r := range []rune(line) {
+		if r == '\t' {
+			col += proseTabWidth - col%proseTabWidth
+		} else {
+			col++
+		}
+		if col > limit {
+			return n
+		}
+	}
+	return -1
+}
+
+// makeProseGuide creates the thin vertical line drawn at the prose budget
+// column. It stays hidden until the cursor enters a prose line.
+func (i *Ite) makeProseGuide() {
+	i.proseGuide = i.editText.Frame(Background(theme.Guide))
+	if i.editorFont == nil {
+		i.editorFont = NewFont(Family(editorFontFamily), Size(fontSize))
+	}
+}
+
+// updateProseGuide highlights prose that runs past its budget and shows the
+// column guide while the cursor sits on a prose line.
+func (i *Ite) updateProseGuide() {
+	i.editText.TagRemove(tagOverflow, "1.0", "end")
+	if i.largeFile {
+		Place(i.proseGuide, Width(0))
+		return
+	}
+	lines := strings.Split(i.editText.Text(), "\n")
+	budgets := proseBudgets(i.currentFile, strings.Join(lines, "\n"))
+	for n, limit := range budgets {
+		if limit == 0 {
+			continue
+		}
+		if col := overflowOffset(lines[n], limit); col >= 0 {
+			i.editText.TagAdd(tagOverflow,
+				fmt.Sprintf("%d.%d", n+1, col),
+				fmt.Sprintf("%d.end", n+1))
+		}
+	}
+
+	var line int
+	fmt.Sscanf(i.editText.Index("insert"), "%d", &line)
+	limit := 0
+	if line >= 1 && line <= len(budgets) {
+		limit = budgets[line-1]
+	}
+	if limit == 0 {
+		Place(i.proseGuide, Width(0))
+		return
+	}
+	x := textInset + i.editorFont.Measure(i.editText.Window, strings.Repeat("0", limit))
+	Place(i.proseGuide, X(x), Y(0), Width(1), Relheight(1))
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Completion Providers
+// -------------------------------------------------------------------------
+
+// Complete (Ctrl+Space) asks the completion providers for what may be
+// typed at the cursor and lists their answers like the path completions.
+// Providers are plugins sending a provider request, a local model or a
+// fuzzy engine of their own. As completing hands them the whole buffer,
+// a provider gets nothing until enabled in the Plugins menu, a choice
+// kept in the settings, and the status bar names the providers enabled
+// while they run. Nothing is ever sent but on Complete.
+
+// completionProvider completes the text at the cursor from outside the
+// editor.
+type completionProvider interface {
+	// providerLabel names the provider to the user.
+	providerLabel() string
+	// requestCompletions asks for the completions at cursor, a Tk index,
+	// of text, the buffer of path. The answer, if any, comes back tagged
+	// with id to showProviderCompletions.
+	requestCompletions(id, path, text, cursor string)
+}
+
+// completionRequest is the latest completion asked of the providers.
+type completionRequest struct {
+	seq    int    // Bumped by every request, dropping the answers to older ones
+	cursor string // Index the completions are for
+}
+
+// providerLabel returns the label the plugin offered its completions
+// under.
+func (p *plugin) providerLabel() string { return p.offers }
+
+// requestCompletions sends the complete event to the plugin.
+func (p *plugin) requestCompletions(id, path, text, cursor string) {
+	p.send(pluginMessage{Event: "complete", ID: id, Path: path, Text: text, Cursor: cursor})
+}
+
+// completionProviders returns the providers enabled and running.
+func (i *Ite) completionProviders() []completionProvider {
+	var providers []completionProvider
+	for _, p := range i.plugins {
+		if p.offers != "" && slices.Contains(i.config.CompletionPlugins, p.name) {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
+
+// registerProvider makes plugin p a completion provider labeled label,
+// adding the menu entry enabling it.
+func (i *Ite) registerProvider(p *plugin, label string) {
+	if p.offers != "" {
+		return
+	}
+	if label == "" {
+		label = p.name
+	}
+	p.offers = label
+	p.allowed = Variable(checkValue(slices.Contains(i.config.CompletionPlugins, p.name)))
+	cmdID := pluginCommandPrefix + p.name + ".completions"
+	i.commands.add(command{id: cmdID, title: "Plugin: Toggle Completions from " + label,
+		run: func() { i.toggleProvider(p) }})
+	menu := i.ensurePluginsMenu()
+	menu.AddSeparator()
+	i.addMenuCheck(menu, cmdID, tr("Completions from")+" "+label, p.allowed)
+	if p.allowed.Get() != "1" {
+		i.pluginLog(p.name+" offers completions, off until enabled in the Plugins menu\n", "")
+	}
+	i.updateProviderStatus()
+}
+
+// toggleProvider enables or disables the completions of p and persists
+// the choice.
+func (i *Ite) toggleProvider(p *plugin) {
+	if n := slices.Index(i.config.CompletionPlugins, p.name); n >= 0 {
+		i.config.CompletionPlugins = slices.Delete(i.config.CompletionPlugins, n, n+1)
+	} else {
+		i.config.CompletionPlugins = append(i.config.CompletionPlugins, p.name)
+	}
+	p.allowed.Set(checkValue(slices.Contains(i.config.CompletionPlugins, p.name)))
+	i.saveConfig()
+	i.updateProviderStatus()
+}
+
+// updateProviderStatus names the providers enabled in the status bar.
+func (i *Ite) updateProviderStatus() {
+	if i.statusProvider == nil {
+		return
+	}
+	var labels []string
+	for _, p := range i.completionProviders() {
+		labels = append(labels, p.providerLabel())
+	}
+	text := ""
+	if len(labels) > 0 {
+		text = tr("Completions:") + " " + strings.Join(labels, ", ")
+	}
+	i.statusProvider.Configure(Txt(text))
+}
+
+// onComplete asks the providers enabled for the completions at the
+// cursor.
+func (i *Ite) onComplete() {
+	providers := i.completionProviders()
+	if len(providers) == 0 {
+		i.showStatusHint("No completion provider enabled: see the Plugins menu")
+		return
+	}
+	i.hideCompletion()
+	i.completing.seq++
+	i.completing.cursor = i.editText.Index("insert")
+	id, text := strconv.Itoa(i.completing.seq), i.editText.Text()
+	for _, p := range providers {
+		p.requestCompletions(id, i.currentFile, text, i.completing.cursor)
+	}
+}
+
+// showProviderCompletions lists the completions req of plugin p, with
+// those the other providers gave, if they answer the latest request and
+// the cursor hasn't moved since. They replace the text from the start of
+// req to the cursor, none by default.
+func (i *Ite) showProviderCompletions(p *plugin, req pluginMessage) {
+	insert := i.editText.Index("insert")
+	if !slices.Contains(i.completionProviders(), completionProvider(p)) ||
+		req.ID != strconv.Itoa(i.completing.seq) || insert != i.completing.cursor || len(req.Items) == 0 {
+		return
+	}
+	start := insert
+	if req.Start != "" {
+		start = i.editText.Index(req.Start)
+		if indexLess(i.editText, insert, start) || indexLess(i.editText, start, "insert linestart") {
+			start = insert
+		}
+	}
+	var items []string
+	if i.completion.window != nil && i.completion.plugin {
+		items = slices.Clone(i.completion.items)
+	}
+	for _, item := range req.Items {
+		if item != "" && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	i.completion.start, i.completion.plugin = start, true
+	i.showCompletion(items)
+}
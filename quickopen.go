@@ -0,0 +1,243 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Quick Open
+// -------------------------------------------------------------------------
+
+const (
+	maxQuickOpenRows = 200 // Matches listed
+)
+
+// fileIndex is the list of the files of a project, built in the
+// background. The list of the previous walk is used while a new one runs.
+type fileIndex struct {
+	root     string
+	files    []string // Slash separated paths relative to root
+	indexing bool
+}
+
+// quickOpenPanel holds the widgets of the Quick Open window.
+type quickOpenPanel struct {
+	window *ToplevelWidget
+	entry  *TEntryWidget
+	list   *ListboxWidget
+	status *TLabelWidget
+	shown  []string // Paths listed, relative to the root
+}
+
+// fuzzyMatch is a file matching the query of Quick Open.
+type fuzzyMatch struct {
+	path  string
+	score int
+}
+
+// onQuickOpen opens the Quick Open window: typing filters the files of
+// the project by the characters they contain in order, best matches
+// first, and Return or a double-click opens the selected one.
+func (i *Ite) onQuickOpen() {
+	if i.quickOpen != nil {
+		Destroy(i.quickOpen.window)
+	}
+	root := i.projectDir()
+	i.indexFiles(root)
+
+	p := &quickOpenPanel{window: Toplevel()}
+	i.quickOpen = p
+	p.window.WmTitle("Quick Open - " + root)
+	WmTransient(p.window, App)
+	frame := p.window.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	p.entry = frame.TEntry(Width(60), Textvariable(""))
+	Grid(p.entry, Row(0), Column(0), Sticky(WE), Pady(px(5)))
+	p.list = frame.Listbox(Width(60), Height(15), Background(theme.Text))
+	Grid(p.list, Row(1), Column(0), Sticky(NEWS))
+	p.status = frame.TLabel(Foreground(theme.Muted))
+	Grid(p.status, Row(2), Column(0), Sticky(W))
+	Focus(p.entry)
+
+	closeWindow := func() {
+		Destroy(p.window)
+		i.quickOpen = nil
+		Focus(i.editText)
+	}
+	open := func() {
+		sel := p.list.Curselection()
+		if len(sel) == 0 || sel[0] >= len(p.shown) {
+			return
+		}
+		path := filepath.Join(root, filepath.FromSlash(p.shown[sel[0]]))
+		closeWindow()
+		if !i.promptSaveIfModified() {
+			return
+		}
+		if err := i.openFile(path); err != nil {
+			i.showError("Error opening file: " + err.Error())
+		}
+	}
+	move := func(delta int) {
+		sel := p.list.Curselection()
+		if len(sel) == 0 || len(p.shown) == 0 {
+			return
+		}
+		n := min(max(sel[0]+delta, 0), len(p.shown)-1)
+		p.list.SelectionClear(0, "end")
+		p.list.SelectionSet(n)
+		p.list.See(n)
+	}
+	i.filterQuickOpen()
+
+	Bind(p.entry, "<KeyRelease>", Command(func(e *Event) {
+		switch e.Keysym {
+		case "Up", "Down", "Return", "Escape":
+		default:
+			i.filterQuickOpen()
+		}
+	}))
+	Bind(p.entry, "<Up>", Command(func() { move(-1) }))
+	Bind(p.entry, "<Down>", Command(func() { move(1) }))
+	Bind(p.entry, "<Return>", Command(open))
+	Bind(p.list, "<Double-Button-1>", Command(open))
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+}
+
+// indexFiles lists the files below root in the background, skipping what
+// .gitignore excludes, and refreshes the Quick Open window once done.
+func (i *Ite) indexFiles(root string) {
+	if i.files.indexing && i.files.root == root {
+		return
+	}
+	if i.files.root != root {
+		i.files = fileIndex{root: root}
+	}
+	i.files.indexing = true
+	limit := i.indexLimit()
+	exclude := i.projectSettingsOrError().Exclude
+	i.goBackground(func(ctx context.Context) {
+		var files []string
+		walkProject(root, exclude, func(path string) error {
+			rel, err := filepath.Rel(root, path)
+			if err == nil {
+				files = append(files, filepath.ToSlash(rel))
+			}
+			if len(files) >= limit || ctx.Err() != nil {
+				return filepath.SkipAll
+			}
+			return nil
+		})
+		i.Dispatch(func() {
+			if i.files.root != root {
+				return // Another project was indexed since
+			}
+			i.files.files, i.files.indexing = files, false
+			if i.quickOpen != nil {
+				i.filterQuickOpen()
+			}
+		})
+	})
+}
+
+// filterQuickOpen lists the files matching the query of the Quick Open
+// window, keeping the selection on the first.
+func (i *Ite) filterQuickOpen() {
+	p := i.quickOpen
+	matches := fuzzyFilter(strings.Join(strings.Fields(p.entry.Textvariable()), ""), i.files.files)
+	p.shown = p.shown[:0]
+	p.list.Delete(0, "end")
+	for _, m := range matches[:min(len(matches), maxQuickOpenRows)] {
+		p.shown = append(p.shown, m.path)
+		p.list.Insert("end", m.path)
+	}
+	if len(p.shown) > 0 {
+		p.list.SelectionSet(0)
+	}
+	status := fmt.Sprintf("%d of %d files", len(matches), len(i.files.files))
+	if i.files.indexing {
+		status += ", indexing..."
+	}
+	p.status.Configure(Txt(status))
+}
+
+// fuzzyFilter returns the files matching query, best first. An empty
+// query matches every file.
+func fuzzyFilter(query string, files []string) []fuzzyMatch {
+	var matches []fuzzyMatch
+	for _, f := range files {
+		if score, ok := fuzzyScore(query, f); ok {
+			matches = append(matches, fuzzyMatch{f, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b fuzzyMatch) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.path), len(b.path)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	return matches
+}
+
+// fuzzyScore reports whether the characters of query appear in path in
+// order, ignoring case, and scores the match: characters matched in a row,
+// at the start of a word and in the file name score higher, characters
+// skipped lower.
+func fuzzyScore(query, path string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	base := strings.LastIndexByte(path, '/') + 1
+	// Matching inside the file name is tried first, as it usually names
+	// what is looked for
+	if score, ok := subsequenceScore(query, path[base:]); ok {
+		return score + 2*len(query), true
+	}
+	return subsequenceScore(query, path)
+}
+
+// subsequenceScore matches the runes of query against s from left to
+// right, scoring the match as fuzzyScore describes.
+func subsequenceScore(query, s string) (int, bool) {
+	score := 0
+	prev := utf8.RuneError // Rune of s before the current one
+	run := 0               // Runes matched in a row
+	q := []rune(query)
+	n := 0
+	for _, r := range s {
+		if n < len(q) && unicode.ToLower(r) == unicode.ToLower(q[n]) {
+			score++
+			if run > 0 {
+				score += 2 * run
+			}
+			if prev == utf8.RuneError || strings.ContainsRune("/_-. ", prev) || (unicode.IsUpper(r) && unicode.IsLower(prev)) {
+				score += 3
+			}
+			run++
+			n++
+		} else {
+			if n > 0 && n < len(q) {
+				score-- // A gap inside the match
+			}
+			run = 0
+		}
+		prev = r
+	}
+	return score, n == len(q)
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, path string
+		ok          bool
+	}{
+		{"", "any/file.go", true},
+		{"main", "main.go", true},
+		{"mgo", "main.go", true},
+		{"MAIN", "cmd/main.go", true},
+		{"ogm", "main.go", false},
+		{"mainx", "main.go", false},
+		{"é", "café.go", true},
+	}
+	for _, tt := range tests {
+		if _, ok := fuzzyScore(tt.query, tt.path); ok != tt.ok {
+			t.Errorf("fuzzyScore(%q, %q) matches = %v, want %v", tt.query, tt.path, ok, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	files := []string{"internal/tomato/main.go", "toml.go", "cmd/tool/main.go", "docs/README.md"}
+	var got []string
+	for _, m := range fuzzyFilter("toml", files) {
+		got = append(got, m.path)
+	}
+	// The file name matching in a row comes first, the scattered matches
+	// after it
+	if len(got) == 0 || got[0] != "toml.go" || slices.Contains(got, "docs/README.md") {
+		t.Errorf("fuzzyFilter(%q) = %q", "toml", got)
+	}
+	if got := fuzzyFilter("", files); len(got) != len(files) {
+		t.Errorf("fuzzyFilter(\"\") returned %d files, want %d", len(got), len(files))
+	}
+}
+
+func TestSwitcherMatches(t *testing.T) {
+	root := filepath.FromSlash("/src/ite")
+	recent := []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "cmd", "tool", "main.go"),
+		filepath.Join(root, "toml.go"),
+	}
+	// No query keeps the latest first
+	if got := switcherMatches(recent, root, ""); !slices.Equal(got, recent) {
+		t.Errorf("switcherMatches(%q) = %q, want %q", "", got, recent)
+	}
+	// Equal matches keep their recency, better ones come first
+	want := []string{recent[0], recent[1]}
+	if got := switcherMatches(recent, root, "main"); !slices.Equal(got, want) {
+		t.Errorf("switcherMatches(%q) = %q, want %q", "main", got, want)
+	}
+	want = []string{recent[2]}
+	if got := switcherMatches(recent, root, "toml"); !slices.Equal(got, want) {
+		t.Errorf("switcherMatches(%q) = %q, want %q", "toml", got, want)
+	}
+}
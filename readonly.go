@@ -0,0 +1,230 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Read-only Regions
+// -------------------------------------------------------------------------
+
+const (
+	tagReadOnly     = "readonly"      // Editor tag marking protected text
+	readOnlyBindTag = "IteReadOnly"   // Bind tag vetting edits before the editor
+	statusHintTime  = 3 * time.Second // How long a status hint stays visible
+	readOnlyHint    = "Read-only region"
+)
+
+// generatedRe matches the banner of generated Go files
+// (see https://go.dev/s/generatedcode).
+var generatedRe = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// bindReadOnly installs the checks that keep edits out of protected
+// regions. The bind tag goes first so a blocked key reaches nothing else.
+func (i *Ite) bindReadOnly() {
+	addBindtag(i.editText.Window, readOnlyBindTag, "")
+
+	Bind(readOnlyBindTag, "<KeyPress>", Command(func(e *Event) {
+		if e.State&ModifierControl != 0 {
+			return
+		}
+		kind, ok := classifyKey(e.Keysym, i.isWordChar)
+		if !ok || kind == editNone {
+			return
+		}
+		from, to := i.editRange(e.Keysym)
+		if i.blockProtected(from, to) {
+			e.SetReturnCodeBreak()
+		}
+	}))
+	for _, event := range []string{"<<Cut>>", "<<Paste>>", "<<Clear>>"} {
+		Bind(readOnlyBindTag, event, Command(func(e *Event) {
+			from, to := i.editRange("")
+			if i.blockProtected(from, to) {
+				e.SetReturnCodeBreak()
+			}
+		}))
+	}
+}
+
+// editRange returns the range of text a key would change: the selection
+// when the cursor is in it, otherwise the character deleted by BackSpace or
+// Delete, or the empty range at the cursor for an insertion.
+func (i *Ite) editRange(keysym string) (from, to string) {
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		first, last := sel[0], sel[len(sel)-1]
+		if !indexLess(i.editText, "insert", first) && !indexLess(i.editText, last, "insert") {
+			return first, last
+		}
+	}
+	insert := i.editText.Index("insert")
+	switch keysym {
+	case "BackSpace":
+		return i.editText.Index("insert -1c"), insert
+	case "Delete":
+		return insert, i.editText.Index("insert +1c")
+	}
+	return insert, insert
+}
+
+// isProtected reports whether changing the text from..to touches a
+// read-only region, or the buffer is read-only. An empty range is
+// protected when it lies strictly inside a region, so text can still be
+// added right before or after one.
+func (i *Ite) isProtected(from, to string) bool {
+	if i.readOnly {
+		return true
+	}
+	regions := i.editText.TagRanges(tagReadOnly)
+	for n := 0; n+1 < len(regions); n += 2 {
+		start, end := regions[n], regions[n+1]
+		if from == to {
+			if indexLess(i.editText, start, from) && indexLess(i.editText, from, end) {
+				return true
+			}
+			continue
+		}
+		if indexLess(i.editText, from, end) && indexLess(i.editText, start, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockProtected reports whether an edit of from..to must be refused and,
+// if so, tells the user why.
+func (i *Ite) blockProtected(from, to string) bool {
+	if !i.isProtected(from, to) {
+		return false
+	}
+	if i.readOnly {
+		i.showStatusHint(readOnlyFileHint)
+	} else {
+		i.showStatusHint(readOnlyHint)
+	}
+	return true
+}
+
+// blockProtectedSelection is blockProtected for the toolbar actions that
+// replace or remove the selection.
+func (i *Ite) blockProtectedSelection() bool {
+	from, to := i.editRange("")
+	return i.blockProtected(from, to)
+}
+
+// protectHeader marks the license header and the generated code banner of
+// the buffer as read-only.
+func (i *Ite) protectHeader() {
+	last, _ := parseIndex(i.editText.Index("end-1c"))
+
+	// Leading comment block mentioning a copyright or license
+	end := 0
+	for end < last && strings.HasPrefix(lineText(i.editText, end+1), "//") {
+		end++
+	}
+	if end > 0 {
+		header := strings.ToLower(i.editText.Get("1.0", fmt.Sprintf("%d.end", end))[0])
+		if strings.Contains(header, "copyright") || strings.Contains(header, "license") {
+			i.editText.TagAdd(tagReadOnly, "1.0", fmt.Sprintf("%d.0", end+1))
+		}
+	}
+
+	for line := 1; line <= last; line++ {
+		text := lineText(i.editText, line)
+		if strings.HasPrefix(text, "package ") {
+			break // The banner must come before the package clause
+		}
+		if generatedRe.MatchString(text) {
+			i.editText.TagAdd(tagReadOnly, fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.0", line+1))
+		}
+	}
+}
+
+// onProtectSelection makes the selected text read-only.
+func (i *Ite) onProtectSelection() {
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		i.editText.TagAdd(tagReadOnly, sel[0], sel[len(sel)-1])
+	}
+}
+
+// onUnprotectSelection makes the selected text editable again.
+func (i *Ite) onUnprotectSelection() {
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		i.editText.TagRemove(tagReadOnly, sel[0], sel[len(sel)-1])
+	}
+}
+
+// showStatusHint displays a short message next to the cursor position in
+// the status bar for a few seconds.
+func (i *Ite) showStatusHint(msg string) {
+	i.statusHint = tr(msg)
+	i.statusHintUntil = time.Now().Add(statusHintTime)
+	i.updateCursorPosition()
+	TclAfter(statusHintTime, i.updateCursorPosition)
+}
+
+// -------------------------------------------------------------------------
+// Read-only Mode
+// -------------------------------------------------------------------------
+
+// A buffer in read-only mode refuses every edit, as if all its text were a
+// protected region. Files the user can't write open in that mode; Toggle
+// Read-only switches it for any buffer. Saving a read-only buffer offers
+// to save a writable copy elsewhere and go on editing that instead.
+const readOnlyFileHint = "Read-only file: toggle Read-only to edit it"
+
+// fileWritable reports whether the user may write path. A missing file
+// counts as writable: saving will create it.
+func fileWritable(path string) bool {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return !errors.Is(err, fs.ErrPermission)
+	}
+	f.Close()
+	return true
+}
+
+// setReadOnly puts the buffer in read-only mode or takes it out.
+func (i *Ite) setReadOnly(on bool) {
+	i.readOnly = on
+	i.readOnlyVar.Set(checkValue(on))
+	text := ""
+	if on {
+		text = "🔒 Read-only"
+	}
+	i.statusReadOnly.Configure(Txt(text))
+}
+
+// onToggleReadOnly switches the read-only mode of the buffer.
+func (i *Ite) onToggleReadOnly() {
+	i.setReadOnly(!i.readOnly)
+	if !i.readOnly && i.currentFile != "" && !fileWritable(i.currentFile) {
+		i.showStatusHint("You can't write " + filepath.Base(i.currentFile) + ": save it elsewhere to keep the changes")
+	}
+}
+
+// offerWritableCopy tells the user the buffer can't be saved in place and
+// offers to save it as a writable copy, which becomes the file edited.
+func (i *Ite) offerWritableCopy(reason string) {
+	answer := messageBox(
+		Icon("warning"),
+		Title("Read-only"),
+		Type("okcancel"),
+		Msg(reason),
+		Detail("Save a writable copy elsewhere and edit that instead?"))
+	if answer == "ok" {
+		i.onSaveAs()
+	}
+}
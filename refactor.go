@@ -0,0 +1,199 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Refactoring Edits
+// -------------------------------------------------------------------------
+
+// Rename Package and Structural Replace edit many files at once. Files
+// open in a pane with unsaved changes are edited in their buffer, where
+// the changes stay unsaved; the other files are rewritten on disk and the
+// buffers showing them reloaded. The preview lists both kinds apart, and
+// applying is all or nothing: every file is checked against the preview
+// before any is touched, and the files already rewritten are put back if
+// a later one fails.
+
+const tagEditHeading = "editheading" // Preview tag of the headings of the edits on disk and in buffers
+
+// modifiedBuffers returns the text of the buffers with unsaved changes,
+// by absolute path.
+func (i *Ite) modifiedBuffers() map[string]string {
+	buffers := make(map[string]string)
+	for _, pane := range i.panes {
+		path, text := pane.file, pane.text
+		if pane == i.active {
+			path, text = i.currentFile, i.editText
+		}
+		if path != "" && text.Modified() {
+			buffers[absPath(path)] = text.Get("1.0", "end-1c")[0]
+		}
+	}
+	return buffers
+}
+
+// countEdits returns the number of edits of files on disk and in buffers.
+func countEdits(files []structFile) (disk, buffer int) {
+	for _, f := range files {
+		if f.buffer {
+			buffer += len(f.matches)
+		} else {
+			disk += len(f.matches)
+		}
+	}
+	return disk, buffer
+}
+
+// insertEditPreview lists the edits of files in results, starting at
+// line: those on disk first, then those in buffers, each kind under a
+// heading when both are present. Each edit is shown as
+// "path:line: code → rewrite", without the rewrite if showAfter is false.
+// It returns the edits by results line.
+func insertEditPreview(results *TextWidget, root string, files []structFile, line int, showAfter bool) map[int]structMatch {
+	results.TagConfigure(tagEditHeading, Font(editorFontFamily, fontSize, "bold"))
+	edits := make(map[int]structMatch)
+	disk, buffer := countEdits(files)
+	for _, inBuffer := range []bool{false, true} {
+		if disk > 0 && buffer > 0 {
+			heading := fmt.Sprintf("On disk, %d edits:\n", disk)
+			if inBuffer {
+				heading = fmt.Sprintf("In unsaved buffers, %d edits, left unsaved:\n", buffer)
+			}
+			results.Insert("end", heading, tagEditHeading)
+			line++
+		}
+		for _, f := range files {
+			if f.buffer != inBuffer {
+				continue
+			}
+			for _, m := range f.matches {
+				results.Insert("end", fmt.Sprintf("%s:%d:", relativeTo(root, m.path), m.line), tagLink)
+				results.Insert("end", " ")
+				results.Insert("end", previewCode(m.before), tagStructOld)
+				if showAfter {
+					results.Insert("end", " → ")
+					results.Insert("end", previewCode(m.after), tagStructNew)
+				}
+				results.Insert("end", "\n")
+				edits[line] = m
+				line++
+			}
+		}
+	}
+	return edits
+}
+
+// checkFileEdits reports the first file of files changed since the
+// preview: a buffer edited or closed, or a file rewritten on disk.
+func (i *Ite) checkFileEdits(root string, files []structFile) error {
+	for _, f := range files {
+		var current string
+		if f.buffer {
+			pane := i.paneEditing(f.path)
+			if pane == nil {
+				return fmt.Errorf("%s was closed since the preview", relativeTo(root, f.path))
+			}
+			text := pane.text
+			if pane == i.active {
+				text = i.editText
+			}
+			current = text.Get("1.0", "end-1c")[0]
+		} else {
+			data, err := os.ReadFile(f.path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", relativeTo(root, f.path), err)
+			}
+			current = string(data)
+		}
+		if current != string(f.src) {
+			return fmt.Errorf("%s %w", relativeTo(root, f.path), errChangedSinceSearch)
+		}
+	}
+	return nil
+}
+
+// writeFileEdits rewrites the files of files not open in a buffer,
+// recording them in op. If one fails, those already rewritten get their
+// content back and the error is returned.
+func writeFileEdits(op *fileOpRecorder, root string, files []structFile) (edits int, err error) {
+	var written []structFile
+	for _, f := range files {
+		if f.buffer {
+			continue
+		}
+		op.modifying(f.path)
+		n, err := rewriteFile(f)
+		if err != nil {
+			restoreFiles(written)
+			return 0, fmt.Errorf("%s: %w", relativeTo(root, f.path), err)
+		}
+		written = append(written, f)
+		edits += n
+	}
+	return edits, nil
+}
+
+// restoreFiles writes back the content files had when previewed.
+func restoreFiles(files []structFile) {
+	for _, f := range files {
+		perm := os.FileMode(defaultFilePerms)
+		if info, err := os.Stat(f.path); err == nil {
+			perm = info.Mode().Perm()
+		}
+		writeFileAtomic(f.path, f.src, perm)
+	}
+}
+
+// applyBufferEdits applies the edits of the files of files open in a
+// buffer, each as one undo step, and returns the number applied.
+func (i *Ite) applyBufferEdits(files []structFile) int {
+	edits := 0
+	for _, f := range files {
+		if !f.buffer {
+			continue
+		}
+		if pane := i.paneEditing(f.path); pane != nil {
+			i.withPane(pane, func() {
+				n, _ := i.rewriteBuffer(f) // Checked by checkFileEdits
+				edits += n
+			})
+		}
+	}
+	return edits
+}
+
+// reloadEditedBuffers reloads the unmodified buffers of the files of
+// files rewritten on disk.
+func (i *Ite) reloadEditedBuffers(files []structFile) {
+	edited := make(map[string]bool)
+	for _, f := range files {
+		if !f.buffer {
+			edited[absPath(f.path)] = true
+		}
+	}
+	for _, pane := range i.panes {
+		i.withPane(pane, func() {
+			if i.currentFile != "" && !i.editText.Modified() && edited[absPath(i.currentFile)] {
+				i.reloadActiveBuffer()
+			}
+		})
+	}
+}
+
+// editSummary describes the edits applied, e.g. "5 edits in 3 files, 2
+// in unsaved buffers".
+func editSummary(files []structFile, disk, buffer int) string {
+	s := fmt.Sprintf("%d edits in %d files", disk+buffer, len(files))
+	if buffer > 0 {
+		s += fmt.Sprintf(", %d in unsaved buffers", buffer)
+	}
+	return s
+}
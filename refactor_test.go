@@ -0,0 +1,78 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanPackageRenameBuffers(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/m\n\ngo 1.21\n",
+		"a/a.go":  "package a\n\nfunc F() {}\n",
+		"main.go": "package main\n\nimport \"example.com/m/a\"\n\nfunc main() { a.F() }\n",
+	}
+	for name, text := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	main := filepath.Join(root, "main.go")
+	buffers := map[string]string{
+		main: "package main\n\nimport \"example.com/m/a\"\n\nfunc main() {\n\ta.F()\n\ta.F()\n}\n",
+	}
+	plan := planPackageRename(root, filepath.Join(root, "a"), "b", buffers)
+	if plan.err != nil {
+		t.Fatal(plan.err)
+	}
+	disk, buffer := countEdits(plan.files)
+	if disk != 1 || buffer != 3 {
+		t.Errorf("%d edits on disk and %d in buffers, want 1 and 3", disk, buffer)
+	}
+	for _, f := range plan.files {
+		if f.buffer != (f.path == main) {
+			t.Errorf("%s planned in a buffer: %v", f.path, f.buffer)
+		}
+	}
+}
+
+func TestWriteFileEditsRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.go"), filepath.Join(dir, "second.go")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte("x := 1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edit := structMatch{start: 5, end: 6, after: "2"}
+	files := []structFile{
+		{path: first, src: []byte("x := 1\n"), matches: []structMatch{edit}},
+		{path: second, src: []byte("x := 3\n"), matches: []structMatch{edit}}, // Changed since
+		{path: filepath.Join(dir, "open.go"), buffer: true},
+	}
+	if _, err := writeFileEdits(&fileOpRecorder{}, dir, files); err == nil {
+		t.Fatal("no error for a file changed since the preview")
+	}
+	if data, _ := os.ReadFile(first); string(data) != "x := 1\n" {
+		t.Errorf("first.go = %q, want it restored", data)
+	}
+
+	files[1].src = []byte("x := 1\n")
+	n, err := writeFileEdits(&fileOpRecorder{}, dir, files)
+	if err != nil || n != 2 {
+		t.Fatalf("writeFileEdits = %d, %v, want 2 edits", n, err)
+	}
+	for _, path := range []string{first, second} {
+		if data, _ := os.ReadFile(path); string(data) != "x := 2\n" {
+			t.Errorf("%s = %q, want the edit", filepath.Base(path), data)
+		}
+	}
+}
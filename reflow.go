@@ -0,0 +1,216 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// -------------------------------------------------------------------------
+// Comment Reflow
+// -------------------------------------------------------------------------
+
+const (
+	minReflowColumn = 20
+	maxReflowColumn = 200
+)
+
+// listItemRe matches the marker of a list item: "-", "*", "+", "1." or "1)".
+var listItemRe = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+
+// directiveRe matches the text of a //go:build, //nolint: or similar
+// directive, which must stay on its own line.
+var directiveRe = regexp.MustCompile(`^[a-z0-9]+:\S`)
+
+// reflowColumn returns the width that Reflow Comment fills lines to.
+func (i *Ite) reflowColumn() int {
+	if isCommitMessage(i.currentFile) {
+		return commitMsgColumn
+	}
+	if c := i.config.ReflowColumn; c >= minReflowColumn && c <= maxReflowColumn {
+		return c
+	}
+	return commentColumn
+}
+
+// onReflowComment refills the selected lines, or the comment block around
+// the cursor, so that each line is as long as possible without passing
+// the reflow column. The "// " prefix and indentation are kept, and blank
+// comment lines, list items, indented code and directives still break
+// the text where they did. Lines of plain text, such as the contents of
+// a raw string or a commit message, are refilled the same way.
+func (i *Ite) onReflowComment() {
+	var first, last int
+	if len(i.editText.TagRanges("sel")) >= 2 {
+		first, last = i.selectedLines()
+	} else {
+		first, last = i.commentBlock()
+		if first == 0 {
+			return
+		}
+	}
+	from, to := fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.end", last)
+	if i.blockProtected(from, to) {
+		return
+	}
+	old := i.editText.Get(from, to)[0]
+	text := strings.Join(reflowLines(strings.Split(old, "\n"), i.reflowColumn()), "\n")
+	if text == old {
+		return
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, text)
+	})
+	i.editText.MarkSet("insert", fmt.Sprintf("%d.0 lineend", first+strings.Count(text, "\n")))
+	i.refreshCursorState()
+}
+
+// commentBlock returns the run of lines around the cursor with the same
+// prefix as the cursor line: a whole // comment, or for plain text the
+// paragraph of non-blank lines. It returns 0, 0 on a blank line.
+func (i *Ite) commentBlock() (first, last int) {
+	line, _ := parseIndex(i.editText.Index("insert"))
+	end, _ := parseIndex(i.editText.Index("end-1c"))
+	key, blank := reflowKey(lineText(i.editText, line))
+	if blank {
+		return 0, 0
+	}
+	same := func(n int) bool {
+		k, blank := reflowKey(lineText(i.editText, n))
+		return k == key && (!blank || strings.HasSuffix(key, "//"))
+	}
+	first, last = line, line
+	for first > 1 && same(first-1) {
+		first--
+	}
+	for last < end && same(last+1) {
+		last++
+	}
+	return first, last
+}
+
+// reflowKey returns the part of line that the lines of a block share: the
+// indentation and "//" of a comment, or just the indentation. blank
+// reports whether the line holds nothing else.
+func reflowKey(line string) (key string, blank bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	if rest, ok := strings.CutPrefix(trimmed, "//"); ok {
+		return indent + "//", strings.TrimSpace(rest) == ""
+	}
+	return indent, trimmed == ""
+}
+
+// reflowLines refills lines to width columns. Runs of lines sharing a
+// prefix are refilled separately. When some lines are comments, the others
+// are code and stay as they are.
+func reflowLines(lines []string, width int) []string {
+	hasComment := false
+	for _, line := range lines {
+		if key, _ := reflowKey(line); strings.HasSuffix(key, "//") {
+			hasComment = true
+		}
+	}
+	var out []string
+	for start := 0; start < len(lines); {
+		key, _ := reflowKey(lines[start])
+		end := start + 1
+		for end < len(lines) {
+			if k, _ := reflowKey(lines[end]); k != key {
+				break
+			}
+			end++
+		}
+		if hasComment && !strings.HasSuffix(key, "//") {
+			out = append(out, lines[start:end]...)
+		} else {
+			out = append(out, reflowBlock(lines[start:end], key, width)...)
+		}
+		start = end
+	}
+	return out
+}
+
+// reflowBlock refills lines starting with key, one paragraph at a time.
+func reflowBlock(lines []string, key string, width int) []string {
+	comment := strings.HasSuffix(key, "//")
+	prefix := key
+	if comment {
+		prefix += " "
+	}
+
+	var out, words []string
+	var hanging string // Indentation of the lines after the first of a list item
+	flush := func() {
+		if len(words) > 0 {
+			out = append(out, fillWords(words, prefix, prefix+hanging, width)...)
+		}
+		words, hanging = nil, ""
+	}
+	for _, line := range lines {
+		body := strings.TrimPrefix(line, key)
+		if comment {
+			body = strings.TrimPrefix(body, " ")
+		}
+		switch {
+		case strings.TrimSpace(body) == "":
+			flush()
+			out = append(out, strings.TrimRight(line, " \t"))
+		case hanging != "" && strings.HasPrefix(body, hanging) && !strings.HasPrefix(body[len(hanging):], " "):
+			words = append(words, strings.Fields(body)...) // List item continued
+		case comment && (directiveRe.MatchString(strings.TrimPrefix(line, key)) ||
+			strings.HasPrefix(body, " ") || strings.HasPrefix(body, "\t")):
+			// Directives and the indented code of doc comments
+			flush()
+			out = append(out, line)
+		case listItemRe.MatchString(body):
+			flush()
+			hanging = strings.Repeat(" ", len(listItemRe.FindString(body)))
+			words = strings.Fields(body)
+		default:
+			words = append(words, strings.Fields(body)...)
+		}
+	}
+	flush()
+	return out
+}
+
+// fillWords lays out words in lines of at most width columns, the first
+// starting with prefix and the following ones with cont. A word longer
+// than a line gets a line of its own.
+func fillWords(words []string, prefix, cont string, width int) []string {
+	var lines []string
+	line := prefix
+	empty := true
+	for _, word := range words {
+		switch {
+		case empty:
+			line += word
+		case textColumns(line)+1+textColumns(word) > width:
+			lines = append(lines, line)
+			line = cont + word
+		default:
+			line += " " + word
+		}
+		empty = false
+	}
+	return append(lines, line)
+}
+
+// textColumns returns the display width of s, with tabs expanded as the
+// prose budget counts them.
+func textColumns(s string) int {
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			col += proseTabWidth - col%proseTabWidth
+		} else {
+			col++
+		}
+	}
+	return col
+}
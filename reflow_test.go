@@ -0,0 +1,99 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReflowLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{
+			"prose",
+			"one two three\nfour five six seven",
+			14,
+			"one two three\nfour five six\nseven",
+		},
+		{
+			"paragraphs",
+			"a b\nc\n\nd\ne",
+			80,
+			"a b c\n\nd e",
+		},
+		{
+			"comment",
+			"\t// The quick brown fox jumps\n\t// over the lazy dog.",
+			24,
+			"\t// The quick brown\n\t// fox jumps over\n\t// the lazy dog.", // The tab counts 4 columns
+		},
+		{
+			"code left alone",
+			"// a\n// b\nx := 1\ny := 2",
+			80,
+			"// a b\nx := 1\ny := 2",
+		},
+		{
+			"directive",
+			"// a\n//go:generate stringer\n// b",
+			80,
+			"// a\n//go:generate stringer\n// b",
+		},
+		{
+			"indented code in doc comment",
+			"// Use it as in\n//\n//\tf(x)\n// and go.",
+			80,
+			"// Use it as in\n//\n//\tf(x)\n// and go.",
+		},
+		{
+			"list item",
+			"// - first item that wraps\n//   around\n// - second",
+			20,
+			"// - first item that\n//   wraps around\n// - second",
+		},
+		{
+			"long word",
+			"a verylongword b",
+			5,
+			"a\nverylongword\nb",
+		},
+	}
+	for _, tt := range tests {
+		got := strings.Join(reflowLines(strings.Split(tt.in, "\n"), tt.width), "\n")
+		if got != tt.want {
+			t.Errorf("%s: reflowLines = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextColumns(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"héllo", 5},
+		{"\t", proseTabWidth},
+		{"a\tb", proseTabWidth + 1},
+	}
+	for _, tt := range tests {
+		if got := textColumns(tt.s); got != tt.want {
+			t.Errorf("textColumns(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestFillWords(t *testing.T) {
+	got := fillWords([]string{"aa", "bb", "cc"}, "> ", "  ", 7)
+	if want := []string{"> aa bb", "  cc"}; !slices.Equal(got, want) {
+		t.Errorf("fillWords = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Regex Tester
+// -------------------------------------------------------------------------
+
+const (
+	regexMaxMatches = 1000      // Matches highlighted and listed at most
+	tagRegexMatch   = "rematch" // Sample tag of whole matches
+	tagRegexGroup   = "regroup" // Prefix of the sample tags of groups, by group number
+)
+
+// regexPanel is the window trying a Go regular expression on sample text.
+type regexPanel struct {
+	window  *ToplevelWidget
+	pattern *TEntryWidget
+	fold    *TCheckbuttonWidget // (?i)
+	multi   *TCheckbuttonWidget // (?m)
+	dotNL   *TCheckbuttonWidget // (?s)
+	sample  *TextWidget
+	matches *TextWidget
+	status  *TLabelWidget
+}
+
+// onRegexTester opens the Regex Tester window, which highlights the
+// matches of a regular expression, and of its groups, in sample text as
+// either is typed. The syntax is Go's RE2, which has no lookaround or
+// backreferences, unlike the PCRE dialects of most online testers.
+func (i *Ite) onRegexTester() {
+	if i.regex != nil {
+		WmDeiconify(i.regex.window.Window)
+		tclEval("raise %s", i.regex.window)
+		Focus(i.regex.pattern)
+		return
+	}
+	p := &regexPanel{window: Toplevel()}
+	p.window.WmTitle("Regex Tester")
+
+	top := p.window.TFrame()
+	Grid(top, Row(0), Column(0), Columnspan(2), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	Grid(top.TLabel(Txt("Regexp:")), Row(0), Column(0), Padx(px(2)))
+	initial := ""
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		if s := i.editText.Get(sel[0], sel[len(sel)-1])[0]; !strings.Contains(s, "\n") {
+			initial = s
+		}
+	}
+	p.pattern = top.TEntry(Width(50), Textvariable(initial), Font(editorFontFamily, fontSize))
+	Grid(p.pattern, Row(0), Column(1), Sticky(WE))
+	p.fold = top.TCheckbutton(Txt("Ignore case"), Variable(0), Command(i.updateRegexTester))
+	p.multi = top.TCheckbutton(Txt("Multiline"), Variable(0), Command(i.updateRegexTester))
+	p.dotNL = top.TCheckbutton(Txt(". matches \\n"), Variable(0), Command(i.updateRegexTester))
+	Grid(p.fold, Row(0), Column(2), Padx(px(2)))
+	Grid(p.multi, Row(0), Column(3), Padx(px(2)))
+	Grid(p.dotNL, Row(0), Column(4), Padx(px(2)))
+	Grid(top.TButton(Txt("Copy as Go"), Command(i.copyRegexAsGo)), Row(0), Column(5), Padx(px(2)))
+	GridColumnConfigure(top, 1, Weight(1))
+
+	Grid(p.window.TLabel(Txt("Sample text")), Row(1), Column(0), Sticky(W), Padx(px(5)))
+	p.sample = p.window.Text(textStyle(), Width(60), Height(20))
+	Grid(p.sample, Row(2), Column(0), Sticky(NEWS), Padx(px(5)))
+	Grid(p.window.TLabel(Txt("Matches")), Row(1), Column(1), Sticky(W), Padx(px(5)))
+	p.matches = p.window.Text(textStyle(), Width(50), Height(20), Wrap("none"), State("disabled"))
+	Grid(p.matches, Row(2), Column(1), Sticky(NEWS), Padx(px(5)))
+	p.status = p.window.TLabel(Txt(""))
+	Grid(p.status, Row(3), Column(0), Columnspan(2), Sticky(W), Padx(px(5)), Pady(px(5)))
+	GridColumnConfigure(p.window, 0, Weight(2))
+	GridColumnConfigure(p.window, 1, Weight(1))
+	GridRowConfigure(p.window, 2, Weight(1))
+
+	p.sample.TagConfigure(tagRegexMatch, Background(theme.Selection))
+	for n, color := range regexGroupColors() {
+		p.sample.TagConfigure(fmt.Sprintf("%s%d", tagRegexGroup, n+1), Foreground(color), Underline(1))
+	}
+
+	Bind(p.pattern, "<KeyRelease>", Command(i.updateRegexTester))
+	Bind(p.sample, "<KeyRelease>", Command(i.updateRegexTester))
+	Bind(p.sample, "<<Paste>>", Command(func() { TclAfterIdle(i.updateRegexTester) }))
+	closeWindow := func() {
+		Destroy(p.window)
+		i.regex = nil
+	}
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	Focus(p.pattern)
+	i.regex = p
+	i.updateRegexTester()
+}
+
+// regexGroupColors returns the colors of the groups, cycled through from
+// group 1.
+func regexGroupColors() []string {
+	return []string{theme.Link, theme.Success, theme.Warning, theme.Error}
+}
+
+// regexp compiles the pattern of the panel with the chosen flags.
+func (p *regexPanel) regexp() (*regexp.Regexp, error) {
+	flags := ""
+	for _, f := range []struct {
+		check *TCheckbuttonWidget
+		flag  string
+	}{{p.fold, "i"}, {p.multi, "m"}, {p.dotNL, "s"}} {
+		if f.check.Variable() == "1" {
+			flags += f.flag
+		}
+	}
+	pattern := p.pattern.Textvariable()
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// updateRegexTester highlights the matches of the pattern in the sample
+// and lists them with their groups.
+func (i *Ite) updateRegexTester() {
+	p := i.regex
+	if p == nil {
+		return
+	}
+	groups := len(regexGroupColors())
+	p.sample.TagRemove(tagRegexMatch, "1.0", "end")
+	for n := range groups {
+		p.sample.TagRemove(fmt.Sprintf("%s%d", tagRegexGroup, n+1), "1.0", "end")
+	}
+	p.matches.Configure(State("normal"))
+	defer p.matches.Configure(State("disabled"))
+	p.matches.Delete("1.0", "end")
+
+	if p.pattern.Textvariable() == "" {
+		p.status.Configure(Txt(""), Foreground(theme.Foreground))
+		return
+	}
+	re, err := p.regexp()
+	if err != nil {
+		p.status.Configure(Txt(err.Error()), Foreground(theme.Error))
+		return
+	}
+	sample := p.sample.Get("1.0", "end-1c")[0]
+	found := re.FindAllStringSubmatchIndex(sample, regexMaxMatches)
+	pos := func(offset int) string {
+		return fmt.Sprintf("1.0 +%dc", utf8.RuneCountInString(sample[:offset]))
+	}
+	names := re.SubexpNames()
+	for n, m := range found {
+		p.sample.TagAdd(tagRegexMatch, pos(m[0]), pos(m[1]))
+		if n > 0 {
+			p.matches.Insert("end", "\n")
+		}
+		p.matches.Insert("end", fmt.Sprintf("%d: %q\n", n+1, sample[m[0]:m[1]]))
+		for g := 1; g < len(m)/2; g++ {
+			start, end := m[2*g], m[2*g+1]
+			label := fmt.Sprint(g)
+			if names[g] != "" {
+				label += " " + names[g]
+			}
+			if start < 0 {
+				p.matches.Insert("end", fmt.Sprintf("  %s: (no match)\n", label))
+				continue
+			}
+			tag := fmt.Sprintf("%s%d", tagRegexGroup, (g-1)%groups+1)
+			p.sample.TagAdd(tag, pos(start), pos(end))
+			p.matches.Insert("end", fmt.Sprintf("  %s: %q\n", label, sample[start:end]))
+		}
+	}
+
+	status := fmt.Sprintf("%d matches, %d groups", len(found), re.NumSubexp())
+	if len(found) == regexMaxMatches {
+		status = fmt.Sprintf("First %d matches, %d groups", regexMaxMatches, re.NumSubexp())
+	}
+	p.status.Configure(Txt(status), Foreground(theme.Foreground))
+}
+
+// copyRegexAsGo copies the pattern, with its flags, as a Go statement
+// compiling it.
+func (i *Ite) copyRegexAsGo() {
+	re, err := i.regex.regexp()
+	if err != nil {
+		i.showError("Invalid regular expression: " + err.Error())
+		return
+	}
+	ClipboardClear()
+	ClipboardAppend("regexp.MustCompile(" + goStringLiteral(re.String()) + ")")
+	i.regex.status.Configure(Txt("Copied"), Foreground(theme.Foreground))
+}
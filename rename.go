@@ -0,0 +1,349 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Rename Package
+// -------------------------------------------------------------------------
+
+// Rename Package renames the directory of the current file, the package
+// clause of its files, and the import paths of the module naming it or a
+// package below it. Files importing the package without a name have their
+// references to it renamed too. The edits are listed in a preview window
+// and only applied, and the directory moved, on Apply. Files with unsaved
+// changes are edited in their buffer, see refactor.go.
+
+// renamePlan is a package rename, as previewed.
+type renamePlan struct {
+	root             string // Module root
+	oldDir, newDir   string
+	oldPath, newPath string // Import paths
+	oldName, newName string // Package names, equal for package main
+	files            []structFile
+	edits            int
+	broken           int // Go files that didn't parse
+	err              error
+}
+
+// renamePanel is the preview window of a package rename.
+type renamePanel struct {
+	window  *ToplevelWidget
+	results *TextWidget
+	status  *TLabelWidget
+	plan    *renamePlan
+	edits   map[int]structMatch // Edits by results line
+}
+
+// onRenamePackage asks for the new name of the package of the current
+// file and previews the rename.
+func (i *Ite) onRenamePackage() {
+	if i.currentFile == "" || filepath.Ext(i.currentFile) != defaultFileExtension {
+		i.showError("Rename Package needs a saved Go file.")
+		return
+	}
+	dir, _ := filepath.Abs(filepath.Dir(i.currentFile))
+	root := projectRoot(i.currentFile)
+	if samePath(dir, root) {
+		i.showError("Rename Package can't rename the module root; change the module path in go.mod instead.")
+		return
+	}
+	i.promptString("Rename Package", "New name of "+i.displayPath(dir)+":", filepath.Base(dir), func(name string) {
+		name = strings.TrimSpace(name)
+		if name == filepath.Base(dir) {
+			return
+		}
+		if !token.IsIdentifier(name) {
+			i.showError(fmt.Sprintf("Rename Package: %q is not a valid package name.", name))
+			return
+		}
+		i.showStatusHint("Planning the rename of " + filepath.Base(dir) + "...")
+		buffers := i.modifiedBuffers()
+		go func() {
+			plan := planPackageRename(root, dir, name, buffers)
+			i.Dispatch(func() {
+				if plan.err != nil {
+					i.showError("Rename Package: " + plan.err.Error())
+					return
+				}
+				i.showRenamePreview(plan)
+			})
+		}()
+	})
+}
+
+// planPackageRename returns the edits renaming the package in dir, below
+// the module root, to name. Files with unsaved changes are planned as
+// edited, through buffers.
+func planPackageRename(root, dir, name string, buffers map[string]string) *renamePlan {
+	plan := &renamePlan{root: root, oldDir: dir, newDir: filepath.Join(filepath.Dir(dir), name)}
+	module := modulePath(filepath.Join(root, "go.mod"))
+	if module == "" {
+		plan.err = errors.New("no module path in " + filepath.Join(root, "go.mod"))
+		return plan
+	}
+	if _, err := os.Stat(plan.newDir); err == nil {
+		plan.err = errors.New(plan.newDir + " already exists")
+		return plan
+	}
+	rel, _ := filepath.Rel(root, dir)
+	plan.oldPath = module + "/" + filepath.ToSlash(rel)
+	plan.newPath = path.Join(path.Dir(plan.oldPath), name)
+	plan.oldName, plan.err = packageName(dir)
+	if plan.err != nil {
+		return plan
+	}
+	plan.newName = name
+	if plan.oldName == "main" {
+		plan.newName = "main"
+	}
+
+	plan.err = walkProject(root, nil, func(file string) error {
+		if !strings.HasSuffix(file, defaultFileExtension) {
+			return nil
+		}
+		f := structFile{path: file}
+		if text, ok := buffers[file]; ok {
+			f.src, f.buffer = []byte(text), true
+		} else {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return nil
+			}
+			f.src = src
+		}
+		edits, err := plan.fileEdits(file, f.src)
+		if err != nil {
+			plan.broken++
+			return nil
+		}
+		if len(edits) > 0 {
+			f.matches = edits
+			plan.files = append(plan.files, f)
+			plan.edits += len(edits)
+		}
+		return nil
+	})
+	return plan
+}
+
+// packageName returns the name of the package in dir, as declared by its
+// files other than tests.
+func packageName(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != defaultFileExtension || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name, nil
+		}
+	}
+	return "", errors.New("no Go package in " + dir)
+}
+
+// fileEdits returns the edits of the rename in file, whose content is
+// src, in source order.
+func (plan *renamePlan) fileEdits(file string, src []byte) ([]structMatch, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	var edits []structMatch
+	edit := func(node ast.Node, after string) {
+		start, end := fset.Position(node.Pos()), fset.Position(node.End())
+		edits = append(edits, structMatch{
+			path:   file,
+			line:   start.Line,
+			col:    start.Column,
+			start:  start.Offset,
+			end:    end.Offset,
+			before: string(src[start.Offset:end.Offset]),
+			after:  after,
+		})
+	}
+
+	renamed := plan.oldName != plan.newName
+	if renamed && samePath(filepath.Dir(file), plan.oldDir) {
+		switch f.Name.Name {
+		case plan.oldName:
+			edit(f.Name, plan.newName)
+		case plan.oldName + "_test":
+			edit(f.Name, plan.newName+"_test")
+		}
+	}
+	qualified := false // The file refers to the package by its name
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if p == plan.oldPath || strings.HasPrefix(p, plan.oldPath+"/") {
+			edit(spec.Path, strconv.Quote(plan.newPath+p[len(plan.oldPath):]))
+			qualified = qualified || p == plan.oldPath && spec.Name == nil
+		}
+	}
+	if renamed && qualified {
+		ast.Inspect(f, func(n ast.Node) bool {
+			// Package names are left unresolved by the parser, unlike the
+			// local names that could shadow them
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok && id.Name == plan.oldName && id.Obj == nil {
+					edit(id, plan.newName)
+				}
+			}
+			return true
+		})
+	}
+	slices.SortFunc(edits, func(a, b structMatch) int { return cmp.Compare(a.start, b.start) })
+	return edits, nil
+}
+
+// showRenamePreview lists the edits of plan in the preview window.
+func (i *Ite) showRenamePreview(plan *renamePlan) {
+	if i.rename != nil {
+		Destroy(i.rename.window)
+	}
+	p := &renamePanel{window: Toplevel(), plan: plan}
+	p.window.WmTitle("Rename Package - " + plan.oldPath)
+	p.results = p.window.Text(textStyle(), Width(100), Height(25), Wrap("none"))
+	scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.results) }))
+	p.results.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(p.results, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	bottom := p.window.TFrame()
+	Grid(bottom, Row(1), Column(0), Columnspan(2), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	p.status = bottom.TLabel()
+	Grid(p.status, Row(0), Column(0), Sticky(W))
+	apply := bottom.TButton(Txt("Apply"), Command(i.applyPackageRename))
+	Grid(apply, Row(0), Column(1), Padx(px(2)))
+	closeWindow := func() {
+		Destroy(p.window)
+		i.rename = nil
+		Focus(i.editText)
+	}
+	Grid(bottom.TButton(Txt("Cancel"), Command(closeWindow)), Row(0), Column(2), Padx(px(2)))
+	GridColumnConfigure(bottom, 0, Weight(1))
+	GridRowConfigure(p.window, 0, Weight(1))
+	GridColumnConfigure(p.window, 0, Weight(1))
+
+	p.results.TagConfigure(tagLink, Foreground(theme.Link))
+	p.results.TagConfigure(tagStructOld, Background(theme.DiffOld))
+	p.results.TagConfigure(tagStructNew, Background(theme.DiffNew))
+	showEdit := func(line int) {
+		if m, ok := p.edits[line]; ok {
+			i.showLocation(location{path: m.path, line: m.line, col: m.col})
+		}
+	}
+	p.results.TagBind(tagLink, "<Button-1>", func() {
+		line, _ := parseIndex(p.results.Index("current"))
+		showEdit(line)
+	})
+	bindPanelKeys(p.results, showEdit)
+	p.results.TagBind(tagLink, "<Enter>", func() { p.results.Configure(Cursor("hand2")) })
+	p.results.TagBind(tagLink, "<Leave>", func() { p.results.Configure(Cursor("xterm")) })
+
+	p.results.Insert("end", "move ")
+	p.results.Insert("end", relativeTo(plan.root, plan.oldDir), tagStructOld)
+	p.results.Insert("end", " → ")
+	p.results.Insert("end", relativeTo(plan.root, plan.newDir)+"\n", tagStructNew)
+	p.edits = insertEditPreview(p.results, plan.root, plan.files, 2, true)
+	p.results.Configure(State("disabled"))
+
+	disk, buffer := countEdits(plan.files)
+	status := editSummary(plan.files, disk, buffer)
+	if plan.broken > 0 {
+		status += fmt.Sprintf(", %d Go files with syntax errors skipped", plan.broken)
+	}
+	p.status.Configure(Txt(status))
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	i.bindFocusKeys(p.window.Window)
+	Focus(apply)
+	i.rename = p
+}
+
+// applyPackageRename rewrites the files of the previewed rename, moves the
+// package directory and edits the unsaved buffers, all or none, then
+// reloads the other buffers it affected. Nothing is changed if a file was
+// edited since the preview.
+func (i *Ite) applyPackageRename() {
+	p := i.rename
+	plan := p.plan
+	if err := i.checkFileEdits(plan.root, plan.files); err != nil {
+		p.status.Configure(Txt(err.Error() + ": close and rename again"))
+		return
+	}
+	op := i.beginFileOp("Rename Package " + filepath.Base(plan.newDir))
+	disk, err := writeFileEdits(op, plan.root, plan.files)
+	if err == nil {
+		if err = os.Rename(plan.oldDir, plan.newDir); err != nil {
+			var written []structFile
+			for _, f := range plan.files {
+				if !f.buffer {
+					written = append(written, f)
+				}
+			}
+			restoreFiles(written)
+			err = fmt.Errorf("moving %s: %w", relativeTo(plan.root, plan.oldDir), err)
+		}
+	}
+	if err != nil {
+		removeBackups(op.op)
+		p.status.Configure(Txt("Nothing renamed: " + err.Error()))
+		return
+	}
+	op.renamed(plan.oldDir, plan.newDir)
+	i.commitFileOp(op)
+
+	// Edit the buffers under their old names, then follow the move, and
+	// reload the files rewritten outside of it
+	buffer := i.applyBufferEdits(plan.files)
+	for _, pane := range i.panes {
+		i.withPane(pane, func() {
+			if i.currentFile == "" {
+				return
+			}
+			rel, err := filepath.Rel(plan.oldDir, absPath(i.currentFile))
+			if err != nil || !filepath.IsLocal(rel) {
+				return
+			}
+			i.currentFile = filepath.Join(plan.newDir, rel)
+			if i.editText.Modified() {
+				i.recordDiskStamp()
+				i.updateTitle()
+			} else {
+				i.reloadActiveBuffer()
+			}
+		})
+	}
+	i.reloadEditedBuffers(plan.files)
+
+	Destroy(p.window)
+	i.rename = nil
+	Focus(i.editText)
+	i.showStatusHint(fmt.Sprintf("Renamed %s to %s: %s", plan.oldPath, plan.newPath, editSummary(plan.files, disk, buffer)))
+}
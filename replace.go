@@ -0,0 +1,297 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Search and Replace
+// -------------------------------------------------------------------------
+
+const (
+	projectConfigDir  = ".ite"         // Per-project settings directory
+	presetsFileName   = "presets.json" // Replace presets inside projectConfigDir
+	errNoSelection    = "nothing is selected"
+	errEmptySearch    = "the search text is empty"
+	replaceDoneFormat = "%d replaced"
+)
+
+// replaceSpec describes a search and replace operation. Saved with a name,
+// it becomes a preset that can be run again from the command palette.
+type replaceSpec struct {
+	Name      string `json:"name"`
+	Search    string `json:"search"`
+	Replace   string `json:"replace"`   // With Regex set, $1 or ${name} expand submatches
+	Regex     bool   `json:"regex"`     // Search is a regular expression
+	Selection bool   `json:"selection"` // Only replace within the selection
+}
+
+// onReplace opens the search and replace dialog.
+func (i *Ite) onReplace() {
+	dialog := Toplevel()
+	dialog.WmTitle("Replace")
+
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	Grid(frame.TLabel(Txt("Search:")), Row(0), Column(0), Sticky(W), Pady(px(5)))
+	search := frame.TEntry(Width(40), Textvariable(""))
+	Grid(search, Row(0), Column(1), Pady(px(5)))
+	Grid(frame.TLabel(Txt("Replace:")), Row(1), Column(0), Sticky(W), Pady(px(5)))
+	replace := frame.TEntry(Width(40), Textvariable(""))
+	Grid(replace, Row(1), Column(1), Pady(px(5)))
+	regex := frame.TCheckbutton(Txt("Regular expression"), Variable(0))
+	Grid(regex, Row(2), Column(1), Sticky(W))
+	inSel := frame.TCheckbutton(Txt("In selection only"), Variable(0))
+	Grid(inSel, Row(3), Column(1), Sticky(W))
+	Focus(search)
+
+	spec := func() replaceSpec {
+		return replaceSpec{
+			Search:    search.Textvariable(),
+			Replace:   replace.Textvariable(),
+			Regex:     regex.Variable() == "1",
+			Selection: inSel.Variable() == "1",
+		}
+	}
+	replaceAll := func() {
+		i.runReplace(spec())
+	}
+	savePreset := func() {
+		s := spec()
+		i.promptString("Save Preset", "Preset name:", "", func(name string) {
+			s.Name = name
+			i.savePreset(s)
+		})
+	}
+	closeDialog := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(4), Column(0), Columnspan(2), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Replace All"), Command(replaceAll)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Save Preset..."), Command(savePreset)), Row(0), Column(1), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Close"), Command(closeDialog)), Row(0), Column(2), Padx(px(5)))
+
+	Bind(search, "<Return>", Command(replaceAll))
+	Bind(replace, "<Return>", Command(replaceAll))
+	Bind(dialog, "<Escape>", Command(closeDialog))
+}
+
+// runReplace applies spec to the editor and reports the outcome in the
+// status bar.
+func (i *Ite) runReplace(spec replaceSpec) {
+	n, err := i.applyReplace(spec)
+	if err != nil {
+		i.showError("Replace: " + err.Error())
+		return
+	}
+	i.showStatusHint(fmt.Sprintf(replaceDoneFormat, n))
+}
+
+// applyReplace replaces every match of spec in its scope as a single undo
+// step and returns the number of replacements. Matches touching a
+// read-only region are left alone.
+func (i *Ite) applyReplace(spec replaceSpec) (int, error) {
+	re, err := spec.compile()
+	if err != nil {
+		return 0, err
+	}
+
+	from, to := "1.0", "end-1c"
+	if spec.Selection {
+		sel := i.editText.TagRanges("sel")
+		if len(sel) < 2 {
+			return 0, errors.New(errNoSelection)
+		}
+		from, to = sel[0], sel[len(sel)-1]
+	}
+	text := i.editText.Get(from, to)[0]
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+
+	n := 0
+	i.editGroup(func() {
+		// Back to front, so the offsets of earlier matches stay valid
+		for k := len(matches) - 1; k >= 0; k-- {
+			m := matches[k]
+			start := fmt.Sprintf("%s +%dc", from, utf8.RuneCountInString(text[:m[0]]))
+			end := fmt.Sprintf("%s +%dc", from, utf8.RuneCountInString(text[:m[1]]))
+			start, end = i.editText.Index(start), i.editText.Index(end)
+			if i.isProtected(start, end) {
+				continue
+			}
+			i.editText.Delete(start, end)
+			i.editText.Insert(start, spec.replacement(re, text, m))
+			n++
+		}
+	})
+	i.refreshCursorState()
+	return n, nil
+}
+
+// compile returns the expression matching the search text of spec.
+func (spec replaceSpec) compile() (*regexp.Regexp, error) {
+	if spec.Search == "" {
+		return nil, errors.New(errEmptySearch)
+	}
+	pattern := spec.Search
+	if !spec.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	return regexp.Compile(pattern)
+}
+
+// replacement returns the text replacing the match m of re in text.
+func (spec replaceSpec) replacement(re *regexp.Regexp, text string, m []int) string {
+	if spec.Regex {
+		return string(re.ExpandString(nil, spec.Replace, text, m))
+	}
+	return spec.Replace
+}
+
+// replaceText returns text with every match of spec replaced, ignoring
+// its Selection, and the number of replacements.
+func replaceText(spec replaceSpec, text string) (string, int, error) {
+	re, err := spec.compile()
+	if err != nil {
+		return "", 0, err
+	}
+	var sb strings.Builder
+	prev := 0
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	for _, m := range matches {
+		sb.WriteString(text[prev:m[0]])
+		sb.WriteString(spec.replacement(re, text, m))
+		prev = m[1]
+	}
+	sb.WriteString(text[prev:])
+	return sb.String(), len(matches), nil
+}
+
+// -------------------------------------------------------------------------
+// Replace Presets
+// -------------------------------------------------------------------------
+
+// presetsPath returns the presets file of the project holding the current
+// file, or "" when no file is open.
+func (i *Ite) presetsPath() string {
+	if i.currentFile == "" {
+		return ""
+	}
+	return filepath.Join(i.projectDir(), projectConfigDir, presetsFileName)
+}
+
+// loadPresets reads the replace presets of the current project. A missing
+// file yields no presets.
+func (i *Ite) loadPresets() ([]replaceSpec, error) {
+	path := i.presetsPath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var presets []replaceSpec
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// storePresets writes the replace presets of the current project.
+func (i *Ite) storePresets(presets []replaceSpec) error {
+	path := i.presetsPath()
+	if path == "" {
+		return errors.New(statusNoFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(presets, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), configFilePerms)
+}
+
+// savePreset adds spec to the project presets, replacing any preset with
+// the same name.
+func (i *Ite) savePreset(spec replaceSpec) {
+	if spec.Name == "" {
+		i.showError("Preset name is empty")
+		return
+	}
+	presets, err := i.loadPresets()
+	if err != nil {
+		i.showError("Error reading presets: " + err.Error())
+		return
+	}
+	replaced := false
+	for n := range presets {
+		if presets[n].Name == spec.Name {
+			presets[n] = spec
+			replaced = true
+		}
+	}
+	if !replaced {
+		presets = append(presets, spec)
+	}
+	if err := i.storePresets(presets); err != nil {
+		i.showError("Error saving presets: " + err.Error())
+	}
+}
+
+// deletePreset removes the named preset from the project presets.
+func (i *Ite) deletePreset(name string) {
+	presets, err := i.loadPresets()
+	if err != nil {
+		i.showError("Error reading presets: " + err.Error())
+		return
+	}
+	kept := presets[:0]
+	for _, p := range presets {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if err := i.storePresets(kept); err != nil {
+		i.showError("Error saving presets: " + err.Error())
+	}
+}
+
+// projectRoot returns the directory of the Go module holding path: the
+// closest parent with a go.mod file or project settings, or the directory
+// of path if none.
+func projectRoot(path string) string {
+	start := filepath.Dir(path)
+	if abs, err := filepath.Abs(start); err == nil {
+		start = abs
+	}
+	for dir := start; ; {
+		if fileExists(filepath.Join(dir, "go.mod")) || fileExists(filepath.Join(dir, projectConfigDir, projectFileName)) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return start
+		}
+		dir = parent
+	}
+}
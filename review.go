@@ -0,0 +1,288 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Patch Review
+// -------------------------------------------------------------------------
+
+// Review Patch loads a unified diff, from a patch file or from git diff
+// over a range of commits, and lists its files; picking one shows its
+// hunks with the removed and added lines colored. Return or a double click
+// on a line opens the file there.
+const (
+	devNull         = "/dev/null" // Path of the missing side of added and deleted files
+	defaultRevRange = "HEAD~1..HEAD"
+)
+
+// hunkHeaderRe matches the header of a hunk, capturing the first line and
+// the count of each side, e.g. "@@ -12,7 +12,9 @@ func main() {".
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// patchFile is the diff of a file in a patch.
+type patchFile struct {
+	oldPath, newPath string // Relative to the root of the patch, devNull when missing
+	added, deleted   int
+	lines            []patchLine
+}
+
+// patchLine is a line of the hunks of a patchFile.
+type patchLine struct {
+	text   string
+	op     diffOp
+	header bool // Hunk header rather than a line of the file
+	line   int  // Line of the new file shown there, counted from 1
+}
+
+// path returns the path of the file after the patch, or before it for a
+// deleted file.
+func (f *patchFile) path() string {
+	if f.newPath == devNull {
+		return f.oldPath
+	}
+	return f.newPath
+}
+
+// parsePatch returns the files of the unified diff text in order.
+func parsePatch(text string) []*patchFile {
+	var files []*patchFile
+	var f *patchFile
+	oldLeft, newLeft, line := 0, 0, 0 // Lines left in the current hunk
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.TrimSuffix(l, "\r")
+		if oldLeft > 0 || newLeft > 0 {
+			pl := patchLine{text: l, line: line}
+			switch {
+			case strings.HasPrefix(l, "+"):
+				pl.op = diffInsert
+				f.added++
+				newLeft--
+				line++
+			case strings.HasPrefix(l, "-"):
+				pl.op = diffDelete
+				f.deleted++
+				oldLeft--
+			case strings.HasPrefix(l, `\`): // No newline at end of file
+			default:
+				oldLeft--
+				newLeft--
+				line++
+			}
+			f.lines = append(f.lines, pl)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			f = &patchFile{}
+			if a, b, ok := strings.Cut(strings.TrimPrefix(l, "diff --git "), " b/"); ok {
+				f.oldPath, f.newPath = strings.TrimPrefix(a, "a/"), b
+			}
+			files = append(files, f)
+		case strings.HasPrefix(l, "--- "):
+			if f == nil || len(f.lines) > 0 {
+				f = &patchFile{}
+				files = append(files, f)
+			}
+			f.oldPath = patchPath(l[len("--- "):], "a/")
+		case strings.HasPrefix(l, "+++ ") && f != nil:
+			f.newPath = patchPath(l[len("+++ "):], "b/")
+		case strings.HasPrefix(l, "@@") && f != nil:
+			m := hunkHeaderRe.FindStringSubmatch(l)
+			if m == nil {
+				continue
+			}
+			oldLeft, newLeft = hunkCount(m[2]), hunkCount(m[4])
+			line, _ = strconv.Atoi(m[3])
+			f.lines = append(f.lines, patchLine{text: l, header: true, line: max(line, 1)})
+		}
+	}
+	return files
+}
+
+// patchPath returns the path of a "---" or "+++" line without its prefix
+// and timestamp.
+func patchPath(s, prefix string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	if s == devNull {
+		return s
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+// hunkCount returns the line count of a side of a hunk header, which is
+// 1 when left out.
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// onReviewPatch asks for a patch file and reviews it.
+func (i *Ite) onReviewPatch() {
+	paths := GetOpenFile(Title("Review Patch"), Initialdir(i.defaultDir()), Filetypes([]FileType{
+		{TypeName: "Patches", Extensions: []string{"*.patch", "*.diff"}, MacType: ""},
+		{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
+	}))
+	if len(paths) == 0 {
+		return
+	}
+	i.rememberDialogDir(paths[0])
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		i.showError("Review Patch: " + err.Error())
+		return
+	}
+	// The paths of a patch are relative to the root of its repository,
+	// as git writes them
+	dir := filepath.Dir(paths[0])
+	root := dir
+	if out, err := runGit(dir, "", "rev-parse", "--show-toplevel"); err == nil {
+		root = filepath.FromSlash(strings.TrimSpace(out))
+	}
+	i.showReview(filepath.Base(paths[0]), root, decodedText(data))
+}
+
+// onReviewRange asks for a range of commits and reviews git diff over it.
+func (i *Ite) onReviewRange() {
+	i.promptString("Review Commits", "Revision range, as given to git diff:", defaultRevRange, func(revs string) {
+		args := strings.Fields(revs)
+		if len(args) == 0 {
+			return
+		}
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-") {
+				i.showError("Review Commits: give revisions, not options: " + arg)
+				return
+			}
+		}
+		dir := filepath.Dir(i.currentFile)
+		if i.currentFile == "" {
+			dir = i.defaultDir()
+		}
+		i.showStatusHint("git diff " + revs + "...")
+		go func() {
+			root, err := runGit(dir, "", "rev-parse", "--show-toplevel")
+			var out string
+			if err == nil {
+				root = filepath.FromSlash(strings.TrimSpace(root))
+				out, err = runGit(root, "", append(append([]string{"diff", "--no-color", "--no-ext-diff"}, args...), "--")...)
+			}
+			i.Dispatch(func() {
+				if err != nil {
+					i.showError("Review Commits: " + err.Error())
+					return
+				}
+				i.showReview(strings.Join(args, " "), root, out)
+			})
+		}()
+	})
+}
+
+// showReview opens the review window of the patch text named name, its
+// paths relative to root.
+func (i *Ite) showReview(name, root, text string) {
+	files := parsePatch(text)
+	if len(files) == 0 {
+		i.showStatusHint("No changes to review in " + name)
+		return
+	}
+	dialog := Toplevel()
+	dialog.WmTitle("Review - " + name)
+
+	list := dialog.Text(textStyle(), Width(40), Height(30), Wrap("none"))
+	view := dialog.Text(textStyle(), Width(100), Height(30), Wrap("none"))
+	scrollbar := dialog.TScrollbar(Command(func(e *Event) { e.Yview(view) }))
+	view.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	view.TagConfigure(tagDiffDel, Background(theme.DiffOld))
+	view.TagConfigure(tagDiffAdd, Background(theme.DiffNew))
+	view.TagConfigure(tagDiffHunk, Foreground(theme.Muted))
+	status := dialog.TLabel()
+	export := dialog.TButton(Txt("Export PDF..."), Command(func() { i.exportPatchPDF(name, files) }))
+	Grid(list, Row(0), Column(0), Sticky(NEWS))
+	Grid(view, Row(0), Column(1), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(2), Sticky(NS))
+	Grid(status, Row(1), Column(0), Sticky(W), Padx(px(5)))
+	Grid(export, Row(1), Column(1), Columnspan(2), Sticky(E), Padx(px(5)), Pady(px(3)))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 1, Weight(1))
+	Bind(dialog, "<Escape>", Command(func() { Destroy(dialog) }))
+
+	added, deleted := 0, 0
+	for n, f := range files {
+		if n > 0 {
+			list.Insert("end", "\n")
+		}
+		list.Insert("end", fmt.Sprintf("+%-4d -%-4d %s", f.added, f.deleted, f.path()))
+		added, deleted = added+f.added, deleted+f.deleted
+	}
+	list.Configure(State("disabled"))
+	status.Configure(Txt(fmt.Sprintf("%d files, +%d -%d", len(files), added, deleted)))
+
+	var shown *patchFile
+	show := func(line int) {
+		if line < 1 || line > len(files) {
+			return
+		}
+		shown = files[line-1]
+		view.Configure(State("normal"))
+		view.Delete("1.0", "end")
+		view.Insert("end", fmt.Sprintf("--- %s\n+++ %s\n", shown.oldPath, shown.newPath), tagDiffHunk)
+		for _, l := range shown.lines {
+			switch {
+			case l.header:
+				view.Insert("end", l.text+"\n", tagDiffHunk)
+			case l.op == diffDelete:
+				view.Insert("end", l.text+"\n", tagDiffDel)
+			case l.op == diffInsert:
+				view.Insert("end", l.text+"\n", tagDiffAdd)
+			default:
+				view.Insert("end", l.text+"\n")
+			}
+		}
+		view.Configure(State("disabled"))
+		view.MarkSet("insert", "1.0")
+	}
+	// open opens the file shown at the line of the view
+	open := func(line int) {
+		if shown == nil {
+			return
+		}
+		if shown.newPath == devNull {
+			i.showStatusHint(shown.oldPath + " is deleted by the patch")
+			return
+		}
+		target := 1
+		if n := line - 3; n >= 0 && n < len(shown.lines) { // Below the two header lines
+			target = shown.lines[n].line
+		} else if len(shown.lines) > 0 {
+			target = shown.lines[0].line
+		}
+		i.showLocation(location{path: filepath.Join(root, filepath.FromSlash(shown.newPath)), line: target, col: 1})
+	}
+	bindPanelKeys(list, show)
+	bindPanelKeys(view, open)
+	Bind(list, "<ButtonRelease-1>", Command(func(e *Event) {
+		line, _ := parseIndex(mouseIndex(list, e))
+		show(line)
+	}))
+	Bind(view, "<Double-Button-1>", Command(func(e *Event) {
+		line, _ := parseIndex(mouseIndex(view, e))
+		open(line)
+	}))
+	show(1)
+	Focus(list)
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// -------------------------------------------------------------------------
+// Command Runner
+// -------------------------------------------------------------------------
+
+// runner starts the programs of the jobs. The editor runs real processes
+// with execRunner; the tests put a fake in its place, which replays
+// scripted output.
+type runner interface {
+	start(spec procSpec) (process, error)
+}
+
+// procSpec describes a program to run.
+type procSpec struct {
+	args []string // Program and its arguments
+	dir  string   // Working directory
+	env  []string // Variables added to the environment, as KEY=VALUE
+}
+
+// process is a program started by a runner. Both output streams must be
+// read to the end before wait is called.
+type process interface {
+	stdout() io.Reader
+	stderr() io.Reader
+	wait() procResult
+	signal(force bool) error // Stops the program and every process it spawned
+	pid() int
+	args() []string
+	environ() []string // Environment the program was started with
+	started() time.Time
+}
+
+// procResult is the outcome of a finished program.
+type procResult struct {
+	exitCode int           // Exit status, -1 if the program was killed by a signal
+	duration time.Duration // Time from the start to the exit
+	err      error         // Why the run failed, nil on success
+}
+
+// streamOutput reads the output of p line by line until both streams
+// end, then waits for p. emit receives every line, with stderr set for
+// the lines of standard error; it is never called concurrently. Lines
+// written close together to both streams may swap places.
+func streamOutput(p process, emit func(line string, stderr bool)) procResult {
+	var emitMu sync.Mutex
+	var wg sync.WaitGroup
+	for _, stream := range []struct {
+		r      io.Reader
+		stderr bool
+	}{{p.stdout(), false}, {p.stderr(), true}} {
+		wg.Go(func() {
+			reader := bufio.NewReader(stream.r)
+			for {
+				line, err := reader.ReadString('\n')
+				if line != "" {
+					emitMu.Lock()
+					emit(line, stream.stderr)
+					emitMu.Unlock()
+				}
+				if err != nil {
+					break
+				}
+			}
+		})
+	}
+	wg.Wait() // Wait closes the pipes, so all output must be read first
+	return p.wait()
+}
+
+// -------------------------------------------------------------------------
+// Processes
+// -------------------------------------------------------------------------
+
+// execRunner runs programs as child processes, each in a process group of
+// its own, so that stopping it stops the processes it spawned too.
+type execRunner struct{}
+
+// execProcess is a child process started by execRunner.
+type execProcess struct {
+	cmd      *exec.Cmd
+	out, err io.Reader
+	start    time.Time
+}
+
+func (execRunner) start(spec procSpec) (process, error) {
+	cmd := exec.Command(spec.args[0], spec.args[1:]...)
+	cmd.Dir = spec.dir
+	if len(spec.env) > 0 {
+		cmd.Env = append(cmd.Environ(), spec.env...)
+	}
+	setProcessGroup(cmd)
+	// Separate pipes let the console style standard error apart
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &execProcess{cmd: cmd, out: stdout, err: stderr, start: time.Now()}, nil
+}
+
+func (p *execProcess) stdout() io.Reader  { return p.out }
+func (p *execProcess) stderr() io.Reader  { return p.err }
+func (p *execProcess) pid() int           { return p.cmd.Process.Pid }
+func (p *execProcess) args() []string     { return p.cmd.Args }
+func (p *execProcess) environ() []string  { return p.cmd.Environ() }
+func (p *execProcess) started() time.Time { return p.start }
+
+func (p *execProcess) wait() procResult {
+	err := p.cmd.Wait()
+	return procResult{
+		exitCode: p.cmd.ProcessState.ExitCode(),
+		duration: time.Since(p.start),
+		err:      err,
+	}
+}
+
+func (p *execProcess) signal(force bool) error {
+	if force {
+		return killProcessGroup(p.cmd)
+	}
+	return terminateProcessGroup(p.cmd)
+}
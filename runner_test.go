@@ -0,0 +1,139 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRunner stands for execRunner in the tests: it replays the output
+// scripted for a program instead of running it.
+type fakeRunner struct {
+	mu       sync.Mutex
+	programs map[string]fakeProgram // Scripted programs, by space-separated command line
+	started  []procSpec             // Programs started, in order
+}
+
+// fakeProgram is the scripted run of a program.
+type fakeProgram struct {
+	stdout, stderr string
+	exitCode       int
+}
+
+func (r *fakeRunner) start(spec procSpec) (process, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, spec)
+	prog, ok := r.programs[strings.Join(spec.args, " ")]
+	if !ok {
+		return nil, fmt.Errorf("exec: %q: executable file not found in $PATH", spec.args[0])
+	}
+	return &fakeProcess{spec: spec, prog: prog, start: time.Now()}, nil
+}
+
+// fakeProcess is a program started by fakeRunner.
+type fakeProcess struct {
+	spec  procSpec
+	prog  fakeProgram
+	start time.Time
+}
+
+func (p *fakeProcess) stdout() io.Reader  { return strings.NewReader(p.prog.stdout) }
+func (p *fakeProcess) stderr() io.Reader  { return strings.NewReader(p.prog.stderr) }
+func (p *fakeProcess) signal(bool) error  { return nil }
+func (p *fakeProcess) pid() int           { return 1 }
+func (p *fakeProcess) args() []string     { return p.spec.args }
+func (p *fakeProcess) environ() []string  { return p.spec.env }
+func (p *fakeProcess) started() time.Time { return p.start }
+func (p *fakeProcess) wait() (res procResult) {
+	res.exitCode = p.prog.exitCode
+	res.duration = time.Millisecond
+	if res.exitCode != 0 {
+		res.err = fmt.Errorf("exit status %d", res.exitCode)
+	}
+	return res
+}
+
+func TestStreamOutput(t *testing.T) {
+	r := &fakeRunner{programs: map[string]fakeProgram{
+		"go vet ./...": {stdout: "out 1\nout 2", stderr: "err 1\n", exitCode: 1},
+	}}
+	p, err := r.start(procSpec{args: []string{"go", "vet", "./..."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr []string
+	res := streamOutput(p, func(line string, isErr bool) {
+		if isErr {
+			stderr = append(stderr, line)
+		} else {
+			stdout = append(stdout, line)
+		}
+	})
+	if want := []string{"out 1\n", "out 2"}; !slices.Equal(stdout, want) {
+		t.Errorf("stdout lines = %q, want %q", stdout, want)
+	}
+	if want := []string{"err 1\n"}; !slices.Equal(stderr, want) {
+		t.Errorf("stderr lines = %q, want %q", stderr, want)
+	}
+	if res.exitCode != 1 || res.err == nil {
+		t.Errorf("result = %+v, want exit code 1 and an error", res)
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	tests := []struct {
+		args     []string
+		exitCode int
+	}{
+		{[]string{"go", "env", "GOOS"}, 0},
+		{[]string{"go", "nosuchcommand"}, 2},
+	}
+	for _, tt := range tests {
+		p, err := execRunner{}.start(procSpec{args: tt.args, dir: t.TempDir()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stdout strings.Builder
+		res := streamOutput(p, func(line string, stderr bool) {
+			if !stderr {
+				stdout.WriteString(line)
+			}
+		})
+		if res.exitCode != tt.exitCode || (res.err == nil) != (tt.exitCode == 0) || res.duration <= 0 {
+			t.Errorf("%q: result = %+v, want exit code %d", tt.args, res, tt.exitCode)
+		}
+		if tt.exitCode == 0 && strings.TrimSpace(stdout.String()) == "" {
+			t.Errorf("%q: no output", tt.args)
+		}
+	}
+	if _, err := (execRunner{}).start(procSpec{args: []string{"ite-no-such-program"}}); err == nil {
+		t.Error("starting a missing program succeeded")
+	}
+}
+
+func TestCommandStatus(t *testing.T) {
+	tests := []struct {
+		res     procResult
+		stopped bool
+		want    string
+	}{
+		{procResult{duration: 1500 * time.Millisecond}, false, "Build successful (1.5s)\n"},
+		{procResult{exitCode: 1, duration: time.Second, err: errors.New("exit status 1")}, false, "Build failed: exit status 1 (1s)\n"},
+		{procResult{exitCode: -1, err: errors.New("not found")}, false, "Build failed: not found\n"},
+		{procResult{}, true, "Build stopped\n"},
+	}
+	for _, tt := range tests {
+		if got := commandStatus("build", tt.res, tt.stopped); got != tt.want {
+			t.Errorf("commandStatus(%+v, %v) = %q, want %q", tt.res, tt.stopped, got, tt.want)
+		}
+	}
+}
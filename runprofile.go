@@ -0,0 +1,365 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Run Profiles
+// -------------------------------------------------------------------------
+
+const (
+	runProfilesFileName = "run.json"  // Run profiles inside projectConfigDir
+	defaultRunProfile   = "(default)" // Combobox entry for a plain `go run .`
+)
+
+// runProfile is a named Go Run configuration of a project.
+type runProfile struct {
+	Name string   `json:"name"`
+	Args string   `json:"args"` // Program arguments, split like a shell does
+	Env  []string `json:"env"`  // Extra environment variables, as KEY=VALUE
+	Dir  string   `json:"dir"`  // Working directory, relative to the project root
+}
+
+// runProfiles is the content of the run profiles file.
+type runProfiles struct {
+	Selected string       `json:"selected"` // Profile used by Go Run, "" for the default
+	Profiles []runProfile `json:"profiles"`
+}
+
+// runProfilesPath returns the run profiles file of the project holding the
+// current file, or "" when no file is open.
+func (i *Ite) runProfilesPath() string {
+	if i.currentFile == "" {
+		return ""
+	}
+	return filepath.Join(i.projectDir(), projectConfigDir, runProfilesFileName)
+}
+
+// loadRunProfiles reads the run profiles of the current project. A missing
+// file yields no profiles.
+func (i *Ite) loadRunProfiles() (runProfiles, error) {
+	var rp runProfiles
+	path := i.runProfilesPath()
+	if path == "" {
+		return rp, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rp, nil
+		}
+		return rp, err
+	}
+	err = json.Unmarshal(data, &rp)
+	return rp, err
+}
+
+// storeRunProfiles writes the run profiles of the current project.
+func (i *Ite) storeRunProfiles(rp runProfiles) error {
+	path := i.runProfilesPath()
+	if path == "" {
+		return errors.New(statusNoFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rp, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), configFilePerms)
+}
+
+// profile returns the named profile.
+func (rp runProfiles) profile(name string) (runProfile, bool) {
+	n := slices.IndexFunc(rp.Profiles, func(p runProfile) bool { return p.Name == name })
+	if n < 0 {
+		return runProfile{}, false
+	}
+	return rp.Profiles[n], true
+}
+
+// names returns the entries of the profile dropdown.
+func (rp runProfiles) names() []string {
+	names := []string{defaultRunProfile}
+	for _, p := range rp.Profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// makeRunProfileSelector creates the dropdown choosing the profile used by
+// Go Run.
+func (i *Ite) makeRunProfileSelector(parent *TFrameWidget) *TComboboxWidget {
+	i.runProfileBox = parent.TCombobox(Values([]string{defaultRunProfile}),
+		Width(14), State("readonly"), Textvariable(defaultRunProfile))
+	Bind(i.runProfileBox, "<<ComboboxSelected>>", Command(i.onRunProfileSelected))
+	return i.runProfileBox
+}
+
+// refreshRunProfiles loads the profiles of the current project into the
+// dropdown.
+func (i *Ite) refreshRunProfiles() {
+	rp, err := i.loadRunProfiles()
+	if err != nil {
+		rp = runProfiles{}
+	}
+	i.runProfileBox.Configure(Values(rp.names()))
+	selected := defaultRunProfile
+	if _, ok := rp.profile(rp.Selected); ok {
+		selected = rp.Selected
+	}
+	i.runProfileBox.Configure(Textvariable(selected))
+}
+
+// onRunProfileSelected remembers the profile picked in the dropdown.
+func (i *Ite) onRunProfileSelected() {
+	name := i.runProfileBox.Textvariable()
+	if name == defaultRunProfile {
+		name = ""
+	}
+	rp, err := i.loadRunProfiles()
+	if err != nil {
+		i.showError("Error reading run profiles: " + err.Error())
+		return
+	}
+	if rp.Selected == name {
+		return
+	}
+	rp.Selected = name
+	if err := i.storeRunProfiles(rp); err != nil {
+		i.showError("Error saving run profiles: " + err.Error())
+	}
+}
+
+// runProfileJob sets up the Go Run job for the selected profile, from the
+// project root. Without a working directory it is `go run TARGET ARGS`,
+// the target being the run package of the project; with one, the program
+// is built first, so that it runs in that directory while the build still
+// happens in the project root. Without a profile the arguments are the
+// run arguments of the project.
+func (i *Ite) runProfileJob() (*job, error) {
+	root := i.projectDir()
+	j := &job{c: i.console(consoleRun), decoder: logDecoder{}, dir: root}
+	s, err := i.loadProjectSettings()
+	if err != nil {
+		return nil, fmt.Errorf("reading project settings: %w", err)
+	}
+	target := i.runTarget(s)
+	rp, err := i.loadRunProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("reading run profiles: %w", err)
+	}
+	p, ok := rp.profile(rp.Selected)
+	if !ok {
+		p = runProfile{Name: defaultRunProfile, Args: s.RunArgs}
+	}
+	args, err := splitArgs(p.Args)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+	}
+	j.env = p.Env
+	if p.Dir == "" {
+		run := append(append([]string{"go", "run"}, i.tagsArgs()...), target)
+		j.steps = []jobStep{{name: "run", args: append(run, args...)}}
+		return j, nil
+	}
+
+	dir := p.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	name := filepath.Base(filepath.Join(root, target))
+	bin := filepath.Join(os.TempDir(), "ite-run", name)
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	j.steps = []jobStep{
+		{name: "build", args: append(append([]string{"go", "build", "-o", bin}, i.tagsArgs()...), target), required: true},
+		{name: "run", args: append([]string{bin}, args...), dir: dir},
+	}
+	return j, nil
+}
+
+// splitArgs splits s into arguments at unquoted white space. Single and
+// double quotes group words; a backslash escapes the next character
+// outside single quotes.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in arguments")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// onRunProfiles opens the dialog editing the run profiles of the project:
+// picking a name loads it, Save stores the fields under the typed name and
+// Delete removes it.
+func (i *Ite) onRunProfiles() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	rp, err := i.loadRunProfiles()
+	if err != nil {
+		i.showError("Error reading run profiles: " + err.Error())
+		return
+	}
+
+	dialog := Toplevel()
+	dialog.WmTitle("Run Profiles")
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Sticky(NEWS), Padx(px(10)), Pady(px(10)))
+	GridRowConfigure(dialog, 0, Weight(1))
+	GridColumnConfigure(dialog, 0, Weight(1))
+
+	names := func() []string { return rp.names()[1:] }
+	Grid(frame.TLabel(Txt("Profile:")), Row(0), Column(0), Sticky(W), Pady(px(5)))
+	name := frame.TCombobox(Values(names()), Width(40), Textvariable(rp.Selected))
+	Grid(name, Row(0), Column(1), Sticky(WE), Pady(px(5)))
+	Grid(frame.TLabel(Txt("Arguments:")), Row(1), Column(0), Sticky(W), Pady(px(5)))
+	args := frame.TEntry(Width(40), Textvariable(""))
+	Grid(args, Row(1), Column(1), Sticky(WE), Pady(px(5)))
+	Grid(frame.TLabel(Txt("Working directory:")), Row(2), Column(0), Sticky(W), Pady(px(5)))
+	dir := frame.TEntry(Width(40), Textvariable(""))
+	Grid(dir, Row(2), Column(1), Sticky(WE), Pady(px(5)))
+	browse := frame.TButton(Txt("Browse..."), Command(func() {
+		root := i.projectDir()
+		chosen := ChooseDirectory(Initialdir(root), Parent(dialog))
+		if chosen == "" {
+			return
+		}
+		if rel, err := filepath.Rel(root, chosen); err == nil && !strings.HasPrefix(rel, "..") {
+			chosen = rel
+		}
+		dir.Configure(Textvariable(chosen))
+	}))
+	Grid(browse, Row(2), Column(2), Padx(px(5)))
+	Grid(frame.TLabel(Txt("Environment (KEY=VALUE per line):")), Row(3), Column(0), Columnspan(2), Sticky(W), Pady(px(5)))
+	env := frame.Text(textStyle(), Width(50), Height(8))
+	Grid(env, Row(4), Column(0), Columnspan(3), Sticky(NEWS))
+	GridRowConfigure(frame, 4, Weight(1))
+	GridColumnConfigure(frame, 1, Weight(1))
+
+	load := func() {
+		p, _ := rp.profile(name.Textvariable())
+		args.Configure(Textvariable(p.Args))
+		dir.Configure(Textvariable(p.Dir))
+		env.Delete("1.0", "end")
+		env.Insert("1.0", strings.Join(p.Env, "\n"))
+	}
+	load()
+	Bind(name, "<<ComboboxSelected>>", Command(load))
+
+	store := func() bool {
+		if err := i.storeRunProfiles(rp); err != nil {
+			i.showError("Error saving run profiles: " + err.Error())
+			return false
+		}
+		name.Configure(Values(names()))
+		i.refreshRunProfiles()
+		return true
+	}
+	save := func() {
+		p := runProfile{
+			Name: strings.TrimSpace(name.Textvariable()),
+			Args: args.Textvariable(),
+			Dir:  strings.TrimSpace(dir.Textvariable()),
+		}
+		if p.Name == "" || p.Name == defaultRunProfile {
+			i.showError("Enter a name for the profile.")
+			return
+		}
+		if _, err := splitArgs(p.Args); err != nil {
+			i.showError("Arguments: " + err.Error())
+			return
+		}
+		for _, line := range strings.Split(env.Get("1.0", "end")[0], "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if key, _, ok := strings.Cut(line, "="); !ok || key == "" {
+				i.showError("Invalid environment variable: " + line)
+				return
+			}
+			p.Env = append(p.Env, line)
+		}
+		if n := slices.IndexFunc(rp.Profiles, func(q runProfile) bool { return q.Name == p.Name }); n >= 0 {
+			rp.Profiles[n] = p
+		} else {
+			rp.Profiles = append(rp.Profiles, p)
+		}
+		rp.Selected = p.Name
+		store()
+	}
+	remove := func() {
+		target := name.Textvariable()
+		rp.Profiles = slices.DeleteFunc(rp.Profiles, func(p runProfile) bool { return p.Name == target })
+		if rp.Selected == target {
+			rp.Selected = ""
+		}
+		if store() {
+			name.Configure(Textvariable(""))
+			load()
+		}
+	}
+	closeDialog := func() {
+		Destroy(dialog)
+		Focus(i.editText)
+	}
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(5), Column(0), Columnspan(3), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Save"), Command(save)), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Delete"), Command(remove)), Row(0), Column(1), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Close"), Command(closeDialog)), Row(0), Column(2), Padx(px(5)))
+	Bind(dialog, "<Escape>", Command(closeDialog))
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Display Scaling
+// -------------------------------------------------------------------------
+
+const (
+	basePixelsPerPoint = 96.0 / 72 // Tk scaling of a 96 DPI display, the unscaled size
+	minScale           = 0.5
+	maxScale           = 4.0
+	screenFill         = 0.9 // Largest part of the screen the default window takes
+)
+
+// uiScale is the factor applied to pixel distances: paddings, default
+// sizes and the window geometry.
+var uiScale = 1.0
+
+// px converts a distance in pixels of a 96 DPI display to the display in
+// use.
+func px(n int) int {
+	return int(math.Round(float64(n) * uiScale))
+}
+
+// detectScale guesses the scaling factor of the display. The desktop's own
+// setting wins; otherwise the DPI the window system reports to Tk is used.
+// macOS scales Retina displays by itself.
+func detectScale() float64 {
+	for _, name := range []string{"GDK_SCALE", "QT_SCALE_FACTOR"} {
+		if s, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil && s > 0 {
+			return clampScale(s)
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		return 1
+	}
+	return clampScale(TkScaling() / basePixelsPerPoint)
+}
+
+// clampScale keeps a scaling factor within usable bounds.
+func clampScale(s float64) float64 {
+	return min(max(s, minScale), maxScale)
+}
+
+// applyScale sets the scaling factor: the configured one, or the detected
+// one when it is 0. Fonts are sized in points, so scaling Tk's pixels per
+// point resizes them too.
+func applyScale(configured float64) {
+	if configured > 0 {
+		uiScale = clampScale(configured)
+	} else {
+		uiScale = detectScale()
+	}
+	TkScaling(uiScale * basePixelsPerPoint)
+}
+
+// defaultGeometry returns the initial window size: the default one scaled
+// to the display, shrunk to fit small screens.
+func defaultGeometry() string {
+	w, h, _ := strings.Cut(defaultWindowSize, "x")
+	width, _ := strconv.Atoi(w)
+	height, _ := strconv.Atoi(h)
+	screenW := winfoInt(tclEval("winfo screenwidth ."))
+	screenH := winfoInt(tclEval("winfo screenheight ."))
+	width, height = px(width), px(height)
+	if screenW > 0 && screenH > 0 {
+		width = min(width, int(float64(screenW)*screenFill))
+		height = min(height, int(float64(screenH)*screenFill))
+	}
+	return fmt.Sprintf("%dx%d", width, height)
+}
+
+// onDisplayScaling lets the user override the detected scaling factor.
+// The new factor applies from the next start, when the widgets are built.
+func (i *Ite) onDisplayScaling() {
+	current := "auto"
+	if i.config.Scale > 0 {
+		current = strconv.FormatFloat(i.config.Scale, 'g', -1, 64)
+	}
+	i.promptString("Display Scaling",
+		fmt.Sprintf("Scaling factor, or auto (detected: %.2g):", detectScale()),
+		current,
+		func(value string) {
+			value = strings.TrimSpace(value)
+			scale := 0.0
+			if value != "auto" && value != "" {
+				s, err := strconv.ParseFloat(value, 64)
+				if err != nil || s < minScale || s > maxScale {
+					i.showError(fmt.Sprintf("Invalid scaling factor %q: use auto or a number from %g to %g",
+						value, minScale, maxScale))
+					return
+				}
+				scale = s
+			}
+			i.config.Scale = scale
+			i.saveConfig()
+			i.showStatusHint("Display scaling changes after a restart")
+		})
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Incremental Search
+// -------------------------------------------------------------------------
+
+// The search bar, above the status bar, highlights the matches of what is
+// typed in the buffer while typing and moves the cursor to the first one
+// after where the search started. Return and Shift-Return go to the next
+// and previous matches, wrapping around the buffer. The search ignores case
+// unless the text has upper case letters. Escape cancels the search,
+// putting the cursor back; Control-Return, the close button or clicking
+// the editor end it with the match selected.
+const (
+	tagSearch        = "search"        // Editor tag of the matches of the search bar
+	tagSearchCurrent = "searchcurrent" // Editor tag of the match at the cursor
+	maxSearchMatches = 10000           // Matches highlighted at most
+)
+
+// searchMatch is a match of the search bar, as text indices.
+type searchMatch struct {
+	from, to string
+}
+
+// searchBar is the state of the incremental search.
+type searchBar struct {
+	frame   *TFrameWidget
+	entry   *TEntryWidget
+	count   *TLabelWidget
+	shown   bool
+	text    *TextWidget // Editor searched
+	origin  string      // Cursor when the search started
+	view    string      // First visible fraction when the search started
+	matches []searchMatch
+	current int // Match at the cursor, -1 if none
+}
+
+// configureSearchTags sets up the styles of the search tags.
+func (i *Ite) configureSearchTags() {
+	i.editText.TagConfigure(tagSearch, Background(theme.Selection))
+	i.editText.TagConfigure(tagSearchCurrent, Background(theme.Foreground), Foreground(theme.Text))
+}
+
+// makeSearchBar creates the search bar, the first time it is opened.
+func (i *Ite) makeSearchBar() {
+	s := &searchBar{current: -1}
+	s.frame = TFrame(Padding(px(2)))
+	label := s.frame.TLabel(Txt("Find:"))
+	s.entry = s.frame.TEntry(Width(40), Textvariable(""))
+	s.count = s.frame.TLabel(Txt(""), Width(16))
+	prev := s.frame.TButton(Txt("↑"), Width(3), Command(func() { i.stepSearch(-1) }))
+	next := s.frame.TButton(Txt("↓"), Width(3), Command(func() { i.stepSearch(1) }))
+	done := s.frame.TButton(Txt("✕"), Width(3), Command(func() { i.endSearch(false) }))
+	Grid(label, Row(0), Column(0), Padx(px(2)))
+	Grid(s.entry, Row(0), Column(1), Sticky(WE))
+	Grid(s.count, Row(0), Column(2), Padx(px(4)))
+	Grid(prev, Row(0), Column(3))
+	Grid(next, Row(0), Column(4))
+	Grid(done, Row(0), Column(5), Padx(px(2)))
+	GridColumnConfigure(s.frame, 1, Weight(1))
+
+	Bind(s.entry, "<KeyRelease>", Command(func(e *Event) {
+		switch e.Keysym {
+		case "Return", "KP_Enter", "Escape", "Shift_L", "Shift_R", "Control_L", "Control_R":
+			return
+		}
+		i.updateSearch()
+	}))
+	Bind(s.entry, "<Return>", Command(func(e *Event) {
+		i.stepSearch(1)
+		e.SetReturnCodeBreak()
+	}))
+	Bind(s.entry, "<Shift-Return>", Command(func(e *Event) {
+		i.stepSearch(-1)
+		e.SetReturnCodeBreak()
+	}))
+	Bind(s.entry, "<Control-Return>", Command(func(e *Event) {
+		i.endSearch(false)
+		e.SetReturnCodeBreak()
+	}))
+	Bind(s.entry, "<Escape>", Command(func(e *Event) {
+		i.endSearch(true)
+		e.SetReturnCodeBreak()
+	}))
+	Bind(s.entry, "<FocusOut>", Command(func() {
+		TclAfterIdle(func() {
+			// Clicking the editor ends the search; other windows don't
+			if s.shown && tclEval("focus") == s.text.String() {
+				i.endSearch(false)
+			}
+		})
+	}))
+	i.search = s
+}
+
+// onFind opens the search bar, starting from the selection when it is on
+// one line, or focuses it again when already open.
+func (i *Ite) onFind() {
+	if i.search == nil {
+		i.makeSearchBar()
+	}
+	s := i.search
+	if !s.shown {
+		s.text, s.shown = i.editText, true
+		s.origin = i.editText.Index("insert")
+		s.view, _, _ = strings.Cut(i.editText.Yview(), " ")
+		if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+			if text := i.editText.Get(sel[0], sel[1])[0]; text != "" && !strings.Contains(text, "\n") {
+				s.origin = sel[0]
+				s.entry.Configure(Textvariable(text))
+			}
+		}
+		Grid(s.frame, Row(2), Column(0), Sticky(WE))
+		i.updateSearch()
+	}
+	Focus(s.entry)
+	tclEval("%s selection range 0 end", s.entry)
+	s.entry.Icursor("end")
+}
+
+// updateSearch highlights the matches of the text of the search bar and
+// moves the cursor to the first one after the origin of the search.
+func (i *Ite) updateSearch() {
+	s := i.search
+	s.text.TagRemove(tagSearch, "1.0", "end")
+	s.text.TagRemove(tagSearchCurrent, "1.0", "end")
+	s.matches, s.current = findMatches(s.text, s.entry.Textvariable()), -1
+	s.entry.Configure(Foreground(theme.Foreground))
+	if len(s.matches) == 0 {
+		s.text.MarkSet("insert", s.origin)
+		s.text.Yviewmoveto(s.view)
+		if s.entry.Textvariable() != "" {
+			s.entry.Configure(Foreground(theme.Error))
+			s.count.Configure(Txt("No matches"))
+		} else {
+			s.count.Configure(Txt(""))
+		}
+		i.refreshCursorState()
+		return
+	}
+	for _, m := range s.matches {
+		s.text.TagAdd(tagSearch, m.from, m.to)
+	}
+	current := 0
+	for n, m := range s.matches {
+		if !indexLess(s.text, m.from, s.origin) {
+			current = n
+			break
+		}
+	}
+	i.showSearchMatch(current, false)
+}
+
+// stepSearch goes to the next match when dir is 1, or to the previous one
+// when it is -1, wrapping around the buffer.
+func (i *Ite) stepSearch(dir int) {
+	s := i.search
+	if s == nil || !s.shown || len(s.matches) == 0 {
+		return
+	}
+	n := s.current + dir
+	wrapped := n < 0 || n >= len(s.matches)
+	i.showSearchMatch((n+len(s.matches))%len(s.matches), wrapped)
+}
+
+// showSearchMatch makes match n the current one and moves the cursor to
+// it.
+func (i *Ite) showSearchMatch(n int, wrapped bool) {
+	s := i.search
+	if s.current >= 0 {
+		m := s.matches[s.current]
+		s.text.TagRemove(tagSearchCurrent, m.from, m.to)
+	}
+	s.current = n
+	m := s.matches[n]
+	s.text.TagAdd(tagSearchCurrent, m.from, m.to)
+	s.text.MarkSet("insert", m.from)
+	s.text.See(m.to)
+	s.text.See(m.from)
+	total := fmt.Sprint(len(s.matches))
+	if len(s.matches) >= maxSearchMatches {
+		total += "+"
+	}
+	count := fmt.Sprintf("%d of %s", n+1, total)
+	if wrapped {
+		count += " (wrapped)"
+	}
+	s.count.Configure(Txt(count))
+	i.refreshCursorState()
+}
+
+// endSearch hides the search bar and clears its highlights. Cancelled, it
+// puts the cursor and the view back where they were; otherwise it selects
+// the current match.
+func (i *Ite) endSearch(cancel bool) {
+	s := i.search
+	if s == nil || !s.shown {
+		return
+	}
+	s.shown = false
+	GridRemove(s.frame.Window)
+	s.text.TagRemove(tagSearch, "1.0", "end")
+	s.text.TagRemove(tagSearchCurrent, "1.0", "end")
+	if cancel {
+		s.text.MarkSet("insert", s.origin)
+		s.text.Yviewmoveto(s.view)
+	} else if s.current >= 0 {
+		m := s.matches[s.current]
+		s.text.TagRemove("sel", "1.0", "end")
+		s.text.TagAdd("sel", m.from, m.to)
+	}
+	s.matches = nil
+	Focus(s.text)
+	i.refreshCursorState()
+}
+
+// findMatches returns the matches of query in text, ignoring case unless
+// query has upper case letters.
+func findMatches(text *TextWidget, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	pattern := regexp.QuoteMeta(query)
+	if !strings.ContainsFunc(query, unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+	re := regexp.MustCompile(pattern)
+	var matches []searchMatch
+	for n, line := range strings.Split(text.Text(), "\n") {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if len(matches) >= maxSearchMatches {
+				return matches
+			}
+			matches = append(matches, searchMatch{
+				from: fmt.Sprintf("%d.%d", n+1, runeColumn(line, loc[0])),
+				to:   fmt.Sprintf("%d.%d", n+1, runeColumn(line, loc[1])),
+			})
+		}
+	}
+	return matches
+}
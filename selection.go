@@ -0,0 +1,268 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Mouse Selection
+// -------------------------------------------------------------------------
+
+// bindMouseSelection replaces the default multi-click bindings of the
+// editor: double-click selects a Go identifier, triple-click a line,
+// quadruple-click a block of non-blank lines, and Shift-click extends the
+// current selection.
+func (i *Ite) bindMouseSelection() {
+	Bind(i.editText, "<Double-Button-1>", Command(i.onDoubleClick))
+	Bind(i.editText, "<Triple-Button-1>", Command(i.onTripleClick))
+	Bind(i.editText, "<Quadruple-Button-1>", Command(i.onQuadrupleClick))
+	Bind(i.editText, "<Shift-Button-1>", Command(i.onShiftClick))
+
+	// Word-wise cursor movement honoring the same word characters
+	for key, motion := range map[string]struct{ forward, extend bool }{
+		"<Control-Right>":       {true, false},
+		"<Control-Left>":        {false, false},
+		"<Control-Shift-Right>": {true, true},
+		"<Control-Shift-Left>":  {false, true},
+	} {
+		Bind(i.editText, key, Command(func(e *Event) {
+			i.moveWord(motion.forward, motion.extend)
+			e.SetReturnCodeBreak()
+		}))
+	}
+}
+
+// onDoubleClick selects the word under the mouse pointer.
+func (i *Ite) onDoubleClick(e *Event) {
+	line, col := parseIndex(mouseIndex(i.editText, e))
+	runes := []rune(lineText(i.editText, line))
+	start, end := wordBounds(runes, col, i.isWordChar)
+	if start == end && end < len(runes) {
+		end++ // Not on a word: select the single character
+	}
+	i.selectRange(fmt.Sprintf("%d.%d", line, start), fmt.Sprintf("%d.%d", line, end))
+	e.SetReturnCodeBreak()
+}
+
+// onTripleClick selects the whole line under the mouse pointer,
+// including its newline.
+func (i *Ite) onTripleClick(e *Event) {
+	line, _ := parseIndex(mouseIndex(i.editText, e))
+	i.selectRange(fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.0", line+1))
+	e.SetReturnCodeBreak()
+}
+
+// onQuadrupleClick selects the paragraph under the mouse pointer: the run of
+// non-blank lines around it, which in code is usually a statement block.
+func (i *Ite) onQuadrupleClick(e *Event) {
+	line, _ := parseIndex(mouseIndex(i.editText, e))
+	last, _ := parseIndex(i.editText.Index("end-1c"))
+	first := line
+	for first > 1 && strings.TrimSpace(lineText(i.editText, first-1)) != "" {
+		first--
+	}
+	for line < last && strings.TrimSpace(lineText(i.editText, line+1)) != "" {
+		line++
+	}
+	i.selectRange(fmt.Sprintf("%d.0", first), fmt.Sprintf("%d.0", line+1))
+	e.SetReturnCodeBreak()
+}
+
+// onShiftClick extends the selection, or the cursor position when nothing is
+// selected, up to the mouse pointer.
+func (i *Ite) onShiftClick(e *Event) {
+	Focus(i.editText)
+	idx := mouseIndex(i.editText, e)
+	anchor := i.editText.Index("insert")
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		// Keep the end of the selection farthest from the click fixed
+		if indexLess(i.editText, idx, sel[0]) {
+			anchor = sel[len(sel)-1]
+		} else {
+			anchor = sel[0]
+		}
+	}
+	if indexLess(i.editText, idx, anchor) {
+		i.selectRange(idx, anchor)
+		i.editText.MarkSet("insert", idx)
+	} else {
+		i.selectRange(anchor, idx)
+	}
+	e.SetReturnCodeBreak()
+}
+
+// moveWord moves the cursor to the next or previous word boundary. With
+// extend set, the selection grows or shrinks along with the cursor.
+func (i *Ite) moveWord(forward, extend bool) {
+	insert := i.editText.Index("insert")
+	target := i.wordMotionIndex(insert, forward)
+	sel := i.editText.TagRanges("sel")
+	i.editText.TagRemove("sel", "1.0", "end")
+	if extend {
+		anchor := insert
+		if len(sel) >= 2 {
+			// The anchor is the end of the selection the cursor is not on
+			anchor = sel[0]
+			if sel[0] == insert {
+				anchor = sel[len(sel)-1]
+			}
+		}
+		if indexLess(i.editText, target, anchor) {
+			i.editText.TagAdd("sel", target, anchor)
+		} else {
+			i.editText.TagAdd("sel", anchor, target)
+		}
+	}
+	i.editText.MarkSet("insert", target)
+	i.editText.See("insert")
+}
+
+// wordMotionIndex returns the index reached from index by skipping to the
+// end of the next word (forward) or the start of the previous one.
+// At a line boundary the motion steps onto the adjacent line.
+func (i *Ite) wordMotionIndex(index string, forward bool) string {
+	line, col := parseIndex(index)
+	runes := []rune(lineText(i.editText, line))
+	if forward {
+		if col >= len(runes) {
+			return i.editText.Index(fmt.Sprintf("%d.0 +1 lines", line))
+		}
+		for col < len(runes) && !i.isWordChar(runes[col]) {
+			col++
+		}
+		for col < len(runes) && i.isWordChar(runes[col]) {
+			col++
+		}
+	} else {
+		if col == 0 {
+			return i.editText.Index(fmt.Sprintf("%d.0 -1 lines lineend", line))
+		}
+		for col > 0 && !i.isWordChar(runes[col-1]) {
+			col--
+		}
+		for col > 0 && i.isWordChar(runes[col-1]) {
+			col--
+		}
+	}
+	return fmt.Sprintf("%d.%d", line, col)
+}
+
+// onWordChars lets the user choose the extra characters that count as part
+// of a word, e.g. "-" for kebab-case keys or "." for selector chains.
+func (i *Ite) onWordChars() {
+	i.promptString("Word Characters",
+		"Extra word characters besides letters, digits and _:",
+		i.config.WordChars,
+		func(chars string) {
+			i.config.WordChars = chars
+			i.saveConfig()
+		})
+}
+
+// selectRange replaces the selection with the range from..to and moves the
+// cursor to its end.
+func (i *Ite) selectRange(from, to string) {
+	i.editText.TagRemove("sel", "1.0", "end")
+	i.editText.TagAdd("sel", from, to)
+	i.editText.MarkSet("insert", to)
+	i.refreshCursorState()
+}
+
+// -------------------------------------------------------------------------
+// Text Index Helpers
+// -------------------------------------------------------------------------
+
+// isWordChar reports whether r can be part of a word: a Go identifier
+// character or one of the extra characters configured by the user.
+func (i *Ite) isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) ||
+		strings.ContainsRune(i.config.WordChars, r)
+}
+
+// wordBounds returns the character range of the word containing col.
+// The range is empty when col is not on a word character.
+func wordBounds(runes []rune, col int, isWord func(rune) bool) (start, end int) {
+	col = min(max(col, 0), len(runes))
+	start, end = col, col
+	for start > 0 && isWord(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWord(runes[end]) {
+		end++
+	}
+	return start, end
+}
+
+// parseIndex splits a normalized "line.column" text index.
+func parseIndex(index string) (line, col int) {
+	l, c, _ := strings.Cut(index, ".")
+	line, _ = strconv.Atoi(l)
+	col, _ = strconv.Atoi(c)
+	return line, col
+}
+
+// lineText returns the contents of a line without its newline.
+func lineText(text *TextWidget, line int) string {
+	return text.Get(fmt.Sprintf("%d.0", line), fmt.Sprintf("%d.end", line))[0]
+}
+
+// runeColumn converts a byte offset into line to a character column.
+func runeColumn(line string, offset int) int {
+	return utf8.RuneCountInString(line[:min(max(offset, 0), len(line))])
+}
+
+// mouseIndex returns the text index under the mouse pointer of e.
+func mouseIndex(text *TextWidget, e *Event) string {
+	return text.Index(fmt.Sprintf("@%d,%d", e.X, e.Y))
+}
+
+// indexLess reports whether index a comes before index b.
+func indexLess(text *TextWidget, a, b string) bool {
+	return textCount(text, Indices(), a, b) > 0
+}
+
+// textCount counts the items selected by what (Chars, Lines, Ypixels, ...)
+// between two indices. The result is negative when from is after to.
+func textCount(text *TextWidget, what Opt, from, to string) int {
+	res := text.Count(what, from, to)
+	if len(res) == 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(res[0])
+	return n
+}
+
+// selectionInfo describes the current selection as "N chars, M lines
+// selected", the ranges of a block selection summed, followed by the
+// number of carets of a block when there are several, or returns "" when
+// there is nothing to tell.
+func selectionInfo(text *TextWidget, carets int) string {
+	sel := text.TagRanges("sel")
+	var info []string
+	if len(sel) >= 2 {
+		chars, lines := 0, 0
+		for n := 0; n+1 < len(sel); n += 2 {
+			chars += textCount(text, Chars(), sel[n], sel[n+1])
+			startLine, _ := parseIndex(sel[n])
+			endLine, endCol := parseIndex(sel[n+1])
+			lines += endLine - startLine + 1
+			if endCol == 0 && endLine > startLine {
+				lines-- // A selection ending at column 0 does not cover that line
+			}
+		}
+		info = append(info, fmt.Sprintf("%d chars, %d lines selected", chars, lines))
+	}
+	if carets > 1 {
+		info = append(info, fmt.Sprintf("%d carets", carets))
+	}
+	return strings.Join(info, ", ")
+}
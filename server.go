@@ -0,0 +1,112 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Server Detection
+// -------------------------------------------------------------------------
+
+const (
+	tagBrowser  = "browser" // Console tag of "Open in Browser" links
+	browserLink = "[Open in Browser]"
+)
+
+// listenRe matches the messages servers commonly print once they accept
+// connections, e.g. "listening on :8080", "Serving on http://127.0.0.1:3000"
+// or "server started at [::]:8443", capturing the address.
+var listenRe = regexp.MustCompile(`(?i)\b(?:listen(?:ing)?|serving|started|running)\b.*?\b(?:on|at)?\s*((?:https?://)?(?:[\w.-]*|\[[0-9a-f:]*\]):\d{2,5})\b`)
+
+// serverURL returns the URL of the server announced by line, or "".
+// Wildcard hosts are replaced by localhost.
+func serverURL(line string) string {
+	m := listenRe.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	addr := m[1]
+	scheme := "http://"
+	if s, rest, ok := strings.Cut(addr, "://"); ok {
+		scheme, addr = s+"://", rest
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	switch host {
+	case "", "0.0.0.0", "::", "[::]":
+		host = "localhost"
+	}
+	return scheme + net.JoinHostPort(host, port) + "/"
+}
+
+// serverMessages returns the console messages announcing a server found in
+// line: a link opening it in the browser.
+func serverMessages(line string) []consoleMsg {
+	url := serverURL(line)
+	if url == "" {
+		return nil
+	}
+	return []consoleMsg{{
+		text:  browserLink + " " + url + "\n",
+		tag:   tagBrowser,
+		click: func() { openBrowser(url) },
+		url:   url,
+	}}
+}
+
+// configureServerTags sets up the console tag of browser links.
+func (i *Ite) configureServerTags(c *console) {
+	c.text.TagConfigure(tagBrowser, Foreground(theme.Link), Underline(1))
+	c.text.TagBind(tagBrowser, "<Button-1>", func() { i.onConsoleClick(c) })
+	c.text.TagBind(tagBrowser, "<Enter>", func() {
+		c.text.Configure(Cursor("hand2"))
+	})
+	c.text.TagBind(tagBrowser, "<Leave>", func() {
+		c.text.Configure(Cursor("xterm"))
+	})
+}
+
+// setServerBadge shows the address of the running server in the status
+// bar, or hides the badge when url is "".
+func (i *Ite) setServerBadge(url string) {
+	i.serverURL = url
+	if url == "" {
+		i.statusLabelServer.Configure(Txt(""))
+		return
+	}
+	i.statusLabelServer.Configure(Txt("● " + strings.TrimSuffix(url, "/")))
+}
+
+// onServerBadgeClick opens the running server in the browser.
+func (i *Ite) onServerBadgeClick() {
+	if i.serverURL != "" {
+		openBrowser(i.serverURL)
+	}
+}
+
+// openBrowser opens url in the default web browser.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
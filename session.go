@@ -0,0 +1,72 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// -------------------------------------------------------------------------
+// Session Store
+// -------------------------------------------------------------------------
+
+const sessionFileName = "session.json" // Session file inside configDirName
+
+// session holds the state ITE keeps about files between runs, as opposed
+// to the preferences of Config.
+type session struct {
+	Bookmarks map[string][]int `json:"bookmarks"`         // Bookmarked lines, by file path
+	Project   string           `json:"project,omitempty"` // Folder opened as project, "" for none
+}
+
+// sessionPath returns the absolute path of the session file.
+func sessionPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionFileName), nil
+}
+
+// loadSession reads the session file. A missing file yields an empty
+// session.
+func loadSession() (*session, error) {
+	s := &session{Bookmarks: make(map[string][]int)}
+	path, err := sessionPath()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return &session{Bookmarks: make(map[string][]int)}, err
+	}
+	if s.Bookmarks == nil {
+		s.Bookmarks = make(map[string][]int)
+	}
+	return s, nil
+}
+
+// save writes the session file, creating the config directory if needed.
+func (s *session) save() error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), configDirPerms); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), configFilePerms)
+}
@@ -0,0 +1,297 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Settings Archive
+// -------------------------------------------------------------------------
+
+// Export Settings writes the preferences to a zip archive: the settings,
+// which hold the theme, the snippets and the editor options, the key
+// bindings and the last macro. Import Settings replaces them with those of
+// such an archive, on another machine say, and Reset Settings with the
+// defaults. The settings of the machine, such as the DSN, the window, the
+// directories and the tools found there, are neither exported nor
+// replaced; sessions, journals and plugins stay out too. Import and Reset
+// first save the preferences they replace to settingsBackupName in the
+// config dir, so they can be undone by importing it.
+const (
+	settingsExtension   = ".zip"
+	settingsBackupName  = "settings-backup.zip" // Settings before the last import or reset, in the config dir
+	maxSettingsFileSize = 16 << 20              // Largest file read from an archive
+)
+
+// settingsFiles are the files of the config dir an archive holds.
+var settingsFiles = []string{configFileName, keysFileName, macroFileName}
+
+// keepMachineSettings sets the settings of dst belonging to the machine
+// to those of src.
+func keepMachineSettings(dst, src *Config) {
+	dst.DatabaseDSN, dst.Window, dst.Scale = src.DatabaseDSN, src.Window, src.Scale
+	dst.DefaultDir, dst.LastDialogDir = src.DefaultDir, src.LastDialogDir
+	dst.ToolPaths, dst.SpellDictionary = src.ToolPaths, src.SpellDictionary
+}
+
+// portableConfig returns cfg without the settings of the machine.
+func portableConfig(cfg *Config) *Config {
+	c := *cfg
+	keepMachineSettings(&c, &Config{})
+	return &c
+}
+
+// writeSettingsArchive writes the portable settings of cfg and the other
+// settings files of dir to w as a zip archive, leaving out those missing.
+func writeSettingsArchive(w io.Writer, dir string, cfg *Config) error {
+	zw := zip.NewWriter(w)
+	for _, name := range settingsFiles {
+		var data []byte
+		if name == configFileName {
+			data = append([]byte(configHeader), marshalTOML(portableConfig(cfg))...)
+		} else {
+			var err error
+			data, err = os.ReadFile(filepath.Join(dir, name))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// readSettingsArchive returns the portable settings of the zip archive at
+// path, the defaults for those missing, and its other settings files by
+// name, checking that they can be read. Other files are ignored.
+func readSettingsArchive(path string) (*Config, map[string][]byte, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zr.Close()
+	cfg, found := defaultConfig(), false
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		if !slices.Contains(settingsFiles, f.Name) {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(r, maxSettingsFileSize+1))
+		r.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if len(data) > maxSettingsFileSize {
+			return nil, nil, fmt.Errorf("%s: file too large", f.Name)
+		}
+		found = true
+		if f.Name == configFileName {
+			if err := unmarshalTOML(data, cfg); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", configFileName, err)
+			}
+			continue
+		}
+		if !json.Valid(data) {
+			return nil, nil, fmt.Errorf("%s: invalid JSON", f.Name)
+		}
+		files[f.Name] = data
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("no settings in %s", filepath.Base(path))
+	}
+	return portableConfig(cfg), files, nil
+}
+
+// replaceSettings saves the settings current and the other settings files
+// of dir to the backup archive, then replaces them with cfg and files,
+// removing those files leaves out.
+func replaceSettings(dir string, current, cfg *Config, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, configDirPerms); err != nil {
+		return err
+	}
+	var backup bytes.Buffer
+	if err := writeSettingsArchive(&backup, dir, current); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, settingsBackupName), backup.Bytes(), configFilePerms); err != nil {
+		return err
+	}
+	if err := cfg.saveTo(filepath.Join(dir, configFileName)); err != nil {
+		return err
+	}
+	for _, name := range settingsFiles {
+		if name == configFileName {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, ok := files[name]
+		if !ok {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := writeFileAtomic(path, data, configFilePerms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onExportSettings writes the current settings to an archive chosen by
+// the user.
+func (i *Ite) onExportSettings() {
+	dir, err := configDir()
+	if err != nil {
+		i.showError("Error locating settings: " + err.Error())
+		return
+	}
+	path := GetSaveFile(Title("Export Settings..."), Initialdir(i.defaultDir()),
+		Initialfile("ite-settings"+settingsExtension), Filetypes([]FileType{
+			{TypeName: "Zip Archives", Extensions: []string{"*" + settingsExtension}, MacType: ""},
+			{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
+		}))
+	if path == "" {
+		return
+	}
+	i.rememberDialogDir(path)
+	if filepath.Ext(path) == "" {
+		path += settingsExtension
+	}
+	var buf bytes.Buffer
+	if err := writeSettingsArchive(&buf, dir, i.config); err != nil {
+		i.showError("Error exporting settings: " + err.Error())
+		return
+	}
+	if err := os.WriteFile(path, buf.Bytes(), configFilePerms); err != nil {
+		i.showError("Error exporting settings: " + err.Error())
+		return
+	}
+	i.showStatusHint("Exported " + filepath.Base(path))
+}
+
+// onImportSettings replaces the settings with those of an archive chosen
+// by the user, once confirmed.
+func (i *Ite) onImportSettings() {
+	paths := GetOpenFile(Title("Import Settings..."), Initialdir(i.defaultDir()), Filetypes([]FileType{
+		{TypeName: "Zip Archives", Extensions: []string{"*" + settingsExtension}, MacType: ""},
+		{TypeName: "All Files", Extensions: []string{"*"}, MacType: ""},
+	}))
+	if len(paths) == 0 || paths[0] == "" {
+		return
+	}
+	i.rememberDialogDir(paths[0])
+	cfg, files, err := readSettingsArchive(paths[0])
+	if err != nil {
+		i.showError("Error importing settings: " + err.Error())
+		return
+	}
+	answer := messageBox(Icon("warning"), Title("Import Settings"), Type("yesno"),
+		Msg(tr("Replace the settings, key bindings and macro with those of")+" "+filepath.Base(paths[0])+"?"))
+	if answer != "yes" {
+		return
+	}
+	i.restoreSettings(cfg, files, "Settings imported: some apply at the next start")
+}
+
+// onResetSettings restores the default settings, key bindings and macro,
+// once confirmed.
+func (i *Ite) onResetSettings() {
+	answer := messageBox(Icon("warning"), Title("Reset Settings"), Type("yesno"),
+		Msg(tr("Restore the default settings, key bindings and macro?")))
+	if answer != "yes" {
+		return
+	}
+	i.restoreSettings(defaultConfig(), nil, "Settings reset: some apply at the next start")
+}
+
+// restoreSettings replaces the settings with cfg, but for those of the
+// machine, and the other settings files with files, then applies them,
+// telling the user with hint.
+func (i *Ite) restoreSettings(cfg *Config, files map[string][]byte, hint string) {
+	dir, err := configDir()
+	if err != nil {
+		i.showError("Error locating settings: " + err.Error())
+		return
+	}
+	keepMachineSettings(cfg, i.config)
+	if err := replaceSettings(dir, i.config, cfg, files); err != nil {
+		i.showError("Error restoring settings: " + err.Error())
+		return
+	}
+	i.applySettings(cfg)
+	i.showStatusHint(hint)
+}
+
+// applySettings makes cfg the settings and updates the open windows and
+// the menus to them. The key bindings, the language and the scaling apply
+// from the next start.
+func (i *Ite) applySettings(cfg *Config) {
+	i.config = cfg
+	i.applyPreferences()
+	i.setModalEditing(cfg.ModalEditing)
+	i.applyTheme()
+	for _, c := range []struct {
+		v  *VariableOpt
+		on bool
+	}{
+		{i.linkedVar, cfg.LinkedEditing},
+		{i.autoPairsVar, cfg.AutoClosePairs},
+		{i.codeHintsVar, cfg.CodeHints},
+		{i.completionVar, cfg.PathCompletion},
+		{i.autospacingVar, cfg.Autospacing},
+		{i.typewriterVar, cfg.TypewriterScrolling},
+		{i.darkThemeVar, cfg.Theme == darkTheme.Name},
+		{i.relativePathsVar, cfg.RelativePaths},
+		{i.outlineVar, cfg.ShowOutline},
+		{i.stickyVar, cfg.StickyScroll},
+		{i.lensVar, cfg.InlineDiagnostics},
+		{i.spellCheckVar, cfg.SpellCheck},
+		{i.useTrashVar, cfg.UseTrash},
+		{i.gracefulStopVar, cfg.GracefulStop},
+		{i.uncachedVar, cfg.UncachedTests},
+		{i.offlineVar, cfg.WorkOffline},
+		{i.buildUnsavedVar, cfg.BuildUnsaved},
+		{i.vendorVar, cfg.VendorMode},
+		{i.apiVar, cfg.ExternalAPI},
+	} {
+		c.v.Set(checkValue(c.on))
+	}
+	for _, p := range i.plugins {
+		if p.allowed != nil {
+			p.allowed.Set(checkValue(slices.Contains(cfg.CompletionPlugins, p.name)))
+		}
+	}
+	i.updateProviderStatus()
+	i.updateStickyScroll()
+	if cfg.ExternalAPI && i.api == nil {
+		i.startAPI()
+	} else if !cfg.ExternalAPI && i.api != nil {
+		i.stopAPI()
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// First-Run Setup
+// -------------------------------------------------------------------------
+
+const (
+	minFontSize = 8
+	maxFontSize = 32
+)
+
+// setupTool is an external program checked by the setup wizard.
+type setupTool struct {
+	name     string
+	required bool
+	purpose  string // What ITE needs the tool for
+}
+
+// setupTools lists the programs ITE runs.
+var setupTools = []setupTool{
+	{"go", true, "build, run and test"},
+	{"gofmt", true, "formatting"},
+	{"gopls", false, "Go to Definition, signature help and hover"},
+}
+
+// runSetupWizard asks for the basic preferences on the first launch,
+// before the main window is built, and checks that the Go tools are on
+// the PATH. The choices, or the defaults if the wizard is dismissed, are
+// written to the new config file so that it doesn't show again.
+func (i *Ite) runSetupWizard() {
+	WmWithdraw(App)
+	defer WmDeiconify(App)
+
+	dialog := Toplevel()
+	dialog.WmTitle("Welcome to ITE")
+	frame := dialog.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(15)), Pady(px(15)))
+	Grid(frame.TLabel(Txt("Choose your preferences. You can change them later in the settings.")),
+		Row(0), Column(0), Columnspan(2), Sticky(W), Pady(px(5)))
+
+	Grid(frame.TLabel(Txt("Theme:")), Row(1), Column(0), Sticky(W), Pady(px(5)))
+	themeBox := frame.TCombobox(Values([]string{lightTheme.Name, darkTheme.Name}),
+		State("readonly"), Textvariable(i.config.Theme))
+	Grid(themeBox, Row(1), Column(1), Sticky(W), Pady(px(5)))
+
+	Grid(frame.TLabel(Txt("Font size:")), Row(2), Column(0), Sticky(W), Pady(px(5)))
+	sizeBox := frame.TSpinbox(From(minFontSize), To(maxFontSize), Increment(1), Width(5),
+		Textvariable(strconv.Itoa(i.config.FontSize)))
+	Grid(sizeBox, Row(2), Column(1), Sticky(W), Pady(px(5)))
+
+	Grid(frame.TLabel(Txt("Key bindings:")), Row(3), Column(0), Sticky(W), Pady(px(5)))
+	keysBox := frame.TCombobox(Values([]string{keyPresetDefault, keyPresetVim, keyPresetEmacs}),
+		State("readonly"), Textvariable(i.config.KeyPreset))
+	Grid(keysBox, Row(3), Column(1), Sticky(W), Pady(px(5)))
+
+	Grid(frame.TLabel(Txt("Go tools:")), Row(4), Column(0), Sticky("nw"), Pady(px(5)))
+	tools := frame.TFrame()
+	Grid(tools, Row(4), Column(1), Sticky(W), Pady(px(5)))
+	paths := make(map[string]string)
+	for n, tool := range setupTools {
+		path, err := exec.LookPath(tool.name)
+		status, color := "✓ "+path, lightTheme.Success
+		switch {
+		case err == nil:
+			paths[tool.name] = path
+		case tool.required:
+			status, color = "✗ not found on PATH (required for "+tool.purpose+")", lightTheme.Error
+		default:
+			status, color = "– not found (optional, used for "+tool.purpose+")", lightTheme.Muted
+		}
+		Grid(tools.TLabel(Txt(tool.name)), Row(n), Column(0), Sticky(W), Padx(px(5)))
+		Grid(tools.TLabel(Txt(status), Foreground(color)), Row(n), Column(1), Sticky(W))
+	}
+
+	finish := func(apply bool) {
+		if apply {
+			i.config.Theme = themeBox.Textvariable()
+			if size, err := strconv.Atoi(sizeBox.Textvariable()); err == nil {
+				i.config.FontSize = min(max(size, minFontSize), maxFontSize)
+			}
+			i.config.KeyPreset = keysBox.Textvariable()
+		}
+		i.config.ToolPaths = paths
+		if err := i.config.save(); err != nil {
+			fmt.Fprintf(os.Stderr, "ite: saving config: %v\n", err)
+		}
+		Destroy(dialog)
+	}
+
+	btnFrame := frame.TFrame()
+	Grid(btnFrame, Row(5), Column(0), Columnspan(2), Pady(px(10)))
+	Grid(btnFrame.TButton(Txt("Start"), Command(func() { finish(true) })), Row(0), Column(0), Padx(px(5)))
+	Grid(btnFrame.TButton(Txt("Use Defaults"), Command(func() { finish(false) })), Row(0), Column(1), Padx(px(5)))
+	WmProtocol(dialog.Window, "WM_DELETE_WINDOW", func() { finish(false) })
+	Bind(dialog, "<Return>", Command(func() { finish(true) }))
+
+	tclEval("tkwait window %s", dialog)
+}
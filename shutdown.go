@@ -0,0 +1,54 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// -------------------------------------------------------------------------
+// Shutdown
+// -------------------------------------------------------------------------
+
+// shutdownTimeout is how long quitting waits for the background work to
+// end once told to stop.
+const shutdownTimeout = 3 * time.Second
+
+// goBackground runs f in a goroutine that quitting stops and waits for:
+// f should return soon after ctx is canceled.
+func (i *Ite) goBackground(f func(ctx context.Context)) {
+	i.background.Go(func() { f(i.quitCtx) })
+}
+
+// shutdown stops the background work on quit. It cancels the file index
+// and the searches, drops the queued commands and kills the running ones
+// together with the processes they spawned, so that a long go test isn't
+// left behind, then waits up to shutdownTimeout for the goroutines to end.
+func (i *Ite) shutdown() {
+	i.cancelBackground()
+	i.outMu.Lock()
+	i.outCond.Broadcast() // Commands waiting for the consoles may go on
+	i.outMu.Unlock()
+	i.jobQueue = nil
+	for _, c := range i.consoles {
+		c.queued = nil
+		if err := i.stopCommand(c); err != nil {
+			fmt.Fprintf(os.Stderr, "ite: stopping %s: %v\n", c.name, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		i.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		fmt.Fprintf(os.Stderr, "ite: background work still running after %v, quitting anyway\n", shutdownTimeout)
+	}
+}
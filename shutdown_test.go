@@ -0,0 +1,42 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBareIte returns an editor without widgets, enough for the background
+// work.
+func newBareIte() *Ite {
+	i := &Ite{jobs: make(map[int]*job)}
+	i.outCond = sync.NewCond(&i.outMu)
+	i.quitCtx, i.cancelBackground = context.WithCancel(context.Background())
+	return i
+}
+
+func TestShutdown(t *testing.T) {
+	i := newBareIte()
+	var stopped atomic.Bool
+	i.goBackground(func(ctx context.Context) {
+		<-ctx.Done()
+		stopped.Store(true)
+	})
+	// A command blocked on the full console backlog must not hold the quit
+	i.consoleOut = make([]consoleMsg, consoleBacklog)
+	i.goBackground(func(context.Context) { i.sendConsole(consoleMsg{text: "late\n"}) })
+
+	start := time.Now()
+	i.shutdown()
+	if !stopped.Load() {
+		t.Error("background work not canceled")
+	}
+	if took := time.Since(start); took >= shutdownTimeout {
+		t.Errorf("shutdown took %v, the whole timeout", took)
+	}
+}
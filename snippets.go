@@ -0,0 +1,341 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Snippets
+// -------------------------------------------------------------------------
+
+// A snippet is expanded by typing its abbreviation and pressing Tab. In
+// its body, $1, $2, ... are tab stops and ${1:text} a tab stop holding
+// placeholder text; Tab and Shift-Tab cycle through them in order, and
+// the session ends at $0, or at the end of the snippet without one. A
+// number used again mirrors the text typed at its first use. A tab
+// in the body is one indentation level, every line after the first gets
+// the indentation of the cursor line, and $$ is a literal dollar sign.
+const (
+	snippetBindTag = "IteSnippet" // Bind tag of the keys expanding and cycling snippets
+	snippetMark    = "snippet"    // Prefix of the names of the tab stop marks
+)
+
+// defaultSnippets are the snippets available without configuration. The
+// Snippets of the config add to them or replace them, by abbreviation.
+var defaultSnippets = map[string]string{
+	"iferr":  "if err != nil {\n\treturn ${1:err}\n}$0",
+	"ife":    "if ${1:cond} {\n\t$2\n} else {\n\t$0\n}",
+	"fori":   "for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}",
+	"forr":   "for ${1:_}, ${2:v} := range ${3:xs} {\n\t$0\n}",
+	"func":   "func ${1:name}($2) $3 {\n\t$0\n}",
+	"meth":   "func (${1:r} ${2:*T}) ${3:name}($4) $5 {\n\t$0\n}",
+	"st":     "type ${1:Name} struct {\n\t$0\n}",
+	"inter":  "type ${1:Name} interface {\n\t$0\n}",
+	"sw":     "switch ${1:x} {\ncase ${2:value}:\n\t$0\n}",
+	"sel":    "select {\ncase ${1:v} := <-${2:ch}:\n\t$0\n}",
+	"go":     "go func() {\n\t$0\n}()",
+	"defer":  "defer func() {\n\t$0\n}()",
+	"main":   "func main() {\n\t$0\n}",
+	"test":   "func Test${1:Name}(t *testing.T) {\n\t$0\n}",
+	"bench":  "func Benchmark${1:Name}(b *testing.B) {\n\tfor b.Loop() {\n\t\t$0\n\t}\n}",
+	"errorf": "fmt.Errorf(\"${1:%w}\", ${2:err})$0",
+	"pf":     "fmt.Printf(\"${1:%v}\\n\", $2)$0",
+	"pl":     "fmt.Println($1)$0",
+}
+
+// snippetStop is a tab stop of an expanded snippet, as rune offsets into
+// its text.
+type snippetStop struct {
+	n          int // Number of the stop, 0 for the final one
+	start, end int
+}
+
+// snippetEdit is the active snippet session: the cursor cycles through the
+// tab stops, delimited by pairs of marks, until it reaches the last one.
+type snippetEdit struct {
+	stops   []int // Numbers of the tab stops, nil when no session is active
+	current int   // Index of the tab stop holding the cursor
+}
+
+// snippets returns the snippets in effect, by abbreviation.
+func (i *Ite) snippets() map[string]string {
+	all := make(map[string]string, len(defaultSnippets)+len(i.config.Snippets))
+	for abbrev, body := range defaultSnippets {
+		all[abbrev] = body
+	}
+	for abbrev, body := range i.config.Snippets {
+		if body == "" {
+			delete(all, abbrev) // An empty body turns a built-in snippet off
+		} else {
+			all[abbrev] = body
+		}
+	}
+	return all
+}
+
+// bindSnippets installs the keys expanding snippets and cycling through
+// their tab stops. They go before the Text bindings, which insert a tab.
+func (i *Ite) bindSnippets() {
+	addBindtag(i.editText.Window, snippetBindTag, "Text")
+	Bind(snippetBindTag, "<Tab>", Command(func(e *Event) {
+		if i.onSnippetTab(1) {
+			e.SetReturnCodeBreak()
+		}
+	}))
+	backTab := []string{"<Shift-Tab>"}
+	if tclEval("tk windowingsystem") == "x11" {
+		backTab = append(backTab, "<ISO_Left_Tab>")
+	}
+	for _, key := range backTab {
+		Bind(snippetBindTag, key, Command(func(e *Event) {
+			if i.onSnippetTab(-1) {
+				e.SetReturnCodeBreak()
+			}
+		}))
+	}
+	Bind(snippetBindTag, "<Escape>", Command(i.endSnippet))
+	Bind(snippetBindTag, "<Button-1>", Command(i.endSnippet))
+}
+
+// onSnippetTab moves to the tab stop in direction dir, 1 or -1, of the
+// active session or, going forward, expands the abbreviation before the
+// cursor. It reports whether it handled the key.
+func (i *Ite) onSnippetTab(dir int) bool {
+	if i.composing {
+		return false
+	}
+	if i.snippet.stops != nil {
+		if i.insideSnippet() {
+			i.jumpSnippetStop(i.nextSnippetStop(dir))
+			return true
+		}
+		i.endSnippet()
+	}
+	if dir < 0 || i.largeFile || len(i.editText.TagRanges("sel")) > 0 {
+		return false
+	}
+	return i.expandSnippet()
+}
+
+// expandSnippet replaces the abbreviation right before the cursor with
+// its snippet, and starts a session on its tab stops. It reports whether
+// there was an abbreviation to expand.
+func (i *Ite) expandSnippet() bool {
+	before := []rune(i.editText.Get("insert linestart", "insert")[0])
+	start := len(before)
+	for start > 0 && i.isWordChar(before[start-1]) {
+		start--
+	}
+	after := []rune(i.editText.Get("insert", "insert+1c")[0])
+	if start == len(before) || (len(after) > 0 && i.isWordChar(after[0])) {
+		return false
+	}
+	body, ok := i.snippets()[string(before[start:])]
+	if !ok {
+		return false
+	}
+	from := fmt.Sprintf("insert-%dc", len(before)-start)
+	if i.blockProtected(from, "insert") {
+		return true
+	}
+	text, stops := parseSnippet(body, leadingSpace(string(before)), i.indentUnit())
+
+	i.endLinkedEdit()
+	i.editGroup(func() {
+		i.editText.Delete(from, "insert")
+		i.editText.MarkSet(snippetMark+"Origin", "insert")
+		i.editText.MarkGravity(snippetMark+"Origin", "left")
+		i.editText.Insert("insert", text)
+	})
+	for n, s := range stops {
+		i.editText.MarkSet(snippetStopMark("Start", n), fmt.Sprintf("%sOrigin+%dc", snippetMark, s.start))
+		i.editText.MarkGravity(snippetStopMark("Start", n), "left")
+		i.editText.MarkSet(snippetStopMark("End", n), fmt.Sprintf("%sOrigin+%dc", snippetMark, s.end))
+	}
+	i.editText.MarkUnset(snippetMark + "Origin")
+	i.snippet = snippetEdit{}
+	for _, s := range stops {
+		i.snippet.stops = append(i.snippet.stops, s.n)
+	}
+	i.jumpSnippetStop(0)
+	return true
+}
+
+// nextSnippetStop returns the index of the tab stop after the current one
+// in direction dir, 1 or -1, skipping the mirrors.
+func (i *Ite) nextSnippetStop(dir int) int {
+	stops := i.snippet.stops
+	n := i.snippet.current + dir
+	for n > 0 && n < len(stops)-1 && slices.Index(stops, stops[n]) < n {
+		n += dir
+	}
+	return max(n, 0)
+}
+
+// jumpSnippetStop moves the cursor to tab stop n, selecting its
+// placeholder, after mirroring the text of the stop it leaves. Reaching
+// the final stop ends the session.
+func (i *Ite) jumpSnippetStop(n int) {
+	i.mirrorSnippetStop(i.snippet.current)
+	start, end := snippetStopMark("Start", n), snippetStopMark("End", n)
+	i.editText.TagRemove("sel", "1.0", "end")
+	i.editText.MarkSet("insert", end)
+	if indexLess(i.editText, start, end) {
+		i.editText.TagAdd("sel", start, end)
+	}
+	i.snippet.current = n
+	if n == len(i.snippet.stops)-1 {
+		i.endSnippet()
+	}
+	i.editText.See("insert")
+	i.refreshCursorState()
+}
+
+// mirrorSnippetStop copies the text of tab stop n into the later stops
+// with the same number.
+func (i *Ite) mirrorSnippetStop(n int) {
+	stops := i.snippet.stops
+	if n >= len(stops)-1 {
+		return
+	}
+	text := i.editText.Get(snippetStopMark("Start", n), snippetStopMark("End", n))[0]
+	i.editGroup(func() {
+		for m := n + 1; m < len(stops); m++ {
+			start, end := snippetStopMark("Start", m), snippetStopMark("End", m)
+			if stops[m] != stops[n] || i.editText.Get(start, end)[0] == text {
+				continue
+			}
+			i.editText.Delete(start, end)
+			i.editText.Insert(start, text)
+		}
+	})
+}
+
+// insideSnippet reports whether the cursor is still between the first and
+// the last tab stop of the session.
+func (i *Ite) insideSnippet() bool {
+	first, last := snippetStopMark("Start", 0), snippetStopMark("End", len(i.snippet.stops)-1)
+	return !indexLess(i.editText, "insert", first) && !indexLess(i.editText, last, "insert")
+}
+
+// endSnippet closes the current session, if any.
+func (i *Ite) endSnippet() {
+	for n := range i.snippet.stops {
+		i.editText.MarkUnset(snippetStopMark("Start", n), snippetStopMark("End", n))
+	}
+	i.snippet = snippetEdit{}
+}
+
+// snippetStopMark returns the name of the start or end mark of tab stop n
+// of the session.
+func snippetStopMark(side string, n int) string {
+	return fmt.Sprintf("%s%s%d", snippetMark, side, n)
+}
+
+// parseSnippet returns the text of the snippet body, indented by indent
+// and with tabs turned into unit, and its tab stops in the order the
+// cursor visits them, the final one last.
+func parseSnippet(body, indent, unit string) (string, []snippetStop) {
+	var out []rune
+	var stops []snippetStop
+	placeholders := map[int]string{} // By number, for the mirrors
+	write := func(s string) {
+		for _, r := range s {
+			switch r {
+			case '\t':
+				out = append(out, []rune(unit)...)
+			case '\n':
+				out = append(out, '\n')
+				out = append(out, []rune(indent)...)
+			default:
+				out = append(out, r)
+			}
+		}
+	}
+	for len(body) > 0 {
+		n, placeholder, rest, ok := snippetTabStop(body)
+		switch {
+		case ok:
+			if first, seen := placeholders[n]; seen {
+				placeholder = first
+			} else {
+				placeholders[n] = placeholder
+			}
+			start := len(out)
+			write(placeholder)
+			stops = append(stops, snippetStop{n: n, start: start, end: len(out)})
+			body = rest
+		case strings.HasPrefix(body, "$$"):
+			out = append(out, '$')
+			body = body[2:]
+		default:
+			next := strings.IndexByte(body[1:], '$') + 1
+			if next == 0 {
+				next = len(body)
+			}
+			write(body[:next])
+			body = body[next:]
+		}
+	}
+
+	// The stops are visited by number, then the final one, which is the
+	// end of the text if the body has none
+	slices.SortStableFunc(stops, func(a, b snippetStop) int {
+		switch {
+		case a.n == b.n:
+			return 0
+		case a.n == 0:
+			return 1
+		case b.n == 0:
+			return -1
+		}
+		return a.n - b.n
+	})
+	if len(stops) == 0 || stops[len(stops)-1].n != 0 {
+		stops = append(stops, snippetStop{start: len(out), end: len(out)})
+	}
+	return string(out), stops
+}
+
+// snippetTabStop parses the $n or ${n:placeholder} tab stop at the start
+// of s, returning the rest of s after it.
+func snippetTabStop(s string) (n int, placeholder, rest string, ok bool) {
+	if !strings.HasPrefix(s, "$") {
+		return 0, "", "", false
+	}
+	braced := strings.HasPrefix(s, "${")
+	digits := s[1:]
+	if braced {
+		digits = s[2:]
+	}
+	end := 0
+	for end < len(digits) && digits[end] >= '0' && digits[end] <= '9' {
+		n = n*10 + int(digits[end]-'0')
+		end++
+	}
+	if end == 0 {
+		return 0, "", "", false
+	}
+	rest = digits[end:]
+	if !braced {
+		return n, "", rest, true
+	}
+	if strings.HasPrefix(rest, "}") {
+		return n, "", rest[1:], true
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return 0, "", "", false
+	}
+	placeholder, rest, found := strings.Cut(rest[1:], "}")
+	if !found {
+		return 0, "", "", false
+	}
+	return n, placeholder, rest, true
+}
@@ -0,0 +1,537 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Spell Checking
+// -------------------------------------------------------------------------
+
+// The spell checker underlines the misspelled words of the comments and
+// string literals of Go files, the code being left to the compiler. The
+// words come from a plain word list, one per line, or a Hunspell .dic
+// file, whose affix flags are ignored: common English endings are stripped
+// instead. Words that look like code, such as mixedCaps, snake_case,
+// file.go or the identifiers of the file, are not checked. The words a
+// project uses on purpose are kept in projectConfigDir/spellingFileName.
+
+const (
+	tagSpelling      = "spelling"     // Editor tag of the misspelled words
+	spellingFileName = "spelling.txt" // Words ignored in a project, inside projectConfigDir
+	minSpellWord     = 3              // Shorter words are not checked
+	maxSuggestions   = 8
+)
+
+// dictionaryPaths lists the word lists looked for when the settings name
+// none, in order.
+var dictionaryPaths = []string{
+	"/usr/share/hunspell/en_US.dic",
+	"/usr/share/myspell/en_US.dic",
+	"/usr/share/myspell/dicts/en_US.dic",
+	"/usr/share/dict/words",
+	"/usr/share/dict/american-english",
+	"/usr/share/dict/british-english",
+}
+
+// spellSuffixes are the English endings stripped from a word missing from
+// the dictionary, with what replaces them to form the stem.
+var spellSuffixes = []struct{ suffix, stem string }{
+	{"'s", ""}, {"s", ""}, {"es", ""}, {"ies", "y"}, {"ied", "y"},
+	{"ed", ""}, {"ed", "e"}, {"ing", ""}, {"ing", "e"}, {"ly", ""},
+	{"er", ""}, {"er", "e"}, {"ers", ""}, {"ers", "e"}, {"est", ""},
+}
+
+// spellState is the spell checker of the editor.
+type spellState struct {
+	dict       dictionary      // Loaded word list, nil until first needed
+	dictErr    error           // Why no word list could be loaded
+	ignoreRoot string          // Project whose ignored words are in ignored
+	ignored    map[string]bool // Words ignored in ignoreRoot, in lower case
+	menu       *MenuWidget     // Popup menu of the suggestions, nil until first used
+}
+
+// dictionary is a set of words in lower case.
+type dictionary map[string]struct{}
+
+// loadDictionary reads the word list at path: a word per line, or a
+// Hunspell .dic file, whose first line counts the words and whose lines
+// may add affix flags after a slash.
+func loadDictionary(path string) (dictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d := make(dictionary)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		word, _, _ := strings.Cut(s.Text(), "/")
+		word, _, _ = strings.Cut(word, "\t")
+		word = strings.TrimSpace(word)
+		if word == "" || strings.HasPrefix(word, "#") || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			continue
+		}
+		d[strings.ToLower(word)] = struct{}{}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(d) == 0 {
+		return nil, fmt.Errorf("%s holds no words", path)
+	}
+	return d, nil
+}
+
+// known reports whether word, or its stem without a common English
+// ending, is in d.
+func (d dictionary) known(word string) bool {
+	lower := strings.ToLower(word)
+	if _, ok := d[lower]; ok {
+		return true
+	}
+	for _, s := range spellSuffixes {
+		if stem, ok := strings.CutSuffix(lower, s.suffix); ok && len(stem) >= 2 {
+			if _, ok := d[stem+s.stem]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// suggest returns up to maxSuggestions words of d one edit away from
+// word, or two if none is one away, best first and with the case of
+// word's first letter.
+func (d dictionary) suggest(word string) []string {
+	lower := strings.ToLower(word)
+	found := make(map[string]bool)
+	edits := spellEdits(lower)
+	for _, w := range edits {
+		if _, ok := d[w]; ok {
+			found[w] = true
+		}
+	}
+	if len(found) == 0 {
+		for _, e := range edits {
+			for _, w := range spellEdits(e) {
+				if _, ok := d[w]; ok {
+					found[w] = true
+				}
+			}
+		}
+	}
+	delete(found, lower)
+	words := slices.Collect(maps.Keys(found))
+	// Words sharing a longer start with word, then closer in length, come
+	// first
+	slices.SortFunc(words, func(a, b string) int {
+		if c := cmp.Compare(commonPrefixLen(b, lower), commonPrefixLen(a, lower)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(absInt(len(a)-len(lower)), absInt(len(b)-len(lower))); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	words = words[:min(len(words), maxSuggestions)]
+	if r, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(r) {
+		for n, w := range words {
+			first, size := utf8.DecodeRuneInString(w)
+			words[n] = string(unicode.ToUpper(first)) + w[size:]
+		}
+	}
+	return words
+}
+
+// spellEdits returns the strings one deletion, transposition, replacement
+// or insertion of a letter away from word.
+func spellEdits(word string) []string {
+	runes := []rune(word)
+	letters := []rune("abcdefghijklmnopqrstuvwxyz'")
+	for _, r := range runes {
+		if !slices.Contains(letters, r) {
+			letters = append(letters, r)
+		}
+	}
+	var edits []string
+	for n := 0; n <= len(runes); n++ {
+		head, tail := string(runes[:n]), runes[n:]
+		if len(tail) > 0 {
+			edits = append(edits, head+string(tail[1:]))
+		}
+		if len(tail) > 1 {
+			edits = append(edits, head+string(tail[1])+string(tail[0])+string(tail[2:]))
+		}
+		for _, r := range letters {
+			if len(tail) > 0 && r != tail[0] {
+				edits = append(edits, head+string(r)+string(tail[1:]))
+			}
+			edits = append(edits, head+string(r)+string(tail))
+		}
+	}
+	return edits
+}
+
+// commonPrefixLen returns the length in bytes of the start a and b share.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// -------------------------------------------------------------------------
+// Words to Check
+// -------------------------------------------------------------------------
+
+// spellWord is a word of a comment or string literal.
+type spellWord struct {
+	text       string
+	line       int // Line of the buffer, from 1
+	start, end int // Rune columns on its line
+}
+
+// spellWords returns the words of the comments and string literals of the
+// Go source src that look like prose: at least minSpellWord letters, in
+// lower case or capitalized, outside chunks of code such as paths and
+// qualified names, and not an identifier of src. Directive comments are
+// skipped.
+func spellWords(src string) []spellWord {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	type span struct{ start, end int }
+	var spans []span
+	idents := make(map[string]bool)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch {
+		case tok == token.IDENT:
+			idents[lit] = true
+		case tok == token.COMMENT && directiveRe.MatchString(strings.TrimPrefix(lit, "//")):
+		case tok == token.COMMENT:
+			start := file.Offset(pos)
+			spans = append(spans, span{start, min(start+len(lit), len(src))})
+		case tok == token.STRING && len(lit) >= 2:
+			start := file.Offset(pos) + 1 // Inside the quotes
+			spans = append(spans, span{start, min(start+len(lit)-2, len(src))})
+		}
+	}
+
+	var words []spellWord
+	line, lineStart := 1, 0 // Line of the current span and its offset
+	prev := 0
+	for _, sp := range spans {
+		for _, w := range proseWords(src[sp.start:sp.end]) {
+			if idents[w.text] {
+				continue
+			}
+			// Lines are counted from the previous word on, the offsets
+			// growing
+			off := sp.start + w.start
+			for n := strings.IndexByte(src[prev:off], '\n'); n >= 0; n = strings.IndexByte(src[prev:off], '\n') {
+				line++
+				prev += n + 1
+				lineStart = prev
+			}
+			prev = off
+			col := utf8.RuneCountInString(src[lineStart:off])
+			words = append(words, spellWord{w.text, line, col, col + utf8.RuneCountInString(w.text)})
+		}
+	}
+	return words
+}
+
+// proseWord is a word found by proseWords, at a byte offset of the text.
+type proseWord struct {
+	text  string
+	start int
+}
+
+// proseWords returns the words of text worth checking, as spellWords
+// describes them.
+func proseWords(text string) []proseWord {
+	var words []proseWord
+	for start := 0; start < len(text); {
+		// Chunks are separated by blanks
+		for start < len(text) && isSpellBlank(text[start]) {
+			start++
+		}
+		end := start
+		for end < len(text) && !isSpellBlank(text[end]) {
+			end++
+		}
+		chunk := text[start:end]
+		if !isCodeChunk(chunk) {
+			for _, w := range letterRuns(chunk) {
+				if isProse(w.text) {
+					words = append(words, proseWord{w.text, start + w.start})
+				}
+			}
+		}
+		start = end
+	}
+	return words
+}
+
+func isSpellBlank(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// isCodeChunk reports whether chunk, a run of text without blanks, looks
+// like code rather than prose: a path, an address, a qualified name, a
+// call or an identifier with underscores or digits.
+func isCodeChunk(chunk string) bool {
+	if strings.ContainsAny(chunk, "/@_()[]{}=<>|&*#$`0123456789") {
+		return true
+	}
+	// A dot between two letters joins the parts of a name, as in file.go
+	for n := 1; n+1 < len(chunk); n++ {
+		if chunk[n] == '.' && isASCIILetter(chunk[n-1]) && isASCIILetter(chunk[n+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isASCIILetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// letterRuns returns the runs of letters of chunk, which may hold
+// apostrophes between letters. A letter after a backslash belongs to an
+// escape sequence and starts no word.
+func letterRuns(chunk string) []proseWord {
+	var runs []proseWord
+	start := -1
+	var prev rune
+	for n, r := range chunk {
+		letter := unicode.IsLetter(r) || r == '\'' && start >= 0 && n+1 < len(chunk) && isASCIILetter(chunk[n+1])
+		switch {
+		case letter && start < 0 && prev == '\\':
+		case letter && start < 0:
+			start = n
+		case !letter && start >= 0:
+			runs = append(runs, proseWord{chunk[start:n], start})
+			start = -1
+		}
+		prev = r
+	}
+	if start >= 0 {
+		runs = append(runs, proseWord{chunk[start:], start})
+	}
+	return runs
+}
+
+// isProse reports whether word is long enough to check and written in
+// lower case, or capitalized: words with other capitals are names or
+// acronyms.
+func isProse(word string) bool {
+	if utf8.RuneCountInString(word) < minSpellWord {
+		return false
+	}
+	for n, r := range word {
+		if n > 0 && unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// -------------------------------------------------------------------------
+// Editor
+// -------------------------------------------------------------------------
+
+// onToggleSpellCheck turns spell checking on or off, and persists the
+// choice.
+func (i *Ite) onToggleSpellCheck() {
+	i.config.SpellCheck = !i.config.SpellCheck
+	i.spellCheckVar.Set(checkValue(i.config.SpellCheck))
+	i.saveConfig()
+	if i.config.SpellCheck {
+		i.spell.dict, i.spell.dictErr = nil, nil // Look for the word list again
+	}
+	i.markSpelling()
+}
+
+// configureSpellingTag styles the misspelled words and binds the menu of
+// suggestions to their right click.
+func (i *Ite) configureSpellingTag() {
+	i.editText.TagConfigure(tagSpelling, Underline(1), Underlinefg(theme.Warning))
+	buttons := []string{"<Button-3>"}
+	if runtime.GOOS == "darwin" {
+		buttons = append(buttons, "<Button-2>") // The right button on macOS
+	}
+	for _, b := range buttons {
+		i.editText.TagBind(tagSpelling, b, func() { i.showSpellingMenu() })
+	}
+}
+
+// dictionary returns the word list of the spell checker, loading it the
+// first time. It returns nil if none could be loaded.
+func (i *Ite) dictionary() dictionary {
+	if i.spell.dict != nil || i.spell.dictErr != nil {
+		return i.spell.dict
+	}
+	paths := dictionaryPaths
+	if i.config.SpellDictionary != "" {
+		paths = []string{i.config.SpellDictionary}
+	}
+	i.spell.dictErr = errors.New("no word list found; set spellDictionary in the settings")
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil && i.config.SpellDictionary == "" {
+			continue
+		}
+		d, err := loadDictionary(path)
+		if err != nil {
+			i.spell.dictErr = err
+			break
+		}
+		i.spell.dict, i.spell.dictErr = d, nil
+		break
+	}
+	if i.spell.dictErr != nil {
+		i.showStatusHint("Spell checking: " + i.spell.dictErr.Error())
+	}
+	return i.spell.dict
+}
+
+// ignoredWords returns the words ignored in the current project, in lower
+// case, reading them when the project changed.
+func (i *Ite) ignoredWords() map[string]bool {
+	root := i.projectDir()
+	if i.spell.ignored != nil && i.spell.ignoreRoot == root {
+		return i.spell.ignored
+	}
+	i.spell.ignoreRoot, i.spell.ignored = root, make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(root, projectConfigDir, spellingFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		i.showStatusHint("Reading the ignored words: " + err.Error())
+	}
+	for _, word := range strings.Fields(string(data)) {
+		i.spell.ignored[strings.ToLower(word)] = true
+	}
+	return i.spell.ignored
+}
+
+// markSpelling underlines the misspelled words of the comments and strings
+// of the active buffer, when spell checking is on and the buffer holds Go.
+func (i *Ite) markSpelling() {
+	i.editText.TagRemove(tagSpelling, "1.0", "end")
+	if !i.config.SpellCheck || i.largeFile || i.loading() ||
+		(i.currentFile != "" && filepath.Ext(i.currentFile) != defaultFileExtension) {
+		return
+	}
+	d := i.dictionary()
+	if d == nil {
+		return
+	}
+	ignored := i.ignoredWords()
+	for _, w := range spellWords(i.editText.Text()) {
+		if !ignored[strings.ToLower(w.text)] && !d.known(w.text) {
+			i.editText.TagAdd(tagSpelling, fmt.Sprintf("%d.%d", w.line, w.start), fmt.Sprintf("%d.%d", w.line, w.end))
+		}
+	}
+}
+
+// showSpellingMenu pops up, at the mouse pointer, the suggestions for the
+// misspelled word under it, and the entry ignoring it in the project.
+func (i *Ite) showSpellingMenu() {
+	line, col := parseIndex(i.editText.Index("current"))
+	var from, to string
+	ranges := i.editText.TagRanges(tagSpelling)
+	for n := 0; n+1 < len(ranges); n += 2 {
+		l1, c1 := parseIndex(ranges[n])
+		l2, c2 := parseIndex(ranges[n+1])
+		if line == l1 && line == l2 && col >= c1 && col < c2 {
+			from, to = ranges[n], ranges[n+1]
+		}
+	}
+	if from == "" {
+		return
+	}
+	word := i.editText.Get(from, to)[0]
+	if i.spell.menu == nil {
+		i.spell.menu = Menu(Tearoff(false))
+	}
+	menu := i.spell.menu
+	tclEval("%s delete 0 end", menu)
+	suggestions := i.dictionary().suggest(word)
+	for _, s := range suggestions {
+		menu.AddCommand(Lbl(s), Command(func() { i.replaceMisspelling(from, to, word, s) }))
+	}
+	if len(suggestions) == 0 {
+		menu.AddCommand(Lbl("No suggestions"), State("disabled"))
+	}
+	menu.AddSeparator()
+	menu.AddCommand(Lbl(fmt.Sprintf("Ignore %q in Project", word)), Command(func() { i.ignoreWord(word) }))
+	xy := strings.Fields(tclEval("winfo pointerxy %s", App))
+	if len(xy) < 2 {
+		return
+	}
+	Popup(menu.Window, winfoInt(xy[0]), winfoInt(xy[1]), nil)
+}
+
+// replaceMisspelling writes s in place of word, from from to to, unless
+// the buffer changed there since the menu was shown.
+func (i *Ite) replaceMisspelling(from, to, word, s string) {
+	if i.editText.Get(from, to)[0] != word || i.blockProtected(from, to) {
+		return
+	}
+	i.editGroup(func() {
+		i.editText.Delete(from, to)
+		i.editText.Insert(from, s)
+	})
+	i.markSpelling()
+}
+
+// ignoreWord adds word to the words ignored in the current project, saved
+// in its settings directory.
+func (i *Ite) ignoreWord(word string) {
+	ignored := i.ignoredWords()
+	ignored[strings.ToLower(word)] = true
+	words := slices.Sorted(maps.Keys(ignored))
+	dir := filepath.Join(i.spell.ignoreRoot, projectConfigDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		i.showError("Error saving the ignored words: " + err.Error())
+		return
+	}
+	data := []byte(strings.Join(words, "\n") + "\n")
+	if err := writeFileAtomic(filepath.Join(dir, spellingFileName), data, defaultFilePerms); err != nil {
+		i.showError("Error saving the ignored words: " + err.Error())
+		return
+	}
+	i.markSpelling()
+}
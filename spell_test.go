@@ -0,0 +1,108 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSpellWords(t *testing.T) {
+	src := "package main\n\n" +
+		"// Speling is chekced here, but notInCamelCase, file.go or http://x.org aren't.\n" +
+		"//go:generate stringer -type=Kind\n" +
+		"func f(errs int) string {\n" +
+		"\treturn \"wrold\\nnext\" + `raw téxt` // errs is an identifier\n" +
+		"}\n"
+	var got []spellWord
+	for _, w := range spellWords(src) {
+		switch w.text {
+		case "Speling", "chekced", "wrold", "next", "téxt", "aren't", "errs", "stringer":
+			got = append(got, w)
+		}
+	}
+	want := []spellWord{
+		{"Speling", 3, 3, 10},
+		{"chekced", 3, 14, 21},
+		{"aren't", 3, 72, 78},
+		{"wrold", 6, 9, 14},
+		{"next", 6, 16, 20},
+		{"téxt", 6, 29, 33},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("spellWords = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestProseWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"plain words here", []string{"plain", "words", "here"}},
+		{"Capital ALLCAPS mixedCaps", []string{"Capital"}},
+		{"a an the", []string{"the"}},
+		{"os.Open, path/to/file, user@host, f(), snake_case, v2", nil},
+		{"end. of sentence, isn't it", []string{"end", "sentence", "isn't"}},
+		{`\tescaped \nnewline`, []string{"escaped", "newline"}},
+		{"%s %d formats", []string{"formats"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, w := range proseWords(tt.text) {
+			got = append(got, w.text)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("proseWords(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "en_US.dic")
+	data := "6\nhello/MS\nworld\nstore/DSG\ncopy/S\nGo\n# comment\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := loadDictionary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range []string{"hello", "Hello", "hello's", "worlds", "stored", "storing", "copies", "go"} {
+		if !d.known(word) {
+			t.Errorf("%q unknown", word)
+		}
+	}
+	for _, word := range []string{"helo", "wrold", "6"} {
+		if d.known(word) {
+			t.Errorf("%q known", word)
+		}
+	}
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"helo", []string{"hello"}},
+		{"Wrold", []string{"World"}},
+		{"stroe", []string{"store"}},
+		{"hellllo", []string{"hello"}}, // Two edits away
+		{"zzzzzzzz", nil},
+	}
+	for _, tt := range tests {
+		if got := d.suggest(tt.word); !slices.Equal(got, tt.want) {
+			t.Errorf("suggest(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(empty, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDictionary(empty); err == nil {
+		t.Error("loading a word list without words succeeded")
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Split View
+// -------------------------------------------------------------------------
+
+// The editor area can hold two panes, each editing its own buffer. The
+// rest of the editor works on the active pane through the Ite fields
+// (editText, currentFile...): activating a pane swaps its state in.
+
+const (
+	splitSideBySide = "horizontal" // Panes left and right
+	splitStacked    = "vertical"   // Panes above and below
+	paneBindTag     = "ItePane"    // Prefix of the bind tag activating a pane
+)
+
+// editorPane holds the state of a pane while another one is active.
+type editorPane struct {
+	id           int
+	frame        *TFrameWidget
+	text         *TextWidget
+	scrollbar    *TScrollbarWidget
+	proseGuide   *FrameWidget
+	columnGuide  *FrameWidget
+	indentGuides []*FrameWidget
+	file         string
+	diskStamp    fileStamp
+	swapFile     string
+	largeFile    bool
+	encoding     string
+	eol          string
+	journal      *undoJournal
+	journalBase  int
+	undo         undoGrouper
+	cursors      cursorHistory
+	linked       linkedEdit
+	snippet      snippetEdit
+	readOnly     bool
+}
+
+// storePane saves the state of the active pane into p.
+func (i *Ite) storePane(p *editorPane) {
+	p.frame, p.text, p.scrollbar = i.editFrame, i.editText, i.editVScrollbar
+	p.proseGuide, p.columnGuide, p.indentGuides = i.proseGuide, i.columnGuide, i.indentGuides
+	p.file, p.diskStamp, p.swapFile, p.largeFile = i.currentFile, i.diskStamp, i.swapFile, i.largeFile
+	p.encoding, p.eol = i.encoding, i.eol
+	p.journal, p.journalBase = i.journal, i.journalBase
+	p.undo, p.linked, p.snippet = i.undo, i.linked, i.snippet
+	p.cursors = i.cursors
+	p.readOnly = i.readOnly
+}
+
+// loadPane makes the state of p the active one.
+func (i *Ite) loadPane(p *editorPane) {
+	i.editFrame, i.editText, i.editVScrollbar = p.frame, p.text, p.scrollbar
+	i.proseGuide, i.columnGuide, i.indentGuides = p.proseGuide, p.columnGuide, p.indentGuides
+	i.currentFile, i.diskStamp, i.swapFile, i.largeFile = p.file, p.diskStamp, p.swapFile, p.largeFile
+	i.encoding, i.eol = p.encoding, p.eol
+	i.journal, i.journalBase = p.journal, p.journalBase
+	i.undo, i.linked, i.snippet = p.undo, p.linked, p.snippet
+	i.cursors = p.cursors
+	i.setReadOnly(p.readOnly)
+}
+
+// switchPane makes p the active pane without updating the display.
+func (i *Ite) switchPane(p *editorPane) {
+	if p == i.active {
+		return
+	}
+	i.storePane(i.active)
+	i.loadPane(p)
+	i.active = p
+}
+
+// activatePane makes p the active pane and shows its state in the title,
+// the status bar and the outline.
+func (i *Ite) activatePane(p *editorPane) {
+	if p == i.active {
+		return
+	}
+	if i.config.SaveOnFocusLoss {
+		i.autosaveBuffer()
+	}
+	i.switchPane(p)
+	i.visitFile(i.currentFile)
+	i.updateTitle()
+	i.refreshCursorState()
+	i.refreshOutline()
+	i.scheduleHealthCheck()
+}
+
+// withPane runs fn with p as the active pane, then restores the pane that
+// was active. fn must not open modal dialogs: the focus coming back to a
+// pane when they close would activate it.
+func (i *Ite) withPane(p *editorPane, fn func()) {
+	prev := i.active
+	if p == prev {
+		fn()
+		return
+	}
+	i.switchPane(p)
+	fn()
+	i.switchPane(prev)
+	i.updateTitle()
+	i.refreshCursorState()
+	i.refreshOutline()
+}
+
+// otherPane returns the inactive pane, or nil when the view isn't split.
+func (i *Ite) otherPane() *editorPane {
+	for _, p := range i.panes {
+		if p != i.active {
+			return p
+		}
+	}
+	return nil
+}
+
+// bindPane installs the bind tag activating p before any other binding of
+// its text sees a click or a key.
+func (i *Ite) bindPane(p *editorPane) {
+	tag := fmt.Sprintf("%s%d", paneBindTag, p.id)
+	addBindtag(i.editText.Window, tag, "")
+	Bind(tag, "<FocusIn>", Command(func() { i.activatePane(p) }))
+	Bind(tag, "<ButtonPress>", Command(func() { i.activatePane(p) }))
+}
+
+// arrangePanes places the panes in the editor region, split along orient.
+func (i *Ite) arrangePanes(orient string) {
+	i.storePane(i.active)
+	if i.splitPane != nil {
+		Destroy(i.splitPane) // The panes are not its children and survive
+	}
+	i.splitOrient = orient
+	i.splitPane = i.editorRegion.TPanedwindow(Orient(orient))
+	for _, p := range i.panes {
+		i.splitPane.Add(p.frame.Window, Weight(1))
+	}
+	Grid(i.splitPane, Row(0), Column(0), Sticky(NEWS))
+}
+
+// onSplit splits the editor along orient. The new pane shows the file of
+// the active one, for reference while editing; an existing split only
+// changes orientation.
+func (i *Ite) onSplit(orient string) {
+	if len(i.panes) > 1 {
+		i.arrangePanes(orient)
+		return
+	}
+	file := i.currentFile
+	i.paneCount++
+	p := &editorPane{id: i.paneCount}
+	i.storePane(i.active)
+	i.panes = append(i.panes, p)
+	i.switchPane(p)
+	i.editFrame, i.editText, i.editVScrollbar = i.createEditorPanel()
+	i.journalBase = -1
+	i.layoutEditorPanel()
+	i.makeProseGuide()
+	i.makeColumnGuide()
+	i.configureEditorTags()
+	i.bindShortcuts()
+	i.arrangePanes(orient)
+	if file != "" {
+		if err := i.openFile(file); err != nil {
+			i.showError("Error opening file: " + err.Error())
+		}
+	}
+	i.updateTitle()
+	i.refreshCursorState()
+	i.refreshOutline()
+	Focus(i.editText)
+}
+
+// onClosePane closes the active pane, asking to save its changes, and
+// activates the other one.
+func (i *Ite) onClosePane() {
+	other := i.otherPane()
+	if other == nil {
+		i.showStatusHint("The view is not split")
+		return
+	}
+	if !i.promptSaveIfModified() {
+		return
+	}
+	i.removeSwap()
+	closing := i.active
+	i.activatePane(other)
+	Destroy(closing.frame)
+	i.forgetVirtualText(closing.text)
+	i.panes = []*editorPane{other}
+	i.arrangePanes(i.splitOrient)
+	Focus(i.editText)
+}
+
+// onMoveToOtherPane shows the file of the active pane in the other one,
+// splitting the editor first if needed, and the active pane goes back to
+// the file shown before it, or to an empty buffer. The file must be saved
+// first, as its unsaved changes live in the pane.
+func (i *Ite) onMoveToOtherPane() {
+	file := i.currentFile
+	if file == "" {
+		i.showStatusHint("Save the buffer first to move it")
+		return
+	}
+	if !i.promptSaveIfModified() {
+		return
+	}
+	prev := ""
+	for _, path := range i.recentFiles {
+		if path != file {
+			prev = path
+			break
+		}
+	}
+	source := i.active
+	if other := i.otherPane(); other == nil {
+		i.onSplit(splitSideBySide)
+	} else {
+		i.activatePane(other)
+		if other.file != file {
+			if !i.promptSaveIfModified() {
+				Focus(i.editText)
+				return
+			}
+			if err := i.openFile(file); err != nil {
+				i.showError("Error opening file: " + err.Error())
+				return
+			}
+		}
+	}
+	target := i.active
+	i.activatePane(source)
+	if prev == "" || i.openFile(prev) != nil {
+		i.onNew()
+	}
+	i.activatePane(target)
+	Focus(i.editText)
+}
+
+// onOtherPane moves the focus to the other pane of a split view.
+func (i *Ite) onOtherPane() {
+	other := i.otherPane()
+	if other == nil {
+		i.showStatusHint("The view is not split")
+		return
+	}
+	i.activatePane(other)
+	Focus(i.editText)
+}
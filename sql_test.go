@@ -0,0 +1,86 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSQLTokens(t *testing.T) {
+	src := "select 'it''s', \"from\", $$a;b$$, $1 -- where\n/* x */ 1.5;"
+	var got []string
+	for _, tok := range sqlTokens([]rune(src)) {
+		got = append(got, src[tok.from:tok.to])
+	}
+	want := []string{"select", "'it''s'", "$$a;b$$", "1", "-- where", "/* x */", "1.5", ";"}
+	if !slices.Equal(got, want) {
+		t.Errorf("sqlTokens = %q, want %q", got, want)
+	}
+}
+
+func TestSQLStatementAt(t *testing.T) {
+	src := "-- Users\nSELECT * FROM users;\n\nINSERT INTO t VALUES (';');\n"
+	for _, tt := range []struct {
+		offset int
+		want   string
+	}{
+		{0, "SELECT * FROM users"},
+		{15, "SELECT * FROM users"},
+		{30, "SELECT * FROM users"}, // After the semicolon
+		{31, "INSERT INTO t VALUES (';')"},
+		{len(src), "INSERT INTO t VALUES (';')"},
+	} {
+		if got := sqlStatementAt(src, tt.offset); got != tt.want {
+			t.Errorf("sqlStatementAt(%d) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+	if got := sqlStatementAt("-- nothing\n;", 0); got != "" {
+		t.Errorf("sqlStatementAt of comments = %q, want none", got)
+	}
+}
+
+func TestNewQueryClient(t *testing.T) {
+	for _, tt := range []struct {
+		dsn  string
+		name string
+		args []string
+		env  []string
+	}{
+		{"postgres://u@localhost/shop", "psql", []string{"-X", "-q", "--csv", "-v", "ON_ERROR_STOP=1", "-d", "postgres://u@localhost/shop", "-c", "q"}, nil},
+		{"mysql://u:pw@db:3306/shop", "mysql", []string{"--batch", "-h", "db", "-P", "3306", "-u", "u", "-e", "q", "shop"}, []string{"MYSQL_PWD=pw"}},
+		{"sqlite:data/shop.db", "sqlite3", []string{"-bail", "-csv", "-header", "data/shop.db", "q"}, nil},
+		{"sqlite:///tmp/shop.db", "sqlite3", []string{"-bail", "-csv", "-header", "/tmp/shop.db", "q"}, nil},
+		{"shop.sqlite", "sqlite3", []string{"-bail", "-csv", "-header", "shop.sqlite", "q"}, nil},
+	} {
+		c, err := newQueryClient(tt.dsn, "q")
+		if err != nil {
+			t.Errorf("newQueryClient(%q): %v", tt.dsn, err)
+			continue
+		}
+		if c.name != tt.name || !slices.Equal(c.args, tt.args) || !slices.Equal(c.env, tt.env) {
+			t.Errorf("newQueryClient(%q) = %s %q %q, want %s %q %q", tt.dsn, c.name, c.args, c.env, tt.name, tt.args, tt.env)
+		}
+	}
+	if _, err := newQueryClient("oracle://db", "q"); err == nil {
+		t.Error("newQueryClient of an unknown database succeeded")
+	}
+}
+
+func TestParseQueryOutput(t *testing.T) {
+	header, rows, err := parseQueryOutput("id,name\n1,\"a, b\"\n2,c\n", ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(header, []string{"id", "name"}) || len(rows) != 2 || rows[0][1] != "a, b" {
+		t.Errorf("parseQueryOutput = %q, %q", header, rows)
+	}
+	header, rows, err = parseQueryOutput("id\tnote\n1\tsays \"hi\"\n", '\t')
+	if err != nil || len(rows) != 1 || rows[0][1] != `says "hi"` {
+		t.Errorf("parseQueryOutput of tabs = %q, %q, %v", header, rows, err)
+	}
+	if header, _, _ := parseQueryOutput("\n", ','); header != nil {
+		t.Errorf("parseQueryOutput of nothing = %q", header)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Startup Profile
+// -------------------------------------------------------------------------
+
+// The startup is timed phase by phase, from loading the config to the
+// window appearing. A startup slower than slowStartup is logged on the
+// standard error; the Doctor window always lists the phases. The panels
+// that aren't visible right away are built on first use: the outline
+// when it is shown, the documentation window when it is opened, and the
+// console tabs once the window is up, unless a command needs them sooner.
+const slowStartup = 500 * time.Millisecond
+
+// startupPhase is a timed phase of the startup.
+type startupPhase struct {
+	name string
+	took time.Duration
+}
+
+// startupProfile records the phases of the startup.
+type startupProfile struct {
+	start  time.Time
+	last   time.Time // End of the previous phase
+	phases []startupPhase
+	done   bool // The window has appeared
+}
+
+// newStartupProfile starts timing the startup.
+func newStartupProfile() *startupProfile {
+	now := time.Now()
+	return &startupProfile{start: now, last: now}
+}
+
+// mark ends the phase called name.
+func (p *startupProfile) mark(name string) {
+	now := time.Now()
+	p.phases = append(p.phases, startupPhase{name, now.Sub(p.last)})
+	p.last = now
+}
+
+// skip leaves the time since the previous phase out of the profile, such
+// as the time spent answering the setup wizard.
+func (p *startupProfile) skip() {
+	p.start = p.start.Add(time.Since(p.last))
+	p.last = time.Now()
+}
+
+// total returns the time from the start to the end of the last phase.
+func (p *startupProfile) total() time.Duration {
+	return p.last.Sub(p.start)
+}
+
+// String returns the phases on a line, e.g.
+// "config 2ms, widgets 41ms, window 60ms (total 103ms)".
+func (p *startupProfile) String() string {
+	parts := make([]string, len(p.phases))
+	for n, phase := range p.phases {
+		parts[n] = fmt.Sprintf("%s %v", phase.name, phase.took.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s (total %v)", strings.Join(parts, ", "), p.total().Round(time.Millisecond))
+}
+
+// onWindowShown ends the startup once the window has appeared: it logs a
+// slow startup and builds the console tabs.
+func (i *Ite) onWindowShown() {
+	p := i.startup
+	if !p.done {
+		p.done = true
+		p.mark("window")
+		if p.total() > slowStartup {
+			fmt.Fprintf(os.Stderr, "ite: slow startup: %v\n", p)
+		}
+	}
+	i.buildConsoles()
+}
+
+// showStartupProfile adds to the Doctor window view the phases of the
+// startup.
+func (i *Ite) showStartupProfile(view *TextWidget) {
+	var b strings.Builder
+	b.WriteString("\nStartup:\n")
+	for _, phase := range i.startup.phases {
+		fmt.Fprintf(&b, "    %-10s %v\n", phase.name, phase.took.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&b, "    %-10s %v\n", "total", i.startup.total().Round(time.Millisecond))
+	view.Configure(State("normal"))
+	view.Insert("end", b.String())
+	view.Configure(State("disabled"))
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Status Bar
+// -------------------------------------------------------------------------
+
+// The status bar is a row of segments, laid out left to right in the order
+// they are added. Clicking a segment runs the action it shows: the cursor
+// position opens Go to Line, the file status saves, the module name opens
+// go.mod, the job spinner shows the console of the running command; the
+// encoding, line ending, build tags and branch segments are menus.
+const (
+	spinnerInterval = 100 * time.Millisecond
+	statusFont      = "GoMono"
+	statusFontSize  = 11
+)
+
+// spinnerFrames are the frames of the job spinner, shown in turn.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// statusSegment is a widget of the status bar.
+type statusSegment struct {
+	id     string
+	w      *Window
+	color  func() string // Foreground in the current theme, nil if the segment sets its own
+	expand bool          // Takes the room the other segments leave
+}
+
+// statusProviders add segments after the built-in ones, see
+// registerStatusSegments.
+var statusProviders []func(*Ite)
+
+// registerStatusSegments has provider add segments to the status bar with
+// statusLabel or statusMenu, after the built-in ones. A fork shows its own
+// state from a file of its own:
+//
+//	func init() {
+//		registerStatusSegments(func(i *Ite) {
+//			words := i.statusLabel("words", func() string { return theme.Muted }, "wordCount")
+//			...
+//		})
+//	}
+//
+// It must be called before the editor starts, from init.
+func registerStatusSegments(provider func(*Ite)) {
+	statusProviders = append(statusProviders, provider)
+}
+
+// makeStatusbar creates the segments of the status bar.
+func (i *Ite) makeStatusbar() {
+	i.statusFrame = TFrame(Relief(SUNKEN))
+	fg := func() string { return theme.Foreground }
+	i.statusLabelCursor = i.statusLabel("cursor", fg, "goToLine")
+	i.statusLabelCursor.Configure(Txt(tr("Line:Column") + " 0:0"))
+	i.statusSegments[len(i.statusSegments)-1].expand = true
+	i.statusSelection = i.statusLabel("selection", func() string { return theme.Muted }, "")
+	i.statusHealth = i.statusLabel("health", i.healthColor, "")
+	Bind(i.statusHealth, "<Button-1>", Command(i.onHealthClick))
+	i.statusHealth.Configure(Cursor("hand2"))
+	i.statusJobs = i.statusLabel("jobs", fg, "")
+	Bind(i.statusJobs, "<Button-1>", Command(i.onJobsSegmentClick))
+	i.statusJobs.Configure(Cursor("hand2"))
+	i.makeEncodingMenu()
+	i.makeEOLMenu()
+	i.statusLabelFile = i.statusLabel("file", nil, "save")
+	i.statusLabelFile.Configure(Txt(tr(statusNotSaved)))
+	i.statusReadOnly = i.statusLabel("readOnly", func() string { return theme.Warning }, "toggleReadOnly")
+	i.statusProvider = i.statusLabel("provider", func() string { return theme.Warning }, "")
+	i.statusLabelServer = i.statusLabel("server", func() string { return theme.Success }, "")
+	Bind(i.statusLabelServer, "<Button-1>", Command(i.onServerBadgeClick))
+	i.statusLabelServer.Configure(Cursor("hand2"))
+	i.statusModuleName = i.statusLabel("module", func() string { return theme.Muted }, "")
+	Bind(i.statusModuleName, "<Button-1>", Command(i.onModuleSegmentClick))
+	i.statusModuleName.Configure(Cursor("hand2"))
+	i.statusLabelModule = i.statusLabel("moduleMode", func() string { return theme.Warning }, "toggleOffline")
+	i.makeBuildTagsMenu()
+	i.makeBranchMenu()
+	for _, provider := range statusProviders {
+		provider(i)
+	}
+}
+
+// addStatusSegment adds w, a child of statusFrame, as the segment id.
+func (i *Ite) addStatusSegment(id string, w *Window, color func() string) {
+	i.statusSegments = append(i.statusSegments, &statusSegment{id: id, w: w, color: color})
+	i.colorStatusSegment(i.statusSegments[len(i.statusSegments)-1])
+}
+
+// statusLabel adds a text segment colored by color. Clicking it runs the
+// command cmd, if not empty.
+func (i *Ite) statusLabel(id string, color func() string, cmd string) *TLabelWidget {
+	label := i.statusFrame.TLabel(Font(statusFont, statusFontSize))
+	if cmd != "" {
+		c := i.mustCommand(cmd)
+		label.Configure(Cursor("hand2"))
+		Bind(label, "<Button-1>", Command(func() { c.run() }))
+	}
+	i.addStatusSegment(id, label.Window, color)
+	return label
+}
+
+// statusMenu adds a segment showing a menu when clicked, which the caller
+// fills and attaches.
+func (i *Ite) statusMenu(id string) *MenubuttonWidget {
+	button := i.statusFrame.Menubutton(Relief(FLAT), Font(statusFont, statusFontSize))
+	i.addStatusSegment(id, button.Window, func() string { return theme.Foreground })
+	return button
+}
+
+// layoutStatusbar grids the segments in order.
+func (i *Ite) layoutStatusbar() {
+	for n, s := range i.statusSegments {
+		Grid(s.w, Row(0), Column(n), Sticky(WE), Padx(px(3)))
+		if s.expand {
+			GridColumnConfigure(i.statusFrame, n, Weight(1))
+		}
+	}
+}
+
+// colorStatusSegment applies the theme to s.
+func (i *Ite) colorStatusSegment(s *statusSegment) {
+	s.w.Configure(Background(theme.Text))
+	if s.color != nil {
+		s.w.Configure(Foreground(s.color()))
+	}
+}
+
+// colorStatusbar applies the theme to every segment.
+func (i *Ite) colorStatusbar() {
+	for _, s := range i.statusSegments {
+		i.colorStatusSegment(s)
+	}
+}
+
+// updateModuleSegment shows the path of the module of the current file,
+// reading its go.mod only when the file changes.
+func (i *Ite) updateModuleSegment() {
+	if i.currentFile == i.moduleFile {
+		return
+	}
+	i.moduleFile = i.currentFile
+	name := ""
+	if i.currentFile != "" {
+		name = modulePath(filepath.Join(projectRoot(i.currentFile), "go.mod"))
+	}
+	i.statusModuleName.Configure(Txt(name))
+}
+
+// onModuleSegmentClick opens the go.mod of the current file.
+func (i *Ite) onModuleSegmentClick() {
+	if i.currentFile == "" {
+		return
+	}
+	path := filepath.Join(projectRoot(i.currentFile), "go.mod")
+	if fileExists(path) {
+		i.showLocation(location{path: path, line: 1, col: 1})
+	}
+}
+
+// updateJobsSegment shows the spinner while commands run or files are
+// saved, starting its animation if needed. startJob and writeInBackground
+// call it.
+func (i *Ite) updateJobsSegment() {
+	if i.spinning {
+		return
+	}
+	i.spinning = true
+	i.animateSpinner()
+}
+
+// animateSpinner shows the next frame of the spinner, the names of the
+// consoles running a command and the files being saved, then schedules
+// itself until none is left.
+func (i *Ite) animateSpinner() {
+	var names string
+	for _, c := range i.consoles {
+		if i.consoleBusy(c) {
+			if names != "" {
+				names += ", "
+			}
+			names += c.name
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(i.saving)) {
+		if names != "" {
+			names += ", "
+		}
+		names += "saving " + filepath.Base(path)
+	}
+	if names == "" {
+		i.spinning = false
+		i.statusJobs.Configure(Txt(""))
+		return
+	}
+	i.spinnerFrame = (i.spinnerFrame + 1) % len(spinnerFrames)
+	i.statusJobs.Configure(Txt(spinnerFrames[i.spinnerFrame] + " " + names))
+	TclAfter(spinnerInterval, i.animateSpinner)
+}
+
+// consoleBusy reports whether a command of c is running.
+func (i *Ite) consoleBusy(c *console) bool {
+	for _, j := range i.jobs {
+		if j.c == c {
+			return true
+		}
+	}
+	return false
+}
+
+// onJobsSegmentClick shows the console of the first running command.
+func (i *Ite) onJobsSegmentClick() {
+	for _, c := range i.consoles {
+		if i.consoleBusy(c) {
+			i.consoleTabs.Select(c.frame)
+			i.focusPanel(panelConsole)
+			return
+		}
+	}
+}
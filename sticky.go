@@ -0,0 +1,168 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Sticky Scroll
+// -------------------------------------------------------------------------
+
+// While the first line of a func, func literal, struct or interface type
+// is scrolled out of view and its body is still in view, the line stays
+// pinned at the top of the editor, the outermost scope first, so a long
+// body always shows what it belongs to. The scopes come from the parse
+// the outline is built from, so only Go files have them. Clicking a
+// pinned line goes to it.
+const maxStickyLines = 3
+
+// stickyScope is a declaration whose first line is pinned while its body
+// is in view.
+type stickyScope struct {
+	line, last int // 1-based lines of the signature and the closing brace
+}
+
+// stickyScroll holds the scopes of the buffer and the view pinning them.
+type stickyScroll struct {
+	scopes  []stickyScope // Outermost first, in source order
+	view    *TextWidget   // Pinned lines, nil until first shown
+	owner   *TextWidget   // Editor view is placed in
+	lines   []int         // Lines pinned
+	pending bool          // The view is to be updated
+}
+
+// stickyScopes returns the funcs, func literals, and struct and interface
+// types of the parsed Go file spanning several lines, outermost first.
+func stickyScopes(fset *token.FileSet, file *ast.File) []stickyScope {
+	var scopes []stickyScope
+	add := func(from, to token.Pos) {
+		if !from.IsValid() || !to.IsValid() {
+			return
+		}
+		s := stickyScope{fset.Position(from).Line, fset.Position(to).Line}
+		if s.last > s.line {
+			scopes = append(scopes, s)
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				add(n.Pos(), n.Body.Rbrace)
+			}
+		case *ast.FuncLit:
+			add(n.Pos(), n.Body.Rbrace)
+		case *ast.TypeSpec:
+			switch t := n.Type.(type) {
+			case *ast.StructType:
+				add(n.Pos(), t.Fields.Closing)
+			case *ast.InterfaceType:
+				add(n.Pos(), t.Methods.Closing)
+			}
+		}
+		return true
+	})
+	return scopes
+}
+
+// stickyLines returns the first lines of the scopes pinned while top is
+// the first line in view, outermost first: as many as there are scopes
+// around the first line left visible below them, at most limit.
+func stickyLines(scopes []stickyScope, top, limit int) []int {
+	for n := limit; n > 0; n-- {
+		probe := top + n // Pinning n lines covers the lines down to it
+		var lines []int
+		for _, s := range scopes {
+			if s.line < probe && probe <= s.last {
+				lines = append(lines, s.line)
+			}
+		}
+		if len(lines) >= n {
+			return lines[:n]
+		}
+	}
+	return nil
+}
+
+// scheduleStickyScroll updates the pinned lines once Tk is idle.
+func (i *Ite) scheduleStickyScroll() {
+	if i.sticky.pending {
+		return
+	}
+	i.sticky.pending = true
+	TclAfterIdle(func() {
+		i.sticky.pending = false
+		i.updateStickyScroll()
+	})
+}
+
+// updateStickyScroll pins the first lines of the scopes around the top of
+// the active editor, hiding the view when there are none.
+func (i *Ite) updateStickyScroll() {
+	s := &i.sticky
+	var lines []int
+	if i.config.StickyScroll && !i.largeFile {
+		top, _ := parseIndex(i.editText.Index("@0,0"))
+		lines = stickyLines(s.scopes, top, maxStickyLines)
+	}
+	if len(lines) == 0 {
+		if s.view != nil {
+			tclEval("place forget %s", s.view)
+		}
+		s.lines = nil
+		return
+	}
+	if s.owner != i.editText {
+		i.makeStickyView()
+	}
+	s.lines = lines
+	v := s.view
+	v.Configure(State("normal"), Height(len(lines)), Tabs(tabStops()),
+		Background(theme.Frame), Foreground(theme.Foreground), Highlightbackground(theme.Frame))
+	v.Delete("1.0", "end")
+	for n, line := range lines {
+		if n > 0 {
+			v.Insert("end", "\n")
+		}
+		v.Insert("end", lineText(i.editText, line))
+	}
+	v.Configure(State("disabled"))
+	Place(v, X(0), Y(0), Relwidth(1))
+}
+
+// makeStickyView creates the view of the pinned lines in the active
+// editor, removing the one of another pane.
+func (i *Ite) makeStickyView() {
+	s := &i.sticky
+	if s.view != nil {
+		Destroy(s.view)
+	}
+	s.owner = i.editText
+	s.view = i.editText.Window.Text(textStyle(), Wrap("none"), Undo(false), Takefocus(false), Cursor("hand2"))
+	Bind(s.view, "<Button-1>", Command(func(e *Event) {
+		row, _ := parseIndex(mouseIndex(s.view, e))
+		if row < 1 || row > len(s.lines) {
+			return
+		}
+		line := s.lines[row-1]
+		text := lineText(i.editText, line)
+		i.jumpTo(line, len(text)-len(strings.TrimLeft(text, " \t")))
+		Focus(i.editText)
+	}))
+}
+
+// onToggleStickyScroll switches sticky scroll on or off and persists the
+// choice.
+func (i *Ite) onToggleStickyScroll() {
+	i.config.StickyScroll = !i.config.StickyScroll
+	i.stickyVar.Set(checkValue(i.config.StickyScroll))
+	i.saveConfig()
+	i.updateStickyScroll()
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"slices"
+	"testing"
+)
+
+func TestStickyLines(t *testing.T) {
+	const src = `package p
+
+type T struct {
+	a int
+	b int
+}
+
+func f() {
+	x := 1
+	g := func() {
+		x++
+		x++
+	}
+	g()
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopes := stickyScopes(fset, file)
+	want := []stickyScope{{3, 6}, {8, 15}, {10, 13}}
+	if !slices.Equal(scopes, want) {
+		t.Fatalf("stickyScopes = %v, want %v", scopes, want)
+	}
+	tests := []struct {
+		top  int
+		want []int
+	}{
+		{1, nil},
+		{4, []int{3}},
+		{7, nil},
+		{9, []int{8, 10}}, // The literal begins under the line pinned
+		{10, []int{8, 10}},
+		{12, []int{8}}, // Pinning the literal would cover its end
+		{14, []int{8}},
+		{16, nil},
+	}
+	for _, tt := range tests {
+		if got := stickyLines(scopes, tt.top, maxStickyLines); !slices.Equal(got, tt.want) {
+			t.Errorf("stickyLines(top %d) = %v, want %v", tt.top, got, tt.want)
+		}
+	}
+	if got := stickyLines(scopes, 12, 1); !slices.Equal(got, []int{8}) {
+		t.Errorf("stickyLines(top 12, limit 1) = %v, want [8]", got)
+	}
+}
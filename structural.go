@@ -0,0 +1,656 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Structural Search and Replace
+// -------------------------------------------------------------------------
+
+const (
+	wildcardPrefix = "__ite_" // Identifier standing for a $name wildcard in a parsed pattern
+	scopeFile      = "Current file"
+	scopeProject   = "Project"
+	tagStructOld   = "structold" // Matched code in the results
+	tagStructNew   = "structnew" // Its replacement
+)
+
+// wildcardRe matches a $name wildcard, optionally followed by "..." to
+// stand for the remaining arguments of a call.
+var wildcardRe = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)(\.\.\.)?`)
+
+var (
+	identType = reflect.TypeFor[*ast.Ident]()
+	exprType  = reflect.TypeFor[ast.Expr]()
+	posType   = reflect.TypeFor[token.Pos]()
+)
+
+// structRule is a parsed structural search: a Go expression pattern in
+// which $name matches any expression, and the template it is rewritten to.
+type structRule struct {
+	pattern ast.Expr
+	replace string // "" to search only
+}
+
+// binding is the code a wildcard matched.
+type binding struct {
+	text     string // Source text
+	key      string // Formatting-independent form, to compare repeated wildcards
+	list     bool   // Bound the remaining call arguments by $name...
+	ellipsis bool   // The call passed its last argument with ...
+	compound bool   // Needs parentheses when not delimited, as in $x.Method()
+}
+
+// structMatch is an expression matching a structRule.
+type structMatch struct {
+	path       string
+	line, col  int // 1-based, col in UTF-8 bytes
+	start, end int // Byte offsets in the searched source
+	before     string
+	after      string
+}
+
+// structFile holds the matches in a file and the source they refer to.
+type structFile struct {
+	path    string
+	src     []byte
+	buffer  bool // src is the text of an open buffer, not of the file on disk
+	matches []structMatch
+}
+
+// structResult is the outcome of a structural search, delivered to
+// showStructuralResult.
+type structResult struct {
+	id        int
+	rule      *structRule
+	files     []structFile
+	searched  int // Go files searched
+	broken    int // Go files that didn't parse
+	truncated bool
+	err       error
+}
+
+// parseStructRule parses pattern and its replacement template.
+func parseStructRule(pattern, replace string) (*structRule, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New(errEmptySearch)
+	}
+	expr, err := parser.ParseExpr(wildcardRe.ReplaceAllString(pattern, wildcardPrefix+"${1}${2}"))
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := expr.(*ast.Ident); ok && isWildcard(id) {
+		return nil, errors.New("the pattern matches every expression")
+	}
+	rule := &structRule{pattern: expr, replace: strings.TrimSpace(replace)}
+	if rule.replace == "" {
+		return rule, nil
+	}
+	names := make(map[string]bool)
+	for _, m := range wildcardRe.FindAllStringSubmatch(pattern, -1) {
+		names[m[1]] = true
+	}
+	for _, m := range wildcardRe.FindAllStringSubmatch(rule.replace, -1) {
+		if !names[m[1]] {
+			return nil, fmt.Errorf("$%s is not in the pattern", m[1])
+		}
+	}
+	if _, err := parser.ParseExpr(wildcardRe.ReplaceAllString(rule.replace, wildcardPrefix+"${1}${2}")); err != nil {
+		return nil, fmt.Errorf("replacement: %v", err)
+	}
+	return rule, nil
+}
+
+// isWildcard reports whether id stands for a $name wildcard.
+func isWildcard(id *ast.Ident) bool {
+	return id != nil && strings.HasPrefix(id.Name, wildcardPrefix)
+}
+
+// findStructural returns the outermost expressions of src matching rule.
+func findStructural(path string, src []byte, rule *structRule) ([]structMatch, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	tf := fset.File(file.Pos())
+	m := &structMatcher{tf: tf, src: src}
+	var matches []structMatch
+	ast.Inspect(file, func(n ast.Node) bool {
+		e, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		m.binds = make(map[string]binding)
+		if !m.match(reflect.ValueOf(rule.pattern), reflect.ValueOf(e)) {
+			return true
+		}
+		pos := tf.Position(e.Pos())
+		start, end := tf.Offset(e.Pos()), tf.Offset(e.End())
+		match := structMatch{path: path, line: pos.Line, col: pos.Column,
+			start: start, end: end, before: string(src[start:end])}
+		if rule.replace != "" {
+			match.after = rule.expand(m.binds)
+		}
+		matches = append(matches, match)
+		return false // Matches don't nest, so rewriting them never overlaps
+	})
+	return matches, nil
+}
+
+// structMatcher compares a pattern with the syntax tree of a file,
+// recording what the wildcards bind.
+type structMatcher struct {
+	tf    *token.File
+	src   []byte
+	binds map[string]binding
+}
+
+// match reports whether the pattern node pat matches the node val. Like
+// gofmt -r, it compares the trees field by field, ignoring positions and
+// comments.
+func (m *structMatcher) match(pat, val reflect.Value) bool {
+	if pat.Kind() == reflect.Interface {
+		if pat.IsNil() || val.IsNil() {
+			return pat.IsNil() && val.IsNil()
+		}
+		pat, val = pat.Elem(), val.Elem()
+	}
+	if pat.Type() == identType && val.Type().Implements(exprType) && !val.IsNil() {
+		if id := pat.Interface().(*ast.Ident); isWildcard(id) {
+			return m.bind(id.Name, m.exprBinding(val.Interface().(ast.Expr)))
+		}
+	}
+	if pat.Type() != val.Type() {
+		return false
+	}
+	if call, ok := pat.Interface().(*ast.CallExpr); ok && call != nil && variadicWildcard(call) != nil {
+		return m.matchVariadic(call, val.Interface().(*ast.CallExpr))
+	}
+
+	switch pat.Kind() {
+	case reflect.Pointer:
+		if pat.IsNil() || val.IsNil() {
+			return pat.IsNil() && val.IsNil()
+		}
+		return m.match(pat.Elem(), val.Elem())
+	case reflect.Slice:
+		if pat.Len() != val.Len() {
+			return false
+		}
+		for k := range pat.Len() {
+			if !m.match(pat.Index(k), val.Index(k)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for k := range pat.NumField() {
+			field := pat.Type().Field(k)
+			switch field.Type {
+			case posType:
+				// Only a call's ... changes the meaning of the code
+				if field.Name == "Ellipsis" &&
+					pat.Field(k).Interface().(token.Pos).IsValid() != val.Field(k).Interface().(token.Pos).IsValid() {
+					return false
+				}
+				continue
+			case reflect.TypeFor[*ast.Object](), reflect.TypeFor[*ast.Scope](), reflect.TypeFor[*ast.CommentGroup]():
+				continue
+			}
+			if !m.match(pat.Field(k), val.Field(k)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return pat.Interface() == val.Interface()
+	}
+}
+
+// variadicWildcard returns the wildcard of a pattern call ending with
+// $name..., or nil.
+func variadicWildcard(call *ast.CallExpr) *ast.Ident {
+	if !call.Ellipsis.IsValid() || len(call.Args) == 0 {
+		return nil
+	}
+	if id, ok := call.Args[len(call.Args)-1].(*ast.Ident); ok && isWildcard(id) {
+		return id
+	}
+	return nil
+}
+
+// matchVariadic matches a call whose pattern ends with $name..., which
+// binds the arguments left after the fixed ones, possibly none.
+func (m *structMatcher) matchVariadic(pat, val *ast.CallExpr) bool {
+	fixed := len(pat.Args) - 1
+	if len(val.Args) < fixed || !m.match(reflect.ValueOf(pat.Fun), reflect.ValueOf(val.Fun)) {
+		return false
+	}
+	for k := range fixed {
+		if !m.match(reflect.ValueOf(pat.Args[k]), reflect.ValueOf(val.Args[k])) {
+			return false
+		}
+	}
+	rest := val.Args[fixed:]
+	b := binding{list: true, ellipsis: val.Ellipsis.IsValid()}
+	if len(rest) > 0 {
+		b.text = string(m.src[m.tf.Offset(rest[0].Pos()):m.tf.Offset(rest[len(rest)-1].End())])
+		keys := make([]string, len(rest))
+		for k, arg := range rest {
+			keys[k] = types.ExprString(arg)
+		}
+		b.key = strings.Join(keys, ", ")
+	}
+	if b.ellipsis {
+		b.key += "..."
+	}
+	return m.bind(variadicWildcard(pat).Name, b)
+}
+
+// exprBinding returns the binding of a wildcard matching e.
+func (m *structMatcher) exprBinding(e ast.Expr) binding {
+	b := binding{
+		text: string(m.src[m.tf.Offset(e.Pos()):m.tf.Offset(e.End())]),
+		key:  types.ExprString(e),
+	}
+	switch e.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.CompositeLit, *ast.ParenExpr, *ast.SelectorExpr,
+		*ast.IndexExpr, *ast.IndexListExpr, *ast.SliceExpr, *ast.TypeAssertExpr, *ast.CallExpr:
+	default:
+		b.compound = true
+	}
+	return b
+}
+
+// bind records what the wildcard name matched. A wildcard used more than
+// once must match the same code each time.
+func (m *structMatcher) bind(name string, b binding) bool {
+	name = strings.TrimPrefix(name, wildcardPrefix)
+	if prev, ok := m.binds[name]; ok {
+		return prev.key == b.key
+	}
+	m.binds[name] = b
+	return true
+}
+
+// expand returns the replacement template with the wildcards replaced by
+// the code they bound.
+func (r *structRule) expand(binds map[string]binding) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range wildcardRe.FindAllStringSubmatchIndex(r.replace, -1) {
+		sb.WriteString(r.replace[last:loc[0]])
+		last = loc[1]
+		b := binds[r.replace[loc[2]:loc[3]]]
+		switch {
+		case loc[4] >= 0 && b.list && b.text == "":
+			// No arguments left: drop the comma before them
+			s := strings.TrimRight(sb.String(), " \t\n")
+			sb.Reset()
+			sb.WriteString(strings.TrimSuffix(s, ","))
+		case loc[4] >= 0 && b.list:
+			sb.WriteString(b.text)
+			if b.ellipsis {
+				sb.WriteString("...")
+			}
+		case b.compound && !delimited(r.replace, loc[0], loc[1]):
+			sb.WriteString("(" + b.text + ")")
+		default:
+			sb.WriteString(b.text)
+		}
+		if loc[4] >= 0 && !b.list {
+			sb.WriteString("...")
+		}
+	}
+	sb.WriteString(r.replace[last:])
+	return sb.String()
+}
+
+// delimited reports whether the template text between start and end stands
+// alone, as a call argument or list element, so that an expression put
+// there needs no parentheses.
+func delimited(s string, start, end int) bool {
+	before := strings.TrimRight(s[:start], " \t\n")
+	after := strings.TrimLeft(s[end:], " \t\n")
+	open := before == "" || strings.ContainsAny(before[len(before)-1:], "(,[{:")
+	closed := after == "" || strings.ContainsAny(after[:1], "),]}")
+	return open && closed
+}
+
+// searchStructural looks for rule in the Go files below root but those
+// matching exclude, or only in file when it isn't "". Files with unsaved
+// changes are searched as edited, through buffers.
+func searchStructural(root string, exclude []string, file string, rule *structRule, buffers map[string]string) structResult {
+	res := structResult{rule: rule}
+	total := 0
+	visit := func(path string) error {
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		f := structFile{path: path}
+		if text, ok := buffers[path]; ok {
+			f.src, f.buffer = []byte(text), true
+		} else {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			f.src = src
+		}
+		res.searched++
+		matches, err := findStructural(path, f.src, rule)
+		if err != nil {
+			res.broken++
+			return nil
+		}
+		if len(matches) > 0 {
+			f.matches = matches
+			res.files = append(res.files, f)
+			total += len(matches)
+		}
+		if total >= maxFindHits {
+			res.truncated = true
+			return filepath.SkipAll
+		}
+		return nil
+	}
+	if file != "" {
+		visit(file)
+	} else {
+		res.err = walkProject(root, exclude, visit)
+	}
+	return res
+}
+
+// -------------------------------------------------------------------------
+// Structural Replace Window
+// -------------------------------------------------------------------------
+
+// structPanel holds the widgets of the Structural Replace window.
+type structPanel struct {
+	window  *ToplevelWidget
+	pattern *TEntryWidget
+	replace *TEntryWidget
+	scope   *TComboboxWidget
+	status  *TLabelWidget
+	results *TextWidget
+	root    string
+	exclude []string // Exclude patterns of the project
+	res     structResult
+	matches map[int]structMatch // Matches by results line
+	id      int                 // Latest search
+}
+
+// onStructuralReplace opens the Structural Replace window. Patterns are Go
+// expressions in which $name matches any expression, so fmt.Errorf($msg)
+// finds every single-argument call of fmt.Errorf however it is formatted.
+// The replacement refers to the wildcards the same way; $name... as the
+// last argument of a call stands for the remaining arguments. Find lists
+// each match with its rewrite, Apply rewrites them all.
+func (i *Ite) onStructuralReplace() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.structural != nil {
+		Destroy(i.structural.window)
+	}
+	p := &structPanel{window: Toplevel(), root: i.projectDir(), exclude: i.projectSettingsOrError().Exclude}
+	p.window.WmTitle("Structural Replace - " + p.root)
+
+	top := p.window.TFrame()
+	Grid(top, Row(0), Column(0), Sticky(WE), Padx(px(5)), Pady(px(5)))
+	Grid(top.TLabel(Txt("Pattern:")), Row(0), Column(0), Sticky(W), Padx(px(2)))
+	initial := ""
+	if sel := i.editText.TagRanges("sel"); len(sel) >= 2 {
+		initial = i.editText.Get(sel[0], sel[len(sel)-1])[0]
+	}
+	p.pattern = top.TEntry(Width(60), Textvariable(initial))
+	Grid(p.pattern, Row(0), Column(1), Sticky(WE))
+	Grid(top.TLabel(Txt("Replace:")), Row(1), Column(0), Sticky(W), Padx(px(2)))
+	p.replace = top.TEntry(Width(60), Textvariable(""))
+	Grid(p.replace, Row(1), Column(1), Sticky(WE))
+	p.scope = top.TCombobox(Values([]string{scopeFile, scopeProject}), State("readonly"),
+		Width(12), Textvariable(scopeProject))
+	Grid(p.scope, Row(0), Column(2), Padx(px(2)))
+	Grid(top.TButton(Txt("Find"), Command(i.runStructuralSearch)), Row(0), Column(3), Padx(px(2)))
+	Grid(top.TButton(Txt("Apply"), Command(i.applyStructural)), Row(1), Column(3), Padx(px(2)))
+	GridColumnConfigure(top, 1, Weight(1))
+
+	p.results = p.window.Text(textStyle(), Width(100), Height(25), Wrap("none"), State("disabled"))
+	scrollbar := p.window.TScrollbar(Command(func(e *Event) { e.Yview(p.results) }))
+	p.results.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	Grid(p.results, Row(1), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(1), Column(1), Sticky(NS))
+	p.status = p.window.TLabel(Txt("$name matches any expression, $name... the remaining call arguments"))
+	Grid(p.status, Row(2), Column(0), Sticky(W), Padx(px(5)))
+	GridRowConfigure(p.window, 1, Weight(1))
+	GridColumnConfigure(p.window, 0, Weight(1))
+
+	p.results.TagConfigure(tagLink, Foreground(theme.Link))
+	p.results.TagConfigure(tagStructOld, Background(theme.DiffOld))
+	p.results.TagConfigure(tagStructNew, Background(theme.DiffNew))
+	showMatch := func(line int) {
+		if m, ok := p.matches[line]; ok {
+			i.showLocation(location{path: m.path, line: m.line, col: m.col})
+		}
+	}
+	p.results.TagBind(tagLink, "<Button-1>", func() {
+		line, _ := parseIndex(p.results.Index("current"))
+		showMatch(line)
+	})
+	bindPanelKeys(p.results, showMatch)
+	p.results.TagBind(tagLink, "<Enter>", func() { p.results.Configure(Cursor("hand2")) })
+	p.results.TagBind(tagLink, "<Leave>", func() { p.results.Configure(Cursor("xterm")) })
+
+	closeWindow := func() {
+		Destroy(p.window)
+		i.structural = nil
+	}
+	Bind(p.pattern, "<Return>", Command(i.runStructuralSearch))
+	Bind(p.replace, "<Return>", Command(i.runStructuralSearch))
+	Bind(p.window, "<Escape>", Command(closeWindow))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	i.bindFocusKeys(p.window.Window)
+	Focus(p.pattern)
+	i.structural = p
+}
+
+// runStructuralSearch starts a search with the pattern of the Structural
+// Replace window, superseding any search still running.
+func (i *Ite) runStructuralSearch() {
+	p := i.structural
+	rule, err := parseStructRule(p.pattern.Textvariable(), p.replace.Textvariable())
+	if err != nil {
+		p.status.Configure(Txt("Invalid pattern: " + err.Error()))
+		return
+	}
+	file := ""
+	if p.scope.Textvariable() == scopeFile {
+		if i.currentFile == "" {
+			p.status.Configure(Txt(statusNoFile))
+			return
+		}
+		file, _ = filepath.Abs(i.currentFile)
+	}
+	buffers := i.modifiedBuffers()
+	p.id++
+	id, root, exclude := p.id, p.root, p.exclude
+	p.status.Configure(Txt("Searching..."))
+	go func() {
+		res := searchStructural(root, exclude, file, rule, buffers)
+		res.id = id
+		i.Dispatch(func() { i.showStructuralResult(res) })
+	}()
+}
+
+// showStructuralResult shows the result of a search, unless a newer one
+// was started or the window closed since.
+func (i *Ite) showStructuralResult(res structResult) {
+	if i.structural != nil && res.id == i.structural.id {
+		i.structural.show(res)
+	}
+}
+
+// show lists the matches of res, one "path:line: code → rewrite" line
+// each, with multi-line code shown on one line, those in unsaved buffers
+// apart.
+func (p *structPanel) show(res structResult) {
+	p.res = res
+	p.results.Configure(State("normal"))
+	p.results.Delete("1.0", "end")
+	p.matches = insertEditPreview(p.results, p.root, res.files, 1, res.rule.replace != "")
+	p.results.Configure(State("disabled"))
+
+	disk, buffer := countEdits(res.files)
+	status := fmt.Sprintf("%d matches in %d of %d Go files", disk+buffer, len(res.files), res.searched)
+	if buffer > 0 {
+		status += fmt.Sprintf(", %d in unsaved buffers", buffer)
+	}
+	if res.broken > 0 {
+		status += fmt.Sprintf(", %d with syntax errors skipped", res.broken)
+	}
+	switch {
+	case res.err != nil:
+		status += " (" + res.err.Error() + ")"
+	case res.truncated:
+		status += fmt.Sprintf(" (stopped after %d)", maxFindHits)
+	}
+	p.status.Configure(Txt(status))
+}
+
+// previewCode collapses code to a single line no longer than
+// findPreviewLen characters.
+func previewCode(code string) string {
+	code = strings.Join(strings.Fields(code), " ")
+	if runes := []rune(code); len(runes) > findPreviewLen {
+		code = string(runes[:findPreviewLen]) + "…"
+	}
+	return code
+}
+
+// applyStructural rewrites the matches listed in the Structural Replace
+// window, all or none. Unsaved buffers are edited as one undo step each,
+// leaving read-only regions alone; other files are rewritten on disk, as
+// one file operation, and their buffers reloaded. Nothing changes if a
+// file was edited since the search.
+func (i *Ite) applyStructural() {
+	p := i.structural
+	if p.res.rule == nil || len(p.res.files) == 0 {
+		p.status.Configure(Txt("Nothing to replace: run Find first"))
+		return
+	}
+	if p.res.rule.replace == "" {
+		p.status.Configure(Txt("Nothing to replace: the replacement is empty"))
+		return
+	}
+	files := p.res.files
+	if err := i.checkFileEdits(p.root, files); err != nil {
+		p.status.Configure(Txt(err.Error() + ": run Find again"))
+		return
+	}
+	op := i.beginFileOp("Structural Replace")
+	disk, err := writeFileEdits(op, p.root, files)
+	if err != nil {
+		removeBackups(op.op)
+		p.status.Configure(Txt("Nothing replaced: " + err.Error()))
+		return
+	}
+	i.commitFileOp(op)
+	i.reloadEditedBuffers(files)
+	buffer := i.applyBufferEdits(files)
+
+	p.res = structResult{}
+	p.matches = nil
+	p.results.Configure(State("normal"))
+	p.results.Delete("1.0", "end")
+	p.results.Configure(State("disabled"))
+	p.status.Configure(Txt("Applied " + editSummary(files, disk, buffer)))
+}
+
+// paneEditing returns the pane editing the file at path, or nil.
+func (i *Ite) paneEditing(path string) *editorPane {
+	for _, p := range i.panes {
+		file := p.file
+		if p == i.active {
+			file = i.currentFile
+		}
+		if samePath(file, path) {
+			return p
+		}
+	}
+	return nil
+}
+
+// errChangedSinceSearch reports a file edited after the search.
+var errChangedSinceSearch = errors.New("changed since the search")
+
+// rewriteBuffer replaces the matches of f in the active buffer, back to
+// front so the offsets of earlier matches stay valid.
+func (i *Ite) rewriteBuffer(f structFile) (int, error) {
+	if i.editText.Get("1.0", "end-1c")[0] != string(f.src) {
+		return 0, errChangedSinceSearch
+	}
+	n := 0
+	i.editGroup(func() {
+		for k := len(f.matches) - 1; k >= 0; k-- {
+			m := f.matches[k]
+			start := i.editText.Index(fmt.Sprintf("1.0 +%dc", utf8.RuneCount(f.src[:m.start])))
+			end := i.editText.Index(fmt.Sprintf("1.0 +%dc", utf8.RuneCount(f.src[:m.end])))
+			if i.isProtected(start, end) {
+				continue
+			}
+			i.editText.Delete(start, end)
+			i.editText.Insert(start, m.after)
+			n++
+		}
+	})
+	i.refreshCursorState()
+	return n, nil
+}
+
+// rewriteFile replaces the matches of f in the file on disk.
+func rewriteFile(f structFile) (int, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return 0, err
+	}
+	src, err := os.ReadFile(f.path)
+	if err != nil {
+		return 0, err
+	}
+	if f.buffer || !bytes.Equal(src, f.src) {
+		return 0, errChangedSinceSearch
+	}
+	var out bytes.Buffer
+	last := 0
+	for _, m := range f.matches {
+		out.Write(src[last:m.start])
+		out.WriteString(m.after)
+		last = m.end
+	}
+	out.Write(src[last:])
+	if err := os.WriteFile(f.path, out.Bytes(), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	return len(f.matches), nil
+}
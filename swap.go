@@ -0,0 +1,231 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Swap Files
+// -------------------------------------------------------------------------
+
+const (
+	swapSuffix   = ".ite-swap"
+	untitledSwap = "untitled"                           // Name of the swap files of unnamed buffers, in the config dir
+	swapMagic    = "ite-swap"                           // First word of the header line of a swap file
+	maxSwapSlots = 10                                   // Swap files of a path, for the buffers editing it at once
+	swapStale    = 2 * maxAutosaveSeconds * time.Second // Age orphaning a swap file of another host
+)
+
+// A swap file starts with a header line naming its owner, the editor
+// writing it, by process id and host, as in
+//
+//	ite-swap 4242 workstation
+//
+// and holds the buffer after it. Each buffer editing a path, in a pane or
+// in another instance, writes a swap file of its own, so the path has a
+// few slots, and a swap file is only offered for recovery, or removed,
+// once its owner is gone. The owner on another host can't be asked: its
+// swap file is taken as orphaned when it hasn't been rewritten for a
+// while, as a running editor rewrites it at every autosave.
+
+// swapOwner is the editor writing a swap file.
+type swapOwner struct {
+	pid  int // 0 for a swap file of earlier versions, without header
+	host string
+}
+
+// currentSwapOwner returns the owner of the swap files of this editor.
+func currentSwapOwner() swapOwner {
+	host, _ := os.Hostname()
+	return swapOwner{pid: os.Getpid(), host: host}
+}
+
+// encodeSwap returns the swap file of text written by owner.
+func encodeSwap(owner swapOwner, text string) []byte {
+	return []byte(fmt.Sprintf("%s %d %s\n%s", swapMagic, owner.pid, owner.host, text))
+}
+
+// decodeSwap returns the owner and the buffer of a swap file. A file
+// without header is all buffer.
+func decodeSwap(data []byte) (swapOwner, string) {
+	header, text, ok := strings.Cut(string(data), "\n")
+	fields := strings.Fields(header)
+	if !ok || len(fields) < 2 || fields[0] != swapMagic {
+		return swapOwner{}, string(data)
+	}
+	pid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return swapOwner{}, string(data)
+	}
+	owner := swapOwner{pid: pid}
+	if len(fields) > 2 {
+		owner.host = fields[2]
+	}
+	return owner, text
+}
+
+// swapPaths returns the swap file slots of the buffer editing path:
+// hidden files next to it, e.g. ".main.go.ite-swap" then
+// ".main.go~1.ite-swap", or files in the config directory for an untitled
+// buffer.
+func swapPaths(path string) ([]string, error) {
+	dir, name := filepath.Dir(path), "."+filepath.Base(path)
+	if path == "" {
+		var err error
+		if dir, err = configDir(); err != nil {
+			return nil, err
+		}
+		name = untitledSwap
+	}
+	paths := []string{filepath.Join(dir, name+swapSuffix)}
+	for n := 1; n < maxSwapSlots; n++ {
+		paths = append(paths, filepath.Join(dir, name+"~"+strconv.Itoa(n)+swapSuffix))
+	}
+	return paths, nil
+}
+
+// heldByPane reports whether a pane of this editor other than the active
+// one writes the swap file path.
+func (i *Ite) heldByPane(path string) bool {
+	for _, p := range i.panes {
+		if p != i.active && p.swapFile == path {
+			return true
+		}
+	}
+	return false
+}
+
+// swapOwned reports whether the swap file path, last written at mod by
+// owner, belongs to a running editor other than the active buffer.
+func (i *Ite) swapOwned(path string, owner swapOwner, mod time.Time) bool {
+	self := currentSwapOwner()
+	switch {
+	case owner.pid == 0:
+		return false
+	case owner.host != self.host:
+		return time.Since(mod) < swapStale
+	case owner.pid == self.pid:
+		return i.heldByPane(path)
+	}
+	return processAlive(owner.pid)
+}
+
+// autosaveSwap writes the buffers to their swap files, then reschedules
+// itself. A failure is reported in the status bar, once until a swap file
+// is written again: the swap file is a safety net, not the user's data.
+// Swap files are readable by the user only, as they copy files that may
+// be private.
+func (i *Ite) autosaveSwap() {
+	defer TclAfter(i.autosaveInterval(), i.autosaveSwap)
+	for _, p := range i.panes {
+		i.withPane(p, i.writeSwap)
+	}
+}
+
+// writeSwap writes the buffer of the active pane to its swap file when it
+// has unsaved changes. The buffer keeps the slot it got first, the first
+// without a swap file.
+func (i *Ite) writeSwap() {
+	if !i.editText.Modified() || i.largeFile {
+		return
+	}
+	paths, err := swapPaths(i.currentFile)
+	if err == nil && !slices.Contains(paths, i.swapFile) {
+		i.removeSwap() // The buffer was saved under a new name
+		err = os.MkdirAll(filepath.Dir(paths[0]), configDirPerms)
+		if err == nil {
+			err = errors.New("all swap files in use")
+			for _, path := range paths {
+				if _, serr := os.Lstat(path); os.IsNotExist(serr) && !i.heldByPane(path) {
+					i.swapFile, err = path, nil
+					break
+				}
+			}
+		}
+	}
+	if err == nil {
+		err = writePrivateFile(i.swapFile, encodeSwap(currentSwapOwner(), i.editText.Text()))
+	}
+	if err != nil {
+		if !i.swapFailed {
+			i.swapFailed = true
+			i.showStatusHint("Swap file not written: " + err.Error())
+		}
+		return
+	}
+	i.swapFailed = false
+}
+
+// removeSwap deletes the swap file of the buffer, once its content has
+// been saved or deliberately discarded.
+func (i *Ite) removeSwap() {
+	if i.swapFile != "" {
+		os.Remove(i.swapFile)
+		i.swapFile = ""
+	}
+}
+
+// offerRecovery looks for the swap files left behind by a crash for the
+// current buffer and offers to restore their content, one at a time until
+// one is recovered. A swap file declined is deleted. Those still written
+// by a running editor are left alone. A file changed after its swap file,
+// such as by another program since the crash, is mentioned in the
+// question.
+func (i *Ite) offerRecovery() {
+	paths, err := swapPaths(i.currentFile)
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		swap, err := os.Stat(path)
+		if err != nil || path == i.swapFile {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			i.showError("Error reading swap file: " + err.Error())
+			continue
+		}
+		owner, text := decodeSwap(data)
+		if i.swapOwned(path, owner, swap.ModTime()) {
+			continue
+		}
+		msg := "Unsaved changes from a previous session were found. Recover them?"
+		if i.currentFile != "" {
+			if file, err := os.Stat(i.currentFile); err == nil && file.ModTime().After(swap.ModTime()) {
+				msg = "Unsaved changes from a previous session were found, but the file was " +
+					"modified after them. Recover them, replacing the file's content in the editor?"
+			}
+		}
+
+		resp := messageBox(Icon("question"), Title("Recover Unsaved Changes"), Msg(msg),
+			Detail("Swap file: "+path+" ("+swap.ModTime().Format(time.DateTime)+")"),
+			Type("yesno"))
+		if resp != "yes" {
+			os.Remove(path)
+			continue
+		}
+		i.removeSwap()
+		i.editText.Delete("1.0", "end")
+		i.editText.Insert("1.0", text)
+		i.protectHeader()
+		i.editText.MarkSet("insert", "1.0")
+		i.editText.SetModified(true)
+		i.swapFile = path
+		i.writeSwap() // Owned by this editor from now on
+		i.refreshCursorState()
+		return
+	}
+}
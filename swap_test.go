@@ -0,0 +1,35 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSwapFile(t *testing.T) {
+	owner := swapOwner{pid: 4242, host: "workstation"}
+	data := encodeSwap(owner, "line 1\nline 2\n")
+	if got, text := decodeSwap(data); got != owner || text != "line 1\nline 2\n" {
+		t.Errorf("swap file read back as %v, %q", got, text)
+	}
+	// A swap file of earlier versions is all buffer, without owner
+	if got, text := decodeSwap([]byte("package main\n")); got != (swapOwner{}) || text != "package main\n" {
+		t.Errorf("legacy swap file read as %v, %q", got, text)
+	}
+
+	paths, err := swapPaths(filepath.Join("src", "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != maxSwapSlots || paths[0] != filepath.Join("src", ".main.go.ite-swap") ||
+		paths[1] != filepath.Join("src", ".main.go~1.ite-swap") {
+		t.Errorf("swap paths are %q", paths)
+	}
+
+	if !processAlive(os.Getpid()) {
+		t.Error("this process reported as gone")
+	}
+}
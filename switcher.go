@@ -0,0 +1,178 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// File Switcher
+// -------------------------------------------------------------------------
+
+// Switch File lists the files opened in the session, the latest first,
+// with the previous one selected, so Ctrl+E then Return goes back and
+// forth between two files. Typing filters them like Quick Open, Ctrl+E
+// again moves down the list, and a file shown in the other pane is
+// switched to rather than opened again.
+const maxRecentFiles = 100
+
+// switcherPanel holds the widgets of the Switch File window.
+type switcherPanel struct {
+	window *ToplevelWidget
+	entry  *TEntryWidget
+	list   *ListboxWidget
+	shown  []string // Paths listed
+}
+
+// visitFile moves path to the front of the files opened.
+func (i *Ite) visitFile(path string) {
+	if path == "" {
+		return
+	}
+	i.recentFiles = slices.DeleteFunc(i.recentFiles, func(p string) bool { return p == path })
+	i.recentFiles = slices.Insert(i.recentFiles, 0, path)
+	i.recentFiles = i.recentFiles[:min(len(i.recentFiles), maxRecentFiles)]
+}
+
+// switcherMatches returns the files of recent matching query, matched
+// relative to root, best first and the latest first among equal matches.
+// An empty query keeps the order of recent.
+func switcherMatches(recent []string, root, query string) []string {
+	var matches []fuzzyMatch
+	for _, path := range recent {
+		if score, ok := fuzzyScore(query, filepath.ToSlash(relativeTo(root, path))); ok {
+			matches = append(matches, fuzzyMatch{path, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b fuzzyMatch) int { return cmp.Compare(b.score, a.score) })
+	paths := make([]string, len(matches))
+	for n, m := range matches {
+		paths[n] = m.path
+	}
+	return paths
+}
+
+// onSwitchFile opens the Switch File window, or selects the next file if
+// it is open.
+func (i *Ite) onSwitchFile() {
+	if i.switcher != nil {
+		i.moveSwitcher(1)
+		return
+	}
+	p := &switcherPanel{window: Toplevel()}
+	i.switcher = p
+	p.window.WmTitle("Switch File")
+	WmTransient(p.window, App)
+	frame := p.window.TFrame()
+	Grid(frame, Row(0), Column(0), Padx(px(10)), Pady(px(10)))
+	p.entry = frame.TEntry(Width(60), Textvariable(""))
+	Grid(p.entry, Row(0), Column(0), Sticky(WE), Pady(px(5)))
+	p.list = frame.Listbox(Width(60), Height(15), Background(theme.Text))
+	Grid(p.list, Row(1), Column(0), Sticky(NEWS))
+	Focus(p.entry)
+	i.filterSwitcher()
+
+	Bind(p.entry, "<KeyRelease>", Command(func(e *Event) {
+		switch e.Keysym {
+		case "Up", "Down", "Return", "Escape", "Control_L", "Control_R":
+		default:
+			if e.State&ModifierControl == 0 {
+				i.filterSwitcher()
+			}
+		}
+	}))
+	Bind(p.entry, "<Up>", Command(func() { i.moveSwitcher(-1) }))
+	Bind(p.entry, "<Down>", Command(func() { i.moveSwitcher(1) }))
+	Bind(p.entry, "<Control-e>", Command(func(e *Event) {
+		i.moveSwitcher(1)
+		e.SetReturnCodeBreak()
+	}))
+	Bind(p.entry, "<Return>", Command(i.acceptSwitcher))
+	Bind(p.list, "<Double-Button-1>", Command(i.acceptSwitcher))
+	Bind(p.window, "<Escape>", Command(i.closeSwitcher))
+	WmProtocol(p.window.Window, "WM_DELETE_WINDOW", i.closeSwitcher)
+}
+
+// filterSwitcher lists the files matching the query of the Switch File
+// window, selecting the previous file while there is no query and the
+// best match otherwise.
+func (i *Ite) filterSwitcher() {
+	p := i.switcher
+	query := strings.Join(strings.Fields(p.entry.Textvariable()), "")
+	root := i.projectDir()
+	p.shown = switcherMatches(i.recentFiles, root, query)
+	p.list.Delete(0, "end")
+	for _, path := range p.shown {
+		label := filepath.ToSlash(relativeTo(root, path))
+		if other := i.otherPane(); other != nil && other.file == path {
+			label += "  " + tr("(other pane)")
+		}
+		p.list.Insert("end", label)
+	}
+	if len(p.shown) == 0 {
+		return
+	}
+	sel := 0
+	if query == "" && len(p.shown) > 1 && p.shown[0] == i.currentFile {
+		sel = 1
+	}
+	p.list.SelectionSet(sel)
+	p.list.See(sel)
+}
+
+// moveSwitcher selects the file delta rows away from the selected one,
+// wrapping around at the ends of the list.
+func (i *Ite) moveSwitcher(delta int) {
+	p := i.switcher
+	sel := p.list.Curselection()
+	if len(sel) == 0 || len(p.shown) == 0 {
+		return
+	}
+	n := (sel[0] + delta + len(p.shown)) % len(p.shown)
+	p.list.SelectionClear(0, "end")
+	p.list.SelectionSet(n)
+	p.list.See(n)
+}
+
+// closeSwitcher closes the Switch File window.
+func (i *Ite) closeSwitcher() {
+	if i.switcher == nil {
+		return
+	}
+	Destroy(i.switcher.window)
+	i.switcher = nil
+	Focus(i.editText)
+}
+
+// acceptSwitcher shows the selected file: in the other pane if that pane
+// shows it, else opened in the active one.
+func (i *Ite) acceptSwitcher() {
+	p := i.switcher
+	sel := p.list.Curselection()
+	if len(sel) == 0 || sel[0] >= len(p.shown) {
+		return
+	}
+	path := p.shown[sel[0]]
+	i.closeSwitcher()
+	if path == i.currentFile {
+		return
+	}
+	if other := i.otherPane(); other != nil && other.file == path {
+		i.activatePane(other)
+		Focus(other.text)
+		return
+	}
+	if !i.promptSaveIfModified() {
+		return
+	}
+	if err := i.openFile(path); err != nil {
+		i.showError("Error opening file: " + err.Error())
+	}
+}
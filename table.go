@@ -0,0 +1,127 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Table Windows
+// -------------------------------------------------------------------------
+
+// tableColumn is a column of a table window.
+type tableColumn struct {
+	id, heading string
+	numeric     bool // Right aligned and sorted by value, largest first
+	width       int  // In pixels before scaling
+}
+
+// tableCell is a cell of a table window: the text shown and, in numeric
+// columns, the value it is sorted by.
+type tableCell struct {
+	text  string
+	value float64
+}
+
+// tableWindow is a window listing rows in columns, sorted by the column
+// whose heading was clicked last; a second click reverses the order.
+type tableWindow struct {
+	window  *ToplevelWidget
+	tree    *TTreeviewWidget
+	status  *TLabelWidget
+	columns []tableColumn
+	rows    [][]tableCell
+	sortBy  int
+	reverse bool
+}
+
+// newTableWindow creates a table window with columns, sorted by column
+// sortBy, or in the order of the rows while it is -1. Closing it calls
+// closed.
+func (i *Ite) newTableWindow(columns []tableColumn, sortBy int, closed func()) *tableWindow {
+	t := &tableWindow{window: Toplevel(), columns: columns, sortBy: sortBy}
+	var ids []string
+	for _, c := range columns {
+		ids = append(ids, c.id)
+	}
+	t.tree = t.window.TTreeview(Columns(strings.Join(ids, " ")), Show("headings"), Height(25), Selectmode("browse"))
+	for n, c := range columns {
+		anchor := "w"
+		if c.numeric {
+			anchor = "e"
+		}
+		t.tree.Heading(c.id, Txt(c.heading), Anchor(anchor), Command(func() { t.sort(n) }))
+		t.tree.Column(c.id, Anchor(anchor), Width(px(c.width)))
+	}
+	scrollbar := t.window.TScrollbar(Command(func(e *Event) { e.Yview(t.tree) }))
+	t.tree.Configure(Yscrollcommand(func(e *Event) { e.ScrollSet(scrollbar) }))
+	t.status = t.window.TLabel(Foreground(theme.Muted))
+	Grid(t.tree, Row(0), Column(0), Sticky(NEWS))
+	Grid(scrollbar, Row(0), Column(1), Sticky(NS))
+	Grid(t.status, Row(1), Column(0), Columnspan(2), Sticky(W), Padx(px(5)), Pady(px(3)))
+	GridRowConfigure(t.window, 0, Weight(1))
+	GridColumnConfigure(t.window, 0, Weight(1))
+	closeWindow := func() {
+		Destroy(t.window)
+		closed()
+		Focus(i.editText)
+	}
+	Bind(t.window, "<Escape>", Command(closeWindow))
+	WmProtocol(t.window.Window, "WM_DELETE_WINDOW", closeWindow)
+	return t
+}
+
+// show raises the window and lists rows, noting status below them.
+func (t *tableWindow) show(title string, rows [][]tableCell, status string) {
+	WmDeiconify(t.window.Window)
+	tclEval("raise %s", t.window)
+	t.window.WmTitle(title)
+	t.rows = rows
+	t.status.Configure(Txt(status + "; click a heading to sort"))
+	t.fill()
+}
+
+// sort sorts the rows by column n, reversing the order when they are
+// sorted by n already.
+func (t *tableWindow) sort(n int) {
+	if t.sortBy == n {
+		t.reverse = !t.reverse
+	} else {
+		t.sortBy, t.reverse = n, false
+	}
+	t.fill()
+}
+
+// fill lists the rows in the order of the sort column: text ascending,
+// numbers descending.
+func (t *tableWindow) fill() {
+	if n := t.sortBy; n >= 0 {
+		numeric := t.columns[n].numeric
+		slices.SortStableFunc(t.rows, func(a, b []tableCell) int {
+			c := strings.Compare(a[n].text, b[n].text)
+			if numeric {
+				c = cmp.Compare(b[n].value, a[n].value)
+			}
+			if t.reverse {
+				return -c
+			}
+			return c
+		})
+	}
+	for _, item := range t.tree.Children("") {
+		t.tree.Delete(item)
+	}
+	for _, row := range t.rows {
+		values := make([]string, len(row))
+		for n, cell := range row {
+			values[n] = cell.text
+		}
+		t.tree.Insert("", "end", Values(values))
+	}
+}
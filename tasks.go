@@ -0,0 +1,285 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Tasks
+// -------------------------------------------------------------------------
+
+// Tasks are shell commands of a project, such as go generate or make
+// proto, defined in its settings:
+//
+//	[tasks.generate]
+//	command = "go generate ./..."
+//	dir = "internal/api"             # Relative to the project root, the root by default
+//
+// They run in the Tasks console, from the Tasks menu of the toolbar or the
+// command palette, F9 running the last one again. The last runs of each
+// task are kept with their output for Task History.
+
+const (
+	maxTaskRuns   = 10       // Runs kept per task
+	maxTaskOutput = 64 << 10 // Bytes of output kept per run
+)
+
+// taskSettings is a task of the project settings.
+type taskSettings struct {
+	Command string // Shell command line
+	Dir     string // Working directory, relative to the project root
+}
+
+// taskRun is a finished run of a task.
+type taskRun struct {
+	task   string
+	start  time.Time
+	status string // Final console line, such as "Task successful (1.2s)"
+	output string // Output, cut to maxTaskOutput
+}
+
+// taskState holds the task runs of the session.
+type taskState struct {
+	last    string                // Task run last, "" if none
+	history map[string][]*taskRun // Last runs, by task, oldest first
+	menu    *MenuWidget           // Menu of the toolbar button
+	window  *ToplevelWidget       // Task History window, nil if closed
+}
+
+// taskDecoder shows the output of a task and keeps it for the history.
+type taskDecoder struct {
+	output strings.Builder
+}
+
+func (d *taskDecoder) decode(line string) []consoleMsg {
+	if d.output.Len() < maxTaskOutput {
+		d.output.WriteString(line[:min(len(line), maxTaskOutput-d.output.Len())])
+	}
+	return []consoleMsg{{text: line}}
+}
+
+func (d *taskDecoder) summary() []consoleMsg { return nil }
+
+// setTask stores the value of field of the task name, from key
+// "tasks.name.field" of the settings.
+func (s *projectSettings) setTask(key string, v any) error {
+	name, field, ok := cutLast(key, ".")
+	if !ok || name == "" {
+		return errors.New("unknown setting")
+	}
+	value, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("unexpected value %v", v)
+	}
+	if s.Tasks == nil {
+		s.Tasks = make(map[string]taskSettings)
+	}
+	t := s.Tasks[name]
+	switch field {
+	case "command":
+		t.Command = value
+	case "dir":
+		t.Dir = value
+	default:
+		return errors.New("unknown setting")
+	}
+	s.Tasks[name] = t
+	return nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if n := strings.LastIndex(s, sep); n >= 0 {
+		return s[:n], s[n+len(sep):], true
+	}
+	return s, "", false
+}
+
+// taskNames returns the names of the tasks of s, sorted.
+func (s projectSettings) taskNames() []string {
+	names := make([]string, 0, len(s.Tasks))
+	for name := range s.Tasks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// shellArgs returns the program and arguments running the command line
+// command with the shell of the system.
+func shellArgs(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"sh", "-c", command}
+}
+
+// makeTaskMenu creates the toolbar menu button of the tasks, whose menu is
+// filled with the tasks of the current project when it opens.
+func (i *Ite) makeTaskMenu(parent *TFrameWidget) *TMenubuttonWidget {
+	button := parent.TMenubutton(Txt("Tasks"))
+	i.tasks.menu = button.Menu(Postcommand(i.fillTaskMenu))
+	button.Configure(Mnu(i.tasks.menu))
+	return button
+}
+
+// fillTaskMenu lists the tasks of the current project in the tasks menu.
+func (i *Ite) fillTaskMenu() {
+	menu := i.tasks.menu
+	tclEval("%s delete 0 end", menu)
+	s, err := i.loadProjectSettings()
+	if err != nil {
+		menu.AddCommand(Lbl("Error in "+projectFileName), Command(i.onEditProjectSettings))
+	}
+	names := s.taskNames()
+	for _, name := range names {
+		menu.AddCommand(Lbl(name), Command(func() { i.runTask(name) }))
+	}
+	if len(names) == 0 && err == nil {
+		menu.AddCommand(Lbl("No tasks"), State("disabled"))
+	}
+	menu.AddSeparator()
+	menu.AddCommand(Lbl("Run Last Task"), Command(i.onRunLastTask))
+	menu.AddCommand(Lbl("Task History..."), Command(i.onTaskHistory))
+	menu.AddCommand(Lbl("Edit Tasks..."), Command(i.onEditProjectSettings))
+}
+
+// onRunTask pops up the tasks menu at the mouse pointer.
+func (i *Ite) onRunTask() {
+	i.fillTaskMenu()
+	xy := strings.Fields(tclEval("winfo pointerxy %s", App))
+	if len(xy) < 2 {
+		return
+	}
+	Popup(i.tasks.menu.Window, winfoInt(xy[0]), winfoInt(xy[1]), nil)
+}
+
+// onRunLastTask runs again the task run last.
+func (i *Ite) onRunLastTask() {
+	if i.tasks.last == "" {
+		i.showStatusHint("No task has run yet")
+		return
+	}
+	i.runTask(i.tasks.last)
+}
+
+// runTask runs the task name of the current project in the Tasks console,
+// saving the buffer first, and records the run in the history once done.
+func (i *Ite) runTask(name string) {
+	s, err := i.loadProjectSettings()
+	if err != nil {
+		i.showError("Error reading project settings: " + err.Error())
+		return
+	}
+	t, ok := s.Tasks[name]
+	switch {
+	case !ok:
+		i.showError(fmt.Sprintf("The project has no task %q.", name))
+		return
+	case strings.TrimSpace(t.Command) == "":
+		i.showError(fmt.Sprintf("Task %q has no command.", name))
+		return
+	}
+	if i.currentFile != "" && i.editText.Modified() {
+		i.onSave()
+	}
+	i.tasks.last = name
+
+	root := i.projectDir()
+	d := &taskDecoder{}
+	j := &job{c: i.console(consoleTasks), decoder: d, dir: root}
+	if t.Dir != "" {
+		j.dir = filepath.Join(root, filepath.FromSlash(t.Dir))
+	}
+	j.steps = []jobStep{{name: "task " + name, args: shellArgs(t.Command)}}
+	start := time.Now()
+	j.done = func(res procResult, stopped bool) {
+		i.addTaskRun(&taskRun{
+			task:   name,
+			start:  start,
+			status: strings.TrimSpace(commandStatus("task "+name, res, stopped)),
+			output: d.output.String(),
+		})
+	}
+	i.runJob(j, fmt.Sprintf("Running task %s: %s\n", name, t.Command))
+}
+
+// addTaskRun adds r to the history of its task, dropping the oldest runs
+// past maxTaskRuns.
+func (i *Ite) addTaskRun(r *taskRun) {
+	if i.tasks.history == nil {
+		i.tasks.history = make(map[string][]*taskRun)
+	}
+	runs := append(i.tasks.history[r.task], r)
+	if len(runs) > maxTaskRuns {
+		runs = slices.Clone(runs[len(runs)-maxTaskRuns:])
+	}
+	i.tasks.history[r.task] = runs
+	if i.tasks.window != nil {
+		i.onTaskHistory() // Show the new run
+	}
+}
+
+// onTaskHistory opens the window listing the task runs of the session,
+// latest first, with the output of the selected one.
+func (i *Ite) onTaskHistory() {
+	var runs []*taskRun
+	for _, r := range i.tasks.history {
+		runs = append(runs, r...)
+	}
+	if len(runs) == 0 && i.tasks.window == nil {
+		i.showStatusHint("No task has run yet")
+		return
+	}
+	slices.SortFunc(runs, func(a, b *taskRun) int { return b.start.Compare(a.start) })
+
+	if i.tasks.window != nil {
+		Destroy(i.tasks.window)
+	}
+	w := Toplevel()
+	i.tasks.window = w
+	w.WmTitle("Task History")
+	list := w.Listbox(Width(70), Height(8), Background(theme.Text))
+	Grid(list, Row(0), Column(0), Sticky(NEWS), Padx(px(10)), Pady(px(5)))
+	output := w.Text(textStyle(), Width(100), Height(20), Wrap("none"), State("disabled"))
+	Grid(output, Row(1), Column(0), Sticky(NEWS), Padx(px(10)))
+	GridRowConfigure(w, 1, Weight(1))
+	GridColumnConfigure(w, 0, Weight(1))
+	for _, r := range runs {
+		list.Insert("end", fmt.Sprintf("%s  %s  %s", r.start.Format(time.DateTime), r.task, r.status))
+	}
+	show := func() {
+		sel := list.Curselection()
+		if len(sel) == 0 || sel[0] >= len(runs) {
+			return
+		}
+		output.Configure(State("normal"))
+		output.Delete("1.0", "end")
+		output.Insert("end", runs[sel[0]].output)
+		output.Configure(State("disabled"))
+	}
+	if len(runs) > 0 {
+		list.SelectionSet(0)
+		show()
+	}
+	Bind(list, "<<ListboxSelect>>", Command(show))
+
+	closeWindow := func() {
+		Destroy(w)
+		i.tasks.window = nil
+	}
+	Grid(w.TButton(Txt("Close"), Command(closeWindow)), Row(2), Column(0), Pady(px(10)))
+	WmProtocol(w.Window, "WM_DELETE_WINDOW", closeWindow)
+	Bind(w, "<Escape>", Command(closeWindow))
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskSettings(t *testing.T) {
+	values, err := parseTOML(`[tasks.generate]
+command = "go generate ./..."
+dir = "internal/api"
+
+[tasks."proto.v2"]
+command = "make proto"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s projectSettings
+	for key, v := range values {
+		if err := s.set(key, v); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+	}
+	if got, want := s.taskNames(), []string{"generate", "proto.v2"}; !slices.Equal(got, want) {
+		t.Fatalf("tasks = %q, want %q", got, want)
+	}
+	if got := s.Tasks["generate"]; got.Command != "go generate ./..." || got.Dir != "internal/api" {
+		t.Errorf("generate = %+v", got)
+	}
+	if got := s.Tasks["proto.v2"]; got.Command != "make proto" || got.Dir != "" {
+		t.Errorf("proto.v2 = %+v", got)
+	}
+	if err := s.set("tasks.generate.shell", "bash"); err == nil {
+		t.Error("unknown task setting accepted")
+	}
+	if err := s.set("tasks.generate.command", []string{"go"}); err == nil {
+		t.Error("list command accepted")
+	}
+}
+
+func TestTaskDecoder(t *testing.T) {
+	var d taskDecoder
+	line := strings.Repeat("x", 1000) + "\n"
+	for range maxTaskOutput/len(line) + 2 {
+		if msgs := d.decode(line); len(msgs) != 1 || msgs[0].text != line {
+			t.Fatalf("decode = %+v", msgs)
+		}
+	}
+	if d.output.Len() != maxTaskOutput {
+		t.Errorf("kept %d bytes, want %d", d.output.Len(), maxTaskOutput)
+	}
+}
+
+func TestAddTaskRun(t *testing.T) {
+	i := newBareIte()
+	start := time.Now()
+	for n := range maxTaskRuns + 3 {
+		i.addTaskRun(&taskRun{task: "generate", start: start.Add(time.Duration(n) * time.Second)})
+	}
+	runs := i.tasks.history["generate"]
+	if len(runs) != maxTaskRuns {
+		t.Fatalf("kept %d runs, want %d", len(runs), maxTaskRuns)
+	}
+	if !runs[0].start.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("oldest run kept started at %v, want the fourth", runs[0].start)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/trace"
+
+	"modernc.org/tk9.0/extensions/eval"
+)
+
+// tclEval evaluates a raw Tcl command. It is reserved for the few Tk
+// features the tk9.0 bindings do not wrap; errors are logged to stderr.
+func tclEval(format string, args ...any) string {
+	script := fmt.Sprintf(format, args...)
+	if trace.IsEnabled() {
+		ctx := context.Background()
+		defer trace.StartRegion(ctx, "tcl").End()
+		trace.Log(ctx, "tcl", script)
+	}
+	r, err := eval.Eval(script)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ite: tcl %q: %v\n", script, err)
+	}
+	return r
+}
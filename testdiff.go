@@ -0,0 +1,191 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Test Failure Diffs
+// -------------------------------------------------------------------------
+
+// Console tags of rendered test diffs.
+const (
+	tagDiffDel     = "diffdel"    // Lines only in the first side of a diff
+	tagDiffAdd     = "diffadd"    // Lines only in the second side of a diff
+	tagSideBySide  = "sidebyside" // Link opening a diff side by side
+	sideBySideLink = "[side by side]"
+)
+
+var (
+	// diffHeaderRe matches the "(-want +got)" legend printed with cmp.Diff
+	// output, capturing the names of the two sides.
+	diffHeaderRe = regexp.MustCompile(`\(-(\w+) \+(\w+)\)`)
+
+	// gotWantRe and wantGotRe match one-line assertion messages such as
+	// "Add(1, 2) = 4, want 3" or "got: 4; want: 3".
+	gotWantRe = regexp.MustCompile(`^(.*?)\b(?:got:?|=)\s*(.+?)[,;]\s+want:?\s+(.+)$`)
+	wantGotRe = regexp.MustCompile(`^(.*?)\bwant:?\s+(.+?)[,;]\s+got:?\s+(.+)$`)
+)
+
+// testDiff is a diff found in the output of a failing test, kept to be
+// shown side by side on request.
+type testDiff struct {
+	test                      string
+	names                     [2]string // Side names, e.g. "want" and "got"
+	left, right               []string  // Lines of each side, context included
+	leftChanged, rightChanged []bool    // Whether each line differs
+}
+
+// renderFailure turns the output of a failing test into console messages,
+// coloring diff lines and splitting one-line got/want messages.
+func renderFailure(test string, output []string) []consoleMsg {
+	var msgs []consoleMsg
+	var diff *testDiff
+	endDiff := func() {
+		if d := diff; d != nil && len(d.left)+len(d.right) > 0 {
+			msgs = append(msgs, consoleMsg{
+				text:  "        " + sideBySideLink + "\n",
+				tag:   tagSideBySide,
+				click: func() { showSideBySide(d) },
+			})
+		}
+		diff = nil
+	}
+
+	for _, out := range output {
+		if isTestFrame(out) {
+			continue // Already reported by the FAIL line
+		}
+		line := strings.TrimRight(out, "\n")
+		if m := diffHeaderRe.FindStringSubmatch(line); m != nil {
+			endDiff()
+			diff = &testDiff{test: test, names: [2]string{m[1], m[2]}}
+			msgs = append(msgs, consoleMsg{text: out})
+			continue
+		}
+		if diff != nil {
+			if body, ok := diffBody(line); ok {
+				msgs = append(msgs, diff.add(body, out))
+				continue
+			}
+			endDiff()
+		}
+		if split := splitGotWant(line); split != nil {
+			msgs = append(msgs, split...)
+			continue
+		}
+		msgs = append(msgs, consoleMsg{text: out})
+	}
+	endDiff()
+	return msgs
+}
+
+// diffBody returns a line of a cmp.Diff block without its indentation.
+// ok is false once the block has ended: the testing package indents every
+// line of a multi-line message.
+func diffBody(line string) (body string, ok bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == "" || !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+		return "", false
+	}
+	return strings.TrimLeft(line, " \t"), true
+}
+
+// add records a line of the diff block and returns its console message.
+func (d *testDiff) add(body, out string) consoleMsg {
+	content := strings.TrimLeft(body, " ")
+	if strings.HasPrefix(body, "-") || strings.HasPrefix(body, "+") {
+		content = strings.TrimLeft(body[1:], " ")
+	}
+	switch {
+	case strings.HasPrefix(body, "-"):
+		d.left = append(d.left, content)
+		d.leftChanged = append(d.leftChanged, true)
+		return consoleMsg{text: out, tag: tagDiffDel}
+	case strings.HasPrefix(body, "+"):
+		d.right = append(d.right, content)
+		d.rightChanged = append(d.rightChanged, true)
+		return consoleMsg{text: out, tag: tagDiffAdd}
+	}
+	// Context lines start with a space and are on both sides
+	d.left = append(d.left, content)
+	d.leftChanged = append(d.leftChanged, false)
+	d.right = append(d.right, content)
+	d.rightChanged = append(d.rightChanged, false)
+	return consoleMsg{text: out}
+}
+
+// splitGotWant renders a one-line "got X, want Y" message as a "- want" and
+// a "+ got" line below the rest of the message, or returns nil if line is
+// not such a message.
+func splitGotWant(line string) []consoleMsg {
+	var prefix, got, want string
+	if m := gotWantRe.FindStringSubmatch(line); m != nil {
+		prefix, got, want = m[1], m[2], m[3]
+	} else if m := wantGotRe.FindStringSubmatch(line); m != nil {
+		prefix, want, got = m[1], m[2], m[3]
+	} else {
+		return nil
+	}
+	indent := leadingSpace(line) + "    "
+	return []consoleMsg{
+		{text: strings.TrimRight(prefix, " ") + "\n"},
+		{text: indent + "- want: " + want + "\n", tag: tagDiffDel},
+		{text: indent + "+ got:  " + got + "\n", tag: tagDiffAdd},
+	}
+}
+
+// configureDiffTags sets up the console tags used by rendered diffs.
+func (i *Ite) configureDiffTags(c *console) {
+	c.text.TagConfigure(tagDiffDel, Foreground(theme.Error))
+	c.text.TagConfigure(tagDiffAdd, Foreground(theme.Success))
+	c.text.TagConfigure(tagSideBySide, Foreground(theme.Link), Underline(1))
+	c.text.TagBind(tagSideBySide, "<Button-1>", func() { i.onConsoleClick(c) })
+	c.text.TagBind(tagSideBySide, "<Enter>", func() {
+		c.text.Configure(Cursor("hand2"))
+	})
+	c.text.TagBind(tagSideBySide, "<Leave>", func() {
+		c.text.Configure(Cursor("xterm"))
+	})
+}
+
+// showSideBySide opens a window with the two sides of diff next to each
+// other, differing lines highlighted.
+func showSideBySide(diff *testDiff) {
+	dialog := Toplevel()
+	dialog.WmTitle(diff.test + " - Diff")
+
+	sides := []struct {
+		name    string
+		lines   []string
+		changed []bool
+		tag     string
+		color   string
+	}{
+		{diff.names[0], diff.left, diff.leftChanged, tagDiffDel, theme.DiffOld},
+		{diff.names[1], diff.right, diff.rightChanged, tagDiffAdd, theme.DiffNew},
+	}
+	for col, side := range sides {
+		Grid(dialog.TLabel(Txt(side.name)), Row(0), Column(col), Sticky(W), Padx(px(5)))
+		text := dialog.Text(textStyle(), Width(60), Height(25))
+		text.TagConfigure(side.tag, Background(side.color))
+		for n, line := range side.lines {
+			if side.changed[n] {
+				text.Insert("end", line+"\n", side.tag)
+			} else {
+				text.Insert("end", line+"\n")
+			}
+		}
+		text.Configure(State("disabled"))
+		Grid(text, Row(1), Column(col), Sticky(NEWS), Padx(px(5)), Pady(px(5)))
+		GridColumnConfigure(dialog, col, Weight(1))
+	}
+	GridRowConfigure(dialog, 1, Weight(1))
+	Bind(dialog, "<Escape>", Command(func() { Destroy(dialog) }))
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Go Test Runner
+// -------------------------------------------------------------------------
+
+// Console tags coloring test results.
+const (
+	tagTestPass   = "testpass"
+	tagTestFail   = "testfail"
+	tagTestSkip   = "testskip"
+	tagTestCached = "testcached"
+)
+
+const statusTesting = "Testing...\n"
+
+// testEvent is a single record of the `go test -json` stream
+// (see `go doc test2json`).
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testDecoder turns the `go test -json` stream into a readable report:
+// one colored line per test, the output of failing tests only, and a
+// summary once the run is over.
+type testDecoder struct {
+	output                  map[string][]string // Pending output per package and test
+	passed, failed, skipped int
+	packages, cached        int                     // Packages that passed, and those of them whose result came from the cache
+	coverage                map[string]float64      // Coverage of the packages that passed, nil unless measured
+	historyPath             string                  // Coverage history file of the project
+	profile                 func(pkg, bench string) // Offers to profile a benchmark, nil for no profile links
+	done                    func()                  // Called, on the command goroutine, once the run ended, if set
+	benchPath               string                  // Benchmark results file of the project, "" for no comparison
+	benches                 []benchResult           // Benchmark results of the run, when compared
+	showBenches             func(rows [][]tableCell, status string)
+}
+
+func newTestDecoder() *testDecoder {
+	return &testDecoder{output: make(map[string][]string)}
+}
+
+// onGoTest runs `go test ./...` for the module of the current file,
+// with -count=1 when the cached results are to be ignored. Test log lines
+// carry full paths, so they link back to the source.
+func (i *Ite) onGoTest() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	args := append([]string{"test", "-json", "-fullpath"}, i.tagsArgs()...)
+	if i.config.UncachedTests {
+		args = append(args, "-count=1")
+	}
+	i.runCommand(i.console(consoleTest), append(args, "./..."), statusTesting, newTestDecoder())
+}
+
+// onToggleUncachedTests switches Go Test between reusing the cached test
+// results and running every test again, and persists the choice.
+func (i *Ite) onToggleUncachedTests() {
+	i.config.UncachedTests = !i.config.UncachedTests
+	i.uncachedVar.Set(checkValue(i.config.UncachedTests))
+	i.saveConfig()
+	if i.config.UncachedTests {
+		i.showStatusHint("Tests run uncached (-count=1)")
+	} else {
+		i.showStatusHint("Tests reuse cached results")
+	}
+}
+
+func (d *testDecoder) decode(line string) []consoleMsg {
+	var ev testEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil || ev.Action == "" {
+		return []consoleMsg{{text: line}} // Not part of the JSON stream
+	}
+
+	key := ev.Package + "\x00" + ev.Test
+	switch ev.Action {
+	case "output":
+		if strings.HasPrefix(ev.Test, "Benchmark") {
+			if out, ok := d.benchmarkLine(key, ev.Output); ok {
+				ev.Output = out
+				return d.benchmarkResult(ev)
+			}
+		}
+		d.output[key] = append(d.output[key], ev.Output)
+	case "build-output":
+		return []consoleMsg{{text: ev.Output}}
+	case "pass", "fail", "skip":
+		output := d.output[key]
+		delete(d.output, key)
+		if ev.Test == "" {
+			return d.packageResult(ev, output)
+		}
+		return d.testResult(ev, output)
+	}
+	return nil
+}
+
+// testResult reports the outcome of a single test. Only failing tests show
+// their output.
+func (d *testDecoder) testResult(ev testEvent, output []string) []consoleMsg {
+	indent := strings.Repeat("    ", strings.Count(ev.Test, "/"))
+	var msgs []consoleMsg
+	switch ev.Action {
+	case "pass":
+		d.passed++
+		msgs = append(msgs, consoleMsg{
+			text: fmt.Sprintf("%s--- PASS: %s (%.2fs)\n", indent, ev.Test, ev.Elapsed),
+			tag:  tagTestPass,
+		})
+	case "skip":
+		d.skipped++
+		msgs = append(msgs, consoleMsg{
+			text: fmt.Sprintf("%s--- SKIP: %s\n", indent, ev.Test),
+			tag:  tagTestSkip,
+		})
+	case "fail":
+		d.failed++
+		msgs = append(msgs, consoleMsg{
+			text: fmt.Sprintf("%s--- FAIL: %s (%.2fs)\n", indent, ev.Test, ev.Elapsed),
+			tag:  tagTestFail,
+		})
+		msgs = append(msgs, renderFailure(ev.Test, output)...)
+	}
+	return msgs
+}
+
+// packageResult reports the outcome of a package. Package level output,
+// such as a panic outside any test, is shown when the package fails.
+// Results replayed from the test cache are marked as such.
+func (d *testDecoder) packageResult(ev testEvent, output []string) []consoleMsg {
+	switch ev.Action {
+	case "pass":
+		d.packages++
+		if percent, ok := packageCoverage(output); ok && d.coverage != nil {
+			d.coverage[ev.Package] = percent
+		}
+		if isCachedResult(output) {
+			d.cached++
+			return []consoleMsg{{text: fmt.Sprintf("ok   %s (cached)\n", ev.Package), tag: tagTestCached}}
+		}
+		return []consoleMsg{{text: fmt.Sprintf("ok   %s (%.2fs)\n", ev.Package, ev.Elapsed), tag: tagTestPass}}
+	case "skip":
+		return []consoleMsg{{text: fmt.Sprintf("?    %s [no test files]\n", ev.Package), tag: tagTestSkip}}
+	}
+	var msgs []consoleMsg
+	for _, out := range output {
+		if isTestFrame(out) || isPackageFrame(out) {
+			continue
+		}
+		msgs = append(msgs, consoleMsg{text: out})
+	}
+	return append(msgs, consoleMsg{text: fmt.Sprintf("FAIL %s\n", ev.Package), tag: tagTestFail})
+}
+
+func (d *testDecoder) summary() []consoleMsg {
+	msg := consoleMsg{
+		text: fmt.Sprintf("\n%d passed, %d failed, %d skipped\n", d.passed, d.failed, d.skipped),
+		tag:  tagTestPass,
+	}
+	if d.failed > 0 {
+		msg.tag = tagTestFail
+	}
+	if d.done != nil {
+		d.done()
+	}
+	msgs := append(d.coverageReport(), d.benchmarkReport()...)
+	msgs = append(msgs, msg)
+	if d.cached > 0 {
+		msgs = append(msgs, consoleMsg{
+			text: fmt.Sprintf("%d of %d packages cached, run with -count=1 to test them again\n", d.cached, d.packages),
+			tag:  tagTestCached,
+		})
+	}
+	return msgs
+}
+
+// isCachedResult reports whether the closing ok line in the output of a
+// package says its result came from the test cache.
+func isCachedResult(output []string) bool {
+	for _, out := range output {
+		if isPackageFrame(out) && strings.Contains(out, "\t(cached)") {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestFrame reports whether out is one of the per-test framing lines
+// printed by the test binary, which the decoder replaces with its own.
+func isTestFrame(out string) bool {
+	s := strings.TrimSpace(out)
+	return strings.HasPrefix(s, "=== ") || strings.HasPrefix(s, "--- ")
+}
+
+// isPackageFrame reports whether out is the closing PASS, FAIL or ok line
+// of a package.
+func isPackageFrame(out string) bool {
+	s := strings.TrimSpace(out)
+	return s == "PASS" || s == "FAIL" || strings.HasPrefix(s, "FAIL\t") ||
+		strings.HasPrefix(s, "ok \t") || strings.HasPrefix(s, "ok  \t")
+}
+
+// configureTestTags sets the colors of the test result tags in console c.
+func (i *Ite) configureTestTags(c *console) {
+	c.text.TagConfigure(tagTestPass, Foreground(theme.Success))
+	c.text.TagConfigure(tagTestFail, Foreground(theme.Error))
+	c.text.TagConfigure(tagTestSkip, Foreground(theme.Muted))
+	c.text.TagConfigure(tagTestCached, Foreground(theme.Link))
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Theme Configuration
+// -------------------------------------------------------------------------
+
+// Color palette definitions the themes are built from.
+const (
+	// Light palette
+	colBlack        = "#000000"
+	colExtremeBlack = "#101010"
+	colApricotWhite = "#ffffea" // Main background for text areas
+	colCoolYellow   = "#eceb91" // Selection background
+	colWaterDew     = "#eaffff" // Frame backgrounds
+	colSaltWater    = "#d4ffff" // Active button background
+	colHighBall     = "#8d8c39" // Scrollbar trough
+	colRed          = "#ff0000" // Error/Unsaved status
+	colDarkGreen    = "#006400" // Saved status
+	colMistyRose    = "#ffe4e1" // Prose overflow highlight
+	colSilverSand   = "#bfc1c2" // Column guide
+	colDukeBlue     = "#00009c" // Console links
+	colDarkOrange   = "#c05800" // Warning log lines
+	colCornsilk     = "#f5f5d8" // Current line highlight
+
+	// Dark palette
+	colEerieBlack  = "#1d1f21" // Main background for text areas
+	colBone        = "#e0e0d0" // Text
+	colDarkOlive   = "#4a4a2a" // Selection background
+	colGunmetal    = "#2a3333" // Frame backgrounds
+	colOuterSpace  = "#3a4a4a" // Active button background
+	colDarkKhaki   = "#5a5a30" // Scrollbar trough
+	colPastelRed   = "#ff6b6b" // Error/Unsaved status
+	colPistachio   = "#7ec87e" // Saved status
+	colSandyBrown  = "#e5a050" // Warning log lines
+	colMossGray    = "#8d8c60" // Skipped tests, debug log lines
+	colLightSky    = "#8ab4f8" // Console links
+	colDarkWine    = "#4a2a2a" // Prose overflow highlight
+	colDimGray     = "#555555" // Column guide
+	colDarkSlate   = "#25353a" // Read-only regions
+	colDarkFern    = "#22402a" // Added diff lines
+	colLightSilver = "#d0d0c0" // Button text
+	colCharcoal    = "#282b2e" // Current line highlight
+)
+
+// colorTheme assigns the palette colors to the parts of the user interface.
+type colorTheme struct {
+	Name        string
+	Text        string // Text area background
+	Foreground  string // Text and cursor
+	Selection   string // Selection background
+	Frame       string // Frame and button background
+	Active      string // Active button background
+	Trough      string // Scrollbar trough
+	ButtonText  string
+	Error       string // Unsaved status, failures, error logs
+	Success     string // Saved status, passed tests
+	Warning     string // Warning logs
+	Muted       string // Skipped tests, debug logs
+	Link        string // Console links
+	Overflow    string // Prose overflow highlight
+	Guide       string // Column guide
+	CurrentLine string // Background of the cursor line
+	Protected   string // Read-only region background
+	DiffOld     string // Background of removed lines side by side
+	DiffNew     string // Background of added lines side by side
+}
+
+var (
+	lightTheme = colorTheme{
+		Name:        "light",
+		Text:        colApricotWhite,
+		Foreground:  colBlack,
+		Selection:   colCoolYellow,
+		Frame:       colWaterDew,
+		Active:      colSaltWater,
+		Trough:      colHighBall,
+		ButtonText:  colExtremeBlack,
+		Error:       colRed,
+		Success:     colDarkGreen,
+		Warning:     colDarkOrange,
+		Muted:       colHighBall,
+		Link:        colDukeBlue,
+		Overflow:    colMistyRose,
+		Guide:       colSilverSand,
+		CurrentLine: colCornsilk,
+		Protected:   colWaterDew,
+		DiffOld:     colMistyRose,
+		DiffNew:     colWaterDew,
+	}
+
+	darkTheme = colorTheme{
+		Name:        "dark",
+		Text:        colEerieBlack,
+		Foreground:  colBone,
+		Selection:   colDarkOlive,
+		Frame:       colGunmetal,
+		Active:      colOuterSpace,
+		Trough:      colDarkKhaki,
+		ButtonText:  colLightSilver,
+		Error:       colPastelRed,
+		Success:     colPistachio,
+		Warning:     colSandyBrown,
+		Muted:       colMossGray,
+		Link:        colLightSky,
+		Overflow:    colDarkWine,
+		Guide:       colDimGray,
+		CurrentLine: colCharcoal,
+		Protected:   colDarkSlate,
+		DiffOld:     colDarkWine,
+		DiffNew:     colDarkFern,
+	}
+)
+
+// theme is the theme in use.
+var theme = &lightTheme
+
+// themeByName returns the named theme, falling back to the light one.
+func themeByName(name string) *colorTheme {
+	if name == darkTheme.Name {
+		return &darkTheme
+	}
+	return &lightTheme
+}
+
+// onToggleTheme switches between the light and the dark theme and
+// persists the choice.
+func (i *Ite) onToggleTheme() {
+	if theme == &darkTheme {
+		i.config.Theme = lightTheme.Name
+	} else {
+		i.config.Theme = darkTheme.Name
+	}
+	i.darkThemeVar.Set(checkValue(i.config.Theme == darkTheme.Name))
+	i.saveConfig()
+	i.applyTheme()
+}
+
+// applyTheme recolors the whole user interface with the configured theme.
+func (i *Ite) applyTheme() {
+	theme = themeByName(i.config.Theme)
+	i.applyGlobalStyle()
+	for _, p := range i.panes {
+		i.withPane(p, func() {
+			i.editText.Configure(textColors()...)
+			i.proseGuide.Configure(Background(theme.Guide))
+			i.columnGuide.Configure(Background(theme.Guide))
+			i.configureEditorTags()
+		})
+	}
+	for _, c := range i.consoles {
+		i.configureConsoleTags(c)
+	}
+	i.consoleDarkVar.Set(checkValue(i.consoleTheme().Name == darkTheme.Name))
+	i.colorStatusbar()
+	i.configureOutlineColors()
+	i.updateCursorPosition()
+}
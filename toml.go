@@ -0,0 +1,490 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -------------------------------------------------------------------------
+// TOML
+// -------------------------------------------------------------------------
+
+// The settings file and the project settings are written in the subset of
+// TOML that ITE reads: tables, whose names may be dotted and quoted, as in
+// [indentation.".go"], and key = value pairs, where a value is a string, a
+// boolean, an integer, a float or an array of strings on one line.
+
+// tomlEntry is a key = value pair of a TOML document.
+type tomlEntry struct {
+	key   []string // Names of the table, then the key, e.g. ["format", "command"]
+	value any      // string, bool, int64, float64 or []string
+	line  int
+}
+
+// parseTOML parses src, keys of tables coming back as "table.key".
+func parseTOML(src string) (map[string]any, error) {
+	entries, err := parseTOMLEntries(src)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any, len(entries))
+	for _, e := range entries {
+		values[strings.Join(e.key, ".")] = e.value
+	}
+	return values, nil
+}
+
+// parseTOMLEntries parses src into its key = value pairs, in order.
+func parseTOMLEntries(src string) ([]tomlEntry, error) {
+	var entries []tomlEntry
+	var table []string
+	for n, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(stripTOMLComment(line))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header", n+1)
+			}
+			name, err := splitTOMLKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			table = name
+			continue
+		}
+		rawKey, raw, found := cutTOMLAssign(line)
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key = value", n+1)
+		}
+		key, err := splitTOMLKey(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		v, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		entries = append(entries, tomlEntry{key: append(slices.Clip(table), key...), value: v, line: n + 1})
+	}
+	return entries, nil
+}
+
+// cutTOMLAssign splits line at its first = outside quotes.
+func cutTOMLAssign(line string) (key, value string, found bool) {
+	var quote rune
+	for n, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '=':
+			return line[:n], line[n+1:], true
+		}
+	}
+	return line, "", false
+}
+
+// splitTOMLKey splits a dotted key into its parts, which are bare, made of
+// letters, digits, _ and -, or quoted.
+func splitTOMLKey(s string) ([]string, error) {
+	var parts []string
+	for {
+		s = strings.TrimSpace(s)
+		var part string
+		switch {
+		case strings.HasPrefix(s, `"`):
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, errors.New("unterminated key")
+			}
+			unquoted, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %s", s[:end+1])
+			}
+			part, s = unquoted, s[end+1:]
+		case strings.HasPrefix(s, "'"):
+			end := strings.IndexByte(s[1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated key")
+			}
+			part, s = s[1:end+1], s[end+2:]
+		default:
+			end := strings.IndexFunc(s, func(r rune) bool { return !isTOMLBareKeyRune(r) })
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid key %q", s)
+			}
+			part, s = s[:end], s[end:]
+		}
+		parts = append(parts, part)
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return parts, nil
+		}
+		if s[0] != '.' {
+			return nil, fmt.Errorf("invalid key %q", s)
+		}
+		s = s[1:]
+	}
+}
+
+// isTOMLBareKeyRune reports whether r may appear in a bare key.
+func isTOMLBareKeyRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
+
+// stripTOMLComment removes the comment ending line, if any, leaving the
+// # characters of strings.
+func stripTOMLComment(line string) string {
+	var quote rune
+	escaped := false
+	for n, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:n]
+		}
+	}
+	return line
+}
+
+// parseTOMLValue parses a value of parseTOML.
+func parseTOMLValue(s string) (any, error) {
+	switch {
+	case s == "true" || s == "false":
+		return s == "true", nil
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") || strings.Contains(s[1:len(s)-1], "'") {
+			return nil, errors.New("invalid literal string")
+		}
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, errors.New("arrays must be on one line")
+		}
+		list := []string{}
+		for _, item := range splitTOMLArray(s[1 : len(s)-1]) {
+			v, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			str, ok := v.(string)
+			if !ok {
+				return nil, errors.New("arrays must hold strings")
+			}
+			list = append(list, str)
+		}
+		return list, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if strings.ContainsAny(s, ".eE") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid value %s", s)
+}
+
+// splitTOMLArray returns the trimmed items of the inside of an array,
+// split at the commas outside strings. A trailing comma is allowed.
+func splitTOMLArray(s string) []string {
+	var items []string
+	var quote rune
+	escaped := false
+	start := 0
+	for n, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, strings.TrimSpace(s[start:n]))
+			start = n + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+// -------------------------------------------------------------------------
+// TOML Encoding
+// -------------------------------------------------------------------------
+
+// The settings file is read and written by reflection over the Config
+// struct, its keys being the names of the json tags of the fields. Fields
+// of basic types are key = value pairs of the top level; a pointer to a
+// struct or a map of basic values is a table, and a map of structs or of
+// maps is a table per key, e.g. [regions.outline] or [spacing.".sql"].
+
+// marshalTOML returns the TOML document of v, a pointer to a struct.
+func marshalTOML(v any) []byte {
+	var b strings.Builder
+	var tables []func()
+	s := reflect.ValueOf(v).Elem()
+	for n := range s.NumField() {
+		name, ok := tomlFieldName(s.Type().Field(n))
+		if !ok {
+			continue
+		}
+		f := s.Field(n)
+		switch {
+		case f.Kind() == reflect.Pointer:
+			if !f.IsNil() {
+				tables = append(tables, func() { writeTOMLTable(&b, []string{name}, f.Elem()) })
+			}
+		case f.Kind() == reflect.Map && (f.Type().Elem().Kind() == reflect.Struct || f.Type().Elem().Kind() == reflect.Map):
+			for _, key := range sortedMapKeys(f) {
+				tables = append(tables, func() { writeTOMLTable(&b, []string{name, key}, f.MapIndex(reflect.ValueOf(key))) })
+			}
+		case f.Kind() == reflect.Map:
+			if f.Len() > 0 {
+				tables = append(tables, func() { writeTOMLTable(&b, []string{name}, f) })
+			}
+		default:
+			fmt.Fprintf(&b, "%s = %s\n", tomlKey(name), tomlValue(f))
+		}
+	}
+	for _, table := range tables {
+		table()
+	}
+	return []byte(b.String())
+}
+
+// writeTOMLTable writes the table called name holding the fields of the
+// struct v, or the entries of the map v.
+func writeTOMLTable(b *strings.Builder, name []string, v reflect.Value) {
+	keys := make([]string, len(name))
+	for n, part := range name {
+		keys[n] = tomlKey(part)
+	}
+	fmt.Fprintf(b, "\n[%s]\n", strings.Join(keys, "."))
+	if v.Kind() == reflect.Map {
+		for _, key := range sortedMapKeys(v) {
+			fmt.Fprintf(b, "%s = %s\n", tomlKey(key), tomlValue(v.MapIndex(reflect.ValueOf(key))))
+		}
+		return
+	}
+	for n := range v.NumField() {
+		if name, ok := tomlFieldName(v.Type().Field(n)); ok {
+			fmt.Fprintf(b, "%s = %s\n", tomlKey(name), tomlValue(v.Field(n)))
+		}
+	}
+}
+
+// sortedMapKeys returns the keys of the map v, sorted.
+func sortedMapKeys(v reflect.Value) []string {
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// tomlFieldName returns the key of the struct field f, the name of its
+// json tag, or false for a field left out.
+func tomlFieldName(f reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" || !f.IsExported() {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// tomlKey returns key bare if it can be, quoted otherwise.
+func tomlKey(key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool { return !isTOMLBareKeyRune(r) }) < 0 {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlValue returns the value of basic type v in TOML.
+func tomlValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		s := strconv.FormatFloat(v.Float(), 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEnI") {
+			s += ".0" // A float, not an integer
+		}
+		return s
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for n := range items {
+			items[n] = tomlString(v.Index(n).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return tomlString(v.String())
+}
+
+// tomlString returns s as a TOML basic string, escaping the characters
+// TOML requires to and nothing else.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unmarshalTOML sets the fields of v, a pointer to a struct, from the TOML
+// document data. Keys v doesn't have are ignored, as settings of other
+// versions of ITE.
+func unmarshalTOML(data []byte, v any) error {
+	entries, err := parseTOMLEntries(string(data))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := setTOML(reflect.ValueOf(v).Elem(), e.key, e.value); err != nil {
+			return fmt.Errorf("line %d: %s: %w", e.line, strings.Join(e.key, "."), err)
+		}
+	}
+	return nil
+}
+
+// setTOML stores value at the path key below v.
+func setTOML(v reflect.Value, key []string, value any) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setTOML(v.Elem(), key, value)
+	case reflect.Struct:
+		if len(key) == 0 {
+			return errors.New("expected a table")
+		}
+		for n := range v.NumField() {
+			if name, ok := tomlFieldName(v.Type().Field(n)); ok && name == key[0] {
+				return setTOML(v.Field(n), key[1:], value)
+			}
+		}
+		return nil
+	case reflect.Map:
+		if len(key) == 0 {
+			return errors.New("expected a table")
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		k := reflect.ValueOf(key[0])
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if old := v.MapIndex(k); old.IsValid() {
+			elem.Set(old)
+		}
+		if err := setTOML(elem, key[1:], value); err != nil {
+			return err
+		}
+		v.SetMapIndex(k, elem)
+		return nil
+	}
+	if len(key) > 0 {
+		return errors.New("expected a value, not a table")
+	}
+	ok := false
+	switch v.Kind() {
+	case reflect.Bool:
+		var b bool
+		if b, ok = value.(bool); ok {
+			v.SetBool(b)
+		}
+	case reflect.String:
+		var s string
+		if s, ok = value.(string); ok {
+			v.SetString(s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, ok = value.(int64); ok {
+			v.SetInt(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		switch f := value.(type) {
+		case float64:
+			v.SetFloat(f)
+			ok = true
+		case int64:
+			v.SetFloat(float64(f))
+			ok = true
+		}
+	case reflect.Slice:
+		var list []string
+		if list, ok = value.([]string); ok && v.Type().Elem().Kind() == reflect.String {
+			v.Set(reflect.ValueOf(list).Convert(v.Type()))
+		}
+	}
+	if !ok {
+		return fmt.Errorf("unexpected value %v", value)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		src  string
+		want map[string]any
+	}{
+		{"", map[string]any{}},
+		{"# only a comment\n\n   \n", map[string]any{}},
+		{`build = "./cmd/ite"`, map[string]any{"build": "./cmd/ite"}},
+		{`run = 'C:\path'`, map[string]any{"run": `C:\path`}},
+		{`s = "a \"quoted\" \\ # not a comment" # comment`, map[string]any{"s": `a "quoted" \ # not a comment`}},
+		{`s = 'it # stays'`, map[string]any{"s": "it # stays"}},
+		{"on = true\noff = false", map[string]any{"on": true, "off": false}},
+		{"n = 42\nneg = -7", map[string]any{"n": int64(42), "neg": int64(-7)}},
+		{"f = 1.5\ne = 2e3", map[string]any{"f": 1.5, "e": 2000.0}},
+		{`a = ["x", 'y', "z,w"]`, map[string]any{"a": []string{"x", "y", "z,w"}}},
+		{`a = ["x",]`, map[string]any{"a": []string{"x"}}},
+		{`a = []`, map[string]any{"a": []string{}}},
+		{"[format]\ncommand = \"gofumpt\"\non_save = true", map[string]any{"format.command": "gofumpt", "format.on_save": true}},
+		{"[ a . b ]\nk = 1", map[string]any{"a.b.k": int64(1)}},
+		{"[indentation.\".go\"]\nwidth = 4", map[string]any{"indentation..go.width": int64(4)}},
+		{"\"a=b\" = 1", map[string]any{"a=b": int64(1)}},
+		{"x.y = 1", map[string]any{"x.y": int64(1)}},
+	}
+	for _, tt := range tests {
+		got, err := parseTOML(tt.src)
+		if err != nil {
+			t.Errorf("parseTOML(%q): %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTOML(%q) = %#v, want %#v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, src := range []string{
+		"just words",
+		"= 1",
+		"k =",
+		"k = bare",
+		`k = "unterminated`,
+		"k = 'unterminated",
+		"k = 'a'b'",
+		`k = ["a", 1]`,
+		"k = [\"a\",\n\"b\"]",
+		"[table",
+		"[[array.of.tables]]",
+		"[]",
+		"[a..b]",
+		"bad key = 1",
+		`"unterminated = 1`,
+	} {
+		if got, err := parseTOML(src); err == nil {
+			t.Errorf("parseTOML(%q) = %v, want an error", src, got)
+		}
+	}
+}
+
+func TestProjectSettings(t *testing.T) {
+	values, err := parseTOML("build = \"./cmd\"\nexclude = [\"testdata\"]\n[format]\non_save = true\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s projectSettings
+	for key, v := range values {
+		if err := s.set(key, v); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+	}
+	if s.buildTarget() != "./cmd" || len(s.Exclude) != 1 || !s.Format.OnSave {
+		t.Errorf("settings = %+v", s)
+	}
+	if err := s.set("unknown", "x"); err == nil {
+		t.Error("unknown setting accepted")
+	}
+	if err := s.set("build", int64(1)); err == nil {
+		t.Error("integer build target accepted")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/trace"
+)
+
+// -------------------------------------------------------------------------
+// Performance Tracing
+// -------------------------------------------------------------------------
+
+// Started with --trace FILE, ITE writes a Go execution trace to FILE until
+// it quits, to be opened with `go tool trace FILE`. Besides what the
+// runtime records, the trace holds a task for every command run and for
+// every job of the consoles, and regions for the editor key handling, the
+// callbacks dispatched from background goroutines, the Tcl evaluations,
+// logged with their script, and the steps of the jobs.
+var traceFile *os.File // Trace being written, nil when not tracing
+
+// startTrace starts writing the execution trace to path.
+func startTrace(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return err
+	}
+	traceFile = f
+	return nil
+}
+
+// stopTrace ends the execution trace, if any.
+func stopTrace() {
+	if traceFile == nil {
+		return
+	}
+	trace.Stop()
+	if err := traceFile.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "ite: writing trace: %v\n", err)
+	}
+	traceFile = nil
+}
+
+// traceTask starts a task of the trace called name, such as "command
+// save", and returns its context and the function ending it.
+func traceTask(name string) (context.Context, func()) {
+	if !trace.IsEnabled() {
+		return context.Background(), func() {}
+	}
+	ctx, task := trace.NewTask(context.Background(), name)
+	return ctx, task.End
+}
+
+// traceRegion starts a region of the trace called name in the current
+// goroutine and returns the function ending it.
+func traceRegion(ctx context.Context, name string) func() {
+	return trace.StartRegion(ctx, name).End
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import "os"
+
+// -------------------------------------------------------------------------
+// Trash
+// -------------------------------------------------------------------------
+
+// discardFile removes the file at path on behalf of the user: it goes to
+// the trash of the desktop (XDG trash, Recycle Bin or macOS Trash), so it
+// can be restored, unless the trash is disabled in the settings.
+func (i *Ite) discardFile(path string) error {
+	if !i.config.UseTrash {
+		return os.Remove(path)
+	}
+	return moveToTrash(path)
+}
+
+// onToggleTrash switches between moving discarded files to the trash and
+// deleting them, and persists the choice.
+func (i *Ite) onToggleTrash() {
+	i.config.UseTrash = !i.config.UseTrash
+	i.useTrashVar.Set(checkValue(i.config.UseTrash))
+	i.saveConfig()
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// moveToTrash asks the Finder to move the file at path to the Trash, which
+// keeps the "Put Back" information.
+func moveToTrash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	quoted := strings.ReplaceAll(strings.ReplaceAll(abs, `\`, `\\`), `"`, `\"`)
+	script := fmt.Sprintf(`tell application "Finder" to delete POSIX file "%s"`, quoted)
+	if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("moving %s to the Trash: %v: %s", filepath.Base(abs), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
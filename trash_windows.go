@@ -0,0 +1,29 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// moveToTrash sends the file at path to the Recycle Bin through the
+// Visual Basic file system helpers, which PowerShell can load.
+func moveToTrash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	quoted := strings.ReplaceAll(abs, "'", "''")
+	script := "Add-Type -AssemblyName Microsoft.VisualBasic; " +
+		"[Microsoft.VisualBasic.FileIO.FileSystem]::DeleteFile('" + quoted + "', 'OnlyErrorDialogs', 'SendToRecycleBin')"
+	if out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("moving %s to the Recycle Bin: %v: %s", filepath.Base(abs), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+//go:build unix && !darwin
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// moveToTrash moves the file at path to the trash as described by the
+// FreeDesktop.org Trash specification, with a .trashinfo record so file
+// managers can restore it. Files on the file system of the home directory
+// go to the home trash, others to the trash at the top of their own file
+// system, since a file can't be moved across file systems.
+func moveToTrash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	base := filepath.Base(abs)
+	trash, err := trashDir(abs)
+	if err != nil {
+		return fmt.Errorf("moving %s to the trash: %w", base, err)
+	}
+
+	// Reserve a unique name by creating its info file exclusively
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	name := base
+	var info *os.File
+	for n := 2; ; n++ {
+		info, err = os.OpenFile(filepath.Join(trash, "info", name+".trashinfo"),
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+		name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+	}
+	escaped := (&url.URL{Path: abs}).EscapedPath()
+	_, err = fmt.Fprintf(info, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		escaped, time.Now().Format("2006-01-02T15:04:05"))
+	if cerr := info.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(abs, filepath.Join(trash, "files", name))
+	}
+	if err != nil {
+		os.Remove(filepath.Join(trash, "info", name+".trashinfo"))
+		return fmt.Errorf("moving %s to the trash: %w", base, err)
+	}
+	return nil
+}
+
+// trashDir returns the trash for the file at abs, creating it if needed:
+// the home trash when the file is on the same file system, otherwise
+// $topdir/.Trash/$uid when the administrator set up $topdir/.Trash, or
+// $topdir/.Trash-$uid, $topdir being the top of the file's file system.
+func trashDir(abs string) (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	trash := filepath.Join(dataHome, "Trash")
+	if err := os.MkdirAll(trash, 0700); err != nil {
+		return "", err
+	}
+	dev, err := deviceOf(filepath.Dir(abs))
+	if err != nil {
+		return "", err
+	}
+	if homeDev, err := deviceOf(trash); err != nil || homeDev != dev {
+		top := filepath.Dir(abs)
+		for parent := filepath.Dir(top); parent != top; top, parent = parent, filepath.Dir(parent) {
+			if d, err := deviceOf(parent); err != nil || d != dev {
+				break
+			}
+		}
+		uid := fmt.Sprint(os.Getuid())
+		shared := filepath.Join(top, ".Trash")
+		if fi, err := os.Lstat(shared); err == nil && fi.IsDir() && fi.Mode()&os.ModeSticky != 0 {
+			trash = filepath.Join(shared, uid)
+		} else {
+			trash = filepath.Join(top, ".Trash-"+uid)
+		}
+	}
+	for _, dir := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(trash, dir), 0700); err != nil {
+			return "", err
+		}
+	}
+	return trash, nil
+}
+
+// deviceOf returns the device of the file system holding path.
+func deviceOf(path string) (uint64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("no device for %s", path)
+	}
+	return uint64(st.Dev), nil
+}
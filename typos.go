@@ -0,0 +1,250 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// -------------------------------------------------------------------------
+// Spelling of Names
+// -------------------------------------------------------------------------
+
+// Check Spelling of Names looks for likely misspellings, such as
+// "Recieve", in the exported names of the Go files of the project and in
+// their doc comments. Names are split at their camelCase humps, acronyms
+// left alone, and a word is reported only when the dictionary knows a
+// word close to it. The hints are listed in the Lint console and
+// underlined in the editor, apart from the problems of the linters.
+const (
+	noteTypo            = "typo"     // Kind of the hints about misspelled names
+	tagTypoNote         = "typonote" // Editor tag underlining lines with typo hints
+	statusCheckingNames = "Checking the spelling of names...\n"
+)
+
+// splitIdentifier returns the words of the identifier name: runs of
+// letters split before an upper case letter following a lower case one,
+// and before the last capital of an acronym followed by lower case, as in
+// "Recieve", "HTTP", "Request" for RecieveHTTPRequest.
+func splitIdentifier(name string) []proseWord {
+	var words []proseWord
+	runes := []rune(name)
+	start, offset := -1, 0 // Rune and byte offsets of the current word
+	pos := 0               // Byte offset of runes[n]
+	for n, r := range runes {
+		if !unicode.IsLetter(r) {
+			if start >= 0 {
+				words = append(words, proseWord{name[offset:pos], offset})
+				start = -1
+			}
+			pos += utf8.RuneLen(r)
+			continue
+		}
+		hump := start >= 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[n-1]) || n+1 < len(runes) && unicode.IsLower(runes[n+1]) && unicode.IsUpper(runes[n-1]))
+		if hump {
+			words = append(words, proseWord{name[offset:pos], offset})
+			start = -1
+		}
+		if start < 0 {
+			start, offset = n, pos
+		}
+		pos += utf8.RuneLen(r)
+	}
+	if start >= 0 {
+		words = append(words, proseWord{name[offset:], offset})
+	}
+	return words
+}
+
+// nameTypos returns the likely misspellings of the exported names
+// declared in the Go source src of the file at path, and of the words of
+// their doc comments, as diagnostics of kind noteTypo. Words in ignored,
+// in lower case, are skipped.
+func nameTypos(path string, src []byte, d dictionary, ignored map[string]bool) ([]diagnostic, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var typos []diagnostic
+	seen := make(map[token.Pos]bool) // Doc comments shared by several names
+	check := func(word string, pos token.Pos, report func(suggestion string) string) {
+		if !isProse(word) || ignored[strings.ToLower(word)] || d.known(word) {
+			return
+		}
+		suggestions := d.suggest(word)
+		if len(suggestions) == 0 {
+			return // Too far from any word to be a typo
+		}
+		p := fset.Position(pos)
+		typos = append(typos, diagnostic{
+			location: location{path: path, line: p.Line, col: p.Column},
+			message:  report(suggestions[0]),
+			kind:     noteTypo,
+		})
+	}
+
+	idents := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			idents[id.Name] = true
+		}
+		return true
+	})
+	checkDoc := func(doc *ast.CommentGroup) {
+		if doc == nil || seen[doc.Pos()] {
+			return
+		}
+		seen[doc.Pos()] = true
+		for _, c := range doc.List {
+			if directiveRe.MatchString(strings.TrimPrefix(c.Text, "//")) {
+				continue
+			}
+			for _, w := range proseWords(c.Text) {
+				if idents[w.text] {
+					continue
+				}
+				check(w.text, c.Slash+token.Pos(w.start), func(suggestion string) string {
+					return fmt.Sprintf("hint: %q in a doc comment may be misspelled; did you mean %q?", w.text, suggestion)
+				})
+			}
+		}
+	}
+	checkName := func(id *ast.Ident, doc *ast.CommentGroup) {
+		if id == nil || !id.IsExported() {
+			return
+		}
+		for _, w := range splitIdentifier(id.Name) {
+			check(w.text, id.Pos()+token.Pos(w.start), func(suggestion string) string {
+				return fmt.Sprintf("hint: %q in %s may be misspelled; did you mean %q?", w.text, id.Name, suggestion)
+			})
+		}
+		checkDoc(doc)
+	}
+	checkFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, f := range fields.List {
+			for _, name := range f.Names {
+				checkName(name, f.Doc)
+			}
+		}
+	}
+
+	checkDoc(file.Doc)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			checkName(decl.Name, decl.Doc)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc := spec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					checkName(spec.Name, doc)
+					switch t := spec.Type.(type) {
+					case *ast.StructType:
+						checkFields(t.Fields)
+					case *ast.InterfaceType:
+						checkFields(t.Methods)
+					}
+				case *ast.ValueSpec:
+					doc := spec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					for _, name := range spec.Names {
+						checkName(name, doc)
+					}
+				}
+			}
+		}
+	}
+	return typos, nil
+}
+
+// onCheckNameSpelling checks the spelling of the exported names of the
+// Go files of the project of the current file, and of their doc comments,
+// in the background, and lists the hints in the Lint console.
+func (i *Ite) onCheckNameSpelling() {
+	if i.currentFile == "" {
+		i.showError(statusNoFile)
+		return
+	}
+	d := i.dictionary()
+	if d == nil {
+		return // dictionary told why
+	}
+	if i.editText.Modified() {
+		i.onSave()
+	}
+	root := i.projectDir()
+	exclude := i.projectSettingsOrError().Exclude
+	ignored := maps.Clone(i.ignoredWords())
+	i.clearDiagnostics(noteTypo)
+
+	c := i.console(consoleLint)
+	if c.text.Index("end-1c") != "1.0" {
+		i.appendConsole(c, "\n", "")
+	}
+	i.appendConsole(c, tr(statusCheckingNames), tagRunStart)
+	i.consoleTabs.Select(c.frame)
+
+	pending := slices.Collect(maps.Values(i.saving))
+	i.goBackground(func(ctx context.Context) {
+		for _, s := range pending {
+			<-s.written // The files being saved are read complete
+		}
+		var typos []diagnostic
+		files := 0
+		walkProject(root, exclude, func(path string) error {
+			if ctx.Err() != nil {
+				return filepath.SkipAll
+			}
+			if filepath.Ext(path) != defaultFileExtension {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			found, err := nameTypos(path, src, d, ignored)
+			if err != nil {
+				return nil // Left to the compiler
+			}
+			files++
+			typos = append(typos, found...)
+			return nil
+		})
+		i.Dispatch(func() {
+			var sb strings.Builder
+			for _, t := range typos {
+				fmt.Fprintf(&sb, "%s:%d:%d: %s\n", t.path, t.line, t.col, t.message)
+				i.addDiagnostic(t)
+			}
+			i.appendConsole(c, sb.String(), "")
+			if len(typos) == 0 {
+				i.appendConsole(c, fmt.Sprintf("\nNo likely misspellings in %d files\n", files), tagTestPass)
+			} else {
+				i.appendConsole(c, fmt.Sprintf("\n%d likely misspellings in %d files\n", len(typos), files), tagTestSkip)
+			}
+		})
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"RecieveHTTPRequest", []string{"Recieve", "HTTP", "Request"}},
+		{"parseJSON", []string{"parse", "JSON"}},
+		{"UTF8Decoder", []string{"UTF", "Decoder"}},
+		{"max_size", []string{"max", "size"}},
+		{"ID", []string{"ID"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, w := range splitIdentifier(tt.name) {
+			if tt.name[w.start:w.start+len(w.text)] != w.text {
+				t.Errorf("splitIdentifier(%q): %q at %d", tt.name, w.text, w.start)
+			}
+			got = append(got, w.text)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameTypos(t *testing.T) {
+	d := dictionary{}
+	for _, w := range []string{"receive", "data", "the", "from", "channel", "send", "message", "size"} {
+		d[w] = struct{}{}
+	}
+	src := `package p
+
+// RecieveData reads the data from the chanel.
+func RecieveData() {}
+
+// SendMessage sends the message.
+func SendMessage() {}
+
+func recieveQuietly() {}
+
+type Message struct {
+	Sise int
+	HTTPData string
+}
+
+//go:generate stringer -type=Message
+`
+	typos, err := nameTypos("p.go", []byte(src), d, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, typo := range typos {
+		got = append(got, typo.message)
+		if typo.kind != noteTypo {
+			t.Errorf("%s: kind %q", typo.message, typo.kind)
+		}
+	}
+	want := []string{
+		`hint: "Recieve" in RecieveData may be misspelled; did you mean "Receive"?`,
+		`hint: "chanel" in a doc comment may be misspelled; did you mean "channel"?`,
+		`hint: "Sise" in Sise may be misspelled; did you mean "Size"?`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("nameTypos = %q, want %q", got, want)
+	}
+	if typos[0].line != 4 || typos[0].col != 6 || typos[1].line != 3 || typos[1].col != 40 {
+		t.Errorf("locations %v and %v, want 4:6 and 3:40", typos[0].location, typos[1].location)
+	}
+
+	typos, _ = nameTypos("p.go", []byte(src), d, map[string]bool{"recieve": true, "chanel": true, "sise": true})
+	if len(typos) != 0 {
+		t.Errorf("ignored words reported: %v", typos)
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "modernc.org/tk9.0"
+)
+
+func TestAutoIndent(t *testing.T) {
+	h := newHarness(t)
+	var autoClose bool
+	h.do(func() { autoClose, h.i.config.AutoClosePairs = h.i.config.AutoClosePairs, true })
+	t.Cleanup(func() { h.do(func() { h.i.config.AutoClosePairs = autoClose }) })
+	h.typeText("func f() {\nreturn")
+	h.wantText("func f() {\n\treturn\n}")
+	h.wantCursor("2.7")
+
+	h.setText("\tx := 1")
+	h.do(func() { h.i.editText.MarkSet("insert", "end-1c") })
+	h.typeText("\ny")
+	h.wantText("\tx := 1\n\ty")
+}
+
+func TestCloseBraceDedents(t *testing.T) {
+	h := newHarness(t)
+	var autoClose bool
+	h.do(func() { autoClose, h.i.config.AutoClosePairs = h.i.config.AutoClosePairs, false })
+	t.Cleanup(func() { h.do(func() { h.i.config.AutoClosePairs = autoClose }) })
+	h.typeText("if x {\ny()\n}")
+	h.wantText("if x {\n\ty()\n}")
+}
+
+func TestSearch(t *testing.T) {
+	h := newHarness(t)
+	h.setText("alpha beta\nAlpha gamma\nalpha")
+	h.do(func() { h.i.editText.MarkSet("insert", "1.3") })
+	h.run("find")
+	h.typeText("alpha")
+	count := func() string {
+		var text string
+		h.do(func() { text = h.i.search.count.Txt() })
+		return text
+	}
+	if got := count(); got != "1 of 3" {
+		t.Fatalf("count is %q, want %q", got, "1 of 3")
+	}
+	h.wantCursor("2.0")
+
+	h.press("Return")
+	h.press("Return")
+	if got := count(); got != "3 of 3" {
+		t.Fatalf("count is %q, want %q", got, "3 of 3")
+	}
+	h.press("Return")
+	if got := count(); got != "1 of 3 (wrapped)" {
+		t.Fatalf("count is %q, want %q", got, "1 of 3 (wrapped)")
+	}
+
+	h.press("Escape")
+	h.wantCursor("1.3")
+	h.do(func() {
+		if h.i.search.shown {
+			t.Error("search bar still shown after Escape")
+		}
+	})
+}
+
+func TestSearchSmartCase(t *testing.T) {
+	h := newHarness(t)
+	h.setText("alpha Alpha ALPHA")
+	h.run("find")
+	h.typeText("Alpha")
+	h.press("Control-Return")
+	var sel []string
+	h.do(func() { sel = h.i.editText.TagRanges("sel") })
+	if len(sel) != 2 || sel[0] != "1.6" || sel[1] != "1.11" {
+		t.Fatalf("selection is %q, want the match at 1.6", sel)
+	}
+}
+
+func TestSavePrompt(t *testing.T) {
+	h := newHarness(t)
+	h.typeText("draft")
+
+	h.answer("cancel")
+	h.run("new")
+	h.wantText("draft")
+
+	h.answer("no")
+	h.run("new")
+	h.wantText("")
+	if h.asked != 2 {
+		t.Fatalf("asked %d times, want 2", h.asked)
+	}
+
+	h.run("new") // Nothing to save, no question
+	if h.asked != 2 {
+		t.Fatalf("asked %d times, want 2", h.asked)
+	}
+}
+
+func TestSavePromptSaves(t *testing.T) {
+	h := newHarness(t)
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	h.do(func() { err = h.i.openFile(path) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.do(func() { h.i.editText.MarkSet("insert", "1.0") })
+	h.typeText("new ")
+
+	h.answer("yes")
+	h.run("new")
+	h.wantText("")
+	h.waitFor("the file to be saved", func() bool {
+		data, _ := os.ReadFile(path)
+		return string(data) == "new old\n"
+	})
+}
+
+func TestQuitKeepsBufferChangedOnDisk(t *testing.T) {
+	h := newHarness(t)
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	h.do(func() { err = h.i.openFile(path) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		h.do(func() {
+			tclEval(`foreach w [winfo children .] {
+				if {[winfo class $w] eq "Toplevel" && [wm title $w] eq "File Changed"} {destroy $w}
+			}`)
+			h.i.changePrompt = false
+			h.i.editText.SetModified(false)
+		})
+	})
+	h.do(func() { h.i.editText.MarkSet("insert", "1.0") })
+	h.typeText("new ")
+	if err := os.WriteFile(path, []byte("changed elsewhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h.answer("yes")
+	h.run("quit")
+	h.wantText("new old")
+	h.do(func() {
+		if !h.i.changePrompt {
+			t.Error("not asked about the file changed on disk")
+		}
+		if !h.i.editText.Modified() {
+			t.Error("buffer no longer modified")
+		}
+	})
+	if data, _ := os.ReadFile(path); string(data) != "changed elsewhere\n" {
+		t.Errorf("file overwritten with %q", data)
+	}
+}
+
+func TestSavePromptReadOnly(t *testing.T) {
+	h := newHarness(t)
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	h.do(func() { err = h.i.openFile(path) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		h.do(func() {
+			h.i.setReadOnly(false)
+			h.i.editText.SetModified(false)
+		})
+	})
+	h.do(func() {
+		h.i.editText.Insert("1.0", "new ")
+		h.i.setReadOnly(true)
+	})
+
+	h.answer("yes", "cancel") // Save, then no writable copy
+	h.run("new")
+	h.wantText("new old")
+}
+
+func TestBuildConsole(t *testing.T) {
+	h := newHarness(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/broken\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {\n\tundefinedName()\n}\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var err error
+	h.do(func() { err = h.i.openFile(filepath.Join(dir, "main.go")) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.run("build")
+	h.waitFor("the build error", func() bool {
+		return strings.Contains(h.consoleText(consoleBuild), "undefined: undefinedName")
+	})
+}
+
+func TestModifiedReturnLeftToKeyBindings(t *testing.T) {
+	h := newHarness(t)
+	h.setText("x := y")
+	h.do(func() { h.i.editText.MarkSet("insert", "1.6") })
+	h.press("Alt-Return") // Convert Number, with no number at the cursor
+	h.wantText("x := y")
+	h.press("Return")
+	h.wantText("x := y\n")
+}
+
+func TestBuildConsoleFakeRunner(t *testing.T) {
+	h := newHarness(t)
+	fake := &fakeRunner{programs: map[string]fakeProgram{
+		"go build ./...": {stderr: "# example.com/x\n./main.go:4:2: undefined: y\n", exitCode: 1},
+	}}
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	h.do(func() {
+		h.i.runner = fake
+		err = h.i.openFile(path)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.do(func() { h.i.runner = execRunner{} }) })
+	h.run("build")
+	h.waitFor("the build to fail", func() bool {
+		out := h.consoleText(consoleBuild)
+		return strings.Contains(out, "undefined: y") && strings.Contains(out, "Build failed: exit status 1")
+	})
+}
+
+func TestPasteBlockPastBuffer(t *testing.T) {
+	h := newHarness(t)
+	h.setText("ab\ncd")
+	var status string
+	h.do(func() {
+		h.i.startBlock("1.1")
+		h.i.extendBlock(1, 0)
+		ClipboardClear()
+		ClipboardAppend("X\nY\nZ\nW")
+		h.i.pasteBlock()
+	})
+	h.wantText("aXb\ncYd\n Z\n W")
+
+	h.setText("abcd\nefgh")
+	h.do(func() {
+		h.i.startBlock("1.1")
+		h.i.extendBlock(1, 2)
+		status = h.i.statusSelection.Txt()
+		h.i.endBlock()
+	})
+	if status != "4 chars, 2 lines selected, 2 carets" {
+		t.Errorf("status of the block is %q", status)
+	}
+}
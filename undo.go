@@ -0,0 +1,141 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Undo Grouping
+// -------------------------------------------------------------------------
+
+const (
+	defaultUndoGroupMillis = 1000      // Pause that closes an undo step
+	undoBindTag            = "IteUndo" // Bind tag seeing every key before the editor
+)
+
+// editKind classifies a keystroke for undo grouping.
+type editKind int
+
+const (
+	editNone   editKind = iota // Cursor movement or start of a new step
+	editWord                   // Typing a word character
+	editOther                  // Typing whitespace or punctuation
+	editDelete                 // BackSpace or Delete
+)
+
+// undoGrouper decides where undo separators go in the main editor. Tk's
+// automatic separators make a whole run of typing a single step; instead,
+// a new step starts with every word, after a pause in typing, when
+// switching between typing and deleting, and after the cursor moves.
+type undoGrouper struct {
+	lastKind editKind
+	lastTime time.Time
+}
+
+// bindUndoGrouping turns off Tk's automatic separators for the editor and
+// installs the keystroke tracking. The tracking runs from a dedicated bind
+// tag placed first, so it sees keys that later bindings intercept.
+func (i *Ite) bindUndoGrouping() {
+	i.editText.Configure(Autoseparators(false))
+	addBindtag(i.editText.Window, undoBindTag, "")
+
+	Bind(undoBindTag, "<KeyPress>", Command(i.onUndoKeyPress))
+	Bind(undoBindTag, "<ButtonPress>", Command(i.breakUndoGroup))
+	for _, event := range []string{"<<Cut>>", "<<Paste>>", "<<Undo>>", "<<Redo>>"} {
+		Bind(undoBindTag, event, Command(i.breakUndoGroup))
+	}
+}
+
+// onUndoKeyPress inserts an undo separator before the keystroke when it
+// starts a new undo step.
+func (i *Ite) onUndoKeyPress(e *Event) {
+	if e.State&ModifierControl != 0 || i.composing {
+		return // Shortcuts handle their own grouping
+	}
+	kind, ok := classifyKey(e.Keysym, i.isWordChar)
+	if !ok {
+		return // Modifier keys and the like
+	}
+	if kind == editNone {
+		i.undo.lastKind = editNone
+		return
+	}
+
+	now := time.Now()
+	interval := time.Duration(i.config.UndoGroupMillis) * time.Millisecond
+	last := i.undo.lastKind
+	if last == editNone ||
+		now.Sub(i.undo.lastTime) > interval ||
+		(kind == editWord && last != editWord) ||
+		(kind == editDelete) != (last == editDelete) {
+		i.undoSeparator()
+	}
+	i.undo.lastKind = kind
+	i.undo.lastTime = now
+}
+
+// classifyKey maps a Tk keysym to the kind of edit it performs. ok is false
+// for keys that neither edit nor move the cursor, dead keys included: the
+// character they compose arrives with the next key event.
+func classifyKey(keysym string, isWord func(rune) bool) (kind editKind, ok bool) {
+	switch keysym {
+	case "BackSpace", "Delete":
+		return editDelete, true
+	case "space", "Return", "KP_Enter", "Tab":
+		return editOther, true
+	case "Left", "Right", "Up", "Down", "Home", "End", "Prior", "Next":
+		return editNone, true
+	case keysymUnknown:
+		return editOther, true // Text committed by an input method
+	}
+	r, ok := keysymRune(keysym)
+	if !ok {
+		return editNone, false
+	}
+	if isWord(r) {
+		return editWord, true
+	}
+	return editOther, true
+}
+
+// breakUndoGroup makes the next edit start a new undo step.
+func (i *Ite) breakUndoGroup() {
+	i.undoSeparator()
+	i.undo.lastKind = editNone
+}
+
+// undoSeparator closes the current undo step of the editor.
+func (i *Ite) undoSeparator() {
+	tclEval("%s edit separator", i.editText)
+}
+
+// editGroup runs a programmatic edit of the buffer as a single undo step.
+func (i *Ite) editGroup(edit func()) {
+	i.breakUndoGroup()
+	edit()
+	i.breakUndoGroup()
+}
+
+// onUndoInterval lets the user choose the typing pause, in milliseconds,
+// after which the next keystroke starts a new undo step.
+func (i *Ite) onUndoInterval() {
+	i.promptString("Undo Grouping",
+		"Pause in milliseconds that starts a new undo step:",
+		strconv.Itoa(i.config.UndoGroupMillis),
+		func(value string) {
+			ms, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || ms <= 0 {
+				i.showError("Invalid interval: " + value)
+				return
+			}
+			i.config.UndoGroupMillis = ms
+			i.saveConfig()
+		})
+}
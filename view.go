@@ -0,0 +1,49 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"strconv"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// View Options
+// -------------------------------------------------------------------------
+
+// onToggleTypewriter switches typewriter scrolling on or off and persists
+// the choice. While enabled, the cursor line is kept vertically centered.
+func (i *Ite) onToggleTypewriter() {
+	i.config.TypewriterScrolling = !i.config.TypewriterScrolling
+	i.typewriterVar.Set(checkValue(i.config.TypewriterScrolling))
+	i.saveConfig()
+	if i.config.TypewriterScrolling {
+		i.centerCursorLine()
+	}
+}
+
+// centerCursorLine adjusts the editor's yview so that the line holding the
+// insert cursor sits in the middle of the visible area.
+func (i *Ite) centerCursorLine() {
+	height, err := strconv.Atoi(WinfoHeight(i.editText.Window))
+	if err != nil || height <= 1 {
+		return // Not mapped yet
+	}
+	above := textCount(i.editText, Ypixels(), "1.0", "insert linestart")
+	total := textCount(i.editText, Ypixels(), "1.0", "end")
+	if total <= height {
+		return // Everything fits, nothing to scroll
+	}
+	top := max(above-height/2, 0)
+	i.editText.Yviewmoveto(float64(top) / float64(total))
+}
+
+// checkValue converts a boolean to the on/off value of a Tk checkbutton.
+func checkValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Virtual Text
+// -------------------------------------------------------------------------
+
+// Virtual text is shown after the end of a line without being part of the
+// buffer: it isn't saved, copied, searched or undone, and it takes no
+// index, so the columns of the line stay those of the file. A tag only
+// styles the text of the buffer and an embedded window would take an
+// index, so each piece is a label placed over the editor, like the
+// indentation guides.
+//
+// Features add a source with registerVirtualText, computing the pieces of
+// the lines in view of a file, and call scheduleVirtualText when what
+// they show changes. Every source is drawn again once Tk is idle, after
+// the view scrolled, the cursor moved or the text changed, the pieces of
+// a line in the order the sources were registered.
+const (
+	maxVirtualText = 120 // Characters of a piece shown at most
+	virtualTextGap = 3   // Columns between the end of a line and its virtual text
+)
+
+// virtualText is a piece of text shown after the end of a line.
+type virtualText struct {
+	line  int // 1-based line
+	text  string
+	color string // Foreground, "" for theme.Muted
+}
+
+// virtualTextSource computes the virtual text of the lines first to last
+// of the file at path, "" for an untitled buffer.
+type virtualTextSource struct {
+	name  string
+	lines func(path string, first, last int) []virtualText
+}
+
+// virtualTextState holds the sources of virtual text and its labels.
+type virtualTextState struct {
+	sources []virtualTextSource            // In drawing order
+	labels  map[*TextWidget][]*LabelWidget // By editor, the hidden ones kept for reuse
+	pending bool                           // The virtual text is to be redrawn
+}
+
+// registerVirtualText adds the source name of virtual text, replacing the
+// one of the same name if any.
+func (i *Ite) registerVirtualText(name string, lines func(path string, first, last int) []virtualText) {
+	src := virtualTextSource{name: name, lines: lines}
+	if n := slices.IndexFunc(i.virtual.sources, func(s virtualTextSource) bool { return s.name == name }); n >= 0 {
+		i.virtual.sources[n] = src
+	} else {
+		i.virtual.sources = append(i.virtual.sources, src)
+	}
+	i.scheduleVirtualText()
+}
+
+// scheduleVirtualText redraws the virtual text of every pane once Tk is
+// idle.
+func (i *Ite) scheduleVirtualText() {
+	if i.virtual.pending {
+		return
+	}
+	i.virtual.pending = true
+	TclAfterIdle(func() {
+		i.virtual.pending = false
+		for _, p := range i.panes {
+			if p == i.active {
+				i.drawVirtualText(i.editText, i.currentFile, i.largeFile)
+			} else {
+				i.drawVirtualText(p.text, p.file, p.largeFile)
+			}
+		}
+	})
+}
+
+// collectVirtualText returns the pieces of the sources for the lines
+// first to last of path, by line, each line's in the order of the
+// sources.
+func collectVirtualText(sources []virtualTextSource, path string, first, last int) [][]virtualText {
+	lines := make([][]virtualText, last-first+1)
+	for _, src := range sources {
+		for _, v := range src.lines(path, first, last) {
+			if v.line >= first && v.line <= last && v.text != "" {
+				lines[v.line-first] = append(lines[v.line-first], v)
+			}
+		}
+	}
+	return lines
+}
+
+// clipVirtualText returns text on a single line of at most maxVirtualText
+// characters.
+func clipVirtualText(text string) string {
+	text, _, _ = strings.Cut(text, "\n")
+	if utf8.RuneCountInString(text) <= maxVirtualText {
+		return text
+	}
+	return string([]rune(text)[:maxVirtualText-1]) + "…"
+}
+
+// drawVirtualText places the virtual text of the lines visible in text,
+// editing the file at path, reusing the labels made for it before and
+// hiding those left over.
+func (i *Ite) drawVirtualText(text *TextWidget, path string, large bool) {
+	labels := i.virtual.labels[text]
+	used := 0
+	defer func() {
+		for _, l := range labels[used:] {
+			tclEval("place forget %s", l)
+		}
+		if i.virtual.labels == nil {
+			i.virtual.labels = make(map[*TextWidget][]*LabelWidget)
+		}
+		i.virtual.labels[text] = labels
+	}()
+	if len(i.virtual.sources) == 0 || large || i.loads[text] != nil {
+		return
+	}
+	height := winfoInt(tclEval("winfo height %s", text))
+	first, _ := parseIndex(text.Index("@0,0"))
+	last, _ := parseIndex(text.Index(fmt.Sprintf("@0,%d", height)))
+	cursor, _ := parseIndex(text.Index("insert"))
+	gap := virtualTextGap * i.editorFont.Measure(text.Window, "0")
+	for n, pieces := range collectVirtualText(i.virtual.sources, path, first, last) {
+		line := first + n
+		bbox := strings.Fields(tclEval("%s bbox {%d.0 lineend}", text, line)) // The newline, after the last character
+		if len(pieces) == 0 || len(bbox) < 4 {
+			continue // Nothing to show, or folded or scrolled out of view
+		}
+		background := theme.Text
+		if line == cursor && i.config.HighlightLine {
+			background = theme.CurrentLine
+		}
+		x := winfoInt(bbox[0]) + gap
+		for _, v := range pieces {
+			if used == len(labels) {
+				labels = append(labels, text.Label(Borderwidth(0), Padx(0), Pady(0)))
+			}
+			l := labels[used]
+			used++
+			l.Configure(Txt(clipVirtualText(v.text)), Font(editorFontFamily, fontSize, "italic"),
+				Foreground(orDefault(v.color, theme.Muted)), Background(background))
+			Place(l, X(x), Y(winfoInt(bbox[1])))
+			x += winfoInt(tclEval("winfo reqwidth %s", l)) + gap
+		}
+	}
+}
+
+// forgetVirtualText drops the labels of text, an editor being destroyed.
+func (i *Ite) forgetVirtualText(text *TextWidget) {
+	delete(i.virtual.labels, text)
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCollectVirtualText(t *testing.T) {
+	sources := []virtualTextSource{
+		{name: "a", lines: func(path string, first, last int) []virtualText {
+			return []virtualText{{line: 3, text: "a3"}, {line: 9, text: "out of view"}, {line: 4, text: ""}}
+		}},
+		{name: "b", lines: func(path string, first, last int) []virtualText {
+			return []virtualText{{line: 3, text: "b3"}, {line: 2, text: "b2"}}
+		}},
+	}
+	got := collectVirtualText(sources, "x.go", 2, 4)
+	want := [][]virtualText{{{line: 2, text: "b2"}}, {{line: 3, text: "a3"}, {line: 3, text: "b3"}}, nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectVirtualText = %v, want %v", got, want)
+	}
+}
+
+func TestClipVirtualText(t *testing.T) {
+	if got := clipVirtualText("first\nsecond"); got != "first" {
+		t.Errorf("clipVirtualText kept %q", got)
+	}
+	got := clipVirtualText(strings.Repeat("é", maxVirtualText+5))
+	if utf8.RuneCountInString(got) != maxVirtualText || !strings.HasSuffix(got, "…") {
+		t.Errorf("clipVirtualText of a long text = %q", got)
+	}
+}
+
+func TestDiagnosticLens(t *testing.T) {
+	diag := func(path string, line int, message, kind string) diagnostic {
+		return diagnostic{location: location{path: path, line: line, col: 1}, message: message, kind: kind}
+	}
+	diags := []diagnostic{
+		diag("/p/a.go", 3, "unused variable x", ""),
+		diag("/p/a.go", 3, "x declared and not used", ""),
+		diag("/p/a.go", 3, "moved to heap: x", noteEscape),
+		diag("/p/b.go", 4, "other file", ""),
+		diag("/p/a.go", 5, "unreachable code", ""),
+		diag("/p/a.go", 40, "out of view", ""),
+	}
+	var got []string
+	for _, v := range diagnosticLens(diags, "/p/a.go", 1, 10) {
+		got = append(got, v.text)
+	}
+	want := []string{"unused variable x (+1)", "unreachable code"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnosticLens = %q, want %q", got, want)
+	}
+}
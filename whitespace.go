@@ -0,0 +1,228 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Whitespace and Indentation Guides
+// -------------------------------------------------------------------------
+
+// Tk draws no glyph in place of a character, so visible whitespace is made
+// of tags: a tab is struck through along its width, like the shaft of an
+// arrow, and trailing blanks get the overflow background. Indentation
+// guides are thin frames placed over the leading whitespace of the
+// visible lines, one per indentation level of a block.
+const (
+	tagWhitespaceTab = "wstab"      // Editor tag drawing the tabs
+	tagTrailingSpace = "wstrailing" // Editor tag of the blanks ending a line
+)
+
+// onToggleWordWrap switches the editor between word wrapping and long
+// lines, and persists the choice.
+func (i *Ite) onToggleWordWrap() {
+	if wrapMode == "none" {
+		i.config.WrapMode = "word"
+	} else {
+		i.config.WrapMode = "none"
+	}
+	i.saveConfig()
+	i.applyPreferences()
+}
+
+// onToggleWhitespace shows or hides tabs and trailing blanks, and
+// persists the choice.
+func (i *Ite) onToggleWhitespace() {
+	i.config.ShowWhitespace = !i.config.ShowWhitespace
+	i.saveConfig()
+	i.applyPreferences()
+}
+
+// onToggleIndentGuides shows or hides the indentation guides, and
+// persists the choice.
+func (i *Ite) onToggleIndentGuides() {
+	i.config.IndentGuides = !i.config.IndentGuides
+	i.saveConfig()
+	i.applyPreferences()
+}
+
+// updateViewChecks sets the View menu checkbuttons of the whitespace
+// options to the settings.
+func (i *Ite) updateViewChecks() {
+	i.wordWrapVar.Set(checkValue(wrapMode != "none"))
+	i.whitespaceVar.Set(checkValue(i.config.ShowWhitespace))
+	i.indentGuidesVar.Set(checkValue(i.config.IndentGuides))
+}
+
+// configureWhitespaceTags sets up the whitespace tags, below the selection.
+func (i *Ite) configureWhitespaceTags() {
+	i.editText.TagConfigure(tagWhitespaceTab, Overstrike(1), Overstrikefg(theme.Guide))
+	i.editText.TagConfigure(tagTrailingSpace, Background(theme.Overflow))
+	tclEval("%s tag lower %s sel", i.editText, tagWhitespaceTab)
+	tclEval("%s tag lower %s sel", i.editText, tagTrailingSpace)
+}
+
+// markWhitespace tags the tabs and the trailing blanks of the active
+// buffer, when visible whitespace is on.
+func (i *Ite) markWhitespace() {
+	i.editText.TagRemove(tagWhitespaceTab, "1.0", "end")
+	i.editText.TagRemove(tagTrailingSpace, "1.0", "end")
+	if !i.config.ShowWhitespace || i.largeFile || i.loading() {
+		return
+	}
+	for n, line := range strings.Split(i.editText.Text(), "\n") {
+		col := 0
+		for _, r := range line {
+			if r == '\t' {
+				i.editText.TagAdd(tagWhitespaceTab, fmt.Sprintf("%d.%d", n+1, col))
+			}
+			col++
+		}
+		if trimmed := strings.TrimRight(line, " \t"); len(trimmed) < len(line) {
+			start := runeColumn(line, len(trimmed))
+			i.editText.TagAdd(tagTrailingSpace, fmt.Sprintf("%d.%d", n+1, start), fmt.Sprintf("%d.%d", n+1, col))
+		}
+	}
+}
+
+// scheduleIndentGuides redraws the indentation guides of every pane once
+// Tk is idle, after the view scrolled or the text changed.
+func (i *Ite) scheduleIndentGuides() {
+	if i.guidesPending {
+		return
+	}
+	i.guidesPending = true
+	TclAfterIdle(func() {
+		i.guidesPending = false
+		for _, p := range i.panes {
+			if p == i.active {
+				i.drawIndentGuides(i.editText, &i.indentGuides, i.largeFile)
+			} else {
+				i.drawIndentGuides(p.text, &p.indentGuides, p.largeFile)
+			}
+		}
+	})
+}
+
+// drawIndentGuides places the guides of the lines visible in text,
+// reusing the frames of guides, and hides the frames left over.
+func (i *Ite) drawIndentGuides(text *TextWidget, guides *[]*FrameWidget, large bool) {
+	used := 0
+	defer func() {
+		for _, f := range (*guides)[used:] {
+			tclEval("place forget %s", f)
+		}
+	}()
+	if !i.config.IndentGuides || large || i.loads[text] != nil {
+		return
+	}
+	height := winfoInt(tclEval("winfo height %s", text))
+	first, _ := parseIndex(text.Index("@0,0"))
+	last, _ := parseIndex(text.Index(fmt.Sprintf("@0,%d", height)))
+	indents := make([]int, 0, last-first+1)
+	for line := first; line <= last; line++ {
+		indents = append(indents, visualIndent(lineText(text, line)))
+	}
+	unit := indentStep(indents)
+	if unit == 0 {
+		return
+	}
+	fillBlankIndents(indents)
+	charWidth := i.editorFont.Measure(text.Window, "0")
+
+	// A guide runs down the lines indented past its column
+	for level := 0; ; level++ {
+		col := level * unit
+		deeper := false
+		for n := 0; n < len(indents); n++ {
+			if indents[n] <= col {
+				continue
+			}
+			deeper = true
+			end := n
+			for end+1 < len(indents) && indents[end+1] > col {
+				end++
+			}
+			top := strings.Fields(tclEval("%s dlineinfo %d.0", text, first+n))
+			bottom := strings.Fields(tclEval("%s dlineinfo {%d.0 lineend}", text, first+end))
+			origin := strings.Fields(tclEval("%s bbox %d.0", text, first+n)) // Column 0, past the gutter margin
+			if len(top) >= 4 && len(bottom) >= 4 && len(origin) >= 4 {
+				x := winfoInt(origin[0]) + col*charWidth
+				y := winfoInt(top[1])
+				if used == len(*guides) {
+					*guides = append(*guides, text.Frame(Borderwidth(0)))
+				}
+				f := (*guides)[used]
+				used++
+				f.Configure(Background(theme.Guide))
+				Place(f, X(x), Y(y), Width(1), Height(winfoInt(bottom[1])+winfoInt(bottom[3])-y))
+			}
+			n = end
+		}
+		if !deeper {
+			return
+		}
+	}
+}
+
+// visualIndent returns the columns taken by the leading whitespace of
+// line, tabs reaching the next tab stop, or -1 for a blank line.
+func visualIndent(line string) int {
+	col := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			col++
+		case '\t':
+			col += tabWidth - col%tabWidth
+		default:
+			return col
+		}
+	}
+	return -1
+}
+
+// indentStep returns the columns of an indentation level, guessed from
+// the smallest indentation of indents, or 0 when no line is indented.
+func indentStep(indents []int) int {
+	step := 0
+	for _, n := range indents {
+		if n > 0 && (step == 0 || n < step) {
+			step = n
+		}
+	}
+	return step
+}
+
+// fillBlankIndents gives the blank lines, -1 in indents, the indentation
+// of the shallower of the lines around them, so guides run through them.
+func fillBlankIndents(indents []int) {
+	prev := -1
+	for n := 0; n < len(indents); n++ {
+		if indents[n] >= 0 {
+			prev = indents[n]
+			continue
+		}
+		next := -1
+		for m := n + 1; m < len(indents); m++ {
+			if indents[m] >= 0 {
+				next = indents[m]
+				break
+			}
+		}
+		switch {
+		case prev < 0:
+			indents[n] = max(next, 0)
+		case next < 0:
+			indents[n] = prev
+		default:
+			indents[n] = min(prev, next)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Ivan Guerreschi.
+// BSD-style license.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	. "modernc.org/tk9.0"
+)
+
+// -------------------------------------------------------------------------
+// Window State
+// -------------------------------------------------------------------------
+
+// windowState is the placement of the main window saved between sessions.
+// Position and size are those of the normal, not maximized, window, so
+// leaving the maximized state restores them.
+type windowState struct {
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	X         int  `json:"x"` // Left edge in virtual desktop coordinates, which select the monitor
+	Y         int  `json:"y"`
+	Maximized bool `json:"maximized"`
+}
+
+const minVisible = 100 // Pixels of the window that must stay on screen
+
+// geometryRe parses the result of "wm geometry", e.g. "1250x600+10+-20"
+// for a window partly above the top edge.
+var geometryRe = regexp.MustCompile(`^(\d+)x(\d+)\+(-?\d+)\+(-?\d+)$`)
+
+// parseGeometry splits a Tk geometry string into size and position.
+// Offsets from the right or bottom edge are not supported.
+func parseGeometry(geometry string) (w windowState, ok bool) {
+	m := geometryRe.FindStringSubmatch(geometry)
+	if m == nil {
+		return w, false
+	}
+	w.Width, _ = strconv.Atoi(m[1])
+	w.Height, _ = strconv.Atoi(m[2])
+	w.X, _ = strconv.Atoi(m[3])
+	w.Y, _ = strconv.Atoi(m[4])
+	return w, true
+}
+
+// desktop describes the area covered by the monitors.
+type desktop struct {
+	x, y, width, height int
+}
+
+// currentDesktop returns the virtual desktop, which spans all monitors.
+func currentDesktop() desktop {
+	d := desktop{
+		x:      winfoInt(tclEval("winfo vrootx .")),
+		y:      winfoInt(tclEval("winfo vrooty .")),
+		width:  winfoInt(tclEval("winfo vrootwidth .")),
+		height: winfoInt(tclEval("winfo vrootheight .")),
+	}
+	if d.width <= 0 || d.height <= 0 {
+		d.width = winfoInt(tclEval("winfo screenwidth ."))
+		d.height = winfoInt(tclEval("winfo screenheight ."))
+	}
+	return d
+}
+
+// fitWindow moves w back onto the desktop when too little of it would be
+// visible, as happens after unplugging the monitor it was on, and shrinks
+// it to the desktop.
+func fitWindow(w windowState, d desktop) windowState {
+	if d.width <= 0 || d.height <= 0 {
+		return w
+	}
+	w.Width = min(w.Width, d.width)
+	w.Height = min(w.Height, d.height)
+	offscreen := w.X+w.Width < d.x+minVisible || w.X > d.x+d.width-minVisible ||
+		w.Y < d.y || w.Y > d.y+d.height-minVisible
+	if offscreen {
+		// Center on the primary screen
+		w.X = d.x + (winfoInt(tclEval("winfo screenwidth ."))-w.Width)/2
+		w.Y = d.y + (winfoInt(tclEval("winfo screenheight ."))-w.Height)/2
+		w.X, w.Y = max(w.X, d.x), max(w.Y, d.y)
+	}
+	return w
+}
+
+// windowZoomed reports whether the main window is maximized.
+func windowZoomed() bool {
+	if tclEval("tk windowingsystem") == "x11" {
+		return tclEval("wm attributes . -zoomed") == "1"
+	}
+	return tclEval("wm state .") == "zoomed"
+}
+
+// setWindowZoomed maximizes the main window.
+func setWindowZoomed() {
+	if tclEval("tk windowingsystem") == "x11" {
+		tclEval("wm attributes . -zoomed 1")
+	} else {
+		tclEval("wm state . zoomed")
+	}
+}
+
+// restoreWindow places the main window as it was at the end of the last
+// session, or gives it the default size the first time.
+func (i *Ite) restoreWindow() {
+	saved := i.config.Window
+	if saved == nil || saved.Width <= 0 || saved.Height <= 0 {
+		WmGeometry(App, defaultGeometry())
+		return
+	}
+	w := fitWindow(*saved, currentDesktop())
+	i.normalWindow = w
+	WmGeometry(App, fmt.Sprintf("%dx%d%+d%+d", w.Width, w.Height, w.X, w.Y))
+	if w.Maximized {
+		setWindowZoomed()
+	}
+}
+
+// trackWindow remembers the normal placement of the main window whenever
+// it is moved or resized while not maximized.
+func (i *Ite) trackWindow() {
+	Bind(App, "<Configure>", Command(func(e *Event) {
+		if e.EventWindow != App || windowZoomed() {
+			return
+		}
+		if w, ok := parseGeometry(tclEval("wm geometry .")); ok {
+			i.normalWindow = w
+		}
+	}))
+}
+
+// recordWindow stores the placement of the main window in the settings.
+func (i *Ite) recordWindow() {
+	w := i.normalWindow
+	if w.Width <= 0 {
+		return // Never mapped
+	}
+	w.Maximized = windowZoomed()
+	i.config.Window = &w
+}